@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salchaD-27/infra-check/internal/archive"
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/report"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// maxArchiveSize caps what a scanned archive may unpack to, against zip bombs.
+const maxArchiveSize = 1 << 30
+
+// enableArchives lets a scan command take .tar, .tar.gz, .tgz or .zip files among its
+// paths: each archive is unpacked into a temporary directory that is scanned in its place
+// and then removed.
+//
+// Archives are unpacked to disk rather than read in memory because the scanners, and the
+// external tools such as tflint and puppet-lint that some of them run, read files by path,
+// as do custom rules; every one of them would otherwise need a filesystem of its own. The
+// directory is mounted (see scanner.Mount) rather than made the working directory, so the
+// other paths and the files flags name keep their meaning, and findings are reported by
+// their path inside the archive: relative to its root when it is the only path scanned,
+// and below the archive's own path otherwise.
+func enableArchives(c *cobra.Command) {
+	run := c.RunE
+	if run == nil {
+		return
+	}
+	c.RunE = func(cmd *cobra.Command, args []string) error {
+		found := false
+		for _, arg := range args {
+			found = found || isArchive(arg)
+		}
+		if !found {
+			return run(cmd, args)
+		}
+		if scoped() || watchMode {
+			return fmt.Errorf("--diff, --staged and --watch cannot be used to scan an archive")
+		}
+		if fixMode {
+			return fmt.Errorf("--fix cannot be used to scan an archive, whose fixed files would be thrown away")
+		}
+		if recordHistory && (historyFile == "" || attestCommit == "") {
+			return fmt.Errorf("--record needs --history-db and --commit to scan an archive, which has neither a repository to keep the history in nor a commit")
+		}
+
+		paths := make([]string, len(args))
+		for i, arg := range args {
+			if !isArchive(arg) {
+				paths[i] = arg
+				continue
+			}
+			tmp, err := os.MkdirTemp("", "infra-check-archive-")
+			if err != nil {
+				return err
+			}
+			defer os.RemoveAll(tmp)
+			if err := archive.Extract(arg, tmp, maxArchiveSize); err != nil {
+				return fmt.Errorf("%s: %v", arg, err)
+			}
+			name := arg
+			if len(args) == 1 {
+				name = ""
+			}
+			defer scanner.Mount(tmp, name)()
+			paths[i] = tmp
+		}
+		return run(cmd, paths)
+	}
+}
+
+// isArchive reports whether path is an archive file rather than a directory or a file
+// some scanner reads.
+func isArchive(path string) bool {
+	if !archive.IsArchive(path) {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// reported names f's file, and the files its message names, as they are reported: by
+// their path within a scanned archive or clone rather than the temporary directory holding it.
+func reported(f finding.Finding) finding.Finding {
+	f.File = scanner.Reported(f.File)
+	f.Message = scanner.ReportedText(f.Message)
+	return f
+}
+
+// reportedSections is reported for the cells of report sections, such as cost estimates by file.
+func reportedSections(sections []report.Section) []report.Section {
+	out := make([]report.Section, len(sections))
+	for i, s := range sections {
+		rows := make([][]string, len(s.Rows))
+		for j, row := range s.Rows {
+			rows[j] = make([]string, len(row))
+			for k, cell := range row {
+				rows[j][k] = scanner.ReportedText(cell)
+			}
+		}
+		s.Rows = rows
+		out[i] = s
+	}
+	return out
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salchaD-27/infra-check/internal/bench"
+)
+
+// Flags of the bench command
+var (
+	benchIterations int
+	benchScanners   []string
+	benchHot        int
+	benchFormat     string
+)
+
+// benchCmd times the scanners on a tree, for tuning and for catching slowdowns between releases
+var benchCmd = &cobra.Command{
+	Use:   "bench [path]",
+	Short: "Time each scanner on a tree, reporting files/sec, allocations and the slowest files",
+	Long: `Bench detects the scanners applying to path (default .) and runs each of them on its
+targets --iterations times after one untimed run, one scanner at a time. It reports the
+fastest, median and mean run, the files read per second at the median, the heap
+allocations of a run and, last, the files slowest to scan on their own.
+
+Custom rules and external tools such as puppet-lint are not run. Save the JSON output
+of a release to compare the next one with it on the same machine.`,
+	Example:      "  infra-check bench ./infra --iterations 10 --scanner terraform,ansible --format json",
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+		format := strings.ToLower(benchFormat)
+		if format != "text" && format != "json" {
+			return fmt.Errorf("--format must be text or json, got %s", benchFormat)
+		}
+		rep, err := bench.Run(path, bench.Options{Iterations: benchIterations, Scanners: benchScanners, Hot: benchHot})
+		if err != nil {
+			return err
+		}
+		rep.Version = version
+		if format == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(rep)
+		}
+		return printBench(rep)
+	},
+}
+
+// printBench writes the results and hot files of rep as tables.
+func printBench(rep *bench.Report) error {
+	fmt.Printf("infra-check %s, %s %s/%s, %d CPUs, %d iteration(s)\n\n", rep.Version, rep.GoVersion, rep.GOOS, rep.GOARCH, rep.CPUs, rep.Iterations)
+	if len(rep.Results) == 0 {
+		fmt.Printf("No scanner applies to %s\n", rep.Root)
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SCANNER\tTARGETS\tFILES\tFINDINGS\tMIN\tMEDIAN\tMEAN\tFILES/SEC\tALLOCS/RUN\tBYTES/RUN")
+	for _, r := range rep.Results {
+		if r.Error != "" {
+			fmt.Fprintf(w, "%s\t%d\t%d\t-\t-\t-\t-\t-\t-\t-\tfailed: %s\n", r.Scanner, r.Targets, r.Files, r.Error)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\t%s\t%s\t%.0f\t%d\t%s\n", r.Scanner, r.Targets, r.Files, r.Findings,
+			benchDuration(r.Min), benchDuration(r.Median), benchDuration(r.Mean), r.FilesPerSec, r.AllocsPerRun, benchBytes(r.BytesPerRun))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if len(rep.Hot) == 0 {
+		return nil
+	}
+
+	fmt.Println("\nSlowest files")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tSCANNER\tFILE")
+	for _, h := range rep.Hot {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", benchDuration(h.Duration), h.Scanner, h.File)
+	}
+	return w.Flush()
+}
+
+// benchDuration rounds d to three significant digits or so, enough to compare runs.
+func benchDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond).String()
+	default:
+		return d.Round(time.Microsecond).String()
+	}
+}
+
+// benchBytes prints n in the units --max-file-size takes.
+func benchBytes(n uint64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1fGB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fMB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fKB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%dB", n)
+}
+
+func init() {
+	benchCmd.Flags().IntVar(&benchIterations, "iterations", 5, "Timed runs of each scanner")
+	benchCmd.Flags().StringSliceVar(&benchScanners, "scanner", nil, "Only time these scanners, e.g. terraform,ansible (default: all that apply)")
+	benchCmd.Flags().IntVar(&benchHot, "top", 10, "Number of slowest files listed (0 for none)")
+	benchCmd.Flags().StringVarP(&benchFormat, "format", "f", "text", "Output format: text|json")
+	rootCmd.AddCommand(benchCmd)
+}
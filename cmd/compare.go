@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salchaD-27/infra-check/internal/engine"
+	"github.com/salchaD-27/infra-check/internal/report"
+)
+
+// compareTo is the --compare-to flag: a JSON report the scan is compared with.
+var compareTo string
+
+// compareCmd compares two JSON reports without scanning
+var compareCmd = &cobra.Command{
+	Use:   "diff <old.json> <new.json>",
+	Short: "Report the findings new in one JSON report compared with another",
+	Long: `Diff compares two reports written by 'scan ... --format json', such as the base
+branch's and a pull request's, and reports the findings of new.json that old.json
+does not have, with the number of new, fixed and unchanged findings and the list of
+fixed ones. --fail-on applies to the new findings only.`,
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		old, err := readReport(args[0])
+		if err != nil {
+			return err
+		}
+		current, err := readReport(args[1])
+		if err != nil {
+			return err
+		}
+		if s := current.Summary; s != nil {
+			scanStats.files, scanStats.took = s.FilesScanned, time.Duration(s.DurationMs)*time.Millisecond
+		}
+		if old.Findings, err = visible(old.Findings); err != nil {
+			return err
+		}
+		if current.Findings, err = visible(current.Findings); err != nil {
+			return err
+		}
+		delta := engine.Compare(old.Findings, current.Findings)
+		return renderReport(delta.New, delta.Sections(args[0]))
+	},
+}
+
+// readReport reads a JSON report written by --format json.
+func readReport(path string) (report.Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return report.Result{}, err
+	}
+	r, err := report.ReadResult(data)
+	if err != nil {
+		return r, fmt.Errorf("%s is not a JSON report: %v", path, err)
+	}
+	return r, nil
+}
+
+func init() {
+	compareCmd.Flags().StringVarP(&reportFormat, "format", "f", "text", "Output format: text|json|ndjson|markdown|gha|junit|csv|rdjson|template")
+	compareCmd.Flags().StringVar(&templateFile, "template", "", "Go text/template file rendered by --format template")
+	compareCmd.Flags().StringVar(&failOn, "fail-on", "none", "Exit non-zero when a new finding is at or above this severity: info|warn|error|none")
+	compareCmd.Flags().StringVar(&minSeverity, "min-severity", "info", "Leave findings below this severity out of both reports: info|warn|error")
+	rootCmd.AddCommand(compareCmd)
+}
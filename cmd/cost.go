@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"github.com/salchaD-27/infra-check/internal/cost"
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/report"
+)
+
+var estimateCost bool
+
+// withCostEstimate adds the cost findings and summary section for path when --estimate-cost is set.
+func withCostEstimate(paths []string, findings []finding.Finding, sections []report.Section) ([]finding.Finding, []report.Section, error) {
+	if !estimateCost {
+		return findings, sections, nil
+	}
+	var estimates []cost.Estimate
+	for _, path := range paths {
+		found, err := cost.EstimatePath(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		estimates = append(estimates, found...)
+	}
+	return append(findings, cost.Findings(estimates)...), append(sections, cost.Section(estimates)), nil
+}
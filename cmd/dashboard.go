@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salchaD-27/infra-check/internal/dashboard"
+	"github.com/salchaD-27/infra-check/internal/history"
+)
+
+var dashboardAddr string
+
+// dashboardCmd serves charts of the scan history in a browser
+var dashboardCmd = &cobra.Command{
+	Use:   "dashboard",
+	Short: "Serve a local web UI charting the findings of the recorded scans",
+	Long: `Dashboard serves a web UI over the scans recorded with 'scan ... --record', read
+from the history store (.infra-check/history.db in the repository root by default):
+the findings by severity over time, a trend line for each rule, and every scan with
+its findings and those new and fixed since the scan before. The store is read again
+on every page, so scans recorded meanwhile show up on reload. The dashboard has no
+authentication and listens on localhost unless --addr says otherwise.`,
+	Example:      "  infra-check dashboard --addr 127.0.0.1:8484",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := historyPath()
+		if _, err := recordedScans(); err != nil {
+			return err
+		}
+		load := func() ([]history.Scan, error) {
+			store, err := history.Open(path, true)
+			if err != nil {
+				return nil, err
+			}
+			defer store.Close()
+			return store.Scans()
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		srv := &http.Server{Addr: dashboardAddr, Handler: dashboard.Handler(path, load), ReadHeaderTimeout: 10 * time.Second}
+
+		errc := make(chan error, 1)
+		go func() { errc <- srv.ListenAndServe() }()
+		fmt.Fprintf(os.Stderr, "Serving the dashboard of %s on http://%s\n", path, dashboardAddr)
+		select {
+		case err := <-errc:
+			return err
+		case <-ctx.Done():
+		}
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdown); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	},
+}
+
+func init() {
+	dashboardCmd.Flags().StringVar(&dashboardAddr, "addr", "127.0.0.1:8484", "Address to listen on")
+	rootCmd.AddCommand(dashboardCmd)
+}
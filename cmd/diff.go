@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/salchaD-27/infra-check/internal/engine"
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/gitdiff"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// diffBase and stagedOnly are the --diff and --staged flags shared by the scan commands.
+var (
+	diffBase   string
+	stagedOnly bool
+)
+
+// scoped reports whether a scan is limited to changed files by --diff or --staged.
+func scoped() bool {
+	return diffBase != "" || stagedOnly
+}
+
+// changedFiles returns the files under path changed relative to --diff or staged for
+// commit with --staged, or nil without either.
+func changedFiles(path string) ([]string, error) {
+	switch {
+	case diffBase != "" && stagedOnly:
+		return nil, fmt.Errorf("--diff and --staged cannot be combined")
+	case stagedOnly:
+		staged, err := gitdiff.StagedFiles(path)
+		if err != nil {
+			return nil, fmt.Errorf("--staged: %v", err)
+		}
+		if len(staged) == 0 {
+			notice("No staged files under %s", path)
+		}
+		return staged, nil
+	case diffBase != "":
+		changed, err := gitdiff.ChangedFiles(path, diffBase)
+		if err != nil {
+			return nil, fmt.Errorf("--diff %s: %v", diffBase, err)
+		}
+		if len(changed) == 0 {
+			notice("No files under %s changed since %s", path, diffBase)
+		}
+		return changed, nil
+	}
+	return nil, nil
+}
+
+// scanPaths runs scan on each of paths, directories or single files, and merges the
+// findings. With --diff or --staged only the targets of the scanner holding files changed
+// under each path are scanned, and file-scoped scanners report only on the changed files.
+// With --incremental only the targets changed since the last scan are scanned, and the
+// findings of the others are taken from it. With --stream the findings are reported as scan
+// emits them and none are returned.
+func scanPaths(tool string, paths []string, scan func(target string, emit scanner.Emit) error) ([]finding.Finding, error) {
+	startScan()
+	inc, err := startIncremental(tool, paths)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := startStream()
+	if err != nil {
+		return nil, err
+	}
+	targets := paths
+	var changed []string
+	var s scanner.Scanner
+	if inc != nil || scoped() {
+		var ok bool
+		if s, ok = scanner.Lookup(tool); !ok {
+			return nil, fmt.Errorf("--diff, --staged and --incremental are not supported by 'scan %s'", tool)
+		}
+	}
+	switch {
+	case inc.rescans():
+		targets = nil
+		for _, path := range paths {
+			under := inc.changedUnder(path)
+			countFiles(path, under, tool)
+			targets = append(targets, inc.targets(s, path)...)
+		}
+	case scoped():
+		targets = nil
+		for _, path := range paths {
+			under, err := changedFiles(path)
+			if err != nil {
+				return nil, err
+			}
+			countFiles(path, under, tool)
+			changed = append(changed, under...)
+			targets = append(targets, engine.ChangedTargets(s, path, under)...)
+		}
+	default:
+		for _, path := range paths {
+			countFiles(path, nil, tool)
+		}
+	}
+
+	onlyChanged := s != nil && scoped() && engine.ScopedToFiles(s)
+	keep := make(map[string]bool, len(changed))
+	for _, p := range changed {
+		keep[filepath.Clean(p)] = true
+	}
+
+	progress.Planned(map[string][]string{tool: targets})
+	result := engine.ToolResult{Tool: tool, Targets: targets, ByTarget: make(map[string][]finding.Finding)}
+	for _, target := range targets {
+		progress.Scanning(tool, target)
+		err := scan(target, func(f finding.Finding) {
+			switch {
+			case onlyChanged && !keep[filepath.Clean(f.File)]:
+			case stream != nil:
+				stream.emit(f)
+			default:
+				result.ByTarget[target] = append(result.ByTarget[target], f)
+			}
+		})
+		progress.Scanned(tool, target)
+		if err != nil {
+			return nil, err
+		}
+		result.Findings = append(result.Findings, result.ByTarget[target]...)
+	}
+	if inc != nil {
+		merged, err := inc.finish(paths, []engine.ToolResult{result})
+		if err != nil {
+			return nil, err
+		}
+		return engine.Merge(merged), nil
+	}
+	return result.Findings, nil
+}
+
+// emitting adapts a scan returning its findings to scanPaths.
+func emitting(scan func(target string) ([]finding.Finding, error)) func(string, scanner.Emit) error {
+	return func(target string, emit scanner.Emit) error {
+		findings, err := scan(target)
+		for _, f := range findings {
+			emit(f)
+		}
+		return err
+	}
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/fix"
+)
+
+// fixMode is the --fix flag shared by the scan commands.
+var fixMode bool
+
+// fixing is set during the scan --fix runs to find what to fix; printReport hands it the
+// findings instead of reporting them.
+var fixing *fixState
+
+type fixState struct {
+	findings []finding.Finding
+}
+
+// enableFix makes c rewrite files to fix what it can when --fix is given: c scans once to
+// find the fixable findings, the fixes are applied and printed as a diff, and c scans again
+// to report what is left.
+func enableFix(c *cobra.Command) {
+	run := c.RunE
+	if run == nil {
+		return
+	}
+	c.RunE = func(cmd *cobra.Command, args []string) error {
+		if !fixMode {
+			return run(cmd, args)
+		}
+		if watchMode {
+			return fmt.Errorf("--fix cannot be combined with --watch")
+		}
+		if strings.EqualFold(reportFormat, "patch") {
+			return fmt.Errorf("--fix writes the fixes that --format patch prints; use one or the other")
+		}
+
+		fixing = &fixState{}
+		err := run(cmd, args)
+		found := fixing.findings
+		fixing = nil
+		if err != nil {
+			return err
+		}
+		changes, err := fix.Plan(found)
+		if err != nil {
+			return err
+		}
+		if err := fix.Write(changes); err != nil {
+			return err
+		}
+
+		// the diff goes ahead of a text report; other formats keep stdout machine-readable
+		var out io.Writer = os.Stdout
+		if format := strings.ToLower(reportFormat); (format != "text" && format != "") || countOnly {
+			out = os.Stderr
+		}
+		fixed := 0
+		for _, ch := range changes {
+			fmt.Fprint(out, ch.Diff())
+			fixed += ch.Fixed
+		}
+		notice("Fixed %d finding(s) in %d file(s)", fixed, len(changes))
+		return run(cmd, args)
+	}
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salchaD-27/infra-check/internal/engine"
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/history"
+	"github.com/salchaD-27/infra-check/internal/publish"
+)
+
+// Flags of the scan history: --record on the scan commands and --history-db everywhere
+var (
+	recordHistory bool
+	historyFile   string
+	trendLast     int
+)
+
+// Matches a fingerprint or the start of one, as `history first` takes it
+var fingerprintRegex = regexp.MustCompile(`^[0-9a-f]{6,32}(:[0-9]+)?$`)
+
+// historyCmd groups the commands reading the scan history
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show how the findings of recorded scans change over time",
+	Long: `History reads the scans recorded with 'scan ... --record', one per commit, from
+the history store (.infra-check/history.db in the repository root by default).`,
+}
+
+var historyTrendCmd = &cobra.Command{
+	Use:          "trend",
+	Short:        "List the recorded scans with the findings that appeared and were fixed since the scan before",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		scans, err := recordedScans()
+		if err != nil {
+			return err
+		}
+		steps := history.Trend(scans)
+		if trendLast > 0 && len(steps) > trendLast {
+			steps = steps[len(steps)-trendLast:]
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "COMMIT\tSCANNED\tFINDINGS\tERROR\tWARN\tINFO\tNEW\tFIXED")
+		for _, s := range steps {
+			bySeverity := make(map[finding.Severity]int)
+			for _, f := range s.Scan.Findings {
+				bySeverity[f.Severity]++
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t+%d\t-%d\n", shortCommit(s.Scan.Commit), s.Scan.Time.Local().Format("2006-01-02 15:04"),
+				len(s.Scan.Findings), bySeverity[finding.Error], bySeverity[finding.Warning], bySeverity[finding.Info], len(s.New), len(s.Fixed))
+		}
+		return w.Flush()
+	},
+}
+
+var historyFirstCmd = &cobra.Command{
+	Use:   "first <fingerprint | file[:line]>",
+	Short: "Show when findings first appeared and when they were fixed",
+	Long: `First looks up a finding by its fingerprint, or the start of one, as JSON output
+shows it, or the findings at a file and optional line in the latest scan reporting
+any there, and shows the first and last recorded scans reporting each.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		scans, err := recordedScans()
+		if err != nil {
+			return err
+		}
+		var lifetimes []history.Lifetime
+		if fingerprintRegex.MatchString(args[0]) {
+			lifetimes = history.Lifetimes(scans, args[0], "", 0)
+		} else {
+			file, line := args[0], 0
+			if i := strings.LastIndex(file, ":"); i > 0 {
+				if n, err := strconv.Atoi(file[i+1:]); err == nil {
+					file, line = file[:i], n
+				}
+			}
+			root, err := filepath.Abs(publish.RepoRoot())
+			if err != nil {
+				return err
+			}
+			lifetimes = history.Lifetimes(scans, "", repoRelative(root, file), line)
+		}
+		if len(lifetimes) == 0 {
+			return fmt.Errorf("no recorded finding matches %s", args[0])
+		}
+
+		for i, l := range lifetimes {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("%s  %s  %s\n", l.Finding.Location(), l.Finding.Severity, l.Finding.Message)
+			fmt.Printf("  fingerprint:  %s\n", l.Finding.Fingerprint)
+			fmt.Printf("  first seen:   %s\n", describeScan(l.First))
+			if l.Fixed == nil {
+				fmt.Printf("  last seen:    %s, the latest scan\n", describeScan(l.Last))
+			} else {
+				fmt.Printf("  last seen:    %s\n", describeScan(l.Last))
+				fmt.Printf("  fixed by:     %s\n", describeScan(*l.Fixed))
+			}
+		}
+		return nil
+	},
+}
+
+// historyPath returns --history-db, or the default store in the repository root.
+func historyPath() string {
+	if historyFile != "" {
+		return historyFile
+	}
+	return filepath.Join(publish.RepoRoot(), history.DefaultFile)
+}
+
+// recordedScans reads every scan in the history store, oldest first.
+func recordedScans() ([]history.Scan, error) {
+	store, err := history.Open(historyPath(), true)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+	scans, err := store.Scans()
+	if err != nil {
+		return nil, err
+	}
+	if len(scans) == 0 {
+		return nil, fmt.Errorf("%s holds no scans yet", historyPath())
+	}
+	return scans, nil
+}
+
+// recordScan stores findings in the history under the scanned commit when --record is given.
+func recordScan(findings []finding.Finding) error {
+	if !recordHistory {
+		return nil
+	}
+	if scoped() {
+		return fmt.Errorf("--record keeps whole scans to compare and cannot be combined with --diff or --staged")
+	}
+	commit := attestCommit
+	if commit == "" {
+		commit = headCommit()
+	}
+	if commit == "" {
+		return fmt.Errorf("--record keys scans by commit; run it in a git repository or give --commit")
+	}
+
+	// fingerprints of repository-relative paths match whichever directory the scan runs in
+	root, err := filepath.Abs(publish.RepoRoot())
+	if err != nil {
+		return err
+	}
+	rebased := make([]finding.Finding, len(findings))
+	for i, f := range findings {
+		f.File = repoRelative(root, f.File)
+		rebased[i] = f
+	}
+	rebased = engine.Fingerprint(rebased, root)
+
+	path := historyPath()
+	store, err := history.Open(path, false)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	if err := store.Record(history.Scan{Commit: commit, Time: time.Now().UTC(), Findings: rebased}); err != nil {
+		return fmt.Errorf("recording the scan in %s: %v", path, err)
+	}
+	notice("History: recorded %d finding(s) for %s in %s", len(rebased), shortCommit(commit), path)
+	return nil
+}
+
+// repoRelative returns file, relative to the working directory, as a slash-separated path
+// relative to root, or unchanged when it is outside root.
+func repoRelative(root, file string) string {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return file
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return file
+	}
+	return filepath.ToSlash(rel)
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
+
+func describeScan(s history.Scan) string {
+	return fmt.Sprintf("%s (%s)", shortCommit(s.Commit), s.Time.Local().Format("2006-01-02 15:04"))
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&historyFile, "history-db", "", "Scan history store (default .infra-check/history.db in the repository root)")
+	historyTrendCmd.Flags().IntVar(&trendLast, "last", 0, "Only list the last n scans (0 lists all)")
+	historyCmd.AddCommand(historyTrendCmd, historyFirstCmd)
+	rootCmd.AddCommand(historyCmd)
+}
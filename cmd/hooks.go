@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	hookFailOn string
+	hookForce  bool
+)
+
+// Marks hook scripts written by 'hooks install', so re-installing may replace them
+const hookMarker = "# installed by 'infra-check hooks install'"
+
+// The pre-commit framework configuration written when the repository has none
+const preCommitConfig = `repos:
+  - repo: local
+    hooks:
+      - id: infra-check
+        name: infra-check
+        entry: %s
+        language: system
+        pass_filenames: false
+`
+
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Manage git hooks that run infra-check",
+}
+
+var hooksInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install a pre-commit hook that scans the files staged for commit",
+	Long: `Install writes .git/hooks/pre-commit to run 'infra-check scan all --staged'
+before every commit, blocking the commit when a finding is at or above
+--fail-on. It also writes a .pre-commit-config.yaml for the pre-commit
+framework when the repository has none.
+
+An existing pre-commit hook that infra-check did not write is left alone
+unless --force is given. Skip the hook for one commit with 'git commit --no-verify'.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch strings.ToLower(hookFailOn) {
+		case "info", "warn", "warning", "error":
+		default:
+			return fmt.Errorf("unknown --fail-on severity '%s' (use info, warn or error)", hookFailOn)
+		}
+
+		top, err := gitOutput("", "rev-parse", "--show-toplevel")
+		if err != nil {
+			return err
+		}
+		// --git-path honours core.hooksPath and linked worktrees; it prints a path relative to top
+		hooksDir, err := gitOutput(top, "rev-parse", "--git-path", "hooks")
+		if err != nil {
+			return err
+		}
+		if !filepath.IsAbs(hooksDir) {
+			hooksDir = filepath.Join(top, hooksDir)
+		}
+
+		entry := "infra-check scan all --staged --fail-on " + strings.ToLower(hookFailOn) + " ."
+		if err := writeHook(filepath.Join(hooksDir, "pre-commit"), entry); err != nil {
+			return err
+		}
+		return writePreCommitConfig(filepath.Join(top, ".pre-commit-config.yaml"), entry)
+	},
+}
+
+// writeHook writes the pre-commit script running entry to p.
+func writeHook(p, entry string) error {
+	if existing, err := os.ReadFile(p); err == nil && !strings.Contains(string(existing), hookMarker) && !hookForce {
+		return fmt.Errorf("%s already exists and was not written by infra-check; use --force to replace it", p)
+	}
+	script := fmt.Sprintf(`#!/bin/sh
+%s
+# Scans the files staged for commit; skip once with 'git commit --no-verify'.
+# Set INFRA_CHECK to the binary to run when infra-check is not in PATH.
+exec "${INFRA_CHECK:-infra-check}" %s
+`, hookMarker, strings.TrimPrefix(entry, "infra-check "))
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(p, []byte(script), 0o755); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of a file it replaces
+	if err := os.Chmod(p, 0o755); err != nil {
+		return err
+	}
+	fmt.Printf("Installed pre-commit hook %s\n", p)
+	return nil
+}
+
+// writePreCommitConfig writes a pre-commit framework configuration running entry to p,
+// or, when p exists without an infra-check hook, prints the hook to add to it.
+func writePreCommitConfig(p, entry string) error {
+	config := fmt.Sprintf(preCommitConfig, entry)
+	existing, err := os.ReadFile(p)
+	switch {
+	case os.IsNotExist(err):
+		if err := os.WriteFile(p, []byte(config), 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote %s for the pre-commit framework\n", p)
+	case err != nil:
+		return err
+	case strings.Contains(string(existing), "id: infra-check"):
+		fmt.Printf("%s already runs infra-check\n", p)
+	default:
+		fmt.Printf("%s exists; add this hook to use infra-check with the pre-commit framework:\n\n%s", p, strings.TrimPrefix(config, "repos:\n"))
+	}
+	return nil
+}
+
+// gitOutput runs git in dir, or the working directory when dir is "", and returns its trimmed output.
+func gitOutput(dir string, args ...string) (string, error) {
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		if exit, ok := err.(*exec.ExitError); ok && len(exit.Stderr) > 0 {
+			return "", fmt.Errorf("git: %s", strings.TrimSpace(string(exit.Stderr)))
+		}
+		return "", fmt.Errorf("git: %v", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func init() {
+	hooksInstallCmd.Flags().StringVar(&hookFailOn, "fail-on", "error", "Lowest severity that blocks the commit: info|warn|error")
+	hooksInstallCmd.Flags().BoolVar(&hookForce, "force", false, "Replace an existing pre-commit hook not written by infra-check")
+	hooksCmd.AddCommand(hooksInstallCmd)
+	rootCmd.AddCommand(hooksCmd)
+}
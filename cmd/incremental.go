@@ -0,0 +1,291 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/salchaD-27/infra-check/internal/attest"
+	"github.com/salchaD-27/infra-check/internal/engine"
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/gitdiff"
+	"github.com/salchaD-27/infra-check/internal/history"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// incrementalMode is the --incremental flag of the scan commands.
+var incrementalMode bool
+
+// incrementalScan is a scan with --incremental: the baseline left by the last scan of the
+// branch with the same command, paths and settings, and the files changed since under each
+// scanned path. Only the targets holding those files are scanned again.
+type incrementalScan struct {
+	key    string
+	branch string
+	base   *history.Baseline // nil when everything is scanned, as on the first scan of a branch
+
+	changed map[string][]string // by scanned path
+}
+
+// startIncremental looks up the baseline of an --incremental scan of paths with command, the
+// scanner name or "all". It returns nil without --incremental.
+func startIncremental(command string, paths []string) (*incrementalScan, error) {
+	if !incrementalMode {
+		return nil, nil
+	}
+	if scoped() {
+		return nil, fmt.Errorf("--incremental reports every finding and cannot be combined with --diff or --staged")
+	}
+	if headCommit() == "" {
+		return nil, fmt.Errorf("--incremental rescans what changed since the commit of the last scan; run it in a git repository")
+	}
+	inc := &incrementalScan{branch: currentBranch()}
+	var err error
+	if inc.key, err = incrementalKey(inc.branch, command, paths); err != nil {
+		return nil, err
+	}
+
+	store, err := history.Open(historyPath(), false)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+	base, err := store.Baseline(inc.key)
+	if err != nil {
+		return nil, fmt.Errorf("reading the baseline in %s: %v", historyPath(), err)
+	}
+	if base == nil {
+		notice("Incremental: no earlier scan of %s, scanning everything", inc.branch)
+		return inc, nil
+	}
+
+	inc.changed = make(map[string][]string)
+	total := 0
+	for _, path := range paths {
+		changed, removed, err := gitdiff.Since(path, base.Commit)
+		if err != nil {
+			// e.g. a shallow clone without the commit, or history rewritten since
+			notice("Incremental: cannot compare with %s (%v), scanning everything", shortCommit(base.Commit), err)
+			return inc, nil
+		}
+		// files dirty at the last scan were scanned as they were then, not as in its commit
+		files := append(append(changed, removed...), base.Dirty[path]...)
+		inc.changed[path] = uniqueSorted(files)
+		total += len(inc.changed[path])
+	}
+	inc.base = base
+	notice("Incremental: %d file(s) changed since the last scan of %s at %s", total, inc.branch, shortCommit(base.Commit))
+	return inc, nil
+}
+
+// rescans reports whether only changed targets are scanned.
+func (inc *incrementalScan) rescans() bool {
+	return inc != nil && inc.base != nil
+}
+
+// changedUnder returns the changed files under path, never nil so the summary counts them only.
+func (inc *incrementalScan) changedUnder(path string) []string {
+	if files := inc.changed[path]; files != nil {
+		return files
+	}
+	return []string{}
+}
+
+// targets returns the targets of s under path holding changed files. Targets that are gone
+// are not scanned; their findings are dropped from the baseline by finish.
+func (inc *incrementalScan) targets(s scanner.Scanner, path string) []string {
+	var targets []string
+	for _, t := range engine.ChangedTargets(s, path, inc.changed[path]) {
+		if _, err := os.Stat(t); err == nil {
+			targets = append(targets, t)
+		}
+	}
+	return targets
+}
+
+// detect maps each scanner to its changed targets under path, as engine.RunTargets takes them.
+func (inc *incrementalScan) detect(path string) map[string][]string {
+	detected := make(map[string][]string)
+	for _, s := range scanner.All() {
+		if t := inc.targets(s, path); len(t) > 0 {
+			detected[s.Name()] = t
+		}
+	}
+	return detected
+}
+
+// finish merges the results of the scanned targets with the baseline findings of the others
+// and keeps the merged findings as the branch's new baseline. A target of the baseline that
+// no longer exists is dropped. Results are in registry order with their targets sorted.
+func (inc *incrementalScan) finish(paths []string, results []engine.ToolResult) ([]engine.ToolResult, error) {
+	byTool := make(map[string]map[string][]finding.Finding)
+	if inc.base != nil {
+		for tool, targets := range inc.base.Findings {
+			kept := make(map[string][]finding.Finding)
+			for target, found := range targets {
+				if _, err := os.Stat(target); err == nil {
+					kept[target] = found
+				}
+			}
+			byTool[tool] = kept
+		}
+	}
+	fresh := make(map[string]engine.ToolResult)
+	failed := false
+	for _, r := range results {
+		fresh[r.Tool] = r
+		failed = failed || r.Err != nil
+		if byTool[r.Tool] == nil {
+			byTool[r.Tool] = make(map[string][]finding.Finding)
+		}
+		for target, found := range r.ByTarget {
+			replace(byTool[r.Tool], target, found)
+		}
+	}
+
+	var merged []engine.ToolResult
+	for _, s := range scanner.All() {
+		targets := byTool[s.Name()]
+		r, ran := fresh[s.Name()]
+		if len(targets) == 0 && !ran {
+			continue
+		}
+		m := engine.ToolResult{Tool: s.Name(), ByTarget: targets, Err: r.Err, Duration: r.Duration}
+		for target := range targets {
+			m.Targets = append(m.Targets, target)
+		}
+		sort.Strings(m.Targets)
+		for _, target := range m.Targets {
+			m.Findings = append(m.Findings, targets[target]...)
+		}
+		merged = append(merged, m)
+	}
+
+	if failed {
+		notice("Incremental: a scanner failed, so the baseline of %s is left as it was", inc.branch)
+		return merged, nil
+	}
+	return merged, inc.save(paths, byTool)
+}
+
+// replace sets the findings of target, a rescanned one, in targets. Scanners walking whole
+// trees are rescanned on the directories of the changed files rather than on the paths a full
+// scan gave them, so the findings of targets below target are dropped, and those in target of
+// a target above it.
+func replace(targets map[string][]finding.Finding, target string, found []finding.Finding) {
+	for t, kept := range targets {
+		switch {
+		case within(t, target):
+			delete(targets, t)
+		case within(target, t):
+			var outside []finding.Finding
+			for _, f := range kept {
+				if !within(f.File, target) {
+					outside = append(outside, f)
+				}
+			}
+			targets[t] = outside
+		}
+	}
+	targets[target] = found
+}
+
+// within reports whether p is dir or a path below it.
+func within(p, dir string) bool {
+	rel, err := filepath.Rel(dir, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// save stores findings as the baseline of the branch at HEAD, with the files that differ
+// from HEAD in the working tree.
+func (inc *incrementalScan) save(paths []string, findings map[string]map[string][]finding.Finding) error {
+	base := history.Baseline{
+		Branch:   inc.branch,
+		Commit:   headCommit(),
+		Time:     time.Now().UTC(),
+		Dirty:    make(map[string][]string),
+		Findings: findings,
+	}
+	for _, path := range paths {
+		changed, removed, err := gitdiff.Since(path, base.Commit)
+		if err != nil {
+			return fmt.Errorf("--incremental: %v", err)
+		}
+		if dirty := append(changed, removed...); len(dirty) > 0 {
+			base.Dirty[path] = dirty
+		}
+	}
+
+	path := historyPath()
+	store, err := history.Open(path, false)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	if err := store.SaveBaseline(inc.key, base); err != nil {
+		return fmt.Errorf("saving the baseline in %s: %v", path, err)
+	}
+	return nil
+}
+
+// incrementalKey identifies the baseline of a scan by its branch, command and paths and the
+// settings that change what the scanners report, so a scan never reuses findings made with
+// other rules, configuration or flags.
+func incrementalKey(branch, command string, paths []string) (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	rules, err := attest.RulesDigest(rulesDir)
+	if err != nil {
+		return "", err
+	}
+	settings, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	parts := []string{
+		version, branch, wd, command, rules, string(settings),
+		strings.Join(excludes, ","), fmt.Sprint(followSymlinks, maxDepth, maxFileSize),
+		strings.Join(helmValuesFiles, ","), stateBackendDir, fmt.Sprint(withPuppetLint),
+	}
+	h := sha256.New()
+	for _, part := range append(parts, paths...) {
+		io.WriteString(h, part+"\x00")
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// currentBranch names the branch scanned: the one the CI system reports, as CI checks out
+// a detached commit, or else the checked out branch.
+func currentBranch() string {
+	for _, v := range []string{"GITHUB_HEAD_REF", "GITHUB_REF_NAME", "CI_COMMIT_REF_NAME", "BITBUCKET_BRANCH"} {
+		if branch := os.Getenv(v); branch != "" {
+			return branch
+		}
+	}
+	out, err := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return "HEAD"
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func uniqueSorted(files []string) []string {
+	sort.Strings(files)
+	out := files[:0]
+	for _, f := range files {
+		if len(out) == 0 || f != out[len(out)-1] {
+			out = append(out, f)
+		}
+	}
+	return out
+}
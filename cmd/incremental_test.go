@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/salchaD-27/infra-check/internal/engine"
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// An incremental scan after editing a module called from another directory must report
+// what a full scan does, with the findings in the module attributed to its callers.
+func TestIncrementalMatchesFullScanOfModules(t *testing.T) {
+	root := copyTree(t, "../tests/sample-terraform-modules")
+	s, ok := scanner.Lookup("terraform")
+	if !ok {
+		t.Fatal("terraform scanner not registered")
+	}
+
+	before, err := s.Scan(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	targets := map[string][]finding.Finding{root: before}
+
+	edited := filepath.Join(root, "modules", "bucket", "main.tf")
+	data, err := os.ReadFile(edited)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(edited, append(data, []byte("\n# edited\n")...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, target := range engine.ChangedTargets(s, root, []string{edited}) {
+		found, err := s.Scan(target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		replace(targets, target, found)
+	}
+	var incremental []finding.Finding
+	for _, found := range targets {
+		incremental = append(incremental, found...)
+	}
+
+	full, err := s.Scan(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := messages(incremental), messages(full); !reflect.DeepEqual(got, want) {
+		t.Errorf("incremental scan reports\n%q\nfull scan reports\n%q", got, want)
+	}
+}
+
+// copyTree copies the fixture directory src into a temporary directory.
+func copyTree(t *testing.T, src string) string {
+	t.Helper()
+	dst := t.TempDir()
+	err := filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, rel), 0o755)
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dst, rel), data, 0o644)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dst
+}
+
+// messages returns each finding as its location and message, sorted.
+func messages(findings []finding.Finding) []string {
+	out := make([]string, 0, len(findings))
+	for _, f := range findings {
+		out = append(out, f.Location()+" "+f.Message)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salchaD-27/infra-check/internal/engine"
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/rules"
+)
+
+var noColor bool
+
+// ANSI colors per severity
+var severityColors = map[finding.Severity]string{
+	finding.Error:   "\033[31m",
+	finding.Warning: "\033[33m",
+	finding.Info:    "\033[36m",
+}
+
+const (
+	colorReset = "\033[0m"
+	colorDim   = "\033[2m"
+)
+
+// lintCmd is the quick, single-file entry point for developers
+var lintCmd = &cobra.Command{
+	Use:   "lint [file]",
+	Short: "Lint a single Terraform, Ansible, Kubernetes, Puppet or Dockerfile file",
+	Long: `Lint auto-detects the type of one file, runs the matching scanner and the
+credential patterns on it and prints line-annotated findings. External tools
+such as puppet-lint are not run; use 'scan' for full project scans.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := args[0]
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return fmt.Errorf("%s is a directory; use 'infra-check scan all %s' instead", path, path)
+		}
+
+		tool, findings, err := lintFile(path)
+		if err != nil {
+			return err
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		printLint(path, tool, strings.Split(string(data), "\n"), findings, useColor())
+
+		for _, f := range findings {
+			if f.Severity == finding.Error {
+				return fmt.Errorf("%s has errors", path)
+			}
+		}
+		return nil
+	},
+}
+
+// lintFile scans the single file path with the scanner detected for it and the overlay
+// scanners reading it, returning the name of the detected scanner and the findings.
+func lintFile(path string) (string, []finding.Finding, error) {
+	s, target, err := engine.DetectFile(path)
+	if err != nil {
+		return "", nil, err
+	}
+	tool := s.Name()
+	if target != path {
+		return "", nil, fmt.Errorf("%s is part of the %s target %s; use 'infra-check scan %s %s' instead", path, tool, target, tool, target)
+	}
+
+	findings, err := s.Scan(path)
+	if err != nil {
+		return "", nil, err
+	}
+	findings, err = withCustomRules(tool, path, findings)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, o := range engine.Overlays(path) {
+		found, err := o.Scan(path)
+		if err != nil {
+			return "", nil, err
+		}
+		if found, err = withCustomRules(o.Name(), path, found); err != nil {
+			return "", nil, err
+		}
+		findings = append(findings, found...)
+	}
+	return tool, engine.Process(engine.Dedupe(engine.Suppress(findings)), cfg), nil
+}
+
+// useColor reports whether lint and text scan output should be colored: stdout
+// must be a terminal and neither --no-color nor NO_COLOR may be set.
+func useColor() bool {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// printLint writes findings ordered by line, each followed by the source line it points at.
+func printLint(path, tool string, lines []string, findings []finding.Finding, color bool) {
+	paint := func(code, s string) string {
+		if !color {
+			return s
+		}
+		return code + s + colorReset
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Line < findings[j].Line })
+	counts := make(map[finding.Severity]int)
+	for _, f := range findings {
+		counts[f.Severity]++
+		fmt.Printf("%s: %s %s\n", f.Location(), paint(severityColors[f.Severity], fmt.Sprintf("%-5s", f.Severity)), f.Message)
+		if f.Line > 0 && f.Line <= len(lines) {
+			fmt.Println(paint(colorDim, fmt.Sprintf("  %4d | %s", f.Line, strings.TrimRight(lines[f.Line-1], " \t\r"))))
+		}
+	}
+
+	if len(findings) == 0 {
+		fmt.Printf("%s: no issues found (%s)\n", path, tool)
+		return
+	}
+	fmt.Printf("\n%s (%s): %d error(s), %d warning(s), %d info\n", path, tool, counts[finding.Error], counts[finding.Warning], counts[finding.Info])
+}
+
+func init() {
+	lintCmd.Flags().StringVar(&rulesDir, "rules-dir", rules.DefaultDir, "Directory containing custom rule files")
+	rootCmd.AddCommand(lintCmd)
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// Linting a failure fixture runs the scanner of its file type, not only the credential
+// patterns every file type is checked against, and reports its findings.
+func TestLintFailureFixtures(t *testing.T) {
+	for _, pattern := range []string{
+		"../tests/sample-terraform-files/failure*.tf",
+		"../tests/sample-ansible-files/failure*.yml", // failure2.yaml lacks hosts, so is not taken for a playbook
+		"../tests/sample-puppet-files/failure*.pp",
+	} {
+		paths, err := filepath.Glob(pattern)
+		if err != nil || len(paths) == 0 {
+			t.Fatalf("no fixtures match %s", pattern)
+		}
+		for _, path := range paths {
+			tool, findings, err := lintFile(path)
+			if err != nil {
+				t.Errorf("lint %s: %v", path, err)
+				continue
+			}
+			if tool == "secrets" {
+				t.Errorf("lint %s: detected as secrets instead of its file type", path)
+			}
+			if len(findings) == 0 {
+				t.Errorf("lint %s (%s): no findings", path, tool)
+			}
+		}
+	}
+
+	_, findings, err := lintFile("../tests/sample-terraform-files/failure2.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	errors := 0
+	for _, f := range findings {
+		if f.Severity == finding.Error {
+			errors++
+		}
+	}
+	if errors == 0 {
+		t.Errorf("lint failure2.tf reports no errors, so the command would succeed")
+	}
+}
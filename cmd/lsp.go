@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salchaD-27/infra-check/internal/lsp"
+	"github.com/salchaD-27/infra-check/internal/rules"
+)
+
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Run a language server showing findings as editor diagnostics",
+	Long: `Lsp speaks the Language Server Protocol on stdin and stdout. Editors start it
+for Terraform, Ansible, Puppet and the other supported files and receive the
+findings of the matching scanners as diagnostics while files are edited,
+including unsaved changes.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		custom, err := rules.Load(rulesDir)
+		if err != nil {
+			return err
+		}
+		s := &lsp.Server{Rules: custom, Config: cfg, Version: version}
+		return s.Serve(os.Stdin, os.Stdout)
+	},
+}
+
+func init() {
+	lspCmd.Flags().StringVar(&rulesDir, "rules-dir", rules.DefaultDir, "Directory containing custom rule files")
+	// editors commonly pass --stdio; stdio is the only transport
+	lspCmd.Flags().Bool("stdio", true, "Communicate over stdin and stdout")
+	rootCmd.AddCommand(lspCmd)
+}
@@ -0,0 +1,413 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/salchaD-27/infra-check/internal/engine"
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/fix"
+	"github.com/salchaD-27/infra-check/internal/profile"
+	"github.com/salchaD-27/infra-check/internal/publish"
+	"github.com/salchaD-27/infra-check/internal/report"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+	"github.com/salchaD-27/infra-check/pkg/schema"
+)
+
+// reportFormat is the --format flag shared by the scan commands.
+var reportFormat string
+
+// templateFile is the --template flag: the text/template --format template renders.
+var templateFile string
+
+// failOn is the --fail-on flag: the lowest severity that makes a scan exit non-zero.
+var failOn string
+
+// minSeverity is the --min-severity flag: the lowest severity a report shows.
+var minSeverity string
+
+// profileName and complianceName are the --profile and --compliance flags: the rule
+// bundle and the compliance framework whose findings a report shows, each with the
+// controls it fails.
+var (
+	profileName    string
+	complianceName string
+)
+
+// snippetContext is the --snippet-context flag: the source lines shown around each
+// finding's line, or none at all when negative.
+var snippetContext int
+
+// quiet and countOnly are the --quiet and --count-only flags: the first leaves only the
+// report and errors, the second replaces the report with the number of findings per severity.
+var (
+	quiet     bool
+	countOnly bool
+)
+
+// noDedupe is the --no-dedupe flag: keep findings repeated by several scanners or targets.
+var noDedupe bool
+
+// notice writes an informational message to stderr unless --quiet is given.
+func notice(format string, args ...interface{}) {
+	if !quiet {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+	}
+}
+
+// scanStats feeds the report summary: when the running scan started and how many files
+// its scanners read, or how long a scan took when reporting one read from a JSON report.
+var scanStats struct {
+	started time.Time
+	took    time.Duration
+	root    string
+	files   int
+	tools   []string
+}
+
+// startScan resets scanStats at the start of a scan, including each re-scan of --watch.
+func startScan() {
+	scanStats.started = time.Now()
+	scanStats.root, scanStats.files, scanStats.tools = "", 0, nil
+}
+
+// countFiles records for the summary a scanned root and how many files the tools read
+// under it, or among changed when the scan is limited to changed files. A scan of several
+// roots counts each in turn.
+func countFiles(root string, changed []string, tools ...string) {
+	if scanStats.root == "" {
+		scanStats.root = scanner.Reported(root)
+	} else {
+		scanStats.root += " " + scanner.Reported(root)
+	}
+	scanStats.tools = tools
+	if scoped() && changed == nil {
+		changed = []string{}
+	}
+	if n, err := engine.FilesRead(root, changed, tools...); err == nil {
+		scanStats.files += n
+	}
+}
+
+// summarize builds the report summary of findings from scanStats.
+func summarize(findings []finding.Finding) report.Summary {
+	elapsed := scanStats.took
+	if !scanStats.started.IsZero() {
+		elapsed = time.Since(scanStats.started)
+	}
+	return report.Summarize(findings, scanStats.files, elapsed)
+}
+
+// Severities in increasing order, as accepted by --fail-on and --min-severity
+var severityOrder = map[finding.Severity]int{finding.Info: 1, finding.Warning: 2, finding.Error: 3}
+
+// printFindings writes findings to stdout in the format selected with --format.
+func printFindings(findings []finding.Finding) error {
+	return printReport(findings, nil)
+}
+
+// printReport writes findings followed by any extra report sections.
+// Sections are only rendered by the text, Markdown and JSON formats; the others have no place for tables.
+func printReport(findings []finding.Finding, sections []report.Section) error {
+	if streamOutput {
+		s, err := startStream()
+		if err != nil {
+			return err
+		}
+		return s.finish(findings, sections)
+	}
+	progress.finish()
+	for i := range findings {
+		findings[i] = reported(findings[i])
+	}
+	sections = reportedSections(sections)
+	findings = engine.Suppress(findings)
+	if !noDedupe {
+		findings = engine.Dedupe(findings)
+	}
+	findings = engine.Process(findings, cfg)
+	if fixing != nil {
+		fixing.findings = findings
+		return nil
+	}
+	findings, err := visible(findings)
+	if err != nil {
+		return err
+	}
+	if watching != nil && watching.report(findings) {
+		return nil
+	}
+	findings = engine.Snippets(engine.Fingerprint(findings, ""), "", snippetContext)
+	if compareTo != "" {
+		old, err := readReport(compareTo)
+		if err != nil {
+			return err
+		}
+		// hidden findings of the earlier scan would otherwise count as fixed
+		if old.Findings, err = visible(old.Findings); err != nil {
+			return err
+		}
+		delta := engine.Compare(old.Findings, findings)
+		findings = delta.New
+		sections = append(delta.Sections(compareTo), sections...)
+	}
+	return renderReport(findings, sections)
+}
+
+// renderReport writes findings and sections in the format selected with --format, then
+// finishes the report; the findings are reported, published and checked as they are.
+func renderReport(findings []finding.Finding, sections []report.Section) error {
+	processed := findings
+	summary := summarize(processed)
+	format := strings.ToLower(reportFormat)
+	if signKey != "" && format != "json" {
+		return fmt.Errorf("--sign requires --format json")
+	}
+	if (format == "template") != (templateFile != "") {
+		return fmt.Errorf("--format template and --template must be given together")
+	}
+	if countOnly {
+		if format != "text" && format != "" {
+			return fmt.Errorf("--count-only cannot be combined with --format %s", reportFormat)
+		}
+		for _, sev := range []finding.Severity{finding.Error, finding.Warning, finding.Info} {
+			fmt.Printf("%s %d\n", sev, summary.BySeverity[string(sev)])
+		}
+		return finishReport(processed, sections)
+	}
+	if format != "json" && format != "ndjson" && format != "csv" && format != "template" && format != "patch" {
+		// JSON, NDJSON and CSV keep every occurrence for tooling and triage, and a patch fixes each one;
+		// the other formats are read by people.
+		findings = engine.Collapse(findings, cfg)
+	}
+
+	switch format {
+	case "json":
+		out, err := report.ExportResult(report.Result{SchemaVersion: schema.Version, Findings: findings, Sections: sections, Summary: &summary})
+		if err != nil {
+			return err
+		}
+		out += "\n"
+		fmt.Print(out)
+		if signKey != "" {
+			if err := signResult([]byte(out)); err != nil {
+				return err
+			}
+		}
+
+	case "ndjson":
+		out, err := report.ExportNDJSON(findings)
+		if err != nil {
+			return err
+		}
+		fmt.Print(out)
+
+	case "template":
+		repo := ""
+		if root := publish.RepoRoot(); root != "." {
+			repo = filepath.Base(root)
+		}
+		out, err := report.ExportTemplate(templateFile, report.TemplateData{
+			Findings: findings,
+			Sections: sections,
+			Summary:  summary,
+			Scan: report.ScanInfo{
+				Tool:          "infra-check",
+				Version:       version,
+				SchemaVersion: schema.Version,
+				Path:          scanStats.root,
+				Repository:    repo,
+				Commit:        headCommit(),
+				RunURL:        publish.CIRunURL(),
+				ScannedAt:     scanStats.started.UTC(),
+			},
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Print(out)
+
+	case "markdown":
+		out, err := report.ExportMarkdownWithSummary(findings, &summary)
+		if err != nil {
+			return err
+		}
+		fmt.Println(out + report.ExportMarkdownSections(sections))
+
+	case "gha":
+		out, err := report.ExportGitHubActions(findings)
+		if err != nil {
+			return err
+		}
+		fmt.Print(out)
+
+	case "rdjson":
+		out, err := report.ExportRDJSON(findings)
+		if err != nil {
+			return err
+		}
+		fmt.Println(out)
+
+	case "csv":
+		out, err := report.ExportCSV(findings)
+		if err != nil {
+			return err
+		}
+		fmt.Print(out)
+
+	case "patch":
+		// the diff --fix would write, for git apply or review suggestions, leaving the files as they are
+		changes, err := fix.Plan(findings)
+		if err != nil {
+			return err
+		}
+		for _, c := range changes {
+			fmt.Print(c.Diff())
+		}
+
+	case "junit":
+		out, err := report.ExportJUnit(findings)
+		if err != nil {
+			return err
+		}
+		fmt.Println(out)
+
+	default: // plain text
+		fmt.Print(report.ExportText(findings, useColor()))
+		if !quiet {
+			fmt.Print(report.ExportTextSections(append(sections, summary.Sections()...)))
+		}
+	}
+	return finishReport(processed, sections)
+}
+
+// finishReport records and publishes findings and applies --fail-on once they are reported.
+func finishReport(findings []finding.Finding, sections []report.Section) error {
+	if watching != nil {
+		return nil // a watch keeps running and publishes nothing, whatever it finds
+	}
+	if err := recordScan(findings); err != nil {
+		return err
+	}
+	if err := publishFindings(findings, sections); err != nil {
+		return err
+	}
+	return checkFailOn(findings)
+}
+
+// checkFailOn returns an error when a finding is at or above the --fail-on severity.
+func checkFailOn(findings []finding.Finding) error {
+	threshold, err := failThreshold()
+	if err != nil || threshold == "" {
+		return err
+	}
+	return failedOn(atOrAbove(findings, threshold), threshold)
+}
+
+// failedOn returns the --fail-on error for count findings at or above threshold, if any.
+func failedOn(count int, threshold finding.Severity) error {
+	if count == 0 {
+		return nil
+	}
+	// the findings are the explanation; usage would only bury them
+	rootCmd.SilenceUsage = true
+	return fmt.Errorf("%d finding(s) at or above %s", count, threshold)
+}
+
+// failThreshold returns the --fail-on severity, or "" for none.
+func failThreshold() (finding.Severity, error) {
+	return severityFlag("--fail-on", failOn)
+}
+
+// visible drops the findings below the --min-severity severity and, with --profile or
+// --compliance, those of rules mapped to no control, setting the controls of the others.
+func visible(findings []finding.Finding) ([]finding.Finding, error) {
+	threshold, err := severityFlag("--min-severity", minSeverity)
+	if err != nil {
+		return nil, err
+	}
+	bundles, err := selectedProfiles(profileName, complianceName)
+	if err != nil {
+		return nil, err
+	}
+	if threshold == "" && len(bundles) == 0 {
+		return findings, nil
+	}
+	var out []finding.Finding
+	for _, f := range findings {
+		if threshold != "" && severityOrder[f.Severity] < severityOrder[threshold] {
+			continue
+		}
+		if f, ok := inProfiles(f, bundles); ok {
+			out = append(out, f)
+		}
+	}
+	return out, nil
+}
+
+// selectedProfiles returns the profile named by a --profile flag and that of the framework
+// named by a --compliance flag, leaving out those not given.
+func selectedProfiles(name, framework string) ([]*profile.Profile, error) {
+	var bundles []*profile.Profile
+	if name != "" {
+		p, err := profile.Lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		bundles = append(bundles, p)
+	}
+	if framework != "" {
+		// custom rules carry mappings too
+		all, err := catalogue()
+		if err != nil {
+			return nil, err
+		}
+		p, err := profile.Framework(framework, all)
+		if err != nil {
+			return nil, err
+		}
+		bundles = append(bundles, p)
+	}
+	return bundles, nil
+}
+
+// inProfiles reports whether every one of bundles maps f to a control, adding to f the
+// controls of each.
+func inProfiles(f finding.Finding, bundles []*profile.Profile) (finding.Finding, bool) {
+	for _, p := range bundles {
+		var ok bool
+		if f, ok = p.Apply(f); !ok {
+			return f, false
+		}
+	}
+	return f, true
+}
+
+// severityFlag parses the value of a severity flag, "" for none.
+func severityFlag(name, value string) (finding.Severity, error) {
+	switch strings.ToLower(value) {
+	case "", "none":
+		return "", nil
+	case "info":
+		return finding.Info, nil
+	case "warn", "warning":
+		return finding.Warning, nil
+	case "error":
+		return finding.Error, nil
+	}
+	return "", fmt.Errorf("unknown %s severity '%s' (use info, warn, error or none)", name, value)
+}
+
+// atOrAbove counts the findings with at least the given severity.
+func atOrAbove(findings []finding.Finding, threshold finding.Severity) int {
+	count := 0
+	for _, f := range findings {
+		if severityOrder[f.Severity] >= severityOrder[threshold] {
+			count++
+		}
+	}
+	return count
+}
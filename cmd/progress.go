@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+
+	"github.com/salchaD-27/infra-check/internal/engine"
+)
+
+// verbose is the --verbose flag shared by the scan commands.
+var verbose bool
+
+// How long a scan runs before the status line appears, so quick scans print nothing
+// extra, and how often it is redrawn.
+const (
+	progressDelay    = 500 * time.Millisecond
+	progressInterval = 100 * time.Millisecond
+)
+
+// progress is the reporter of the running scan command, nil when there is nothing to report to.
+var progress *scanProgress
+
+// scanProgress writes what a scan is doing to stderr, keeping stdout for the report. With
+// --verbose each target is logged as a scanner starts on it; when stderr is a terminal a
+// status line with the files read so far, out of those the scanners will read, and the
+// current scanner is redrawn until the report is printed. Files count as read when their
+// target is scanned, once per scanner reading them.
+type scanProgress struct {
+	mu      sync.Mutex
+	started time.Time
+	shown   bool // the status line is on screen
+	stopped bool
+	files   map[string]int // files of each tool's target, keyed by progressKey
+	total   int
+	read    int
+	tool    string
+	target  string
+	done    chan struct{}
+}
+
+// enableProgress reports the progress of c on stderr while it scans.
+func enableProgress(c *cobra.Command) {
+	run := c.RunE
+	if run == nil {
+		return
+	}
+	c.RunE = func(cmd *cobra.Command, args []string) error {
+		if quiet && verbose {
+			return fmt.Errorf("--quiet and --verbose cannot be combined")
+		}
+		startProgress()
+		defer progress.finish()
+		return run(cmd, args)
+	}
+}
+
+// startProgress sets up progress for a scan that is about to start.
+func startProgress() {
+	line := !quiet && term.IsTerminal(os.Stderr.Fd())
+	if !line && !verbose {
+		return
+	}
+	p := &scanProgress{started: time.Now(), done: make(chan struct{})}
+	progress = p
+	engine.SetProgress(p)
+	if line {
+		go func() {
+			tick := time.NewTicker(progressInterval)
+			defer tick.Stop()
+			for {
+				select {
+				case <-p.done:
+					return
+				case <-tick.C:
+					p.draw()
+				}
+			}
+		}()
+	}
+}
+
+func progressKey(tool, target string) string {
+	return tool + "\x00" + target
+}
+
+// Planned counts the files the scanners will read under each of their targets.
+func (p *scanProgress) Planned(targets map[string][]string) {
+	if p == nil {
+		return
+	}
+	tools := make([]string, 0, len(targets))
+	for tool := range targets {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+
+	files := make(map[string]int)
+	total := 0
+	for _, tool := range tools {
+		n := 0
+		for _, target := range targets[tool] {
+			count, _ := engine.FilesRead(target, nil, tool)
+			files[progressKey(tool, target)] = count
+			n += count
+		}
+		if verbose {
+			p.log("%s: %d target(s), %d file(s)", tool, len(targets[tool]), n)
+		}
+		total += n
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.files, p.total = files, total
+}
+
+// Scanning records the scanner and target being scanned.
+func (p *scanProgress) Scanning(tool, target string) {
+	if p == nil {
+		return
+	}
+	if verbose {
+		p.log("%s: scanning %s", tool, target)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tool, p.target = tool, target
+}
+
+// Scanned adds the files of a finished target to those read.
+func (p *scanProgress) Scanned(tool, target string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.read += p.files[progressKey(tool, target)]
+}
+
+// log writes a --verbose line, above the status line when there is one.
+func (p *scanProgress) log(format string, args ...interface{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clear()
+	fmt.Fprintf(os.Stderr, "[%s] "+format+"\n", append([]interface{}{elapsed(p.started)}, args...)...)
+}
+
+// draw redraws the status line once the scan has run for progressDelay.
+func (p *scanProgress) draw() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped || p.tool == "" || time.Since(p.started) < progressDelay {
+		return
+	}
+	status := fmt.Sprintf("Scanning %s %s", p.tool, p.target)
+	if p.total > 0 {
+		status = fmt.Sprintf("%d/%d files · %s", p.read, p.total, status)
+	}
+	status = fmt.Sprintf("[%s] %s", elapsed(p.started), status)
+	// a status line wrapping onto a second row could not be redrawn in place
+	if width, _, err := term.GetSize(os.Stderr.Fd()); err == nil && width > 1 {
+		if runes := []rune(status); len(runes) >= width {
+			status = string(runes[:width-2]) + "…"
+		}
+	}
+	fmt.Fprint(os.Stderr, "\r\033[K"+status)
+	p.shown = true
+}
+
+// clear erases the status line; the caller holds the lock.
+func (p *scanProgress) clear() {
+	if p.shown {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+		p.shown = false
+	}
+}
+
+// finish stops reporting, before the report is printed. It may be called more than once.
+func (p *scanProgress) finish() {
+	if p == nil || progress != p {
+		return
+	}
+	progress = nil
+	engine.SetProgress(nil)
+	close(p.done)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stopped = true
+	p.clear()
+}
+
+// elapsed renders the time since start for progress output, e.g. 1.2s.
+func elapsed(start time.Time) string {
+	return time.Since(start).Round(100 * time.Millisecond).String()
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/publish"
+	"github.com/salchaD-27/infra-check/internal/report"
+)
+
+// Flags shared by the scan commands: --publish, --notify, --webhook, --metrics-* and their settings.
+var (
+	publishTo      []string
+	notifyTo       []string
+	slackWebhook   string
+	reportURL      string
+	webhookURL     string
+	webhookSecret  string
+	webhookRetries int
+	metricsFile    string
+	metricsPush    string
+	metricsJob     string
+	metricsLabels  map[string]string
+)
+
+// publishFindings posts findings to every service named with --publish, sends the
+// summaries asked for with --notify and delivers the result to --webhook. A scan counts
+// as failed for commit statuses, reports and notifications at the --fail-on severity,
+// or on errors without it.
+func publishFindings(findings []finding.Finding, sections []report.Section) error {
+	if len(publishTo) == 0 && len(notifyTo) == 0 && webhookURL == "" && metricsFile == "" && metricsPush == "" {
+		return nil
+	}
+	threshold, err := failThreshold()
+	if err != nil {
+		return err
+	}
+	if threshold == "" {
+		threshold = finding.Error
+	}
+	failed := atOrAbove(findings, threshold) > 0
+
+	for _, target := range publishTo {
+		var summary string
+		switch strings.ToLower(strings.TrimSpace(target)) {
+		case "gitlab":
+			g, err := publish.GitLabFromEnv()
+			if err != nil {
+				return fmt.Errorf("--publish gitlab: %v", err)
+			}
+			if summary, err = g.Publish(findings, failed); err != nil {
+				return fmt.Errorf("--publish gitlab: %v", err)
+			}
+		case "bitbucket":
+			b, err := publish.BitbucketFromEnv()
+			if err != nil {
+				return fmt.Errorf("--publish bitbucket: %v", err)
+			}
+			if summary, err = b.Publish(findings, failed); err != nil {
+				return fmt.Errorf("--publish bitbucket: %v", err)
+			}
+		default:
+			return fmt.Errorf("unknown --publish target '%s' (use gitlab or bitbucket)", target)
+		}
+		notice("%s", summary)
+	}
+
+	link := reportURL
+	if link == "" {
+		link = publish.CIRunURL()
+	}
+	for _, target := range notifyTo {
+		var summary string
+		switch strings.ToLower(strings.TrimSpace(target)) {
+		case "slack":
+			webhook := slackWebhook
+			if webhook == "" {
+				webhook = os.Getenv("SLACK_WEBHOOK_URL")
+			}
+			if webhook == "" {
+				return fmt.Errorf("--notify slack needs --slack-webhook or SLACK_WEBHOOK_URL")
+			}
+			root := publish.RepoRoot()
+			s := &publish.Slack{WebhookURL: webhook, ReportURL: link, Repo: filepath.Base(root), Commit: headCommit(), Root: root}
+			if summary, err = s.Notify(findings, failed); err != nil {
+				return fmt.Errorf("--notify slack: %v", err)
+			}
+		default:
+			return fmt.Errorf("unknown --notify target '%s' (use slack)", target)
+		}
+		notice("%s", summary)
+	}
+
+	if webhookURL != "" {
+		secret := webhookSecret
+		if secret == "" {
+			secret = os.Getenv("INFRA_CHECK_WEBHOOK_SECRET")
+		}
+		w := &publish.Webhook{URL: webhookURL, Secret: secret, Retries: webhookRetries}
+		scanSummary := summarize(findings)
+		result := report.Result{Findings: findings, Sections: sections, Summary: &scanSummary}
+		summary, err := w.Send(result, failed, publish.WebhookMetadata{
+			Version:    version,
+			Repository: filepath.Base(publish.RepoRoot()),
+			Commit:     headCommit(),
+			RunURL:     link,
+		})
+		if err != nil {
+			return fmt.Errorf("--webhook: %v", err)
+		}
+		notice("%s", summary)
+	}
+	return exportMetrics(findings)
+}
+
+// exportMetrics writes the Prometheus metrics of the scan to --metrics-file and pushes them
+// to --metrics-push, labelled with the repository's directory name and --metrics-label.
+func exportMetrics(findings []finding.Finding) error {
+	if metricsFile == "" && metricsPush == "" {
+		return nil
+	}
+	root, err := filepath.Abs(publish.RepoRoot())
+	if err != nil {
+		return err
+	}
+	labels := map[string]string{"repository": filepath.Base(root)}
+	for k, v := range metricsLabels {
+		labels[k] = v
+	}
+	scanner := ""
+	if len(scanStats.tools) == 1 {
+		scanner = scanStats.tools[0]
+	}
+	summary := summarize(findings)
+	now := time.Now()
+
+	if metricsFile != "" {
+		// written next to the target and renamed, so a textfile collector never reads half a file
+		tmp, err := os.CreateTemp(filepath.Dir(metricsFile), ".infra-check-metrics-*")
+		if err != nil {
+			return fmt.Errorf("--metrics-file: %v", err)
+		}
+		defer os.Remove(tmp.Name())
+		_, err = tmp.WriteString(publish.Metrics(findings, summary, scanner, labels, now))
+		if cerr := tmp.Close(); err == nil {
+			err = cerr
+		}
+		if err == nil {
+			err = os.Chmod(tmp.Name(), 0o644)
+		}
+		if err == nil {
+			err = os.Rename(tmp.Name(), metricsFile)
+		}
+		if err != nil {
+			return fmt.Errorf("--metrics-file: %v", err)
+		}
+		notice("Metrics: wrote %s", metricsFile)
+	}
+	if metricsPush != "" {
+		p := &publish.Pushgateway{URL: metricsPush, Job: metricsJob, Labels: labels}
+		summary, err := p.Push(publish.Metrics(findings, summary, scanner, nil, now))
+		if err != nil {
+			return fmt.Errorf("--metrics-push: %v", err)
+		}
+		notice("%s", summary)
+	}
+	return nil
+}
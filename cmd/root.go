@@ -1,11 +1,46 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/spf13/cobra"
+
+	"github.com/salchaD-27/infra-check/internal/ansible"
+	"github.com/salchaD-27/infra-check/internal/config"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+	"github.com/salchaD-27/infra-check/internal/terraform"
+)
+
+var cfgFile string
+
+// excludes are the --exclude path globs, added to the config file's.
+var excludes []string
+
+// followSymlinks and maxDepth are the --follow-symlinks and --max-depth flags of the
+// directory walks.
+var (
+	followSymlinks bool
+	maxDepth       int
 )
 
+// maxFileSize is the --max-file-size flag: files above it are not read by any scanner.
+var maxFileSize string
+
+// skippedFiles are the files the walks skipped so far, logged with --verbose.
+var skippedFiles sync.Map
+
+// version is set at build time with -ldflags "-X github.com/salchaD-27/infra-check/cmd.version=v1.2.3"
+// and falls back to the module version recorded by go install.
+var version = "dev"
+
+// cfg is the loaded project configuration, available to every command.
+var cfg = &config.Config{}
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "infra-check",
@@ -19,11 +54,97 @@ to quickly create a Cobra application.`,
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		loaded, err := loadConfig()
+		if err != nil {
+			// the command line is fine, and the usage text would bury the error
+			cmd.SilenceUsage = true
+			return err
+		}
+		cfg = loaded
+		terraform.Configure(cfg)
+		ansible.Configure(cfg)
+		scanner.SetExcludes(append(append([]string(nil), cfg.Exclude...), excludes...))
+		if maxDepth < 0 {
+			return fmt.Errorf("--max-depth must be 0 (unlimited) or more, got %d", maxDepth)
+		}
+		limit, err := parseSize(maxFileSize)
+		if err != nil {
+			return fmt.Errorf("--max-file-size: %v", err)
+		}
+		scanner.SetWalkOptions(scanner.WalkOptions{
+			FollowSymlinks: followSymlinks,
+			MaxDepth:       maxDepth,
+			MaxFileSize:    limit,
+			SkipBinary:     true,
+			Skipped: func(path, reason string) {
+				// every scanner walks the tree, but a skipped file is logged once
+				if _, seen := skippedFiles.LoadOrStore(path, true); !seen && verbose && progress != nil {
+					progress.log("skipped %s: %s", path, reason)
+				}
+			},
+		})
+		if quiet {
+			cmd.Root().SilenceUsage = true
+		}
+		return nil
+	},
+}
+
+// loadConfig loads the configuration file --config names, or ./.infra-check.yaml when
+// present, and checks the rules it refers to exist.
+func loadConfig() (*config.Config, error) {
+	loaded, err := config.Load(cfgFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := loaded.Validate(knownRule); err != nil {
+		path := cfgFile
+		if path == "" {
+			path = config.DefaultFile
+		}
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return loaded, nil
+}
+
+// sizeUnits are the suffixes parseSize accepts, longest first so "MB" is not read as "B".
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"KIB", 1 << 10}, {"MIB", 1 << 20}, {"GIB", 1 << 30},
+	{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30},
+	{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}, {"B", 1},
+}
+
+// parseSize parses a byte count with an optional binary unit, such as 64MB or 512KiB.
+func parseSize(s string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	unit := int64(1)
+	for _, u := range sizeUnits {
+		if strings.HasSuffix(value, u.suffix) {
+			value, unit = strings.TrimSpace(strings.TrimSuffix(value, u.suffix)), u.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size '%s' (use e.g. 512KB, 64MB or 1GB)", s)
+	}
+	return n * unit, nil
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
+	addScannerCommands()
+	for _, c := range scanCmd.Commands() {
+		enableProgress(c)
+		enableFix(c)
+		enableWatch(c)
+		enableArchives(c)
+	}
 	err := rootCmd.Execute()
 	if err != nil {
 		os.Exit(1)
@@ -31,11 +152,21 @@ func Execute() {
 }
 
 func init() {
+	if info, ok := debug.ReadBuildInfo(); ok && version == "dev" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		version = info.Main.Version
+	}
+	rootCmd.Version = version
+
 	// flags and configuration settings.
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for application.
 
-	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.infra-check.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is ./"+config.DefaultFile+" when present)")
+	rootCmd.PersistentFlags().StringSliceVar(&excludes, "exclude", nil, "Skip paths matching these globs in every scanner, e.g. '**/examples/**' (repeatable)")
+	rootCmd.PersistentFlags().BoolVar(&followSymlinks, "follow-symlinks", false, "Descend into symlinked directories, visiting each directory and file once even through cycles")
+	rootCmd.PersistentFlags().IntVar(&maxDepth, "max-depth", 0, "Read at most this many directory levels below each scanned path, 1 for its own files only (0 for unlimited)")
+	rootCmd.PersistentFlags().StringVar(&maxFileSize, "max-file-size", "64MB", "Skip files larger than this, e.g. 512KB, 64MB or 1GB (0 for no limit); binary files are always skipped")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored terminal output (also disabled by NO_COLOR or when stdout is not a terminal)")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
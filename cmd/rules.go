@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/registry"
+	"github.com/salchaD-27/infra-check/internal/rules"
+)
+
+var rulesDir string
+
+// rulesCmd groups the commands for browsing the rules and for authoring and testing custom ones
+var rulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "List and describe rules, author and test custom rules",
+	Long: `Rules subcommands list and describe the built-in and custom rules, and help platform teams
+write custom YAML policies and verify them against fixtures.`,
+}
+
+// withCustomRules appends the findings of the custom rules for scanner in --rules-dir.
+func withCustomRules(scanner, path string, findings []finding.Finding) ([]finding.Finding, error) {
+	loaded, err := rules.Load(rulesDir)
+	if err != nil {
+		return nil, err
+	}
+	custom, err := rules.EvaluateAll(loaded, scanner, path)
+	if err != nil {
+		return nil, err
+	}
+	return append(findings, custom...), nil
+}
+
+func init() {
+	rulesCmd.PersistentFlags().StringVar(&rulesDir, "rules-dir", rules.DefaultDir, "Directory containing custom rule files")
+	rootCmd.AddCommand(rulesCmd)
+}
+
+// knownRule reports whether id names a registered rule or a custom rule in --rules-dir,
+// for validating the rule IDs in the config file.
+func knownRule(id string) bool {
+	if _, ok := registry.Lookup(id); ok {
+		return true
+	}
+	// a broken rules directory is reported by the commands that evaluate it
+	loaded, _ := rules.Load(rulesDir)
+	for _, r := range loaded {
+		if r.ID == id {
+			return true
+		}
+	}
+	return false
+}
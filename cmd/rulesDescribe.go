@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// rulesDescribeCmd shows everything known about one rule
+var rulesDescribeCmd = &cobra.Command{
+	Use:          "describe <rule-id>",
+	Short:        "Show the description and a remediation example of a rule",
+	Example:      "  infra-check rules describe TF029",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, err := catalogue()
+		if err != nil {
+			return err
+		}
+		for _, r := range all {
+			if !strings.EqualFold(r.ID, args[0]) {
+				continue
+			}
+			fmt.Printf("%s: %s\n\n", r.ID, r.Name)
+			severity := string(r.Severity)
+			if sev := effectiveSeverity(r); sev != severity {
+				severity = fmt.Sprintf("%s (overridden from %s)", sev, r.Severity)
+			}
+			scanners := strings.Join(r.Scanners, ", ")
+			if scanners == "" {
+				scanners = "any"
+			}
+			fmt.Printf("  Severity:  %s\n", severity)
+			fmt.Printf("  Category:  %s\n", r.Category)
+			fmt.Printf("  Scanners:  %s\n", scanners)
+			fmt.Printf("  Enabled:   %s\n", yesNo(cfg.Enabled(r.ID)))
+			if controls := r.Compliance.Controls(); len(controls) > 0 {
+				fmt.Printf("  Controls:  %s\n", strings.Join(controls, ", "))
+			}
+			if r.URL != "" {
+				fmt.Printf("  Docs:      %s\n", r.URL)
+			}
+			fmt.Printf("\n%s\n", r.Description)
+			if r.Remediation != "" {
+				fmt.Println("\nRemediation:")
+				for _, line := range strings.Split(r.Remediation, "\n") {
+					fmt.Println(strings.TrimRight("    "+line, " "))
+				}
+			}
+			return nil
+		}
+		return fmt.Errorf("unknown rule '%s'; run infra-check rules list to see the rules", args[0])
+	},
+}
+
+func init() {
+	rulesCmd.AddCommand(rulesDescribeCmd)
+}
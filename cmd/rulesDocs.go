@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salchaD-27/infra-check/internal/registry"
+)
+
+// rulesDocsCmd prints the rule reference that docs/rules.md holds
+var rulesDocsCmd = &cobra.Command{
+	Use:   "docs",
+	Short: "Print the Markdown reference of the built-in rules",
+	Long: `Docs prints the reference of the built-in rules that rule documentation links point to,
+as committed in docs/rules.md. Regenerate it after changing a rule.`,
+	Example:      "  infra-check rules docs > docs/rules.md",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Print(registry.Markdown(registry.All()))
+		return nil
+	},
+}
+
+func init() {
+	rulesCmd.AddCommand(rulesDocsCmd)
+}
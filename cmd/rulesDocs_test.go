@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/salchaD-27/infra-check/internal/registry"
+)
+
+// docs/rules.md, which rule documentation links point to, must document the rules as
+// registered.
+func TestRulesDocsUpToDate(t *testing.T) {
+	committed, err := os.ReadFile("../docs/rules.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(committed) != registry.Markdown(registry.All()) {
+		t.Error("docs/rules.md is out of date; regenerate it with infra-check rules docs > docs/rules.md")
+	}
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salchaD-27/infra-check/internal/profile"
+	"github.com/salchaD-27/infra-check/internal/registry"
+	"github.com/salchaD-27/infra-check/internal/rules"
+)
+
+var (
+	listRulesScanner   string
+	listRulesCategory  string
+	listRulesProfile   string
+	listRulesFramework string
+)
+
+// rulesListCmd prints the rule catalogue as the current config applies it
+var rulesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the built-in and custom rules with their severity and enabled state",
+	Long: `List prints every built-in rule and every custom rule in --rules-dir. SEVERITY is the
+severity after the config file's severity_overrides, ENABLED is "no" for rules listed
+in disabled_rules or switched off by a scanner setting. With --profile or --compliance
+only the rules mapped to controls of the benchmark or framework are listed, with the
+controls in a CONTROLS column.`,
+	Example: `  infra-check rules list --scanner terraform --category iam
+  infra-check rules list --profile cis-aws
+  infra-check rules list --compliance pci`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, err := catalogue()
+		if err != nil {
+			return err
+		}
+		bundles, err := selectedProfiles(listRulesProfile, listRulesFramework)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		if len(bundles) > 0 {
+			fmt.Fprintln(w, "ID\tSEVERITY\tCATEGORY\tSCANNERS\tENABLED\tCONTROLS\tNAME")
+		} else {
+			fmt.Fprintln(w, "ID\tSEVERITY\tCATEGORY\tSCANNERS\tENABLED\tNAME")
+		}
+		for _, r := range all {
+			if listRulesScanner != "" && !appliesTo(r, listRulesScanner) {
+				continue
+			}
+			if listRulesCategory != "" && r.Category != listRulesCategory {
+				continue
+			}
+			scanners := strings.Join(r.Scanners, ",")
+			if scanners == "" {
+				scanners = "any"
+			}
+			if len(bundles) > 0 {
+				controls := controlsOf(r.ID, bundles)
+				if len(controls) == 0 {
+					continue
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", r.ID, effectiveSeverity(r), r.Category, scanners, yesNo(cfg.Enabled(r.ID)), strings.Join(controls, ", "), r.Name)
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", r.ID, effectiveSeverity(r), r.Category, scanners, yesNo(cfg.Enabled(r.ID)), r.Name)
+		}
+		return w.Flush()
+	},
+}
+
+// catalogue returns the built-in rules followed by the custom rules in --rules-dir, which
+// are described by their message.
+func catalogue() ([]registry.Rule, error) {
+	loaded, err := rules.Load(rulesDir)
+	if err != nil {
+		return nil, err
+	}
+	custom := make([]registry.Rule, 0, len(loaded))
+	for _, r := range loaded {
+		custom = append(custom, registry.Rule{
+			ID:          r.ID,
+			Name:        r.Message,
+			Description: "Custom rule defined in " + r.File + ".",
+			Severity:    r.Severity,
+			Category:    "custom",
+			Compliance:  r.Compliance,
+			Scanners:    []string{r.Scanner},
+		})
+	}
+	sort.Slice(custom, func(i, j int) bool { return custom[i].ID < custom[j].ID })
+	return append(registry.All(), custom...), nil
+}
+
+// controlsOf returns the controls of every one of bundles rule id is mapped to, or none
+// when one of them does not map it.
+func controlsOf(id string, bundles []*profile.Profile) []string {
+	var controls []string
+	for _, p := range bundles {
+		of := p.ControlsOf(id)
+		if len(of) == 0 {
+			return nil
+		}
+		controls = append(controls, of...)
+	}
+	return controls
+}
+
+// effectiveSeverity is the severity of r after the config's overrides.
+func effectiveSeverity(r registry.Rule) string {
+	if sev, ok := cfg.SeverityFor(r.ID); ok {
+		return string(sev)
+	}
+	return string(r.Severity)
+}
+
+// appliesTo reports whether r checks the findings of scanner; rules naming no scanner apply to all.
+func appliesTo(r registry.Rule, scanner string) bool {
+	if len(r.Scanners) == 0 {
+		return true
+	}
+	for _, s := range r.Scanners {
+		if s == scanner {
+			return true
+		}
+	}
+	return false
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func init() {
+	rulesListCmd.Flags().StringVar(&listRulesScanner, "scanner", "", "Only list the rules applying to this scanner")
+	rulesListCmd.Flags().StringVar(&listRulesCategory, "category", "", "Only list the rules in this category, e.g. iam or secrets")
+	rulesListCmd.Flags().StringVar(&listRulesProfile, "profile", "", "Only list the rules of this profile, with the benchmark controls they check: cis-aws")
+	rulesListCmd.Flags().StringVar(&listRulesFramework, "compliance", "", "Only list the rules mapped to controls of this framework, with the controls: soc2|pci|hipaa|nist")
+	rulesCmd.AddCommand(rulesListCmd)
+}
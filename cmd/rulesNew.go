@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salchaD-27/infra-check/internal/rules"
+)
+
+var (
+	newRuleScanner string
+	newRuleID      string
+)
+
+// rulesNewCmd scaffolds a rule file, fixtures and a test entry
+var rulesNewCmd = &cobra.Command{
+	Use:     "new",
+	Short:   "Scaffold a new custom rule with passing and failing fixtures",
+	Example: "  infra-check rules new --scanner terraform --id ORG001",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		created, err := rules.Scaffold(rulesDir, strings.ToLower(newRuleScanner), newRuleID)
+		if err != nil {
+			return err
+		}
+		for _, p := range created {
+			fmt.Println("created", p)
+		}
+		fmt.Printf("Edit the rule and fixtures, then run: infra-check rules test --rules-dir %s\n", rulesDir)
+		return nil
+	},
+}
+
+func init() {
+	rulesNewCmd.Flags().StringVar(&newRuleScanner, "scanner", "", "Scanner the rule targets: "+strings.Join(rules.Scanners, "|"))
+	rulesNewCmd.Flags().StringVar(&newRuleID, "id", "", "Rule ID, e.g. ORG001")
+	rulesNewCmd.MarkFlagRequired("scanner")
+	rulesNewCmd.MarkFlagRequired("id")
+	rulesCmd.AddCommand(rulesNewCmd)
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salchaD-27/infra-check/internal/rules"
+)
+
+// rulesTestCmd runs every tests.yaml entry: rules must stay quiet on
+// their passing fixtures and fire on their failing ones.
+var rulesTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Run custom rules against their fixtures",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		loaded, err := rules.Load(rulesDir)
+		if err != nil {
+			return err
+		}
+		byID := make(map[string]rules.Rule)
+		for _, r := range loaded {
+			byID[r.ID] = r
+		}
+
+		tests, err := rules.LoadTests(rulesDir)
+		if err != nil {
+			return err
+		}
+		if len(tests) == 0 {
+			fmt.Printf("No rule tests found in %s\n", rulesDir)
+			return nil
+		}
+
+		failed := 0
+		for _, t := range tests {
+			r, ok := byID[t.Rule]
+			if !ok {
+				fmt.Printf("FAIL %s: rule not found in %s\n", t.Rule, rulesDir)
+				failed++
+				continue
+			}
+
+			passFindings, err := rules.Evaluate(r, t.Pass)
+			if err != nil {
+				return err
+			}
+			failFindings, err := rules.Evaluate(r, t.Fail)
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case len(passFindings) > 0:
+				fmt.Printf("FAIL %s: %d finding(s) on passing fixture %s\n", r.ID, len(passFindings), t.Pass)
+				for _, f := range passFindings {
+					fmt.Printf("     %s: %s\n", f.File, f.Message)
+				}
+				failed++
+			case len(failFindings) == 0:
+				fmt.Printf("FAIL %s: no findings on failing fixture %s\n", r.ID, t.Fail)
+				failed++
+			default:
+				fmt.Printf("ok   %s\n", r.ID)
+			}
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d rule test(s) failed", failed, len(tests))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rulesCmd.AddCommand(rulesTestCmd)
+}
@@ -4,19 +4,102 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+
+	"github.com/salchaD-27/infra-check/internal/rules"
+	"github.com/salchaD-27/infra-check/internal/scanner"
 )
 
 // scanCmd represents the scan command
 var scanCmd = &cobra.Command{
 	Use:   "scan",
 	Short: "Scan infrastructure code",
-	Long:  "Scan subcommands allow you to run analyses against Terraform, Ansible, Puppet infra code.",
+	Long:  "Scan subcommands allow you to run analyses against Terraform, Ansible, Puppet and other infra code.",
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("scan called")
 	},
 }
 
+// addScannerCommands adds a 'scan <name>' command for every registered scanner that has
+// no hand-written command of its own, so a new scanner only needs to register itself.
+func addScannerCommands() {
+	existing := make(map[string]bool)
+	for _, c := range scanCmd.Commands() {
+		existing[c.Name()] = true
+	}
+	for _, s := range scanner.All() {
+		if existing[s.Name()] {
+			continue
+		}
+		scanCmd.AddCommand(newScannerCmd(s))
+	}
+}
+
+// newScannerCmd builds the generic scan command: run the scanner, add its custom rules, print.
+func newScannerCmd(s scanner.Scanner) *cobra.Command {
+	short := fmt.Sprintf("Scan %s code in the specified directories or files", s.Name())
+	if d, ok := s.(scanner.Describer); ok {
+		short = d.Description()
+	}
+	c := &cobra.Command{
+		Use:   s.Name() + " <path>...",
+		Short: short,
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			findings, err := scanPaths(s.Name(), args, func(target string, emit scanner.Emit) error {
+				if err := scanner.Stream(s, target, emit); err != nil {
+					return err
+				}
+				custom, err := withCustomRules(s.Name(), target, nil)
+				for _, f := range custom {
+					emit(f)
+				}
+				return err
+			})
+			if err != nil {
+				return err
+			}
+
+			return printFindings(findings)
+		},
+	}
+	c.Flags().StringVarP(&reportFormat, "format", "f", "text", "Output format: text|json|ndjson|markdown|gha|junit|csv|rdjson|patch|template")
+	return c
+}
+
 func init() {
+	scanCmd.PersistentFlags().StringVar(&rulesDir, "rules-dir", rules.DefaultDir, "Directory containing custom rule files")
+	scanCmd.PersistentFlags().StringVar(&signKey, "sign", "", "Ed25519 private key (PEM) to sign the JSON result with")
+	scanCmd.PersistentFlags().StringVar(&attestationFile, "attestation", "infra-check.intoto.json", "File the signed attestation is written to with --sign")
+	scanCmd.PersistentFlags().StringVar(&diffBase, "diff", "", "Only scan files changed relative to this git ref (e.g. origin/main), including uncommitted changes")
+	scanCmd.PersistentFlags().BoolVar(&stagedOnly, "staged", false, "Only scan files staged for commit in the git index")
+	scanCmd.PersistentFlags().BoolVar(&streamOutput, "stream", false, "Print each finding as soon as it is found instead of once the scan ends, keeping none in memory (text and ndjson formats)")
+	scanCmd.PersistentFlags().BoolVar(&incrementalMode, "incremental", false, "Only rescan files changed since the last scan of this git branch, reporting their findings together with the kept ones of the others")
+	scanCmd.PersistentFlags().StringVar(&templateFile, "template", "", "Go text/template file rendered by --format template")
+	scanCmd.PersistentFlags().StringVar(&compareTo, "compare-to", "", "JSON report of an earlier scan; report only the findings new since, with the fixed ones in a section")
+	scanCmd.PersistentFlags().StringVar(&failOn, "fail-on", "none", "Exit non-zero when a finding is at or above this severity: info|warn|error|none")
+	scanCmd.PersistentFlags().IntVar(&snippetContext, "snippet-context", 2, "Source lines shown before and after each finding's line in text, Markdown and JSON reports; -1 for no snippets")
+	scanCmd.PersistentFlags().StringVar(&profileName, "profile", "", "Report only the findings of the rules in this compliance profile, each with the benchmark controls it fails: cis-aws")
+	scanCmd.PersistentFlags().StringVar(&complianceName, "compliance", "", "Report only the findings of the rules mapped to controls of this framework, each with the controls it fails: soc2|pci|hipaa|nist")
+	scanCmd.PersistentFlags().StringVar(&minSeverity, "min-severity", "info", "Leave findings below this severity out of the report, whatever the format: info|warn|error")
+	scanCmd.PersistentFlags().StringSliceVar(&publishTo, "publish", nil, "Publish findings after the scan: gitlab (merge request discussions and commit status), bitbucket (Code Insights report)")
+	scanCmd.PersistentFlags().StringSliceVar(&notifyTo, "notify", nil, "Send a scan summary after the scan: slack")
+	scanCmd.PersistentFlags().StringVar(&slackWebhook, "slack-webhook", "", "Slack incoming webhook URL for --notify slack (default $SLACK_WEBHOOK_URL)")
+	scanCmd.PersistentFlags().StringVar(&reportURL, "report-url", "", "Link to the full report in notifications (default: the CI run's URL)")
+	scanCmd.PersistentFlags().StringVar(&webhookURL, "webhook", "", "POST the JSON result with scan metadata to this URL after the scan")
+	scanCmd.PersistentFlags().StringVar(&webhookSecret, "webhook-secret", "", "Sign webhook deliveries with HMAC-SHA256 using this secret (default $INFRA_CHECK_WEBHOOK_SECRET)")
+	scanCmd.PersistentFlags().IntVar(&webhookRetries, "webhook-retries", 3, "Retries of a webhook delivery failing with a network error, 429 or 5xx")
+	scanCmd.PersistentFlags().BoolVar(&recordHistory, "record", false, "Record the findings in the scan history under the scanned commit, for the history commands")
+	scanCmd.PersistentFlags().StringVar(&metricsFile, "metrics-file", "", "Write scan metrics in the Prometheus text format to this file, e.g. for the node_exporter textfile collector")
+	scanCmd.PersistentFlags().StringVar(&metricsPush, "metrics-push", "", "Push scan metrics to this Prometheus Pushgateway URL after the scan")
+	scanCmd.PersistentFlags().StringVar(&metricsJob, "metrics-job", "infra-check", "Job the metrics are pushed as with --metrics-push")
+	scanCmd.PersistentFlags().StringToStringVar(&metricsLabels, "metrics-label", nil, "Extra label on the metrics and Pushgateway group, e.g. team=platform or repository=name (repeatable)")
+	scanCmd.PersistentFlags().BoolVar(&fixMode, "fix", false, "Rewrite files to fix missing tags, sensitive variables, become and trailing whitespace, printing a diff, then report what is left")
+	scanCmd.PersistentFlags().BoolVar(&noDedupe, "no-dedupe", false, "Report a finding once per scanner or target repeating it instead of once per file, line and rule")
+	scanCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Print only the report and errors: no progress, status messages or text summary tables")
+	scanCmd.PersistentFlags().BoolVar(&countOnly, "count-only", false, "Print only the number of findings per severity, one 'SEVERITY count' line each")
+	scanCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "Log each scanner target on stderr as it is scanned")
+	scanCmd.PersistentFlags().BoolVar(&watchMode, "watch", false, "Keep running and re-scan when files change, printing only new and resolved findings")
+	scanCmd.PersistentFlags().StringVar(&attestCommit, "commit", "", "Commit recorded in the attestation and --record history (default: git HEAD of the working directory)")
 	rootCmd.AddCommand(scanCmd)
 
 	// Cobra supports Persistent Flags which will work for this command
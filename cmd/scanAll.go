@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salchaD-27/infra-check/internal/engine"
+	"github.com/salchaD-27/infra-check/internal/report"
+	"github.com/salchaD-27/infra-check/internal/rules"
+)
+
+// allCmd represents the scan all command
+var allCmd = &cobra.Command{
+	Use:   "all [path]",
+	Short: "Detect and scan all supported IaC content in the specified directory",
+	Long: `Detects Terraform, Ansible, Puppet, Kubernetes, Helm, Kustomize, Pulumi,
+CloudFormation, CDK, Nomad and Serverless Framework content under path, runs every
+applicable scanner concurrently and prints one merged report followed by a per-tool
+summary.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+
+		custom, err := rules.Load(rulesDir)
+		if err != nil {
+			return err
+		}
+		startScan()
+		inc, err := startIncremental("all", []string{path})
+		if err != nil {
+			return err
+		}
+		stream, err := startStream()
+		if err != nil {
+			return err
+		}
+		var results []engine.ToolResult
+		var changed []string
+		switch {
+		case inc.rescans():
+			changed = inc.changedUnder(path)
+			results = engine.RunTargets(inc.detect(path), custom)
+		case scoped():
+			if changed, err = changedFiles(path); err != nil {
+				return err
+			}
+			if stream != nil {
+				results = engine.StreamChanged(path, changed, custom, stream.emit)
+			} else {
+				results = engine.RunChanged(path, changed, custom)
+			}
+		case stream != nil:
+			if results, err = engine.StreamAll(path, custom, stream.emit); err != nil {
+				return err
+			}
+		default:
+			if results, err = engine.RunAll(path, custom); err != nil {
+				return err
+			}
+		}
+		countFiles(path, changed, tools(results)...)
+		if inc != nil {
+			if results, err = inc.finish([]string{path}, results); err != nil {
+				return err
+			}
+		}
+		if len(results) == 0 && scoped() {
+			return printReport(nil, nil)
+		}
+		if len(results) == 0 {
+			return fmt.Errorf("no supported IaC content found in %s", path)
+		}
+
+		summary := engine.SummarySection(results, cfg)
+		if stream != nil {
+			summary = engine.CountedSummarySection(results, stream.byTool)
+		}
+		findings, sections, err := withCostEstimate([]string{path}, engine.Merge(results), []report.Section{summary})
+		if err != nil {
+			return err
+		}
+		return printReport(findings, sections)
+	},
+}
+
+// tools returns the names of the scanners that ran.
+func tools(results []engine.ToolResult) []string {
+	names := make([]string, len(results))
+	for i, r := range results {
+		names[i] = r.Tool
+	}
+	return names
+}
+
+func init() {
+	allCmd.Flags().StringVarP(&reportFormat, "format", "f", "text", "Output format: text|json|ndjson|markdown|gha|junit|csv|rdjson|patch|template")
+	allCmd.Flags().BoolVar(&estimateCost, "estimate-cost", false, "Estimate monthly costs of Terraform and CloudFormation resources")
+	scanCmd.AddCommand(allCmd)
+}
@@ -1,59 +1,53 @@
 package cmd
 
 import (
-	"fmt"
-	"strings"
-
 	"github.com/spf13/cobra"
 
 	"github.com/salchaD-27/infra-check/internal/ansible"
+	"github.com/salchaD-27/infra-check/internal/finding"
 	"github.com/salchaD-27/infra-check/internal/report"
 )
 
 var ansibleOutputFormat string
 
+var escalationMap bool
+
+var ansibleStrict bool
+
 var ansibleCmd = &cobra.Command{
-	Use:   "ansible [path]",
-	Short: "Scan Ansible playbooks in the specified directory",
-	Args:  cobra.ExactArgs(1),
+	Use:   "ansible <path>...",
+	Short: "Scan Ansible playbooks in the specified directories or files",
+	Args:  cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		path := args[0]
-
-		findings, err := ansible.Scan(path)
+		findings, err := scanPaths("ansible", args, emitting(func(target string) ([]finding.Finding, error) {
+			findings, err := ansible.ScanWithOptions(target, ansible.Options{SkipBecomeWarnings: escalationMap, Strict: ansibleStrict})
+			if err != nil {
+				return nil, err
+			}
+			return withCustomRules("ansible", target, findings)
+		}))
 		if err != nil {
 			return err
 		}
 
-		switch strings.ToLower(reportFormat) {
-		case "json":
-			out, err := report.ExportJSON(findings)
-			if err != nil {
-				return err
-			}
-			fmt.Println(out)
-		case "markdown":
-			out, err := report.ExportMarkdown(findings)
-			if err != nil {
-				return err
-			}
-			fmt.Println(out)
-		case "gha":
-			out, err := report.ExportGitHubActions(findings)
+		if !escalationMap {
+			return printFindings(findings)
+		}
+		var entries []ansible.Escalation
+		for _, path := range args {
+			found, err := ansible.EscalationMap(path)
 			if err != nil {
 				return err
 			}
-			fmt.Print(out)
-		default: // plain text
-			for _, f := range findings {
-				fmt.Printf("[%s] %s: %s\n", f.Severity, f.File, f.Message)
-			}
+			entries = append(entries, found...)
 		}
-
-		return nil
+		return printReport(findings, []report.Section{ansible.EscalationSection(entries)})
 	},
 }
 
 func init() {
-	ansibleCmd.Flags().StringVarP(&reportFormat, "format", "f", "text", "Output format: text|json|markdown|gha")
+	ansibleCmd.Flags().StringVarP(&reportFormat, "format", "f", "text", "Output format: text|json|ndjson|markdown|gha|junit|csv|rdjson|patch|template")
+	ansibleCmd.Flags().BoolVar(&escalationMap, "escalation-map", false, "Summarise effective privilege per play/role instead of per-task become warnings")
+	ansibleCmd.Flags().BoolVar(&ansibleStrict, "strict", false, "Report play, block and task keys that are not Ansible keywords, such as a misspelled 'when'")
 	scanCmd.AddCommand(ansibleCmd)
 }
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/salchaD-27/infra-check/internal/cdk"
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// cdkCmd checks the templates `cdk synth` wrote, naming the constructs behind findings
+var cdkCmd = &cobra.Command{
+	Use:   "cdk [cdk.out dir]...",
+	Short: "Scan the CloudFormation templates of a CDK cloud assembly (cdk synth output)",
+	Long: `Reads the cloud assembly 'cdk synth' writes (cdk.out by default), including the
+assemblies of stages nested in it, and runs the CloudFormation checks on every stack
+template. Findings are reported on the template and name the construct path of the
+resource, from its aws:cdk:path metadata or the assembly manifest. Errors, warnings
+and infos constructs attach with Annotations are reported too.`,
+	Args: cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dirs := args
+		if len(dirs) == 0 {
+			dirs = []string{cdk.DefaultDir}
+		}
+
+		findings, err := scanPaths("cdk", dirs, emitting(func(target string) ([]finding.Finding, error) {
+			findings, err := cdk.Scan(target)
+			if err != nil {
+				return nil, err
+			}
+			return withCustomRules("cdk", target, findings)
+		}))
+		if err != nil {
+			return err
+		}
+
+		return printFindings(findings)
+	},
+}
+
+func init() {
+	cdkCmd.Flags().StringVarP(&reportFormat, "format", "f", "text", "Output format: text|json|ndjson|markdown|gha|junit|csv|rdjson|patch|template")
+	scanCmd.AddCommand(cdkCmd)
+}
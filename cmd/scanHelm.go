@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/helm"
+)
+
+var helmValuesFiles []string
+
+// helmCmd renders a chart and runs the Kubernetes checks on the output
+var helmCmd = &cobra.Command{
+	Use:   "helm <chart-dir>...",
+	Short: "Render Helm charts and scan the resulting Kubernetes manifests",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		findings, err := scanPaths("helm", args, emitting(func(target string) ([]finding.Finding, error) {
+			findings, err := helm.Scan(target, helmValuesFiles)
+			if err != nil {
+				return nil, err
+			}
+			return withCustomRules("helm", target, findings)
+		}))
+		if err != nil {
+			return err
+		}
+
+		return printFindings(findings)
+	},
+}
+
+func init() {
+	helmCmd.Flags().StringVarP(&reportFormat, "format", "f", "text", "Output format: text|json|ndjson|markdown|gha|junit|csv|rdjson|patch|template")
+	helmCmd.Flags().StringSliceVar(&helmValuesFiles, "values", nil, "Values file to overlay on the chart defaults (repeatable)")
+	scanCmd.AddCommand(helmCmd)
+}
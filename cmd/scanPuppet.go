@@ -1,65 +1,36 @@
 package cmd
 
 import (
-	"fmt"
-	"strings"
-
 	"github.com/spf13/cobra"
-	// "github.com/salchaD-27/infra-check/internal/finding"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
 	"github.com/salchaD-27/infra-check/internal/puppet"
-	"github.com/salchaD-27/infra-check/internal/report"
 )
 
-var reportFormat string
-
-var puppetOutputFormat string
+var withPuppetLint bool
 
 var puppetCmd = &cobra.Command{
-	Use:   "puppet [path]",
-	Short: "Scan Puppet manifests in the specified directory",
-	Args:  cobra.ExactArgs(1),
+	Use:   "puppet <path>...",
+	Short: "Scan Puppet manifests, templates and Hiera data in the specified directories or files",
+	Args:  cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		path := args[0]
-
-		findings, err := puppet.Scan(path)
-		if err != nil {
-			return err
-		}
-
-		// Export the findings in the requested format
-		switch strings.ToLower(reportFormat) {
-		case "json":
-			out, err := report.ExportJSON(findings)
-			if err != nil {
-				return err
-			}
-			fmt.Println(out)
-
-		case "markdown":
-			out, err := report.ExportMarkdown(findings)
-			if err != nil {
-				return err
-			}
-			fmt.Println(out)
-
-		case "gha":
-			out, err := report.ExportGitHubActions(findings)
+		findings, err := scanPaths("puppet", args, emitting(func(target string) ([]finding.Finding, error) {
+			findings, err := puppet.ScanWithOptions(target, puppet.Options{PuppetLint: withPuppetLint})
 			if err != nil {
-				return err
-			}
-			fmt.Print(out)
-
-		default: // plain text
-			for _, f := range findings {
-				fmt.Printf("[%s] %s: %s\n", f.Severity, f.File, f.Message)
+				return nil, err
 			}
+			return withCustomRules("puppet", target, findings)
+		}))
+		if err != nil {
+			return err
 		}
 
-		return nil
+		return printFindings(findings)
 	},
 }
 
 func init() {
-	puppetCmd.Flags().StringVarP(&reportFormat, "format", "f", "text", "Output format: text|json|markdown|gha")
+	puppetCmd.Flags().StringVarP(&reportFormat, "format", "f", "text", "Output format: text|json|ndjson|markdown|gha|junit|csv|rdjson|patch|template")
+	puppetCmd.Flags().BoolVar(&withPuppetLint, "with-puppet-lint", false, "Also run the external puppet-lint tool (must be in PATH)")
 	scanCmd.AddCommand(puppetCmd)
 }
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salchaD-27/infra-check/internal/engine"
+	"github.com/salchaD-27/infra-check/internal/gitrepo"
+	"github.com/salchaD-27/infra-check/internal/report"
+	"github.com/salchaD-27/infra-check/internal/rules"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// repoCmd represents the scan repo command
+var repoCmd = &cobra.Command{
+	Use:   "repo <url>[@ref]",
+	Short: "Clone a remote git repository and scan all supported IaC content in it",
+	Long: `Makes a shallow clone of a branch or tag (the default branch without @ref) into a
+temporary directory, scans it like 'scan all' and removes the clone, e.g. to audit a
+third-party module before adopting it:
+
+  infra-check scan repo https://github.com/org/terraform-aws-vpc@v5.1.0
+
+Findings are reported relative to the repository root. Only https and ssh URLs are
+accepted; git's own credential helpers and ssh keys are used for private repositories.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo, ref := gitrepo.Split(args[0])
+		if err := gitrepo.Check(repo, ref); err != nil {
+			return err
+		}
+		if scoped() {
+			return fmt.Errorf("--diff and --staged cannot be used with scan repo")
+		}
+		if watchMode {
+			return fmt.Errorf("--watch cannot be used with scan repo")
+		}
+		if fixMode {
+			return fmt.Errorf("--fix cannot be used with scan repo; clone the repository and fix the clone")
+		}
+		if recordHistory && historyFile == "" {
+			return fmt.Errorf("--record needs --history-db with scan repo, as the clone is removed after the scan")
+		}
+		custom, err := rules.Load(rulesDir)
+		if err != nil {
+			return err
+		}
+
+		tmp, err := os.MkdirTemp("", "infra-check-repo-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmp)
+		notice("Cloning %s", args[0])
+		if err := gitrepo.Clone(cmd.Context(), repo, ref, tmp); err != nil {
+			return err
+		}
+
+		// findings are reported relative to the repository root, which path classifiers
+		// and excludes also refer to
+		defer scanner.Mount(tmp, "")()
+		startScan()
+		results, err := engine.RunAll(tmp, custom)
+		if err != nil {
+			return err
+		}
+		countFiles(tmp, nil, tools(results)...)
+		if len(results) == 0 {
+			return fmt.Errorf("no supported IaC content found in %s", args[0])
+		}
+		findings, sections, err := withCostEstimate([]string{tmp}, engine.Merge(results), []report.Section{engine.SummarySection(results, cfg)})
+		if err != nil {
+			return err
+		}
+		return printReport(findings, sections)
+	},
+}
+
+func init() {
+	repoCmd.Flags().StringVarP(&reportFormat, "format", "f", "text", "Output format: text|json|ndjson|markdown|gha|junit|csv|rdjson|patch|template")
+	repoCmd.Flags().BoolVar(&estimateCost, "estimate-cost", false, "Estimate monthly costs of Terraform and CloudFormation resources")
+	scanCmd.AddCommand(repoCmd)
+}
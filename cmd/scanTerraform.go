@@ -1,64 +1,58 @@
 package cmd
 
 import (
-	"fmt"
-	"strings"
-
 	"github.com/spf13/cobra"
 
+	"github.com/salchaD-27/infra-check/internal/finding"
 	"github.com/salchaD-27/infra-check/internal/report"
 	"github.com/salchaD-27/infra-check/internal/terraform"
 )
 
 var outputFormat string
 
+var credentialReport bool
+
 // terraformCmd represents the terraform scan command
 var terraformCmd = &cobra.Command{
-	Use:   "terraform [path]",
-	Short: "Scan Terraform files in the specified directory",
-	Args:  cobra.ExactArgs(1),
+	Use:   "terraform <path>...",
+	Short: "Scan Terraform files in the specified directories or files",
+	Args:  cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		path := args[0]
-
-		findings, err := terraform.Scan(path)
+		findings, err := scanPaths("terraform", args, emitting(func(target string) ([]finding.Finding, error) {
+			findings, err := terraform.Scan(target)
+			if err != nil {
+				return nil, err
+			}
+			return withCustomRules("terraform", target, findings)
+		}))
 		if err != nil {
 			return err
 		}
 
-		switch strings.ToLower(reportFormat) {
-		case "json":
-			out, err := report.ExportJSON(findings)
-			if err != nil {
-				return err
-			}
-			fmt.Println(out)
-
-		case "markdown":
-			out, err := report.ExportMarkdown(findings)
-			if err != nil {
-				return err
-			}
-			fmt.Println(out)
-
-		case "gha":
-			out, err := report.ExportGitHubActions(findings)
-			if err != nil {
-				return err
-			}
-			fmt.Print(out)
-
-		default:
-			for _, f := range findings {
-				fmt.Printf("[%s] %s: %s\n", f.Severity, f.File, f.Message)
+		var sections []report.Section
+		if credentialReport {
+			var sources []terraform.CredentialSource
+			for _, path := range args {
+				found, _, err := terraform.AuditCredentials(path)
+				if err != nil {
+					return err
+				}
+				sources = append(sources, found...)
 			}
+			sections = append(sections, terraform.CredentialSection(sources))
 		}
-
-		return nil
+		findings, sections, err = withCostEstimate(args, findings, sections)
+		if err != nil {
+			return err
+		}
+		return printReport(findings, sections)
 	},
 }
 
 func init() {
-	terraformCmd.Flags().StringVarP(&reportFormat, "format", "f", "text", "Output format: text|json|markdown|gha")
+	terraformCmd.Flags().StringVarP(&reportFormat, "format", "f", "text", "Output format: text|json|ndjson|markdown|gha|junit|csv|rdjson|patch|template")
+	terraformCmd.Flags().BoolVar(&credentialReport, "credential-report", false, "Add a per-stack credential sources section to the report")
+	terraformCmd.Flags().BoolVar(&estimateCost, "estimate-cost", false, "Estimate monthly resource costs from the bundled pricing table")
 
 	// Cobra supports Persistent Flags which will work for this command
 	// and all subcommands, e.g.:
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/terraform"
+)
+
+var stateBackendDir string
+
+// tfstateCmd audits local state files or the remote state of a configuration
+var tfstateCmd = &cobra.Command{
+	Use:   "tfstate [path]...",
+	Short: "Audit Terraform state for stored secrets, exposed outputs and drift",
+	Long: `Audit terraform.tfstate files under path, or with --backend the remote state
+of an initialised configuration (fetched with 'terraform state pull'), for
+secrets stored in state, outputs exposing them without sensitive = true,
+and resources that drifted from or were removed from the configuration.`,
+	Args: cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var findings []finding.Finding
+		var err error
+		switch {
+		case stateBackendDir != "":
+			findings, err = auditRemoteState(stateBackendDir)
+		case len(args) > 0:
+			findings, err = scanPaths("tfstate", args, emitting(func(target string) ([]finding.Finding, error) {
+				findings, err := terraform.ScanState(target)
+				if err != nil {
+					return nil, err
+				}
+				return withCustomRules("tfstate", target, findings)
+			}))
+		default:
+			return fmt.Errorf("give a state file or directory, or --backend with a configuration directory")
+		}
+		if err != nil {
+			return err
+		}
+		return printFindings(findings)
+	},
+}
+
+// auditRemoteState pulls the state of the configuration in dir through its configured backend.
+func auditRemoteState(dir string) ([]finding.Finding, error) {
+	out, err := exec.Command("terraform", "-chdir="+dir, "state", "pull").Output()
+	if err != nil {
+		if exit, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("terraform state pull in %s failed: %s", dir, exit.Stderr)
+		}
+		return nil, fmt.Errorf("terraform state pull in %s: %v", dir, err)
+	}
+	return terraform.AuditState(dir+" (remote state)", out, dir)
+}
+
+func init() {
+	tfstateCmd.Flags().StringVarP(&reportFormat, "format", "f", "text", "Output format: text|json|ndjson|markdown|gha|junit|csv|rdjson|patch|template")
+	tfstateCmd.Flags().StringVar(&stateBackendDir, "backend", "", "Initialised configuration directory whose remote state is pulled and audited")
+	scanCmd.AddCommand(tfstateCmd)
+}
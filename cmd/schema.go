@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salchaD-27/infra-check/pkg/schema"
+)
+
+// schemaCmd prints the JSON schema of the --format json output
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON schema of the JSON report format",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Print(string(schema.ResultV1))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
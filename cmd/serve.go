@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salchaD-27/infra-check/internal/rules"
+	"github.com/salchaD-27/infra-check/internal/server"
+)
+
+var (
+	serveAddr      string
+	serveWorkers   int
+	serveMaxUpload int64
+	serveTimeout   time.Duration
+	serveRetention time.Duration
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run infra-check as an HTTP scan service",
+	Long: `Serve runs a REST API for scanning code without installing the CLI:
+
+  POST /scan          scan a tar or tar.gz archive sent as the request body, or
+                      the repository in a JSON body {"repo": "https://...", "ref": "main"}
+  GET  /results/{id}  the scan's status and, once done, its JSON result
+
+Scans are queued and run like 'scan all', with the custom rules of --rules-dir
+and the project config applied. Results are kept in memory for --retention.
+The API has no authentication of its own; run it behind a proxy that adds it.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		custom, err := rules.Load(rulesDir)
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		s := server.New(ctx, server.Options{
+			Rules:     custom,
+			Config:    cfg,
+			MaxUpload: serveMaxUpload << 20,
+			Workers:   serveWorkers,
+			Timeout:   serveTimeout,
+			Retention: serveRetention,
+		})
+		srv := &http.Server{Addr: serveAddr, Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+
+		errc := make(chan error, 1)
+		go func() { errc <- srv.ListenAndServe() }()
+		fmt.Fprintf(os.Stderr, "Serving the scan API on %s\n", serveAddr)
+		select {
+		case err := <-errc:
+			return err
+		case <-ctx.Done():
+		}
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdown); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	},
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddr, "addr", ":8080", "Address to listen on")
+	serveCmd.Flags().IntVar(&serveWorkers, "workers", 2, "Scans run at once")
+	serveCmd.Flags().Int64Var(&serveMaxUpload, "max-upload", 64, "Largest accepted archive, in MiB; archives may unpack to 8 times this")
+	serveCmd.Flags().DurationVar(&serveTimeout, "timeout", 5*time.Minute, "Time limit for one scan, including cloning the repository")
+	serveCmd.Flags().DurationVar(&serveRetention, "retention", time.Hour, "How long finished results are kept")
+	serveCmd.Flags().StringVar(&rulesDir, "rules-dir", rules.DefaultDir, "Directory containing custom rule files")
+	rootCmd.AddCommand(serveCmd)
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/salchaD-27/infra-check/internal/attest"
+	"github.com/salchaD-27/infra-check/pkg/schema"
+)
+
+var (
+	signKey         string
+	attestationFile string
+	attestCommit    string
+)
+
+// signResult writes a signed attestation over the JSON result exactly as printed.
+func signResult(result []byte) error {
+	key, err := attest.LoadPrivateKey(signKey)
+	if err != nil {
+		return err
+	}
+	rulesDigest, err := attest.RulesDigest(rulesDir)
+	if err != nil {
+		return err
+	}
+
+	pred := attest.Predicate{
+		Version:       version,
+		SchemaVersion: schema.Version,
+		Commit:        attestCommit,
+		ScannedAt:     time.Now().UTC(),
+	}
+	if pred.Commit == "" {
+		pred.Commit = headCommit()
+	}
+	if rulesDigest != "" {
+		pred.RuleBundle = attest.RuleBundle{Path: rulesDir, Digest: rulesDigest}
+	}
+
+	envelope, err := attest.Sign(result, "infra-check-result.json", pred, key)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(attestationFile, envelope, 0o644); err != nil {
+		return err
+	}
+	notice("Wrote signed attestation to %s", attestationFile)
+	return nil
+}
+
+// headCommit returns the commit checked out in the working directory, or "" outside a git work tree.
+func headCommit() string {
+	out, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
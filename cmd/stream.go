@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/salchaD-27/infra-check/internal/engine"
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/profile"
+	"github.com/salchaD-27/infra-check/internal/report"
+)
+
+// streamOutput is the --stream flag of the scan commands.
+var streamOutput bool
+
+// streaming is the report of a --stream scan, once it started.
+var streaming *findingStream
+
+// findingStream reports the findings of a --stream scan as the scanners emit them, keeping
+// counts for the summary and --fail-on rather than the findings.
+type findingStream struct {
+	mu       sync.Mutex
+	pipeline *engine.Pipeline
+	keep     func(finding.Finding) bool
+	profiles []*profile.Profile
+	write    func(finding.Finding) error
+	tally    report.Tally
+	byTool   map[string]map[finding.Severity]int
+	err      error
+}
+
+// startStream starts the report of a --stream scan, or returns nil without --stream. The
+// flags needing every finding at once are refused.
+func startStream() (*findingStream, error) {
+	if !streamOutput || streaming != nil {
+		return streaming, nil
+	}
+	format := strings.ToLower(reportFormat)
+	if format != "text" && format != "" && format != "ndjson" {
+		return nil, fmt.Errorf("--stream writes the text and ndjson formats only, not %s", reportFormat)
+	}
+	for _, c := range []struct {
+		flag string
+		set  bool
+	}{
+		{"--compare-to", compareTo != ""}, {"--fix", fixMode}, {"--watch", watchMode}, {"--incremental", incrementalMode},
+		{"--count-only", countOnly}, {"--sign", signKey != ""}, {"--record", recordHistory},
+		{"--publish, --notify, --webhook or --metrics-*", len(publishTo) > 0 || len(notifyTo) > 0 || webhookURL != "" || metricsFile != "" || metricsPush != ""},
+	} {
+		if c.set {
+			return nil, fmt.Errorf("--stream reports findings as they are found and cannot be combined with %s, which needs all of them", c.flag)
+		}
+	}
+	threshold, err := severityFlag("--min-severity", minSeverity)
+	if err != nil {
+		return nil, err
+	}
+	bundles, err := selectedProfiles(profileName, complianceName)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &findingStream{
+		pipeline: engine.NewPipeline(cfg, !noDedupe, snippetContext),
+		profiles: bundles,
+		byTool:   make(map[string]map[finding.Severity]int),
+	}
+	if threshold != "" {
+		s.keep = func(f finding.Finding) bool { return severityOrder[f.Severity] >= severityOrder[threshold] }
+	}
+	if format == "ndjson" {
+		s.write = report.NewNDJSONStream(os.Stdout).Write
+	} else {
+		s.write = report.NewTextStream(os.Stdout, useColor()).Write
+	}
+	streaming = s
+	return s, nil
+}
+
+// emit reports f unless it is suppressed, disabled, outside the --profile or --compliance
+// framework, a duplicate or below --min-severity. Scanners running concurrently may call it at once.
+func (s *findingStream) emit(f finding.Finding) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, ok := s.pipeline.Process(reported(f))
+	if !ok {
+		return
+	}
+	if f, ok = inProfiles(f, s.profiles); !ok {
+		return
+	}
+	if f.Scanner != "" {
+		if s.byTool[f.Scanner] == nil {
+			s.byTool[f.Scanner] = make(map[finding.Severity]int)
+		}
+		s.byTool[f.Scanner][f.Severity]++
+	}
+	if f, ok = s.pipeline.Report(f, s.keep); !ok {
+		return
+	}
+	s.tally.Add(f)
+	if err := s.write(f); err != nil && s.err == nil {
+		s.err = err
+	}
+}
+
+// finish reports the findings left, such as cost estimates added after the scan, then the
+// sections and summary, and applies --fail-on.
+func (s *findingStream) finish(findings []finding.Finding, sections []report.Section) error {
+	progress.finish()
+	for _, f := range findings {
+		s.emit(f)
+	}
+	if s.err != nil {
+		return s.err
+	}
+	summary := s.tally.Summary(scanStats.files, time.Since(scanStats.started))
+	if strings.ToLower(reportFormat) != "ndjson" && !quiet {
+		fmt.Print(report.ExportTextSections(append(reportedSections(sections), summary.Sections()...)))
+	}
+
+	threshold, err := failThreshold()
+	if err != nil || threshold == "" {
+		return err
+	}
+	count := 0
+	for sev, n := range summary.BySeverity {
+		if severityOrder[finding.Severity(sev)] >= severityOrder[threshold] {
+			count += n
+		}
+	}
+	return failedOn(count, threshold)
+}
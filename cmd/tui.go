@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salchaD-27/infra-check/internal/engine"
+	"github.com/salchaD-27/infra-check/internal/rules"
+	"github.com/salchaD-27/infra-check/internal/tui"
+)
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui [path]",
+	Short: "Browse, filter and suppress findings in a terminal UI",
+	Long: `Tui scans path like 'scan all' and lists the findings grouped by file or by
+severity. Open a finding in $EDITOR with enter, show only the findings of one
+rule with r or filter by text with /, and suppress a finding with s, which
+writes an infra-check:ignore comment above its line.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+		if info, err := os.Stdout.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+			return fmt.Errorf("tui needs a terminal; use 'infra-check scan all %s' for reports", path)
+		}
+
+		custom, err := rules.Load(rulesDir)
+		if err != nil {
+			return err
+		}
+		results, err := engine.RunAll(path, custom)
+		if err != nil {
+			return err
+		}
+		for _, r := range results {
+			if r.Err != nil {
+				fmt.Fprintf(os.Stderr, "%s scan failed: %v\n", r.Tool, r.Err)
+			}
+		}
+		return tui.Run(engine.Process(engine.Dedupe(engine.Suppress(engine.Merge(results))), cfg))
+	},
+}
+
+func init() {
+	tuiCmd.Flags().StringVar(&rulesDir, "rules-dir", rules.DefaultDir, "Directory containing custom rule files")
+	rootCmd.AddCommand(tuiCmd)
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salchaD-27/infra-check/internal/attest"
+)
+
+var verifyKey string
+
+// verifyCmd checks a report against the attestation written by --sign
+var verifyCmd = &cobra.Command{
+	Use:   "verify [report.json]",
+	Short: "Verify a JSON report against its signed attestation",
+	Long: `Verify checks that the attestation written by 'scan ... --format json --sign'
+is signed by the given public key and covers exactly the report file, then
+prints the infra-check version, rule bundle and commit it attests.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pub, err := attest.LoadPublicKey(verifyKey)
+		if err != nil {
+			return err
+		}
+		result, err := os.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		envelope, err := os.ReadFile(attestationFile)
+		if err != nil {
+			return err
+		}
+
+		statement, err := attest.Verify(envelope, result, pub)
+		if err != nil {
+			return fmt.Errorf("%s: %v", args[0], err)
+		}
+		p := statement.Predicate
+		fmt.Printf("Verified %s\n", args[0])
+		fmt.Printf("  infra-check version: %s\n", p.Version)
+		fmt.Printf("  schema version:      %s\n", p.SchemaVersion)
+		fmt.Printf("  commit:              %s\n", orNone(p.Commit))
+		fmt.Printf("  rule bundle:         %s\n", orNone(p.RuleBundle.Digest))
+		fmt.Printf("  scanned at:          %s\n", p.ScannedAt.Format("2006-01-02T15:04:05Z07:00"))
+		return nil
+	},
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+func init() {
+	verifyCmd.Flags().StringVar(&verifyKey, "key", "", "Ed25519 public key (PEM) the attestation must be signed with")
+	verifyCmd.Flags().StringVar(&attestationFile, "attestation", "infra-check.intoto.json", "Attestation file to verify")
+	verifyCmd.MarkFlagRequired("key")
+	rootCmd.AddCommand(verifyCmd)
+}
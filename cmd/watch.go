@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+	"github.com/salchaD-27/infra-check/internal/watch"
+)
+
+// watchMode is the --watch flag shared by the scan commands.
+var watchMode bool
+
+// watching is set while a scan command runs under --watch. The first run reports as
+// usual; later runs only print the findings that appeared or were resolved.
+var watching *watchState
+
+type watchState struct {
+	previous []finding.Finding
+	rerun    bool
+	changed  []string
+}
+
+// enableWatch makes c re-run when the files it scans change, when --watch is given.
+func enableWatch(c *cobra.Command) {
+	run := c.RunE
+	if run == nil {
+		return
+	}
+	c.RunE = func(cmd *cobra.Command, args []string) error {
+		if !watchMode {
+			return run(cmd, args)
+		}
+		if format := strings.ToLower(reportFormat); format != "text" && format != "" {
+			return fmt.Errorf("--watch prints text output and cannot be combined with --format %s", reportFormat)
+		}
+		if signKey != "" {
+			return fmt.Errorf("--watch cannot be combined with --sign")
+		}
+		if countOnly {
+			return fmt.Errorf("--watch cannot be combined with --count-only")
+		}
+		roots := []string{"."}
+		if len(args) > 0 {
+			roots = args
+		}
+
+		watching = &watchState{}
+		defer func() { watching = nil }()
+		if err := run(cmd, args); err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		notice("Watching %s for changes (Ctrl+C to stop)", strings.Join(roots, ", "))
+		return watch.Run(ctx, roots, scannedFile, func(changed []string) {
+			watching.rerun, watching.changed = true, changed
+			if err := run(cmd, args); err != nil {
+				fmt.Printf("[%s] Scan failed: %v\n", time.Now().Format("15:04:05"), err)
+			}
+		})
+	}
+}
+
+// scannedFile reports whether any scanner reads p, so editor swap and backup files do
+// not trigger a re-scan.
+func scannedFile(p string) bool {
+	base := filepath.Base(p)
+	if strings.HasPrefix(base, ".") || strings.HasSuffix(base, "~") {
+		return false
+	}
+	for _, s := range scanner.All() {
+		if s.FileMatcher()(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// report prints the difference between findings and the previous run's, and remembers
+// findings for the next one. It reports whether the run was a re-run, in which case the
+// caller prints nothing else.
+func (w *watchState) report(findings []finding.Finding) bool {
+	defer func() { w.previous = findings }()
+	if !w.rerun {
+		return false
+	}
+
+	key := func(f finding.Finding) string {
+		// line numbers shift while editing, so a finding that only moved is not news
+		return strings.Join([]string{string(f.Severity), f.File, f.Environment, f.Message}, "\x00")
+	}
+	before := make(map[string]int)
+	for _, f := range w.previous {
+		before[key(f)]++
+	}
+	after := make(map[string]int)
+	for _, f := range findings {
+		after[key(f)]++
+	}
+	var added, resolved []finding.Finding
+	for _, f := range findings {
+		if k := key(f); before[k] > 0 {
+			before[k]--
+		} else {
+			added = append(added, f)
+		}
+	}
+	for _, f := range w.previous {
+		if k := key(f); after[k] > 0 {
+			after[k]--
+		} else {
+			resolved = append(resolved, f)
+		}
+	}
+
+	names := make([]string, len(w.changed))
+	for i, p := range w.changed {
+		names[i] = filepath.ToSlash(p)
+	}
+	stamp := time.Now().Format("15:04:05")
+	if len(added) == 0 && len(resolved) == 0 {
+		fmt.Printf("[%s] %s changed: no new or resolved findings (%d total)\n", stamp, strings.Join(names, ", "), len(findings))
+		return true
+	}
+	fmt.Printf("[%s] %s changed: %d new, %d resolved (%d total)\n", stamp, strings.Join(names, ", "), len(added), len(resolved), len(findings))
+	for _, f := range added {
+		fmt.Printf("+ [%s] %s: %s\n", f.Severity, f.Location(), f.Message)
+	}
+	for _, f := range resolved {
+		fmt.Printf("- [%s] %s: %s\n", f.Severity, f.Location(), f.Message)
+	}
+	return true
+}
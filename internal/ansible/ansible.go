@@ -6,19 +6,35 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v3"
 
 	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/scanner"
 )
 
 type Task map[string]interface{} // a map representing an Ansible task
 
 type Play struct { // represents an Ansible "play" (the unit in a playbook file).
-	Hosts interface{}            `yaml:"hosts"` // required field check
-	Tasks []Task                 `yaml:"tasks"`
-	Vars  map[string]interface{} `yaml:"vars,omitempty"` // Add this field
+	Name       string                 `yaml:"name,omitempty"`
+	Hosts      interface{}            `yaml:"hosts"` // required field check
+	Become     interface{}            `yaml:"become,omitempty"`
+	BecomeUser string                 `yaml:"become_user,omitempty"`
+	RemoteUser string                 `yaml:"remote_user,omitempty"`
+	Tasks      []Task                 `yaml:"tasks"`
+	Vars       map[string]interface{} `yaml:"vars,omitempty"` // Add this field
+	VarsFiles  []interface{}          `yaml:"vars_files,omitempty"`
+	PreTasks   []Task                 `yaml:"pre_tasks,omitempty"`
+	PostTasks  []Task                 `yaml:"post_tasks,omitempty"`
+	Roles      []interface{}          `yaml:"roles,omitempty"`
+	Handlers   []Task                 `yaml:"handlers,omitempty"`
+	NoLog      interface{}            `yaml:"no_log,omitempty"`
+
+	// import_playbook entries share the play list
+	ImportPlaybook     interface{} `yaml:"import_playbook,omitempty"`
+	ImportPlaybookFQCN interface{} `yaml:"ansible.builtin.import_playbook,omitempty"`
 }
 
 // FindingSeverity types
@@ -79,23 +95,63 @@ func containsSecretKeyword(s string) bool {
 // "'become' is false in task (possible privilege issue)"
 
 func Scan(path string) ([]finding.Finding, error) {
+	return ScanWithOptions(path, Options{})
+}
+
+// Options tune the Ansible scan.
+type Options struct {
+	// SkipBecomeWarnings drops the per-task 'become' warnings, e.g. when the
+	// escalation map summarises privilege levels instead.
+	SkipBecomeWarnings bool
+
+	// Strict checks play, block and task keys against the keywords Ansible knows, reporting
+	// misspellings such as whne that Ansible only rejects when it runs the playbook.
+	Strict bool
+}
+
+// ScanWithOptions is Scan with tunable behaviour.
+func ScanWithOptions(path string, opts Options) ([]finding.Finding, error) {
 	var findings []finding.Finding
+	// Roles, keyed by directory, are checked once after every playbook applying them is known.
+	roles := make(map[string]*role)
+	roleUsers := make(map[string]map[string]bool) // role dir -> variables the applying plays reference
+	// Jinja2 templates are checked after the walk, with the variables in scope where tasks render them.
+	var templates []string
+	rendered := make(map[string]*varScope)
+	// Included task files are checked once after the walk; imported playbooks are walked in
+	// execution order from the playbook importing them.
+	taskFiles, imported := collectIncludes(path)
 
 	// 	Walking Filesystem
 	// For every file in path, checks extension (.yml/.yaml).
 	// Ignores directories and files with other extensions.
 	// Read YAML File, file contents.
 	// Parses YAML file into a slice of Play.
-	err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+	err := scanner.Walk(path, func(p string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return err
 		}
 
 		ext := filepath.Ext(p)
+		if ext == ".j2" {
+			templates = append(templates, p)
+			return nil
+		}
 		if ext != ".yml" && ext != ".yaml" {
 			return nil
 		}
 
+		// Role files are scanned through their role, included task files through their includes
+		if dir := roleDirOf(p); dir != "" {
+			if roles[dir] == nil {
+				roles[dir] = loadRole(filepath.Base(dir), dir)
+			}
+			return nil
+		}
+		if _, ok := taskFiles[filepath.Clean(p)]; ok {
+			return nil
+		}
+
 		data, err := ioutil.ReadFile(p)
 		if err != nil {
 			findings = append(findings, finding.Finding{
@@ -106,8 +162,25 @@ func Scan(path string) ([]finding.Finding, error) {
 			return nil
 		}
 
+		// Encrypted files cannot be analysed, and are exactly where secrets belong
+		if isVaultedFile(data) {
+			return nil
+		}
+
+		// Galaxy requirements are dependency lists, not playbooks
+		if isRequirementsFile(p) {
+			findings = append(findings, checkRequirements(p, data)...)
+			return nil
+		}
+
 		var plays []Play
 		if err := yaml.Unmarshal(data, &plays); err != nil {
+			// Vars files (group_vars, host_vars, vars_files) are mappings rather than play lists
+			var vars map[string]interface{}
+			if yaml.Unmarshal(data, &vars) == nil && vars != nil {
+				findings = append(findings, checkPlaintextSecrets(p, "vars file", vars)...)
+				return nil
+			}
 			findings = append(findings, finding.Finding{
 				File:     p,
 				Severity: finding.Error,
@@ -116,6 +189,10 @@ func Scan(path string) ([]finding.Finding, error) {
 			return nil
 		}
 
+		if opts.Strict {
+			findings = append(findings, checkStrictPlays(p, data)...)
+		}
+
 		// Track variables defined and used to detect unused ones
 		definedVars := make(map[string]bool)
 		usedVars := make(map[string]bool)
@@ -131,8 +208,12 @@ func Scan(path string) ([]finding.Finding, error) {
 		// Hardcoded Secret Detection:
 		// If any key in the task or attribute contains a secret keyword and value is a non-empty string, flags it as a potential secret leak.
 		// Variable Usage Tracking:
-		// If a string value contains Ansible variable syntax (e.g., {{ my_var }}), extracts the variable name(s) for usage tracking.
+		// Every templated value of the play is tokenized (see jinja.go) to collect the variables it references.
 		for _, play := range plays {
+			if _, ok := play.importedPlaybook(); ok {
+				continue
+			}
+
 			// Check required field 'hosts'
 			if play.Hosts == nil {
 				findings = append(findings, finding.Finding{
@@ -146,76 +227,106 @@ func Scan(path string) ([]finding.Finding, error) {
 			for varName := range play.Vars {
 				definedVars[varName] = true
 			}
+			findings = append(findings, checkPlaintextSecrets(p, "play vars", play.Vars)...)
 
-			for _, task := range play.Tasks {
-				// Check missing or false 'become'
-				become, exists := task["become"]
-				if !exists {
-					findings = append(findings, finding.Finding{
-						File:     p,
-						Severity: finding.Warning,
-						Message:  "Task missing 'become' field (no privilege escalation specified)",
-					})
-				} else if val, ok := become.(bool); ok && !val {
-					findings = append(findings, finding.Finding{
-						File:     p,
-						Severity: finding.Warning,
-						Message:  "'become' is false in task (possible privilege issue)",
-					})
-				}
+			findings = append(findings, checkTasks(p, play.Tasks, opts)...)
 
-				// Required task field 'name'
-				if _, ok := task["name"]; !ok {
-					findings = append(findings, finding.Finding{
-						File:     p,
-						Severity: finding.Warning,
-						Message:  "Task missing required field 'name'",
-					})
-				}
+			// Needless root escalation, and what tasks pass their modules
+			playUser := play.BecomeUser
+			if playUser == "" {
+				playUser = "root"
+			}
+			for _, section := range [][]Task{play.PreTasks, play.Tasks, play.PostTasks, play.Handlers} {
+				findings = append(findings, checkRootBecome(p, section, playUser, false)...)
+				findings = append(findings, checkModuleArgs(p, section)...)
+			}
+
+			// Credentials passed to modules in tasks that log their arguments
+			playNoLog := noLogEnabled(play.NoLog)
+			for _, section := range [][]Task{play.PreTasks, play.Tasks, play.PostTasks, play.Handlers} {
+				findings = append(findings, checkNoLog(p, section, playNoLog)...)
+			}
 
-				// Check for deprecated module usage (task keys except known keys)
-				for key := range task {
-					if key != "name" && key != "become" && key != "vars" {
-						if msg, deprecated := deprecatedModules[key]; deprecated {
-							findings = append(findings, finding.Finding{
-								File:     p,
-								Severity: finding.Warning,
-								Message:  fmt.Sprintf("Use of deprecated module '%s': %s", key, msg),
-							})
+			// Variables referenced anywhere in the play: vars, tasks, conditions, handlers and rendered templates
+			playUses := make(map[string]bool)
+			for _, src := range playTemplates(play) {
+				for _, v := range src.parse().vars() {
+					usedVars[v] = true
+					playUses[v] = true
+				}
+			}
+			playbookDirs := []string{filepath.Join(filepath.Dir(p), "templates"), filepath.Dir(p)}
+			sections := [][]Task{play.PreTasks, play.Tasks, play.PostTasks, play.Handlers}
+			for _, tasks := range sections {
+				files, _ := includedTasks(p, tasks, []string{filepath.Dir(p)}, make(map[string]bool))
+				for _, f := range files {
+					sections = append(sections, f.tasks)
+					for _, src := range taskTemplates(f.tasks) {
+						for _, v := range src.parse().vars() {
+							usedVars[v] = true
+							playUses[v] = true
 						}
 					}
 				}
-
-				// Detect hardcoded secrets in task attributes
-				for attr, val := range task {
-					attrLower := strings.ToLower(attr)
-					if containsSecretKeyword(attrLower) {
-						if strVal, ok := val.(string); ok && strings.TrimSpace(strVal) != "" {
-							findings = append(findings, finding.Finding{
-								File:     p,
-								Severity: finding.Error,
-								Message:  fmt.Sprintf("Possible hardcoded secret in attribute '%s'", attr),
-							})
-						}
+			}
+			var roleEntries []interface{}
+			roleEntries = append(roleEntries, play.Roles...)
+			for _, tasks := range sections {
+				for _, v := range templateFileVars(tasks, playbookDirs) {
+					usedVars[v] = true
+					playUses[v] = true
+				}
+				for _, task := range roleIncludes(tasks) {
+					if entry, _, _ := includeRole(task); entry != nil {
+						roleEntries = append(roleEntries, entry)
 					}
+				}
+			}
 
-					// Detect usage of variables in string templates "{{ var }}"
-					if strVal, ok := val.(string); ok {
-						if strings.Contains(strVal, "{{") && strings.Contains(strVal, "}}") {
-							// Simple extraction of variables inside {{ }}
-							parts := strings.Split(strVal, "{{")
-							for _, part := range parts[1:] {
-								varName := strings.TrimSpace(strings.Split(part, "}}")[0])
-								if len(varName) > 0 {
-									usedVars[varName] = true
-								}
-							}
-						}
+			// Roles applied by the play: their tasks may read play vars, and the play may read their defaults
+			for _, entry := range roleEntries {
+				r, ok := resolveRole(filepath.Dir(p), entry)
+				if !ok {
+					continue
+				}
+				if roles[r.dir] == nil {
+					roles[r.dir] = r
+				}
+				for _, src := range playTemplates(r.play()) {
+					for _, v := range src.parse().vars() {
+						usedVars[v] = true
 					}
 				}
+				roleTasks := append(append([]Task{}, r.tasks...), r.handlers...)
+				included, _ := r.includes()
+				for _, f := range included {
+					roleTasks = append(roleTasks, f.tasks...)
+				}
+				for _, src := range taskTemplates(roleTasks[len(r.tasks)+len(r.handlers):]) {
+					for _, v := range src.parse().vars() {
+						usedVars[v] = true
+					}
+				}
+				for _, v := range templateFileVars(roleTasks, append(r.templateDirs(), playbookDirs...)) {
+					usedVars[v] = true
+				}
+				if roleUsers[r.dir] == nil {
+					roleUsers[r.dir] = make(map[string]bool)
+				}
+				for v := range playUses {
+					roleUsers[r.dir][v] = true
+				}
 			}
 		}
 
+		// Variables referenced before anything earlier in execution order defines them
+		if !imported[filepath.Clean(p)] {
+			findings = append(findings, checkExecutionOrder(p, plays, rendered)...)
+		}
+
+		// Jinja2 syntax, unknown and deprecated filters, secret defaults
+		findings = append(findings, checkJinja(p, plays)...)
+
 		// Detect unused variables
 		for varName := range definedVars {
 			if !usedVars[varName] {
@@ -230,5 +341,95 @@ func Scan(path string) ([]finding.Finding, error) {
 		return nil
 	})
 
+	dirs := make([]string, 0, len(roles))
+	for dir := range roles {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		findings = append(findings, checkRole(roles[dir], roleUsers[dir], opts)...)
+	}
+	files := make([]string, 0, len(taskFiles))
+	for p := range taskFiles {
+		files = append(files, p)
+	}
+	sort.Strings(files)
+	for _, p := range files {
+		f := taskFiles[p]
+		findings = append(findings, checkTasks(f.path, f.tasks, opts)...)
+		findings = append(findings, checkNoLog(f.path, f.tasks, false)...)
+		findings = append(findings, checkJinja(f.path, []Play{{Tasks: f.tasks}})...)
+		findings = append(findings, checkRootBecome(f.path, f.tasks, "root", false)...)
+		findings = append(findings, checkModuleArgs(f.path, f.tasks)...)
+		if opts.Strict {
+			findings = append(findings, checkStrictTasks(f.path, f.tasks)...)
+		}
+	}
+	for _, p := range templates {
+		findings = append(findings, checkTemplateFile(p, rendered[filepath.Clean(p)])...)
+	}
+
 	return findings, err
 }
+
+// checkTasks runs the per-task checks: privilege escalation, names, deprecated modules
+// and hardcoded secrets in task attributes.
+func checkTasks(p string, tasks []Task, opts Options) []finding.Finding {
+	var findings []finding.Finding
+	for _, task := range tasks {
+		// Check missing or false 'become'
+		become, exists := task["become"]
+		if opts.SkipBecomeWarnings {
+			// summarised by the escalation map instead
+		} else if !exists {
+			findings = append(findings, finding.Finding{
+				File:     p,
+				Severity: finding.Warning,
+				Message:  "Task missing 'become' field (no privilege escalation specified)",
+			})
+		} else if val, ok := become.(bool); ok && !val {
+			findings = append(findings, finding.Finding{
+				File:     p,
+				Severity: finding.Warning,
+				Message:  "'become' is false in task (possible privilege issue)",
+			})
+		}
+
+		// Required task field 'name'
+		if _, ok := task["name"]; !ok {
+			findings = append(findings, finding.Finding{
+				File:     p,
+				Severity: finding.Warning,
+				Message:  "Task missing required field 'name'",
+			})
+		}
+
+		// Check for deprecated module usage (task keys except known keys)
+		for key := range task {
+			if key != "name" && key != "become" && key != "vars" {
+				if msg, deprecated := deprecatedModules[key]; deprecated {
+					findings = append(findings, finding.Finding{
+						File:     p,
+						Severity: finding.Warning,
+						Message:  fmt.Sprintf("Use of deprecated module '%s': %s", key, msg),
+					})
+				}
+			}
+		}
+
+		// Detect hardcoded secrets in task attributes
+		for attr, val := range task {
+			attrLower := strings.ToLower(attr)
+			if containsSecretKeyword(attrLower) {
+				if strVal, ok := val.(string); ok && strings.TrimSpace(strVal) != "" && !isVaulted(strVal) {
+					findings = append(findings, finding.Finding{
+						File:     p,
+						Severity: finding.Error,
+						Message:  fmt.Sprintf("Possible hardcoded secret in attribute '%s'", attr),
+					})
+				}
+			}
+		}
+	}
+	return findings
+}
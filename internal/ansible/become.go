@@ -0,0 +1,86 @@
+package ansible
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/config"
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// becomeRules holds the tasks and modules allowed to become root; see Configure.
+var becomeRules config.BecomeRules
+
+// Configure applies the ansible section of the project configuration to later scans.
+func Configure(cfg *config.Config) {
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	becomeRules = cfg.Ansible.Become
+}
+
+// Modules that only read facts, talk to the control node or remote APIs, or steer the play,
+// and so never need root on the managed host. Names are those of ansible.builtin.
+var unprivilegedModules = toSet(
+	"debug", "set_fact", "set_stats", "assert", "fail", "meta", "pause", "ping",
+	"wait_for", "wait_for_connection", "uri", "include_vars", "add_host", "group_by",
+	"stat", "find", "tempfile",
+)
+
+// taskModule returns the module a task calls, with the ansible.builtin and ansible.legacy
+// prefixes removed, or "" when it has none.
+func taskModule(task Task) string {
+	for _, key := range sortedMapKeys(task) {
+		if key == "action" || key == "local_action" {
+			if s, ok := task[key].(string); ok && s != "" {
+				key = strings.Fields(s)[0]
+			} else if m, ok := asMap(task[key]); ok {
+				key, _ = m["module"].(string)
+			}
+		} else if taskKeywords[key] || strings.HasPrefix(key, "with_") {
+			continue
+		}
+		key = strings.TrimPrefix(key, "ansible.builtin.")
+		return strings.TrimPrefix(key, "ansible.legacy.")
+	}
+	return ""
+}
+
+// checkRootBecome flags tasks that set become themselves, or in a block around them, and
+// so run as root (user is the become_user inherited from the play) although their module
+// does not need it. Escalation set for the whole play is left to the escalation map.
+func checkRootBecome(p string, tasks []Task, user string, become bool) []finding.Finding {
+	var findings []finding.Finding
+	for _, task := range tasks {
+		taskBecome, taskUser := become, user
+		if v, ok := task["become"]; ok {
+			taskBecome = becomeValue(v) == "yes"
+		}
+		if u, ok := task["become_user"].(string); ok && u != "" {
+			taskUser = u
+		}
+		if _, isBlock := task["block"]; isBlock {
+			for _, section := range []string{"block", "rescue", "always"} {
+				findings = append(findings, checkRootBecome(p, toTasks(task[section]), taskUser, taskBecome)...)
+			}
+			continue
+		}
+		if !taskBecome || taskUser != "root" {
+			continue
+		}
+		module := taskModule(task)
+		name, _ := task["name"].(string)
+		if !unprivilegedModules[module] || becomeRules.RootAllowed(module, name) {
+			continue
+		}
+		if name == "" {
+			name = module
+		}
+		findings = append(findings, finding.Finding{
+			File:     p,
+			Severity: finding.Warning,
+			Message:  fmt.Sprintf("Task '%s' becomes root to run '%s', which does not need root; drop become, or list the task in ansible.become.allow_root", name, module),
+		})
+	}
+	return findings
+}
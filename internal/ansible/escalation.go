@@ -0,0 +1,200 @@
+package ansible
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/salchaD-27/infra-check/internal/report"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// Escalation summarises the effective privilege level of one play, or of a role applied by a play.
+type Escalation struct {
+	File        string
+	Play        string
+	Role        string // empty for the play's own tasks
+	RemoteUser  string
+	Become      string   // play (or role) level become: yes, no or the raw templated value
+	Tasks       int      // tasks in the play, blocks expanded
+	Escalated   int      // tasks that effectively run with become
+	BecomeUsers []string // distinct effective become_user values of escalated tasks
+}
+
+// privilege is the become state inherited down the play -> block -> task chain.
+type privilege struct {
+	become     string // "yes", "no" or the raw value when templated
+	becomeUser string
+}
+
+func (p privilege) override(task map[string]interface{}) privilege {
+	if v, ok := task["become"]; ok {
+		p.become = becomeValue(v)
+	}
+	if u, ok := task["become_user"].(string); ok && u != "" {
+		p.becomeUser = u
+	}
+	return p
+}
+
+// EscalationMap walks the playbooks under path and returns the privilege summary of every play and role.
+func EscalationMap(path string) ([]Escalation, error) {
+	var entries []Escalation
+
+	err := scanner.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		ext := filepath.Ext(p)
+		if ext != ".yml" && ext != ".yaml" {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil
+		}
+		var plays []Play
+		if err := yaml.Unmarshal(data, &plays); err != nil {
+			return nil // reported as a parse error by Scan
+		}
+
+		for i, play := range plays {
+			if play.Hosts == nil {
+				continue // not a playbook (vars or task file)
+			}
+			base := privilege{become: "no", becomeUser: "root"}
+			if play.Become != nil {
+				base.become = becomeValue(play.Become)
+			}
+			if play.BecomeUser != "" {
+				base.becomeUser = play.BecomeUser
+			}
+			remoteUser := play.RemoteUser
+			if remoteUser == "" {
+				remoteUser = "(inventory default)"
+			}
+			name := play.Name
+			if name == "" {
+				name = fmt.Sprintf("play #%d (hosts: %v)", i+1, play.Hosts)
+			}
+
+			entry := Escalation{File: p, Play: name, RemoteUser: remoteUser, Become: base.become}
+			users := make(map[string]bool)
+			for _, tasks := range [][]Task{play.PreTasks, play.Tasks, play.PostTasks} {
+				countPrivileges(tasks, base, &entry, users)
+			}
+			entry.BecomeUsers = sortedSet(users)
+			entries = append(entries, entry)
+
+			for _, r := range play.Roles {
+				roleEntry := Escalation{File: p, Play: name, RemoteUser: remoteUser, Become: base.become}
+				roleName := ""
+				switch role := r.(type) {
+				case string:
+					roleName = role
+				case map[string]interface{}:
+					roleName = fmt.Sprint(firstNonNil(role["role"], role["name"]))
+					priv := base.override(role)
+					roleEntry.Become = priv.become
+					if priv.become != "no" {
+						roleEntry.BecomeUsers = []string{priv.becomeUser}
+					}
+				}
+				if roleEntry.BecomeUsers == nil && base.become != "no" {
+					roleEntry.BecomeUsers = []string{base.becomeUser}
+				}
+				roleEntry.Role = roleName
+				entries = append(entries, roleEntry)
+			}
+		}
+		return nil
+	})
+
+	return entries, err
+}
+
+func countPrivileges(tasks []Task, inherited privilege, entry *Escalation, users map[string]bool) {
+	for _, task := range tasks {
+		priv := inherited.override(task)
+		if _, isBlock := task["block"]; isBlock {
+			for _, section := range []string{"block", "rescue", "always"} {
+				countPrivileges(toTasks(task[section]), priv, entry, users)
+			}
+			continue
+		}
+		entry.Tasks++
+		if priv.become != "no" {
+			entry.Escalated++
+			users[priv.becomeUser] = true
+		}
+	}
+}
+
+// becomeValue normalises a become value to yes/no, keeping templated values verbatim.
+func becomeValue(v interface{}) string {
+	switch b := v.(type) {
+	case bool:
+		if b {
+			return "yes"
+		}
+		return "no"
+	case string:
+		if parsed, err := strconv.ParseBool(b); err == nil {
+			return becomeValue(parsed)
+		}
+		switch strings.ToLower(b) {
+		case "yes", "on":
+			return "yes"
+		case "no", "off":
+			return "no"
+		}
+		return b
+	}
+	return fmt.Sprint(v)
+}
+
+func firstNonNil(vals ...interface{}) interface{} {
+	for _, v := range vals {
+		if v != nil {
+			return v
+		}
+	}
+	return ""
+}
+
+func sortedSet(set map[string]bool) []string {
+	out := make([]string, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// EscalationSection renders the escalation map as a report section.
+func EscalationSection(entries []Escalation) report.Section {
+	s := report.Section{
+		Title:   "Privilege Escalation Map",
+		Columns: []string{"File", "Play", "Role", "remote_user", "become", "Escalated tasks", "become_user"},
+	}
+	for _, e := range entries {
+		role := e.Role
+		tasks := fmt.Sprintf("%d/%d", e.Escalated, e.Tasks)
+		if role == "" {
+			role = "-"
+		} else {
+			tasks = "(role tasks)"
+		}
+		users := strings.Join(e.BecomeUsers, ", ")
+		if users == "" {
+			users = "-"
+		}
+		s.Rows = append(s.Rows, []string{e.File, e.Play, role, e.RemoteUser, e.Become, tasks, users})
+	}
+	return s
+}
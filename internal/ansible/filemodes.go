@@ -0,0 +1,72 @@
+package ansible
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/filemode"
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// Modules creating files and directories, with the parameters naming the path, by preference.
+var fileModules = map[string][]string{
+	"file":     {"path", "dest", "name"},
+	"copy":     {"dest"},
+	"template": {"dest"},
+}
+
+// checkFileModes flags file, copy and template tasks, in tasks and the blocks among them,
+// giving what they create a mode any user may write, such as 0777 or 0666.
+func checkFileModes(p string, tasks []Task) []finding.Finding {
+	var findings []finding.Finding
+	for _, task := range tasks {
+		if _, isBlock := task["block"]; isBlock {
+			for _, section := range []string{"block", "rescue", "always"} {
+				findings = append(findings, checkFileModes(p, toTasks(task[section]))...)
+			}
+			continue
+		}
+		module := taskModule(task)
+		keys, ok := fileModules[module]
+		if !ok {
+			continue
+		}
+		params := moduleParams(task)
+		mode, ok := fileMode(params["mode"])
+		if !ok || !filemode.WorldWritable(mode) {
+			continue
+		}
+		target := "its target"
+		for _, key := range keys {
+			if s, ok := params[key].(string); ok && s != "" {
+				target = "'" + s + "'"
+				break
+			}
+		}
+		name, _ := task["name"].(string)
+		if name == "" {
+			name = module
+		}
+		findings = append(findings, finding.Finding{
+			File:     p,
+			Severity: finding.Warning,
+			Message:  fmt.Sprintf("Task '%s' makes %s world-writable (mode %s); any local user can modify it", name, target, mode),
+		})
+	}
+	return findings
+}
+
+// fileMode returns a mode parameter as written: YAML reads an unquoted 0777 as the number
+// 511, which Ansible takes as that octal mode. Templated modes are not decided.
+func fileMode(v interface{}) (string, bool) {
+	switch m := v.(type) {
+	case int:
+		return fmt.Sprintf("%04o", m), true
+	case string:
+		if m == "" || strings.Contains(m, "{{") {
+			return "", false
+		}
+		return m, true
+	}
+	return "", false
+}
@@ -0,0 +1,258 @@
+package ansible
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// Include and import resolution.
+//
+// include_tasks, import_tasks and the legacy include are resolved when they name a literal
+// file: relative to the including file first, then to the playbook (or, in a role, the
+// role's tasks directory), as ansible-playbook looks them up. Included files are not
+// playbooks; each is checked once with the per-task checks and walked in execution order
+// wherever it is included, so variable tracking follows the whole call tree.
+// include_role/import_role and import_playbook are followed the same way. Templated
+// names cannot be resolved and stay opaque.
+
+var includeTasksKeys = []string{
+	"include_tasks", "ansible.builtin.include_tasks",
+	"import_tasks", "ansible.builtin.import_tasks",
+	"include", "ansible.builtin.include",
+}
+
+var includeRoleKeys = []string{
+	"include_role", "ansible.builtin.include_role",
+	"import_role", "ansible.builtin.import_role",
+}
+
+// includedFile is a task file pulled in by an include.
+type includedFile struct {
+	path  string
+	tasks []Task
+}
+
+// includeFile returns the file named by an include_tasks/import_tasks task. ok is false
+// for other tasks; file is "" when the name is templated.
+func includeFile(task Task) (file string, ok bool) {
+	for _, k := range includeTasksKeys {
+		v, present := task[k]
+		if !present {
+			continue
+		}
+		switch val := v.(type) {
+		case string:
+			// the legacy include accepts "file.yml key=value ..."
+			if fields := strings.Fields(val); len(fields) > 0 {
+				file = fields[0]
+			}
+		default:
+			if args, isMap := asMap(val); isMap {
+				file, _ = firstNonNil(args["file"], args["_raw_params"]).(string)
+			}
+		}
+		if strings.Contains(file, "{{") {
+			file = ""
+		}
+		return file, true
+	}
+	return "", false
+}
+
+// includeRole returns the roles:-style entry and tasks_from of an include_role/import_role
+// task. ok is false for other tasks; the entry is nil when the role name is templated.
+func includeRole(task Task) (entry map[string]interface{}, tasksFrom string, ok bool) {
+	for _, k := range includeRoleKeys {
+		v, present := task[k]
+		if !present {
+			continue
+		}
+		args, _ := asMap(v)
+		name, _ := args["name"].(string)
+		tasksFrom, _ = args["tasks_from"].(string)
+		if name == "" || strings.Contains(name, "{{") || strings.Contains(tasksFrom, "{{") {
+			return nil, "", true
+		}
+		return map[string]interface{}{"role": name}, tasksFrom, true
+	}
+	return nil, "", false
+}
+
+// resolveIncludedRole loads the role an include_role/import_role task applies, with its
+// tasks switched to tasks_from when set.
+func resolveIncludedRole(playbookDir string, entry map[string]interface{}, tasksFrom string) (*role, bool) {
+	r, ok := resolveRole(playbookDir, entry)
+	if !ok || tasksFrom == "" {
+		return r, ok
+	}
+	tasksDir := filepath.Join(r.dir, "tasks")
+	p := resolveFile(tasksFrom, []string{tasksDir})
+	if p == "" && filepath.Ext(tasksFrom) == "" {
+		p = resolveFile(tasksFrom+".yml", []string{tasksDir})
+	}
+	tasks, ok := loadTaskFile(p)
+	if !ok {
+		return nil, false
+	}
+	r.tasksFile, r.tasks = p, tasks
+	return r, true
+}
+
+// resolveFile returns the file name refers to, searching dirs in order.
+func resolveFile(name string, dirs []string) string {
+	if name == "" {
+		return ""
+	}
+	candidates := []string{name}
+	if !filepath.IsAbs(name) {
+		candidates = nil
+		for _, dir := range dirs {
+			candidates = append(candidates, filepath.Join(dir, name))
+		}
+	}
+	for _, p := range candidates {
+		if info, err := os.Stat(p); err == nil && !info.IsDir() {
+			return filepath.Clean(p)
+		}
+	}
+	return ""
+}
+
+// loadTaskFile reads a list of tasks. Vaulted and malformed files do not load.
+func loadTaskFile(p string) ([]Task, bool) {
+	if p == "" {
+		return nil, false
+	}
+	data, err := os.ReadFile(p)
+	if err != nil || isVaultedFile(data) {
+		return nil, false
+	}
+	var tasks []Task
+	if err := yaml.Unmarshal(data, &tasks); err != nil {
+		return nil, false
+	}
+	return tasks, true
+}
+
+// includedTasks returns the task files tasks in file from include, directly or through
+// other included files, in execution order; dirs are searched after the including file's
+// directory. seen guards against cycles and files already returned. unresolved reports
+// includes that could not be followed, including role includes.
+func includedTasks(from string, tasks []Task, dirs []string, seen map[string]bool) (files []includedFile, unresolved bool) {
+	for _, task := range tasks {
+		if _, isBlock := task["block"]; isBlock {
+			for _, section := range []string{"block", "rescue", "always"} {
+				nested, u := includedTasks(from, toTasks(task[section]), dirs, seen)
+				files = append(files, nested...)
+				unresolved = unresolved || u
+			}
+			continue
+		}
+		if _, _, isRole := includeRole(task); isRole {
+			unresolved = true
+			continue
+		}
+		name, isInclude := includeFile(task)
+		if !isInclude {
+			continue
+		}
+		p := resolveFile(name, append([]string{filepath.Dir(from)}, dirs...))
+		included, ok := loadTaskFile(p)
+		if !ok {
+			unresolved = true
+			continue
+		}
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		files = append(files, includedFile{path: p, tasks: included})
+		nested, u := includedTasks(p, included, dirs, seen)
+		files = append(files, nested...)
+		unresolved = unresolved || u
+	}
+	return files, unresolved
+}
+
+// roleIncludes returns the include_role/import_role tasks among tasks, including those in blocks.
+func roleIncludes(tasks []Task) []Task {
+	var out []Task
+	for _, task := range tasks {
+		if _, isBlock := task["block"]; isBlock {
+			for _, section := range []string{"block", "rescue", "always"} {
+				out = append(out, roleIncludes(toTasks(task[section]))...)
+			}
+			continue
+		}
+		if _, _, ok := includeRole(task); ok {
+			out = append(out, task)
+		}
+	}
+	return out
+}
+
+// importedPlaybook returns the file an import_playbook entry names. ok is false for
+// plays; file is "" when the name is templated.
+func (play Play) importedPlaybook() (file string, ok bool) {
+	for _, v := range []interface{}{play.ImportPlaybook, play.ImportPlaybookFQCN} {
+		if name, isString := v.(string); isString {
+			if strings.Contains(name, "{{") {
+				return "", true
+			}
+			if fields := strings.Fields(name); len(fields) > 0 {
+				return fields[0], true
+			}
+		}
+	}
+	return "", false
+}
+
+// collectIncludes finds, in the playbooks under path, the task files they include, which
+// are not playbooks, and the playbooks they import, whose execution order is walked from
+// the importing playbook.
+func collectIncludes(path string) (map[string]includedFile, map[string]bool) {
+	taskFiles := make(map[string]includedFile)
+	imported := make(map[string]bool)
+	seen := make(map[string]bool)
+	scanner.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		if ext := filepath.Ext(p); ext != ".yml" && ext != ".yaml" {
+			return nil
+		}
+		if roleDirOf(p) != "" || isRequirementsFile(p) {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil || !IsPlaybook(data) {
+			return nil
+		}
+		var plays []Play
+		if yaml.Unmarshal(data, &plays) != nil {
+			return nil
+		}
+		dir := filepath.Dir(p)
+		for _, play := range plays {
+			if name, ok := play.importedPlaybook(); ok {
+				if target := resolveFile(name, []string{dir}); target != "" {
+					imported[target] = true
+				}
+				continue
+			}
+			for _, tasks := range [][]Task{play.PreTasks, play.Tasks, play.PostTasks, play.Handlers} {
+				files, _ := includedTasks(p, tasks, []string{dir}, seen)
+				for _, f := range files {
+					taskFiles[f.path] = f
+				}
+			}
+		}
+		return nil
+	})
+	return taskFiles, imported
+}
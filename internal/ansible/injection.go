@@ -0,0 +1,109 @@
+package ansible
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// Filters whose output cannot carry shell syntax into a command line.
+var shellSafeFilters = toSet("quote", "int", "float", "bool", "length", "count")
+
+// Variables set by Ansible on the control node, such as paths of the playbook, that no
+// inventory or user input controls.
+var controllerVars = toSet("playbook_dir", "role_path", "inventory_dir", "inventory_file", "ansible_playbook_python")
+
+// checkModuleArgs runs the checks on what tasks pass their modules: package version pins,
+// file modes, and variables interpolated into commands.
+func checkModuleArgs(p string, tasks []Task) []finding.Finding {
+	findings := checkPackagePins(p, tasks)
+	findings = append(findings, checkFileModes(p, tasks)...)
+	return append(findings, checkCommandInjection(p, tasks)...)
+}
+
+// checkCommandInjection flags shell, command and raw tasks, in tasks and the blocks among
+// them, interpolating variables into their command line without | quote. The shell and raw
+// modules hand the line to a shell, which runs whatever commands a value holds; command
+// splits it on spaces, so a value can add arguments. Commands given as argv are safe.
+func checkCommandInjection(p string, tasks []Task) []finding.Finding {
+	var findings []finding.Finding
+	for _, task := range tasks {
+		if _, isBlock := task["block"]; isBlock {
+			for _, section := range []string{"block", "rescue", "always"} {
+				findings = append(findings, checkCommandInjection(p, toTasks(task[section]))...)
+			}
+			continue
+		}
+		module := taskModule(task)
+		if module != "shell" && module != "command" && module != "raw" {
+			continue
+		}
+		line := commandLine(task, module)
+		if line == "" {
+			continue
+		}
+		vars := unquotedVars(line)
+		if len(vars) == 0 {
+			continue
+		}
+		name, _ := task["name"].(string)
+		if name == "" {
+			name = module
+		}
+		msg := fmt.Sprintf("Task '%s' interpolates %s unquoted into the command line of '%s'; a value containing shell syntax runs as commands: add | quote, or use command with argv", name, quoteList(vars), module)
+		if module == "command" {
+			msg = fmt.Sprintf("Task '%s' interpolates %s unquoted into the command line of 'command'; a value containing spaces or dashes adds arguments: add | quote, or pass argv", name, quoteList(vars))
+		}
+		findings = append(findings, finding.Finding{File: p, Severity: finding.Warning, Message: msg})
+	}
+	return findings
+}
+
+// commandLine returns the command line of a shell, command or raw task: its free-form
+// value or its cmd parameter. It returns "" for a command given as argv.
+func commandLine(task Task, module string) string {
+	params := moduleParams(task)
+	if _, ok := params["argv"]; ok {
+		return ""
+	}
+	if cmd, ok := params["cmd"].(string); ok {
+		return cmd
+	}
+	for key, v := range task {
+		if strings.TrimPrefix(strings.TrimPrefix(key, "ansible.builtin."), "ansible.legacy.") == module {
+			if s, ok := v.(string); ok {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// unquotedVars returns the variables of the {{ }} expressions of line whose last filter is
+// not one of shellSafeFilters, leaving out those the control node sets.
+func unquotedVars(line string) []string {
+	blocks, _ := splitTemplate(line)
+	seen := make(map[string]bool)
+	var vars []string
+	for _, b := range blocks {
+		if b.kind != '{' {
+			continue
+		}
+		toks, err := lexJinja(b.body)
+		if err != nil {
+			continue
+		}
+		info := analyzeTokens(toks)
+		if n := len(info.filters); n > 0 && shellSafeFilters[strings.TrimPrefix(info.filters[n-1].name, "ansible.builtin.")] {
+			continue
+		}
+		for _, v := range info.vars {
+			if !controllerVars[v] && !seen[v] {
+				seen[v] = true
+				vars = append(vars, v)
+			}
+		}
+	}
+	return vars
+}
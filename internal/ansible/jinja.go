@@ -0,0 +1,538 @@
+package ansible
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// Jinja2 expression analysis.
+//
+// Templates are split into {{ }} expressions and {% %} statements with string literals
+// and nested braces respected, then tokenized. The token stream tells variables apart
+// from attributes, filters, tests, function calls and keyword arguments, and records
+// the filters applied so they can be linted.
+
+type tokenKind int
+
+const (
+	tokName tokenKind = iota
+	tokString
+	tokNumber
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	val  string
+}
+
+// Two-character operators; everything else is a single character.
+var jinjaOperators = []string{"==", "!=", "<=", ">=", "//", "**"}
+
+// lexJinja tokenizes a Jinja2 expression.
+func lexJinja(expr string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '\'' || c == '"':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(expr) && expr[j] != c; j++ {
+				if expr[j] == '\\' && j+1 < len(expr) {
+					j++
+				}
+				b.WriteByte(expr[j])
+			}
+			if j >= len(expr) {
+				return toks, fmt.Errorf("unterminated string literal")
+			}
+			toks = append(toks, token{tokString, b.String()})
+			i = j + 1
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(expr) && expr[j] >= '0' && expr[j] <= '9' {
+				j++
+			}
+			if j+1 < len(expr) && expr[j] == '.' && expr[j+1] >= '0' && expr[j+1] <= '9' {
+				for j++; j < len(expr) && expr[j] >= '0' && expr[j] <= '9'; j++ {
+				}
+			}
+			toks = append(toks, token{tokNumber, expr[i:j]})
+			i = j
+		case isIdentStart(c):
+			j := i
+			for j < len(expr) && isIdentChar(expr[j]) {
+				j++
+			}
+			toks = append(toks, token{tokName, expr[i:j]})
+			i = j
+		default:
+			op := expr[i : i+1]
+			for _, two := range jinjaOperators {
+				if strings.HasPrefix(expr[i:], two) {
+					op = two
+					break
+				}
+			}
+			toks = append(toks, token{tokOp, op})
+			i += len(op)
+		}
+	}
+	return toks, nil
+}
+
+// filterUse is one application of a filter inside an expression.
+type filterUse struct {
+	name    string  // as written, possibly a dotted FQCN
+	args    []token // tokens between the filter's parentheses
+	subject string  // root variable the filter chain starts from, if any
+}
+
+// exprInfo is what an expression references.
+type exprInfo struct {
+	vars    []string
+	filters []filterUse
+	tests   []string
+}
+
+// analyzeTokens classifies the names of a token stream.
+func analyzeTokens(toks []token) exprInfo {
+	var info exprInfo
+	subject := ""   // root variable of the operand being built
+	prev := token{} // previous significant token
+	isOp := func(t token, op string) bool { return t.kind == tokOp && t.val == op }
+	next := func(i int, op string) bool { return i+1 < len(toks) && isOp(toks[i+1], op) }
+
+	// dotted reads a possibly dotted name starting at i and returns it with the index of its last token.
+	dotted := func(i int) (string, int) {
+		name := toks[i].val
+		for i+2 < len(toks) && isOp(toks[i+1], ".") && toks[i+2].kind == tokName {
+			name += "." + toks[i+2].val
+			i += 2
+		}
+		return name, i
+	}
+
+	for i := 0; i < len(toks); i++ {
+		t := toks[i]
+		switch t.kind {
+		case tokName:
+			switch {
+			case isOp(prev, "|"):
+				name, end := dotted(i)
+				f := filterUse{name: name, subject: subject}
+				if next(end, "(") {
+					f.args = toks[end+2 : matchingParen(toks, end+1)]
+				}
+				info.filters = append(info.filters, f)
+				i = end
+			case prev.kind == tokName && prev.val == "is":
+				if t.val == "not" {
+					continue // keep "is" as the previous token for the test name
+				}
+				name, end := dotted(i)
+				info.tests = append(info.tests, name)
+				i = end
+			case isOp(prev, "."):
+				// attribute access
+			case jinjaKeywords[t.val]:
+				subject = ""
+			case next(i, "("):
+				subject = "" // function call such as lookup() or range()
+			case next(i, "="):
+				// keyword argument
+			default:
+				info.vars = append(info.vars, t.val)
+				subject = t.val
+			}
+		case tokOp:
+			switch t.val {
+			case ".", "[", "]", "|", "(", ")":
+			default:
+				subject = ""
+			}
+		default:
+			if !isOp(prev, "[") {
+				subject = ""
+			}
+		}
+		prev = toks[i]
+	}
+	return info
+}
+
+// matchingParen returns the index of the parenthesis closing the one at open, or len(toks).
+func matchingParen(toks []token, open int) int {
+	depth := 0
+	for i := open; i < len(toks); i++ {
+		if toks[i].kind != tokOp {
+			continue
+		}
+		switch toks[i].val {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(toks)
+}
+
+// jinjaTemplate is the analysis of one templated string or raw expression.
+type jinjaTemplate struct {
+	exprs  []exprInfo
+	locals map[string]bool // names bound by {% for %} and {% set %}
+	err    error           // first syntax error, if any
+}
+
+// vars returns the distinct variables referenced, excluding template-local names.
+func (t jinjaTemplate) vars() []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, e := range t.exprs {
+		for _, v := range e.vars {
+			if !t.locals[v] && !seen[v] {
+				seen[v] = true
+				out = append(out, v)
+			}
+		}
+	}
+	return out
+}
+
+// parseExpression analyses a raw expression such as a when: condition.
+func parseExpression(expr string) jinjaTemplate {
+	t := jinjaTemplate{locals: make(map[string]bool)}
+	toks, err := lexJinja(expr)
+	t.err = err
+	t.exprs = append(t.exprs, analyzeTokens(toks))
+	return t
+}
+
+// parseTemplate analyses every {{ }} expression and {% %} statement in s.
+func parseTemplate(s string) jinjaTemplate {
+	t := jinjaTemplate{locals: make(map[string]bool)}
+	blocks, err := splitTemplate(s)
+	for _, b := range blocks {
+		t.block(b)
+	}
+	if err != nil && t.err == nil {
+		t.err = err
+	}
+	return t
+}
+
+// templateBlock is one {{ }} expression or {% %} statement of a template.
+type templateBlock struct {
+	kind byte // '{' or '%'
+	body string
+	line int // 1-based line of the opening delimiter
+}
+
+// splitTemplate returns the expressions and statements of s in order, dropping {# #}
+// comments. On an unterminated block it returns the blocks before it and the error.
+func splitTemplate(s string) ([]templateBlock, error) {
+	var blocks []templateBlock
+	line := 1
+	last := 0
+	for i := 0; i < len(s)-1; i++ {
+		if s[i] != '{' || (s[i+1] != '{' && s[i+1] != '%' && s[i+1] != '#') {
+			continue
+		}
+		line += strings.Count(s[last:i], "\n")
+		last = i
+		kind := s[i+1]
+		end, err := templateEnd(s, i+2, kind)
+		if err != nil {
+			return blocks, err
+		}
+		if kind != '#' {
+			blocks = append(blocks, templateBlock{kind: kind, body: strings.Trim(s[i+2:end], "-+"), line: line})
+		}
+		i = end + 1
+	}
+	return blocks, nil
+}
+
+// block adds the analysis of one expression or statement to t.
+func (t *jinjaTemplate) block(b templateBlock) {
+	toks, err := lexJinja(b.body)
+	if err != nil && t.err == nil {
+		t.err = err
+	}
+	switch b.kind {
+	case '{':
+		t.exprs = append(t.exprs, analyzeTokens(toks))
+	case '%':
+		t.statement(toks)
+	}
+}
+
+// statement records the expressions and bindings of a {% %} statement.
+func (t *jinjaTemplate) statement(toks []token) {
+	if len(toks) == 0 || toks[0].kind != tokName {
+		return
+	}
+	switch toks[0].val {
+	case "for":
+		t.locals["loop"] = true
+		for i, tok := range toks[1:] {
+			if tok.kind == tokName && tok.val == "in" {
+				t.exprs = append(t.exprs, analyzeTokens(toks[i+2:]))
+				return
+			}
+			if tok.kind == tokName {
+				t.locals[tok.val] = true
+			}
+		}
+	case "set":
+		for i, tok := range toks[1:] {
+			if tok.kind == tokOp && tok.val == "=" {
+				t.exprs = append(t.exprs, analyzeTokens(toks[i+2:]))
+				return
+			}
+			if tok.kind == tokName {
+				t.locals[tok.val] = true
+			}
+		}
+	case "if", "elif":
+		t.exprs = append(t.exprs, analyzeTokens(toks[1:]))
+	case "macro":
+		// the macro name and its parameters
+		for i, tok := range toks[1:] {
+			if tok.kind == tokName && (i == 0 || toks[i].val == "(" || toks[i].val == ",") {
+				t.locals[tok.val] = true
+			}
+		}
+	case "import", "from":
+		// {% import 'x' as m %}, {% from 'x' import a, b as c %}
+		for i, tok := range toks[1:] {
+			if tok.kind == tokName && (toks[i].val == "as" || toks[i].val == "import" || toks[i].val == ",") {
+				t.locals[tok.val] = true
+			}
+		}
+	case "with":
+		for i, tok := range toks[1:] {
+			if tok.kind == tokName && i+2 < len(toks) && toks[i+2].val == "=" {
+				t.locals[tok.val] = true
+			}
+		}
+		t.exprs = append(t.exprs, analyzeTokens(toks[1:]))
+	}
+}
+
+// templateEnd returns the offset of the closing delimiter of a block opened with kind
+// ('{', '%' or '#') whose body starts at start, skipping string literals and nested braces.
+func templateEnd(s string, start int, kind byte) (int, error) {
+	closer := map[byte]byte{'{': '}', '%': '%', '#': '#'}[kind]
+	depth := 0
+	for j := start; j < len(s)-1; j++ {
+		c := s[j]
+		if kind != '#' && (c == '\'' || c == '"') {
+			k := j + 1
+			for k < len(s) && s[k] != c {
+				if s[k] == '\\' {
+					k++
+				}
+				k++
+			}
+			if k >= len(s) {
+				return 0, fmt.Errorf("unterminated string literal")
+			}
+			j = k
+			continue
+		}
+		if kind == '{' && c == '{' {
+			depth++
+			continue
+		}
+		if c == closer && s[j+1] == '}' && depth == 0 {
+			return j, nil
+		}
+		if kind == '{' && c == '}' && depth > 0 {
+			depth--
+		}
+	}
+	return 0, fmt.Errorf("unterminated {%c", kind)
+}
+
+// Jinja2 and ansible.builtin filters; anything else must come from a collection.
+var knownFilters = toSet(
+	// Jinja2
+	"abs", "attr", "batch", "capitalize", "center", "count", "d", "default", "dictsort", "e",
+	"escape", "filesizeformat", "first", "float", "forceescape", "format", "groupby", "indent",
+	"int", "items", "join", "last", "length", "list", "lower", "map", "max", "min", "pprint",
+	"random", "reject", "rejectattr", "replace", "reverse", "round", "safe", "select",
+	"selectattr", "slice", "sort", "string", "striptags", "sum", "title", "tojson", "trim",
+	"truncate", "unique", "upper", "urlencode", "urlize", "wordcount", "wordwrap", "xmlattr",
+	// Ansible
+	"b64decode", "b64encode", "basename", "bool", "checksum", "combinations", "combine",
+	"comment", "dict2items", "difference", "dirname", "expanduser", "expandvars", "extract",
+	"fileglob", "flatten", "from_json", "from_yaml", "from_yaml_all", "generate_random_mac", "hash",
+	"human_readable", "human_to_bytes", "intersect", "items2dict", "log", "mandatory", "md5",
+	"password_hash", "path_join", "permutations", "pow", "product", "quote", "random_mac",
+	"realpath", "regex_escape", "regex_findall", "regex_replace", "regex_search", "relpath",
+	"root", "sha1", "shuffle", "split", "splitext", "strftime", "subelements",
+	"symmetric_difference", "ternary", "to_datetime", "to_json", "to_nice_json",
+	"to_nice_yaml", "to_uuid", "to_yaml", "type_debug", "union", "unvault", "urldecode",
+	"urlsplit", "vault", "win_basename", "win_dirname", "win_splitdrive", "zip", "zip_longest",
+	// routed from collections and still usable by their short names
+	"json_query", "ipaddr", "ipv4", "ipv6", "ipwrap",
+)
+
+// Filters that were removed or replaced, with the replacement.
+var deprecatedFilters = map[string]string{
+	"success":         "use the 'is success' test",
+	"succeeded":       "use the 'is succeeded' test",
+	"failed":          "use the 'is failed' test",
+	"failure":         "use the 'is failure' test",
+	"changed":         "use the 'is changed' test",
+	"change":          "use the 'is changed' test",
+	"skipped":         "use the 'is skipped' test",
+	"skip":            "use the 'is skipped' test",
+	"match":           "use the 'is match' test",
+	"search":          "use the 'is search' test",
+	"regex":           "use the 'is regex' test",
+	"version_compare": "use the 'is version' test",
+	"issubset":        "use the 'is subset' test",
+	"issuperset":      "use the 'is superset' test",
+}
+
+// Variable name fragments that mark a secret whose default would be a hardcoded credential.
+// Narrower than secretKeywords: paths like ssh_key_file commonly default to literals.
+var secretDefaultKeywords = []string{"password", "passwd", "pwd", "secret", "token", "api_key", "private_key"}
+
+func toSet(items ...string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[item] = true
+	}
+	return set
+}
+
+// templateSource is a templated value found in a play, with where it came from.
+type templateSource struct {
+	label string
+	text  string
+	raw   bool // a bare expression (when: and friends) rather than a {{ }} template
+}
+
+func (s templateSource) parse() jinjaTemplate {
+	if s.raw {
+		return parseExpression(s.text)
+	}
+	return parseTemplate(s.text)
+}
+
+// playTemplates returns every templated value of a play: vars, then tasks in execution order, then handlers.
+func playTemplates(play Play) []templateSource {
+	var out []templateSource
+	var vars []string
+	collectTemplates(play.Vars, &vars)
+	for _, v := range vars {
+		out = append(out, templateSource{label: "play vars", text: v})
+	}
+	for _, tasks := range [][]Task{play.PreTasks, play.Tasks, play.PostTasks, play.Handlers} {
+		out = append(out, taskTemplates(tasks)...)
+	}
+	return out
+}
+
+func taskTemplates(tasks []Task) []templateSource {
+	var out []templateSource
+	for _, task := range tasks {
+		label := newTaskRef(task).label
+		if _, isBlock := task["block"]; isBlock {
+			for _, section := range []string{"block", "rescue", "always"} {
+				out = append(out, taskTemplates(toTasks(task[section]))...)
+			}
+		}
+		keys := make([]string, 0, len(task))
+		for k := range task {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if isConditionalKey(k) || k == "block" || k == "rescue" || k == "always" {
+				continue
+			}
+			var values []string
+			collectTemplates(task[k], &values)
+			for _, v := range values {
+				out = append(out, templateSource{label: label, text: v})
+			}
+		}
+		for _, expr := range conditionalValues(task) {
+			out = append(out, templateSource{label: label, text: expr, raw: true})
+		}
+	}
+	return out
+}
+
+// checkJinja lints the Jinja2 expressions of every play: syntax errors, unknown and
+// deprecated filters, and secrets falling back to hardcoded defaults.
+func checkJinja(p string, plays []Play) []finding.Finding {
+	var findings []finding.Finding
+	reported := make(map[string]bool)
+	report := func(sev finding.Severity, msg string) {
+		if !reported[msg] {
+			reported[msg] = true
+			findings = append(findings, finding.Finding{File: p, Severity: sev, Message: msg})
+		}
+	}
+
+	for _, play := range plays {
+		for _, src := range playTemplates(play) {
+			t := src.parse()
+			if t.err != nil {
+				report(finding.Warning, fmt.Sprintf("Invalid Jinja2 expression in %s: %v", src.label, t.err))
+			}
+			lintFilters(t, src.label, report)
+		}
+	}
+	return findings
+}
+
+// lintFilters reports the deprecated and unknown filters of t, and secrets falling back
+// to hardcoded defaults, against label.
+func lintFilters(t jinjaTemplate, label string, report func(finding.Severity, string)) {
+	for _, e := range t.exprs {
+		for _, f := range e.filters {
+			name := strings.TrimPrefix(f.name, "ansible.builtin.")
+			if msg, deprecated := deprecatedFilters[name]; deprecated {
+				report(finding.Warning, fmt.Sprintf("Deprecated filter '%s' in %s: %s", f.name, label, msg))
+				continue
+			}
+			if strings.Contains(name, ".") {
+				continue // filter from a collection, referenced by FQCN
+			}
+			if !knownFilters[name] {
+				report(finding.Warning, fmt.Sprintf("Unknown Jinja2 filter '%s' in %s (typo, or a collection filter that needs its fully qualified name)", f.name, label))
+				continue
+			}
+			if (name == "default" || name == "d") && isSecretName(f.subject) && len(f.args) > 0 &&
+				f.args[0].kind == tokString && f.args[0].val != "" {
+				report(finding.Error, fmt.Sprintf("Secret variable '%s' in %s falls back to a hardcoded default; use '| mandatory' or a vaulted value instead", f.subject, label))
+			}
+		}
+	}
+}
+
+func isSecretName(name string) bool {
+	lower := strings.ToLower(name)
+	for _, kw := range secretDefaultKeywords {
+		if strings.Contains(lower, kw) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package ansible
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// Task keywords; every other key of a task is the module (or its free-form arguments).
+var taskKeywords = toSet(
+	"name", "when", "tags", "vars", "environment", "register", "notify", "listen",
+	"become", "become_user", "become_method", "become_flags", "become_exe",
+	"delegate_to", "delegate_facts", "run_once", "local_action", "args",
+	"loop", "loop_control", "until", "retries", "delay", "ignore_errors", "ignore_unreachable",
+	"changed_when", "failed_when", "check_mode", "diff", "no_log", "throttle", "timeout",
+	"any_errors_fatal", "collections", "module_defaults", "connection", "remote_user",
+	"port", "debugger", "async", "poll", "block", "rescue", "always",
+)
+
+// Parameters whose name looks secret but whose value is a mode or policy.
+var nonSecretParams = toSet("update_password", "password_lock", "password_expire_max", "password_expire_min", "password_expire_warn", "return_content")
+
+// checkNoLog flags tasks passing credentials to a module without no_log: true, since
+// Ansible prints module arguments on failure and in verbose output. noLog is the value
+// inherited from the enclosing play or block.
+func checkNoLog(p string, tasks []Task, noLog bool) []finding.Finding {
+	var findings []finding.Finding
+	for _, task := range tasks {
+		taskNoLog := noLog
+		if v, ok := task["no_log"]; ok {
+			taskNoLog = noLogEnabled(v)
+		}
+		if _, isBlock := task["block"]; isBlock {
+			for _, section := range []string{"block", "rescue", "always"} {
+				findings = append(findings, checkNoLog(p, toTasks(task[section]), taskNoLog)...)
+			}
+			continue
+		}
+		if taskNoLog {
+			continue
+		}
+		module, params := secretParams(task)
+		if len(params) == 0 {
+			continue
+		}
+		name, _ := task["name"].(string)
+		if name == "" {
+			name = module
+		}
+		findings = append(findings, finding.Finding{
+			File:     p,
+			Severity: finding.Warning,
+			Message:  fmt.Sprintf("Task '%s' passes %s to '%s' without 'no_log: true'; the credentials may be written to logs", name, quoteList(params), module),
+		})
+	}
+	return findings
+}
+
+// noLogEnabled reports whether a no_log value hides output. Templated values cannot be
+// decided statically and are given the benefit of the doubt.
+func noLogEnabled(v interface{}) bool {
+	switch val := v.(type) {
+	case bool:
+		return val
+	case string:
+		switch strings.ToLower(strings.TrimSpace(val)) {
+		case "true", "yes", "on", "1":
+			return true
+		}
+		return strings.Contains(val, "{{")
+	}
+	return false
+}
+
+// secretParams returns the module a task calls and the secret-looking parameters it
+// sets, from its argument map (including args: and uri-style headers) or k=v form.
+func secretParams(task Task) (string, []string) {
+	module := ""
+	found := make(map[string]bool)
+	addMap := func(m map[string]interface{}) {
+		for k, v := range m {
+			if sub, ok := asMap(v); ok && (k == "headers" || k == "body") {
+				for hk, hv := range sub {
+					if isSecretParam(hk, hv) || (strings.EqualFold(hk, "authorization") && hv != nil) {
+						found[k+"."+hk] = true
+					}
+				}
+				continue
+			}
+			if isSecretParam(k, v) {
+				found[k] = true
+			}
+		}
+	}
+	keys := make([]string, 0, len(task))
+	for key := range task {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if taskKeywords[key] || strings.HasPrefix(key, "with_") {
+			continue
+		}
+		if module == "" {
+			module = key
+		}
+		switch v := task[key].(type) {
+		case string:
+			for _, field := range strings.Fields(v) {
+				if k, value, ok := strings.Cut(field, "="); ok && isSecretParam(k, value) {
+					found[k] = true
+				}
+			}
+		default:
+			if m, ok := asMap(v); ok {
+				addMap(m)
+			}
+		}
+	}
+	if args, ok := asMap(task["args"]); ok {
+		addMap(args)
+	}
+	if module == "" {
+		return "", nil
+	}
+	params := make([]string, 0, len(found))
+	for k := range found {
+		params = append(params, k)
+	}
+	sort.Strings(params)
+	return module, params
+}
+
+// isSecretParam reports whether a module parameter carries a credential: a secret-like
+// name set to a string value, as opposed to flags such as password_lock.
+func isSecretParam(name string, v interface{}) bool {
+	s, ok := v.(string)
+	return ok && strings.TrimSpace(s) != "" && isSecretName(name) && !nonSecretParams[strings.ToLower(name)]
+}
+
+func quoteList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = "'" + item + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
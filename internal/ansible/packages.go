@@ -0,0 +1,139 @@
+package ansible
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// Package managers whose tasks are checked for version pins, by module name.
+var packageModules = toSet("apt", "yum", "dnf", "package", "pip")
+
+// rpmVersion matches an rpm package name carrying its version, as in nginx-1.20.1.
+var rpmVersion = regexp.MustCompile(`^[A-Za-z0-9_.+-]+-[0-9]`)
+
+// checkPackagePins flags package tasks installing with state: latest, or naming packages
+// without a version, in tasks and the blocks among them: either way two runs of the
+// playbook may install different versions.
+func checkPackagePins(p string, tasks []Task) []finding.Finding {
+	var findings []finding.Finding
+	for _, task := range tasks {
+		if _, isBlock := task["block"]; isBlock {
+			for _, section := range []string{"block", "rescue", "always"} {
+				findings = append(findings, checkPackagePins(p, toTasks(task[section]))...)
+			}
+			continue
+		}
+		module := taskModule(task)
+		if !packageModules[module] {
+			continue
+		}
+		params := moduleParams(task)
+		state, _ := params["state"].(string)
+		names := packageNames(params["name"])
+		if len(names) == 0 {
+			continue
+		}
+		name, _ := task["name"].(string)
+		if name == "" {
+			name = module
+		}
+
+		switch state {
+		case "absent", "removed":
+			continue
+		case "latest":
+			findings = append(findings, finding.Finding{
+				File:     p,
+				Severity: finding.Warning,
+				Message:  fmt.Sprintf("Task '%s' installs %s with state: latest, so each run may install a different version; pin versions and use state: present", name, quoteList(names)),
+			})
+			continue
+		}
+		if _, ok := params["version"]; ok && module == "pip" {
+			continue
+		}
+		var unpinned []string
+		for _, n := range names {
+			if !packagePinned(module, n) {
+				unpinned = append(unpinned, n)
+			}
+		}
+		if len(unpinned) > 0 {
+			findings = append(findings, finding.Finding{
+				File:     p,
+				Severity: finding.Warning,
+				Message:  fmt.Sprintf("Task '%s' installs %s with '%s' without a version pin, so each run may install a different version", name, quoteList(unpinned), module),
+			})
+		}
+	}
+	return findings
+}
+
+// moduleParams returns the parameters a task passes its module, given as a mapping, in
+// args, or in the free-form key=value style.
+func moduleParams(task Task) map[string]interface{} {
+	params := make(map[string]interface{})
+	for key, v := range task {
+		if strings.TrimPrefix(strings.TrimPrefix(key, "ansible.builtin."), "ansible.legacy.") != taskModule(task) {
+			continue
+		}
+		if m, ok := asMap(v); ok {
+			for k, val := range m {
+				params[k] = val
+			}
+		} else if s, ok := v.(string); ok {
+			for _, field := range strings.Fields(s) {
+				if k, val, ok := strings.Cut(field, "="); ok {
+					params[k] = val
+				}
+			}
+		}
+	}
+	if args, ok := asMap(task["args"]); ok {
+		for k, val := range args {
+			params[k] = val
+		}
+	}
+	return params
+}
+
+// packageNames returns the package names of a name parameter, a list or a comma-separated
+// string. Templated names, which may carry a version, files and URLs are left out.
+func packageNames(v interface{}) []string {
+	var raw []string
+	switch n := v.(type) {
+	case string:
+		raw = strings.Split(n, ",")
+	case []interface{}:
+		for _, item := range n {
+			if s, ok := item.(string); ok {
+				raw = append(raw, s)
+			}
+		}
+	}
+	var names []string
+	for _, n := range raw {
+		n = strings.TrimSpace(n)
+		if n == "" || strings.Contains(n, "{{") || strings.Contains(n, "/") || strings.HasPrefix(n, "@") {
+			continue
+		}
+		names = append(names, n)
+	}
+	return names
+}
+
+// packagePinned reports whether the package name n carries a version in the syntax of module:
+// nginx=1.18.0-0ubuntu1 for apt, nginx-1.20.1 for yum and dnf, requests==2.31.0 for pip.
+func packagePinned(module, n string) bool {
+	if strings.ContainsAny(n, "=<>") {
+		return true
+	}
+	switch module {
+	case "yum", "dnf", "package":
+		return rpmVersion.MatchString(n)
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package ansible
+
+import (
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// ansibleScanner scans only the YAML files holding plays, so the other YAML
+// tools' files are not reported as malformed playbooks. Jinja2 templates are
+// read along with the playbooks rendering them.
+type ansibleScanner struct{}
+
+func init() {
+	scanner.Register(ansibleScanner{})
+}
+
+func (ansibleScanner) Name() string { return "ansible" }
+func (ansibleScanner) Description() string {
+	return "Scan Ansible playbooks in the specified directories or files"
+}
+func (ansibleScanner) FileMatcher() scanner.FileMatcher {
+	return scanner.Extensions(".yml", ".yaml", ".j2")
+}
+func (ansibleScanner) Scan(path string) ([]finding.Finding, error) { return Scan(path) }
+
+func (ansibleScanner) Targets(root string, files []string) []string {
+	var playbooks []string
+	for _, p := range files {
+		if data, err := os.ReadFile(p); err == nil && IsPlaybook(data) {
+			playbooks = append(playbooks, p)
+		}
+	}
+	return playbooks
+}
+
+// IsPlaybook reports whether data is a list of Ansible plays.
+func IsPlaybook(data []byte) bool {
+	var plays []map[string]interface{}
+	if err := yaml.Unmarshal(data, &plays); err != nil {
+		return false
+	}
+	for _, play := range plays {
+		for _, key := range []string{"hosts", "import_playbook", "ansible.builtin.import_playbook"} {
+			if _, ok := play[key]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package ansible
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// Galaxy requirements files.
+//
+// requirements.yml is either a list of roles (the old format) or a mapping with roles:
+// and collections: lists. An entry is reproducible when it names an exact version or,
+// for git sources, a tag or commit rather than a branch.
+
+// requirement is one role or collection entry of a requirements file.
+type requirement struct {
+	Name    string `yaml:"name"`
+	Src     string `yaml:"src"`
+	Version string `yaml:"version"`
+	Scm     string `yaml:"scm"`
+	Type    string `yaml:"type"`
+}
+
+// Branch names that follow whatever is pushed next.
+var defaultBranches = toSet("main", "master", "head", "develop", "development", "dev", "trunk", "latest")
+
+// An exact version, optionally written as ==1.2.3 or v1.2.3.
+var exactVersionRegex = regexp.MustCompile(`^(==)?v?\d+(\.\d+)*([-+.][0-9A-Za-z.-]+)?$`)
+
+// A git commit hash, possibly abbreviated.
+var commitRegex = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// isRequirementsFile reports whether p is a Galaxy requirements file.
+func isRequirementsFile(p string) bool {
+	switch filepath.Base(p) {
+	case "requirements.yml", "requirements.yaml":
+		return true
+	}
+	return false
+}
+
+// checkRequirements flags roles and collections that are not pinned to a version, and
+// git sources that track a branch.
+func checkRequirements(p string, data []byte) []finding.Finding {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return []finding.Finding{{File: p, Severity: finding.Error, Message: fmt.Sprintf("YAML parse error: %v", err)}}
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	root := doc.Content[0]
+
+	var findings []finding.Finding
+	check := func(kind string, list *yaml.Node) {
+		if list == nil || list.Kind != yaml.SequenceNode {
+			return
+		}
+		for _, item := range list.Content {
+			var req requirement
+			if item.Kind == yaml.ScalarNode {
+				req.Name = item.Value
+			} else if err := item.Decode(&req); err != nil {
+				continue
+			}
+			if msg := requirementProblem(kind, req); msg != "" {
+				findings = append(findings, finding.Finding{File: p, Severity: finding.Warning, Message: msg, Line: item.Line})
+			}
+		}
+	}
+	switch root.Kind {
+	case yaml.SequenceNode:
+		check("role", root)
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			switch root.Content[i].Value {
+			case "roles":
+				check("role", root.Content[i+1])
+			case "collections":
+				check("collection", root.Content[i+1])
+			}
+		}
+	}
+	return findings
+}
+
+// requirementProblem describes why an entry is not reproducible, or returns "".
+func requirementProblem(kind string, req requirement) string {
+	source := req.Src
+	if source == "" || (kind == "collection" && req.Type != "git") {
+		source = req.Name
+	}
+	label := req.Name
+	if label == "" {
+		label = req.Src
+	}
+	if label == "" {
+		return ""
+	}
+
+	// Local paths and tarballs are whatever is on disk or at the URL.
+	switch req.Type {
+	case "file", "dir", "subdirs", "url":
+		return ""
+	}
+
+	version := strings.TrimSpace(req.Version)
+	if isGitSource(req, source) {
+		// git+https://host/repo.git,ref carries the ref after the comma
+		if base, ref, ok := strings.Cut(source, ","); ok && version == "" {
+			label, version = base, ref
+		}
+		if version == "" {
+			return fmt.Sprintf("%s '%s' in requirements is pulled from git without a version; pin a tag or commit for reproducible builds", capitalize(kind), label)
+		}
+		if defaultBranches[strings.ToLower(version)] {
+			return fmt.Sprintf("%s '%s' in requirements tracks the git branch '%s'; pin a tag or commit for reproducible builds", capitalize(kind), label, version)
+		}
+		return ""
+	}
+	if version == "" || version == "*" {
+		return fmt.Sprintf("%s '%s' in requirements has no version pin; builds install whatever is latest", capitalize(kind), label)
+	}
+	if !exactVersionRegex.MatchString(version) && !commitRegex.MatchString(version) && !strings.Contains(version, "<") {
+		return fmt.Sprintf("%s '%s' in requirements allows any newer version ('%s'); pin an exact version or add an upper bound", capitalize(kind), label, version)
+	}
+	return ""
+}
+
+// isGitSource reports whether an entry is installed from a git repository.
+func isGitSource(req requirement, source string) bool {
+	if req.Scm == "git" || req.Type == "git" {
+		return true
+	}
+	return strings.HasPrefix(source, "git+") || strings.HasPrefix(source, "git@") ||
+		strings.HasSuffix(strings.SplitN(source, ",", 2)[0], ".git")
+}
@@ -0,0 +1,275 @@
+package ansible
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// Role layout support.
+//
+// Roles are resolved from the play's roles: list the way ansible-playbook looks them up
+// without a configured roles_path: roles/<name> next to the playbook, then <name>
+// relative to the playbook. Files inside a role directory are not playbooks; they are
+// scanned through their role, once, with the role's defaults and vars in scope.
+
+// Directories of the standard role layout.
+var roleSubdirs = []string{"tasks", "handlers", "defaults", "vars", "meta", "templates", "files"}
+
+// Keys of a roles: entry that are keywords rather than role parameters.
+var roleKeywords = toSet("role", "name", "vars", "when", "tags", "become", "become_user", "delegate_to", "environment")
+
+// role is an Ansible role loaded from its directory.
+type role struct {
+	name string
+	dir  string
+
+	tasksFile    string
+	tasks        []Task
+	handlersFile string
+	handlers     []Task
+	defaultsFile string
+	defaults     map[string]interface{}
+	varsFile     string
+	vars         map[string]interface{}
+
+	// dependencies reports meta/main.yml dependencies, which are not followed.
+	dependencies bool
+}
+
+// isRoleDir reports whether dir has the layout of a role.
+func isRoleDir(dir string) bool {
+	for _, sub := range []string{"tasks", "handlers", "defaults", "vars", "meta"} {
+		if mainFile(filepath.Join(dir, sub)) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// roleDirOf returns the role directory holding p, or "" when p is not part of a role.
+func roleDirOf(p string) string {
+	for dir := filepath.Dir(p); ; {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		for _, sub := range roleSubdirs {
+			if filepath.Base(dir) == sub && isRoleDir(parent) {
+				return parent
+			}
+		}
+		dir = parent
+	}
+}
+
+// mainFile returns the main.yml (or main.yaml) of a role subdirectory, or "".
+func mainFile(dir string) string {
+	for _, name := range []string{"main.yml", "main.yaml"} {
+		if info, err := os.Stat(filepath.Join(dir, name)); err == nil && !info.IsDir() {
+			return filepath.Join(dir, name)
+		}
+	}
+	return ""
+}
+
+// loadRole reads the main files of the role in dir. Missing files leave their part empty.
+func loadRole(name, dir string) *role {
+	r := &role{name: name, dir: dir}
+	load := func(sub string, out interface{}) string {
+		p := mainFile(filepath.Join(dir, sub))
+		if p == "" {
+			return ""
+		}
+		if data, err := os.ReadFile(p); err == nil {
+			yaml.Unmarshal(data, out)
+		}
+		return p
+	}
+	r.tasksFile = load("tasks", &r.tasks)
+	r.handlersFile = load("handlers", &r.handlers)
+	r.defaultsFile = load("defaults", &r.defaults)
+	r.varsFile = load("vars", &r.vars)
+	var meta struct {
+		Dependencies []interface{} `yaml:"dependencies"`
+	}
+	load("meta", &meta)
+	r.dependencies = len(meta.Dependencies) > 0
+	return r
+}
+
+// roleEntryName returns the role name of a roles: entry, a string or a map with role/name.
+func roleEntryName(entry interface{}) string {
+	switch e := entry.(type) {
+	case string:
+		return e
+	case map[string]interface{}:
+		if name, ok := firstNonNil(e["role"], e["name"]).(string); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// roleParams returns the variables a roles: entry passes to the role.
+func roleParams(entry interface{}) map[string]interface{} {
+	e, ok := entry.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	params := make(map[string]interface{})
+	for k, v := range e {
+		if !roleKeywords[k] {
+			params[k] = v
+		}
+	}
+	if vars, ok := asMap(e["vars"]); ok {
+		for k, v := range vars {
+			params[k] = v
+		}
+	}
+	return params
+}
+
+// resolveRole locates the role named by a roles: entry of a playbook in playbookDir.
+// Templated names and roles from collections (namespace.collection.role) do not resolve.
+func resolveRole(playbookDir string, entry interface{}) (*role, bool) {
+	name := roleEntryName(entry)
+	if name == "" || strings.Contains(name, "{{") {
+		return nil, false
+	}
+	candidates := []string{filepath.Join(playbookDir, "roles", name)}
+	if filepath.IsAbs(name) {
+		candidates = []string{name}
+	} else if strings.Contains(name, "/") || !strings.Contains(name, ".") {
+		candidates = append(candidates, filepath.Join(playbookDir, name))
+	}
+	for _, dir := range candidates {
+		if isRoleDir(dir) {
+			return loadRole(filepath.Base(name), dir), true
+		}
+	}
+	return nil, false
+}
+
+// rolePlay presents a role's tasks, handlers and variables as a play so the play-level
+// template analysis applies to it unchanged.
+func (r *role) play() Play {
+	vars := make(map[string]interface{})
+	for k, v := range r.defaults {
+		vars[k] = v
+	}
+	for k, v := range r.vars {
+		vars[k] = v
+	}
+	return Play{Name: "role " + r.name, Tasks: r.tasks, Handlers: r.handlers, Vars: vars}
+}
+
+// checkRole runs the task and template checks on a role's own files, and reports role
+// defaults that neither the role nor the plays applying it (usedBy) reference.
+func checkRole(r *role, usedBy map[string]bool, opts Options) []finding.Finding {
+	var findings []finding.Finding
+	if r.tasksFile != "" {
+		findings = append(findings, checkTasks(r.tasksFile, r.tasks, opts)...)
+		findings = append(findings, checkJinja(r.tasksFile, []Play{{Tasks: r.tasks}})...)
+		findings = append(findings, checkNoLog(r.tasksFile, r.tasks, false)...)
+		findings = append(findings, checkRootBecome(r.tasksFile, r.tasks, "root", false)...)
+		findings = append(findings, checkModuleArgs(r.tasksFile, r.tasks)...)
+	}
+	if r.handlersFile != "" {
+		findings = append(findings, checkJinja(r.handlersFile, []Play{{Handlers: r.handlers}})...)
+		findings = append(findings, checkNoLog(r.handlersFile, r.handlers, false)...)
+		findings = append(findings, checkRootBecome(r.handlersFile, r.handlers, "root", false)...)
+		findings = append(findings, checkModuleArgs(r.handlersFile, r.handlers)...)
+	}
+	included, unresolved := r.includes()
+	for _, f := range included {
+		findings = append(findings, checkTasks(f.path, f.tasks, opts)...)
+		findings = append(findings, checkJinja(f.path, []Play{{Tasks: f.tasks}})...)
+		findings = append(findings, checkNoLog(f.path, f.tasks, false)...)
+		findings = append(findings, checkRootBecome(f.path, f.tasks, "root", false)...)
+		findings = append(findings, checkModuleArgs(f.path, f.tasks)...)
+		if opts.Strict {
+			findings = append(findings, checkStrictTasks(f.path, f.tasks)...)
+		}
+	}
+	if opts.Strict {
+		findings = append(findings, checkStrictTasks(r.tasksFile, r.tasks)...)
+		findings = append(findings, checkStrictTasks(r.handlersFile, r.handlers)...)
+	}
+	findings = append(findings, checkPlaintextSecrets(r.defaultsFile, fmt.Sprintf("role '%s' defaults", r.name), r.defaults)...)
+	findings = append(findings, checkPlaintextSecrets(r.varsFile, fmt.Sprintf("role '%s' vars", r.name), r.vars)...)
+
+	used := make(map[string]bool)
+	for name := range usedBy {
+		used[name] = true
+	}
+	for _, src := range playTemplates(r.play()) {
+		for _, v := range src.parse().vars() {
+			used[v] = true
+		}
+	}
+	roleTasks := append(append([]Task{}, r.tasks...), r.handlers...)
+	for _, f := range included {
+		for _, src := range taskTemplates(f.tasks) {
+			for _, v := range src.parse().vars() {
+				used[v] = true
+			}
+		}
+		roleTasks = append(roleTasks, f.tasks...)
+	}
+	for _, v := range templateFileVars(roleTasks, r.templateDirs()) {
+		used[v] = true
+	}
+	// Unresolved includes and dependencies may read defaults we never see.
+	if r.dependencies || unresolved {
+		return findings
+	}
+	names := make([]string, 0, len(r.defaults))
+	for name := range r.defaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !used[name] {
+			findings = append(findings, finding.Finding{
+				File:     r.defaultsFile,
+				Severity: finding.Warning,
+				Message:  fmt.Sprintf("Role default '%s' of role '%s' defined but not used", name, r.name),
+			})
+		}
+	}
+	return findings
+}
+
+// includes returns the role's task files besides main: those its tasks and handlers
+// include, then the other files under tasks/, which include_role can select with
+// tasks_from. It also reports whether any include could not be followed.
+func (r *role) includes() ([]includedFile, bool) {
+	tasksDir := filepath.Join(r.dir, "tasks")
+	seen := map[string]bool{filepath.Clean(r.tasksFile): true}
+	files, unresolved := includedTasks(r.tasksFile, r.tasks, []string{tasksDir}, seen)
+	handlers, u := includedTasks(r.handlersFile, r.handlers, []string{filepath.Join(r.dir, "handlers")}, seen)
+	files, unresolved = append(files, handlers...), unresolved || u
+
+	others, _ := filepath.Glob(filepath.Join(tasksDir, "*.y*ml"))
+	sort.Strings(others)
+	for _, p := range others {
+		p = filepath.Clean(p)
+		tasks, ok := loadTaskFile(p)
+		if seen[p] || !ok {
+			continue
+		}
+		seen[p] = true
+		files = append(files, includedFile{path: p, tasks: tasks})
+		nested, u := includedTasks(p, tasks, []string{tasksDir}, seen)
+		files, unresolved = append(files, nested...), unresolved || u
+	}
+	return files, unresolved
+}
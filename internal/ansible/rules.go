@@ -0,0 +1,253 @@
+package ansible
+
+import (
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/registry"
+)
+
+func init() {
+	scanners := []string{"ansible"}
+	registry.Register(
+		registry.Rule{
+			ID: "ANS001", Name: "Play without hosts", Severity: finding.Warning, Category: "correctness", Scanners: scanners,
+			Description: "A play has no hosts field, which ansible-playbook rejects.",
+			Messages:    []string{"Play missing required field 'hosts'"},
+			Remediation: `- name: Configure web servers
+  hosts: webservers
+  tasks: []`,
+		},
+		registry.Rule{
+			ID: "ANS002", Name: "Unused variable", Severity: finding.Warning, Category: "hygiene", Scanners: scanners,
+			Description: "A play variable or role default is defined but never used.",
+			Messages:    []string{"Variable '*' defined but not used", "Role default '*' of role '*' defined but not used"},
+			Remediation: `# remove the variable, or use it
+- name: Install nginx
+  ansible.builtin.package:
+    name: "nginx={{ nginx_version }}"`,
+		},
+		registry.Rule{
+			ID: "ANS003", Name: "Task without become", Severity: finding.Warning, Category: "privilege", Scanners: scanners,
+			Description: "A task does not state whether it escalates privileges, or sets become: false where the play escalates.",
+			Messages:    []string{"Task missing 'become' field (no privilege escalation specified)", "'become' is false in task (possible privilege issue)"},
+			Remediation: `- name: Install nginx
+  ansible.builtin.package:
+    name: nginx
+  become: true`,
+		},
+		registry.Rule{
+			ID: "ANS004", Name: "Task without name", Severity: finding.Warning, Category: "hygiene", Scanners: scanners,
+			Description: "A task has no name, leaving play output and logs unreadable.",
+			Messages:    []string{"Task missing required field 'name'"},
+			Remediation: `- name: Start nginx
+  ansible.builtin.service:
+    name: nginx
+    state: started`,
+		},
+		registry.Rule{
+			ID: "ANS005", Name: "Deprecated module", Severity: finding.Warning, Category: "deprecation", Scanners: scanners,
+			Description: "A task uses a module that is deprecated or removed in current Ansible releases.",
+			Messages:    []string{"Use of deprecated module '*': *"},
+			Remediation: `# use the fully qualified replacement module
+- name: Install packages
+  ansible.builtin.dnf:
+    name: httpd`,
+		},
+		registry.Rule{
+			ID: "ANS006", Name: "Hardcoded secret in task", Severity: finding.Error, Category: "secrets", Scanners: scanners,
+			Compliance:  registry.HardcodedSecrets,
+			Description: "A module argument with a secret-like name is set to a literal.",
+			Messages:    []string{"Possible hardcoded secret in attribute '*'"},
+			Remediation: `- name: Create the database user
+  community.mysql.mysql_user:
+    name: app
+    password: "{{ vault_db_password }}"`,
+		},
+		registry.Rule{
+			ID: "ANS007", Name: "Credentials without no_log", Severity: finding.Warning, Category: "secrets", Scanners: scanners,
+			Compliance:  registry.ExposedSecrets,
+			Description: "A task passes passwords or tokens to a module without no_log: true, so they can end up in logs and callback output.",
+			Messages:    []string{"Task '*' passes * to '*' without 'no_log: true'; *"},
+			Remediation: `- name: Create the database user
+  community.mysql.mysql_user:
+    name: app
+    password: "{{ vault_db_password }}"
+  no_log: true`,
+		},
+		registry.Rule{
+			ID: "ANS008", Name: "Plaintext secret variable", Severity: finding.Warning, Category: "secrets", Scanners: scanners,
+			Compliance:  registry.HardcodedSecrets,
+			Description: "A variable with a secret-like name holds a plaintext value instead of a vaulted one.",
+			Messages:    []string{"Variable '*' in * holds a plaintext secret; *"},
+			Remediation: "ansible-vault encrypt_string 's3cr3t' --name db_password >> group_vars/all/vault.yml",
+		},
+		registry.Rule{
+			ID: "ANS009", Name: "Secret with hardcoded fallback", Severity: finding.Error, Category: "secrets", Scanners: scanners,
+			Compliance:  registry.HardcodedSecrets,
+			Description: "A secret variable is templated with a default(...) fallback, which is used whenever the real value is missing.",
+			Messages:    []string{"Secret variable '*' in * falls back to a hardcoded default; *"},
+			Remediation: `password: "{{ vault_db_password | mandatory }}"`,
+		},
+		registry.Rule{
+			ID: "ANS010", Name: "Invalid Jinja2", Severity: finding.Warning, Category: "correctness", Scanners: scanners,
+			Description: "A templated value or template file does not parse as Jinja2 and fails when rendered.",
+			Messages:    []string{"Invalid Jinja2 expression in *: *", "Invalid Jinja2 syntax in template: *"},
+			Remediation: `msg: "{{ item.name }}" # every {{ closed by }}, every {% by %}`,
+		},
+		registry.Rule{
+			ID: "ANS011", Name: "Deprecated or unknown Jinja2 filter", Severity: finding.Warning, Category: "correctness", Scanners: scanners,
+			Description: "A Jinja2 expression uses a deprecated filter, or one neither built in nor qualified with its collection.",
+			Messages:    []string{"Deprecated filter '*' in *: *", "Unknown Jinja2 filter '*' in * *"},
+			Remediation: `# qualify collection filters and replace deprecated ones
+value: "{{ data | community.general.json_query('items[*].id') }}"`,
+		},
+		registry.Rule{
+			ID: "ANS012", Name: "Template escaping disabled", Severity: finding.Warning, Category: "injection", Scanners: scanners,
+			Compliance:  registry.Injection,
+			Description: "A template marks a value safe or turns autoescape off, so user-controlled input is rendered unescaped.",
+			Messages:    []string{"'* | safe' in template disables escaping; *", "'{% autoescape false %}' in template disables escaping for the whole block"},
+			Remediation: `{# let autoescape escape user input instead of | safe #}
+<p>{{ user_comment }}</p>`,
+		},
+		registry.Rule{
+			ID: "ANS013", Name: "Command lookup in template", Severity: finding.Warning, Category: "injection", Scanners: scanners,
+			Compliance:  registry.Injection,
+			Description: "A template runs a command through lookup('pipe') with templated input.",
+			Messages:    []string{"Template runs a command through lookup('pipe') with templated input *"},
+			Remediation: `# run the command in a task and read its registered output
+- name: Read the release
+  ansible.builtin.command: cat /etc/release
+  register: release
+  changed_when: false`,
+		},
+		registry.Rule{
+			ID: "ANS014", Name: "Undefined variable", Severity: finding.Warning, Category: "correctness", Scanners: scanners,
+			Description: "A variable is used before any play, task, vars file or the scope rendering the template defines it.",
+			Messages: []string{
+				"Variable '*' used in template is not defined where the template is rendered *",
+				"Variable '*' used by * before any earlier play, task or vars file defines it *",
+			},
+			Remediation: `- name: Configure app
+  hosts: app
+  vars:
+    app_port: 8080`,
+		},
+		registry.Rule{
+			ID: "ANS015", Name: "Hardcoded secret in template", Severity: finding.Error, Category: "secrets", Scanners: scanners,
+			Compliance:  registry.HardcodedSecrets,
+			Description: "A template contains a literal secret instead of rendering it from a vaulted variable.",
+			Messages:    []string{"Possible hardcoded secret '*' in template; render it from a vaulted variable instead"},
+			Remediation: "password={{ vault_app_password }}",
+		},
+		registry.Rule{
+			ID: "ANS016", Name: "Unused registered variable", Severity: finding.Info, Category: "hygiene", Scanners: scanners,
+			Description: "A task registers a result nothing later reads.",
+			Messages:    []string{"Registered variable '*' (*) is never referenced afterwards"},
+			Remediation: `# drop register: when nothing reads the result
+- name: Restart nginx
+  ansible.builtin.service:
+    name: nginx
+    state: restarted`,
+		},
+		registry.Rule{
+			ID: "ANS017", Name: "Registered variable shadows play variable", Severity: finding.Warning, Category: "correctness", Scanners: scanners,
+			Description: "A registered variable has the name of a play variable and silently replaces it for the rest of the play.",
+			Messages:    []string{"Registered variable '*' (*) shadows the play variable of the same name"},
+			Remediation: `- name: Check the service
+  ansible.builtin.command: systemctl is-active nginx
+  register: nginx_status # a name no play variable uses`,
+		},
+		registry.Rule{
+			ID: "ANS018", Name: "Result of a conditional task read unguarded", Severity: finding.Warning, Category: "correctness", Scanners: scanners,
+			Description: "A task reads a field of a result registered by a task with a when: condition, which is missing when that task is skipped.",
+			Messages:    []string{"* reads '*.*' but * registering it has a when: condition and may be skipped *"},
+			Remediation: "when: result is not skipped and result.rc == 0",
+		},
+		registry.Rule{
+			ID: "ANS019", Name: "Unpinned requirement", Severity: finding.Warning, Category: "supply-chain", Scanners: scanners,
+			Compliance:  registry.SupplyChain,
+			Description: "A role or collection in requirements.yml has no version, an open-ended one, or tracks a git branch.",
+			Messages:    []string{"* '*' in requirements *; pin *", "* '*' in requirements has no version pin; *"},
+			Remediation: `roles:
+  - name: geerlingguy.docker
+    version: 7.4.1
+collections:
+  - name: community.general
+    version: ">=9.0.0,<10.0.0"`,
+		},
+		registry.Rule{
+			ID: "ANS020", Name: "Unknown play or block keyword", Severity: finding.Error, Category: "correctness", Scanners: scanners,
+			Description: "With --strict, a play or block has a key that is not an Ansible keyword, usually a misspelling, which ansible-playbook rejects when it loads the play.",
+			Messages:    []string{"Unknown play keyword '*' in play '*'; *", "Unknown block keyword '*' in block '*'; *"},
+			Remediation: `- name: Configure web servers
+  hosts: webservers
+  gather_facts: false # not gather_fact`,
+		},
+		registry.Rule{
+			ID: "ANS021", Name: "Misspelled task keyword", Severity: finding.Error, Category: "correctness", Scanners: scanners,
+			Description: "With --strict, a task has, besides its module, a key one or two letters away from a task keyword, such as whne for when.",
+			Messages:    []string{"Unknown task keyword '*' in task '*'; did you mean '*'?"},
+			Remediation: `- name: Install nginx
+  ansible.builtin.apt:
+    name: nginx
+  when: ansible_os_family == "Debian"`,
+		},
+		registry.Rule{
+			ID: "ANS022", Name: "Task with several modules", Severity: finding.Error, Category: "correctness", Scanners: scanners,
+			Description: "With --strict, a task has more than one key that is neither a task keyword nor close to one, so all but one of them are unknown keywords and Ansible reports conflicting action statements.",
+			Messages:    []string{"Task '*' has more than one module key (*); *"},
+			Remediation: "# keep one module per task, and spell the other keys as task keywords",
+		},
+		registry.Rule{
+			ID: "ANS023", Name: "Needless root escalation", Severity: finding.Warning, Category: "privilege", Scanners: scanners,
+			Compliance:  registry.PrivilegedWorkloads,
+			Description: "A task sets become, or its block does, and runs as root, but its module only reads facts, calls an API or steers the play and never needs root.",
+			Messages:    []string{"Task '*' becomes root to run '*', which does not need root; *"},
+			Remediation: `- name: Probe health
+  ansible.builtin.uri:
+    url: http://localhost/health
+  # no become; or allow the task in ansible.become.allow_root`,
+		},
+		registry.Rule{
+			ID: "ANS024", Name: "Package installed with state: latest", Severity: finding.Warning, Category: "supply-chain", Scanners: scanners,
+			Compliance:  registry.SupplyChain,
+			Description: "An apt, yum, dnf, package or pip task uses state: latest, upgrading to whatever version the repository has when the playbook runs.",
+			Messages:    []string{"Task '*' installs * with state: latest, so each run may install a different version; *"},
+			Remediation: `- name: Install nginx
+  ansible.builtin.apt:
+    name: nginx=1.18.0-6ubuntu14
+    state: present`,
+		},
+		registry.Rule{
+			ID: "ANS025", Name: "Package without a version pin", Severity: finding.Warning, Category: "supply-chain", Scanners: scanners,
+			Compliance:  registry.SupplyChain,
+			Description: "An apt, yum, dnf, package or pip task names packages without a version, so hosts provisioned at different times get different versions.",
+			Messages:    []string{"Task '*' installs * with '*' without a version pin, so each run may install a different version"},
+			Remediation: `- name: Install the client libraries
+  ansible.builtin.pip:
+    name:
+      - requests==2.31.0
+      - flask==3.0.3`,
+		},
+		registry.Rule{
+			ID: "ANS026", Name: "World-writable file", Severity: finding.Warning, Category: "permissions", Scanners: scanners,
+			Compliance:  registry.FilePermissions,
+			Description: "A file, copy or template task sets a mode, such as 0777, 0666 or o+w, that lets every local user modify what it creates.",
+			Messages:    []string{"Task '*' makes * world-writable (mode *); any local user can modify it"},
+			Remediation: `- name: Create the application directory
+  ansible.builtin.file:
+    path: /srv/app
+    state: directory
+    mode: "0755"`,
+		},
+		registry.Rule{
+			ID: "ANS027", Name: "Unquoted variable in a command", Severity: finding.Warning, Category: "injection", Scanners: scanners,
+			Compliance:  registry.Injection,
+			Description: "A shell, raw or command task interpolates variables into its command line without | quote: through shell and raw a value can run commands of its own, through command it can add arguments.",
+			Messages:    []string{"Task '*' interpolates * unquoted into the command line of '*'; *"},
+			Remediation: `- name: Restore the dump
+  ansible.builtin.command:
+    argv: [pg_restore, -d, "{{ db_name }}", "{{ dump_file }}"]
+# or, where a shell is needed: pg_restore -d {{ db_name | quote }} ...`,
+		},
+	)
+}
@@ -0,0 +1,186 @@
+package ansible
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// Play keywords, as documented for ansible-core; a play takes no other key.
+var playKeywords = toSet(
+	"name", "hosts", "gather_facts", "gather_subset", "gather_timeout", "fact_path",
+	"become", "become_user", "become_method", "become_flags", "become_exe",
+	"remote_user", "connection", "port", "vars", "vars_files", "vars_prompt",
+	"pre_tasks", "tasks", "post_tasks", "handlers", "roles", "tags", "environment",
+	"serial", "strategy", "max_fail_percentage", "any_errors_fatal", "ignore_errors",
+	"ignore_unreachable", "no_log", "check_mode", "diff", "collections", "module_defaults",
+	"order", "run_once", "throttle", "timeout", "force_handlers", "debugger",
+	"import_playbook", "ansible.builtin.import_playbook", "when",
+)
+
+// Block keywords: the task keywords that apply to the tasks of a block, which runs no module.
+var blockKeywords = toSet(
+	"name", "when", "tags", "vars", "environment", "notify",
+	"become", "become_user", "become_method", "become_flags", "become_exe",
+	"delegate_to", "delegate_facts", "run_once", "ignore_errors", "ignore_unreachable",
+	"check_mode", "diff", "no_log", "throttle", "timeout", "any_errors_fatal",
+	"collections", "module_defaults", "connection", "remote_user", "port", "debugger",
+	"block", "rescue", "always",
+)
+
+// checkStrictPlays checks the keys of the plays in data, and of their tasks, against the
+// keywords Ansible knows. Ansible only reports a misspelled keyword when it loads the play.
+func checkStrictPlays(p string, data []byte) []finding.Finding {
+	var plays []map[string]interface{}
+	if yaml.Unmarshal(data, &plays) != nil {
+		return nil
+	}
+	var findings []finding.Finding
+	for i, play := range plays {
+		name, _ := play["name"].(string)
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		for _, key := range sortedMapKeys(play) {
+			if playKeywords[key] {
+				continue
+			}
+			findings = append(findings, finding.Finding{
+				File:     p,
+				Severity: finding.Error,
+				Message:  fmt.Sprintf("Unknown play keyword '%s' in play '%s'; %s", key, name, suggestKeyword(key, playKeywords, "Ansible rejects the play")),
+			})
+		}
+		for _, section := range []string{"pre_tasks", "tasks", "post_tasks", "handlers"} {
+			findings = append(findings, checkStrictTasks(p, toTasks(play[section]))...)
+		}
+	}
+	return findings
+}
+
+// checkStrictTasks flags, in tasks and the blocks among them, the keys besides the module
+// that look like a misspelled keyword, and tasks left naming more than one module, as a
+// misspelling too far from any keyword makes them. Any other key is taken for the module.
+func checkStrictTasks(p string, tasks []Task) []finding.Finding {
+	var findings []finding.Finding
+	for _, task := range tasks {
+		name, _ := task["name"].(string)
+		if _, isBlock := task["block"]; isBlock {
+			if name == "" {
+				name = "unnamed"
+			}
+			for _, key := range sortedMapKeys(task) {
+				if !blockKeywords[key] {
+					findings = append(findings, finding.Finding{
+						File:     p,
+						Severity: finding.Error,
+						Message:  fmt.Sprintf("Unknown block keyword '%s' in block '%s'; %s", key, name, suggestKeyword(key, blockKeywords, "Ansible rejects the block")),
+					})
+				}
+			}
+			for _, section := range []string{"block", "rescue", "always"} {
+				findings = append(findings, checkStrictTasks(p, toTasks(task[section]))...)
+			}
+			continue
+		}
+
+		var candidates, modules, typos []string
+		for _, key := range sortedMapKeys(task) {
+			if !taskKeywords[key] && key != "action" && !strings.HasPrefix(key, "with_") {
+				candidates = append(candidates, key)
+			}
+		}
+		// a single candidate is the module, even when its name is close to a keyword, as make is to name
+		for _, key := range candidates {
+			if len(candidates) > 1 && !strings.Contains(key, ".") && closestKeyword(key, taskKeywords) != "" {
+				typos = append(typos, key)
+			} else {
+				modules = append(modules, key)
+			}
+		}
+		if name == "" && len(modules) > 0 {
+			name = modules[0]
+		}
+		for _, key := range typos {
+			findings = append(findings, finding.Finding{
+				File:     p,
+				Severity: finding.Error,
+				Message:  fmt.Sprintf("Unknown task keyword '%s' in task '%s'; did you mean '%s'?", key, name, closestKeyword(key, taskKeywords)),
+			})
+		}
+		if len(modules) > 1 {
+			findings = append(findings, finding.Finding{
+				File:     p,
+				Severity: finding.Error,
+				Message:  fmt.Sprintf("Task '%s' has more than one module key (%s); all but one are unknown keywords, which Ansible rejects as conflicting action statements", name, quoteList(modules)),
+			})
+		}
+	}
+	return findings
+}
+
+// suggestKeyword names the keyword of known that key is most likely a misspelling of, or
+// returns otherwise.
+func suggestKeyword(key string, known map[string]bool, otherwise string) string {
+	if k := closestKeyword(key, known); k != "" {
+		return fmt.Sprintf("did you mean '%s'?", k)
+	}
+	return otherwise
+}
+
+// closestKeyword returns the keyword of known within one edit of key (two for keywords
+// longer than five characters), counting a swap of neighbouring letters as one edit, or ""
+// when there is none. Short keywords allow a single edit so that modules such as apt are
+// not mistaken for args.
+func closestKeyword(key string, known map[string]bool) string {
+	best, bestDistance := "", 0
+	for _, k := range sortedMapKeys(known) {
+		limit := 1
+		if len(k) > 5 {
+			limit = 2
+		}
+		if d := editDistance(strings.ToLower(key), k); d <= limit && (best == "" || d < bestDistance) {
+			best, bestDistance = k, d
+		}
+	}
+	return best
+}
+
+// editDistance is the optimal string alignment distance between a and b: insertions,
+// deletions, substitutions and transpositions of adjacent characters.
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func sortedMapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,210 @@
+package ansible
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// Jinja2 template (.j2) files.
+//
+// A template is checked with the same tokenizer as templated task values. Its variables
+// are cross-checked against what is in scope at the template tasks rendering it, which
+// the execution-order walk records; templates no task renders are only linted.
+
+var templateModules = []string{"template", "ansible.builtin.template", "win_template", "ansible.windows.win_template"}
+
+// Variables the template module adds while rendering.
+var templateVars = toSet("ansible_managed", "template_host", "template_path", "template_fullpath",
+	"template_destpath", "template_uid", "template_run_date")
+
+// Lines assigning a literal to a secret-like setting, as in config files: key = value, key: value.
+var templateSecretRegex = regexp.MustCompile(`(?i)^\s*(?:export\s+)?([A-Za-z0-9_.-]*(?:password|passwd|pwd|secret|token|api_key|apikey|private_key)[A-Za-z0-9_.-]*)\s*[:=]\s*(.*)$`)
+
+// templateSrc returns the src: of a template task, or "" for other tasks and templated paths.
+func templateSrc(task Task) string {
+	for _, k := range templateModules {
+		var src string
+		switch v := task[k].(type) {
+		case string:
+			for _, field := range strings.Fields(v) {
+				if value, ok := strings.CutPrefix(field, "src="); ok {
+					src = value
+				}
+			}
+		default:
+			if args, ok := asMap(v); ok {
+				src, _ = args["src"].(string)
+			}
+		}
+		if src != "" && !strings.Contains(src, "{{") {
+			return src
+		}
+	}
+	return ""
+}
+
+// recordTemplate adds the names in scope at a template task to the template it renders.
+func (a *orderAnalyzer) recordTemplate(task Task, scopes []*varScope) {
+	src := templateSrc(task)
+	if a.rendered == nil || src == "" {
+		return
+	}
+	p := resolveFile(src, a.templateDirs)
+	if p == "" {
+		return
+	}
+	scope := a.rendered[p]
+	if scope == nil {
+		scope = newScope()
+		a.rendered[p] = scope
+	}
+	for _, s := range scopes {
+		scope.opaque = scope.opaque || s.opaque
+		for name := range s.vars {
+			scope.define(name)
+		}
+	}
+}
+
+// templateFileVars returns the variables read by the template files that tasks render,
+// so variables only a template uses do not count as unused.
+func templateFileVars(tasks []Task, dirs []string) []string {
+	var vars []string
+	for _, task := range tasks {
+		if _, isBlock := task["block"]; isBlock {
+			for _, section := range []string{"block", "rescue", "always"} {
+				vars = append(vars, templateFileVars(toTasks(task[section]), dirs)...)
+			}
+			continue
+		}
+		src := templateSrc(task)
+		if src == "" {
+			continue
+		}
+		if p := resolveFile(src, dirs); p != "" {
+			if data, err := os.ReadFile(p); err == nil {
+				vars = append(vars, parseTemplate(string(data)).vars()...)
+			}
+		}
+	}
+	return vars
+}
+
+// templateDirs returns where a role's template tasks look up src: paths.
+func (r *role) templateDirs() []string {
+	return []string{filepath.Join(r.dir, "templates"), r.dir}
+}
+
+// checkTemplateFile checks a .j2 template: syntax, filters, hardcoded secrets, unsafe
+// constructs and, when scope is known, variables no rendering task defines.
+func checkTemplateFile(p string, scope *varScope) []finding.Finding {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return []finding.Finding{{File: p, Severity: finding.Error, Message: fmt.Sprintf("Failed to read file: %v", err)}}
+	}
+	text := string(data)
+
+	var findings []finding.Finding
+	reported := make(map[string]bool)
+	line := 0
+	report := func(sev finding.Severity, msg string) {
+		if !reported[msg] {
+			reported[msg] = true
+			findings = append(findings, finding.Finding{File: p, Severity: sev, Message: msg, Line: line})
+		}
+	}
+
+	whole := parseTemplate(text)
+	if whole.err != nil {
+		report(finding.Warning, fmt.Sprintf("Invalid Jinja2 syntax in template: %v", whole.err))
+	}
+	blocks, _ := splitTemplate(text)
+
+	// Names tested with 'is defined' or defaulted anywhere are treated as guarded throughout.
+	guarded := make(map[string]bool)
+	parsed := make([]jinjaTemplate, len(blocks))
+	for i, b := range blocks {
+		parsed[i] = jinjaTemplate{locals: whole.locals}
+		parsed[i].block(b)
+		if isGuarded(parsed[i]) {
+			for _, v := range parsed[i].vars() {
+				guarded[v] = true
+			}
+		}
+	}
+
+	for i, b := range blocks {
+		t := parsed[i]
+		line = b.line
+		lintFilters(t, "template", report)
+		for _, e := range t.exprs {
+			for _, f := range e.filters {
+				if strings.TrimPrefix(f.name, "ansible.builtin.") == "safe" && f.subject != "" {
+					report(finding.Warning, fmt.Sprintf("'%s | safe' in template disables escaping; make sure '%s' cannot carry user-controlled input", f.subject, f.subject))
+				}
+			}
+		}
+		toks, _ := lexJinja(b.body)
+		if b.kind == '%' && len(toks) > 1 && toks[0].val == "autoescape" && strings.EqualFold(toks[1].val, "false") {
+			report(finding.Warning, "'{% autoescape false %}' in template disables escaping for the whole block")
+		}
+		if pipeLookupWithVars(toks) {
+			report(finding.Warning, "Template runs a command through lookup('pipe') with templated input (possible command injection)")
+		}
+
+		if scope == nil || scope.opaque {
+			continue
+		}
+		for _, v := range t.vars() {
+			if guarded[v] || templateVars[v] || isDefined(v, []*varScope{scope}) {
+				continue
+			}
+			report(finding.Warning, fmt.Sprintf("Variable '%s' used in template is not defined where the template is rendered (possible undefined variable at runtime)", v))
+		}
+	}
+
+	for i, l := range strings.Split(text, "\n") {
+		line = i + 1
+		trimmed := strings.TrimSpace(l)
+		if strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";") || strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+		m := templateSecretRegex.FindStringSubmatch(l)
+		if m == nil {
+			continue
+		}
+		value := strings.Trim(strings.TrimSpace(m[2]), `"';,`)
+		if value == "" || strings.Contains(value, "{{") || strings.Contains(value, "{%") {
+			continue
+		}
+		report(finding.Error, fmt.Sprintf("Possible hardcoded secret '%s' in template; render it from a vaulted variable instead", m[1]))
+	}
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Line < findings[j].Line })
+	return findings
+}
+
+// pipeLookupWithVars reports whether toks call lookup/query('pipe', ...) with a variable
+// in the command, which runs it through the shell on the controller.
+func pipeLookupWithVars(toks []token) bool {
+	for i := 0; i+2 < len(toks); i++ {
+		switch toks[i].val {
+		case "lookup", "query", "q":
+		default:
+			continue
+		}
+		if toks[i].kind != tokName || toks[i+1].val != "(" || toks[i+2].kind != tokString || toks[i+2].val != "pipe" {
+			continue
+		}
+		args := toks[i+2 : matchingParen(toks, i+1)]
+		if len(analyzeTokens(args).vars) > 0 {
+			return true
+		}
+	}
+	return false
+}
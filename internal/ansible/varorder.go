@@ -0,0 +1,654 @@
+package ansible
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// Execution-order variable analysis.
+//
+// Unlike the per-file defined/used comparison in Scan, this walks plays and tasks in
+// the order Ansible runs them and flags variables a task references before anything
+// earlier has defined them. Scopes follow Ansible semantics:
+// - host scope (group_vars/host_vars, set_fact, register) persists across plays
+// - play scope (vars, vars_files) is reset for every play
+// - block and task vars only apply to the tasks they wrap
+// - role defaults, vars and parameters only apply to the role's tasks
+// Included task files, roles and imported playbooks are walked in place when their names are
+// literal. Anything we cannot resolve statically (unresolvable roles and includes,
+// include_vars, ...) makes the rest of the play "opaque" and silences reports, preferring
+// misses over noise.
+
+// Variables Ansible always provides.
+var magicVars = map[string]bool{
+	"hostvars": true, "groups": true, "group_names": true, "inventory_hostname": true,
+	"inventory_hostname_short": true, "inventory_dir": true, "inventory_file": true,
+	"play_hosts": true, "playbook_dir": true, "role_path": true, "role_name": true,
+	"omit": true, "environment": true, "lookup": true, "query": true, "q": true,
+	"now": true, "range": true, "dict": true, "lipsum": true, "undef": true,
+}
+
+// Task keywords whose values are raw Jinja2 expressions (no surrounding {{ }}).
+var conditionalKeys = []string{"when", "changed_when", "failed_when", "until"}
+
+// Task keywords that pull in content we do not follow, so anything may be defined after them.
+var opaqueKeys = []string{
+	"include_vars", "ansible.builtin.include_vars",
+	"include_tasks", "ansible.builtin.include_tasks",
+	"import_tasks", "ansible.builtin.import_tasks",
+	"include_role", "ansible.builtin.include_role",
+	"import_role", "ansible.builtin.import_role",
+	"include", "ansible.builtin.include",
+}
+
+var setFactKeys = []string{"set_fact", "ansible.builtin.set_fact"}
+
+// Jinja2 words that look like identifiers but are not variables.
+var jinjaKeywords = map[string]bool{
+	"and": true, "or": true, "not": true, "in": true, "is": true, "if": true, "else": true,
+	"true": true, "false": true, "none": true, "True": true, "False": true, "None": true,
+}
+
+// varScope is a set of defined names; opaque means unknown content may define anything.
+type varScope struct {
+	vars   map[string]bool
+	opaque bool
+}
+
+func newScope() *varScope { return &varScope{vars: make(map[string]bool)} }
+
+func (s *varScope) define(name string) { s.vars[name] = true }
+
+func (s *varScope) defineAll(m map[string]interface{}) {
+	for k := range m {
+		s.define(k)
+	}
+}
+
+// orderAnalyzer holds state for one playbook file.
+type orderAnalyzer struct {
+	file        string
+	playbookDir string
+	host        *varScope
+	play        *varScope // vars of the play being walked
+	findings    []finding.Finding
+	reported    map[string]bool
+
+	// Registered results in registration order, for hygiene checks.
+	registered map[string]*registration
+	registers  []string
+
+	// Where template src: paths are looked up, and the names in scope where each
+	// template is rendered (nil when not collected).
+	templateDirs []string
+	rendered     map[string]*varScope
+
+	// Files being walked through includes and imports, to stop cycles.
+	including map[string]bool
+}
+
+// registration records a task's register: and how it was used afterwards.
+type registration struct {
+	task        string
+	conditional bool // the registering task has a when: condition and may be skipped
+	used        bool
+}
+
+// taskRef identifies the task an expression belongs to, with its own when: conditions.
+type taskRef struct {
+	label string
+	when  []string
+}
+
+func newTaskRef(task Task) taskRef {
+	label := "unnamed task"
+	if name, _ := task["name"].(string); name != "" {
+		label = fmt.Sprintf("task '%s'", name)
+	}
+	ref := taskRef{label: label}
+	switch w := task["when"].(type) {
+	case string:
+		ref.when = []string{w}
+	case []interface{}:
+		for _, item := range w {
+			ref.when = append(ref.when, fmt.Sprint(item))
+		}
+	}
+	return ref
+}
+
+// checkExecutionOrder reports variables referenced before they are defined in execution order,
+// and registered variables that are unused, shadow play vars or are read after possibly being skipped.
+// The variables in scope at every template task are added to rendered, keyed by template path.
+func checkExecutionOrder(p string, plays []Play, rendered map[string]*varScope) []finding.Finding {
+	a := &orderAnalyzer{
+		host:       newScope(),
+		reported:   make(map[string]bool),
+		registered: make(map[string]*registration),
+		rendered:   rendered,
+		including:  map[string]bool{filepath.Clean(p): true},
+	}
+
+	// Inventory variables next to the playbook are available to every play.
+	for _, sub := range []string{"group_vars", "host_vars"} {
+		loadVarsDir(filepath.Join(filepath.Dir(p), sub), a.host)
+	}
+	a.walkPlays(p, plays)
+
+	// Included files and roles may read registered results we never saw.
+	if !a.host.opaque {
+		for _, name := range a.registers {
+			if reg := a.registered[name]; !reg.used {
+				a.findings = append(a.findings, finding.Finding{
+					File:     p,
+					Severity: finding.Info,
+					Message:  fmt.Sprintf("Registered variable '%s' (%s) is never referenced afterwards", name, reg.task),
+				})
+			}
+		}
+	}
+	return a.findings
+}
+
+// walkPlays walks the plays of playbook p in order, following import_playbook entries
+// with the host scope carried over.
+func (a *orderAnalyzer) walkPlays(p string, plays []Play) {
+	file, playbookDir, templateDirs := a.file, a.playbookDir, a.templateDirs
+	dir := filepath.Dir(p)
+	a.file, a.playbookDir = p, dir
+	a.templateDirs = []string{filepath.Join(dir, "templates"), dir}
+	defer func() { a.file, a.playbookDir, a.templateDirs = file, playbookDir, templateDirs }()
+
+	for _, play := range plays {
+		if name, ok := play.importedPlaybook(); ok {
+			target := resolveFile(name, []string{dir})
+			var imported []Play
+			if data, err := os.ReadFile(target); target == "" || a.including[target] || err != nil || yaml.Unmarshal(data, &imported) != nil {
+				a.host.opaque = true
+				continue
+			}
+			a.including[target] = true
+			a.walkPlays(target, imported)
+			delete(a.including, target)
+			continue
+		}
+
+		scope := newScope()
+		scope.defineAll(play.Vars)
+		for _, vf := range play.VarsFiles {
+			if !loadVarsFile(dir, vf, scope) {
+				scope.opaque = true
+			}
+		}
+		a.play = scope
+
+		scopes := []*varScope{a.host, scope}
+		a.walkTasks(play.PreTasks, scopes)
+		for _, entry := range play.Roles {
+			a.walkRole(dir, entry, scopes)
+		}
+		a.walkTasks(play.Tasks, scopes)
+		a.walkTasks(play.PostTasks, scopes)
+
+		// Handlers run at unpredictable points; they only count as readers of registered results.
+		for _, h := range play.Handlers {
+			var exprs []string
+			collectTemplates(map[string]interface{}(h), &exprs)
+			for _, v := range exprs {
+				a.markVars(parseTemplate(v).vars())
+			}
+			for _, expr := range conditionalValues(h) {
+				a.markVars(expressionVars(expr))
+			}
+		}
+	}
+}
+
+// walkRole walks the tasks of a role applied by a play, reporting against the role's task file.
+// A role that cannot be resolved, or has dependencies, makes the play opaque.
+func (a *orderAnalyzer) walkRole(playbookDir string, entry interface{}, scopes []*varScope) {
+	r, ok := resolveRole(playbookDir, entry)
+	if !ok || r.dependencies {
+		a.play.opaque = true
+		return
+	}
+	a.walkRoleTasks(r, entry, scopes)
+}
+
+// walkRoleTasks walks a resolved role's tasks with its defaults, vars and parameters in scope.
+func (a *orderAnalyzer) walkRoleTasks(r *role, entry interface{}, scopes []*varScope) {
+	local := newScope()
+	local.defineAll(r.defaults)
+	local.defineAll(r.vars)
+	local.defineAll(roleParams(entry))
+	file, templateDirs := a.file, a.templateDirs
+	a.file = r.tasksFile
+	a.templateDirs = append(r.templateDirs(), templateDirs...)
+	a.walkTasks(r.tasks, append(append([]*varScope{}, scopes...), local))
+	a.file, a.templateDirs = file, templateDirs
+}
+
+func (a *orderAnalyzer) walkTasks(tasks []Task, scopes []*varScope) {
+	for _, task := range tasks {
+		a.walkTask(task, scopes)
+	}
+}
+
+func (a *orderAnalyzer) walkTask(task Task, scopes []*varScope) {
+	local := newScope()
+	if vars, ok := asMap(task["vars"]); ok {
+		local.defineAll(vars)
+	}
+	if loopVar := taskLoopVar(task); loopVar != "" {
+		local.define(loopVar)
+	}
+	inner := append(append([]*varScope{}, scopes...), local)
+	ref := newTaskRef(task)
+
+	// Blocks: check the nested task lists in order with the block's vars in scope.
+	if _, isBlock := task["block"]; isBlock {
+		for _, section := range []string{"block", "rescue", "always"} {
+			a.walkTasks(toTasks(task[section]), inner)
+		}
+		a.checkExprs(ref, conditionalValues(task), inner)
+		return
+	}
+
+	// Arguments are evaluated before the task registers anything.
+	keys := make([]string, 0, len(task))
+	for k := range task {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var args []string
+	for _, k := range keys {
+		if k == "vars" || k == "register" || isConditionalKey(k) {
+			continue
+		}
+		collectTemplates(task[k], &args)
+	}
+	a.checkTemplates(ref, args, inner)
+	a.recordTemplate(task, inner)
+
+	// when: is evaluated before the task runs; the registered result is only
+	// visible to the task's own retry/changed/failed conditions.
+	a.checkExprs(ref, conditionalValues(Task{"when": task["when"]}), inner)
+	register, _ := task["register"].(string)
+	if register != "" {
+		local.define(register)
+	}
+	a.checkExprs(ref, conditionalValues(Task{
+		"changed_when": task["changed_when"],
+		"failed_when":  task["failed_when"],
+		"until":        task["until"],
+	}), inner)
+
+	// Definitions that outlive the task, in host scope.
+	if register != "" {
+		a.register(register, ref, task)
+	}
+	for _, k := range setFactKeys {
+		if facts, ok := asMap(task[k]); ok {
+			for fact := range facts {
+				if fact != "cacheable" {
+					a.host.define(fact)
+				}
+			}
+		}
+	}
+	if a.followInclude(task, inner) {
+		return
+	}
+	for _, k := range opaqueKeys {
+		if _, ok := task[k]; ok {
+			a.host.opaque = true
+		}
+	}
+}
+
+// followInclude walks what an include_tasks/import_tasks or include_role/import_role task
+// pulls in, reporting against the included file, and reports whether it could resolve it.
+func (a *orderAnalyzer) followInclude(task Task, scopes []*varScope) bool {
+	if name, ok := includeFile(task); ok {
+		p := resolveFile(name, []string{filepath.Dir(a.file), a.playbookDir})
+		tasks, ok := loadTaskFile(p)
+		if !ok || a.including[p] {
+			return false
+		}
+		file := a.file
+		a.file, a.including[p] = p, true
+		a.walkTasks(tasks, scopes)
+		a.file = file
+		delete(a.including, p)
+		return true
+	}
+	if entry, tasksFrom, ok := includeRole(task); ok && entry != nil {
+		r, ok := resolveIncludedRole(a.playbookDir, entry, tasksFrom)
+		if !ok || r.dependencies || a.including[r.tasksFile] {
+			return false
+		}
+		a.including[r.tasksFile] = true
+		a.walkRoleTasks(r, entry, scopes)
+		delete(a.including, r.tasksFile)
+		return true
+	}
+	return false
+}
+
+// register records a task's registered result, flagging names that shadow play vars.
+func (a *orderAnalyzer) register(name string, ref taskRef, task Task) {
+	if a.play != nil && a.play.vars[name] {
+		a.findings = append(a.findings, finding.Finding{
+			File:     a.file,
+			Severity: finding.Warning,
+			Message:  fmt.Sprintf("Registered variable '%s' (%s) shadows the play variable of the same name", name, ref.label),
+		})
+	}
+	a.host.define(name)
+	if _, seen := a.registered[name]; !seen {
+		a.registers = append(a.registers, name)
+	}
+	_, conditional := task["when"]
+	a.registered[name] = &registration{task: ref.label, conditional: conditional}
+}
+
+func (a *orderAnalyzer) checkExprs(ref taskRef, exprs []string, scopes []*varScope) {
+	for _, expr := range exprs {
+		a.checkExpr(ref, expr, parseExpression(expr), scopes)
+	}
+}
+
+func (a *orderAnalyzer) checkTemplates(ref taskRef, values []string, scopes []*varScope) {
+	for _, v := range values {
+		t := parseTemplate(v)
+		local := newScope()
+		for name := range t.locals {
+			local.define(name)
+		}
+		inner := append(append([]*varScope{}, scopes...), local)
+		// Each expression is guarded on its own: a default in one does not cover the others.
+		for _, e := range t.exprs {
+			a.checkExpr(ref, v, jinjaTemplate{exprs: []exprInfo{e}, locals: t.locals}, inner)
+		}
+	}
+}
+
+func (a *orderAnalyzer) checkExpr(ref taskRef, text string, t jinjaTemplate, scopes []*varScope) {
+	vars := t.vars()
+	a.markVars(vars)
+	a.checkSkippedResults(ref, text, t)
+
+	// Guarded references are intentional.
+	if isGuarded(t) {
+		return
+	}
+	for _, s := range scopes {
+		if s.opaque {
+			return
+		}
+	}
+	for _, v := range vars {
+		if isDefined(v, scopes) || a.reported[v] {
+			continue
+		}
+		a.reported[v] = true
+		a.findings = append(a.findings, finding.Finding{
+			File:     a.file,
+			Severity: finding.Warning,
+			Message:  fmt.Sprintf("Variable '%s' used by %s before any earlier play, task or vars file defines it (possible undefined variable at runtime)", v, ref.label),
+		})
+	}
+}
+
+// markVars marks the registered results among vars as used.
+func (a *orderAnalyzer) markVars(vars []string) {
+	for _, v := range vars {
+		if reg := a.registered[v]; reg != nil {
+			reg.used = true
+		}
+	}
+}
+
+// checkSkippedResults flags reads of .stdout on results registered by conditional tasks:
+// when the task is skipped the registered value has no stdout and the read fails.
+func (a *orderAnalyzer) checkSkippedResults(ref taskRef, expr string, t jinjaTemplate) {
+	if isGuarded(t) || strings.Contains(expr, "skipped") {
+		return
+	}
+	for _, m := range stdoutRefRegex.FindAllStringSubmatch(expr, -1) {
+		name := m[1]
+		reg := a.registered[name]
+		if reg == nil || !reg.conditional || a.reported[name+".stdout"] || guardsResult(ref.when, name) {
+			continue
+		}
+		a.reported[name+".stdout"] = true
+		a.findings = append(a.findings, finding.Finding{
+			File:     a.file,
+			Severity: finding.Warning,
+			Message:  fmt.Sprintf("%s reads '%s.%s' but %s registering it has a when: condition and may be skipped (guard with '%s is not skipped' or a default)", capitalize(ref.label), name, m[2], reg.task, name),
+		})
+	}
+}
+
+// Matches result.stdout / result.stdout_lines references
+var stdoutRefRegex = regexp.MustCompile(`\b([A-Za-z_][A-Za-z0-9_]*)\.(stdout_lines|stdout)\b`)
+
+// isGuarded reports whether an expression explicitly handles undefined values
+// with a default filter or a defined/undefined test.
+func isGuarded(t jinjaTemplate) bool {
+	for _, e := range t.exprs {
+		for _, f := range e.filters {
+			switch strings.TrimPrefix(f.name, "ansible.builtin.") {
+			case "default", "d":
+				return true
+			}
+		}
+		for _, test := range e.tests {
+			switch test {
+			case "defined", "undefined":
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// guardsResult reports whether any when: condition of the reading task mentions the result,
+// e.g. `when: result is not skipped` or `when: result.rc == 0`.
+func guardsResult(when []string, name string) bool {
+	for _, w := range when {
+		for _, v := range expressionVars(w) {
+			if v == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func isDefined(name string, scopes []*varScope) bool {
+	if magicVars[name] || strings.HasPrefix(name, "ansible_") {
+		return true
+	}
+	for _, s := range scopes {
+		if s.vars[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// expressionVars returns the root variable names referenced by a Jinja2 expression,
+// skipping string literals, attribute access, filter and test names and function calls.
+func expressionVars(expr string) []string {
+	return parseExpression(expr).vars()
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
+
+func isConditionalKey(k string) bool {
+	for _, c := range conditionalKeys {
+		if c == k {
+			return true
+		}
+	}
+	return false
+}
+
+// conditionalValues returns the raw expressions from when/changed_when/failed_when/until.
+func conditionalValues(task Task) []string {
+	var exprs []string
+	for _, k := range conditionalKeys {
+		switch v := task[k].(type) {
+		case string:
+			exprs = append(exprs, stripBraces(v))
+		case []interface{}:
+			for _, item := range v {
+				if s, ok := item.(string); ok {
+					exprs = append(exprs, stripBraces(s))
+				}
+			}
+		}
+	}
+	return exprs
+}
+
+func stripBraces(s string) string {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "{{") && strings.HasSuffix(s, "}}") {
+		return s[2 : len(s)-2]
+	}
+	return s
+}
+
+// collectTemplates gathers every string inside v that contains a {{ }} template.
+func collectTemplates(v interface{}, out *[]string) {
+	switch val := v.(type) {
+	case string:
+		if strings.Contains(val, "{{") {
+			*out = append(*out, val)
+		}
+	case []interface{}:
+		for _, item := range val {
+			collectTemplates(item, out)
+		}
+	case map[string]interface{}, Task:
+		m, _ := asMap(val)
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			collectTemplates(m[k], out)
+		}
+	}
+}
+
+// taskLoopVar returns the loop variable name for looping tasks ("item" unless overridden).
+func taskLoopVar(task Task) string {
+	looping := false
+	for k := range task {
+		if k == "loop" || strings.HasPrefix(k, "with_") {
+			looping = true
+			break
+		}
+	}
+	if !looping {
+		return ""
+	}
+	if lc, ok := asMap(task["loop_control"]); ok {
+		if lv, ok := lc["loop_var"].(string); ok && lv != "" {
+			return lv
+		}
+	}
+	return "item"
+}
+
+// asMap returns v as a plain map. Nested mappings inside a Task decode as Task values.
+func asMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case Task:
+		return m, true
+	}
+	return nil, false
+}
+
+func toTasks(v interface{}) []Task {
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+	var tasks []Task
+	for _, item := range list {
+		if m, ok := asMap(item); ok {
+			tasks = append(tasks, Task(m))
+		}
+	}
+	return tasks
+}
+
+// loadVarsFile adds the top-level keys of a vars_files entry to scope.
+// It reports false when the file cannot be resolved statically.
+func loadVarsFile(dir string, entry interface{}, scope *varScope) bool {
+	name, ok := entry.(string)
+	if !ok || strings.Contains(name, "{{") {
+		return false
+	}
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(dir, name)
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return false
+	}
+	var vars map[string]interface{}
+	if err := yaml.Unmarshal(data, &vars); err != nil {
+		return false
+	}
+	scope.defineAll(vars)
+	return true
+}
+
+// loadVarsDir adds keys from every YAML file under an inventory vars directory.
+func loadVarsDir(dir string, scope *varScope) {
+	scanner.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		ext := filepath.Ext(p)
+		if ext == ".yml" || ext == ".yaml" || ext == "" {
+			if !loadVarsFile("", p, scope) {
+				// A vaulted vars file may define anything
+				if data, err := os.ReadFile(p); err == nil && isVaultedFile(data) {
+					scope.opaque = true
+				}
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,58 @@
+package ansible
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// Header of ansible-vault encrypted files and of !vault tagged values. yaml.v3 decodes a
+// !vault scalar as a plain string, so vaulted values are recognised by this prefix.
+const vaultHeader = "$ANSIBLE_VAULT;"
+
+// isVaultedFile reports whether data is a file encrypted with ansible-vault.
+func isVaultedFile(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n\ufeff"), []byte(vaultHeader))
+}
+
+// isVaulted reports whether v is an inline !vault encrypted value.
+func isVaulted(v interface{}) bool {
+	s, ok := v.(string)
+	return ok && strings.HasPrefix(strings.TrimSpace(s), vaultHeader)
+}
+
+// checkPlaintextSecrets recommends vaulting variables with secret-like names whose
+// values are plaintext literals, descending into nested mappings (db.password).
+// Templated values only reference another variable and are left alone.
+func checkPlaintextSecrets(p, label string, vars map[string]interface{}) []finding.Finding {
+	var findings []finding.Finding
+	var walk func(prefix string, m map[string]interface{})
+	walk = func(prefix string, m map[string]interface{}) {
+		names := make([]string, 0, len(m))
+		for name := range m {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			full := prefix + name
+			switch v := m[name].(type) {
+			case map[string]interface{}:
+				walk(full+".", v)
+			case string:
+				if !isSecretName(name) || strings.TrimSpace(v) == "" || isVaulted(v) || strings.Contains(v, "{{") {
+					continue
+				}
+				findings = append(findings, finding.Finding{
+					File:     p,
+					Severity: finding.Warning,
+					Message:  fmt.Sprintf("Variable '%s' in %s holds a plaintext secret; encrypt it with 'ansible-vault encrypt_string' or move it to a vaulted vars file", full, label),
+				})
+			}
+		}
+	}
+	walk("", vars)
+	return findings
+}
@@ -0,0 +1,170 @@
+// unpacking of tar, tar.gz and zip archives into a directory for scanning
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var errNoFiles = errors.New("not an archive with files in it")
+
+// IsArchive reports whether name has the extension of an archive Extract unpacks.
+func IsArchive(name string) bool {
+	name = strings.ToLower(name)
+	for _, ext := range []string{".tar", ".tar.gz", ".tgz", ".zip"} {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// Extract unpacks the tar, tar.gz or zip archive at path into dir, telling the formats
+// apart by content. See ExtractTar for what is written.
+func Extract(path, dir string, limit int64) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(f, magic); err == nil && bytes.Equal(magic, []byte("PK\x03\x04")) {
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		return extractZip(f, info.Size(), dir, limit)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return ExtractTar(f, dir, limit)
+}
+
+// ExtractTar unpacks a tar archive, gzip-compressed or not, into dir. Only regular files
+// and directories are written: links could point outside dir, and nothing scanned needs
+// them. Entries escaping dir are rejected, and limit caps the unpacked size.
+func ExtractTar(r io.Reader, dir string, limit int64) error {
+	br := bufio.NewReader(r)
+	var src io.Reader = br
+	if magic, err := br.Peek(2); err == nil && bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return fmt.Errorf("invalid gzip archive: %v", err)
+		}
+		defer gz.Close()
+		src = gz
+	}
+
+	tr := tar.NewReader(src)
+	var written int64
+	files := 0
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("invalid tar archive: %w", err)
+		}
+		target, err := entryPath(dir, hdr.Name)
+		if err != nil {
+			return err
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if written += hdr.Size; written > limit {
+				return fmt.Errorf("archive unpacks to more than %d bytes", limit)
+			}
+			if err := writeFile(target, tr, hdr.Size); err != nil {
+				return fmt.Errorf("invalid tar archive: %w", err)
+			}
+			files++
+		}
+	}
+	if files == 0 {
+		return errNoFiles
+	}
+	return nil
+}
+
+// extractZip is ExtractTar for zip archives.
+func extractZip(r io.ReaderAt, size int64, dir string, limit int64) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("invalid zip archive: %v", err)
+	}
+	var written int64
+	files := 0
+	for _, zf := range zr.File {
+		target, err := entryPath(dir, zf.Name)
+		if err != nil {
+			return err
+		}
+		mode := zf.Mode()
+		switch {
+		case mode.IsDir():
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case mode.IsRegular():
+			// writeFile copies the declared size and no more, so a lying header cannot exceed limit
+			if written += int64(zf.UncompressedSize64); written > limit {
+				return fmt.Errorf("archive unpacks to more than %d bytes", limit)
+			}
+			rc, err := zf.Open()
+			if err != nil {
+				return fmt.Errorf("invalid zip archive: %v", err)
+			}
+			err = writeFile(target, rc, int64(zf.UncompressedSize64))
+			rc.Close()
+			if err != nil {
+				return fmt.Errorf("invalid zip archive: %w", err)
+			}
+			files++
+		}
+	}
+	if files == 0 {
+		return errNoFiles
+	}
+	return nil
+}
+
+// entryPath joins an archive entry name to dir, rejecting names that escape it.
+func entryPath(dir, entry string) (string, error) {
+	name := filepath.Clean(filepath.FromSlash(entry))
+	if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("archive entry '%s' points outside the archive", entry)
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// writeFile writes exactly size bytes from r to a new file at path.
+func writeFile(path string, r io.Reader, size int64) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = io.CopyN(f, r, size)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,233 @@
+// Package attest signs JSON results as in-toto statements wrapped in DSSE envelopes,
+// so compliance systems can verify which infra-check version and rule bundle produced a
+// report for which commit. Keys are plain Ed25519 keys in PEM form, e.g. from
+// `openssl genpkey -algorithm ed25519`.
+package attest
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	StatementType = "https://in-toto.io/Statement/v1"
+	PredicateType = "https://github.com/salchaD-27/infra-check/attestation/result/v1"
+	PayloadType   = "application/vnd.in-toto+json"
+)
+
+// Statement is an in-toto v1 statement whose subject is the JSON result.
+type Statement struct {
+	Type          string    `json:"_type"`
+	Subject       []Subject `json:"subject"`
+	PredicateType string    `json:"predicateType"`
+	Predicate     Predicate `json:"predicate"`
+}
+
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// Predicate records what produced the result.
+type Predicate struct {
+	Version       string     `json:"infraCheckVersion"`
+	SchemaVersion string     `json:"schemaVersion"`
+	Commit        string     `json:"commit,omitempty"`
+	RuleBundle    RuleBundle `json:"ruleBundle"`
+	ScannedAt     time.Time  `json:"scannedAt"`
+}
+
+// RuleBundle identifies the custom rules in effect; Digest is empty when none were loaded.
+type RuleBundle struct {
+	Path   string `json:"path,omitempty"`
+	Digest string `json:"sha256,omitempty"`
+}
+
+// Envelope is a DSSE envelope.
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+type Signature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// Sign returns the DSSE envelope over a statement binding result to pred.
+func Sign(result []byte, name string, pred Predicate, key ed25519.PrivateKey) ([]byte, error) {
+	statement := Statement{
+		Type:          StatementType,
+		Subject:       []Subject{{Name: name, Digest: map[string]string{"sha256": digest(result)}}},
+		PredicateType: PredicateType,
+		Predicate:     pred,
+	}
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return nil, err
+	}
+	keyID, err := KeyID(key.Public().(ed25519.PublicKey))
+	if err != nil {
+		return nil, err
+	}
+	env := Envelope{
+		PayloadType: PayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures: []Signature{{
+			KeyID: keyID,
+			Sig:   base64.StdEncoding.EncodeToString(ed25519.Sign(key, pae(PayloadType, payload))),
+		}},
+	}
+	return json.MarshalIndent(env, "", "  ")
+}
+
+// Verify checks that envelope is signed by pub and attests exactly result, and returns the statement.
+func Verify(envelope, result []byte, pub ed25519.PublicKey) (*Statement, error) {
+	var env Envelope
+	if err := json.Unmarshal(envelope, &env); err != nil {
+		return nil, fmt.Errorf("reading attestation: %v", err)
+	}
+	if env.PayloadType != PayloadType {
+		return nil, fmt.Errorf("unexpected payload type '%s'", env.PayloadType)
+	}
+	payload, err := base64.StdEncoding.DecodeString(env.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("decoding payload: %v", err)
+	}
+
+	verified := false
+	for _, s := range env.Signatures {
+		sig, err := base64.StdEncoding.DecodeString(s.Sig)
+		if err == nil && ed25519.Verify(pub, pae(env.PayloadType, payload), sig) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, errors.New("no valid signature for the given public key")
+	}
+
+	var statement Statement
+	if err := json.Unmarshal(payload, &statement); err != nil {
+		return nil, fmt.Errorf("reading statement: %v", err)
+	}
+	if statement.Type != StatementType || statement.PredicateType != PredicateType {
+		return nil, fmt.Errorf("not an infra-check result attestation")
+	}
+	want := digest(result)
+	for _, s := range statement.Subject {
+		if s.Digest["sha256"] == want {
+			return &statement, nil
+		}
+	}
+	return nil, errors.New("report does not match the attested digest")
+}
+
+// pae is the DSSE pre-authentication encoding.
+func pae(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
+
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// KeyID is the hex SHA-256 of the PKIX encoding of pub.
+func KeyID(pub ed25519.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	return digest(der), nil
+}
+
+// LoadPrivateKey reads a PKCS#8 PEM Ed25519 private key.
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	ed, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an Ed25519 private key", path)
+	}
+	return ed, nil
+}
+
+// LoadPublicKey reads a PKIX PEM Ed25519 public key.
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	ed, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an Ed25519 public key", path)
+	}
+	return ed, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s holds no PEM data", path)
+	}
+	return block, nil
+}
+
+// RulesDigest hashes the names and contents of the rule files in dir, which are read
+// the same way rules.Load reads them. A missing or empty directory yields an empty digest.
+func RulesDigest(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	hashed := 0
+	for _, e := range entries { // ReadDir returns entries sorted by name
+		if ext := filepath.Ext(e.Name()); e.IsDir() || (ext != ".yml" && ext != ".yaml") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return "", err
+		}
+		io.WriteString(h, e.Name()+"\x00")
+		h.Write(data)
+		h.Write([]byte{0})
+		hashed++
+	}
+	if hashed == 0 {
+		return "", nil
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,202 @@
+// repeated timed runs of each scanner over a tree, to compare throughput between builds
+package bench
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/salchaD-27/infra-check/internal/engine"
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// Options select what Run measures.
+type Options struct {
+	// Iterations is the number of timed runs of each scanner, after an untimed one that warms
+	// the file cache.
+	Iterations int
+	// Scanners limits the run to the named scanners; all detected ones are run when empty.
+	Scanners []string
+	// Hot is the number of slowest files reported.
+	Hot int
+}
+
+// Result is the measurement of one scanner over all of its targets.
+type Result struct {
+	Scanner     string        `json:"scanner"`
+	Targets     int           `json:"targets"`
+	Files       int           `json:"files"`
+	Findings    int           `json:"findings"`
+	Min         time.Duration `json:"minNs"`
+	Median      time.Duration `json:"medianNs"`
+	Mean        time.Duration `json:"meanNs"`
+	FilesPerSec float64       `json:"filesPerSec"`
+	// AllocsPerRun and BytesPerRun are the heap allocations of one run.
+	AllocsPerRun uint64 `json:"allocsPerRun"`
+	BytesPerRun  uint64 `json:"bytesPerRun"`
+	Error        string `json:"error,omitempty"`
+}
+
+// HotFile is a file that is slow to scan on its own, with the fastest of its runs.
+type HotFile struct {
+	Scanner  string        `json:"scanner"`
+	File     string        `json:"file"`
+	Duration time.Duration `json:"ns"`
+}
+
+// Report holds the results of Run in registry order and the slowest files, slowest first.
+// Version, set by the caller, and the machine fields tell apart runs that are not comparable.
+type Report struct {
+	Version    string    `json:"version"`
+	Root       string    `json:"root"`
+	Iterations int       `json:"iterations"`
+	GoVersion  string    `json:"goVersion"`
+	GOOS       string    `json:"goos"`
+	GOARCH     string    `json:"goarch"`
+	CPUs       int       `json:"cpus"`
+	Results    []Result  `json:"results"`
+	Hot        []HotFile `json:"hotFiles"`
+}
+
+// Run scans root with each detected scanner Iterations times, one scanner at a time so the
+// measurements do not disturb each other, then times each file a scanner can scan on its
+// own, such as a single .tf file but not a file of a Helm chart.
+func Run(root string, opts Options) (*Report, error) {
+	if opts.Iterations < 1 {
+		return nil, fmt.Errorf("iterations must be 1 or more, got %d", opts.Iterations)
+	}
+	detected, err := engine.Detect(root)
+	if err != nil {
+		return nil, err
+	}
+	selected := make(map[string]bool)
+	for _, name := range opts.Scanners {
+		if _, ok := scanner.Lookup(name); !ok {
+			return nil, fmt.Errorf("unknown scanner %q", name)
+		}
+		selected[name] = true
+	}
+
+	rep := &Report{
+		Root:       root,
+		Iterations: opts.Iterations,
+		GoVersion:  runtime.Version(),
+		GOOS:       runtime.GOOS,
+		GOARCH:     runtime.GOARCH,
+		CPUs:       runtime.NumCPU(),
+	}
+	for _, s := range scanner.All() {
+		targets, ok := detected[s.Name()]
+		if !ok || (len(selected) > 0 && !selected[s.Name()]) {
+			continue
+		}
+		r, err := measure(s, root, targets, opts.Iterations)
+		if err != nil {
+			return nil, err
+		}
+		rep.Results = append(rep.Results, r)
+		if r.Error == "" && opts.Hot > 0 {
+			rep.Hot = append(rep.Hot, hotFiles(s, targets, opts.Iterations)...)
+		}
+	}
+
+	sort.SliceStable(rep.Hot, func(i, j int) bool { return rep.Hot[i].Duration > rep.Hot[j].Duration })
+	if len(rep.Hot) > opts.Hot {
+		rep.Hot = rep.Hot[:opts.Hot]
+	}
+	return rep, nil
+}
+
+// measure times iterations runs of s over targets. A scanner failing on the warm-up run is
+// reported with its error rather than timed.
+func measure(s scanner.Scanner, root string, targets []string, iterations int) (Result, error) {
+	r := Result{Scanner: s.Name(), Targets: len(targets)}
+	files, err := engine.FilesRead(root, nil, s.Name())
+	if err != nil {
+		return r, err
+	}
+	r.Files = files
+
+	count := func(finding.Finding) { r.Findings++ }
+	for _, t := range targets {
+		if err := scanner.Stream(s, t, count); err != nil {
+			r.Error = fmt.Sprintf("%s: %v", t, err)
+			return r, nil
+		}
+	}
+
+	discard := func(finding.Finding) {}
+	runs := make([]time.Duration, iterations)
+	var before, after runtime.MemStats
+	var allocs, bytes uint64
+	for i := range runs {
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		start := time.Now()
+		for _, t := range targets {
+			scanner.Stream(s, t, discard)
+		}
+		runs[i] = time.Since(start)
+		runtime.ReadMemStats(&after)
+		allocs += after.Mallocs - before.Mallocs
+		bytes += after.TotalAlloc - before.TotalAlloc
+	}
+
+	var total time.Duration
+	for _, d := range runs {
+		total += d
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i] < runs[j] })
+	r.Min = runs[0]
+	r.Median = runs[len(runs)/2]
+	r.Mean = total / time.Duration(len(runs))
+	if r.Median > 0 {
+		r.FilesPerSec = float64(r.Files) / r.Median.Seconds()
+	}
+	r.AllocsPerRun = allocs / uint64(iterations)
+	r.BytesPerRun = bytes / uint64(iterations)
+	return r, nil
+}
+
+// hotFiles times each file of targets that s matches and scans as a target of its own, taking
+// the fastest of iterations runs as the least disturbed one. Files failing to scan are left out.
+func hotFiles(s scanner.Scanner, targets []string, iterations int) []HotFile {
+	files := make(map[string]bool)
+	for _, t := range targets {
+		scanner.Walk(t, func(p string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() && s.FileMatcher()(p) {
+				files[p] = true
+			}
+			return nil
+		})
+	}
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	discard := func(finding.Finding) {}
+	var hot []HotFile
+	for _, p := range paths {
+		if own := scanner.Targets(s, p, []string{p}); len(own) != 1 || own[0] != p {
+			continue
+		}
+		var fastest time.Duration
+		failed := false
+		for i := 0; i < iterations && !failed; i++ {
+			start := time.Now()
+			failed = scanner.Stream(s, p, discard) != nil
+			if d := time.Since(start); i == 0 || d < fastest {
+				fastest = d
+			}
+		}
+		if !failed {
+			hot = append(hot, HotFile{Scanner: s.Name(), File: p, Duration: fastest})
+		}
+	}
+	return hot
+}
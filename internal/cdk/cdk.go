@@ -0,0 +1,194 @@
+// Package cdk scans the cloud assemblies `cdk synth` writes to cdk.out: the CloudFormation
+// templates of the stacks are checked with the CloudFormation rule set, with findings
+// naming the construct that defines each resource.
+package cdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/salchaD-27/infra-check/internal/cloudformation"
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// DefaultDir is where `cdk synth` writes the cloud assembly.
+const DefaultDir = "cdk.out"
+
+// manifest is the subset of a cloud assembly's manifest.json the scan needs.
+type manifest struct {
+	Artifacts map[string]artifact `json:"artifacts"`
+}
+
+type artifact struct {
+	Type       string `json:"type"`
+	Properties struct {
+		TemplateFile  string `json:"templateFile"`  // of a stack
+		DirectoryName string `json:"directoryName"` // of a nested assembly, e.g. a stage
+	} `json:"properties"`
+	Metadata map[string][]metadataEntry `json:"metadata"` // by construct path
+}
+
+type metadataEntry struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// Severities of the messages constructs attach with Annotations.of(...)
+var annotationSeverities = map[string]finding.Severity{
+	"aws:cdk:error":   finding.Error,
+	"aws:cdk:warning": finding.Warning,
+	"aws:cdk:info":    finding.Info,
+}
+
+// IsAssembly reports whether dir holds a cloud assembly manifest.
+func IsAssembly(dir string) bool {
+	_, err := readManifest(dir)
+	return err == nil
+}
+
+// Scan checks the cloud assembly in path, or every cloud assembly under path.
+func Scan(path string) ([]finding.Finding, error) {
+	if IsAssembly(path) {
+		return scanAssembly(path)
+	}
+	var findings []finding.Finding
+	err := scanner.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return err
+		}
+		if p != path && (info.Name() == ".git" || info.Name() == "node_modules") {
+			return filepath.SkipDir
+		}
+		if !IsAssembly(p) {
+			return nil
+		}
+		fs, err := scanAssembly(p)
+		if err != nil {
+			return err
+		}
+		findings = append(findings, fs...)
+		return filepath.SkipDir // nested assemblies are read through the manifest
+	})
+	return findings, err
+}
+
+func readManifest(dir string) (*manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		return nil, err
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%s: %v", filepath.Join(dir, "manifest.json"), err)
+	}
+	if m.Artifacts == nil {
+		return nil, fmt.Errorf("%s lists no artifacts", filepath.Join(dir, "manifest.json"))
+	}
+	return &m, nil
+}
+
+// scanAssembly checks the stack templates of the assembly in dir and of the assemblies
+// nested in it, and reports the annotations of their constructs.
+func scanAssembly(dir string) ([]finding.Finding, error) {
+	m, err := readManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(m.Artifacts))
+	for name := range m.Artifacts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var findings []finding.Finding
+	for _, name := range names {
+		a := m.Artifacts[name]
+		switch a.Type {
+		case "cdk:cloud-assembly":
+			nested, err := scanAssembly(filepath.Join(dir, a.Properties.DirectoryName))
+			if err != nil {
+				return nil, err
+			}
+			findings = append(findings, nested...)
+		case "aws:cloudformation:stack":
+			findings = append(findings, scanStack(dir, name, a)...)
+		}
+	}
+	return findings, nil
+}
+
+func scanStack(dir, name string, a artifact) []finding.Finding {
+	file := filepath.Join(dir, a.Properties.TemplateFile)
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return []finding.Finding{{
+			File:     filepath.Join(dir, "manifest.json"),
+			Severity: finding.Error,
+			Message:  fmt.Sprintf("Failed to read the template of stack '%s': %v", name, err),
+		}}
+	}
+
+	// without path metadata in the template, the manifest maps logical IDs to constructs
+	constructs := make(map[string]string)
+	var findings []finding.Finding
+	paths := make([]string, 0, len(a.Metadata))
+	for path := range a.Metadata {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		for _, entry := range a.Metadata[path] {
+			if id, ok := entry.Data.(string); ok && entry.Type == "aws:cdk:logicalId" {
+				constructs[id] = path
+			}
+			if sev, ok := annotationSeverities[entry.Type]; ok {
+				findings = append(findings, finding.Finding{
+					File:     file,
+					Severity: sev,
+					Message:  fmt.Sprintf("Construct '%s': %v", constructPath(path), entry.Data),
+				})
+			}
+		}
+	}
+
+	label := func(logicalID, resourceType string, resource *yaml.Node) string {
+		path := cdkPath(resource)
+		if path == "" {
+			path = constructs[logicalID]
+		}
+		if path == "" {
+			return cloudformation.ResourceLabel(logicalID, resourceType, resource)
+		}
+		return fmt.Sprintf("Construct '%s' (%s)", constructPath(path), resourceType)
+	}
+	return append(findings, cloudformation.Check(file, data, label)...)
+}
+
+// cdkPath returns the aws:cdk:path metadata of a template resource.
+func cdkPath(resource *yaml.Node) string {
+	var res struct {
+		Metadata map[string]interface{} `yaml:"Metadata"`
+	}
+	if resource == nil || resource.Decode(&res) != nil {
+		return ""
+	}
+	path, _ := res.Metadata["aws:cdk:path"].(string)
+	return path
+}
+
+// constructPath shortens a construct path to the construct a user declares: the L1
+// resource child of L2 constructs, Resource or Default, is dropped.
+func constructPath(path string) string {
+	path = strings.TrimPrefix(path, "/")
+	for _, child := range []string{"/Resource", "/Default"} {
+		path = strings.TrimSuffix(path, child)
+	}
+	return path
+}
@@ -0,0 +1,50 @@
+package cdk
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// cdkScanner scans cloud assemblies, each a directory with a manifest.json; an assembly
+// nested in another, e.g. a stage, is scanned with the one holding it.
+type cdkScanner struct{}
+
+func init() {
+	scanner.Register(cdkScanner{})
+}
+
+func (cdkScanner) Name() string { return "cdk" }
+func (cdkScanner) Description() string {
+	return "Scan the CloudFormation templates of a CDK cloud assembly (cdk synth output)"
+}
+func (cdkScanner) FileMatcher() scanner.FileMatcher {
+	return func(p string) bool {
+		return filepath.Base(p) == "manifest.json" || strings.HasSuffix(p, ".template.json")
+	}
+}
+func (cdkScanner) Scan(path string) ([]finding.Finding, error) { return Scan(path) }
+
+func (cdkScanner) Targets(root string, files []string) []string {
+	var assemblies []string
+	for _, p := range files {
+		if filepath.Base(p) == "manifest.json" && IsAssembly(filepath.Dir(p)) {
+			assemblies = append(assemblies, filepath.Dir(p))
+		}
+	}
+	var outer []string
+	for _, dir := range assemblies {
+		nested := false
+		for _, other := range assemblies {
+			if other != dir && strings.HasPrefix(dir, other+string(filepath.Separator)) {
+				nested = true
+			}
+		}
+		if !nested {
+			outer = append(outer, dir)
+		}
+	}
+	return outer
+}
@@ -0,0 +1,16 @@
+package cdk
+
+import (
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/registry"
+)
+
+func init() {
+	registry.Register(registry.Rule{
+		ID: "CDK001", Name: "CDK annotation", Severity: finding.Warning, Category: "cdk", Scanners: []string{"cdk"},
+		Description: "A construct recorded an error, warning or info annotation during synthesis, for example from cdk-nag or a deprecated API.",
+		Messages:    []string{"Construct '*': *"},
+		Remediation: `# fix the construct the annotation names, or acknowledge a known warning in code:
+Annotations.of(bucket).acknowledgeWarning('@aws-cdk/aws-s3:accessLogs', 'logged centrally')`,
+	})
+}
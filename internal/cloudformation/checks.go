@@ -0,0 +1,235 @@
+package cloudformation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// Resources encrypted at rest through a boolean property
+var encryptionProperties = map[string]string{
+	"AWS::EC2::Volume":        "Encrypted",
+	"AWS::RDS::DBInstance":    "StorageEncrypted",
+	"AWS::RDS::DBCluster":     "StorageEncrypted",
+	"AWS::EFS::FileSystem":    "Encrypted",
+	"AWS::Neptune::DBCluster": "StorageEncrypted",
+	"AWS::DocDB::DBCluster":   "StorageEncrypted",
+}
+
+// Canned ACLs that grant access beyond the bucket owner's account
+var publicACLs = map[string]bool{"PublicRead": true, "PublicReadWrite": true, "AuthenticatedRead": true}
+
+// Resources whose policy document is a resource policy, which can name principals
+var resourcePolicies = map[string]bool{
+	"AWS::S3::BucketPolicy": true,
+	"AWS::SQS::QueuePolicy": true,
+	"AWS::SNS::TopicPolicy": true,
+}
+
+// Ports that must never be reachable from the whole internet
+var sensitivePorts = map[int]string{22: "SSH", 3389: "RDP"}
+
+// Keywords to detect secrets in property and parameter names
+var secretKeywords = []string{"password", "passwd", "secret", "token", "privatekey", "passphrase"}
+
+type addFunc func(sev finding.Severity, line int, format string, args ...interface{})
+
+// checkTemplate runs every check on the resources and parameters of a template.
+func checkTemplate(p string, root *yaml.Node, label Label) []finding.Finding {
+	var findings []finding.Finding
+
+	parameters := get(root, "Parameters")
+	for i := 0; parameters != nil && parameters.Kind == yaml.MappingNode && i+1 < len(parameters.Content); i += 2 {
+		name, param := parameters.Content[i], parameters.Content[i+1]
+		if !looksLikeSecret(name.Value) || strings.EqualFold(scalar(get(param, "NoEcho")), "true") {
+			continue
+		}
+		if scalar(get(param, "Default")) != "" {
+			findings = append(findings, finding.Finding{File: p, Severity: finding.Error, Line: name.Line,
+				Message: fmt.Sprintf("Parameter '%s' has a hardcoded default secret and no NoEcho: true", name.Value)})
+		} else {
+			findings = append(findings, finding.Finding{File: p, Severity: finding.Warning, Line: name.Line,
+				Message: fmt.Sprintf("Parameter '%s' looks like a secret but is not NoEcho: true, so its value shows in the console and API", name.Value)})
+		}
+	}
+
+	resources := get(root, "Resources")
+	for i := 0; resources != nil && resources.Kind == yaml.MappingNode && i+1 < len(resources.Content); i += 2 {
+		id, res := resources.Content[i], resources.Content[i+1]
+		typ := scalar(get(res, "Type"))
+		name := label(id.Value, typ, res)
+		add := func(sev finding.Severity, line int, format string, args ...interface{}) {
+			findings = append(findings, finding.Finding{File: p, Severity: sev, Message: name + " " + fmt.Sprintf(format, args...), Line: line})
+		}
+		checkResource(add, typ, id.Line, get(res, "Properties"))
+	}
+	return findings
+}
+
+func checkResource(add addFunc, typ string, line int, props *yaml.Node) {
+	if flag, ok := encryptionProperties[typ]; ok && !(typ == "AWS::RDS::DBInstance" && get(props, "DBClusterIdentifier") != nil) {
+		switch v := get(props, flag); {
+		case v == nil:
+			add(finding.Warning, line, "does not set %s; its data is stored unencrypted at rest", flag)
+		case strings.EqualFold(scalar(v), "false"):
+			add(finding.Error, v.Line, "sets %s to false; its data is stored unencrypted at rest", flag)
+		}
+	}
+
+	switch typ {
+	case "AWS::S3::Bucket":
+		if acl := get(props, "AccessControl"); publicACLs[scalar(acl)] {
+			add(finding.Error, acl.Line, "grants public access with AccessControl: %s", acl.Value)
+		}
+		block := get(props, "PublicAccessBlockConfiguration")
+		for _, setting := range []string{"BlockPublicAcls", "BlockPublicPolicy", "IgnorePublicAcls", "RestrictPublicBuckets"} {
+			if v := get(block, setting); strings.EqualFold(scalar(v), "false") {
+				add(finding.Warning, v.Line, "sets PublicAccessBlockConfiguration %s to false", setting)
+			}
+		}
+		if get(props, "BucketEncryption") == nil {
+			add(finding.Info, line, "has no BucketEncryption; it relies on the SSE-S3 default instead of a KMS key you control")
+		}
+	case "AWS::RDS::DBInstance":
+		if v := get(props, "PubliclyAccessible"); strings.EqualFold(scalar(v), "true") {
+			add(finding.Error, v.Line, "is PubliclyAccessible, reachable from outside its VPC")
+		}
+	case "AWS::EC2::SecurityGroup":
+		for _, rule := range items(get(props, "SecurityGroupIngress")) {
+			checkIngress(add, rule)
+		}
+	case "AWS::EC2::SecurityGroupIngress":
+		checkIngress(add, props)
+	case "AWS::IAM::Role":
+		checkPolicy(add, "AssumeRolePolicyDocument", get(props, "AssumeRolePolicyDocument"), true)
+		for _, policy := range items(get(props, "Policies")) {
+			checkPolicy(add, "policy '"+scalar(get(policy, "PolicyName"))+"'", get(policy, "PolicyDocument"), false)
+		}
+	case "AWS::IAM::Policy", "AWS::IAM::ManagedPolicy", "AWS::IAM::User", "AWS::IAM::Group":
+		if doc := get(props, "PolicyDocument"); doc != nil {
+			checkPolicy(add, "PolicyDocument", doc, false)
+		}
+		for _, policy := range items(get(props, "Policies")) {
+			checkPolicy(add, "policy '"+scalar(get(policy, "PolicyName"))+"'", get(policy, "PolicyDocument"), false)
+		}
+	default:
+		if resourcePolicies[typ] {
+			checkPolicy(add, "PolicyDocument", get(props, "PolicyDocument"), true)
+		}
+	}
+
+	for i := 0; props != nil && props.Kind == yaml.MappingNode && i+1 < len(props.Content); i += 2 {
+		key, value := props.Content[i], props.Content[i+1]
+		if v := scalar(value); v != "" && looksLikeSecret(key.Value) && !referenceName(key.Value) && !strings.Contains(v, "{{resolve:") {
+			add(finding.Error, key.Line, "property '%s' holds a hardcoded secret; use a NoEcho parameter or a {{resolve:secretsmanager:...}} dynamic reference", key.Value)
+		}
+	}
+}
+
+// checkIngress flags an ingress rule open to the world on a sensitive port or all ports.
+func checkIngress(add addFunc, rule *yaml.Node) {
+	var open string
+	for _, key := range []string{"CidrIp", "CidrIpv6"} {
+		if cidr := scalar(get(rule, key)); cidr == "0.0.0.0/0" || cidr == "::/0" {
+			open = cidr
+		}
+	}
+	if open == "" {
+		return
+	}
+	protocol := strings.ToLower(scalar(get(rule, "IpProtocol")))
+	from, fromErr := strconv.Atoi(scalar(get(rule, "FromPort")))
+	to, toErr := strconv.Atoi(scalar(get(rule, "ToPort")))
+	var exposed string
+	switch {
+	case protocol == "-1" || protocol == "all":
+		exposed = "all ports"
+	case fromErr != nil || toErr != nil:
+		return
+	case from <= 0 && to >= 65535:
+		exposed = "all ports"
+	default:
+		var names []string
+		for _, port := range []int{22, 3389} {
+			if from <= port && port <= to {
+				names = append(names, fmt.Sprintf("%s (%d)", sensitivePorts[port], port))
+			}
+		}
+		exposed = strings.Join(names, " and ")
+	}
+	if exposed != "" {
+		add(finding.Error, rule.Line, "allows ingress from %s to %s", open, exposed)
+	}
+}
+
+// checkPolicy flags policy statements granting every action, a service wildcard on every
+// resource, or, for resource and trust policies, access to every principal.
+func checkPolicy(add addFunc, what string, doc *yaml.Node, principals bool) {
+	for _, st := range items(get(doc, "Statement")) {
+		if effect := scalar(get(st, "Effect")); effect != "" && !strings.EqualFold(effect, "Allow") {
+			continue
+		}
+		actions, resources := scalars(get(st, "Action")), scalars(get(st, "Resource"))
+		allActions, allResources := contains(actions, "*"), contains(resources, "*")
+		switch {
+		case allActions && allResources:
+			add(finding.Error, st.Line, "%s allows Action \"*\" on Resource \"*\" (full administrator access)", what)
+		case allActions:
+			add(finding.Error, st.Line, "%s allows Action \"*\"; grant only the actions needed", what)
+		case allResources:
+			var wild []string
+			for _, a := range actions {
+				if strings.HasSuffix(a, ":*") {
+					wild = append(wild, fmt.Sprintf("\"%s\"", a))
+				}
+			}
+			if len(wild) > 0 {
+				add(finding.Warning, st.Line, "%s allows %s on Resource \"*\"", what, strings.Join(wild, ", "))
+			}
+		}
+		if !principals {
+			continue
+		}
+		principal := get(st, "Principal")
+		everyone := scalar(principal) == "*" || contains(scalars(get(principal, "AWS")), "*")
+		if everyone && get(st, "Condition") != nil {
+			add(finding.Warning, st.Line, "%s allows Principal \"*\"; make sure its conditions restrict who can use it", what)
+		} else if everyone {
+			add(finding.Error, st.Line, "%s allows Principal \"*\" without conditions (anyone can use it)", what)
+		}
+	}
+}
+
+// referenceName reports whether a property names a secret rather than holding one,
+// e.g. SecretArn or SecretName.
+func referenceName(key string) bool {
+	for _, suffix := range []string{"Arn", "Id", "Name", "Ref", "Length"} {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func looksLikeSecret(name string) bool {
+	lower := strings.ToLower(name)
+	for _, kw := range secretKeywords {
+		if strings.Contains(lower, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
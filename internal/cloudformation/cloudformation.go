@@ -0,0 +1,177 @@
+// static analysis of AWS CloudFormation templates
+package cloudformation
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// Extensions of files that may hold a template
+var templateExtensions = []string{".yaml", ".yml", ".json", ".template"}
+
+// IsTemplateFile reports whether p is named like a template; whether it is one depends on
+// its content, see IsTemplate.
+func IsTemplateFile(p string) bool {
+	return scanner.Extensions(templateExtensions...)(p)
+}
+
+// IsTemplate reports whether data is a CloudFormation template in YAML or JSON: a mapping
+// with Resources that declares AWSTemplateFormatVersion or has an AWS:: resource.
+func IsTemplate(data []byte) bool {
+	root, err := parse(data)
+	return err == nil && isTemplate(root)
+}
+
+// Label names a resource in messages, from its logical ID and type.
+type Label func(logicalID, resourceType string, resource *yaml.Node) string
+
+// ResourceLabel is the default Label: "Resource 'Bucket' (AWS::S3::Bucket)".
+func ResourceLabel(logicalID, resourceType string, _ *yaml.Node) string {
+	return fmt.Sprintf("Resource '%s' (%s)", logicalID, resourceType)
+}
+
+// Scan walks path for CloudFormation templates and checks each one:
+// - S3 buckets granting public access or without default encryption
+// - IAM policies allowing Action "*", service wildcards on Resource "*" or Principal "*"
+// - Security groups open to the world on SSH, RDP or all ports
+// - EBS volumes, RDS instances and clusters and EFS file systems not encrypted at rest
+// - Publicly accessible RDS instances
+// - Hardcoded secrets in resource properties and secret parameters without NoEcho
+func Scan(path string) ([]finding.Finding, error) {
+	var findings []finding.Finding
+
+	err := scanner.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if p != path && (info.Name() == ".git" || info.Name() == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !IsTemplateFile(p) {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			findings = append(findings, finding.Finding{
+				File:     p,
+				Severity: finding.Error,
+				Message:  fmt.Sprintf("Failed to read file: %v", err),
+			})
+			return nil
+		}
+		if root, err := parse(data); err == nil && isTemplate(root) {
+			findings = append(findings, checkTemplate(p, root, ResourceLabel)...)
+		}
+		return nil
+	})
+
+	return findings, err
+}
+
+// Check runs the template checks against the content of a template file, naming resources
+// with label, or ResourceLabel when nil.
+func Check(p string, data []byte, label Label) []finding.Finding {
+	root, err := parse(data)
+	if err != nil {
+		return []finding.Finding{{File: p, Severity: finding.Error, Message: fmt.Sprintf("Template parse error: %v", err)}}
+	}
+	if label == nil {
+		label = ResourceLabel
+	}
+	return checkTemplate(p, root, label)
+}
+
+// parse returns the root mapping of a YAML or JSON document.
+func parse(data []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("the document is not a mapping")
+	}
+	return doc.Content[0], nil
+}
+
+func isTemplate(root *yaml.Node) bool {
+	resources := get(root, "Resources")
+	if resources == nil || resources.Kind != yaml.MappingNode {
+		return false
+	}
+	if get(root, "AWSTemplateFormatVersion") != nil {
+		return true
+	}
+	for i := 1; i < len(resources.Content); i += 2 {
+		if strings.HasPrefix(scalar(get(resources.Content[i], "Type")), "AWS::") {
+			return true
+		}
+	}
+	return false
+}
+
+// get returns the value of key in a mapping node, or nil.
+func get(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// scalar returns the value of a literal scalar, or "" for anything else, including short
+// form intrinsic functions such as !Ref.
+func scalar(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode || node.Tag != "" && !strings.HasPrefix(node.Tag, "!!") {
+		return ""
+	}
+	return node.Value
+}
+
+// scalars returns a literal scalar or the literal scalars of a sequence.
+func scalars(node *yaml.Node) []string {
+	if node == nil {
+		return nil
+	}
+	if node.Kind == yaml.ScalarNode {
+		if s := scalar(node); s != "" {
+			return []string{s}
+		}
+		return nil
+	}
+	var out []string
+	if node.Kind == yaml.SequenceNode {
+		for _, n := range node.Content {
+			if s := scalar(n); s != "" {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+// items returns the elements of a sequence, or a single mapping as one element.
+func items(node *yaml.Node) []*yaml.Node {
+	switch {
+	case node == nil:
+		return nil
+	case node.Kind == yaml.SequenceNode:
+		return node.Content
+	case node.Kind == yaml.MappingNode:
+		return []*yaml.Node{node}
+	}
+	return nil
+}
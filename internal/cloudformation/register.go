@@ -0,0 +1,41 @@
+package cloudformation
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// cloudformationScanner scans the YAML and JSON files that are CloudFormation templates.
+// The templates of a CDK cloud assembly are left to the cdk scanner, which names the
+// constructs.
+type cloudformationScanner struct{}
+
+func init() {
+	scanner.Register(cloudformationScanner{})
+}
+
+func (cloudformationScanner) Name() string { return "cloudformation" }
+func (cloudformationScanner) Description() string {
+	return "Scan AWS CloudFormation templates in the specified directories or files"
+}
+func (cloudformationScanner) FileMatcher() scanner.FileMatcher            { return IsTemplateFile }
+func (cloudformationScanner) Scan(path string) ([]finding.Finding, error) { return Scan(path) }
+
+func (cloudformationScanner) Targets(root string, files []string) []string {
+	var templates []string
+	for _, p := range files {
+		if strings.HasSuffix(p, ".template.json") {
+			if _, err := os.Stat(filepath.Join(filepath.Dir(p), "manifest.json")); err == nil {
+				continue // synthesized by the CDK
+			}
+		}
+		if data, err := os.ReadFile(p); err == nil && IsTemplate(data) {
+			templates = append(templates, p)
+		}
+	}
+	return templates
+}
@@ -0,0 +1,126 @@
+package cloudformation
+
+import (
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/registry"
+)
+
+// The CDK scanner runs these checks on synthesized templates, so its findings count too.
+func init() {
+	scanners := []string{"cloudformation", "cdk"}
+	registry.Register(
+		registry.Rule{
+			ID: "CFN001", Name: "Secret parameter without NoEcho", Severity: finding.Error, Category: "secrets", Scanners: scanners,
+			Compliance:  registry.ExposedSecrets,
+			Description: "A parameter with a secret-like name is not NoEcho: true, so its value shows in the console and API, or it has a literal default.",
+			Messages: []string{
+				"Parameter '*' has a hardcoded default secret and no NoEcho: true",
+				"Parameter '*' looks like a secret but is not NoEcho: true, *",
+			},
+			Remediation: `Parameters:
+  DBPassword:
+    Type: String
+    NoEcho: true`,
+		},
+		registry.Rule{
+			ID: "CFN002", Name: "Encryption at rest not enabled", Severity: finding.Warning, Category: "encryption", Scanners: scanners,
+			Compliance:  registry.EncryptionAtRest,
+			Description: "A storage resource does not enable encryption at rest, or disables it explicitly.",
+			Messages:    []string{"* (*) does not set *; its data is stored unencrypted at rest", "* (*) sets * to false; its data is stored unencrypted at rest"},
+			Remediation: `Database:
+  Type: AWS::RDS::DBInstance
+  Properties:
+    StorageEncrypted: true`,
+		},
+		registry.Rule{
+			ID: "CFN003", Name: "Public S3 bucket", Severity: finding.Error, Category: "access", Scanners: scanners,
+			Compliance:  registry.PublicStorage,
+			Description: "A bucket grants public access with a canned ACL or switches off a public access block setting.",
+			Messages:    []string{"* (*) grants public access with AccessControl: *", "* (*) sets PublicAccessBlockConfiguration * to false"},
+			Remediation: `Bucket:
+  Type: AWS::S3::Bucket
+  Properties:
+    PublicAccessBlockConfiguration:
+      BlockPublicAcls: true
+      BlockPublicPolicy: true
+      IgnorePublicAcls: true
+      RestrictPublicBuckets: true`,
+		},
+		registry.Rule{
+			ID: "CFN004", Name: "S3 bucket without BucketEncryption", Severity: finding.Info, Category: "encryption", Scanners: scanners,
+			Description: "A bucket relies on the SSE-S3 default instead of a KMS key you control.",
+			Messages:    []string{"* (*) has no BucketEncryption; *"},
+			Remediation: `BucketEncryption:
+  ServerSideEncryptionConfiguration:
+    - ServerSideEncryptionByDefault:
+        SSEAlgorithm: aws:kms
+        KMSMasterKeyID: !Ref BucketKey`,
+		},
+		registry.Rule{
+			ID: "CFN005", Name: "Publicly accessible RDS instance", Severity: finding.Error, Category: "network", Scanners: scanners,
+			Compliance:  registry.NetworkExposure,
+			Description: "A DB instance is PubliclyAccessible and reachable from outside its VPC.",
+			Messages:    []string{"* (*) is PubliclyAccessible, reachable from outside its VPC"},
+			Remediation: `Database:
+  Type: AWS::RDS::DBInstance
+  Properties:
+    PubliclyAccessible: false`,
+		},
+		registry.Rule{
+			ID: "CFN006", Name: "Hardcoded secret property", Severity: finding.Error, Category: "secrets", Scanners: scanners,
+			Compliance:  registry.HardcodedSecrets,
+			Description: "A resource property with a secret-like name is a literal rather than a NoEcho parameter or dynamic reference.",
+			Messages:    []string{"* (*) property '*' holds a hardcoded secret; *"},
+			Remediation: "MasterUserPassword: '{{resolve:secretsmanager:prod/db:SecretString:password}}'",
+		},
+		registry.Rule{
+			ID: "CFN007", Name: "Security group open to the internet", Severity: finding.Error, Category: "network", Scanners: scanners,
+			Compliance:  registry.NetworkExposure,
+			Description: "A security group ingress rule allows 0.0.0.0/0 or ::/0 to SSH, RDP or all ports.",
+			Messages:    []string{"* (*) allows ingress from * to *"},
+			Remediation: `SecurityGroupIngress:
+  - IpProtocol: tcp
+    FromPort: 22
+    ToPort: 22
+    CidrIp: 10.0.0.0/8`,
+		},
+		registry.Rule{
+			ID: "CFN008", Name: "IAM administrator policy", Severity: finding.Error, Category: "iam", Scanners: scanners,
+			Compliance:  registry.LeastPrivilege,
+			Description: "A policy statement allows every action on every resource.",
+			Messages:    []string{`* (*) * allows Action "*" on Resource "*" (full administrator access)`},
+			Remediation: `Statement:
+  - Effect: Allow
+    Action: s3:GetObject
+    Resource: !Sub "${Bucket.Arn}/*"`,
+		},
+		registry.Rule{
+			ID: "CFN009", Name: "IAM wildcard action", Severity: finding.Error, Category: "iam", Scanners: scanners,
+			Compliance:  registry.LeastPrivilege,
+			Description: `A policy statement allows Action "*" instead of the actions it needs.`,
+			Messages:    []string{`* (*) * allows Action "*"; grant only the actions needed`},
+			Remediation: `Action:
+  - dynamodb:GetItem
+  - dynamodb:PutItem`,
+		},
+		registry.Rule{
+			ID: "CFN010", Name: "IAM service wildcard on all resources", Severity: finding.Warning, Category: "iam", Scanners: scanners,
+			Compliance:  registry.LeastPrivilege,
+			Description: `A policy statement allows every action of a service on Resource "*".`,
+			Messages:    []string{`* (*) * allows * on Resource "*"`},
+			Remediation: `Action: s3:GetObject
+Resource: !Sub "${Bucket.Arn}/*"`,
+		},
+		registry.Rule{
+			ID: "CFN011", Name: "IAM wildcard principal", Severity: finding.Error, Category: "iam", Scanners: scanners,
+			Compliance:  registry.LeastPrivilege,
+			Description: `A trust or resource policy allows Principal "*", letting anyone use it unless its conditions restrict who.`,
+			Messages: []string{
+				`* (*) * allows Principal "*" without conditions (anyone can use it)`,
+				`* (*) * allows Principal "*"; make sure its conditions restrict who can use it`,
+			},
+			Remediation: `Principal:
+  AWS: !Sub "arn:aws:iam::${AWS::AccountId}:root"`,
+		},
+	)
+}
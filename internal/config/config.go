@@ -0,0 +1,378 @@
+// project configuration loaded from .infra-check.yaml
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/glob"
+	"github.com/salchaD-27/infra-check/internal/registry"
+)
+
+// DefaultFile is looked up in the working directory when --config is not given.
+const DefaultFile = ".infra-check.yaml"
+
+// Config is the project configuration file.
+//
+//	environments:
+//	  prod: ["envs/prod/**", "**/production/**"]
+//	  staging: ["envs/staging/**"]
+//	production_environments: [prod]
+//	exclude: ["**/examples/**", ".terraform/**"]
+//	noise_reduction:
+//	  enabled: true
+//	  threshold: 5
+//	  rules:
+//	    - match: "Resource missing required tag '*'"
+//	      threshold: 20
+//	severity_overrides:
+//	  - match: "Resource missing required tag '*'"
+//	    severity: info
+//	  - match: TF017
+//	    severity: warn
+//	disabled_rules: [TF012, DKR012]
+//	terraform:
+//	  variables:
+//	    require_description: false
+//	    sensitive_names: [passphrase, dsn]
+//	ansible:
+//	  become:
+//	    allow_root: ["Check the package cache", community.general.*]
+type Config struct {
+	// Environments maps an environment name to the path globs classified as that environment.
+	Environments map[string][]string `yaml:"environments"`
+
+	// ProductionEnvironments lists the environments whose findings are escalated.
+	// Defaults to "prod" and "production".
+	ProductionEnvironments []string `yaml:"production_environments"`
+
+	// Exclude lists path globs no scanner reads, in addition to --exclude.
+	Exclude []string `yaml:"exclude"`
+
+	// NoiseReduction collapses repetitive findings in human-facing reports.
+	NoiseReduction NoiseReduction `yaml:"noise_reduction"`
+
+	// SeverityOverrides replace the built-in severity of matching rules; the first match wins.
+	SeverityOverrides []SeverityOverride `yaml:"severity_overrides"`
+
+	// DisabledRules lists the IDs of built-in or custom rules whose findings are dropped.
+	DisabledRules []string `yaml:"disabled_rules"`
+
+	// Terraform configures the Terraform scanner's built-in checks.
+	Terraform Terraform `yaml:"terraform"`
+
+	// Ansible configures the Ansible scanner's built-in checks.
+	Ansible Ansible `yaml:"ansible"`
+}
+
+// Ansible holds settings for the built-in Ansible checks.
+type Ansible struct {
+	Become BecomeRules `yaml:"become"`
+}
+
+// BecomeRules configures the review of tasks becoming root for modules that do not need it.
+type BecomeRules struct {
+	// AllowRoot lists the task names and modules allowed to become root anyway; '*' matches
+	// any run of characters.
+	AllowRoot []string `yaml:"allow_root"`
+}
+
+// Terraform holds settings for the built-in Terraform checks.
+type Terraform struct {
+	Variables VariableRules `yaml:"variables"`
+}
+
+// VariableRules configures the variable hygiene checks. Every check is enabled unless
+// switched off explicitly.
+type VariableRules struct {
+	RequireType        *bool `yaml:"require_type"`
+	RequireDescription *bool `yaml:"require_description"`
+
+	// RequireSensitive flags variables with secret-like names not declared sensitive = true.
+	RequireSensitive *bool `yaml:"require_sensitive"`
+
+	// SensitiveNames are extra name fragments, matched case-insensitively, treated as secret-like.
+	SensitiveNames []string `yaml:"sensitive_names"`
+
+	// Ignore lists variable names exempt from these checks; '*' matches any run of characters.
+	Ignore []string `yaml:"ignore"`
+}
+
+// NoiseReduction configures the collapsing of a rule firing many times in one directory
+// (a Terraform module, a role) into a single aggregated finding.
+type NoiseReduction struct {
+	Enabled bool `yaml:"enabled"`
+
+	// Threshold is the number of occurrences from which findings are collapsed. Defaults to 5.
+	Threshold int `yaml:"threshold"`
+
+	// MaxListed caps the locations listed in an aggregated finding. Defaults to 10.
+	MaxListed int `yaml:"max_listed"`
+
+	// Rules override Threshold per rule; the first matching entry wins.
+	Rules []NoiseRule `yaml:"rules"`
+}
+
+// NoiseRule sets the threshold for rules whose ID or message matches Match, a pattern
+// where '*' matches any run of characters. A negative threshold never collapses the rule.
+type NoiseRule struct {
+	Match     string `yaml:"match"`
+	Threshold int    `yaml:"threshold"`
+}
+
+// SeverityOverride sets the severity of rules whose ID or message matches Match, a pattern
+// where '*' matches any run of characters. Built-in rules match by their registry ID too.
+type SeverityOverride struct {
+	Match    string `yaml:"match"`
+	Severity string `yaml:"severity"`
+}
+
+const (
+	defaultNoiseThreshold = 5
+	defaultNoiseMaxListed = 10
+)
+
+var defaultProductionEnvironments = []string{"prod", "production"}
+
+// Load reads the config file at path. With an empty path DefaultFile is used if it
+// exists; otherwise an empty configuration is returned.
+func Load(path string) (*Config, error) {
+	explicit := path != ""
+	if !explicit {
+		path = DefaultFile
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && os.IsNotExist(err) {
+			return &Config{}, nil
+		}
+		return nil, err
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	for i, o := range cfg.SeverityOverrides {
+		sev, ok := parseSeverity(o.Severity)
+		if !ok {
+			return nil, fmt.Errorf("%s: severity_overrides[%d]: unknown severity '%s' (use info, warn or error)", path, i, o.Severity)
+		}
+		cfg.SeverityOverrides[i].Severity = string(sev)
+	}
+	return &cfg, nil
+}
+
+func parseSeverity(s string) (finding.Severity, bool) {
+	switch strings.ToLower(s) {
+	case "info":
+		return finding.Info, true
+	case "warn", "warning":
+		return finding.Warning, true
+	case "error":
+		return finding.Error, true
+	}
+	return "", false
+}
+
+// Environment returns the environment a file is classified as, or "" when no classifier matches.
+// Environments are tried in name order so the result is deterministic when globs overlap.
+func (c *Config) Environment(file string) string {
+	if c == nil || len(c.Environments) == 0 {
+		return ""
+	}
+	path := file
+	if filepath.IsAbs(file) {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, file); err == nil {
+				path = rel
+			}
+		}
+	}
+
+	names := make([]string, 0, len(c.Environments))
+	for name := range c.Environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if glob.MatchAny(c.Environments[name], path) {
+			return name
+		}
+	}
+	return ""
+}
+
+// IsProduction reports whether findings in env should be escalated.
+func (c *Config) IsProduction(env string) bool {
+	if env == "" {
+		return false
+	}
+	prod := defaultProductionEnvironments
+	if c != nil && len(c.ProductionEnvironments) > 0 {
+		prod = c.ProductionEnvironments
+	}
+	for _, p := range prod {
+		if p == env {
+			return true
+		}
+	}
+	return false
+}
+
+// NoiseThreshold returns the collapse threshold for a rule, named as in SeverityFor, or 0
+// when it is never collapsed.
+func (c *Config) NoiseThreshold(rules ...string) int {
+	if c == nil || !c.NoiseReduction.Enabled {
+		return 0
+	}
+	n := c.NoiseReduction
+	threshold := n.Threshold
+match:
+	for _, r := range n.Rules {
+		for _, rule := range rules {
+			if rule != "" && (r.Match == rule || wildcardMatch(r.Match, rule)) {
+				threshold = r.Threshold
+				break match
+			}
+		}
+	}
+	switch {
+	case threshold < 0:
+		return 0
+	case threshold == 0:
+		return defaultNoiseThreshold
+	}
+	return threshold
+}
+
+// SeverityFor returns the configured severity of the first override matching any of the
+// names of a rule: its message or ID, and its registry ID.
+func (c *Config) SeverityFor(rules ...string) (finding.Severity, bool) {
+	if c == nil {
+		return "", false
+	}
+	for _, o := range c.SeverityOverrides {
+		for _, rule := range rules {
+			if rule != "" && (o.Match == rule || wildcardMatch(o.Match, rule)) {
+				return finding.Severity(o.Severity), true
+			}
+		}
+	}
+	return "", false
+}
+
+// Validate reports the first severity override or noise rule whose match is a rule ID that
+// known does not recognise; a typo there would otherwise silently match nothing. Matches
+// that are message patterns are not checked.
+func (c *Config) Validate(known func(id string) bool) error {
+	for i, o := range c.SeverityOverrides {
+		if registry.IsID(o.Match) && !known(o.Match) {
+			return fmt.Errorf("severity_overrides[%d]: unknown rule '%s'", i, o.Match)
+		}
+	}
+	for i, r := range c.NoiseReduction.Rules {
+		if registry.IsID(r.Match) && !known(r.Match) {
+			return fmt.Errorf("noise_reduction.rules[%d]: unknown rule '%s'", i, r.Match)
+		}
+	}
+	for i, id := range c.DisabledRules {
+		if !known(id) {
+			return fmt.Errorf("disabled_rules[%d]: unknown rule '%s'", i, id)
+		}
+	}
+	return nil
+}
+
+// switchedOff maps the rules a scanner setting can turn off to that setting.
+var switchedOff = map[string]func(*Config) bool{
+	"TF008": func(c *Config) bool { return !c.Terraform.Variables.TypeRequired() },
+	"TF009": func(c *Config) bool { return !c.Terraform.Variables.DescriptionRequired() },
+	"TF010": func(c *Config) bool { return !c.Terraform.Variables.SensitiveRequired() },
+}
+
+// Enabled reports whether the rule with the given ID runs: it is neither listed in
+// DisabledRules nor switched off by a scanner setting such as terraform.variables.
+func (c *Config) Enabled(id string) bool {
+	if c == nil || id == "" {
+		return true
+	}
+	for _, d := range c.DisabledRules {
+		if d == id {
+			return false
+		}
+	}
+	if off, ok := switchedOff[id]; ok && off(c) {
+		return false
+	}
+	return true
+}
+
+// NoiseMaxListed returns how many locations an aggregated finding lists.
+func (c *Config) NoiseMaxListed() int {
+	if c == nil || c.NoiseReduction.MaxListed <= 0 {
+		return defaultNoiseMaxListed
+	}
+	return c.NoiseReduction.MaxListed
+}
+
+// wildcardMatch matches s against pattern, where '*' matches any run of characters.
+// Unlike path globs it crosses slashes, since rule messages are free text.
+func wildcardMatch(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return false
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return strings.HasSuffix(s, parts[len(parts)-1])
+}
+
+// TypeRequired reports whether variables must declare a type.
+func (v VariableRules) TypeRequired() bool { return enabled(v.RequireType) }
+
+// DescriptionRequired reports whether variables must have a description.
+func (v VariableRules) DescriptionRequired() bool { return enabled(v.RequireDescription) }
+
+// SensitiveRequired reports whether secret-like variables must be declared sensitive.
+func (v VariableRules) SensitiveRequired() bool { return enabled(v.RequireSensitive) }
+
+// Ignored reports whether the variable name is exempt from the hygiene checks.
+func (v VariableRules) Ignored(name string) bool {
+	for _, pattern := range v.Ignore {
+		if pattern == name || wildcardMatch(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// RootAllowed reports whether a task named task calling module may become root.
+func (b BecomeRules) RootAllowed(module, task string) bool {
+	for _, pattern := range b.AllowRoot {
+		for _, s := range []string{module, "ansible.builtin." + module, task} {
+			if s != "" && (pattern == s || wildcardMatch(pattern, s)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func enabled(flag *bool) bool {
+	return flag == nil || *flag
+}
@@ -0,0 +1,133 @@
+package cost
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// estimateCloudFormation prices the resources of a CloudFormation template in YAML or
+// JSON. Files that are not templates yield nothing; properties set through intrinsic
+// functions (!Ref, Fn::If, ...) are left unpriced.
+func estimateCloudFormation(p string) []Estimate {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	root := doc.Content[0]
+	resources := mappingValue(root, "Resources")
+	if root.Kind != yaml.MappingNode || resources == nil || resources.Kind != yaml.MappingNode {
+		return nil
+	}
+	if mappingValue(root, "AWSTemplateFormatVersion") == nil && !hasAWSResource(resources) {
+		return nil
+	}
+
+	var estimates []Estimate
+	for i := 0; i+1 < len(resources.Content); i += 2 {
+		id, res := resources.Content[i], resources.Content[i+1]
+		typ := scalar(mappingValue(res, "Type"))
+		s, ok := cloudFormationSpec(typ, mappingValue(res, "Properties"))
+		if !ok {
+			continue
+		}
+		e := Estimate{File: p, Line: id.Line, Resource: id.Value, Type: typ}
+		estimates = append(estimates, price(e, s))
+	}
+	return estimates
+}
+
+// cloudFormationSpec reads the cost-relevant properties of a priced resource type.
+func cloudFormationSpec(typ string, props *yaml.Node) (spec, bool) {
+	s := spec{count: 1}
+	p := propReader{props: props, spec: &s}
+	switch typ {
+	case "AWS::EC2::Instance":
+		s.kind = "instance"
+		s.class = p.str("InstanceType", true)
+	case "AWS::RDS::DBInstance":
+		s.kind = "database"
+		s.class = p.str("DBInstanceClass", true)
+		s.storageGB = p.number("AllocatedStorage")
+		s.storageType = p.str("StorageType", false)
+		s.multiAZ = strings.EqualFold(p.str("MultiAZ", false), "true")
+	case "AWS::EC2::Volume":
+		s.kind = "volume"
+		s.storageGB = p.number("Size")
+		s.storageType = p.str("VolumeType", false)
+	default:
+		fixed, ok := cfnFixedTypes[typ]
+		if !ok {
+			return s, false
+		}
+		s.kind, s.fixedType = "fixed", fixed
+	}
+	return s, true
+}
+
+// propReader reads literal scalar properties, recording those it cannot resolve.
+type propReader struct {
+	props *yaml.Node
+	spec  *spec
+}
+
+func (p propReader) str(name string, required bool) string {
+	node := mappingValue(p.props, name)
+	if node == nil {
+		if required {
+			p.spec.unknown = append(p.spec.unknown, name+" (not set)")
+		}
+		return ""
+	}
+	if node.Kind != yaml.ScalarNode || strings.HasPrefix(node.Tag, "!") && !strings.HasPrefix(node.Tag, "!!") {
+		p.spec.unknown = append(p.spec.unknown, name)
+		return ""
+	}
+	return node.Value
+}
+
+func (p propReader) number(name string) float64 {
+	v := p.str(name, false)
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		p.spec.unknown = append(p.spec.unknown, name+" (not a number)")
+	}
+	return n
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func scalar(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return node.Value
+}
+
+func hasAWSResource(resources *yaml.Node) bool {
+	for i := 1; i < len(resources.Content); i += 2 {
+		if strings.HasPrefix(scalar(mappingValue(resources.Content[i], "Type")), "AWS::") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,195 @@
+// Package cost estimates the monthly cost of the compute, database and storage
+// resources declared in Terraform and CloudFormation, from a bundled pricing table.
+package cost
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/report"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// Estimate is the priced (or unpriceable) cost of one resource.
+type Estimate struct {
+	File     string
+	Line     int
+	Resource string // e.g. aws_instance.web or the CloudFormation logical ID
+	Type     string
+	Details  string // the priced arguments, e.g. "m5.large x2, 100 GB gp3"
+	Monthly  float64
+	Priced   bool // false when a size or class is not a literal or not in the pricing table
+}
+
+// spec is the cost-relevant arguments of a resource, independent of the IaC language.
+type spec struct {
+	kind          string // instance, database, volume or fixed
+	class         string // instance type or DB instance class
+	storageGB     float64
+	storageType   string
+	multiAZ       bool
+	count         float64 // instances created, 1 unless count is set
+	fixedType     string
+	unknown       []string // arguments that could not be resolved
+	unknownPrices []string // resolved values missing from the pricing table
+}
+
+// EstimatePath scans path for Terraform and CloudFormation and returns the estimates
+// ordered by file and line.
+func EstimatePath(path string) ([]Estimate, error) {
+	var estimates []Estimate
+	err := walkTerraformDirs(path, func(dir string, files []string) {
+		estimates = append(estimates, estimateTerraform(files)...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = scanner.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if p != path && (info.Name() == ".git" || info.Name() == ".terraform") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(p)) {
+		case ".yaml", ".yml", ".json", ".template":
+			estimates = append(estimates, estimateCloudFormation(p)...)
+		}
+		return nil
+	})
+	sort.SliceStable(estimates, func(i, j int) bool {
+		if estimates[i].File != estimates[j].File {
+			return estimates[i].File < estimates[j].File
+		}
+		return estimates[i].Line < estimates[j].Line
+	})
+	return estimates, err
+}
+
+// price turns a resource spec into an estimate.
+func price(e Estimate, s spec) Estimate {
+	count := s.count
+	var parts []string
+	var monthly float64
+	switch s.kind {
+	case "instance":
+		if hourly, ok := instancePrices[s.class]; ok {
+			monthly += hourly * HoursPerMonth
+		} else if s.class != "" {
+			s.unknownPrices = append(s.unknownPrices, "instance type "+s.class)
+		}
+		parts = append(parts, s.class)
+	case "database":
+		azs := 1.0
+		if s.multiAZ {
+			azs = 2
+		}
+		if hourly, ok := databasePrices[s.class]; ok {
+			monthly += hourly * HoursPerMonth * azs
+		} else if s.class != "" {
+			s.unknownPrices = append(s.unknownPrices, "instance class "+s.class)
+		}
+		if s.storageType == "" {
+			s.storageType = defaultStorageType
+		}
+		if perGB, ok := databaseStoragePrices[s.storageType]; ok {
+			monthly += perGB * s.storageGB * azs
+		}
+		parts = append(parts, s.class)
+		if s.storageGB > 0 {
+			parts = append(parts, fmt.Sprintf("%g GB %s", s.storageGB, s.storageType))
+		}
+		if s.multiAZ {
+			parts = append(parts, "multi-AZ")
+		}
+	case "volume":
+		if s.storageType == "" {
+			s.storageType = defaultVolumeType
+		}
+		if perGB, ok := volumePrices[s.storageType]; ok {
+			monthly += perGB * s.storageGB
+		} else {
+			s.unknownPrices = append(s.unknownPrices, "volume type "+s.storageType)
+		}
+		parts = append(parts, fmt.Sprintf("%g GB %s", s.storageGB, s.storageType))
+	case "fixed":
+		monthly = fixedPrices[s.fixedType] * HoursPerMonth
+		parts = append(parts, "flat hourly rate")
+	}
+	if s.kind == "instance" && s.storageGB > 0 {
+		if s.storageType == "" {
+			s.storageType = defaultVolumeType
+		}
+		monthly += volumePrices[s.storageType] * s.storageGB
+		parts = append(parts, fmt.Sprintf("%g GB %s root volume", s.storageGB, s.storageType))
+	}
+	if count != 1 {
+		parts = append(parts, fmt.Sprintf("x%g", count))
+	}
+
+	e.Details = strings.Join(nonEmpty(parts), ", ")
+	e.Monthly = monthly * count
+	e.Priced = len(s.unknown) == 0 && len(s.unknownPrices) == 0
+	if !e.Priced {
+		missing := append(append([]string(nil), s.unknown...), s.unknownPrices...)
+		e.Details = strings.TrimPrefix(e.Details+"; not priced: "+strings.Join(missing, ", "), "; ")
+	}
+	return e
+}
+
+func nonEmpty(parts []string) []string {
+	var out []string
+	for _, p := range parts {
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Findings returns one INFO finding per estimate.
+func Findings(estimates []Estimate) []finding.Finding {
+	var findings []finding.Finding
+	for _, e := range estimates {
+		msg := fmt.Sprintf("Estimated cost of %s (%s): $%.2f/month at on-demand %s prices", e.Resource, e.Details, e.Monthly, PriceRegion)
+		if !e.Priced {
+			msg = fmt.Sprintf("Cost of %s could not be fully estimated (%s)", e.Resource, e.Details)
+		}
+		findings = append(findings, finding.Finding{File: e.File, Severity: finding.Info, Message: msg, Line: e.Line})
+	}
+	return findings
+}
+
+// Section renders the estimates as a cost summary with a total row.
+func Section(estimates []Estimate) report.Section {
+	s := report.Section{
+		Title:   "Cost Estimate",
+		Columns: []string{"Resource", "Type", "Details", "Monthly (USD)"},
+	}
+	var total float64
+	partial := false
+	for _, e := range estimates {
+		amount := fmt.Sprintf("%.2f", e.Monthly)
+		if !e.Priced {
+			amount += " (partial)"
+			partial = true
+		}
+		total += e.Monthly
+		s.Rows = append(s.Rows, []string{e.Resource, e.Type, e.Details, amount})
+	}
+	if len(estimates) > 0 {
+		totalText := fmt.Sprintf("%.2f", total)
+		if partial {
+			totalText += " (partial)"
+		}
+		s.Rows = append(s.Rows, []string{"Total", "", fmt.Sprintf("on-demand %s prices, %d hours/month", PriceRegion, HoursPerMonth), totalText})
+	}
+	return s
+}
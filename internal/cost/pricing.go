@@ -0,0 +1,64 @@
+package cost
+
+// Bundled on-demand prices in USD for us-east-1 (Linux, single-AZ, no reservations).
+// They are meant for order-of-magnitude estimates in review, not for billing.
+
+// HoursPerMonth is the average number of hours in a month used by AWS pricing.
+const HoursPerMonth = 730
+
+// PriceRegion is the region the bundled prices were taken from.
+const PriceRegion = "us-east-1"
+
+// Hourly price per EC2 instance type.
+var instancePrices = map[string]float64{
+	"t2.micro": 0.0116, "t2.small": 0.023, "t2.medium": 0.0464, "t2.large": 0.0928,
+	"t3.nano": 0.0052, "t3.micro": 0.0104, "t3.small": 0.0208, "t3.medium": 0.0416,
+	"t3.large": 0.0832, "t3.xlarge": 0.1664, "t3.2xlarge": 0.3328,
+	"t4g.micro": 0.0084, "t4g.small": 0.0168, "t4g.medium": 0.0336, "t4g.large": 0.0672,
+	"m5.large": 0.096, "m5.xlarge": 0.192, "m5.2xlarge": 0.384, "m5.4xlarge": 0.768,
+	"m6i.large": 0.096, "m6i.xlarge": 0.192, "m6i.2xlarge": 0.384,
+	"m6g.large": 0.077, "m6g.xlarge": 0.154, "m7g.large": 0.0816,
+	"c5.large": 0.085, "c5.xlarge": 0.17, "c5.2xlarge": 0.34, "c6i.large": 0.085, "c6g.large": 0.068,
+	"r5.large": 0.126, "r5.xlarge": 0.252, "r5.2xlarge": 0.504, "r6i.large": 0.126, "r6g.large": 0.1008,
+	"g4dn.xlarge": 0.526, "p3.2xlarge": 3.06,
+}
+
+// Hourly price per RDS instance class (MySQL/PostgreSQL, single-AZ).
+var databasePrices = map[string]float64{
+	"db.t3.micro": 0.018, "db.t3.small": 0.036, "db.t3.medium": 0.072, "db.t3.large": 0.145,
+	"db.t4g.micro": 0.016, "db.t4g.small": 0.032, "db.t4g.medium": 0.065, "db.t4g.large": 0.129,
+	"db.m5.large": 0.178, "db.m5.xlarge": 0.356, "db.m5.2xlarge": 0.712,
+	"db.m6g.large": 0.159, "db.m6i.large": 0.178,
+	"db.r5.large": 0.25, "db.r5.xlarge": 0.5, "db.r6g.large": 0.225,
+}
+
+// Monthly price per GB of EBS volume by volume type.
+var volumePrices = map[string]float64{
+	"gp2": 0.10, "gp3": 0.08, "io1": 0.125, "io2": 0.125, "st1": 0.045, "sc1": 0.015, "standard": 0.05,
+}
+
+// Monthly price per GB of RDS storage by storage type.
+var databaseStoragePrices = map[string]float64{
+	"gp2": 0.115, "gp3": 0.115, "io1": 0.125, "io2": 0.125, "standard": 0.10,
+}
+
+// Default volume and storage types when a resource does not set one.
+const (
+	defaultVolumeType  = "gp2"
+	defaultStorageType = "gp2"
+)
+
+// Hourly price of resources billed at a flat rate regardless of their arguments,
+// keyed by Terraform type. CloudFormation types map onto them through cfnFixedTypes.
+var fixedPrices = map[string]float64{
+	"aws_nat_gateway": 0.045,
+	"aws_lb":          0.0225,
+	"aws_alb":         0.0225,
+	"aws_elb":         0.025,
+}
+
+var cfnFixedTypes = map[string]string{
+	"AWS::EC2::NatGateway":                      "aws_nat_gateway",
+	"AWS::ElasticLoadBalancingV2::LoadBalancer": "aws_lb",
+	"AWS::ElasticLoadBalancing::LoadBalancer":   "aws_elb",
+}
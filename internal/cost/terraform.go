@@ -0,0 +1,198 @@
+package cost
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/gocty"
+
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// walkTerraformDirs calls fn with the .tf files of every directory under path, in path order.
+func walkTerraformDirs(path string, fn func(dir string, files []string)) error {
+	dirs := make(map[string][]string)
+	err := scanner.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if p != path && (info.Name() == ".git" || info.Name() == ".terraform") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(p) == ".tf" {
+			dirs[filepath.Dir(p)] = append(dirs[filepath.Dir(p)], p)
+		}
+		return nil
+	})
+	names := make([]string, 0, len(dirs))
+	for d := range dirs {
+		names = append(names, d)
+	}
+	sort.Strings(names)
+	for _, d := range names {
+		fn(d, dirs[d])
+	}
+	return err
+}
+
+// estimateTerraform prices the resources of one module. Arguments set from variables
+// are resolved through the variables' defaults.
+func estimateTerraform(files []string) []Estimate {
+	var bodies []*hclsyntax.Body
+	defaults := make(map[string]cty.Value)
+	for _, p := range files {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			continue
+		}
+		file, diags := hclsyntax.ParseConfig(data, p, hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			continue // reported by the terraform scanner
+		}
+		body := file.Body.(*hclsyntax.Body)
+		bodies = append(bodies, body)
+		for _, block := range body.Blocks {
+			if block.Type != "variable" || len(block.Labels) != 1 {
+				continue
+			}
+			if def, ok := block.Body.Attributes["default"]; ok {
+				if v, diags := def.Expr.Value(nil); !diags.HasErrors() {
+					defaults[block.Labels[0]] = v
+				}
+			}
+		}
+	}
+	ctx := &hcl.EvalContext{Variables: map[string]cty.Value{"var": cty.ObjectVal(defaults)}}
+
+	var estimates []Estimate
+	for _, body := range bodies {
+		for _, block := range body.Blocks {
+			if block.Type != "resource" || len(block.Labels) != 2 {
+				continue
+			}
+			s, ok := terraformSpec(ctx, block.Labels[0], block.Body)
+			if !ok {
+				continue
+			}
+			e := Estimate{
+				File:     block.DefRange().Filename,
+				Line:     block.DefRange().Start.Line,
+				Resource: block.Labels[0] + "." + block.Labels[1],
+				Type:     block.Labels[0],
+			}
+			estimates = append(estimates, price(e, s))
+		}
+	}
+	return estimates
+}
+
+// terraformSpec reads the cost-relevant arguments of a priced resource type.
+func terraformSpec(ctx *hcl.EvalContext, resourceType string, body *hclsyntax.Body) (spec, bool) {
+	s := spec{count: 1}
+	a := attrReader{ctx: ctx, body: body, spec: &s}
+	switch resourceType {
+	case "aws_instance":
+		s.kind = "instance"
+		s.class = a.str("instance_type", true)
+		for _, inner := range body.Blocks {
+			if inner.Type == "root_block_device" {
+				root := attrReader{ctx: ctx, body: inner.Body, spec: &s}
+				s.storageGB = root.number("volume_size", false)
+				s.storageType = root.str("volume_type", false)
+			}
+		}
+	case "aws_db_instance":
+		s.kind = "database"
+		s.class = a.str("instance_class", true)
+		s.storageGB = a.number("allocated_storage", false)
+		s.storageType = a.str("storage_type", false)
+		s.multiAZ = a.boolean("multi_az")
+	case "aws_ebs_volume":
+		s.kind = "volume"
+		s.storageGB = a.number("size", true)
+		s.storageType = a.str("type", false)
+	default:
+		if _, ok := fixedPrices[resourceType]; !ok {
+			return s, false
+		}
+		s.kind, s.fixedType = "fixed", resourceType
+	}
+	if _, ok := body.Attributes["count"]; ok {
+		unresolved := len(s.unknown)
+		if s.count = a.number("count", true); s.count == 0 && len(s.unknown) == unresolved {
+			return s, false // count = 0 creates nothing
+		}
+	}
+	if _, ok := body.Attributes["for_each"]; ok {
+		s.unknown = append(s.unknown, "for_each")
+	}
+	return s, true
+}
+
+// attrReader evaluates literal (or variable default) arguments, recording those it cannot resolve.
+type attrReader struct {
+	ctx  *hcl.EvalContext
+	body *hclsyntax.Body
+	spec *spec
+}
+
+func (a attrReader) value(name string, required bool) (cty.Value, bool) {
+	attr, ok := a.body.Attributes[name]
+	if !ok {
+		if required {
+			a.spec.unknown = append(a.spec.unknown, name+" (not set)")
+		}
+		return cty.NilVal, false
+	}
+	v, diags := attr.Expr.Value(a.ctx)
+	if diags.HasErrors() || v.IsNull() || !v.IsWhollyKnown() {
+		a.spec.unknown = append(a.spec.unknown, name)
+		return cty.NilVal, false
+	}
+	return v, true
+}
+
+func (a attrReader) str(name string, required bool) string {
+	v, ok := a.value(name, required)
+	if !ok {
+		return ""
+	}
+	var s string
+	if v, err := convert.Convert(v, cty.String); err != nil || gocty.FromCtyValue(v, &s) != nil {
+		a.spec.unknown = append(a.spec.unknown, name)
+	}
+	return s
+}
+
+func (a attrReader) number(name string, required bool) float64 {
+	v, ok := a.value(name, required)
+	if !ok {
+		return 0
+	}
+	var n float64
+	if v, err := convert.Convert(v, cty.Number); err != nil || gocty.FromCtyValue(v, &n) != nil {
+		a.spec.unknown = append(a.spec.unknown, fmt.Sprintf("%s (not a number)", name))
+	}
+	return n
+}
+
+func (a attrReader) boolean(name string) bool {
+	v, ok := a.value(name, false)
+	if !ok {
+		return false
+	}
+	var b bool
+	if v, err := convert.Convert(v, cty.Bool); err != nil || gocty.FromCtyValue(v, &b) != nil {
+		a.spec.unknown = append(a.spec.unknown, name)
+	}
+	return b
+}
@@ -0,0 +1,98 @@
+package dashboard
+
+import (
+	"fmt"
+	"html/template"
+	"net/url"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/history"
+)
+
+// series is one line of a chart: a value for each scan.
+type series struct {
+	Name   string
+	Class  string // the CSS class giving the line its colour
+	Values []int
+}
+
+// Chart sizes, in pixels, and the margins around the plot, which hold the axis labels.
+const (
+	chartWidth, chartHeight = 760, 220
+	marginLeft, marginRight = 44, 12
+	marginTop, marginBottom = 12, 28
+	sparkWidth, sparkHeight = 120, 24
+)
+
+// chart renders lines over scans as an SVG chart, each point linking to its scan, with the
+// values on the y axis and the dates of the first and last scan on the x axis.
+func chart(scans []history.Scan, lines []series) template.HTML {
+	top := peak(lines)
+	x := position(len(scans), marginLeft, chartWidth-marginRight)
+	y := func(v int) float64 {
+		return marginTop + float64(chartHeight-marginTop-marginBottom)*(1-float64(v)/float64(top))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="chart" viewBox="0 0 %d %d" width="%d" height="%d" role="img">`, chartWidth, chartHeight, chartWidth, chartHeight)
+	for _, v := range []int{0, top / 2, top} {
+		fmt.Fprintf(&b, `<line class="grid" x1="%d" x2="%d" y1="%.1f" y2="%.1f"/>`, marginLeft, chartWidth-marginRight, y(v), y(v))
+		fmt.Fprintf(&b, `<text class="axis" x="%d" y="%.1f" text-anchor="end">%d</text>`, marginLeft-6, y(v)+4, v)
+	}
+	fmt.Fprintf(&b, `<text class="axis" x="%.1f" y="%d">%s</text>`, x(0), chartHeight-8, day(scans[0].Time))
+	if len(scans) > 1 {
+		fmt.Fprintf(&b, `<text class="axis" x="%.1f" y="%d" text-anchor="end">%s</text>`, x(len(scans)-1), chartHeight-8, day(scans[len(scans)-1].Time))
+	}
+	for _, l := range lines {
+		fmt.Fprintf(&b, `<polyline class="line %s" points="%s"/>`, l.Class, points(l.Values, x, y))
+		for i, v := range l.Values {
+			title := fmt.Sprintf("%s, %s: %d %s", short(scans[i].Commit), scans[i].Time.Local().Format("2006-01-02 15:04"), v, l.Name)
+			fmt.Fprintf(&b, `<a href="/scans/%s"><circle class="point %s" cx="%.1f" cy="%.1f" r="3"><title>%s</title></circle></a>`,
+				url.PathEscape(scans[i].Commit), l.Class, x(i), y(v), template.HTMLEscapeString(title))
+		}
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// spark renders values as a small SVG line without axes or labels.
+func spark(values []int) template.HTML {
+	top := peak([]series{{Values: values}})
+	x := position(len(values), 2, sparkWidth-2)
+	y := func(v int) float64 {
+		return 2 + float64(sparkHeight-4)*(1-float64(v)/float64(top))
+	}
+	return template.HTML(fmt.Sprintf(`<svg class="spark" viewBox="0 0 %d %d" width="%d" height="%d"><polyline class="line total" points="%s"/></svg>`,
+		sparkWidth, sparkHeight, sparkWidth, sparkHeight, points(values, x, y)))
+}
+
+// peak is the largest value of lines, and at least 1 so an empty chart has a scale.
+func peak(lines []series) int {
+	top := 1
+	for _, l := range lines {
+		for _, v := range l.Values {
+			if v > top {
+				top = v
+			}
+		}
+	}
+	return top
+}
+
+// position spreads n points evenly from left to right, centring a single one.
+func position(n int, left, right int) func(int) float64 {
+	return func(i int) float64 {
+		if n < 2 {
+			return float64(left+right) / 2
+		}
+		return float64(left) + float64(right-left)*float64(i)/float64(n-1)
+	}
+}
+
+func points(values []int, x func(int) float64, y func(int) float64) string {
+	p := make([]string, len(values))
+	for i, v := range values {
+		p[i] = fmt.Sprintf("%.1f,%.1f", x(i), y(v))
+	}
+	return strings.Join(p, " ")
+}
@@ -0,0 +1,287 @@
+// Package dashboard serves a local web UI over the scan history: the findings of the
+// recorded scans over time, the trend of each rule and the findings of each scan. Pages are
+// plain HTML with inline SVG charts, so the dashboard works without network access.
+package dashboard
+
+import (
+	_ "embed"
+	"html/template"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/history"
+	"github.com/salchaD-27/infra-check/internal/registry"
+)
+
+//go:embed dashboard.html
+var pages string
+
+var tmpl = template.Must(template.New("dashboard").Funcs(template.FuncMap{"path": url.PathEscape}).Parse(pages))
+
+// Loader returns the recorded scans, oldest first. It is called for every page, so scans
+// recorded while the dashboard runs show up on the next reload.
+type Loader func() ([]history.Scan, error)
+
+type dashboard struct {
+	title string
+	load  Loader
+}
+
+// Handler serves the dashboard of the scans load returns, titled title:
+//
+//	GET /                 findings by severity over time, the scans and the rules
+//	GET /scans/{commit}   the findings of a scan, with those new and fixed since the one before
+//	GET /rules/{rule}     the findings of a rule over time and in the latest scan
+func Handler(title string, load Loader) http.Handler {
+	d := &dashboard{title: title, load: load}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", d.overview)
+	mux.HandleFunc("GET /scans/{commit}", d.scan)
+	mux.HandleFunc("GET /rules/{rule...}", d.rule)
+	return mux
+}
+
+// scanRow is a scan as the tables list it.
+type scanRow struct {
+	Commit, Short, Time  string
+	Findings             int
+	Error, Warning, Info int
+	New, Fixed           int
+}
+
+// ruleRow is a rule with its findings in the latest scan and a sparkline of them over time.
+type ruleRow struct {
+	Key, Label string
+	Count      int
+	Spark      template.HTML
+}
+
+// findingRow is a finding as the tables list it.
+type findingRow struct {
+	Severity, Location, Message string
+	Rule, RuleLabel             string
+	New                         bool
+}
+
+func (d *dashboard) overview(w http.ResponseWriter, r *http.Request) {
+	steps, ok := d.steps(w)
+	if !ok {
+		return
+	}
+	scans := make([]history.Scan, len(steps))
+	rows := make([]scanRow, len(steps))
+	for i, s := range steps {
+		scans[i] = s.Scan
+		rows[len(steps)-1-i] = rowOf(s) // newest first
+	}
+
+	counts := make(map[string][]int) // findings of each rule in each scan
+	for i, scan := range scans {
+		for _, f := range scan.Findings {
+			key := registry.Key(f)
+			if counts[key] == nil {
+				counts[key] = make([]int, len(scans))
+			}
+			counts[key][i]++
+		}
+	}
+	rules := make([]ruleRow, 0, len(counts))
+	for key, values := range counts {
+		rules = append(rules, ruleRow{Key: key, Label: label(key), Count: values[len(values)-1], Spark: spark(values)})
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Count != rules[j].Count {
+			return rules[i].Count > rules[j].Count
+		}
+		return rules[i].Key < rules[j].Key
+	})
+
+	d.render(w, "overview", struct {
+		Title string
+		Chart template.HTML
+		Scans []scanRow
+		Rules []ruleRow
+	}{d.title, chart(scans, bySeverity(scans)), rows, rules})
+}
+
+func (d *dashboard) scan(w http.ResponseWriter, r *http.Request) {
+	steps, ok := d.steps(w)
+	if !ok {
+		return
+	}
+	for _, s := range steps {
+		if s.Scan.Commit != r.PathValue("commit") {
+			continue
+		}
+		isNew := make(map[string]bool, len(s.New))
+		for _, f := range s.New {
+			isNew[f.Fingerprint] = true
+		}
+		d.render(w, "scan", struct {
+			Title    string
+			Scan     scanRow
+			Findings []findingRow
+			Fixed    []findingRow
+		}{d.title, rowOf(s), findingRows(s.Scan.Findings, isNew), findingRows(s.Fixed, nil)})
+		return
+	}
+	http.Error(w, "no recorded scan of commit "+r.PathValue("commit"), http.StatusNotFound)
+}
+
+func (d *dashboard) rule(w http.ResponseWriter, r *http.Request) {
+	steps, ok := d.steps(w)
+	if !ok {
+		return
+	}
+	key := r.PathValue("rule")
+	scans := make([]history.Scan, len(steps))
+	counts := make([]int, len(steps))
+	seen := false
+	for i, s := range steps {
+		scans[i] = s.Scan
+		for _, f := range s.Scan.Findings {
+			if registry.Key(f) == key {
+				counts[i]++
+				seen = true
+			}
+		}
+	}
+	if !seen {
+		http.Error(w, "no recorded finding of rule "+key, http.StatusNotFound)
+		return
+	}
+	latest := steps[len(steps)-1]
+	isNew := make(map[string]bool)
+	var findings []finding.Finding
+	for _, f := range latest.New {
+		isNew[f.Fingerprint] = true
+	}
+	for _, f := range latest.Scan.Findings {
+		if registry.Key(f) == key {
+			findings = append(findings, f)
+		}
+	}
+	rule, _ := registry.Lookup(key)
+	d.render(w, "rule", struct {
+		Title    string
+		Key      string
+		Rule     registry.Rule
+		Chart    template.HTML
+		Latest   scanRow
+		Findings []findingRow
+	}{d.title, key, rule, chart(scans, []series{{Name: "findings", Class: "total", Values: counts}}), rowOf(latest), findingRows(findings, isNew)})
+}
+
+// steps loads the scans and compares each with the one before, or answers the request with
+// the error.
+func (d *dashboard) steps(w http.ResponseWriter) ([]history.Step, bool) {
+	scans, err := d.load()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if len(scans) == 0 {
+		http.Error(w, "no scans recorded yet; record them with 'scan ... --record'", http.StatusNotFound)
+		return nil, false
+	}
+	return history.Trend(scans), true
+}
+
+func (d *dashboard) render(w http.ResponseWriter, name string, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tmpl.ExecuteTemplate(w, name, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func rowOf(s history.Step) scanRow {
+	row := scanRow{
+		Commit:   s.Scan.Commit,
+		Short:    short(s.Scan.Commit),
+		Time:     s.Scan.Time.Local().Format("2006-01-02 15:04"),
+		Findings: len(s.Scan.Findings),
+		New:      len(s.New),
+		Fixed:    len(s.Fixed),
+	}
+	for _, f := range s.Scan.Findings {
+		switch f.Severity {
+		case finding.Error:
+			row.Error++
+		case finding.Warning:
+			row.Warning++
+		case finding.Info:
+			row.Info++
+		}
+	}
+	return row
+}
+
+// findingRows lists findings, the most severe first, marking those whose fingerprint is in isNew.
+func findingRows(findings []finding.Finding, isNew map[string]bool) []findingRow {
+	rows := make([]findingRow, 0, len(findings))
+	for _, f := range findings {
+		key := registry.Key(f)
+		rows = append(rows, findingRow{
+			Severity:  string(f.Severity),
+			Location:  f.Location(),
+			Message:   f.Message,
+			Rule:      key,
+			RuleLabel: label(key),
+			New:       isNew[f.Fingerprint],
+		})
+	}
+	rank := map[string]int{string(finding.Error): 0, string(finding.Warning): 1, string(finding.Info): 2}
+	sort.SliceStable(rows, func(i, j int) bool { return rank[rows[i].Severity] < rank[rows[j].Severity] })
+	return rows
+}
+
+// bySeverity is the series of the findings of scans of each severity and in total.
+func bySeverity(scans []history.Scan) []series {
+	out := []series{
+		{Name: "total", Class: "total"},
+		{Name: string(finding.Error), Class: "error"},
+		{Name: string(finding.Warning), Class: "warn"},
+		{Name: string(finding.Info), Class: "info"},
+	}
+	for i := range out {
+		out[i].Values = make([]int, len(scans))
+	}
+	for i, scan := range scans {
+		out[0].Values[i] = len(scan.Findings)
+		for _, f := range scan.Findings {
+			switch f.Severity {
+			case finding.Error:
+				out[1].Values[i]++
+			case finding.Warning:
+				out[2].Values[i]++
+			case finding.Info:
+				out[3].Values[i]++
+			}
+		}
+	}
+	return out
+}
+
+// label names the rule whose findings have key: its ID and name, or the key itself, the
+// message of a check no rule is known for.
+func label(key string) string {
+	if r, ok := registry.Lookup(key); ok {
+		return r.ID + " " + r.Name
+	}
+	return key
+}
+
+func short(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
+
+// day formats the time of a scan on the x axis of charts.
+func day(t time.Time) string {
+	return t.Local().Format("Jan 2")
+}
@@ -0,0 +1,49 @@
+package dashboard
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/history"
+)
+
+func TestPages(t *testing.T) {
+	open := finding.Finding{File: "main.tf", Line: 3, Severity: finding.Error, Message: "Bucket is public", Fingerprint: "aaaa"}
+	fixed := finding.Finding{File: "main.tf", Line: 9, Severity: finding.Warning, Message: "Uses a /tmp path", Fingerprint: "bbbb"}
+	added := finding.Finding{File: "vars.tf", Line: 1, Severity: finding.Info, Message: "Variable without description", Fingerprint: "cccc"}
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	scans := []history.Scan{
+		{Commit: "1111111111111111", Time: start, Findings: []finding.Finding{open, fixed}},
+		{Commit: "2222222222222222", Time: start.Add(24 * time.Hour), Findings: []finding.Finding{open, added}},
+	}
+	h := Handler("history.db", func() ([]history.Scan, error) { return scans, nil })
+
+	for _, tc := range []struct {
+		path   string
+		status int
+		want   []string
+	}{
+		{"/", http.StatusOK, []string{"<svg", `href="/scans/2222222222222222"`, "+1", "-1", "Bucket is public"}},
+		{"/scans/2222222222222222", http.StatusOK, []string{`vars.tf:1</code> <span class="badge">new</span>`, "main.tf:9", "Uses a /tmp path"}},
+		{"/rules/" + url.PathEscape("Uses a /tmp path"), http.StatusOK, []string{"<svg", "None"}},
+		{"/scans/3333333333333333", http.StatusNotFound, nil},
+		{"/rules/nothing", http.StatusNotFound, nil},
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", tc.path, nil))
+		if rec.Code != tc.status {
+			t.Errorf("GET %s: status %d, want %d", tc.path, rec.Code, tc.status)
+			continue
+		}
+		for _, s := range tc.want {
+			if !strings.Contains(rec.Body.String(), s) {
+				t.Errorf("GET %s: page lacks %q", tc.path, s)
+			}
+		}
+	}
+}
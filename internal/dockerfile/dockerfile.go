@@ -0,0 +1,273 @@
+// static analysis of Dockerfiles
+package dockerfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// Instruction is one logical Dockerfile instruction with continuation lines joined.
+type Instruction struct {
+	Cmd  string // upper-cased instruction keyword, e.g. RUN
+	Args string
+	Line int // line the instruction starts on
+}
+
+// Keywords to detect secrets passed through ENV or ARG
+var secretKeywords = []string{"password", "secret", "token", "api_key", "apikey", "access_key", "private_key"}
+
+// Matches downloads piped straight into a shell: curl ... | sh
+var pipeToShellRegex = regexp.MustCompile(`\b(curl|wget)\b[^|;&]*\|\s*(sudo\s+)?(ba|z|da)?sh\b`)
+
+// Matches sudo at the start of a shell command
+var sudoRegex = regexp.MustCompile(`(^|[;&|]\s*)sudo\b`)
+
+// Matches world-writable chmod modes
+var chmod777Regex = regexp.MustCompile(`\bchmod\s+(-R\s+)?0?777\b`)
+
+// IsDockerfile reports whether the file name looks like a Dockerfile or Containerfile.
+func IsDockerfile(p string) bool {
+	base := filepath.Base(p)
+	for _, name := range []string{"Dockerfile", "Containerfile"} {
+		if base == name || strings.HasPrefix(base, name+".") {
+			return true
+		}
+	}
+	return strings.HasSuffix(base, ".dockerfile")
+}
+
+// Scan walks path for Dockerfiles and checks each one.
+func Scan(path string) ([]finding.Finding, error) {
+	return scanner.Collect(path, ScanStream)
+}
+
+// ScanStream is Scan emitting the findings of each Dockerfile as it is checked.
+func ScanStream(path string, emit scanner.Emit) error {
+	return scanner.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		if !IsDockerfile(p) {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			emit(finding.Finding{
+				File:     p,
+				Severity: finding.Error,
+				Message:  fmt.Sprintf("Failed to read file: %v", err),
+			})
+			return nil
+		}
+		for _, f := range Check(p, Parse(string(data))) {
+			emit(f)
+		}
+		return nil
+	})
+}
+
+// Parse splits a Dockerfile into instructions, joining backslash continuations
+// and dropping comments and blank lines.
+func Parse(content string) []Instruction {
+	var out []Instruction
+	var current strings.Builder
+	start := 0
+
+	for i, raw := range strings.Split(content, "\n") {
+		line := strings.TrimSpace(raw)
+		if current.Len() == 0 {
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			start = i + 1
+		} else if strings.HasPrefix(line, "#") {
+			continue // comments are allowed between continuation lines
+		}
+
+		if strings.HasSuffix(line, "\\") {
+			current.WriteString(strings.TrimSuffix(line, "\\") + " ")
+			continue
+		}
+		current.WriteString(line)
+
+		text := current.String()
+		current.Reset()
+		cmd, args, _ := strings.Cut(text, " ")
+		out = append(out, Instruction{Cmd: strings.ToUpper(cmd), Args: strings.TrimSpace(args), Line: start})
+	}
+	return out
+}
+
+// Check runs the Dockerfile checks against parsed instructions.
+func Check(p string, instructions []Instruction) []finding.Finding {
+	var findings []finding.Finding
+	add := func(sev finding.Severity, line int, format string, args ...interface{}) {
+		findings = append(findings, finding.Finding{File: p, Severity: sev, Message: fmt.Sprintf(format, args...), Line: line})
+	}
+
+	stages := make(map[string]bool)
+	user, userLine := "", 0
+	healthcheck := false
+	lastFrom := 0
+
+	for _, in := range instructions {
+		switch in.Cmd {
+		case "FROM":
+			lastFrom = in.Line
+			user, userLine = "", 0
+			fields := strings.Fields(in.Args)
+			var image string
+			for _, f := range fields {
+				if !strings.HasPrefix(f, "--") {
+					image = f
+					break
+				}
+			}
+			for i, f := range fields {
+				if strings.EqualFold(f, "AS") && i+1 < len(fields) {
+					stages[strings.ToLower(fields[i+1])] = true
+				}
+			}
+			if image == "" || image == "scratch" || stages[strings.ToLower(image)] || strings.Contains(image, "$") {
+				continue
+			}
+			if !strings.Contains(image, "@") {
+				name := image[strings.LastIndex(image, "/")+1:]
+				tag := ""
+				if i := strings.Index(name, ":"); i >= 0 {
+					tag = name[i+1:]
+				}
+				switch tag {
+				case "":
+					add(finding.Warning, in.Line, "Base image '%s' has no tag and resolves to 'latest' (pin a version tag or digest)", image)
+				case "latest":
+					add(finding.Warning, in.Line, "Base image '%s' uses the 'latest' tag (pin a version tag or digest)", image)
+				}
+			}
+
+		case "USER":
+			user, userLine = strings.Fields(in.Args + " ")[0], in.Line
+
+		case "ADD":
+			for _, src := range addSources(in.Args) {
+				if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+					add(finding.Warning, in.Line, "ADD downloads '%s' without integrity verification (use ADD --checksum or curl with a checksum check)", src)
+				} else if !isArchive(src) {
+					add(finding.Info, in.Line, "Use COPY instead of ADD for '%s' (ADD also extracts archives and fetches URLs)", src)
+				}
+			}
+
+		case "RUN":
+			if pipeToShellRegex.MatchString(in.Args) {
+				add(finding.Warning, in.Line, "RUN pipes a download straight into a shell; download, verify and then execute instead")
+			}
+			if sudoRegex.MatchString(in.Args) {
+				add(finding.Warning, in.Line, "RUN uses sudo; switch USER for the commands that need it instead")
+			}
+			if chmod777Regex.MatchString(in.Args) {
+				add(finding.Warning, in.Line, "RUN makes files world-writable (chmod 777)")
+			}
+			if strings.Contains(in.Args, "apt-get install") && !strings.Contains(in.Args, "--no-install-recommends") {
+				add(finding.Info, in.Line, "apt-get install without --no-install-recommends pulls in unneeded packages")
+			}
+
+		case "ENV", "ARG":
+			for _, name := range declaredNames(in.Cmd, in.Args) {
+				if !looksLikeSecret(name.key) || name.value == "" || strings.HasPrefix(name.value, "$") {
+					continue
+				}
+				if in.Cmd == "ENV" {
+					add(finding.Error, in.Line, "ENV '%s' bakes a hardcoded secret into the image", name.key)
+				} else {
+					add(finding.Warning, in.Line, "ARG '%s' has a default secret; build args are visible in the image history (use build secrets)", name.key)
+				}
+			}
+
+		case "EXPOSE":
+			for _, port := range strings.Fields(in.Args) {
+				if strings.HasPrefix(port, "22/") || port == "22" {
+					add(finding.Warning, in.Line, "Image exposes SSH port 22")
+				}
+			}
+
+		case "MAINTAINER":
+			add(finding.Info, in.Line, "MAINTAINER is deprecated; use LABEL org.opencontainers.image.authors")
+
+		case "HEALTHCHECK":
+			healthcheck = !strings.EqualFold(strings.TrimSpace(in.Args), "NONE")
+		}
+	}
+
+	if lastFrom == 0 {
+		return findings
+	}
+	if user == "" {
+		add(finding.Warning, lastFrom, "Final stage sets no USER, so the container runs as root")
+	} else if u, _, _ := strings.Cut(user, ":"); u == "root" || u == "0" {
+		add(finding.Warning, userLine, "Final stage runs as root")
+	}
+	if !healthcheck {
+		add(finding.Info, lastFrom, "Image defines no HEALTHCHECK")
+	}
+	return findings
+}
+
+// addSources returns the source arguments of an ADD instruction (all but the destination).
+func addSources(args string) []string {
+	var fields []string
+	for _, f := range strings.Fields(args) {
+		if !strings.HasPrefix(f, "--") {
+			fields = append(fields, f)
+		}
+	}
+	if len(fields) < 2 || strings.HasPrefix(fields[0], "[") {
+		return nil
+	}
+	return fields[:len(fields)-1]
+}
+
+func isArchive(src string) bool {
+	for _, ext := range []string{".tar", ".tar.gz", ".tgz", ".tar.bz2", ".tar.xz", ".txz"} {
+		if strings.HasSuffix(src, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+type declaration struct {
+	key, value string
+}
+
+// declaredNames parses ENV/ARG arguments in both key=value and legacy "ENV key value" forms.
+func declaredNames(cmd, args string) []declaration {
+	if !strings.Contains(args, "=") {
+		key, value, _ := strings.Cut(args, " ")
+		if cmd == "ARG" {
+			return []declaration{{key: key}}
+		}
+		return []declaration{{key, strings.TrimSpace(value)}}
+	}
+	var out []declaration
+	for _, f := range strings.Fields(args) {
+		key, value, _ := strings.Cut(f, "=")
+		out = append(out, declaration{key, strings.Trim(value, `"'`)})
+	}
+	return out
+}
+
+func looksLikeSecret(name string) bool {
+	lower := strings.ToLower(name)
+	for _, kw := range secretKeywords {
+		if strings.Contains(lower, kw) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package dockerfile
+
+import (
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// dockerfileScanner scans every Dockerfile on its own so findings stay attributed per file.
+type dockerfileScanner struct{}
+
+func init() {
+	scanner.Register(dockerfileScanner{})
+}
+
+func (dockerfileScanner) Name() string { return "dockerfile" }
+func (dockerfileScanner) Description() string {
+	return "Scan Dockerfiles in the specified directories or files"
+}
+func (dockerfileScanner) FileMatcher() scanner.FileMatcher            { return IsDockerfile }
+func (dockerfileScanner) Scan(path string) ([]finding.Finding, error) { return Scan(path) }
+func (dockerfileScanner) ScanStream(path string, emit scanner.Emit) error {
+	return ScanStream(path, emit)
+}
+
+func (dockerfileScanner) Targets(root string, files []string) []string { return files }
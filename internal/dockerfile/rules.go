@@ -0,0 +1,101 @@
+package dockerfile
+
+import (
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/registry"
+)
+
+func init() {
+	scanners := []string{"dockerfile"}
+	registry.Register(
+		registry.Rule{
+			ID: "DKR001", Name: "Unpinned base image", Severity: finding.Warning, Category: "supply-chain", Scanners: scanners,
+			Compliance:  registry.SupplyChain,
+			Description: "A FROM instruction uses no tag or the latest tag, so rebuilding can silently pick up a different image.",
+			Messages:    []string{"Base image '*' has no tag and resolves to 'latest' *", "Base image '*' uses the 'latest' tag *"},
+			Remediation: "FROM node:20.11-alpine3.19@sha256:<digest>",
+		},
+		registry.Rule{
+			ID: "DKR002", Name: "Unverified remote ADD", Severity: finding.Warning, Category: "supply-chain", Scanners: scanners,
+			Compliance:  registry.SupplyChain,
+			Description: "ADD downloads a URL without --checksum, so a compromised server can change what goes into the image.",
+			Messages:    []string{"ADD downloads '*' without integrity verification *"},
+			Remediation: "ADD --checksum=sha256:<digest> https://example.com/tool.tar.gz /tmp/",
+		},
+		registry.Rule{
+			ID: "DKR003", Name: "ADD instead of COPY", Severity: finding.Info, Category: "hygiene", Scanners: scanners,
+			Description: "ADD copies a local file; COPY does the same without ADD's implicit archive extraction and URL fetching.",
+			Messages:    []string{"Use COPY instead of ADD for '*' *"},
+			Remediation: "COPY app/ /srv/app/",
+		},
+		registry.Rule{
+			ID: "DKR004", Name: "Download piped into a shell", Severity: finding.Warning, Category: "supply-chain", Scanners: scanners,
+			Compliance:  registry.SupplyChain,
+			Description: "RUN pipes curl or wget output straight into a shell, executing whatever the server returns.",
+			Messages:    []string{"RUN pipes a download straight into a shell; *"},
+			Remediation: `RUN curl -fsSLo install.sh https://example.com/install.sh \
+ && echo "<sha256>  install.sh" | sha256sum -c - \
+ && sh install.sh`,
+		},
+		registry.Rule{
+			ID: "DKR005", Name: "sudo in RUN", Severity: finding.Warning, Category: "privilege", Scanners: scanners,
+			Compliance:  registry.PrivilegedWorkloads,
+			Description: "RUN uses sudo, which needs it installed and configured in the image; switch USER instead.",
+			Messages:    []string{"RUN uses sudo; *"},
+			Remediation: `USER root
+RUN apt-get update && apt-get install -y --no-install-recommends curl
+USER app`,
+		},
+		registry.Rule{
+			ID: "DKR006", Name: "World-writable files", Severity: finding.Warning, Category: "permissions", Scanners: scanners,
+			Compliance:  registry.FilePermissions,
+			Description: "RUN sets mode 777, letting any process in the container modify the files.",
+			Messages:    []string{"RUN makes files world-writable (chmod 777)"},
+			Remediation: "RUN chown -R app:app /srv/app && chmod -R 750 /srv/app",
+		},
+		registry.Rule{
+			ID: "DKR007", Name: "apt-get install with recommends", Severity: finding.Info, Category: "hygiene", Scanners: scanners,
+			Description: "apt-get install without --no-install-recommends installs packages the image does not need.",
+			Messages:    []string{"apt-get install without --no-install-recommends *"},
+			Remediation: `RUN apt-get update \
+ && apt-get install -y --no-install-recommends curl \
+ && rm -rf /var/lib/apt/lists/*`,
+		},
+		registry.Rule{
+			ID: "DKR008", Name: "Secret in ENV or ARG", Severity: finding.Error, Category: "secrets", Scanners: scanners,
+			Compliance:  registry.HardcodedSecrets,
+			Description: "An ENV or ARG with a secret-like name has a literal value, which is stored in the image or its history.",
+			Messages:    []string{"ENV '*' bakes a hardcoded secret into the image", "ARG '*' has a default secret; *"},
+			Remediation: `RUN --mount=type=secret,id=npm_token \
+    NPM_TOKEN="$(cat /run/secrets/npm_token)" npm ci`,
+		},
+		registry.Rule{
+			ID: "DKR009", Name: "SSH port exposed", Severity: finding.Warning, Category: "network", Scanners: scanners,
+			Compliance:  registry.NetworkExposure,
+			Description: "The image exposes port 22, which suggests an SSH server inside the container.",
+			Messages:    []string{"Image exposes SSH port 22"},
+			Remediation: `# drop EXPOSE 22 and use docker exec or kubectl exec to get a shell
+EXPOSE 8080`,
+		},
+		registry.Rule{
+			ID: "DKR010", Name: "MAINTAINER instruction", Severity: finding.Info, Category: "deprecation", Scanners: scanners,
+			Description: "MAINTAINER is deprecated in favour of the org.opencontainers.image.authors label.",
+			Messages:    []string{"MAINTAINER is deprecated; *"},
+			Remediation: `LABEL org.opencontainers.image.authors="platform@example.com"`,
+		},
+		registry.Rule{
+			ID: "DKR011", Name: "Container runs as root", Severity: finding.Warning, Category: "privilege", Scanners: scanners,
+			Compliance:  registry.PrivilegedWorkloads,
+			Description: "The final stage sets no USER or switches to root, so the container process runs as root.",
+			Messages:    []string{"Final stage sets no USER, so the container runs as root", "Final stage runs as root"},
+			Remediation: `RUN addgroup -S app && adduser -S -G app app
+USER app`,
+		},
+		registry.Rule{
+			ID: "DKR012", Name: "No HEALTHCHECK", Severity: finding.Info, Category: "reliability", Scanners: scanners,
+			Description: "The image defines no HEALTHCHECK, so Docker cannot tell a hung container from a healthy one.",
+			Messages:    []string{"Image defines no HEALTHCHECK"},
+			Remediation: "HEALTHCHECK --interval=30s --timeout=3s CMD wget -qO- http://localhost:8080/health || exit 1",
+		},
+	)
+}
@@ -0,0 +1,81 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/report"
+)
+
+// Delta is the difference between the findings of two scans.
+type Delta struct {
+	New       []finding.Finding // in the new scan only
+	Fixed     []finding.Finding // in the old scan only
+	Unchanged []finding.Finding // in both, as the new scan reports them
+}
+
+// Compare classifies the findings of two scans as new, fixed or unchanged. Findings match by
+// fingerprint (see Fingerprint) when both scans have them, so a finding that only moved to
+// another line is unchanged; findings of reports from before fingerprints match by file and
+// rule, a repeated rule in a file counting once per occurrence.
+func Compare(old, current []finding.Finding) Delta {
+	key := func(f finding.Finding) string { return f.Fingerprint }
+	if !fingerprinted(old) || !fingerprinted(current) {
+		key = func(f finding.Finding) string { return f.File + "\x00" + f.Rule() }
+	}
+
+	remaining := make(map[string]int)
+	for _, f := range old {
+		remaining[key(f)]++
+	}
+	var d Delta
+	for _, f := range current {
+		k := key(f)
+		if remaining[k] > 0 {
+			remaining[k]--
+			d.Unchanged = append(d.Unchanged, f)
+			continue
+		}
+		d.New = append(d.New, f)
+	}
+	for _, f := range old {
+		k := key(f)
+		if remaining[k] > 0 {
+			remaining[k]--
+			d.Fixed = append(d.Fixed, f)
+		}
+	}
+	return d
+}
+
+// Sections renders the delta against the scan named against: the number of new, fixed and
+// unchanged findings, and the fixed findings, which the report no longer lists.
+func (d Delta) Sections(against string) []report.Section {
+	counts := report.Section{
+		Title:   "Comparison with " + against,
+		Columns: []string{"Status", "Findings"},
+		Rows: [][]string{
+			{"new", fmt.Sprint(len(d.New))},
+			{"fixed", fmt.Sprint(len(d.Fixed))},
+			{"unchanged", fmt.Sprint(len(d.Unchanged))},
+		},
+	}
+	fixed := report.Section{Title: "Fixed Findings", Columns: []string{"File", "Line", "Severity", "Message"}}
+	for _, f := range d.Fixed {
+		line := ""
+		if f.Line > 0 {
+			line = fmt.Sprint(f.Line)
+		}
+		fixed.Rows = append(fixed.Rows, []string{f.File, line, string(f.Severity), f.Message})
+	}
+	return []report.Section{counts, fixed}
+}
+
+func fingerprinted(findings []finding.Finding) bool {
+	for _, f := range findings {
+		if f.Fingerprint == "" {
+			return false
+		}
+	}
+	return true
+}
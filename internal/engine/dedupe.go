@@ -0,0 +1,49 @@
+package engine
+
+import (
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/registry"
+)
+
+// Dedupe drops findings repeating the file, line, rule (see registry.ID) and message of an
+// earlier one, as when two scanners both match a file or overlapping targets are scanned twice. The
+// first occurrence is kept, with the highest severity any of its duplicates had. Run it before
+// Process, so overrides and escalation apply to the surviving severity.
+//
+// Findings without a line, such as those of Ansible, are all kept: a scanner reports one per
+// task or play, which the same message on line 0 would merge.
+func Dedupe(findings []finding.Finding) []finding.Finding {
+	first := make(map[dedupeKey]int)
+	var out []finding.Finding
+	for _, f := range findings {
+		if f.Line == 0 {
+			out = append(out, f)
+			continue
+		}
+		k := keyOf(f)
+		i, seen := first[k]
+		if !seen {
+			first[k] = len(out)
+			out = append(out, f)
+			continue
+		}
+		if severityRank[f.Severity] > severityRank[out[i].Severity] {
+			out[i].Severity = f.Severity
+		}
+	}
+	return out
+}
+
+// dedupeKey is what duplicate findings share. The message is part of it as one rule may
+// report several findings on a line, such as one for each missing tag of a resource.
+type dedupeKey struct {
+	file, rule, message string
+	line                int
+}
+
+func keyOf(f finding.Finding) dedupeKey {
+	return dedupeKey{f.File, registry.ID(f), f.Message, f.Line}
+}
+
+// severityRank orders severities from least to most severe.
+var severityRank = map[finding.Severity]int{finding.Info: 1, finding.Warning: 2, finding.Error: 3}
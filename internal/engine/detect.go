@@ -0,0 +1,203 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// Detect walks root and returns the targets each applicable scanner should scan, keyed by
+// scanner name. Each registered scanner sees the files its FileMatcher selects and picks its
+// targets from them (see scanner.TargetDetector); scanners without matching files are absent.
+func Detect(root string) (map[string][]string, error) {
+	files, err := listFiles(root)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make(map[string][]string)
+	for _, s := range scanner.All() {
+		if t := scanner.Targets(s, root, matching(s, files)); len(t) > 0 {
+			targets[s.Name()] = t
+		}
+	}
+	return targets, nil
+}
+
+// DetectFile returns the scanner that handles a single file and the target to scan,
+// which is a directory for scanners working on whole trees such as Helm charts. Overlay
+// scanners, which read every file type, are left to Overlays.
+func DetectFile(p string) (scanner.Scanner, string, error) {
+	for _, s := range scanner.All() {
+		if isOverlay(s) {
+			continue
+		}
+		if t := scanner.Targets(s, p, matching(s, []string{p})); len(t) > 0 {
+			return s, t[0], nil
+		}
+	}
+	return nil, "", fmt.Errorf("cannot determine the file type of %s", p)
+}
+
+// Overlays returns the overlay scanners reading the file p, to run alongside the scanner
+// DetectFile picks for it.
+func Overlays(p string) []scanner.Scanner {
+	var out []scanner.Scanner
+	for _, s := range scanner.All() {
+		if isOverlay(s) && s.FileMatcher()(p) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func isOverlay(s scanner.Scanner) bool {
+	o, ok := s.(scanner.Overlay)
+	return ok && o.Overlay()
+}
+
+// FilesRead counts the files the named scanners read: the files under root, or files when
+// it is not nil (a scan limited to changed files), that any of their FileMatchers selects.
+// Unlike detection it looks inside .terraform, which scanners given a tree walk too.
+func FilesRead(root string, files []string, tools ...string) (int, error) {
+	if files == nil {
+		err := scanner.Walk(root, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				if p != root && info.Name() == ".git" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			files = append(files, p)
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	var matchers []scanner.FileMatcher
+	for _, t := range tools {
+		if s, ok := scanner.Lookup(t); ok {
+			matchers = append(matchers, s.FileMatcher())
+		}
+	}
+	count := 0
+	for _, p := range files {
+		for _, match := range matchers {
+			if match(p) {
+				count++
+				break
+			}
+		}
+	}
+	return count, nil
+}
+
+// listFiles returns the files under root, skipping .git and .terraform directories.
+func listFiles(root string) ([]string, error) {
+	var files []string
+	err := scanner.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if p != root && (info.Name() == ".git" || info.Name() == ".terraform") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		files = append(files, p)
+		return nil
+	})
+	return files, err
+}
+
+// ChangedTargets returns what s should scan when only changed files under root need
+// checking: the targets it picks that are or contain a changed file it reads, such as a
+// chart whose values changed, or, for scanners working on whole trees, the directories
+// holding the changed files, or root itself when they touch links between directories.
+func ChangedTargets(s scanner.Scanner, root string, changed []string) []string {
+	files := matching(s, changed)
+	if len(files) == 0 {
+		return nil
+	}
+	if d, ok := s.(scanner.TargetDetector); ok {
+		all, err := listFiles(root)
+		if err != nil {
+			return d.Targets(root, files)
+		}
+		var targets []string
+		for _, t := range d.Targets(root, matching(s, all)) {
+			for _, p := range files {
+				if p == t || strings.HasPrefix(p, t+string(filepath.Separator)) {
+					targets = append(targets, t)
+					break
+				}
+			}
+		}
+		return targets
+	}
+	if info, err := os.Stat(root); err == nil && !info.IsDir() {
+		return []string{root}
+	}
+	if l, ok := s.(scanner.Linker); ok && l.Linked(root, files) {
+		return []string{root}
+	}
+	// scanners walk the directory they are given, so a directory below another one is covered
+	var dirs []string
+	for _, p := range files {
+		dirs = append(dirs, filepath.Dir(p))
+	}
+	sort.Strings(dirs)
+	var out []string
+	for _, dir := range dirs {
+		if n := len(out); n > 0 && (dir == out[n-1] || strings.HasPrefix(dir, out[n-1]+string(filepath.Separator))) {
+			continue
+		}
+		out = append(out, dir)
+	}
+	return out
+}
+
+// ScopedToFiles reports whether a changed-files scan with s keeps only the findings in
+// changed files. Scanners picking their own targets scan whole changed targets, such as a
+// chart whose values changed or a playbook with its roles; the others scan the directories
+// of the changed files, which also hold files that did not change.
+func ScopedToFiles(s scanner.Scanner) bool {
+	_, picksTargets := s.(scanner.TargetDetector)
+	return !picksTargets
+}
+
+// OnlyFiles keeps the findings reported on one of files.
+func OnlyFiles(findings []finding.Finding, files []string) []finding.Finding {
+	keep := make(map[string]bool, len(files))
+	for _, p := range files {
+		keep[filepath.Clean(p)] = true
+	}
+	var out []finding.Finding
+	for _, f := range findings {
+		if keep[filepath.Clean(f.File)] {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func matching(s scanner.Scanner, files []string) []string {
+	match := s.FileMatcher()
+	var out []string
+	for _, p := range files {
+		if match(p) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -0,0 +1,46 @@
+// scanner orchestration and the post-processing applied to every scanner's findings before reporting
+package engine
+
+import (
+	"github.com/salchaD-27/infra-check/internal/config"
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/registry"
+)
+
+// Process applies the configured post-processing steps to findings: dropping disabled rules,
+// severity overrides, environment classification and production severity escalation.
+func Process(findings []finding.Finding, cfg *config.Config) []finding.Finding {
+	out := make([]finding.Finding, 0, len(findings))
+	for _, f := range findings {
+		if f, ok := process(f, cfg); ok {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// process is Process for one finding; false drops it.
+func process(f finding.Finding, cfg *config.Config) (finding.Finding, bool) {
+	if !cfg.Enabled(registry.ID(f)) {
+		return f, false
+	}
+	if sev, ok := cfg.SeverityFor(f.Rule(), registry.ID(f)); ok {
+		f.Severity = sev
+	}
+	f.Environment = cfg.Environment(f.File)
+	if cfg.IsProduction(f.Environment) {
+		f.Severity = escalate(f.Severity)
+	}
+	return f, true
+}
+
+// escalate raises a severity by one level: INFO -> WARN -> ERROR.
+func escalate(s finding.Severity) finding.Severity {
+	switch s {
+	case finding.Info:
+		return finding.Warning
+	case finding.Warning:
+		return finding.Error
+	}
+	return s
+}
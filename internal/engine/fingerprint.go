@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/registry"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// Fingerprint sets the Fingerprint of every finding: a hash of its rule (see registry.Key),
+// its file's slash-separated path and the whitespace-normalized source line it points at, so
+// a finding keeps its fingerprint across commits that only move it to another line. Relative
+// paths are read from under root, or from the working directory when root is empty; absolute
+// paths are hashed relative to the working directory. Findings that would share a fingerprint,
+// such as a rule firing on two identical lines, get ":2", ":3" and so on in report order.
+func Fingerprint(findings []finding.Finding, root string) []finding.Finding {
+	fp := newFingerprinter(root, make(sources))
+	out := make([]finding.Finding, len(findings))
+	for i, f := range findings {
+		out[i] = fp.set(f)
+	}
+	return out
+}
+
+// fingerprinter sets fingerprints as Fingerprint does, one finding at a time.
+type fingerprinter struct {
+	wd, root string
+	src      sources
+	seen     map[string]int
+}
+
+func newFingerprinter(root string, src sources) *fingerprinter {
+	wd, _ := os.Getwd()
+	return &fingerprinter{wd: wd, root: root, src: src, seen: make(map[string]int)}
+}
+
+func (fp *fingerprinter) set(f finding.Finding) finding.Finding {
+	path := f.File
+	if filepath.IsAbs(path) && fp.wd != "" {
+		if rel, err := filepath.Rel(fp.wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+	}
+
+	lines := fp.src.lines(f.File, fp.root)
+	snippet := ""
+	if f.Line > 0 && f.Line <= len(lines) {
+		snippet = strings.Join(strings.Fields(lines[f.Line-1]), " ")
+	}
+
+	sum := sha256.Sum256([]byte(registry.Key(f) + "\x00" + filepath.ToSlash(filepath.Clean(path)) + "\x00" + snippet))
+	id := hex.EncodeToString(sum[:16])
+	if fp.seen[id]++; fp.seen[id] > 1 {
+		id = fmt.Sprintf("%s:%d", id, fp.seen[id])
+	}
+	f.Fingerprint = id
+	return f
+}
+
+// sources caches the lines of the files findings point at, nil for those that cannot be read.
+type sources map[string][]string
+
+// lines returns the lines of file, read from under root when it is relative and root is given.
+func (s sources) lines(file, root string) []string {
+	if !filepath.IsAbs(file) && root != "" {
+		file = filepath.Join(root, file)
+	}
+	lines, ok := s[file]
+	if !ok {
+		if data, err := scanner.ReadFile(file); err == nil {
+			lines = strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+		}
+		s[file] = lines
+	}
+	return lines
+}
@@ -0,0 +1,67 @@
+package engine
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/config"
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/registry"
+)
+
+// Collapse replaces a rule firing at least its noise threshold times in one directory with a
+// single aggregated finding listing the count and locations, placed where the first
+// occurrence was. It is a no-op unless noise reduction is enabled in cfg. Run it after Process
+// so findings of different severities (e.g. escalated production ones) stay apart.
+func Collapse(findings []finding.Finding, cfg *config.Config) []finding.Finding {
+	if cfg == nil || !cfg.NoiseReduction.Enabled {
+		return findings
+	}
+
+	type key struct {
+		scanner, rule, dir string
+		severity           finding.Severity
+	}
+	keyOf := func(f finding.Finding) key {
+		return key{f.Scanner, f.Rule(), filepath.Dir(f.File), f.Severity}
+	}
+	groups := make(map[key][]finding.Finding)
+	for _, f := range findings {
+		k := keyOf(f)
+		groups[k] = append(groups[k], f)
+	}
+
+	var out []finding.Finding
+	emitted := make(map[key]bool)
+	for _, f := range findings {
+		k := keyOf(f)
+		group := groups[k]
+		threshold := cfg.NoiseThreshold(k.rule, registry.ID(f))
+		if threshold == 0 || len(group) < threshold {
+			out = append(out, f)
+			continue
+		}
+		if emitted[k] {
+			continue
+		}
+		emitted[k] = true
+		out = append(out, aggregate(k.dir, group, cfg.NoiseMaxListed()))
+	}
+	return out
+}
+
+// aggregate builds the finding standing in for a collapsed group.
+func aggregate(dir string, group []finding.Finding, maxListed int) finding.Finding {
+	var locations []string
+	for i, f := range group {
+		if i == maxListed {
+			locations = append(locations, fmt.Sprintf("and %d more", len(group)-maxListed))
+			break
+		}
+		locations = append(locations, f.Location())
+	}
+	agg := group[0]
+	agg.Message = fmt.Sprintf("%s (%d occurrences in %s: %s)", agg.Message, len(group), dir, strings.Join(locations, ", "))
+	return agg
+}
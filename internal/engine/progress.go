@@ -0,0 +1,20 @@
+package engine
+
+// Progress is told what RunAll and RunChanged are scanning, for progress output on long scans.
+// Scanning and Scanned are called from the goroutines of the concurrently running tools.
+type Progress interface {
+	// Planned is called once, before any target is scanned, with the targets of every tool.
+	Planned(targets map[string][]string)
+	// Scanning is called as a tool starts on one of its targets.
+	Scanning(tool, target string)
+	// Scanned is called when a tool is done with a target, whether or not it failed.
+	Scanned(tool, target string)
+}
+
+// progress is set by the CLI around a scan; library and server scans leave it nil.
+var progress Progress
+
+// SetProgress makes the following scans report to p, or to nothing when p is nil.
+func SetProgress(p Progress) {
+	progress = p
+}
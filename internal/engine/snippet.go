@@ -0,0 +1,43 @@
+package engine
+
+import (
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// maxSnippetLine caps the characters kept of each snippet line, so minified or generated
+// files do not flood the report.
+const maxSnippetLine = 200
+
+// Snippets sets the Snippet of every finding with a line to the source from context lines
+// before to context lines after it, reading files as Fingerprint does. Findings without a
+// line, or whose file cannot be read or is shorter than their line, get none; a negative
+// context leaves every finding without one.
+func Snippets(findings []finding.Finding, root string, context int) []finding.Finding {
+	if context < 0 {
+		return findings
+	}
+	src := make(sources)
+	out := make([]finding.Finding, len(findings))
+	for i, f := range findings {
+		f.Snippet = snippet(f, src.lines(f.File, root), context)
+		out[i] = f
+	}
+	return out
+}
+
+// snippet returns the excerpt of lines, the lines of f's file, around f, or nil.
+func snippet(f finding.Finding, lines []string, context int) *finding.Snippet {
+	if f.Line <= 0 || f.Line > len(lines) {
+		return nil
+	}
+	start := max(f.Line-context, 1)
+	end := min(f.Line+context, len(lines))
+	excerpt := make([]string, 0, end-start+1)
+	for _, l := range lines[start-1 : end] {
+		if r := []rune(l); len(r) > maxSnippetLine {
+			l = string(r[:maxSnippetLine]) + "…"
+		}
+		excerpt = append(excerpt, l)
+	}
+	return &finding.Snippet{StartLine: start, Lines: excerpt}
+}
@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"github.com/salchaD-27/infra-check/internal/config"
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// maxStreamedSources caps the files whose lines a Pipeline keeps. Scanners emit the findings
+// of a file together, so only the files read last are worth keeping.
+const maxStreamedSources = 16
+
+// Pipeline applies the post-processing of a report to findings one at a time, as a streamed
+// scan emits them: Suppress, Dedupe and Process, then fingerprints and snippets for the
+// findings kept. It holds the keys of the findings it passed on, not the findings.
+//
+// A finding passed on cannot be changed any more, so where Dedupe raises the severity of the
+// first occurrence, a Pipeline passes on a duplicate that is more severe than those before it.
+type Pipeline struct {
+	cfg     *config.Config
+	dedupe  bool
+	context int
+
+	suppressed suppressor
+	seen       map[dedupeKey]finding.Severity
+	src        sources
+	fp         *fingerprinter
+}
+
+// NewPipeline returns a Pipeline applying cfg, deduplicating unless dedupe is false, and
+// adding snippets of context lines as Snippets does.
+func NewPipeline(cfg *config.Config, dedupe bool, context int) *Pipeline {
+	src := make(sources)
+	return &Pipeline{
+		cfg:        cfg,
+		dedupe:     dedupe,
+		context:    context,
+		suppressed: make(suppressor),
+		seen:       make(map[dedupeKey]finding.Severity),
+		src:        src,
+		fp:         newFingerprinter("", src),
+	}
+}
+
+// Process suppresses f and applies the configuration to it, the steps whose result the
+// per-tool summary counts; false drops it.
+func (p *Pipeline) Process(f finding.Finding) (finding.Finding, bool) {
+	if p.suppressed.silenced(f) {
+		return f, false
+	}
+	return process(f, p.cfg)
+}
+
+// Report drops f when it repeats an earlier finding, or sets its fingerprint and snippet to
+// report it. keep, when not nil, filters the findings first, as --min-severity does.
+func (p *Pipeline) Report(f finding.Finding, keep func(finding.Finding) bool) (finding.Finding, bool) {
+	if p.dedupe && f.Line > 0 { // as Dedupe, findings without a line are all kept
+		k := keyOf(f)
+		if sev, ok := p.seen[k]; ok && severityRank[f.Severity] <= severityRank[sev] {
+			return f, false
+		}
+		p.seen[k] = f.Severity
+	}
+	if keep != nil && !keep(f) {
+		return f, false
+	}
+	if len(p.src) >= maxStreamedSources {
+		clear(p.src)
+	}
+	f = p.fp.set(f)
+	if p.context >= 0 {
+		f.Snippet = snippet(f, p.src.lines(f.File, ""), p.context)
+	}
+	return f, true
+}
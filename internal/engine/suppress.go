@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/registry"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// SuppressMarker starts an inline suppression comment:
+//
+//	# infra-check:ignore                     every finding on the next line
+//	acl = "public-read" # infra-check:ignore  every finding on this line
+//	# infra-check:ignore [TAG-001],[SEC-002] -- reviewed with security, see TICKET-42
+//
+// IDs restrict the comment to the findings of those rules: custom rules, credential patterns
+// or built-in checks by their registry ID. Text after "--" is a free-form reason. The marker may follow #, // or /*.
+const SuppressMarker = "infra-check:ignore"
+
+var suppressRegex = regexp.MustCompile(`(#|//|/\*)\s*` + regexp.QuoteMeta(SuppressMarker) + `(.*)$`)
+
+// suppression is one comment: the rule IDs it is limited to, none for all.
+type suppression struct{ ids map[string]bool }
+
+func (s suppression) covers(f finding.Finding) bool {
+	return len(s.ids) == 0 || s.ids[registry.ID(f)]
+}
+
+// Suppress drops the findings silenced by an inline suppression comment (see SuppressMarker)
+// on their line or on a comment line right above it. Findings without a line cannot be
+// suppressed inline.
+func Suppress(findings []finding.Finding) []finding.Finding {
+	s := make(suppressor)
+	var out []finding.Finding
+	for _, f := range findings {
+		if !s.silenced(f) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// suppressor caches the suppression comments of the files findings point at.
+type suppressor map[string]map[int][]suppression
+
+// silenced reports whether a comment suppresses f.
+func (s suppressor) silenced(f finding.Finding) bool {
+	if f.Line == 0 {
+		return false
+	}
+	lines, ok := s[f.File]
+	if !ok {
+		lines = suppressions(f.File)
+		s[f.File] = lines
+	}
+	for _, c := range lines[f.Line] {
+		if c.covers(f) {
+			return true
+		}
+	}
+	return false
+}
+
+// suppressions maps 1-based line numbers of file to the comments silencing findings on them.
+func suppressions(file string) map[int][]suppression {
+	data, err := scanner.ReadFile(file)
+	if err != nil || !strings.Contains(string(data), SuppressMarker) {
+		return nil
+	}
+	out := make(map[int][]suppression)
+	for i, line := range strings.Split(string(data), "\n") {
+		m := suppressRegex.FindStringSubmatchIndex(line)
+		if m == nil {
+			continue
+		}
+		rest := line[m[4]:m[5]]
+		rest, _, _ = strings.Cut(rest, "--")
+		rest = strings.TrimSuffix(strings.TrimSpace(rest), "*/")
+		s := suppression{}
+		for _, id := range strings.FieldsFunc(rest, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+			if s.ids == nil {
+				s.ids = make(map[string]bool)
+			}
+			s.ids[strings.Trim(id, "[]")] = true
+		}
+		out[i+1] = append(out[i+1], s)
+		// a comment on a line of its own covers the line below
+		if strings.TrimSpace(line[:m[0]]) == "" {
+			out[i+2] = append(out[i+2], s)
+		}
+	}
+	return out
+}
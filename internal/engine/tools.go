@@ -0,0 +1,215 @@
+package engine
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/salchaD-27/infra-check/internal/config"
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/report"
+	"github.com/salchaD-27/infra-check/internal/rules"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+	_ "github.com/salchaD-27/infra-check/internal/scanners" // register the built-in scanners
+)
+
+// ToolResult is the outcome of one tool in a merged scan.
+type ToolResult struct {
+	Tool     string
+	Targets  []string
+	Findings []finding.Finding
+	ByTarget map[string][]finding.Finding // the findings of each target, as in Findings
+	Err      error
+	Duration time.Duration
+}
+
+// RunAll detects the IaC content under root and runs every applicable scanner concurrently,
+// together with the custom rules targeting it. Results are in registry order and their findings
+// are tagged with the tool that produced them. A failing tool is reported in its result and
+// does not stop the others.
+func RunAll(root string, custom []rules.Rule) ([]ToolResult, error) {
+	detected, err := Detect(root)
+	if err != nil {
+		return nil, err
+	}
+	return RunTargets(detected, custom), nil
+}
+
+// RunChanged is RunAll limited to the targets holding changed, the files under root that
+// changed (see gitdiff.ChangedFiles). Findings are narrowed to changed files as in ScopedToFiles.
+func RunChanged(root string, changed []string, custom []rules.Rule) []ToolResult {
+	results := RunTargets(changedTargets(root, changed), custom)
+	for i := range results {
+		if s, _ := scanner.Lookup(results[i].Tool); ScopedToFiles(s) {
+			results[i].Findings = OnlyFiles(results[i].Findings, changed)
+			for t, found := range results[i].ByTarget {
+				results[i].ByTarget[t] = OnlyFiles(found, changed)
+			}
+		}
+	}
+	return results
+}
+
+// StreamChanged is RunChanged streaming the findings as StreamAll does.
+func StreamChanged(root string, changed []string, custom []rules.Rule, emit scanner.Emit) []ToolResult {
+	keep := make(map[string]bool, len(changed))
+	for _, p := range changed {
+		keep[filepath.Clean(p)] = true
+	}
+	return StreamTargets(changedTargets(root, changed), custom, func(f finding.Finding) {
+		if s, _ := scanner.Lookup(f.Scanner); ScopedToFiles(s) && !keep[filepath.Clean(f.File)] {
+			return
+		}
+		emit(f)
+	})
+}
+
+// changedTargets maps every scanner to its ChangedTargets.
+func changedTargets(root string, changed []string) map[string][]string {
+	detected := make(map[string][]string)
+	for _, s := range scanner.All() {
+		if t := ChangedTargets(s, root, changed); len(t) > 0 {
+			detected[s.Name()] = t
+		}
+	}
+	return detected
+}
+
+// RunTargets runs each scanner on the targets detected maps it to concurrently, as RunAll
+// runs them on the targets it detects.
+func RunTargets(detected map[string][]string, custom []rules.Rule) []ToolResult {
+	results := scanTargets(detected, custom, func(r *ToolResult, target string, f finding.Finding) {
+		r.ByTarget[target] = append(r.ByTarget[target], f)
+	})
+	for i := range results {
+		r := &results[i]
+		for _, target := range r.Targets {
+			r.Findings = append(r.Findings, r.ByTarget[target]...)
+		}
+	}
+	return results
+}
+
+// StreamAll is RunAll passing every finding to emit as soon as its scanner finds it instead
+// of keeping them, so the results hold none. emit is called by one scanner at a time.
+func StreamAll(root string, custom []rules.Rule, emit scanner.Emit) ([]ToolResult, error) {
+	detected, err := Detect(root)
+	if err != nil {
+		return nil, err
+	}
+	return StreamTargets(detected, custom, emit), nil
+}
+
+// StreamTargets is RunTargets streaming the findings as StreamAll does.
+func StreamTargets(detected map[string][]string, custom []rules.Rule, emit scanner.Emit) []ToolResult {
+	var mu sync.Mutex
+	return scanTargets(detected, custom, func(_ *ToolResult, _ string, f finding.Finding) {
+		mu.Lock()
+		defer mu.Unlock()
+		emit(f)
+	})
+}
+
+// scanTargets runs each scanner on its targets concurrently, calling emit from the goroutine
+// of the scanner with every finding, tagged with its tool, as the scanner emits it. A failing
+// target stops its scanner; the findings emitted until then stand.
+func scanTargets(detected map[string][]string, custom []rules.Rule, emit func(r *ToolResult, target string, f finding.Finding)) []ToolResult {
+	var results []ToolResult
+	for _, s := range scanner.All() {
+		if targets, ok := detected[s.Name()]; ok {
+			results = append(results, ToolResult{Tool: s.Name(), Targets: targets, ByTarget: make(map[string][]finding.Finding)})
+		}
+	}
+
+	if progress != nil && len(results) > 0 {
+		progress.Planned(detected)
+	}
+
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(r *ToolResult) {
+			defer wg.Done()
+			start := time.Now()
+			s, _ := scanner.Lookup(r.Tool)
+			for _, target := range r.Targets {
+				if progress != nil {
+					progress.Scanning(r.Tool, target)
+				}
+				tagged := func(f finding.Finding) {
+					f.Scanner = r.Tool
+					emit(r, target, f)
+				}
+				err := scanner.Stream(s, target, tagged)
+				if err == nil {
+					var extra []finding.Finding
+					extra, err = rules.EvaluateAll(custom, r.Tool, target)
+					for _, f := range extra {
+						tagged(f)
+					}
+				}
+				if progress != nil {
+					progress.Scanned(r.Tool, target)
+				}
+				if err != nil {
+					r.Err = fmt.Errorf("%s: %v", target, err)
+					break
+				}
+			}
+			r.Duration = time.Since(start)
+		}(&results[i])
+	}
+	wg.Wait()
+
+	return results
+}
+
+// Merge concatenates the findings of all results.
+func Merge(results []ToolResult) []finding.Finding {
+	var all []finding.Finding
+	for _, r := range results {
+		all = append(all, r.Findings...)
+	}
+	return all
+}
+
+// SummarySection renders per-tool finding counts. Counts are taken after Suppress and
+// Process so they agree with the findings and severities shown in the report.
+func SummarySection(results []ToolResult, cfg *config.Config) report.Section {
+	counts := make(map[string]map[finding.Severity]int)
+	for _, r := range results {
+		counts[r.Tool] = make(map[finding.Severity]int)
+		for _, f := range Process(Suppress(r.Findings), cfg) {
+			counts[r.Tool][f.Severity]++
+		}
+	}
+	return CountedSummarySection(results, counts)
+}
+
+// CountedSummarySection is SummarySection given the counts by severity of each tool's
+// findings, as a streamed scan keeps them instead of the findings (see Pipeline.Process).
+func CountedSummarySection(results []ToolResult, counts map[string]map[finding.Severity]int) report.Section {
+	s := report.Section{
+		Title:   "Summary by Tool",
+		Columns: []string{"Tool", "Targets", "Findings", "ERROR", "WARN", "INFO", "Duration", "Status"},
+	}
+	for _, r := range results {
+		c := counts[r.Tool]
+		status := "ok"
+		if r.Err != nil {
+			status = "failed: " + r.Err.Error()
+		}
+		s.Rows = append(s.Rows, []string{
+			r.Tool,
+			fmt.Sprint(len(r.Targets)),
+			fmt.Sprint(c[finding.Error] + c[finding.Warning] + c[finding.Info]),
+			fmt.Sprint(c[finding.Error]),
+			fmt.Sprint(c[finding.Warning]),
+			fmt.Sprint(c[finding.Info]),
+			r.Duration.Round(time.Microsecond).String(),
+			status,
+		})
+	}
+	return s
+}
@@ -0,0 +1,27 @@
+// Unix file mode strings, as configuration management tools take them
+package filemode
+
+import (
+	"strconv"
+	"strings"
+)
+
+// WorldWritable reports whether an octal (0777) or symbolic (o+w, a=rwx, u=rw,o=rw) mode
+// lets any user write. Modes with the sticky bit, such as 1777 for a shared /tmp, are meant
+// for it and are not reported.
+func WorldWritable(mode string) bool {
+	if n, err := strconv.ParseUint(mode, 8, 32); err == nil {
+		return n&0o002 != 0 && n&0o1000 == 0
+	}
+	for _, clause := range strings.Split(mode, ",") {
+		i := strings.IndexAny(clause, "+=")
+		if i < 0 || strings.Contains(clause[i+1:], "t") {
+			continue
+		}
+		who := clause[:i]
+		if (who == "" || strings.ContainsAny(who, "oa")) && strings.Contains(clause[i+1:], "w") {
+			return true
+		}
+	}
+	return false
+}
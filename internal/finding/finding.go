@@ -1,5 +1,10 @@
 package finding
 
+import (
+	"fmt"
+	"regexp"
+)
+
 type Severity string
 
 const (
@@ -12,4 +17,60 @@ type Finding struct {
 	File     string
 	Severity Severity
 	Message  string
+
+	// Line is the 1-based line the finding points at, or 0 when the scanner does not track positions.
+	Line int `json:"Line,omitempty"`
+
+	// Environment is the environment the file is classified as by the config's path classifiers.
+	Environment string `json:"Environment,omitempty"`
+
+	// Scanner names the tool that produced the finding in merged multi-tool reports.
+	Scanner string `json:"Scanner,omitempty"`
+
+	// Fingerprint identifies the finding across commits independently of its line; see engine.Fingerprint.
+	Fingerprint string `json:"Fingerprint,omitempty"`
+
+	// Snippet is the source around Line, set by engine.Snippets for reports.
+	Snippet *Snippet `json:"Snippet,omitempty"`
+
+	// Controls are the benchmark and framework controls the finding fails, as in
+	// "CIS AWS 2.1.4" or "PCI-DSS 3.5.1", set when the scan selects a profile or framework.
+	Controls []string `json:"Controls,omitempty"`
+}
+
+// Snippet is an excerpt of the file a finding points at.
+type Snippet struct {
+	// StartLine is the 1-based line number of the first of Lines.
+	StartLine int      `json:"StartLine"`
+	Lines     []string `json:"Lines"`
+}
+
+// Location renders the file and, when known, the line as file:line.
+func (f Finding) Location() string {
+	if f.Line > 0 {
+		return fmt.Sprintf("%s:%d", f.File, f.Line)
+	}
+	return f.File
+}
+
+// Matches the [ID] prefix custom rules and credential patterns put on their messages
+var ruleIDRegex = regexp.MustCompile(`^\[([^\]\s]+)\]`)
+
+// RuleID returns the ID the message of the finding carries as a "[ID]" prefix, as those of
+// custom rules and credential patterns do, or "". The IDs of the other built-in checks are
+// found by matching the message against the registry: see registry.ID.
+func (f Finding) RuleID() string {
+	if m := ruleIDRegex.FindStringSubmatch(f.Message); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// Rule identifies the check that produced the finding by its RuleID, or the message itself.
+// It does not know the IDs of the registry; registry.Key does.
+func (f Finding) Rule() string {
+	if id := f.RuleID(); id != "" {
+		return id
+	}
+	return f.Message
 }
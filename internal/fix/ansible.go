@@ -0,0 +1,131 @@
+package fix
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// fixBecome sets become: true on the tasks the Ansible scanner flags for a missing or false
+// become: the tasks of each play in a playbook, or every task of a task file. Tasks written
+// in flow style, or whose only key ends in a block scalar, are left alone.
+func fixBecome(_ string, src []byte, _ []finding.Finding) []edit {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(src, &doc); err != nil || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.SequenceNode {
+		return nil
+	}
+	items := doc.Content[0].Content
+	tasks := items
+	if isPlaybook(items) {
+		tasks = nil
+		for _, play := range items {
+			if list := mappingValue(play, "tasks"); list != nil && list.Kind == yaml.SequenceNode {
+				tasks = append(tasks, list.Content...)
+			}
+		}
+	}
+
+	offsets := lineOffsets(src)
+	var edits []edit
+	for _, task := range tasks {
+		if task.Kind != yaml.MappingNode || task.Style&yaml.FlowStyle != 0 || len(task.Content) < 2 {
+			continue
+		}
+		if become := mappingValue(task, "become"); become != nil {
+			if become.Kind == yaml.ScalarNode && become.Tag == "!!bool" && strings.EqualFold(become.Value, "false") {
+				at := offsets[become.Line-1] + become.Column - 1
+				edits = append(edits, edit{start: at, end: at + len(become.Value), text: "true", fixes: 1})
+			}
+			continue
+		}
+
+		first := task.Content[0]
+		indent := strings.Repeat(" ", first.Column-1)
+		if len(task.Content) >= 4 {
+			// between the first key's value and the second key
+			at := offsets[task.Content[2].Line-1]
+			edits = append(edits, edit{start: at, end: at, text: indent + "become: true\n", fixes: 1})
+			continue
+		}
+		// after the value of the only key, which may be the module's arguments spanning lines
+		end := lastLine(task.Content[1])
+		if end == 0 {
+			continue
+		}
+		if end >= len(offsets) {
+			// the task is on the last line
+			text := indent + "become: true\n"
+			if len(src) > 0 && src[len(src)-1] != '\n' {
+				text = "\n" + text
+			}
+			edits = append(edits, edit{start: len(src), end: len(src), text: text, fixes: 1})
+			continue
+		}
+		at := offsets[end]
+		edits = append(edits, edit{start: at, end: at, text: indent + "become: true\n", fixes: 1})
+	}
+	return edits
+}
+
+// isPlaybook reports whether the top-level items of a file are plays rather than tasks,
+// including a play missing its hosts that the scanner still checks the tasks of.
+func isPlaybook(items []*yaml.Node) bool {
+	for _, item := range items {
+		for _, key := range []string{"hosts", "tasks", "import_playbook", "ansible.builtin.import_playbook"} {
+			if mappingValue(item, key) != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lastLine returns the line a value ends on, from its last scalar, or 0 when that cannot be
+// told from the nodes: a block scalar, or a flow collection spanning lines.
+func lastLine(node *yaml.Node) int {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+			return 0
+		}
+		return node.Line
+	case yaml.AliasNode:
+		return node.Line
+	case yaml.MappingNode, yaml.SequenceNode:
+		if len(node.Content) == 0 {
+			return node.Line
+		}
+		end := lastLine(node.Content[len(node.Content)-1])
+		if node.Style&yaml.FlowStyle != 0 && end != node.Line {
+			return 0
+		}
+		return end
+	}
+	return 0
+}
+
+// mappingValue returns the value of key in a mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// lineOffsets returns the offset at which each line of src starts.
+func lineOffsets(src []byte) []int {
+	offsets := []int{0}
+	for i, c := range src {
+		if c == '\n' && i+1 < len(src) {
+			offsets = append(offsets, i+1)
+		}
+	}
+	return offsets
+}
@@ -0,0 +1,31 @@
+package fix
+
+import "testing"
+
+// A task whose only key is its module, with the arguments as a mapping below it, gets
+// become: true after those arguments, beside the module key.
+func TestBecomeAfterModuleArguments(t *testing.T) {
+	before := `- hosts: all
+  tasks:
+    - apt:
+        name: curl
+        state: present
+    - shell: echo "Hello World"
+`
+	want := `- hosts: all
+  tasks:
+    - apt:
+        name: curl
+        state: present
+      become: true
+    - shell: echo "Hello World"
+      become: true
+`
+	after, applied := apply([]byte(before), fixBecome("site.yml", []byte(before), nil))
+	if len(applied) != 2 {
+		t.Errorf("applied %d edits, want 2", len(applied))
+	}
+	if string(after) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", after, want)
+	}
+}
@@ -0,0 +1,171 @@
+package fix
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Lines of context around the changes in a diff
+const diffContext = 3
+
+// hunkCore is one changed run of lines: Before's lines [from, to) replaced by added.
+type hunkCore struct {
+	from, to int
+	added    []string
+}
+
+// Diff renders the change as a unified diff, as `git apply` and `patch -p1` take it, with
+// the file's path relative to the working directory.
+func (c Change) Diff() string {
+	before := splitLines(string(c.Before))
+	cores := c.cores(before)
+	if len(cores) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	name := filepath.ToSlash(filepath.Clean(c.File))
+	if wd, err := os.Getwd(); err == nil && filepath.IsAbs(c.File) {
+		if rel, err := filepath.Rel(wd, c.File); err == nil && !strings.HasPrefix(rel, "..") {
+			name = filepath.ToSlash(rel)
+		}
+	}
+	fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", name, name)
+	delta := 0 // lines added minus removed by the hunks written so far
+	for i := 0; i < len(cores); {
+		j := i + 1
+		for j < len(cores) && cores[j].from-cores[j-1].to <= 2*diffContext {
+			j++
+		}
+		from := max(0, cores[i].from-diffContext)
+		to := min(len(before), cores[j-1].to+diffContext)
+		grown := 0
+		for _, core := range cores[i:j] {
+			grown += len(core.added) - (core.to - core.from)
+		}
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(from, to-from), hunkRange(from+delta, to-from+grown))
+
+		at := from
+		for _, core := range cores[i:j] {
+			for ; at < core.from; at++ {
+				writeLine(&b, ' ', before[at])
+			}
+			for ; at < core.to; at++ {
+				writeLine(&b, '-', before[at])
+			}
+			for _, line := range core.added {
+				writeLine(&b, '+', line)
+			}
+		}
+		for ; at < to; at++ {
+			writeLine(&b, ' ', before[at])
+		}
+		delta += grown
+		i = j
+	}
+	return b.String()
+}
+
+// cores turns the edits into changed runs of lines, merging edits on the same lines.
+func (c Change) cores(before []string) []hunkCore {
+	starts := make([]int, len(before))
+	offset := 0
+	for i, line := range before {
+		starts[i] = offset
+		offset += len(line)
+	}
+	lineOf := func(offset int) int {
+		return sort.Search(len(starts), func(i int) bool { return starts[i] > offset }) - 1
+	}
+	lastLine := func(e edit) int {
+		if e.end > e.start {
+			return lineOf(e.end - 1)
+		}
+		return lineOf(e.start)
+	}
+	lineEnd := func(line int) int {
+		if line+1 < len(starts) {
+			return starts[line+1]
+		}
+		return len(c.Before)
+	}
+
+	var cores []hunkCore
+	for i := 0; i < len(c.edits); {
+		first, last := lineOf(c.edits[i].start), lastLine(c.edits[i])
+		if c.edits[i].start == len(c.Before) && (len(c.Before) == 0 || c.Before[len(c.Before)-1] == '\n') {
+			first, last = len(before), len(before)-1 // appended after the last line
+		}
+		j := i + 1
+		for j < len(c.edits) && lineOf(c.edits[j].start) <= last {
+			last = max(last, lastLine(c.edits[j]))
+			j++
+		}
+
+		start, end := len(c.Before), len(c.Before)
+		if first < len(before) {
+			start, end = starts[first], lineEnd(last)
+		}
+		var after strings.Builder
+		pos := start
+		for _, e := range c.edits[i:j] {
+			after.Write(c.Before[pos:e.start])
+			after.WriteString(e.text)
+			pos = e.end
+		}
+		after.Write(c.Before[pos:end])
+
+		old := before[first : last+1]
+		if first >= len(before) {
+			old = nil
+		}
+		added := splitLines(after.String())
+		// keep only the lines that differ
+		p := 0
+		for p < len(old) && p < len(added) && old[p] == added[p] {
+			p++
+		}
+		s := 0
+		for s < len(old)-p && s < len(added)-p && old[len(old)-1-s] == added[len(added)-1-s] {
+			s++
+		}
+		core := hunkCore{from: first + p, to: first + len(old) - s, added: added[p : len(added)-s]}
+		if n := len(cores); n > 0 && cores[n-1].to == core.from {
+			// adjacent runs read better as one: every removed line, then every added one
+			cores[n-1].to = core.to
+			cores[n-1].added = append(cores[n-1].added, core.added...)
+		} else {
+			cores = append(cores, core)
+		}
+		i = j
+	}
+	return cores
+}
+
+// splitLines splits s into lines that keep their newlines.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// hunkRange renders the start,length of a hunk header from a 0-based start.
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
+
+func writeLine(b *strings.Builder, prefix byte, line string) {
+	b.WriteByte(prefix)
+	b.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		b.WriteString("\n\\ No newline at end of file\n")
+	}
+}
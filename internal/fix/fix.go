@@ -0,0 +1,168 @@
+// Package fix rewrites files to resolve findings that have a safe, mechanical fix: missing
+// Terraform tags, secret-like Terraform variables not marked sensitive, Ansible tasks
+// without become and trailing whitespace in Puppet manifests. Findings in code of any other
+// shape than a fixer expects are left alone.
+package fix
+
+import (
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// Placeholder is the value of the tags the fixer adds, to be replaced by hand.
+const Placeholder = "TODO"
+
+// Change is the rewrite of one file.
+type Change struct {
+	File   string
+	Before []byte
+	After  []byte
+	Fixed  int // findings the rewrite resolves
+
+	edits []edit // as applied to Before, in order
+}
+
+// edit replaces src[start:end] with text, resolving fixes findings.
+type edit struct {
+	start, end int
+	text       string
+	fixes      int
+}
+
+// fixer returns the edits resolving findings, which are all in the file holding src.
+type fixer func(path string, src []byte, findings []finding.Finding) []edit
+
+var (
+	missingTagRegex = regexp.MustCompile(`^Resource missing required tag '([^']+)'$`)
+	sensitiveRegex  = regexp.MustCompile(`^Variable '([^']+)' looks like a secret but is not declared sensitive = true`)
+	trailingRegex   = regexp.MustCompile(`^Trailing whitespace on line \d+$`)
+)
+
+// fixerFor returns the name and fixer of the fix for f, or "" when it has none.
+func fixerFor(f finding.Finding) (string, fixer) {
+	switch {
+	case f.Message == "Resource missing 'tags' attribute entirely":
+		return "tags", fixMissingTags
+	case missingTagRegex.MatchString(f.Message):
+		return "tag", fixRequiredTags
+	case sensitiveRegex.MatchString(f.Message):
+		return "sensitive", fixSensitive
+	case f.Message == "Task missing 'become' field (no privilege escalation specified)",
+		f.Message == "'become' is false in task (possible privilege issue)":
+		return "become", fixBecome
+	case trailingRegex.MatchString(f.Message):
+		return "whitespace", fixTrailingWhitespace
+	}
+	return "", nil
+}
+
+// Plan works out the changes fixing findings without writing them, one per file in path order.
+func Plan(findings []finding.Finding) ([]Change, error) {
+	type group struct {
+		fix      fixer
+		findings []finding.Finding
+	}
+	byFile := make(map[string]map[string]*group)
+	for _, f := range findings {
+		name, fx := fixerFor(f)
+		if fx == nil {
+			continue
+		}
+		if byFile[f.File] == nil {
+			byFile[f.File] = make(map[string]*group)
+		}
+		g := byFile[f.File][name]
+		if g == nil {
+			g = &group{fix: fx}
+			byFile[f.File][name] = g
+		}
+		g.findings = append(g.findings, f)
+	}
+	files := make([]string, 0, len(byFile))
+	for file := range byFile {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	var changes []Change
+	for _, file := range files {
+		src, err := scanner.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(byFile[file]))
+		for name := range byFile[file] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var edits []edit
+		for _, name := range names {
+			g := byFile[file][name]
+			edits = append(edits, g.fix(file, src, g.findings)...)
+		}
+		after, applied := apply(src, edits)
+		fixed := 0
+		for _, e := range applied {
+			fixed += e.fixes
+		}
+		if fixed > 0 {
+			changes = append(changes, Change{File: file, Before: src, After: after, Fixed: fixed, edits: applied})
+		}
+	}
+	return changes, nil
+}
+
+// apply makes the edits to src, dropping any overlapping an earlier one, and returns the
+// result with the edits applied.
+func apply(src []byte, edits []edit) ([]byte, []edit) {
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+	var out []byte
+	var applied []edit
+	pos := 0
+	for _, e := range edits {
+		if e.start < pos {
+			continue
+		}
+		out = append(out, src[pos:e.start]...)
+		out = append(out, e.text...)
+		pos = e.end
+		applied = append(applied, e)
+	}
+	return append(out, src[pos:]...), applied
+}
+
+// Write saves the changes, keeping the files' permissions.
+func Write(changes []Change) error {
+	for _, c := range changes {
+		info, err := os.Stat(c.File)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(c.File, c.After, info.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lineStart returns the offset of the start of the line holding offset.
+func lineStart(src []byte, offset int) int {
+	for offset > 0 && src[offset-1] != '\n' {
+		offset--
+	}
+	return offset
+}
+
+// indentAt returns the leading whitespace of the line holding offset.
+func indentAt(src []byte, offset int) string {
+	start := lineStart(src, offset)
+	end := start
+	for end < len(src) && (src[end] == ' ' || src[end] == '\t') {
+		end++
+	}
+	return string(src[start:end])
+}
@@ -0,0 +1,31 @@
+package fix
+
+import (
+	"bytes"
+	"unicode"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// fixTrailingWhitespace strips the whitespace the Puppet scanner flags from the ends of lines.
+func fixTrailingWhitespace(_ string, src []byte, findings []finding.Finding) []edit {
+	lines := make(map[int]bool)
+	for _, f := range findings {
+		lines[f.Line] = true
+	}
+	var edits []edit
+	start := 0
+	for n := 1; start <= len(src); n++ {
+		end := len(src)
+		if i := bytes.IndexByte(src[start:], '\n'); i >= 0 {
+			end = start + i
+		}
+		if lines[n] {
+			if trimmed := len(bytes.TrimRightFunc(src[start:end], unicode.IsSpace)); start+trimmed < end {
+				edits = append(edits, edit{start: start + trimmed, end: end, fixes: 1})
+			}
+		}
+		start = end + 1
+	}
+	return edits
+}
@@ -0,0 +1,265 @@
+package fix
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/terraform"
+)
+
+// Resource types known to take a tags map. Tags are added to no other resource, since an
+// unsupported tags argument fails terraform validate: many types of these providers, such
+// as security group rules, policies and attachments, take none.
+var taggedTypes = toSet(
+	// AWS
+	"aws_instance", "aws_launch_template", "aws_ebs_volume", "aws_ebs_snapshot", "aws_ami", "aws_eip", "aws_key_pair",
+	"aws_vpc", "aws_subnet", "aws_security_group", "aws_internet_gateway", "aws_nat_gateway", "aws_route_table",
+	"aws_network_acl", "aws_network_interface", "aws_vpc_endpoint", "aws_vpn_gateway", "aws_customer_gateway",
+	"aws_ec2_transit_gateway", "aws_lb", "aws_alb", "aws_lb_target_group", "aws_elb",
+	"aws_s3_bucket", "aws_db_instance", "aws_rds_cluster", "aws_db_subnet_group", "aws_dynamodb_table",
+	"aws_elasticache_cluster", "aws_elasticache_replication_group", "aws_efs_file_system", "aws_redshift_cluster",
+	"aws_elasticsearch_domain", "aws_opensearch_domain", "aws_kinesis_stream",
+	"aws_iam_role", "aws_iam_user", "aws_iam_policy", "aws_kms_key", "aws_secretsmanager_secret", "aws_ssm_parameter",
+	"aws_acm_certificate", "aws_lambda_function", "aws_sqs_queue", "aws_sns_topic", "aws_sfn_state_machine",
+	"aws_cloudwatch_log_group", "aws_cloudtrail", "aws_ecr_repository", "aws_ecs_cluster", "aws_ecs_service",
+	"aws_ecs_task_definition", "aws_eks_cluster", "aws_eks_node_group", "aws_cloudfront_distribution",
+	"aws_route53_zone", "aws_api_gateway_rest_api", "aws_wafv2_web_acl", "aws_backup_vault", "aws_codebuild_project",
+	"aws_glue_job",
+	// Azure
+	"azurerm_resource_group", "azurerm_virtual_network", "azurerm_network_security_group", "azurerm_network_interface",
+	"azurerm_public_ip", "azurerm_lb", "azurerm_application_gateway", "azurerm_firewall", "azurerm_dns_zone",
+	"azurerm_linux_virtual_machine", "azurerm_windows_virtual_machine", "azurerm_virtual_machine", "azurerm_managed_disk",
+	"azurerm_storage_account", "azurerm_key_vault", "azurerm_mssql_server", "azurerm_sql_server", "azurerm_mssql_database",
+	"azurerm_postgresql_server", "azurerm_mysql_server", "azurerm_cosmosdb_account", "azurerm_redis_cache",
+	"azurerm_kubernetes_cluster", "azurerm_container_registry", "azurerm_service_plan", "azurerm_app_service_plan",
+	"azurerm_linux_web_app", "azurerm_windows_web_app", "azurerm_app_service", "azurerm_function_app",
+	"azurerm_eventhub_namespace", "azurerm_servicebus_namespace", "azurerm_log_analytics_workspace",
+	"azurerm_user_assigned_identity",
+)
+
+// Matches an attribute line up to its "=", to align added attributes like terraform fmt
+var attributeLineRegex = regexp.MustCompile(`^(\s*)("[^"]*"|[\w-]+)(\s*)=`)
+
+// parseHCL parses a .tf file, or returns nil for other and invalid files.
+func parseHCL(path string, src []byte) *hclsyntax.Body {
+	if !strings.HasSuffix(path, ".tf") {
+		return nil
+	}
+	file, diags := hclsyntax.ParseConfig(src, path, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil
+	}
+	body, _ := file.Body.(*hclsyntax.Body)
+	return body
+}
+
+// taggable reports whether resources of type accept tags, as far as the fixer knows.
+func taggable(resourceType string) bool {
+	return taggedTypes[resourceType]
+}
+
+// fixMissingTags adds a tags map with every required tag set to Placeholder to resources
+// of a taggedTypes type that have none.
+func fixMissingTags(path string, src []byte, findings []finding.Finding) []edit {
+	body := parseHCL(path, src)
+	if body == nil {
+		return nil
+	}
+	lines := make(map[int]bool)
+	for _, f := range findings {
+		lines[f.Line] = true
+	}
+	tags := terraform.RequiredTags()
+	width := 0
+	for _, t := range tags {
+		width = max(width, len(t))
+	}
+
+	var edits []edit
+	for _, b := range body.Blocks {
+		if b.Type != "resource" || len(b.Labels) != 2 || !lines[b.TypeRange.Start.Line] || !taggable(b.Labels[0]) {
+			continue
+		}
+		if _, ok := b.Body.Attributes["tags"]; ok {
+			continue
+		}
+		indent := bodyIndent(src, b)
+		var text strings.Builder
+		// terraform fmt does not align the opening line of a multi-line value
+		text.WriteString(indent + "tags = {\n")
+		for _, t := range tags {
+			fmt.Fprintf(&text, "%s  %-*s = %q\n", indent, width, t, Placeholder)
+		}
+		text.WriteString(indent + "}\n")
+		if e, ok := appendToBlock(src, b, text.String()); ok {
+			e.fixes = 1
+			edits = append(edits, e)
+		}
+	}
+	return edits
+}
+
+// fixRequiredTags adds the missing required tags, set to Placeholder, to tags written as
+// an object literal. Tags built by expressions such as merge() are left alone.
+func fixRequiredTags(path string, src []byte, findings []finding.Finding) []edit {
+	body := parseHCL(path, src)
+	if body == nil {
+		return nil
+	}
+	missing := make(map[int][]string)
+	for _, f := range findings {
+		if m := missingTagRegex.FindStringSubmatch(f.Message); m != nil {
+			missing[f.Line] = append(missing[f.Line], m[1])
+		}
+	}
+
+	var edits []edit
+	for _, b := range body.Blocks {
+		if b.Type != "resource" {
+			continue
+		}
+		attr, ok := b.Body.Attributes["tags"]
+		if !ok || len(missing[attr.SrcRange.Start.Line]) == 0 {
+			continue
+		}
+		obj, ok := attr.Expr.(*hclsyntax.ObjectConsExpr)
+		if !ok {
+			continue
+		}
+		names := missing[attr.SrcRange.Start.Line]
+		closing := obj.SrcRange.End.Byte - 1
+
+		if obj.SrcRange.Start.Line == obj.SrcRange.End.Line {
+			items := make([]string, len(names))
+			for i, n := range names {
+				items[i] = fmt.Sprintf("%s = %q", n, Placeholder)
+			}
+			if len(obj.Items) > 0 {
+				at := obj.Items[len(obj.Items)-1].ValueExpr.Range().End.Byte
+				edits = append(edits, edit{start: at, end: at, text: ", " + strings.Join(items, ", "), fixes: len(names)})
+			} else {
+				edits = append(edits, edit{start: obj.SrcRange.Start.Byte + 1, end: closing, text: " " + strings.Join(items, ", ") + " ", fixes: len(names)})
+			}
+			continue
+		}
+
+		start := lineStart(src, closing)
+		if strings.TrimSpace(string(src[start:closing])) != "" {
+			continue
+		}
+		indent := indentAt(src, attr.SrcRange.Start.Byte) + "  "
+		if len(obj.Items) > 0 {
+			indent = indentAt(src, obj.Items[0].KeyExpr.Range().Start.Byte)
+		}
+		eq := equalsColumn(src, start, indent)
+		var text strings.Builder
+		for _, n := range names {
+			key := indent + n
+			if pad := eq - len(key); pad > 1 {
+				key += strings.Repeat(" ", pad-1)
+			}
+			fmt.Fprintf(&text, "%s = %q\n", key, Placeholder)
+		}
+		edits = append(edits, edit{start: start, end: start, text: text.String(), fixes: len(names)})
+	}
+	return edits
+}
+
+// fixSensitive sets sensitive = true on secret-like variables.
+func fixSensitive(path string, src []byte, findings []finding.Finding) []edit {
+	body := parseHCL(path, src)
+	if body == nil {
+		return nil
+	}
+	flagged := make(map[string]bool)
+	for _, f := range findings {
+		if m := sensitiveRegex.FindStringSubmatch(f.Message); m != nil {
+			flagged[m[1]] = true
+		}
+	}
+
+	var edits []edit
+	for _, b := range body.Blocks {
+		if b.Type != "variable" || len(b.Labels) != 1 || !flagged[b.Labels[0]] {
+			continue
+		}
+		if attr, ok := b.Body.Attributes["sensitive"]; ok {
+			r := attr.Expr.Range()
+			edits = append(edits, edit{start: r.Start.Byte, end: r.End.Byte, text: "true", fixes: 1})
+			continue
+		}
+		text := alignAttribute(src, b, bodyIndent(src, b), "sensitive", "true") + "\n"
+		if e, ok := appendToBlock(src, b, text); ok {
+			e.fixes = 1
+			edits = append(edits, e)
+		}
+	}
+	return edits
+}
+
+// bodyIndent returns the indentation of the attributes of b.
+func bodyIndent(src []byte, b *hclsyntax.Block) string {
+	for _, attr := range b.Body.Attributes {
+		if attr.SrcRange.Start.Line != b.OpenBraceRange.Start.Line {
+			return indentAt(src, attr.SrcRange.Start.Byte)
+		}
+	}
+	return indentAt(src, b.TypeRange.Start.Byte) + "  "
+}
+
+// alignAttribute renders "name = value" at indent, with the "=" in line with the attribute
+// just above b's closing brace as terraform fmt aligns consecutive attributes.
+func alignAttribute(src []byte, b *hclsyntax.Block, indent, name, value string) string {
+	key := indent + name
+	if b.CloseBraceRange.Start.Line != b.OpenBraceRange.Start.Line {
+		eq := equalsColumn(src, lineStart(src, b.CloseBraceRange.Start.Byte), indent)
+		if pad := eq - len(key); pad > 1 {
+			key += strings.Repeat(" ", pad-1)
+		}
+	}
+	return key + " = " + value
+}
+
+// equalsColumn returns the offset of the "=" within the line before the one starting at
+// start when that line is an attribute at indent, or 0.
+func equalsColumn(src []byte, start int, indent string) int {
+	if start == 0 {
+		return 0
+	}
+	prev := lineStart(src, start-1)
+	m := attributeLineRegex.FindSubmatch(src[prev : start-1])
+	if m == nil || string(m[1]) != indent {
+		return 0
+	}
+	return len(m[0]) - 1
+}
+
+// appendToBlock inserts text, whole lines, as the last lines of b's body. A block written
+// on one line is expanded when its body is empty, and left alone otherwise.
+func appendToBlock(src []byte, b *hclsyntax.Block, text string) (edit, bool) {
+	open, closing := b.OpenBraceRange.End.Byte, b.CloseBraceRange.Start.Byte
+	if b.OpenBraceRange.Start.Line == b.CloseBraceRange.Start.Line {
+		if strings.TrimSpace(string(src[open:closing])) != "" {
+			return edit{}, false
+		}
+		return edit{start: open, end: closing, text: "\n" + text + indentAt(src, b.TypeRange.Start.Byte)}, true
+	}
+	start := lineStart(src, closing)
+	if strings.TrimSpace(string(src[start:closing])) != "" {
+		return edit{}, false
+	}
+	return edit{start: start, end: start, text: text}, true
+}
+
+func toSet(items ...string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[item] = true
+	}
+	return set
+}
@@ -0,0 +1,40 @@
+package fix
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// --fix adds tags to the resources whose type takes them only: a tags argument on a
+// security group rule, a bucket policy or a policy attachment fails terraform validate.
+func TestMissingTagsOnlyOnTaggableResources(t *testing.T) {
+	s, ok := scanner.Lookup("terraform")
+	if !ok {
+		t.Fatal("terraform scanner not registered")
+	}
+	findings, err := s.Scan("../../tests/sample-terraform-fix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, err := Plan(findings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("got %d changes, want 1", len(changes))
+	}
+	c := changes[0]
+	if c.Fixed != 1 {
+		t.Errorf("fixed %d findings, want 1", c.Fixed)
+	}
+	after := string(c.After)
+	if n := strings.Count(after, "tags = {"); n != 1 {
+		t.Errorf("added %d tags maps, want 1:\n%s", n, after)
+	}
+	group := after[strings.Index(after, `resource "aws_security_group" "admin"`):strings.Index(after, `resource "aws_security_group_rule"`)]
+	if !strings.Contains(group, "tags = {") {
+		t.Errorf("no tags added to aws_security_group.admin:\n%s", after)
+	}
+}
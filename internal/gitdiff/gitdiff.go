@@ -0,0 +1,156 @@
+// changed-file discovery against a git base ref or the index for PR- and commit-scoped scans
+package gitdiff
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ChangedFiles returns the files under root that differ from base: added, modified and renamed
+// files since the merge base of base and HEAD, including uncommitted and untracked ones.
+// Deleted files are left out as there is nothing to scan. Paths are returned joined to root,
+// the way scanners walking root name them.
+func ChangedFiles(root, base string) ([]string, error) {
+	repo, err := toplevel(root)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := git(repo, "rev-parse", "--verify", "--quiet", base+"^{commit}"); err != nil {
+		return nil, fmt.Errorf("unknown git ref '%s'", base)
+	}
+	// compare against where the branch forked, as a pull request does; fall back to the
+	// ref itself when the histories are unrelated
+	from := base
+	if mb, err := git(repo, "merge-base", base, "HEAD"); err == nil {
+		from = strings.TrimSpace(string(mb))
+	}
+
+	diff, err := git(repo, "diff", "--name-only", "--diff-filter=ACMR", "-z", from)
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := git(repo, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, err
+	}
+	return underRoot(root, repo, append(diff, untracked...))
+}
+
+// Since returns the files under root that differ between commit and the working tree, named
+// as ChangedFiles names them. Unlike ChangedFiles it compares with commit itself, not a merge
+// base: changed holds the added and modified files, untracked ones included, and removed the
+// deleted files and the old names of renamed ones.
+func Since(root, commit string) (changed, removed []string, err error) {
+	repo, err := toplevel(root)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := git(repo, "rev-parse", "--verify", "--quiet", commit+"^{commit}"); err != nil {
+		return nil, nil, fmt.Errorf("unknown git commit '%s'", commit)
+	}
+	diff, err := git(repo, "diff", "--name-only", "--no-renames", "--diff-filter=AMT", "-z", commit)
+	if err != nil {
+		return nil, nil, err
+	}
+	deleted, err := git(repo, "diff", "--name-only", "--no-renames", "--diff-filter=D", "-z", commit)
+	if err != nil {
+		return nil, nil, err
+	}
+	untracked, err := git(repo, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, nil, err
+	}
+	if changed, err = underRoot(root, repo, append(diff, untracked...)); err != nil {
+		return nil, nil, err
+	}
+	removed, err = underRoot(root, repo, deleted)
+	return changed, removed, err
+}
+
+// StagedFiles returns the files under root added, modified or renamed in the git index, the
+// ones the next commit will contain. Their working tree content is what gets scanned, so
+// changes left unstaged are seen too.
+func StagedFiles(root string) ([]string, error) {
+	repo, err := toplevel(root)
+	if err != nil {
+		return nil, err
+	}
+	staged, err := git(repo, "diff", "--cached", "--name-only", "--diff-filter=ACMR", "-z")
+	if err != nil {
+		return nil, err
+	}
+	return underRoot(root, repo, staged)
+}
+
+// toplevel returns the root of the work tree holding root, a directory or file.
+func toplevel(root string) (string, error) {
+	dir := root
+	if info, err := os.Stat(root); err != nil {
+		return "", err
+	} else if !info.IsDir() {
+		dir = filepath.Dir(root)
+	}
+	top, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(top)), nil
+}
+
+// underRoot maps the NUL-separated, repo-relative paths git printed to sorted paths joined
+// to root, dropping those outside it.
+func underRoot(root, repo string, names []byte) ([]string, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	// git prints paths with symlinks resolved; compare like with like
+	if resolved, err := filepath.EvalSymlinks(absRoot); err == nil {
+		absRoot = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(repo); err == nil {
+		repo = resolved
+	}
+
+	seen := make(map[string]bool)
+	var files []string
+	for _, name := range bytes.Split(names, []byte{0}) {
+		if len(name) == 0 {
+			continue
+		}
+		abs := filepath.Join(repo, filepath.FromSlash(string(name)))
+		rel, err := filepath.Rel(absRoot, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue // outside the scanned path
+		}
+		p := filepath.Join(root, rel)
+		if rel == "." {
+			p = root
+		}
+		if !seen[p] {
+			seen[p] = true
+			files = append(files, p)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func git(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %v", args[0], err)
+	}
+	return out, nil
+}
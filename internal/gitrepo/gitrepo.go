@@ -0,0 +1,72 @@
+// validation and shallow cloning of remote git repositories given by URL
+package gitrepo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Split separates a trailing @ref from a repository argument such as
+// https://github.com/org/repo@v1.2.0 or git@github.com:org/repo@feature/x. An @ before
+// the path, as in git@github.com:org/repo, is not a ref.
+func Split(arg string) (repo, ref string) {
+	path := 0
+	if i := strings.Index(arg, "://"); i >= 0 {
+		j := strings.Index(arg[i+3:], "/")
+		if j < 0 {
+			return arg, ""
+		}
+		path = i + 3 + j
+	} else if i := strings.Index(arg, ":"); i >= 0 {
+		path = i + 1
+	}
+	at := strings.LastIndex(arg[path:], "@")
+	if at < 0 {
+		return arg, ""
+	}
+	return arg[:path+at], arg[path+at+1:]
+}
+
+// Check accepts https and ssh URLs, including the scp-like user@host:path form. Other
+// schemes are refused: file:// and local paths would read the host's disk, and ext:: runs
+// commands. Arguments starting with '-' could be taken for git options.
+func Check(repo, ref string) error {
+	if strings.HasPrefix(repo, "-") || strings.HasPrefix(ref, "-") {
+		return fmt.Errorf("the repository URL and ref must not start with '-'")
+	}
+	if u, err := url.Parse(repo); err == nil && (u.Scheme == "https" || u.Scheme == "ssh") && u.Host != "" {
+		return nil
+	}
+	// scp-like ssh syntax, user@host:path
+	if at, colon := strings.Index(repo, "@"), strings.Index(repo, ":"); at > 0 && colon > at+1 && !strings.Contains(repo[:colon], "/") {
+		return nil
+	}
+	return fmt.Errorf("unsupported repository URL '%s'; use an https:// or ssh URL", repo)
+}
+
+// Clone makes a shallow clone of ref, a branch or tag, or of the default branch of repo
+// into dir. Git never prompts for credentials, so a private repository without them fails
+// instead of hanging.
+func Clone(ctx context.Context, repo, ref, dir string) error {
+	args := []string{"clone", "--quiet", "--depth", "1", "--single-branch"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	args = append(args, "--", repo, dir)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("cloning %s: %v", repo, ctx.Err())
+		}
+		return fmt.Errorf("cloning %s: %s", repo, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
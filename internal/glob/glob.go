@@ -0,0 +1,88 @@
+// path globbing with ** support for config-driven path matching
+package glob
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Match reports whether path matches pattern. Patterns use forward slashes:
+//   - '*' matches any run of characters within one path segment
+//   - '?' matches a single character within one path segment
+//   - '**' matches any number of whole segments, including none
+//
+// Paths are cleaned and converted to forward slashes before matching, so
+// "./envs/prod/main.tf" matches "envs/prod/**".
+func Match(pattern, path string) bool {
+	re := compile(pattern)
+	return re != nil && re.MatchString(Normalize(path))
+}
+
+// MatchAny reports whether path matches any of the patterns.
+func MatchAny(patterns []string, path string) bool {
+	for _, p := range patterns {
+		if Match(p, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchDir reports whether pattern matches everything directly below the directory dir,
+// as patterns ending in "/**" or "/*" do, so a walk can skip the directory as a whole.
+func MatchDir(pattern, dir string) bool {
+	re := compile(pattern)
+	return re != nil && re.MatchString(Normalize(dir)+"/")
+}
+
+// Normalize cleans path and converts it to the forward-slash form patterns are written in.
+func Normalize(path string) string {
+	return strings.TrimPrefix(filepath.ToSlash(filepath.Clean(path)), "./")
+}
+
+var (
+	cacheMu sync.Mutex
+	cache   = make(map[string]*regexp.Regexp)
+)
+
+func compile(pattern string) *regexp.Regexp {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if re, ok := cache[pattern]; ok {
+		return re
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	p := strings.TrimPrefix(filepath.ToSlash(pattern), "./")
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		switch {
+		case c == '*' && i+1 < len(p) && p[i+1] == '*':
+			// "**/" matches zero or more leading segments, a trailing "**" everything below.
+			if i+2 < len(p) && p[i+2] == '/' {
+				b.WriteString("(?:.*/)?")
+				i += 2
+			} else {
+				b.WriteString(".*")
+				i++
+			}
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		re = nil
+	}
+	cache[pattern] = re
+	return re
+}
@@ -0,0 +1,173 @@
+// relationship graphs of scanned infrastructure, with findings overlaid, rendered for review documents
+package graph
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// Graph holds the blocks of each module of a configuration and the references between them.
+type Graph struct {
+	Modules []Module
+	Edges   []Edge
+}
+
+// Module is a directory of the configuration with its blocks.
+type Module struct {
+	Dir   string
+	Nodes []Node
+}
+
+// Node is a block, such as a resource, data source or module call, spanning Line to EndLine
+// of File. Worst and Findings are set by Overlay.
+type Node struct {
+	ID            string
+	Label         string
+	File          string
+	Line, EndLine int
+
+	Worst    finding.Severity
+	Findings int
+}
+
+// Edge is a reference from one node to another, or from a module call to the module it
+// calls when Call is set; To is then the Dir of that module.
+type Edge struct {
+	From, To  string
+	DependsOn bool
+	Call      bool
+}
+
+var severityRank = map[finding.Severity]int{finding.Info: 1, finding.Warning: 2, finding.Error: 3}
+
+// Overlay counts on each node the findings reported within its lines, keeping the worst
+// severity. Findings outside every block, such as those of a whole file, are left out.
+func (g *Graph) Overlay(findings []finding.Finding) {
+	byFile := make(map[string][]*Node)
+	for i := range g.Modules {
+		for j := range g.Modules[i].Nodes {
+			n := &g.Modules[i].Nodes[j]
+			byFile[filepath.Clean(n.File)] = append(byFile[filepath.Clean(n.File)], n)
+		}
+	}
+	for _, f := range findings {
+		for _, n := range byFile[filepath.Clean(f.File)] {
+			if f.Line < n.Line || f.Line > n.EndLine || f.Line == 0 {
+				continue
+			}
+			n.Findings++
+			if severityRank[f.Severity] > severityRank[n.Worst] {
+				n.Worst = f.Severity
+			}
+			break
+		}
+	}
+}
+
+// Fill and border colors of nodes by their worst finding
+var severityColors = map[finding.Severity][2]string{
+	finding.Error:   {"#f8d7da", "#c0392b"},
+	finding.Warning: {"#fff3cd", "#d68910"},
+	finding.Info:    {"#d6eaf8", "#2e86c1"},
+	"":              {"#ffffff", "#555555"},
+}
+
+// label is the text of a node: its address, and its findings once overlaid.
+func (n Node) label(lineBreak string) string {
+	if n.Findings == 0 {
+		return n.Label
+	}
+	return fmt.Sprintf("%s%s%d finding(s), worst %s", n.Label, lineBreak, n.Findings, n.Worst)
+}
+
+// ids maps the node IDs and module dirs of g to short identifiers, as Mermaid needs them.
+func (g *Graph) ids() (nodes map[string]string, modules map[string]string) {
+	nodes, modules = make(map[string]string), make(map[string]string)
+	for i, m := range g.Modules {
+		modules[m.Dir] = fmt.Sprintf("m%d", i)
+		for _, n := range m.Nodes {
+			nodes[n.ID] = fmt.Sprintf("n%d", len(nodes))
+		}
+	}
+	return nodes, modules
+}
+
+// DOT renders g in the Graphviz DOT language, each module as a cluster. A module call points
+// at the cluster of the module it calls.
+func DOT(g *Graph) string {
+	nodes, modules := g.ids()
+	first := make(map[string]string) // module dir -> first node id
+	var b strings.Builder
+	b.WriteString("digraph infrastructure {\n")
+	b.WriteString("  compound=true;\n  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=\"rounded,filled\", fontname=\"Helvetica\"];\n")
+	for _, m := range g.Modules {
+		fmt.Fprintf(&b, "  subgraph cluster_%s {\n    label=%s;\n", modules[m.Dir], dotQuote(m.Dir))
+		for _, n := range m.Nodes {
+			if first[m.Dir] == "" {
+				first[m.Dir] = nodes[n.ID]
+			}
+			c := severityColors[n.Worst]
+			fmt.Fprintf(&b, "    %s [label=%s, fillcolor=\"%s\", color=\"%s\"];\n", nodes[n.ID], dotQuote(n.label("\n")), c[0], c[1])
+		}
+		b.WriteString("  }\n")
+	}
+	for _, e := range g.Edges {
+		switch {
+		case e.Call && first[e.To] != "":
+			fmt.Fprintf(&b, "  %s -> %s [lhead=cluster_%s, style=bold];\n", nodes[e.From], first[e.To], modules[e.To])
+		case e.Call:
+		case e.DependsOn:
+			fmt.Fprintf(&b, "  %s -> %s [style=dashed, label=\"depends_on\"];\n", nodes[e.From], nodes[e.To])
+		default:
+			fmt.Fprintf(&b, "  %s -> %s;\n", nodes[e.From], nodes[e.To])
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// Mermaid renders g as a Mermaid flowchart, each module as a subgraph, for Markdown documents.
+func Mermaid(g *Graph) string {
+	nodes, modules := g.ids()
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for _, sev := range []finding.Severity{finding.Error, finding.Warning, finding.Info} {
+		c := severityColors[sev]
+		fmt.Fprintf(&b, "  classDef %s fill:%s,stroke:%s\n", strings.ToLower(string(sev)), c[0], c[1])
+	}
+	for _, m := range g.Modules {
+		fmt.Fprintf(&b, "  subgraph %s[%s]\n", modules[m.Dir], mermaidQuote(m.Dir))
+		for _, n := range m.Nodes {
+			fmt.Fprintf(&b, "    %s[%s]", nodes[n.ID], mermaidQuote(n.label("<br/>")))
+			if n.Worst != "" {
+				fmt.Fprintf(&b, ":::%s", strings.ToLower(string(n.Worst)))
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("  end\n")
+	}
+	for _, e := range g.Edges {
+		switch {
+		case e.Call:
+			fmt.Fprintf(&b, "  %s ==> %s\n", nodes[e.From], modules[e.To])
+		case e.DependsOn:
+			fmt.Fprintf(&b, "  %s -.->|depends_on| %s\n", nodes[e.From], nodes[e.To])
+		default:
+			fmt.Fprintf(&b, "  %s --> %s\n", nodes[e.From], nodes[e.To])
+		}
+	}
+	return b.String()
+}
+
+// mermaidQuote quotes a label, replacing the double quotes Mermaid cannot escape.
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
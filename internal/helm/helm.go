@@ -0,0 +1,263 @@
+// static analysis of Helm charts, run against the rendered templates
+package helm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/kubernetes"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// Chart is the subset of Chart.yaml the checks need.
+type Chart struct {
+	APIVersion   string       `yaml:"apiVersion"`
+	Name         string       `yaml:"name"`
+	Version      string       `yaml:"version"`
+	AppVersion   string       `yaml:"appVersion"`
+	Description  string       `yaml:"description"`
+	Type         string       `yaml:"type"`
+	Dependencies []Dependency `yaml:"dependencies"`
+}
+
+type Dependency struct {
+	Name       string `yaml:"name"`
+	Version    string `yaml:"version"`
+	Repository string `yaml:"repository"`
+}
+
+// Matches an exact semantic version such as 1.2.3 or 1.2.3-rc.1
+var pinnedVersionRegex = regexp.MustCompile(`^v?\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+
+// Scan renders the chart in chartDir with its default values, overlaid by valuesFiles
+// in order, and checks both the chart metadata and the rendered manifests.
+func Scan(chartDir string, valuesFiles []string) ([]finding.Finding, error) {
+	var findings []finding.Finding
+
+	chartFile := filepath.Join(chartDir, "Chart.yaml")
+	data, err := os.ReadFile(chartFile)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a Helm chart: %v", chartDir, err)
+	}
+	var chart Chart
+	if err := yaml.Unmarshal(data, &chart); err != nil {
+		findings = append(findings, finding.Finding{
+			File:     chartFile,
+			Severity: finding.Error,
+			Message:  fmt.Sprintf("YAML parse error: %v", err),
+		})
+		return findings, nil
+	}
+	findings = append(findings, checkChart(chartFile, chart)...)
+
+	values, err := loadValues(chartDir, valuesFiles)
+	if err != nil {
+		return findings, err
+	}
+
+	rendered, renderFindings, err := render(chartDir, chart, values)
+	if err != nil {
+		return findings, err
+	}
+	findings = append(findings, renderFindings...)
+
+	names := make([]string, 0, len(rendered))
+	for name := range rendered {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		findings = append(findings, kubernetes.ScanManifest(name, []byte(rendered[name]))...)
+	}
+
+	return findings, nil
+}
+
+// Render renders the chart in chartDir with its default values and returns the manifests
+// by template path; templates that fail to parse or render are left out.
+func Render(chartDir string) (map[string]string, error) {
+	data, err := os.ReadFile(filepath.Join(chartDir, "Chart.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a Helm chart: %v", chartDir, err)
+	}
+	var chart Chart
+	if err := yaml.Unmarshal(data, &chart); err != nil {
+		return nil, err
+	}
+	values, err := loadValues(chartDir, nil)
+	if err != nil {
+		return nil, err
+	}
+	rendered, _, err := render(chartDir, chart, values)
+	return rendered, err
+}
+
+// checkChart runs the chart-specific metadata checks.
+func checkChart(file string, chart Chart) []finding.Finding {
+	var findings []finding.Finding
+
+	if chart.AppVersion == "" && chart.Type != "library" {
+		findings = append(findings, finding.Finding{
+			File:     file,
+			Severity: finding.Warning,
+			Message:  "Chart missing 'appVersion' (deployed application version is not tracked)",
+		})
+	}
+	if chart.APIVersion == "v1" {
+		findings = append(findings, finding.Finding{
+			File:     file,
+			Severity: finding.Info,
+			Message:  "Chart uses apiVersion v1; Helm 3 charts should use apiVersion v2",
+		})
+	}
+
+	for _, dep := range chart.Dependencies {
+		if !pinnedVersionRegex.MatchString(strings.TrimSpace(dep.Version)) {
+			version := dep.Version
+			if version == "" {
+				version = "none"
+			}
+			findings = append(findings, finding.Finding{
+				File:     file,
+				Severity: finding.Warning,
+				Message:  fmt.Sprintf("Chart dependency '%s' is not pinned to an exact version (version: %s)", dep.Name, version),
+			})
+		}
+		if strings.HasPrefix(dep.Repository, "http://") {
+			findings = append(findings, finding.Finding{
+				File:     file,
+				Severity: finding.Warning,
+				Message:  fmt.Sprintf("Chart dependency '%s' is fetched over plain HTTP (%s)", dep.Name, dep.Repository),
+			})
+		}
+	}
+
+	return findings
+}
+
+// loadValues reads values.yaml and merges each override file on top of it.
+func loadValues(chartDir string, valuesFiles []string) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	files := append([]string{filepath.Join(chartDir, "values.yaml")}, valuesFiles...)
+	for i, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			if i == 0 && os.IsNotExist(err) {
+				continue // charts may ship without defaults
+			}
+			return nil, err
+		}
+		var override map[string]interface{}
+		if err := yaml.Unmarshal(data, &override); err != nil {
+			return nil, fmt.Errorf("%s: %v", f, err)
+		}
+		mergeValues(values, override)
+	}
+	return values, nil
+}
+
+// mergeValues deep-merges src into dst, with src winning on conflicts.
+func mergeValues(dst, src map[string]interface{}) {
+	for k, v := range src {
+		if srcMap, ok := v.(map[string]interface{}); ok {
+			if dstMap, ok := dst[k].(map[string]interface{}); ok {
+				mergeValues(dstMap, srcMap)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
+
+// render executes every manifest template of the chart and returns the output by template path.
+// Failures parsing or rendering a template become findings on that template.
+func render(chartDir string, chart Chart, values map[string]interface{}) (map[string]string, []finding.Finding, error) {
+	var findings []finding.Finding
+	r := newRenderer()
+
+	templatesDir := filepath.Join(chartDir, "templates")
+	var manifests []string
+	err := scanner.Walk(templatesDir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if err := r.add(p, string(content)); err != nil {
+			findings = append(findings, finding.Finding{
+				File:     p,
+				Severity: finding.Error,
+				Message:  fmt.Sprintf("Failed to parse template: %v", err),
+			})
+			return nil
+		}
+		base := filepath.Base(p)
+		ext := filepath.Ext(p)
+		if !strings.HasPrefix(base, "_") && base != "NOTES.txt" && (ext == ".yaml" || ext == ".yml" || ext == ".tpl") {
+			manifests = append(manifests, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, findings, err
+	}
+
+	rendered := make(map[string]string)
+	for _, name := range manifests {
+		out, err := r.render(name, templateData(chartDir, chart, values, name))
+		if err != nil {
+			findings = append(findings, finding.Finding{
+				File:     name,
+				Severity: finding.Error,
+				Message:  fmt.Sprintf("Failed to render template: %v", err),
+			})
+			continue
+		}
+		rendered[name] = out
+	}
+	return rendered, findings, nil
+}
+
+// templateData builds the built-in objects available to chart templates.
+func templateData(chartDir string, chart Chart, values map[string]interface{}, name string) map[string]interface{} {
+	return map[string]interface{}{
+		"Values": values,
+		"Chart": map[string]interface{}{
+			"Name":        chart.Name,
+			"Version":     chart.Version,
+			"AppVersion":  chart.AppVersion,
+			"Description": chart.Description,
+			"Type":        chart.Type,
+		},
+		"Release": map[string]interface{}{
+			"Name":      "release-name",
+			"Namespace": "default",
+			"Service":   "Helm",
+			"IsInstall": true,
+			"IsUpgrade": false,
+			"Revision":  1,
+		},
+		"Capabilities": map[string]interface{}{
+			"KubeVersion": map[string]interface{}{"Version": "v1.29.0", "Major": "1", "Minor": "29"},
+		},
+		"Template": map[string]interface{}{
+			"Name":     name,
+			"BasePath": filepath.Join(chartDir, "templates"),
+		},
+	}
+}
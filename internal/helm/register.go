@@ -0,0 +1,36 @@
+package helm
+
+import (
+	"path/filepath"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// helmScanner scans each chart directory, i.e. every directory holding a Chart.yaml.
+type helmScanner struct{}
+
+func init() {
+	scanner.Register(helmScanner{})
+}
+
+func (helmScanner) Name() string { return "helm" }
+func (helmScanner) Description() string {
+	return "Render a Helm chart and scan the resulting manifests"
+}
+func (helmScanner) FileMatcher() scanner.FileMatcher {
+	return scanner.Extensions(".yml", ".yaml", ".tpl")
+}
+
+// Scan scans a chart with its default values.
+func (helmScanner) Scan(chart string) ([]finding.Finding, error) { return Scan(chart, nil) }
+
+func (helmScanner) Targets(root string, files []string) []string {
+	var charts []string
+	for _, p := range files {
+		if filepath.Base(p) == "Chart.yaml" {
+			charts = append(charts, filepath.Dir(p))
+		}
+	}
+	return charts
+}
@@ -0,0 +1,255 @@
+package helm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rendering uses Go text/template like Helm does, with the subset of Sprig and Helm
+// functions commonly found in charts. Functions that need a live cluster (lookup)
+// return empty results so rendering matches `helm template`.
+
+// renderer renders every template of one chart against a fixed set of values.
+type renderer struct {
+	tmpl *template.Template
+}
+
+func newRenderer() *renderer {
+	r := &renderer{}
+	r.tmpl = template.New("chart").Option("missingkey=zero").Funcs(r.funcMap())
+	return r
+}
+
+func (r *renderer) add(name, content string) error {
+	_, err := r.tmpl.New(name).Parse(content)
+	return err
+}
+
+func (r *renderer) render(name string, data interface{}) (string, error) {
+	var b bytes.Buffer
+	if err := r.tmpl.ExecuteTemplate(&b, name, data); err != nil {
+		return "", err
+	}
+	return strings.ReplaceAll(b.String(), "<no value>", ""), nil
+}
+
+func (r *renderer) funcMap() template.FuncMap {
+	return template.FuncMap{
+		// Helm
+		"include": func(name string, data interface{}) (string, error) {
+			return r.render(name, data)
+		},
+		"tpl": func(text string, data interface{}) (string, error) {
+			t, err := r.tmpl.Clone()
+			if err != nil {
+				return "", err
+			}
+			t, err = t.New("tpl").Parse(text)
+			if err != nil {
+				return "", err
+			}
+			var b bytes.Buffer
+			err = t.Execute(&b, data)
+			return b.String(), err
+		},
+		"required": func(msg string, v interface{}) (interface{}, error) {
+			if empty(v) {
+				return nil, errors.New(msg)
+			}
+			return v, nil
+		},
+		"fail":   func(msg string) (string, error) { return "", errors.New(msg) },
+		"lookup": func(...interface{}) map[string]interface{} { return map[string]interface{}{} },
+		"toYaml": func(v interface{}) string {
+			data, err := yaml.Marshal(v)
+			if err != nil {
+				return ""
+			}
+			return strings.TrimSuffix(string(data), "\n")
+		},
+		"fromYaml": func(s string) map[string]interface{} {
+			m := map[string]interface{}{}
+			yaml.Unmarshal([]byte(s), &m)
+			return m
+		},
+		"toJson": func(v interface{}) string {
+			data, _ := json.Marshal(v)
+			return string(data)
+		},
+
+		// Sprig: defaults and flow
+		"default": func(def interface{}, given ...interface{}) interface{} {
+			if len(given) == 0 || empty(given[0]) {
+				return def
+			}
+			return given[0]
+		},
+		"empty": empty,
+		"coalesce": func(vals ...interface{}) interface{} {
+			for _, v := range vals {
+				if !empty(v) {
+					return v
+				}
+			}
+			return nil
+		},
+		"ternary": func(a, b interface{}, cond bool) interface{} {
+			if cond {
+				return a
+			}
+			return b
+		},
+
+		// Sprig: strings
+		"quote":      func(vals ...interface{}) string { return joinQuoted(`"`, vals) },
+		"squote":     func(vals ...interface{}) string { return joinQuoted(`'`, vals) },
+		"upper":      strings.ToUpper,
+		"lower":      strings.ToLower,
+		"title":      strings.Title,
+		"trim":       strings.TrimSpace,
+		"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+		"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trunc": func(n int, s string) string {
+			if n >= 0 && len(s) > n {
+				return s[:n]
+			}
+			return s
+		},
+		"replace":   func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"contains":  func(substr, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix": func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix": func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		"indent": func(n int, s string) string {
+			pad := strings.Repeat(" ", n)
+			return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+		},
+		"nindent": func(n int, s string) string {
+			pad := strings.Repeat(" ", n)
+			return "\n" + pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+		},
+		"join": func(sep string, v interface{}) string {
+			var parts []string
+			for _, item := range toList(v) {
+				parts = append(parts, fmt.Sprint(item))
+			}
+			return strings.Join(parts, sep)
+		},
+		"toString":     func(v interface{}) string { return fmt.Sprint(v) },
+		"b64enc":       func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) },
+		"b64dec":       func(s string) string { d, _ := base64.StdEncoding.DecodeString(s); return string(d) },
+		"sha256sum":    func(s string) string { h := sha256.Sum256([]byte(s)); return hex.EncodeToString(h[:]) },
+		"randAlphaNum": func(n int) string { return strings.Repeat("x", n) },
+
+		// Sprig: numbers
+		"int":   toInt,
+		"int64": func(v interface{}) int64 { return int64(toInt(v)) },
+		"add":   func(a, b interface{}) int { return toInt(a) + toInt(b) },
+		"sub":   func(a, b interface{}) int { return toInt(a) - toInt(b) },
+		"mul":   func(a, b interface{}) int { return toInt(a) * toInt(b) },
+
+		// Sprig: collections
+		"list": func(vals ...interface{}) []interface{} { return vals },
+		"dict": func(kv ...interface{}) map[string]interface{} {
+			m := map[string]interface{}{}
+			for i := 0; i+1 < len(kv); i += 2 {
+				m[fmt.Sprint(kv[i])] = kv[i+1]
+			}
+			return m
+		},
+		"get":    func(m map[string]interface{}, k string) interface{} { return m[k] },
+		"hasKey": func(m map[string]interface{}, k string) bool { _, ok := m[k]; return ok },
+		"set": func(m map[string]interface{}, k string, v interface{}) map[string]interface{} {
+			m[k] = v
+			return m
+		},
+		"merge": func(dst map[string]interface{}, srcs ...map[string]interface{}) map[string]interface{} {
+			for _, src := range srcs {
+				for k, v := range src {
+					if _, ok := dst[k]; !ok {
+						dst[k] = v
+					}
+				}
+			}
+			return dst
+		},
+		"keys": func(m map[string]interface{}) []string {
+			var ks []string
+			for k := range m {
+				ks = append(ks, k)
+			}
+			return ks
+		},
+
+		// Sprig: types and versions
+		"kindIs":        func(kind string, v interface{}) bool { return v != nil && reflect.TypeOf(v).Kind().String() == kind },
+		"typeOf":        func(v interface{}) string { return fmt.Sprintf("%T", v) },
+		"semverCompare": func(constraint, version string) bool { return true },
+	}
+}
+
+// empty mirrors Sprig's notion of an empty value.
+func empty(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return rv.Len() == 0
+	case reflect.Bool:
+		return !rv.Bool()
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		return rv.Int() == 0
+	case reflect.Float64, reflect.Float32:
+		return rv.Float() == 0
+	}
+	return false
+}
+
+func joinQuoted(q string, vals []interface{}) string {
+	var parts []string
+	for _, v := range vals {
+		if v != nil {
+			parts = append(parts, q+fmt.Sprint(v)+q)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func toList(v interface{}) []interface{} {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil
+	}
+	out := make([]interface{}, rv.Len())
+	for i := range out {
+		out[i] = rv.Index(i).Interface()
+	}
+	return out
+}
+
+func toInt(v interface{}) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case int64:
+		return int(n)
+	case float64:
+		return int(n)
+	case string:
+		i, _ := strconv.Atoi(n)
+		return i
+	}
+	return 0
+}
@@ -0,0 +1,49 @@
+package helm
+
+import (
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/registry"
+)
+
+func init() {
+	scanners := []string{"helm"}
+	registry.Register(
+		registry.Rule{
+			ID: "HELM001", Name: "Chart without appVersion", Severity: finding.Warning, Category: "hygiene", Scanners: scanners,
+			Description: "Chart.yaml sets no appVersion, so releases do not record which application version they deploy.",
+			Messages:    []string{"Chart missing 'appVersion' *"},
+			Remediation: `apiVersion: v2
+name: web
+version: 1.4.0
+appVersion: "2.3.1"`,
+		},
+		registry.Rule{
+			ID: "HELM002", Name: "Chart apiVersion v1", Severity: finding.Info, Category: "deprecation", Scanners: scanners,
+			Description: "The chart uses the Helm 2 apiVersion v1 instead of v2.",
+			Messages:    []string{"Chart uses apiVersion v1; *"},
+			Remediation: `apiVersion: v2
+name: web
+version: 1.4.0`,
+		},
+		registry.Rule{
+			ID: "HELM003", Name: "Unpinned chart dependency", Severity: finding.Warning, Category: "supply-chain", Scanners: scanners,
+			Compliance:  registry.SupplyChain,
+			Description: "A chart dependency uses a version range, so updates to it are picked up without review.",
+			Messages:    []string{"Chart dependency '*' is not pinned to an exact version *"},
+			Remediation: `dependencies:
+  - name: postgresql
+    version: 15.5.0
+    repository: https://charts.bitnami.com/bitnami`,
+		},
+		registry.Rule{
+			ID: "HELM004", Name: "Chart dependency over HTTP", Severity: finding.Warning, Category: "supply-chain", Scanners: scanners,
+			Compliance:  registry.EncryptionInTransit,
+			Description: "A chart dependency is fetched from a plain HTTP repository and can be tampered with in transit.",
+			Messages:    []string{"Chart dependency '*' is fetched over plain HTTP *"},
+			Remediation: `dependencies:
+  - name: redis
+    version: 19.0.1
+    repository: https://charts.bitnami.com/bitnami`,
+		},
+	)
+}
@@ -0,0 +1,247 @@
+// Package history records the findings of scans in a BoltDB file, one scan per commit, to
+// follow a repository's findings over time: how many appear and get fixed from one scan to
+// the next, and when a finding first appeared.
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// DefaultFile is the history store, relative to the repository root.
+const DefaultFile = ".infra-check/history.db"
+
+var (
+	scansBucket     = []byte("scans")
+	baselinesBucket = []byte("baselines")
+)
+
+// Scan is the recorded result of scanning a commit. The findings carry fingerprints and
+// paths relative to the repository root, so they compare across scans.
+type Scan struct {
+	Commit   string
+	Time     time.Time
+	Findings []finding.Finding
+}
+
+// Store is an open history file.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens the history at path, creating it and its directory unless readOnly.
+func Open(path string, readOnly bool) (*Store, error) {
+	if readOnly {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("no scan history at %s; record scans with 'scan ... --record'", path)
+		}
+	} else if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(path, 0o644, &bolt.Options{Timeout: 5 * time.Second, ReadOnly: readOnly})
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %v", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the file.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Record stores scan, replacing any earlier scan of the same commit.
+func (s *Store) Record(scan Scan) error {
+	if scan.Commit == "" {
+		return fmt.Errorf("a scan is recorded by commit, and has none")
+	}
+	data, err := json.Marshal(scan)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(scansBucket)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(scan.Commit), data)
+	})
+}
+
+// Scans returns every recorded scan, oldest first.
+func (s *Store) Scans() ([]Scan, error) {
+	var scans []Scan
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(scansBucket)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var scan Scan
+			if err := json.Unmarshal(v, &scan); err != nil {
+				return fmt.Errorf("scan of %s: %v", k, err)
+			}
+			scans = append(scans, scan)
+			return nil
+		})
+	})
+	sort.SliceStable(scans, func(i, j int) bool { return scans[i].Time.Before(scans[j].Time) })
+	return scans, err
+}
+
+// Baseline is the last scan of a branch kept for incremental scans: the raw findings of
+// every target, before suppression and configuration apply, so the next scan of the branch
+// only rescans the targets holding files changed since Commit. Paths are as the scanners
+// named them.
+type Baseline struct {
+	Branch string
+	Commit string
+	Time   time.Time
+
+	// Dirty maps a scanned path to the files under it that differed from Commit when the
+	// baseline was taken.
+	Dirty map[string][]string
+
+	// Findings maps a scanner to its targets and the findings of each.
+	Findings map[string]map[string][]finding.Finding
+}
+
+// Baseline returns the baseline saved under key, or nil when there is none.
+func (s *Store) Baseline(key string) (*Baseline, error) {
+	var base *Baseline
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(baselinesBucket)
+		if b == nil {
+			return nil
+		}
+		data := b.Get([]byte(key))
+		if data == nil {
+			return nil
+		}
+		base = &Baseline{}
+		return json.Unmarshal(data, base)
+	})
+	return base, err
+}
+
+// SaveBaseline stores base under key, replacing the earlier one.
+func (s *Store) SaveBaseline(key string, base Baseline) error {
+	data, err := json.Marshal(base)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(baselinesBucket)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), data)
+	})
+}
+
+// Step is a scan compared with the one before it.
+type Step struct {
+	Scan  Scan
+	New   []finding.Finding // in Scan but not in the scan before
+	Fixed []finding.Finding // in the scan before but not in Scan
+}
+
+// Trend compares every scan with the previous one; all findings of the first scan are new.
+func Trend(scans []Scan) []Step {
+	steps := make([]Step, len(scans))
+	var before map[string]finding.Finding
+	for i, scan := range scans {
+		now := byFingerprint(scan.Findings)
+		step := Step{Scan: scan}
+		for _, f := range scan.Findings {
+			if _, ok := before[f.Fingerprint]; !ok {
+				step.New = append(step.New, f)
+			}
+		}
+		if i > 0 {
+			for _, f := range scans[i-1].Findings {
+				if _, ok := now[f.Fingerprint]; !ok {
+					step.Fixed = append(step.Fixed, f)
+				}
+			}
+		}
+		steps[i] = step
+		before = now
+	}
+	return steps
+}
+
+func byFingerprint(findings []finding.Finding) map[string]finding.Finding {
+	m := make(map[string]finding.Finding, len(findings))
+	for _, f := range findings {
+		m[f.Fingerprint] = f
+	}
+	return m
+}
+
+// Lifetime is where a finding shows up in the history.
+type Lifetime struct {
+	Finding finding.Finding // as in the last scan reporting it
+	First   Scan            // the first scan reporting it
+	Last    Scan            // the last scan reporting it
+	Fixed   *Scan           // the scan after Last, or nil when Last is the latest scan
+}
+
+// Lifetimes returns the history of the findings whose fingerprint starts with prefix, or
+// of those at file (and line, when not 0) in the latest scan reporting any there.
+func Lifetimes(scans []Scan, prefix, file string, line int) []Lifetime {
+	var fingerprints []string
+	if prefix != "" {
+		seen := make(map[string]bool)
+		for _, scan := range scans {
+			for _, f := range scan.Findings {
+				if strings.HasPrefix(f.Fingerprint, prefix) && !seen[f.Fingerprint] {
+					seen[f.Fingerprint] = true
+					fingerprints = append(fingerprints, f.Fingerprint)
+				}
+			}
+		}
+	} else {
+		for i := len(scans) - 1; i >= 0 && len(fingerprints) == 0; i-- {
+			for _, f := range scans[i].Findings {
+				if f.File == file && (line == 0 || f.Line == line) {
+					fingerprints = append(fingerprints, f.Fingerprint)
+				}
+			}
+		}
+	}
+
+	var lifetimes []Lifetime
+	for _, fp := range fingerprints {
+		var l Lifetime
+		first, last := -1, -1
+		for i, scan := range scans {
+			for _, f := range scan.Findings {
+				if f.Fingerprint == fp {
+					if first < 0 {
+						first = i
+					}
+					last = i
+					l.Finding = f
+					break
+				}
+			}
+		}
+		l.First, l.Last = scans[first], scans[last]
+		if last+1 < len(scans) {
+			l.Fixed = &scans[last+1]
+		}
+		lifetimes = append(lifetimes, l)
+	}
+	return lifetimes
+}
@@ -0,0 +1,260 @@
+// static analysis of Kubernetes manifests
+package kubernetes
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// Object is the subset of a Kubernetes manifest the checks need.
+type Object struct {
+	APIVersion string                 `yaml:"apiVersion"`
+	Kind       string                 `yaml:"kind"`
+	Metadata   Metadata               `yaml:"metadata"`
+	Spec       map[string]interface{} `yaml:"spec"`
+}
+
+type Metadata struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace"`
+}
+
+// Keywords to detect hardcoded secrets in container environment variables
+var secretKeywords = []string{"password", "secret", "token", "key", "pwd"}
+
+// Capabilities that effectively grant root on the node
+var dangerousCapabilities = map[string]bool{
+	"ALL": true, "SYS_ADMIN": true, "NET_ADMIN": true, "SYS_PTRACE": true, "SYS_MODULE": true,
+}
+
+// Scan walks path for *.yml/*.yaml files containing Kubernetes objects and checks each one.
+// YAML files that are not Kubernetes manifests (no apiVersion/kind) are ignored.
+func Scan(path string) ([]finding.Finding, error) {
+	return scanner.Collect(path, ScanStream)
+}
+
+// ScanStream is Scan emitting the findings of each manifest once it is checked.
+func ScanStream(path string, emit scanner.Emit) error {
+	return scanner.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		ext := filepath.Ext(p)
+		if ext != ".yml" && ext != ".yaml" {
+			return nil
+		}
+
+		data, err := os.ReadFile(p)
+		if err != nil {
+			emit(finding.Finding{
+				File:     p,
+				Severity: finding.Error,
+				Message:  fmt.Sprintf("Failed to read file: %v", err),
+			})
+			return nil
+		}
+
+		for _, f := range ScanManifest(p, data) {
+			emit(f)
+		}
+		return nil
+	})
+}
+
+// ScanManifest checks every object in a (possibly multi-document) manifest.
+// Findings are attributed to file, which need not exist on disk (e.g. rendered Helm templates).
+func ScanManifest(file string, data []byte) []finding.Finding {
+	var findings []finding.Finding
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var obj Object
+		err := dec.Decode(&obj)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			findings = append(findings, finding.Finding{
+				File:     file,
+				Severity: finding.Error,
+				Message:  fmt.Sprintf("YAML parse error: %v", err),
+			})
+			break
+		}
+		if obj.APIVersion == "" || obj.Kind == "" {
+			continue
+		}
+		findings = append(findings, checkObject(file, obj)...)
+	}
+
+	return findings
+}
+
+// podSpec returns the pod template spec of workload kinds.
+func podSpec(obj Object) map[string]interface{} {
+	switch obj.Kind {
+	case "Pod":
+		return obj.Spec
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job", "ReplicationController":
+		return nested(obj.Spec, "template", "spec")
+	case "CronJob":
+		return nested(obj.Spec, "jobTemplate", "spec", "template", "spec")
+	}
+	return nil
+}
+
+func checkObject(file string, obj Object) []finding.Finding {
+	var findings []finding.Finding
+	ref := fmt.Sprintf("%s '%s'", obj.Kind, obj.Metadata.Name)
+	add := func(sev finding.Severity, format string, args ...interface{}) {
+		findings = append(findings, finding.Finding{
+			File:     file,
+			Severity: sev,
+			Message:  fmt.Sprintf("%s: %s", ref, fmt.Sprintf(format, args...)),
+		})
+	}
+
+	if obj.Kind == "Service" {
+		if t, _ := obj.Spec["type"].(string); t == "LoadBalancer" || t == "NodePort" {
+			add(finding.Info, "Service type %s exposes the workload outside the cluster", t)
+		}
+		return findings
+	}
+
+	spec := podSpec(obj)
+	if spec == nil {
+		return findings
+	}
+
+	for _, field := range []string{"hostNetwork", "hostPID", "hostIPC"} {
+		if v, _ := spec[field].(bool); v {
+			add(finding.Error, "%s is enabled (shares host namespaces)", field)
+		}
+	}
+
+	if volumes, ok := spec["volumes"].([]interface{}); ok {
+		for _, v := range volumes {
+			vol, _ := v.(map[string]interface{})
+			if _, ok := vol["hostPath"]; ok {
+				add(finding.Warning, "volume '%v' mounts a hostPath", vol["name"])
+			}
+		}
+	}
+
+	podSecurity, _ := spec["securityContext"].(map[string]interface{})
+
+	var containers []interface{}
+	for _, key := range []string{"initContainers", "containers"} {
+		if list, ok := spec[key].([]interface{}); ok {
+			containers = append(containers, list...)
+		}
+	}
+	for _, c := range containers {
+		container, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name := fmt.Sprint(container["name"])
+		checkContainer(name, container, podSecurity, add)
+	}
+
+	return findings
+}
+
+func checkContainer(name string, c, podSecurity map[string]interface{}, add func(finding.Severity, string, ...interface{})) {
+	image, _ := c["image"].(string)
+	if image == "" {
+		add(finding.Warning, "container '%s' has no image", name)
+	} else if tag := imageTag(image); tag == "" || tag == "latest" {
+		add(finding.Warning, "container '%s' image '%s' is not pinned to a version tag or digest", name, image)
+	}
+
+	security, _ := c["securityContext"].(map[string]interface{})
+	if v, _ := security["privileged"].(bool); v {
+		add(finding.Error, "container '%s' runs privileged", name)
+	}
+	if v, ok := security["allowPrivilegeEscalation"].(bool); !ok || v {
+		add(finding.Warning, "container '%s' does not set allowPrivilegeEscalation: false", name)
+	}
+	if !runsAsNonRoot(security, podSecurity) {
+		add(finding.Warning, "container '%s' may run as root (set runAsNonRoot: true or a non-zero runAsUser)", name)
+	}
+	if v, _ := security["readOnlyRootFilesystem"].(bool); !v {
+		add(finding.Info, "container '%s' does not use a read-only root filesystem", name)
+	}
+	if caps, ok := nested(security, "capabilities")["add"].([]interface{}); ok {
+		for _, cp := range caps {
+			if capName := strings.TrimPrefix(strings.ToUpper(fmt.Sprint(cp)), "CAP_"); dangerousCapabilities[capName] {
+				add(finding.Error, "container '%s' adds dangerous capability %s", name, capName)
+			}
+		}
+	}
+
+	if _, ok := nested(c, "resources")["limits"]; !ok {
+		add(finding.Warning, "container '%s' has no resource limits", name)
+	}
+
+	if env, ok := c["env"].([]interface{}); ok {
+		for _, e := range env {
+			ev, _ := e.(map[string]interface{})
+			envName := strings.ToLower(fmt.Sprint(ev["name"]))
+			value, isLiteral := ev["value"].(string)
+			if !isLiteral || value == "" {
+				continue
+			}
+			for _, kw := range secretKeywords {
+				if strings.Contains(envName, kw) {
+					add(finding.Error, "container '%s' env '%v' may contain a hardcoded secret (use secretKeyRef)", name, ev["name"])
+					break
+				}
+			}
+		}
+	}
+}
+
+func runsAsNonRoot(container, pod map[string]interface{}) bool {
+	for _, sc := range []map[string]interface{}{container, pod} {
+		if uid, ok := sc["runAsUser"].(int); ok {
+			return uid != 0
+		}
+		if v, ok := sc["runAsNonRoot"].(bool); ok {
+			return v
+		}
+	}
+	return false
+}
+
+// imageTag returns the tag of an image reference, or "@" prefixed digest marker when pinned by digest.
+func imageTag(image string) string {
+	if strings.Contains(image, "@") {
+		return "@digest"
+	}
+	last := image[strings.LastIndex(image, "/")+1:]
+	if i := strings.LastIndex(last, ":"); i >= 0 {
+		return last[i+1:]
+	}
+	return ""
+}
+
+// nested walks a chain of map keys, returning nil when any level is missing.
+func nested(m map[string]interface{}, keys ...string) map[string]interface{} {
+	cur := m
+	for _, k := range keys {
+		next, ok := cur[k].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		cur = next
+	}
+	return cur
+}
@@ -0,0 +1,88 @@
+package kubernetes
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// kubernetesScanner scans the YAML files holding Kubernetes objects. Files inside
+// Helm charts are left to the helm scanner, which checks the rendered templates, and
+// those of kustomization directories to the kustomize scanner, which checks the builds.
+type kubernetesScanner struct{}
+
+// Names of kustomization files, as the kustomize package looks for them
+var kustomizationFiles = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+func init() {
+	scanner.Register(kubernetesScanner{})
+}
+
+func (kubernetesScanner) Name() string { return "kubernetes" }
+func (kubernetesScanner) Description() string {
+	return "Scan Kubernetes manifests in the specified directories or files"
+}
+func (kubernetesScanner) FileMatcher() scanner.FileMatcher {
+	return scanner.Extensions(".yml", ".yaml")
+}
+func (kubernetesScanner) Scan(path string) ([]finding.Finding, error) { return Scan(path) }
+func (kubernetesScanner) ScanStream(path string, emit scanner.Emit) error {
+	return ScanStream(path, emit)
+}
+
+func (kubernetesScanner) Targets(root string, files []string) []string {
+	var charts, kustomizations []string
+	for _, p := range files {
+		if filepath.Base(p) == "Chart.yaml" {
+			charts = append(charts, filepath.Dir(p)+string(filepath.Separator))
+		}
+		for _, name := range kustomizationFiles {
+			if filepath.Base(p) == name {
+				kustomizations = append(kustomizations, filepath.Dir(p)+string(filepath.Separator))
+			}
+		}
+	}
+
+	var manifests []string
+	for _, p := range files {
+		if filepath.Base(p) == "Chart.yaml" || insideAny(p, charts) || insideAny(p, kustomizations) {
+			continue
+		}
+		if data, err := os.ReadFile(p); err == nil && IsManifest(data) {
+			manifests = append(manifests, p)
+		}
+	}
+	return manifests
+}
+
+// IsManifest reports whether any YAML document in data is a Kubernetes object.
+func IsManifest(data []byte) bool {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc struct {
+			APIVersion string `yaml:"apiVersion"`
+			Kind       string `yaml:"kind"`
+		}
+		if err := dec.Decode(&doc); err != nil {
+			return false
+		}
+		if doc.APIVersion != "" && doc.Kind != "" {
+			return true
+		}
+	}
+}
+
+func insideAny(p string, dirs []string) bool {
+	for _, dir := range dirs {
+		if strings.HasPrefix(p, dir) {
+			return true
+		}
+	}
+	return false
+}
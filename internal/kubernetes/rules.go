@@ -0,0 +1,115 @@
+package kubernetes
+
+import (
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/registry"
+)
+
+// Helm and Kustomize run these checks on the objects they render, so their findings count too.
+func init() {
+	scanners := []string{"kubernetes", "helm", "kustomize"}
+	registry.Register(
+		registry.Rule{
+			ID: "K8S001", Name: "Service exposed outside the cluster", Severity: finding.Info, Category: "network", Scanners: scanners,
+			Compliance:  registry.NetworkExposure,
+			Description: "A Service of type LoadBalancer or NodePort is reachable from outside the cluster.",
+			Messages:    []string{"Service '*': Service type * exposes the workload outside the cluster"},
+			Remediation: `apiVersion: v1
+kind: Service
+spec:
+  type: ClusterIP # expose it through an Ingress instead`,
+		},
+		registry.Rule{
+			ID: "K8S002", Name: "Host namespaces shared", Severity: finding.Error, Category: "isolation", Scanners: scanners,
+			Compliance:  registry.PrivilegedWorkloads,
+			Description: "A pod sets hostNetwork, hostPID or hostIPC and shares the node's namespaces.",
+			Messages:    []string{"* '*': host* is enabled (shares host namespaces)"},
+			Remediation: `spec:
+  hostNetwork: false
+  hostPID: false
+  hostIPC: false`,
+		},
+		registry.Rule{
+			ID: "K8S003", Name: "hostPath volume", Severity: finding.Warning, Category: "isolation", Scanners: scanners,
+			Compliance:  registry.PrivilegedWorkloads,
+			Description: "A pod mounts a directory of the node, which can expose node credentials or allow escaping the container.",
+			Messages:    []string{"* '*': volume '*' mounts a hostPath"},
+			Remediation: `volumes:
+  - name: cache
+    emptyDir: {}`,
+		},
+		registry.Rule{
+			ID: "K8S004", Name: "Unpinned container image", Severity: finding.Warning, Category: "supply-chain", Scanners: scanners,
+			Compliance:  registry.SupplyChain,
+			Description: "A container has no image, or one not pinned to a version tag or digest.",
+			Messages:    []string{"* '*': container '*' has no image", "* '*': container '*' image '*' is not pinned to a version tag or digest"},
+			Remediation: "image: ghcr.io/acme/web:1.4.2@sha256:<digest>",
+		},
+		registry.Rule{
+			ID: "K8S005", Name: "Privileged container", Severity: finding.Error, Category: "privilege", Scanners: scanners,
+			Compliance:  registry.PrivilegedWorkloads,
+			Description: "A container runs privileged, with every capability and access to the node's devices.",
+			Messages:    []string{"* '*': container '*' runs privileged"},
+			Remediation: `securityContext:
+  privileged: false`,
+		},
+		registry.Rule{
+			ID: "K8S006", Name: "Privilege escalation allowed", Severity: finding.Warning, Category: "privilege", Scanners: scanners,
+			Compliance:  registry.PrivilegedWorkloads,
+			Description: "A container does not set allowPrivilegeEscalation: false, so setuid binaries can gain privileges.",
+			Messages:    []string{"* '*': container '*' does not set allowPrivilegeEscalation: false"},
+			Remediation: `securityContext:
+  allowPrivilegeEscalation: false`,
+		},
+		registry.Rule{
+			ID: "K8S007", Name: "Container may run as root", Severity: finding.Warning, Category: "privilege", Scanners: scanners,
+			Compliance:  registry.PrivilegedWorkloads,
+			Description: "Neither the pod nor the container sets runAsNonRoot: true or a non-zero runAsUser.",
+			Messages:    []string{"* '*': container '*' may run as root *"},
+			Remediation: `securityContext:
+  runAsNonRoot: true
+  runAsUser: 10001`,
+		},
+		registry.Rule{
+			ID: "K8S008", Name: "Writable root filesystem", Severity: finding.Info, Category: "isolation", Scanners: scanners,
+			Compliance:  registry.PrivilegedWorkloads,
+			Description: "A container does not set readOnlyRootFilesystem: true, so an attacker can modify its binaries.",
+			Messages:    []string{"* '*': container '*' does not use a read-only root filesystem"},
+			Remediation: `securityContext:
+  readOnlyRootFilesystem: true`,
+		},
+		registry.Rule{
+			ID: "K8S009", Name: "Dangerous capability", Severity: finding.Error, Category: "privilege", Scanners: scanners,
+			Compliance:  registry.PrivilegedWorkloads,
+			Description: "A container adds ALL, SYS_ADMIN, NET_ADMIN, SYS_PTRACE or SYS_MODULE, each enough to escape the container or attack the node.",
+			Messages:    []string{"* '*': container '*' adds dangerous capability *"},
+			Remediation: `securityContext:
+  capabilities:
+    drop: ["ALL"]
+    add: ["NET_BIND_SERVICE"]`,
+		},
+		registry.Rule{
+			ID: "K8S010", Name: "No resource limits", Severity: finding.Warning, Category: "reliability", Scanners: scanners,
+			Description: "A container sets no resource limits and can starve the other workloads on its node.",
+			Messages:    []string{"* '*': container '*' has no resource limits"},
+			Remediation: `resources:
+  requests:
+    cpu: 100m
+    memory: 128Mi
+  limits:
+    memory: 256Mi`,
+		},
+		registry.Rule{
+			ID: "K8S011", Name: "Hardcoded secret in env", Severity: finding.Error, Category: "secrets", Scanners: scanners,
+			Compliance:  registry.HardcodedSecrets,
+			Description: "A container env var with a secret-like name has a literal value instead of a secretKeyRef.",
+			Messages:    []string{"* '*': container '*' env '*' may contain a hardcoded secret *"},
+			Remediation: `env:
+  - name: DB_PASSWORD
+    valueFrom:
+      secretKeyRef:
+        name: db
+        key: password`,
+		},
+	)
+}
@@ -0,0 +1,195 @@
+package kustomize
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// Generator is a configMapGenerator or secretGenerator entry.
+type Generator struct {
+	Name      string            `yaml:"name"`
+	Namespace string            `yaml:"namespace"`
+	Behavior  string            `yaml:"behavior"` // create (default), merge or replace
+	Literals  []string          `yaml:"literals"`
+	Files     []string          `yaml:"files"`
+	Envs      []string          `yaml:"envs"`
+	Env       string            `yaml:"env"`
+	Type      string            `yaml:"type"` // of a Secret
+	Options   *GeneratorOptions `yaml:"options"`
+}
+
+// GeneratorOptions are the options of the generators, in generatorOptions or per generator.
+type GeneratorOptions struct {
+	DisableNameSuffixHash bool              `yaml:"disableNameSuffixHash"`
+	Labels                map[string]string `yaml:"labels"`
+	Annotations           map[string]string `yaml:"annotations"`
+}
+
+// generate adds the ConfigMaps and Secrets of k's generators to resources, or merges them
+// into the generated ones of a base of the same name.
+func (b *builder) generate(dir, file string, k *Kustomization, resources []*resource) ([]*resource, error) {
+	type generator struct {
+		field, kind string
+		Generator
+	}
+	var gens []generator
+	for _, g := range k.ConfigMapGenerator {
+		gens = append(gens, generator{"configMapGenerator", "ConfigMap", g})
+	}
+	for _, g := range k.SecretGenerator {
+		gens = append(gens, generator{"secretGenerator", "Secret", g})
+		if len(g.Literals) > 0 {
+			b.add(file, finding.Warning, "secretGenerator '%s' has literal values committed with the kustomization; generate the Secret from a file kept out of the repository or use an external secret store", g.Name)
+		}
+	}
+
+	for _, entry := range gens {
+		g := entry.Generator
+		data, err := generatorData(dir, g)
+		if err != nil {
+			return nil, fmt.Errorf("%s '%s': %v", entry.field, g.Name, err)
+		}
+		if entry.kind == "Secret" {
+			for key, v := range data {
+				data[key] = base64.StdEncoding.EncodeToString([]byte(v.(string)))
+			}
+		}
+
+		var existing *resource
+		for _, r := range resources {
+			if kind(r.obj) == entry.kind && (r.origName == g.Name || name(r.obj) == g.Name) {
+				existing = r
+				break
+			}
+		}
+		switch g.Behavior {
+		case "merge", "replace":
+			if existing == nil {
+				return nil, fmt.Errorf("%s '%s' has behavior %s but no base generates it", entry.kind, g.Name, g.Behavior)
+			}
+			merged := data
+			if g.Behavior == "merge" {
+				merged = child(existing.obj, "data", true)
+				for key, v := range data {
+					merged[key] = v
+				}
+			}
+			existing.obj["data"] = merged
+			continue
+		}
+
+		obj := map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       entry.kind,
+			"metadata":   map[string]interface{}{"name": g.Name},
+			"data":       data,
+		}
+		if g.Namespace != "" {
+			child(obj, "metadata", false)["namespace"] = g.Namespace
+		}
+		if entry.kind == "Secret" {
+			obj["type"] = "Opaque"
+			if g.Type != "" {
+				obj["type"] = g.Type
+			}
+		}
+		hashed := true
+		for _, opts := range []*GeneratorOptions{k.GeneratorOptions, g.Options} {
+			if opts == nil {
+				continue
+			}
+			hashed = hashed && !opts.DisableNameSuffixHash
+			addStrings(child(obj, "metadata", false), "labels", opts.Labels)
+			addStrings(child(obj, "metadata", false), "annotations", opts.Annotations)
+		}
+		resources = append(resources, &resource{obj: obj, origName: g.Name, hashed: hashed})
+	}
+	return resources, nil
+}
+
+// generatorData reads the key-value pairs of a generator from its literals, files and env files.
+func generatorData(dir string, g Generator) (map[string]interface{}, error) {
+	data := make(map[string]interface{})
+	for _, lit := range g.Literals {
+		key, value, ok := strings.Cut(lit, "=")
+		if !ok {
+			return nil, fmt.Errorf("literal '%s' is not key=value", lit)
+		}
+		data[key] = strings.Trim(value, `"'`)
+	}
+	for _, f := range g.Files {
+		key, path, ok := strings.Cut(f, "=")
+		if !ok {
+			key, path = filepath.Base(f), f
+		}
+		content, err := os.ReadFile(filepath.Join(dir, path))
+		if err != nil {
+			return nil, err
+		}
+		data[key] = string(content)
+	}
+	envs := g.Envs
+	if g.Env != "" {
+		envs = append(envs, g.Env)
+	}
+	for _, f := range envs {
+		content, err := os.ReadFile(filepath.Join(dir, f))
+		if err != nil {
+			return nil, err
+		}
+		lines := bufio.NewScanner(bytes.NewReader(content))
+		for lines.Scan() {
+			line := strings.TrimSpace(lines.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			if key, value, ok := strings.Cut(line, "="); ok {
+				data[key] = value
+			}
+		}
+	}
+	return data, nil
+}
+
+// hashNames appends a hash of their content to the names of generated resources, as
+// kustomize does so that changing a ConfigMap rolls the workloads using it, and updates the
+// references to them.
+func hashNames(resources []*resource) {
+	renames := make(map[string]map[string]string)
+	for _, r := range resources {
+		if !r.hashed {
+			continue
+		}
+		content, _ := json.Marshal(map[string]interface{}{"kind": kind(r.obj), "name": name(r.obj), "data": r.obj["data"], "type": r.obj["type"]})
+		sum := sha256.Sum256(content)
+		old := name(r.obj)
+		renamed := old + "-" + hex.EncodeToString(sum[:])[:10]
+		child(r.obj, "metadata", false)["name"] = renamed
+		if renames[kind(r.obj)] == nil {
+			renames[kind(r.obj)] = make(map[string]string)
+		}
+		renames[kind(r.obj)][old] = renamed
+	}
+	renameReferences(resources, renames)
+}
+
+// addStrings adds values to the string map m[key]; a nil m is left alone.
+func addStrings(m map[string]interface{}, key string, values map[string]string) {
+	if m == nil || len(values) == 0 {
+		return
+	}
+	target := child(m, key, true)
+	for k, v := range values {
+		target[k] = v
+	}
+}
@@ -0,0 +1,351 @@
+// builds kustomizations the way `kustomize build` does and scans the resulting manifests
+package kustomize
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/kubernetes"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// Names kustomize looks for in a directory, in its order of preference
+var kustomizationFiles = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+// Kustomization is the subset of kustomization.yaml the build supports.
+type Kustomization struct {
+	Resources  []string `yaml:"resources"`
+	Bases      []string `yaml:"bases"`
+	Components []string `yaml:"components"`
+
+	Namespace         string            `yaml:"namespace"`
+	NamePrefix        string            `yaml:"namePrefix"`
+	NameSuffix        string            `yaml:"nameSuffix"`
+	CommonLabels      map[string]string `yaml:"commonLabels"`
+	Labels            []Labels          `yaml:"labels"`
+	CommonAnnotations map[string]string `yaml:"commonAnnotations"`
+	Images            []Image           `yaml:"images"`
+	Replicas          []Replica         `yaml:"replicas"`
+
+	Patches               []Patch  `yaml:"patches"`
+	PatchesStrategicMerge []string `yaml:"patchesStrategicMerge"`
+	PatchesJSON6902       []Patch  `yaml:"patchesJson6902"`
+
+	ConfigMapGenerator []Generator       `yaml:"configMapGenerator"`
+	SecretGenerator    []Generator       `yaml:"secretGenerator"`
+	GeneratorOptions   *GeneratorOptions `yaml:"generatorOptions"`
+	HelmCharts         []interface{}     `yaml:"helmCharts"`
+}
+
+// Labels is an entry of labels: pairs added to metadata, and optionally to selectors and
+// pod templates.
+type Labels struct {
+	Pairs            map[string]string `yaml:"pairs"`
+	IncludeSelectors bool              `yaml:"includeSelectors"`
+	IncludeTemplates bool              `yaml:"includeTemplates"`
+}
+
+// Image rewrites the name, tag or digest of the container images called Name.
+type Image struct {
+	Name    string `yaml:"name"`
+	NewName string `yaml:"newName"`
+	NewTag  string `yaml:"newTag"`
+	Digest  string `yaml:"digest"`
+}
+
+// Replica sets the replicas of the workloads called Name.
+type Replica struct {
+	Name  string `yaml:"name"`
+	Count int    `yaml:"count"`
+}
+
+// resource is an object being built, with the name it was loaded with, which patches and
+// overlays may still refer to it by.
+type resource struct {
+	obj      map[string]interface{}
+	origName string
+	hashed   bool // generated with a content hash to append to its name
+}
+
+// Scan builds the kustomization in path, or every kustomization under path that no other
+// one there builds on, and checks the resulting objects. Findings are reported on the
+// kustomization file of the build they come from.
+func Scan(path string) ([]finding.Finding, error) {
+	if file := kustomizationFile(path); file != "" {
+		return scanBuild(path, file), nil
+	}
+	var files []string
+	err := scanner.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		files = append(files, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var findings []finding.Finding
+	for _, dir := range topLevel(files) {
+		findings = append(findings, scanBuild(dir, kustomizationFile(dir))...)
+	}
+	return findings, nil
+}
+
+// scanBuild builds the kustomization in dir and checks its objects; a failing build is an
+// error on the kustomization file.
+func scanBuild(dir, file string) []finding.Finding {
+	out, findings, err := build(dir)
+	if err != nil {
+		return append(findings, finding.Finding{
+			File:     file,
+			Severity: finding.Error,
+			Message:  fmt.Sprintf("Failed to build kustomization: %v", err),
+		})
+	}
+	return append(findings, kubernetes.ScanManifest(file, out)...)
+}
+
+// Build builds the kustomization in dir and returns the resulting objects as a stream of
+// YAML documents.
+func Build(dir string) ([]byte, error) {
+	out, _, err := build(dir)
+	return out, err
+}
+
+// build is Build, also returning the findings about the kustomizations read on the way.
+func build(dir string) ([]byte, []finding.Finding, error) {
+	b := &builder{visiting: make(map[string]bool)}
+	resources, err := b.kustomize(dir, nil)
+	if err != nil {
+		return nil, b.findings, err
+	}
+	hashNames(resources)
+	var out bytes.Buffer
+	for _, r := range resources {
+		data, err := yaml.Marshal(r.obj)
+		if err != nil {
+			continue
+		}
+		out.WriteString("---\n")
+		out.Write(data)
+	}
+	return out.Bytes(), b.findings, nil
+}
+
+// kustomizationFile returns the kustomization file in dir, or "" when it has none.
+func kustomizationFile(dir string) string {
+	for _, name := range kustomizationFiles {
+		p := filepath.Join(dir, name)
+		if info, err := os.Stat(p); err == nil && !info.IsDir() {
+			return p
+		}
+	}
+	return ""
+}
+
+// IsKustomization reports whether path is named like a kustomization file.
+func IsKustomization(path string) bool {
+	base := filepath.Base(path)
+	for _, name := range kustomizationFiles {
+		if base == name {
+			return true
+		}
+	}
+	return false
+}
+
+// topLevel returns the directories of the kustomizations among files that no other one
+// among them lists as a resource, base or component, i.e. the overlays that get applied.
+func topLevel(files []string) []string {
+	var dirs []string
+	referenced := make(map[string]bool)
+	for _, p := range files {
+		if !IsKustomization(p) {
+			continue
+		}
+		dir := filepath.Dir(p)
+		if kustomizationFile(dir) != p {
+			continue // a directory with two kustomization files builds the preferred one
+		}
+		dirs = append(dirs, dir)
+		k, err := load(p)
+		if err != nil {
+			continue
+		}
+		for _, entry := range append(append(append([]string(nil), k.Resources...), k.Bases...), k.Components...) {
+			if !remote(entry) {
+				referenced[filepath.Clean(filepath.Join(dir, entry))] = true
+			}
+		}
+	}
+	var out []string
+	for _, dir := range dirs {
+		if !referenced[filepath.Clean(dir)] {
+			out = append(out, dir)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func load(file string) (*Kustomization, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var k Kustomization
+	if err := yaml.Unmarshal(data, &k); err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	return &k, nil
+}
+
+// remote reports whether a resource entry is a URL or git repository rather than a path.
+func remote(entry string) bool {
+	return strings.Contains(entry, "://") || strings.HasPrefix(entry, "git@") || strings.HasPrefix(entry, "github.com/")
+}
+
+type builder struct {
+	visiting map[string]bool
+	findings []finding.Finding
+}
+
+func (b *builder) add(file string, sev finding.Severity, format string, args ...interface{}) {
+	b.findings = append(b.findings, finding.Finding{File: file, Severity: sev, Message: fmt.Sprintf(format, args...)})
+}
+
+// kustomize applies the kustomization in dir to resources, the accumulated resources of the
+// kustomization a component belongs to, or nil: its resources and components are added, its
+// generators run, and its patches and transformers applied.
+func (b *builder) kustomize(dir string, resources []*resource) ([]*resource, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if b.visiting[abs] {
+		return nil, fmt.Errorf("%s is part of a cycle of kustomizations", dir)
+	}
+	b.visiting[abs] = true
+	defer delete(b.visiting, abs)
+
+	file := kustomizationFile(dir)
+	if file == "" {
+		return nil, fmt.Errorf("%s holds no kustomization.yaml", dir)
+	}
+	k, err := load(file)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range append(append([]string(nil), k.Resources...), k.Bases...) {
+		if remote(entry) {
+			b.add(file, finding.Info, "Remote resource '%s' is not fetched, so the objects it adds are not scanned", entry)
+			if !strings.Contains(entry, "ref=") {
+				b.add(file, finding.Warning, "Remote resource '%s' is not pinned with ?ref= to a tag or commit", entry)
+			}
+			continue
+		}
+		p := filepath.Join(dir, entry)
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, fmt.Errorf("resource %s: %v", entry, err)
+		}
+		if info.IsDir() {
+			sub, err := b.kustomize(p, nil)
+			if err != nil {
+				return nil, err
+			}
+			resources = append(resources, sub...)
+			continue
+		}
+		loaded, err := readResources(p)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, loaded...)
+	}
+	for _, entry := range k.Components {
+		if resources, err = b.kustomize(filepath.Join(dir, entry), resources); err != nil {
+			return nil, err
+		}
+	}
+	if len(k.HelmCharts) > 0 {
+		b.add(file, finding.Info, "helmCharts are not inflated, so the objects they add are not scanned; scan the charts with 'scan helm'")
+	}
+
+	if resources, err = b.generate(dir, file, k, resources); err != nil {
+		return nil, err
+	}
+	if resources, err = patch(dir, k, resources); err != nil {
+		return nil, err
+	}
+	transform(k, resources)
+	return resources, nil
+}
+
+// readResources reads the objects of a (possibly multi-document) manifest, expanding lists.
+func readResources(file string) ([]*resource, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var resources []*resource
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var obj map[string]interface{}
+		err := dec.Decode(&obj)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		if obj == nil {
+			continue
+		}
+		if items, ok := obj["items"].([]interface{}); ok && strings.HasSuffix(str(obj, "kind"), "List") {
+			for _, item := range items {
+				if m, ok := item.(map[string]interface{}); ok {
+					resources = append(resources, &resource{obj: m, origName: name(m)})
+				}
+			}
+			continue
+		}
+		resources = append(resources, &resource{obj: obj, origName: name(obj)})
+	}
+	return resources, nil
+}
+
+func str(m map[string]interface{}, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+// child returns m[key] as a map, creating it when create is set.
+func child(m map[string]interface{}, key string, create bool) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	c, ok := m[key].(map[string]interface{})
+	if !ok && create {
+		c = make(map[string]interface{})
+		m[key] = c
+	}
+	return c
+}
+
+func kind(obj map[string]interface{}) string { return str(obj, "kind") }
+func name(obj map[string]interface{}) string { return str(child(obj, "metadata", false), "name") }
+func namespace(obj map[string]interface{}) string {
+	return str(child(obj, "metadata", false), "namespace")
+}
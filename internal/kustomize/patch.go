@@ -0,0 +1,436 @@
+package kustomize
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Patch is an entry of patches or patchesJson6902: a strategic merge patch or JSON patch,
+// inline or in a file, applied to the resources Target selects or, for a strategic merge
+// patch without a target, to the resource it names.
+type Patch struct {
+	Path   string    `yaml:"path"`
+	Patch  string    `yaml:"patch"`
+	Target *Selector `yaml:"target"`
+}
+
+// Selector selects resources by group, version, kind, name and namespace, which are regular
+// expressions, and by label and annotation selectors.
+type Selector struct {
+	Group              string `yaml:"group"`
+	Version            string `yaml:"version"`
+	Kind               string `yaml:"kind"`
+	Name               string `yaml:"name"`
+	Namespace          string `yaml:"namespace"`
+	LabelSelector      string `yaml:"labelSelector"`
+	AnnotationSelector string `yaml:"annotationSelector"`
+}
+
+// The list fields strategic merge patches merge items of by a key instead of replacing them
+var mergeKeys = map[string]string{
+	"containers":          "name",
+	"initContainers":      "name",
+	"ephemeralContainers": "name",
+	"volumes":             "name",
+	"env":                 "name",
+	"imagePullSecrets":    "name",
+	"volumeMounts":        "mountPath",
+	"ports":               "containerPort",
+}
+
+// patch applies k's patches to resources.
+func patch(dir string, k *Kustomization, resources []*resource) ([]*resource, error) {
+	var patches []Patch
+	for _, p := range k.PatchesStrategicMerge {
+		if strings.Contains(p, "\n") {
+			patches = append(patches, Patch{Patch: p})
+		} else {
+			patches = append(patches, Patch{Path: p})
+		}
+	}
+	patches = append(append(patches, k.PatchesJSON6902...), k.Patches...)
+
+	for _, p := range patches {
+		src, what := p.Patch, "inline patch"
+		if p.Path != "" {
+			data, err := os.ReadFile(filepath.Join(dir, p.Path))
+			if err != nil {
+				return nil, fmt.Errorf("patch %s: %v", p.Path, err)
+			}
+			src, what = string(data), "patch "+p.Path
+		}
+		var doc interface{}
+		if err := yaml.Unmarshal([]byte(src), &doc); err != nil {
+			return nil, fmt.Errorf("%s: %v", what, err)
+		}
+
+		var err error
+		switch doc := doc.(type) {
+		case []interface{}:
+			if p.Target == nil {
+				return nil, fmt.Errorf("%s is a JSON patch and needs a target", what)
+			}
+			for _, r := range resources {
+				if p.Target.matches(r) {
+					if r.obj, err = jsonPatch(r.obj, doc); err != nil {
+						return nil, fmt.Errorf("%s: %v", what, err)
+					}
+				}
+			}
+		case map[string]interface{}:
+			resources, err = strategicPatch(resources, doc, p.Target)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", what, err)
+			}
+		default:
+			return nil, fmt.Errorf("%s is neither a strategic merge patch nor a JSON patch", what)
+		}
+	}
+	return resources, nil
+}
+
+// strategicPatch merges doc into the resources target selects, or the one doc names.
+func strategicPatch(resources []*resource, doc map[string]interface{}, target *Selector) ([]*resource, error) {
+	matched := false
+	var out []*resource
+	for _, r := range resources {
+		if target != nil && !target.matches(r) ||
+			target == nil && (kind(r.obj) != kind(doc) || r.origName != name(doc) && name(r.obj) != name(doc) ||
+				namespace(doc) != "" && namespace(doc) != namespace(r.obj)) {
+			out = append(out, r)
+			continue
+		}
+		matched = true
+		if str(doc, "$patch") == "delete" {
+			continue
+		}
+		patch := deepCopy(doc).(map[string]interface{})
+		if target != nil {
+			// a targeted patch applies whatever the resource is called
+			delete(child(patch, "metadata", false), "name")
+			delete(child(patch, "metadata", false), "namespace")
+		}
+		r.obj = mergePatch(r.obj, patch)
+		out = append(out, r)
+	}
+	if !matched && target == nil {
+		return nil, fmt.Errorf("no resource %s '%s' to patch", kind(doc), name(doc))
+	}
+	return out, nil
+}
+
+// mergePatch merges patch into dst as a strategic merge patch: maps merge, the lists in
+// mergeKeys merge by key, other values replace, null deletes and $patch: delete and replace
+// directives apply.
+func mergePatch(dst, patch map[string]interface{}) map[string]interface{} {
+	if str(patch, "$patch") == "replace" {
+		delete(patch, "$patch")
+		return patch
+	}
+	for key, pv := range patch {
+		if strings.HasPrefix(key, "$") {
+			continue
+		}
+		switch pv := pv.(type) {
+		case nil:
+			delete(dst, key)
+		case map[string]interface{}:
+			if str(pv, "$patch") == "delete" {
+				delete(dst, key)
+			} else if dv, ok := dst[key].(map[string]interface{}); ok {
+				dst[key] = mergePatch(dv, pv)
+			} else {
+				dst[key] = pv
+			}
+		case []interface{}:
+			dl, ok := dst[key].([]interface{})
+			if mk, merges := mergeKeys[key]; merges && ok {
+				dst[key] = mergeList(dl, pv, mk)
+			} else {
+				dst[key] = pv
+			}
+		default:
+			dst[key] = pv
+		}
+	}
+	return dst
+}
+
+// mergeList merges the items of patch into dst by their key field.
+func mergeList(dst, patch []interface{}, key string) []interface{} {
+	for _, item := range patch {
+		pm, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		at := -1
+		for i, d := range dst {
+			if dm, ok := d.(map[string]interface{}); ok && fmt.Sprint(dm[key]) == fmt.Sprint(pm[key]) {
+				at = i
+				break
+			}
+		}
+		switch {
+		case str(pm, "$patch") == "delete":
+			if at >= 0 {
+				dst = append(dst[:at], dst[at+1:]...)
+			}
+		case at >= 0:
+			dst[at] = mergePatch(dst[at].(map[string]interface{}), pm)
+		default:
+			dst = append(dst, pm)
+		}
+	}
+	return dst
+}
+
+// jsonPatch applies the RFC 6902 operations ops to obj.
+func jsonPatch(obj map[string]interface{}, ops []interface{}) (map[string]interface{}, error) {
+	var doc interface{} = obj
+	for _, o := range ops {
+		op, _ := o.(map[string]interface{})
+		kind, path, from := str(op, "op"), str(op, "path"), str(op, "from")
+		tokens, err := pointer(path)
+		if err != nil {
+			return nil, err
+		}
+		switch kind {
+		case "add", "replace":
+			doc, err = patchAt(doc, tokens, func(c interface{}, key string) (interface{}, error) {
+				return setAt(c, key, deepCopy(op["value"]), kind == "add")
+			})
+		case "remove":
+			doc, err = patchAt(doc, tokens, removeAt)
+		case "move", "copy":
+			var fromTokens []string
+			if fromTokens, err = pointer(from); err != nil {
+				return nil, err
+			}
+			var value interface{}
+			if value, err = getAt(doc, fromTokens); err != nil {
+				break
+			}
+			value = deepCopy(value)
+			if kind == "move" {
+				if doc, err = patchAt(doc, fromTokens, removeAt); err != nil {
+					break
+				}
+			}
+			doc, err = patchAt(doc, tokens, func(c interface{}, key string) (interface{}, error) {
+				return setAt(c, key, value, true)
+			})
+		case "test":
+			var value interface{}
+			if value, err = getAt(doc, tokens); err == nil && !reflect.DeepEqual(value, op["value"]) {
+				err = fmt.Errorf("test of %s failed", path)
+			}
+		default:
+			err = fmt.Errorf("unknown JSON patch op '%s'", kind)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	patched, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("patch replaced the object with a non-object")
+	}
+	return patched, nil
+}
+
+// pointer splits a JSON pointer into its unescaped tokens.
+func pointer(path string) ([]string, error) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("JSON pointer '%s' does not start with /", path)
+	}
+	tokens := strings.Split(path[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// patchAt applies op to the container holding the value tokens point at, and returns node
+// with the container op returned in its place.
+func patchAt(node interface{}, tokens []string, op func(container interface{}, key string) (interface{}, error)) (interface{}, error) {
+	if len(tokens) == 1 {
+		return op(node, tokens[0])
+	}
+	switch n := node.(type) {
+	case map[string]interface{}:
+		c, ok := n[tokens[0]]
+		if !ok {
+			return nil, fmt.Errorf("path segment '%s' not found", tokens[0])
+		}
+		c, err := patchAt(c, tokens[1:], op)
+		n[tokens[0]] = c
+		return n, err
+	case []interface{}:
+		i, err := index(tokens[0], len(n), false)
+		if err != nil {
+			return nil, err
+		}
+		c, err := patchAt(n[i], tokens[1:], op)
+		n[i] = c
+		return n, err
+	}
+	return nil, fmt.Errorf("path segment '%s' is below a scalar", tokens[0])
+}
+
+func getAt(node interface{}, tokens []string) (interface{}, error) {
+	for _, t := range tokens {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			next, ok := n[t]
+			if !ok {
+				return nil, fmt.Errorf("path segment '%s' not found", t)
+			}
+			node = next
+		case []interface{}:
+			i, err := index(t, len(n), false)
+			if err != nil {
+				return nil, err
+			}
+			node = n[i]
+		default:
+			return nil, fmt.Errorf("path segment '%s' is below a scalar", t)
+		}
+	}
+	return node, nil
+}
+
+// setAt sets key of container to value, inserting into lists when insert is set.
+func setAt(container interface{}, key string, value interface{}, insert bool) (interface{}, error) {
+	switch c := container.(type) {
+	case map[string]interface{}:
+		if _, ok := c[key]; !ok && !insert {
+			return nil, fmt.Errorf("cannot replace missing key '%s'", key)
+		}
+		c[key] = value
+		return c, nil
+	case []interface{}:
+		i, err := index(key, len(c), insert)
+		if err != nil {
+			return nil, err
+		}
+		if !insert {
+			c[i] = value
+			return c, nil
+		}
+		return append(c[:i], append([]interface{}{value}, c[i:]...)...), nil
+	}
+	return nil, fmt.Errorf("cannot set '%s' below a scalar", key)
+}
+
+func removeAt(container interface{}, key string) (interface{}, error) {
+	switch c := container.(type) {
+	case map[string]interface{}:
+		if _, ok := c[key]; !ok {
+			return nil, fmt.Errorf("cannot remove missing key '%s'", key)
+		}
+		delete(c, key)
+		return c, nil
+	case []interface{}:
+		i, err := index(key, len(c), false)
+		if err != nil {
+			return nil, err
+		}
+		return append(c[:i], c[i+1:]...), nil
+	}
+	return nil, fmt.Errorf("cannot remove '%s' below a scalar", key)
+}
+
+// index parses a list index; "-", and the length, are valid for inserting at the end.
+func index(token string, length int, insert bool) (int, error) {
+	if token == "-" && insert {
+		return length, nil
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > length || i == length && !insert {
+		return 0, fmt.Errorf("index '%s' out of range", token)
+	}
+	return i, nil
+}
+
+// matches reports whether s selects r, by the current or the original name.
+func (s *Selector) matches(r *resource) bool {
+	group, version := "", str(r.obj, "apiVersion")
+	if i := strings.Index(version, "/"); i >= 0 {
+		group, version = version[:i], version[i+1:]
+	}
+	if !matchPattern(s.Group, group) || !matchPattern(s.Version, version) || !matchPattern(s.Kind, kind(r.obj)) ||
+		!matchPattern(s.Namespace, namespace(r.obj)) {
+		return false
+	}
+	if s.Name != "" && !matchPattern(s.Name, name(r.obj)) && !matchPattern(s.Name, r.origName) {
+		return false
+	}
+	meta := child(r.obj, "metadata", false)
+	return matchSelector(s.LabelSelector, child(meta, "labels", false)) &&
+		matchSelector(s.AnnotationSelector, child(meta, "annotations", false))
+}
+
+// matchPattern matches value against the regular expression pattern as a whole; an empty
+// pattern matches anything.
+func matchPattern(pattern, value string) bool {
+	if pattern == "" {
+		return true
+	}
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return pattern == value
+	}
+	return re.MatchString(value)
+}
+
+// matchSelector matches a label selector of comma-separated key=value, key!=value and key
+// requirements.
+func matchSelector(selector string, labels map[string]interface{}) bool {
+	for _, req := range strings.Split(selector, ",") {
+		req = strings.TrimSpace(req)
+		if req == "" {
+			continue
+		}
+		if key, value, ok := strings.Cut(req, "!="); ok {
+			if fmt.Sprint(labels[strings.TrimSpace(key)]) == strings.TrimSpace(value) {
+				return false
+			}
+			continue
+		}
+		if key, value, ok := strings.Cut(strings.Replace(req, "==", "=", 1), "="); ok {
+			if v, ok := labels[strings.TrimSpace(key)]; !ok || fmt.Sprint(v) != strings.TrimSpace(value) {
+				return false
+			}
+			continue
+		}
+		if _, ok := labels[req]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func deepCopy(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[k] = deepCopy(e)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, e := range v {
+			l[i] = deepCopy(e)
+		}
+		return l
+	}
+	return v
+}
@@ -0,0 +1,28 @@
+package kustomize
+
+import (
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// kustomizeScanner builds the Kustomize overlays in a tree and scans the manifests they
+// produce; the kubernetes scanner leaves the files of kustomization directories to it.
+type kustomizeScanner struct{}
+
+func init() {
+	scanner.Register(kustomizeScanner{})
+}
+
+func (kustomizeScanner) Name() string { return "kustomize" }
+func (kustomizeScanner) Description() string {
+	return "Build Kustomize overlays and scan the resulting manifests"
+}
+func (kustomizeScanner) FileMatcher() scanner.FileMatcher {
+	return func(path string) bool {
+		return IsKustomization(path) || scanner.Extensions(".yml", ".yaml")(path)
+	}
+}
+func (kustomizeScanner) Scan(path string) ([]finding.Finding, error) { return Scan(path) }
+
+// Targets returns the directories of the top-level kustomizations among files.
+func (kustomizeScanner) Targets(root string, files []string) []string { return topLevel(files) }
@@ -0,0 +1,38 @@
+package kustomize
+
+import (
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/registry"
+)
+
+func init() {
+	scanners := []string{"kustomize"}
+	registry.Register(
+		registry.Rule{
+			ID: "KUS001", Name: "Literal secretGenerator values", Severity: finding.Warning, Category: "secrets", Scanners: scanners,
+			Compliance:  registry.HardcodedSecrets,
+			Description: "A secretGenerator has literals committed with the kustomization instead of reading a file kept out of the repository.",
+			Messages:    []string{"secretGenerator '*' has literal values committed with the kustomization; *"},
+			Remediation: `secretGenerator:
+  - name: db
+    envs:
+      - db.env # kept out of the repository`,
+		},
+		registry.Rule{
+			ID: "KUS002", Name: "Unpinned remote resource", Severity: finding.Warning, Category: "supply-chain", Scanners: scanners,
+			Compliance:  registry.SupplyChain,
+			Description: "A remote resource is not pinned with ?ref= to a tag or commit, so its content can change under the kustomization.",
+			Messages:    []string{"Remote resource '*' is not pinned with ?ref= to a tag or commit"},
+			Remediation: `resources:
+  - https://github.com/acme/platform//base?ref=v1.8.0`,
+		},
+		registry.Rule{
+			ID: "KUS003", Name: "Content not scanned", Severity: finding.Info, Category: "coverage", Scanners: scanners,
+			Description: "A remote resource or helmCharts entry adds objects the scan cannot see.",
+			Messages:    []string{"Remote resource '*' is not fetched, *", "helmCharts are not inflated, *"},
+			Remediation: `# vendor the remote content so it is scanned with the rest
+resources:
+  - vendor/platform-base`,
+		},
+	)
+}
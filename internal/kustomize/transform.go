@@ -0,0 +1,212 @@
+package kustomize
+
+import "strings"
+
+// Kinds that are not namespaced, which namespace leaves alone
+var clusterScoped = map[string]bool{
+	"Namespace":                      true,
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"CustomResourceDefinition":       true,
+	"PersistentVolume":               true,
+	"StorageClass":                   true,
+	"PriorityClass":                  true,
+	"IngressClass":                   true,
+	"RuntimeClass":                   true,
+	"PodSecurityPolicy":              true,
+	"MutatingWebhookConfiguration":   true,
+	"ValidatingWebhookConfiguration": true,
+	"APIService":                     true,
+}
+
+// Workload kinds whose selectors and pod templates get labels
+var workloads = map[string]bool{
+	"Deployment":            true,
+	"StatefulSet":           true,
+	"DaemonSet":             true,
+	"ReplicaSet":            true,
+	"Job":                   true,
+	"CronJob":               true,
+	"ReplicationController": true,
+}
+
+// transform applies k's namespace, name prefix and suffix, labels, annotations, images and
+// replicas to resources.
+func transform(k *Kustomization, resources []*resource) {
+	renames := make(map[string]map[string]string)
+	for _, r := range resources {
+		meta := child(r.obj, "metadata", true)
+		if k.Namespace != "" && !clusterScoped[kind(r.obj)] {
+			meta["namespace"] = k.Namespace
+		}
+		if (k.NamePrefix != "" || k.NameSuffix != "") && kind(r.obj) != "Namespace" && kind(r.obj) != "CustomResourceDefinition" {
+			old := name(r.obj)
+			meta["name"] = k.NamePrefix + old + k.NameSuffix
+			if kind(r.obj) == "ConfigMap" || kind(r.obj) == "Secret" {
+				if renames[kind(r.obj)] == nil {
+					renames[kind(r.obj)] = make(map[string]string)
+				}
+				renames[kind(r.obj)][old] = name(r.obj)
+			}
+		}
+
+		addStrings(meta, "labels", k.CommonLabels)
+		if len(k.CommonLabels) > 0 {
+			addSelectorLabels(r.obj, k.CommonLabels)
+			addStrings(template(r.obj), "labels", k.CommonLabels)
+		}
+		for _, l := range k.Labels {
+			addStrings(meta, "labels", l.Pairs)
+			if l.IncludeSelectors {
+				addSelectorLabels(r.obj, l.Pairs)
+			}
+			if l.IncludeSelectors || l.IncludeTemplates {
+				addStrings(template(r.obj), "labels", l.Pairs)
+			}
+		}
+		addStrings(meta, "annotations", k.CommonAnnotations)
+		addStrings(template(r.obj), "annotations", k.CommonAnnotations)
+
+		for _, c := range containers(r.obj) {
+			for _, img := range k.Images {
+				if image, ok := c["image"].(string); ok && imageName(image) == img.Name {
+					c["image"] = rewriteImage(image, img)
+				}
+			}
+		}
+		for _, rep := range k.Replicas {
+			if workloads[kind(r.obj)] && kind(r.obj) != "Job" && kind(r.obj) != "CronJob" &&
+				(name(r.obj) == rep.Name || r.origName == rep.Name) {
+				child(r.obj, "spec", true)["replicas"] = rep.Count
+			}
+		}
+	}
+	renameReferences(resources, renames)
+}
+
+// addSelectorLabels adds labels to the selector of a workload or Service.
+func addSelectorLabels(obj map[string]interface{}, labels map[string]string) {
+	switch {
+	case kind(obj) == "Service":
+		addStrings(child(obj, "spec", true), "selector", labels)
+	case workloads[kind(obj)] && kind(obj) != "CronJob":
+		spec := child(obj, "spec", true)
+		if kind(obj) == "ReplicationController" {
+			addStrings(spec, "selector", labels)
+		} else {
+			addStrings(child(spec, "selector", true), "matchLabels", labels)
+		}
+	}
+}
+
+// podTemplate returns the pod template of a workload, or nil.
+func podTemplate(obj map[string]interface{}) map[string]interface{} {
+	if !workloads[kind(obj)] {
+		return nil
+	}
+	spec := child(obj, "spec", false)
+	if kind(obj) == "CronJob" {
+		spec = child(child(spec, "jobTemplate", false), "spec", false)
+	}
+	return child(spec, "template", false)
+}
+
+// template returns the metadata of the pod template of a workload, or nil.
+func template(obj map[string]interface{}) map[string]interface{} {
+	if t := podTemplate(obj); t != nil {
+		return child(t, "metadata", true)
+	}
+	return nil
+}
+
+// podSpec returns the pod spec of a Pod or workload, or nil.
+func podSpec(obj map[string]interface{}) map[string]interface{} {
+	if kind(obj) == "Pod" {
+		return child(obj, "spec", false)
+	}
+	return child(podTemplate(obj), "spec", false)
+}
+
+// containers returns the containers and init containers of a Pod or workload.
+func containers(obj map[string]interface{}) []map[string]interface{} {
+	spec := podSpec(obj)
+	var out []map[string]interface{}
+	for _, field := range []string{"initContainers", "containers"} {
+		list, _ := spec[field].([]interface{})
+		for _, c := range list {
+			if m, ok := c.(map[string]interface{}); ok {
+				out = append(out, m)
+			}
+		}
+	}
+	return out
+}
+
+// imageName strips the tag and digest from an image reference.
+func imageName(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
+
+// rewriteImage applies img's new name, tag or digest to image.
+func rewriteImage(image string, img Image) string {
+	name, ref := imageName(image), image[len(imageName(image)):]
+	if img.NewName != "" {
+		name = img.NewName
+	}
+	switch {
+	case img.Digest != "":
+		ref = "@" + img.Digest
+	case img.NewTag != "":
+		ref = ":" + img.NewTag
+	}
+	return name + ref
+}
+
+// renameReferences points the volumes, envFrom and env of pods at the new names of the
+// ConfigMaps and Secrets in renames, by kind and old name.
+func renameReferences(resources []*resource, renames map[string]map[string]string) {
+	if len(renames) == 0 {
+		return
+	}
+	rename := func(m map[string]interface{}, key, kind string) {
+		if n, ok := renames[kind][str(m, key)]; ok && m != nil {
+			m[key] = n
+		}
+	}
+	for _, r := range resources {
+		spec := podSpec(r.obj)
+		volumes, _ := spec["volumes"].([]interface{})
+		for _, v := range volumes {
+			vol, _ := v.(map[string]interface{})
+			rename(child(vol, "configMap", false), "name", "ConfigMap")
+			rename(child(vol, "secret", false), "secretName", "Secret")
+			sources, _ := child(vol, "projected", false)["sources"].([]interface{})
+			for _, s := range sources {
+				src, _ := s.(map[string]interface{})
+				rename(child(src, "configMap", false), "name", "ConfigMap")
+				rename(child(src, "secret", false), "name", "Secret")
+			}
+		}
+		for _, c := range containers(r.obj) {
+			envFrom, _ := c["envFrom"].([]interface{})
+			for _, e := range envFrom {
+				ef, _ := e.(map[string]interface{})
+				rename(child(ef, "configMapRef", false), "name", "ConfigMap")
+				rename(child(ef, "secretRef", false), "name", "Secret")
+			}
+			env, _ := c["env"].([]interface{})
+			for _, e := range env {
+				ev, _ := e.(map[string]interface{})
+				from := child(ev, "valueFrom", false)
+				rename(child(from, "configMapKeyRef", false), "name", "ConfigMap")
+				rename(child(from, "secretKeyRef", false), "name", "Secret")
+			}
+		}
+	}
+}
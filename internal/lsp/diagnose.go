@@ -0,0 +1,156 @@
+package lsp
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/config"
+	"github.com/salchaD-27/infra-check/internal/engine"
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/rules"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// Sibling files larger than this are not copied into an overlay: they are rarely
+// configuration, and copying them on every keystroke would make diagnostics lag.
+const maxSiblingSize = 1 << 20
+
+// Diagnose scans the unsaved content of the file at path with every scanner that reads
+// it and returns the findings on that file. Scanners only read from disk, so each scan
+// runs on an overlay: a temporary copy of what the scanner would read for path, with
+// content in place of the file on disk. A scanner failing does not keep the others'
+// findings back; its error is returned along with them.
+func Diagnose(path, content string, custom []rules.Rule, cfg *config.Config) ([]finding.Finding, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if scanner.Excluded(path, false) {
+		return nil, nil
+	}
+	var findings []finding.Finding
+	var firstErr error
+	for _, s := range scanner.All() {
+		if !s.FileMatcher()(path) {
+			continue
+		}
+		target := path
+		recursive := false
+		if _, ok := s.(scanner.TargetDetector); ok {
+			// a playbook or manifest is its own target; a file such as Chart.yaml stands for
+			// its directory; the files the scanner would not pick at all are skipped
+			targets := scanner.Targets(s, path, []string{path})
+			if len(targets) == 0 {
+				continue
+			}
+			target, recursive = targets[0], targets[0] != path
+		} else {
+			// scanners walking trees check a file together with its siblings, e.g. the
+			// variables and outputs of a Terraform module
+			target = filepath.Dir(path)
+		}
+
+		found, err := scanOverlay(s, path, content, target, recursive, custom)
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("%s: %v", s.Name(), err)
+		}
+		findings = append(findings, found...)
+	}
+	return engine.Process(findings, cfg), firstErr
+}
+
+// scanOverlay copies target to a temporary directory with content as path, scans the
+// copy with s and returns the findings on path under the original names.
+func scanOverlay(s scanner.Scanner, path, content, target string, recursive bool, custom []rules.Rule) ([]finding.Finding, error) {
+	tmp, err := os.MkdirTemp("", "infra-check-lsp-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	overlayTarget := filepath.Join(tmp, filepath.Base(target))
+	overlayPath := overlayTarget
+	if target != path {
+		rel, err := filepath.Rel(target, path)
+		if err != nil {
+			return nil, err
+		}
+		overlayPath = filepath.Join(overlayTarget, rel)
+		if err := copyTree(target, overlayTarget, s.FileMatcher(), recursive); err != nil {
+			return nil, err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(overlayPath), 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(overlayPath, []byte(content), 0o644); err != nil {
+		return nil, err
+	}
+
+	findings, err := s.Scan(overlayTarget)
+	if err != nil {
+		return nil, err
+	}
+	matched, err := rules.EvaluateAll(custom, s.Name(), overlayTarget)
+	if err != nil {
+		return nil, err
+	}
+	findings = append(findings, matched...)
+
+	var out []finding.Finding
+	// the overlay holds the buffer, so suppression comments not saved yet count too
+	for _, f := range engine.Suppress(engine.OnlyFiles(findings, []string{overlayPath})) {
+		f.File = path
+		f.Message = strings.ReplaceAll(f.Message, overlayTarget, target)
+		f.Scanner = s.Name()
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+// copyTree copies the files in src that match into dst, descending into subdirectories
+// when recursive. Hidden directories and downloaded dependencies are left out.
+func copyTree(src, dst string, match scanner.FileMatcher, recursive bool) error {
+	return scanner.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if p != src && (!recursive || strings.HasPrefix(info.Name(), ".") || info.Name() == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() || info.Size() > maxSiblingSize || !match(p) {
+			return nil
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		return copyFile(p, filepath.Join(dst, rel))
+	})
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
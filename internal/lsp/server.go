@@ -0,0 +1,370 @@
+// language server publishing findings as editor diagnostics while files are edited
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/salchaD-27/infra-check/internal/config"
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/registry"
+	"github.com/salchaD-27/infra-check/internal/rules"
+)
+
+// Debounce is how long typing must pause before a document is scanned again.
+const Debounce = 300 * time.Millisecond
+
+// protocol errors from the JSON-RPC and LSP specifications
+const (
+	parseError           = -32700
+	methodNotFound       = -32601
+	serverNotInitialized = -32002
+)
+
+// DiagnosticSeverity values for finding severities
+var diagnosticSeverity = map[finding.Severity]int{finding.Error: 1, finding.Warning: 2, finding.Info: 3}
+
+// Server is a language server speaking LSP over a stream, usually stdin and stdout.
+// It supports full document sync and answers nothing but diagnostics.
+type Server struct {
+	Rules   []rules.Rule
+	Config  *config.Config
+	Version string
+
+	out  io.Writer
+	wmu  sync.Mutex // serializes messages on out
+	mu   sync.Mutex
+	docs map[string]*document
+
+	initialized bool
+	shutdown    bool
+}
+
+type document struct {
+	version int
+	text    string
+	timer   *time.Timer
+}
+
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *responseError  `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type textDocument struct {
+	URI     string `json:"uri"`
+	Version int    `json:"version"`
+	Text    string `json:"text"`
+}
+
+type position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type diagnostic struct {
+	Range struct {
+		Start position `json:"start"`
+		End   position `json:"end"`
+	} `json:"range"`
+	Severity int    `json:"severity"`
+	Code     string `json:"code,omitempty"`
+	Source   string `json:"source"`
+	Message  string `json:"message"`
+
+	// CodeDescription links the rule's documentation, shown by editors next to the code.
+	CodeDescription *codeDescription `json:"codeDescription,omitempty"`
+}
+
+type codeDescription struct {
+	Href string `json:"href"`
+}
+
+// Serve reads requests from in and writes responses and notifications to out until the
+// client sends exit or closes in. It returns an error when exit came without shutdown,
+// as the specification asks servers to exit with a failure then.
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	s.out = out
+	s.docs = make(map[string]*document)
+	r := bufio.NewReader(in)
+	for {
+		body, err := readMessage(r)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var msg message
+		if err := json.Unmarshal(body, &msg); err != nil {
+			s.reply(nil, nil, &responseError{parseError, err.Error()})
+			continue
+		}
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return fmt.Errorf("exit without shutdown")
+			}
+			return nil
+		}
+		s.handle(msg)
+	}
+}
+
+func (s *Server) handle(msg message) {
+	isRequest := len(msg.ID) > 0
+	if !s.initialized && msg.Method != "initialize" {
+		if isRequest {
+			s.reply(msg.ID, nil, &responseError{serverNotInitialized, "initialize first"})
+		}
+		return
+	}
+
+	switch msg.Method {
+	case "initialize":
+		s.initialized = true
+		s.reply(msg.ID, map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync": map[string]interface{}{"openClose": true, "change": 1, "save": true},
+			},
+			"serverInfo": map[string]string{"name": "infra-check", "version": s.Version},
+		}, nil)
+	case "shutdown":
+		s.shutdown = true
+		s.reply(msg.ID, nil, nil)
+	case "textDocument/didOpen":
+		var p struct {
+			TextDocument textDocument `json:"textDocument"`
+		}
+		if json.Unmarshal(msg.Params, &p) == nil {
+			s.update(p.TextDocument.URI, p.TextDocument.Version, p.TextDocument.Text, 0)
+		}
+	case "textDocument/didChange":
+		var p struct {
+			TextDocument   textDocument `json:"textDocument"`
+			ContentChanges []struct {
+				Text string `json:"text"`
+			} `json:"contentChanges"`
+		}
+		// full sync: the last change holds the whole document
+		if json.Unmarshal(msg.Params, &p) == nil && len(p.ContentChanges) > 0 {
+			s.update(p.TextDocument.URI, p.TextDocument.Version, p.ContentChanges[len(p.ContentChanges)-1].Text, Debounce)
+		}
+	case "textDocument/didSave":
+		var p struct {
+			TextDocument textDocument `json:"textDocument"`
+			Text         *string      `json:"text"`
+		}
+		if json.Unmarshal(msg.Params, &p) == nil {
+			s.mu.Lock()
+			doc := s.docs[p.TextDocument.URI]
+			s.mu.Unlock()
+			if doc != nil {
+				text := doc.text
+				if p.Text != nil {
+					text = *p.Text
+				}
+				s.update(p.TextDocument.URI, doc.version, text, 0)
+			}
+		}
+	case "textDocument/didClose":
+		var p struct {
+			TextDocument textDocument `json:"textDocument"`
+		}
+		if json.Unmarshal(msg.Params, &p) == nil {
+			s.mu.Lock()
+			if doc := s.docs[p.TextDocument.URI]; doc != nil && doc.timer != nil {
+				doc.timer.Stop()
+			}
+			delete(s.docs, p.TextDocument.URI)
+			s.mu.Unlock()
+			s.publish(p.TextDocument.URI, nil, []diagnostic{})
+		}
+	default:
+		// notifications the server has no use for, such as initialized or $/ events, are ignored
+		if isRequest {
+			s.reply(msg.ID, nil, &responseError{methodNotFound, "method not supported: " + msg.Method})
+		}
+	}
+}
+
+// update stores a document's new text and scans it after delay, cancelling a scan still
+// waiting for an older text.
+func (s *Server) update(uri string, version int, text string, delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	doc := s.docs[uri]
+	if doc == nil {
+		doc = &document{}
+		s.docs[uri] = doc
+	}
+	doc.version, doc.text = version, text
+	if doc.timer != nil {
+		doc.timer.Stop()
+	}
+	doc.timer = time.AfterFunc(delay, func() { s.diagnose(uri, version, text) })
+}
+
+// diagnose scans a document's text and publishes its findings, unless the text changed
+// in the meantime.
+func (s *Server) diagnose(uri string, version int, text string) {
+	path, ok := uriPath(uri)
+	if !ok {
+		return
+	}
+	var diagnostics []diagnostic
+	findings, err := Diagnose(path, text, s.Rules, s.Config)
+	if err != nil {
+		s.log(fmt.Sprintf("scanning %s: %v", path, err))
+	}
+	lines := strings.Split(text, "\n")
+	for _, f := range findings {
+		d := diagnostic{Severity: diagnosticSeverity[f.Severity], Code: f.RuleID(), Source: "infra-check", Message: f.Message}
+		if rule, ok := registry.For(f); ok {
+			d.Code = rule.ID
+			d.CodeDescription = &codeDescription{Href: rule.URL}
+		}
+		if f.Scanner != "" {
+			d.Source = "infra-check " + f.Scanner
+		}
+		// findings point at whole lines; those without a line go on the first one
+		line := max(f.Line-1, 0)
+		if line < len(lines) {
+			d.Range.Start.Line, d.Range.End.Line = line, line
+			d.Range.End.Character = utf16Len(strings.TrimRight(lines[line], "\r"))
+		}
+		diagnostics = append(diagnostics, d)
+	}
+	if diagnostics == nil {
+		diagnostics = []diagnostic{}
+	}
+
+	s.mu.Lock()
+	doc := s.docs[uri]
+	current := doc != nil && doc.version == version && doc.text == text
+	s.mu.Unlock()
+	if current {
+		s.publish(uri, &version, diagnostics)
+	}
+}
+
+func (s *Server) publish(uri string, version *int, diagnostics []diagnostic) {
+	params := map[string]interface{}{"uri": uri, "diagnostics": diagnostics}
+	if version != nil {
+		params["version"] = *version
+	}
+	s.notify("textDocument/publishDiagnostics", params)
+}
+
+// log shows a message in the client's output for the server.
+func (s *Server) log(msg string) {
+	s.notify("window/logMessage", map[string]interface{}{"type": 1, "message": msg})
+}
+
+func (s *Server) notify(method string, params interface{}) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return
+	}
+	s.write(message{JSONRPC: "2.0", Method: method, Params: data})
+}
+
+func (s *Server) reply(id json.RawMessage, result interface{}, rerr *responseError) {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	msg := message{JSONRPC: "2.0", ID: id, Result: result, Error: rerr}
+	if result == nil && rerr == nil {
+		// a successful response carries a result, null if there is nothing to return
+		data, _ := json.Marshal(struct {
+			JSONRPC string          `json:"jsonrpc"`
+			ID      json.RawMessage `json:"id"`
+			Result  interface{}     `json:"result"`
+		}{"2.0", id, nil})
+		s.writeRaw(data)
+		return
+	}
+	s.write(msg)
+}
+
+func (s *Server) write(msg message) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	s.writeRaw(data)
+}
+
+func (s *Server) writeRaw(data []byte) {
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+	fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(data), data)
+}
+
+// readMessage reads one message framed by a Content-Length header.
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line == "" && length < 0 {
+				return nil, io.EOF
+			}
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			if length, err = strconv.Atoi(strings.TrimSpace(value)); err != nil {
+				return nil, fmt.Errorf("invalid Content-Length header: %s", line)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("message without a Content-Length header")
+	}
+	body := make([]byte, length)
+	_, err := io.ReadFull(r, body)
+	return body, err
+}
+
+// uriPath returns the file path of a file:// URI.
+func uriPath(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return "", false
+	}
+	return filepath.FromSlash(u.Path), true
+}
+
+// utf16Len returns the length of s in UTF-16 code units, which LSP positions count.
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
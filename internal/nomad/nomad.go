@@ -0,0 +1,226 @@
+// static analysis of HashiCorp Nomad job specifications
+package nomad
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// Keywords to detect secrets in env variable names
+var secretKeywords = []string{"password", "passwd", "secret", "token", "api_key", "apikey", "access_key", "private_key", "credential"}
+
+// Drivers running containers, whose config can request privileged mode
+var containerDrivers = map[string]bool{"docker": true, "podman": true}
+
+// IsJobFile reports whether p is named like a Nomad job file: *.nomad, or *.hcl, which
+// also holds other HCL and is told apart by its content.
+func IsJobFile(p string) bool {
+	return strings.HasSuffix(p, ".nomad") || strings.HasSuffix(p, ".hcl") && !strings.HasSuffix(p, ".pkr.hcl")
+}
+
+// namedJob reports whether p is named *.nomad or *.nomad.hcl, which needs no look at its
+// content to be taken for a job.
+func namedJob(p string) bool {
+	return strings.HasSuffix(p, ".nomad") || strings.HasSuffix(p, ".nomad.hcl")
+}
+
+// IsJob reports whether the HCL in data declares a job at the top level.
+func IsJob(data []byte) bool {
+	file, diags := hclsyntax.ParseConfig(data, "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return false
+	}
+	for _, block := range file.Body.(*hclsyntax.Body).Blocks {
+		if block.Type == "job" {
+			return true
+		}
+	}
+	return false
+}
+
+// Scan walks path for Nomad job files and checks the tasks of each job:
+// - docker and podman tasks running privileged
+// - raw_exec tasks, which run unisolated on the client
+// - tasks without a resources stanza
+// - secrets written in plain text into env blocks
+func Scan(path string) ([]finding.Finding, error) {
+	var findings []finding.Finding
+
+	err := scanner.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		if !IsJobFile(p) {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			findings = append(findings, finding.Finding{
+				File:     p,
+				Severity: finding.Error,
+				Message:  fmt.Sprintf("Failed to read file: %v", err),
+			})
+			return nil
+		}
+		if !namedJob(p) && !IsJob(data) {
+			return nil
+		}
+		findings = append(findings, Check(p, data)...)
+		return nil
+	})
+
+	return findings, err
+}
+
+// Check parses a job file and runs the task checks on every job in it.
+func Check(p string, data []byte) []finding.Finding {
+	file, diags := hclsyntax.ParseConfig(data, p, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return []finding.Finding{{
+			File:     p,
+			Severity: finding.Error,
+			Message:  fmt.Sprintf("HCL parse error: %s", diags.Error()),
+		}}
+	}
+
+	var findings []finding.Finding
+	for _, job := range blocks(file.Body.(*hclsyntax.Body), "job") {
+		for _, group := range blocks(job.Body, "group") {
+			for _, task := range blocks(group.Body, "task") {
+				findings = append(findings, checkTask(p, label(job), label(group), task)...)
+			}
+		}
+	}
+	return findings
+}
+
+func checkTask(p, job, group string, task *hclsyntax.Block) []finding.Finding {
+	var findings []finding.Finding
+	add := func(sev finding.Severity, line int, format string, args ...interface{}) {
+		prefix := fmt.Sprintf("Job '%s' task '%s.%s': ", job, group, label(task))
+		findings = append(findings, finding.Finding{File: p, Severity: sev, Message: prefix + fmt.Sprintf(format, args...), Line: line})
+	}
+	line := task.DefRange().Start.Line
+
+	driver, _ := literalString(task.Body, "driver")
+	switch {
+	case driver == "raw_exec":
+		add(finding.Error, attrLine(task.Body, "driver", line), "uses the raw_exec driver, which runs the command without isolation as the Nomad client user (often root); use exec, docker or another isolating driver")
+	case containerDrivers[driver]:
+		for _, config := range blocks(task.Body, "config") {
+			if v, ok := literal(config.Body, "privileged"); ok && v.Type() == cty.Bool && v.True() {
+				add(finding.Error, attrLine(config.Body, "privileged", line), "%s container runs privileged", driver)
+			}
+			if mode, _ := literalString(config.Body, "network_mode"); mode == "host" {
+				add(finding.Warning, attrLine(config.Body, "network_mode", line), "%s container shares the host network namespace (network_mode = \"host\")", driver)
+			}
+		}
+	}
+
+	if len(blocks(task.Body, "resources")) == 0 {
+		add(finding.Warning, line, "has no resources stanza, so it is scheduled with the default cpu and memory and its real usage is neither reserved nor limited")
+	}
+
+	for _, env := range envVars(task.Body) {
+		if looksLikeSecret(env.name) && env.value != "" {
+			add(finding.Error, env.line, "env '%s' holds a plaintext secret; render it from Vault or Nomad Variables with a template block instead", env.name)
+		}
+	}
+	return findings
+}
+
+type envVar struct {
+	name, value string
+	line        int
+}
+
+// envVars returns the env variables of a task with literal string values, from env blocks
+// and env = { ... } attributes.
+func envVars(body *hclsyntax.Body) []envVar {
+	var out []envVar
+	for _, env := range blocks(body, "env") {
+		for name, attr := range env.Body.Attributes {
+			if v, ok := literal(env.Body, name); ok && v.Type() == cty.String {
+				out = append(out, envVar{name, v.AsString(), attr.SrcRange.Start.Line})
+			}
+		}
+	}
+	if attr, ok := body.Attributes["env"]; ok {
+		if obj, ok := attr.Expr.(*hclsyntax.ObjectConsExpr); ok {
+			for _, item := range obj.Items {
+				key, diags := item.KeyExpr.Value(nil)
+				value, vdiags := item.ValueExpr.Value(nil)
+				if diags.HasErrors() || vdiags.HasErrors() || key.Type() != cty.String || value.Type() != cty.String || !value.IsKnown() {
+					continue
+				}
+				out = append(out, envVar{key.AsString(), value.AsString(), item.KeyExpr.Range().Start.Line})
+			}
+		}
+	}
+	return out
+}
+
+// blocks returns the blocks of body with the given type.
+func blocks(body *hclsyntax.Body, typ string) []*hclsyntax.Block {
+	var out []*hclsyntax.Block
+	for _, block := range body.Blocks {
+		if block.Type == typ {
+			out = append(out, block)
+		}
+	}
+	return out
+}
+
+func label(block *hclsyntax.Block) string {
+	if len(block.Labels) == 0 {
+		return ""
+	}
+	return block.Labels[0]
+}
+
+// literal returns the value of attribute name when it has no references or interpolations.
+func literal(body *hclsyntax.Body, name string) (cty.Value, bool) {
+	attr, ok := body.Attributes[name]
+	if !ok {
+		return cty.NilVal, false
+	}
+	v, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || !v.IsKnown() || v.IsNull() {
+		return cty.NilVal, false
+	}
+	return v, true
+}
+
+func literalString(body *hclsyntax.Body, name string) (string, bool) {
+	v, ok := literal(body, name)
+	if !ok || v.Type() != cty.String {
+		return "", false
+	}
+	return v.AsString(), true
+}
+
+// attrLine returns the line of attribute name, or fallback when body does not set it.
+func attrLine(body *hclsyntax.Body, name string, fallback int) int {
+	if attr, ok := body.Attributes[name]; ok {
+		return attr.SrcRange.Start.Line
+	}
+	return fallback
+}
+
+func looksLikeSecret(name string) bool {
+	lower := strings.ToLower(name)
+	for _, kw := range secretKeywords {
+		if strings.Contains(lower, kw) {
+			return true
+		}
+	}
+	return false
+}
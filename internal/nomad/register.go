@@ -0,0 +1,35 @@
+package nomad
+
+import (
+	"os"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// nomadScanner scans Nomad job files. Jobs named *.hcl are told apart from other HCL by
+// their top-level job block.
+type nomadScanner struct{}
+
+func init() {
+	scanner.Register(nomadScanner{})
+}
+
+func (nomadScanner) Name() string { return "nomad" }
+func (nomadScanner) Description() string {
+	return "Scan Nomad job specifications in the specified directories or files"
+}
+func (nomadScanner) FileMatcher() scanner.FileMatcher            { return IsJobFile }
+func (nomadScanner) Scan(path string) ([]finding.Finding, error) { return Scan(path) }
+
+func (nomadScanner) Targets(root string, files []string) []string {
+	var jobs []string
+	for _, p := range files {
+		if namedJob(p) {
+			jobs = append(jobs, p)
+		} else if data, err := os.ReadFile(p); err == nil && IsJob(data) {
+			jobs = append(jobs, p)
+		}
+	}
+	return jobs
+}
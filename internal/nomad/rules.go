@@ -0,0 +1,63 @@
+package nomad
+
+import (
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/registry"
+)
+
+func init() {
+	scanners := []string{"nomad"}
+	registry.Register(
+		registry.Rule{
+			ID: "NMD001", Name: "raw_exec driver", Severity: finding.Error, Category: "isolation", Scanners: scanners,
+			Compliance:  registry.PrivilegedWorkloads,
+			Description: "A task uses the raw_exec driver and runs without isolation as the Nomad client user.",
+			Messages:    []string{"Job '*' task '*': uses the raw_exec driver, *"},
+			Remediation: `task "web" {
+  driver = "docker"
+  config {
+    image = "ghcr.io/acme/web:1.4.2"
+  }
+}`,
+		},
+		registry.Rule{
+			ID: "NMD002", Name: "Privileged container", Severity: finding.Error, Category: "privilege", Scanners: scanners,
+			Compliance:  registry.PrivilegedWorkloads,
+			Description: "A docker or podman task runs its container privileged.",
+			Messages:    []string{"Job '*' task '*': * container runs privileged"},
+			Remediation: `config {
+  image      = "ghcr.io/acme/web:1.4.2"
+  privileged = false
+}`,
+		},
+		registry.Rule{
+			ID: "NMD003", Name: "Host network", Severity: finding.Warning, Category: "isolation", Scanners: scanners,
+			Compliance:  registry.PrivilegedWorkloads,
+			Description: "A container task shares the host network namespace.",
+			Messages:    []string{"Job '*' task '*': * container shares the host network namespace *"},
+			Remediation: `network {
+  mode = "bridge"
+}`,
+		},
+		registry.Rule{
+			ID: "NMD004", Name: "No resources stanza", Severity: finding.Warning, Category: "reliability", Scanners: scanners,
+			Description: "A task has no resources stanza and gets the default cpu and memory, neither reserved nor limited to what it uses.",
+			Messages:    []string{"Job '*' task '*': has no resources stanza, *"},
+			Remediation: `resources {
+  cpu    = 500
+  memory = 256
+}`,
+		},
+		registry.Rule{
+			ID: "NMD005", Name: "Plaintext secret in env", Severity: finding.Error, Category: "secrets", Scanners: scanners,
+			Compliance:  registry.HardcodedSecrets,
+			Description: "A task env var with a secret-like name holds a literal instead of a value rendered from Vault or Nomad Variables.",
+			Messages:    []string{"Job '*' task '*': env '*' holds a plaintext secret; *"},
+			Remediation: `template {
+  destination = "secrets/db.env"
+  env         = true
+  data        = "DB_PASSWORD={{ with secret \"secret/data/db\" }}{{ .Data.data.password }}{{ end }}"
+}`,
+		},
+	)
+}
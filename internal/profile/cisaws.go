@@ -0,0 +1,58 @@
+package profile
+
+// cisAWS maps the Terraform and CloudFormation rules to the CIS Amazon Web Services
+// Foundations Benchmark. Most of its controls concern account settings, such as root MFA,
+// password policy and CloudTrail, that infrastructure code rarely declares; those are not
+// covered here, so a clean scan is not a passing benchmark.
+var cisAWS = &Profile{
+	Name:   "cis-aws",
+	Title:  "CIS Amazon Web Services Foundations Benchmark v3.0.0",
+	Prefix: "CIS AWS",
+	Controls: []Control{
+		{
+			ID:    "1.16",
+			Title: `Ensure IAM policies that allow full "*:*" administrative privileges are not attached`,
+			Rules: []string{"TF030", "CFN008"},
+		},
+		{
+			ID:    "2.1.4",
+			Title: "Ensure that S3 Buckets are configured with 'Block public access (bucket settings)'",
+			Rules: []string{"TF002", "TF064", "CFN003"},
+		},
+		{
+			ID:       "2.2.1",
+			Title:    "Ensure EBS volume encryption is enabled in all regions",
+			Rules:    []string{"TF024", "TF025", "CFN002"},
+			Matching: []string{"aws_ebs_volume.", "(AWS::EC2::Volume)"},
+		},
+		{
+			ID:       "2.3.1",
+			Title:    "Ensure that encryption-at-rest is enabled for RDS Instances",
+			Rules:    []string{"TF024", "TF025", "CFN002"},
+			Matching: []string{"aws_db_instance.", "aws_rds_cluster.", "(AWS::RDS::DBInstance)", "(AWS::RDS::DBCluster)"},
+		},
+		{
+			ID:    "2.3.3",
+			Title: "Ensure that public access is not given to RDS Instance",
+			Rules: []string{"CFN005"},
+		},
+		{
+			ID:       "2.4.1",
+			Title:    "Ensure that encryption is enabled for EFS file systems",
+			Rules:    []string{"TF024", "TF025", "CFN002"},
+			Matching: []string{"aws_efs_file_system.", "(AWS::EFS::FileSystem)"},
+		},
+		{
+			ID:       "5.2",
+			Title:    "Ensure no security groups allow ingress from 0.0.0.0/0 to remote server administration ports",
+			Rules:    []string{"TF029", "CFN007"},
+			Matching: []string{"0.0.0.0/0"},
+		},
+		{
+			ID:       "5.3",
+			Title:    "Ensure no security groups allow ingress from ::/0 to remote server administration ports",
+			Rules:    []string{"TF029", "CFN007"},
+			Matching: []string{"::/0"},
+		},
+	},
+}
@@ -0,0 +1,142 @@
+// rule bundles mapping the built-in checks to the controls of compliance benchmarks
+package profile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/registry"
+)
+
+// Profile is a benchmark and the controls of it the built-in rules check. A scan with a
+// profile reports only the findings of those rules, each naming the controls it fails.
+type Profile struct {
+	// Name selects the profile with --profile; Title names the benchmark and its version.
+	Name  string
+	Title string
+
+	// Prefix is prepended to control IDs in findings, so a reference reads "CIS AWS 2.1.4".
+	Prefix string
+
+	Controls []Control
+}
+
+// Control is one control of a benchmark and the rules whose findings show it failing.
+type Control struct {
+	ID    string
+	Title string
+	Rules []string
+
+	// Matching, when set, limits the control to the findings of Rules whose message
+	// contains one of these strings, for rules checking more than the control covers:
+	// encryption at rest of EBS volumes, say, out of that of every storage resource.
+	Matching []string
+}
+
+// The profiles by name.
+var profiles = map[string]*Profile{
+	cisAWS.Name: cisAWS,
+}
+
+// Lookup returns the profile named name, case-insensitively.
+func Lookup(name string) (*Profile, error) {
+	if p, ok := profiles[strings.ToLower(name)]; ok {
+		return p, nil
+	}
+	return nil, fmt.Errorf("unknown profile '%s' (use %s)", name, strings.Join(Names(), ", "))
+}
+
+// Names returns the names of the profiles in alphabetical order.
+func Names() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Framework returns the profile of a compliance framework, made of the mappings of it the
+// rules carry: a control for each control ID they name, covering every finding of the rules
+// naming it.
+func Framework(key string, rules []registry.Rule) (*Profile, error) {
+	fw, err := registry.LookupFramework(key)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*Control)
+	for _, r := range rules {
+		for _, id := range r.Compliance[fw.Key] {
+			if byID[id] == nil {
+				byID[id] = &Control{ID: id}
+			}
+			byID[id].Rules = append(byID[id].Rules, r.ID)
+		}
+	}
+	ids := make([]string, 0, len(byID))
+	for id := range byID {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	p := &Profile{Name: fw.Key, Title: fw.Title, Prefix: fw.Prefix}
+	for _, id := range ids {
+		p.Controls = append(p.Controls, *byID[id])
+	}
+	return p, nil
+}
+
+// Apply reports whether f is the finding of a rule the profile maps to a control, and
+// adds the references of the controls it fails to its Controls.
+func (p *Profile) Apply(f finding.Finding) (finding.Finding, bool) {
+	id := registry.ID(f)
+	if id == "" {
+		return f, false
+	}
+	var controls []string
+	for _, c := range p.Controls {
+		if c.covers(id, f.Message) {
+			controls = append(controls, p.Prefix+" "+c.ID)
+		}
+	}
+	if len(controls) == 0 {
+		return f, false
+	}
+	f.Controls = append(f.Controls, controls...)
+	return f, true
+}
+
+func (c Control) covers(id, message string) bool {
+	found := false
+	for _, r := range c.Rules {
+		if r == id {
+			found = true
+			break
+		}
+	}
+	if !found || len(c.Matching) == 0 {
+		return found
+	}
+	for _, s := range c.Matching {
+		if strings.Contains(message, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// ControlsOf returns the references of every control rule id is mapped to, whichever of
+// its findings the control is limited to.
+func (p *Profile) ControlsOf(id string) []string {
+	var controls []string
+	for _, c := range p.Controls {
+		for _, r := range c.Rules {
+			if r == id {
+				controls = append(controls, p.Prefix+" "+c.ID)
+				break
+			}
+		}
+	}
+	return controls
+}
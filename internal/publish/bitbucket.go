@@ -0,0 +1,191 @@
+package publish
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// Bitbucket Cloud limits annotations to 1000 per report and 100 per request.
+const (
+	maxAnnotations     = 1000
+	annotationsPerCall = 100
+)
+
+// Bitbucket creates a Code Insights report with an annotation per finding on a commit,
+// which Bitbucket Cloud shows on the pull requests containing it.
+type Bitbucket struct {
+	API       string // API 2.0 base URL, https://api.bitbucket.org/2.0 by default
+	Token     string // repository, project or workspace access token; none through the Pipelines proxy
+	Workspace string
+	Repo      string // repository slug
+	Commit    string
+	ReportURL string // linked from the report, e.g. the pipeline; optional
+	Root      string // repository root that finding paths are made relative to
+	Client    *http.Client
+}
+
+// BitbucketFromEnv configures Bitbucket from the variables of a Bitbucket Pipelines
+// build. Without BITBUCKET_TOKEN, requests go through the Pipelines proxy, which
+// authenticates Code Insights calls on the build's behalf.
+func BitbucketFromEnv() (*Bitbucket, error) {
+	b := &Bitbucket{
+		API:       os.Getenv("BITBUCKET_API_URL"),
+		Token:     os.Getenv("BITBUCKET_TOKEN"),
+		Workspace: os.Getenv("BITBUCKET_WORKSPACE"),
+		Repo:      os.Getenv("BITBUCKET_REPO_SLUG"),
+		Commit:    os.Getenv("BITBUCKET_COMMIT"),
+		ReportURL: CIRunURL(),
+		Root:      os.Getenv("BITBUCKET_CLONE_DIR"),
+	}
+	if b.Root == "" {
+		b.Root = RepoRoot()
+	}
+	if b.API == "" {
+		b.API = "https://api.bitbucket.org/2.0"
+		if b.Token == "" {
+			// the proxy only accepts plain HTTP
+			b.API = "http://api.bitbucket.org/2.0"
+			proxy, _ := url.Parse("http://localhost:29418")
+			b.Client = &http.Client{Timeout: defaultClient.Timeout, Transport: &http.Transport{Proxy: http.ProxyURL(proxy)}}
+		}
+	}
+	switch {
+	case b.Workspace == "" || b.Repo == "":
+		return nil, fmt.Errorf("BITBUCKET_WORKSPACE and BITBUCKET_REPO_SLUG are not set")
+	case b.Commit == "":
+		return nil, fmt.Errorf("BITBUCKET_COMMIT is not set")
+	}
+	return b, nil
+}
+
+type insightsReport struct {
+	Title      string         `json:"title"`
+	Details    string         `json:"details"`
+	ReportType string         `json:"report_type"`
+	Reporter   string         `json:"reporter"`
+	Result     string         `json:"result"`
+	Link       string         `json:"link,omitempty"`
+	Data       []insightsData `json:"data"`
+}
+
+type insightsData struct {
+	Title string      `json:"title"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+type insightsAnnotation struct {
+	ExternalID     string `json:"external_id"`
+	AnnotationType string `json:"annotation_type"`
+	Summary        string `json:"summary"`
+	Severity       string `json:"severity"`
+	Path           string `json:"path,omitempty"`
+	Line           int    `json:"line,omitempty"`
+}
+
+// Publish replaces the commit's infra-check report with one holding findings, FAILED when
+// failed is true. It returns a one-line summary of what was done.
+func (b *Bitbucket) Publish(findings []finding.Finding, failed bool) (string, error) {
+	result := "PASSED"
+	if failed {
+		result = "FAILED"
+	}
+	c := counts(findings)
+	report := insightsReport{
+		Title:      "infra-check",
+		Details:    fmt.Sprintf("infra-check found %d issue(s) in the infrastructure code.", len(findings)),
+		ReportType: "SECURITY",
+		Reporter:   "infra-check",
+		Result:     result,
+		Link:       b.ReportURL,
+		Data: []insightsData{
+			{Title: "Errors", Type: "NUMBER", Value: c[finding.Error]},
+			{Title: "Warnings", Type: "NUMBER", Value: c[finding.Warning]},
+			{Title: "Info", Type: "NUMBER", Value: c[finding.Info]},
+		},
+	}
+	// putting a report under an existing ID replaces it along with its annotations,
+	// so findings resolved since the last run disappear
+	path := "/reports/infra-check"
+	if _, err := b.call(http.MethodPut, path, report, nil); err != nil {
+		return "", err
+	}
+
+	annotations := b.annotations(findings)
+	for start := 0; start < len(annotations); start += annotationsPerCall {
+		end := min(start+annotationsPerCall, len(annotations))
+		if _, err := b.call(http.MethodPost, path+"/annotations", annotations[start:end], nil); err != nil {
+			return "", err
+		}
+	}
+
+	summary := fmt.Sprintf("Bitbucket: created Code Insights report %s with %d annotation(s) on %s", result, len(annotations), shortSHA(b.Commit))
+	if len(findings) > len(annotations) {
+		summary += fmt.Sprintf(" (%d finding(s) over the limit of %d left out)", len(findings)-len(annotations), maxAnnotations)
+	}
+	return summary, nil
+}
+
+// annotations converts findings, most severe first when there are more than a report holds.
+func (b *Bitbucket) annotations(findings []finding.Finding) []insightsAnnotation {
+	var bySeverity []finding.Finding
+	for _, sev := range []finding.Severity{finding.Error, finding.Warning, finding.Info} {
+		for _, f := range findings {
+			if f.Severity == sev {
+				bySeverity = append(bySeverity, f)
+			}
+		}
+	}
+
+	var out []insightsAnnotation
+	ids := make(map[string]int)
+	for _, f := range bySeverity {
+		if len(out) == maxAnnotations {
+			break
+		}
+		a := insightsAnnotation{
+			AnnotationType: "CODE_SMELL",
+			Summary:        f.Message,
+			Severity:       map[finding.Severity]string{finding.Error: "HIGH", finding.Warning: "MEDIUM", finding.Info: "LOW"}[f.Severity],
+			Path:           repoPath(b.Root, f.File),
+			Line:           f.Line,
+		}
+		if f.Severity == finding.Error {
+			a.AnnotationType = "VULNERABILITY"
+		}
+		if len(a.Summary) > 450 {
+			a.Summary = a.Summary[:447] + "..."
+		}
+		if a.Path == "" {
+			a.Line = 0
+		}
+		// stable IDs keep an annotation's identity across runs; repeats of one finding are numbered
+		sum := sha256.Sum256([]byte(strings.Join([]string{a.Path, fmt.Sprint(f.Line), f.Message}, "\x00")))
+		a.ExternalID = "infra-check-" + hex.EncodeToString(sum[:8])
+		if n := ids[a.ExternalID]; n > 0 {
+			ids[a.ExternalID]++
+			a.ExternalID = fmt.Sprintf("%s-%d", a.ExternalID, n)
+		} else {
+			ids[a.ExternalID] = 1
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// call sends a request to an endpoint of the commit.
+func (b *Bitbucket) call(method, path string, body, out interface{}) (http.Header, error) {
+	u := fmt.Sprintf("%s/repositories/%s/%s/commit/%s%s", strings.TrimRight(b.API, "/"), url.PathEscape(b.Workspace), url.PathEscape(b.Repo), b.Commit, path)
+	header := map[string]string{}
+	if b.Token != "" {
+		header["Authorization"] = "Bearer " + b.Token
+	}
+	return request(b.Client, method, u, header, body, out)
+}
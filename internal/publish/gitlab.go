@@ -0,0 +1,203 @@
+package publish
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// GitLab posts findings on the added lines of a merge request's diff as discussions and
+// sets a commit status on the scanned commit.
+type GitLab struct {
+	API          string // API v4 base URL, e.g. https://gitlab.com/api/v4
+	Token        string // personal, project or group access token with the api scope
+	Project      string // numeric ID or full path, e.g. group/project
+	MergeRequest string // IID; only the commit status is set when empty
+	Commit       string // SHA the commit status is set on; none when empty
+	TargetURL    string // linked from the commit status, e.g. the CI job; optional
+	Root         string // repository root that finding paths are made relative to
+	Client       *http.Client
+}
+
+// GitLabFromEnv configures GitLab from the variables of a GitLab CI merge request
+// pipeline. The token is read from GITLAB_TOKEN, as CI_JOB_TOKEN cannot post discussions.
+func GitLabFromEnv() (*GitLab, error) {
+	g := &GitLab{
+		API:          os.Getenv("CI_API_V4_URL"),
+		Token:        os.Getenv("GITLAB_TOKEN"),
+		Project:      os.Getenv("CI_PROJECT_ID"),
+		MergeRequest: os.Getenv("CI_MERGE_REQUEST_IID"),
+		Commit:       os.Getenv("CI_COMMIT_SHA"),
+		TargetURL:    os.Getenv("CI_JOB_URL"),
+		Root:         os.Getenv("CI_PROJECT_DIR"),
+	}
+	if g.API == "" {
+		g.API = "https://gitlab.com/api/v4"
+	}
+	if g.Root == "" {
+		g.Root = RepoRoot()
+	}
+	switch {
+	case g.Token == "":
+		return nil, fmt.Errorf("GITLAB_TOKEN is not set")
+	case g.Project == "":
+		return nil, fmt.Errorf("CI_PROJECT_ID is not set")
+	}
+	return g, nil
+}
+
+type gitLabPosition struct {
+	PositionType string `json:"position_type"`
+	BaseSHA      string `json:"base_sha"`
+	StartSHA     string `json:"start_sha"`
+	HeadSHA      string `json:"head_sha"`
+	OldPath      string `json:"old_path"`
+	NewPath      string `json:"new_path"`
+	NewLine      int    `json:"new_line"`
+}
+
+// Publish posts the findings and sets the commit status, failed when failed is true.
+// Findings already posted by an earlier run are not posted again. It returns a one-line
+// summary of what was done.
+func (g *GitLab) Publish(findings []finding.Finding, failed bool) (string, error) {
+	var done []string
+	if g.MergeRequest != "" {
+		posted, skipped, err := g.discuss(findings)
+		if err != nil {
+			return "", err
+		}
+		done = append(done, fmt.Sprintf("posted %d discussion(s) on merge request !%s (%d finding(s) outside the diff or already posted)", posted, g.MergeRequest, skipped))
+	}
+	if g.Commit != "" {
+		state := "success"
+		if failed {
+			state = "failed"
+		}
+		c := counts(findings)
+		status := map[string]string{
+			"state":       state,
+			"name":        "infra-check",
+			"description": fmt.Sprintf("%d error(s), %d warning(s), %d info", c[finding.Error], c[finding.Warning], c[finding.Info]),
+		}
+		if g.TargetURL != "" {
+			status["target_url"] = g.TargetURL
+		}
+		if _, err := g.call(http.MethodPost, "/statuses/"+g.Commit, status, nil); err != nil {
+			return "", err
+		}
+		done = append(done, fmt.Sprintf("set commit status %s on %s", state, shortSHA(g.Commit)))
+	}
+	if len(done) == 0 {
+		return "", fmt.Errorf("neither a merge request (CI_MERGE_REQUEST_IID) nor a commit (CI_COMMIT_SHA) to publish to")
+	}
+	return "GitLab: " + strings.Join(done, "; "), nil
+}
+
+// discuss opens a discussion for each finding on a line the merge request adds.
+func (g *GitLab) discuss(findings []finding.Finding) (posted, skipped int, err error) {
+	mr := "/merge_requests/" + g.MergeRequest
+	var info struct {
+		DiffRefs struct {
+			BaseSHA  string `json:"base_sha"`
+			StartSHA string `json:"start_sha"`
+			HeadSHA  string `json:"head_sha"`
+		} `json:"diff_refs"`
+	}
+	if _, err := g.call(http.MethodGet, mr, nil, &info); err != nil {
+		return 0, 0, err
+	}
+
+	added := make(map[string]map[int]bool)
+	var diffs []struct {
+		NewPath     string `json:"new_path"`
+		Diff        string `json:"diff"`
+		DeletedFile bool   `json:"deleted_file"`
+	}
+	if err := g.pages(mr+"/diffs", &diffs, func() {
+		for _, d := range diffs {
+			if !d.DeletedFile {
+				added[d.NewPath] = addedLines(d.Diff)
+			}
+		}
+	}); err != nil {
+		return 0, 0, err
+	}
+
+	existing := make(map[string]bool)
+	var discussions []struct {
+		Notes []struct {
+			Body     string `json:"body"`
+			Position *struct {
+				NewPath string `json:"new_path"`
+				NewLine int    `json:"new_line"`
+			} `json:"position"`
+		} `json:"notes"`
+	}
+	if err := g.pages(mr+"/discussions", &discussions, func() {
+		for _, d := range discussions {
+			if len(d.Notes) > 0 && d.Notes[0].Position != nil {
+				n := d.Notes[0]
+				existing[fmt.Sprintf("%s:%d:%s", n.Position.NewPath, n.Position.NewLine, n.Body)] = true
+			}
+		}
+	}); err != nil {
+		return 0, 0, err
+	}
+
+	for _, f := range findings {
+		p := repoPath(g.Root, f.File)
+		body := fmt.Sprintf("%s\n**infra-check** `%s`: %s", marker, f.Severity, f.Message)
+		if p == "" || f.Line == 0 || !added[p][f.Line] || existing[fmt.Sprintf("%s:%d:%s", p, f.Line, body)] {
+			skipped++
+			continue
+		}
+		discussion := map[string]interface{}{
+			"body": body,
+			"position": gitLabPosition{
+				PositionType: "text",
+				BaseSHA:      info.DiffRefs.BaseSHA,
+				StartSHA:     info.DiffRefs.StartSHA,
+				HeadSHA:      info.DiffRefs.HeadSHA,
+				OldPath:      p,
+				NewPath:      p,
+				NewLine:      f.Line,
+			},
+		}
+		if _, err := g.call(http.MethodPost, mr+"/discussions", discussion, nil); err != nil {
+			return posted, skipped, err
+		}
+		existing[fmt.Sprintf("%s:%d:%s", p, f.Line, body)] = true
+		posted++
+	}
+	return posted, skipped, nil
+}
+
+// pages GETs every page of a list endpoint into out, calling each after every page.
+func (g *GitLab) pages(path string, out interface{}, each func()) error {
+	for page := "1"; page != ""; {
+		header, err := g.call(http.MethodGet, path+"?per_page=100&page="+page, nil, out)
+		if err != nil {
+			return err
+		}
+		each()
+		page = header.Get("X-Next-Page")
+	}
+	return nil
+}
+
+// call sends a request to a project endpoint.
+func (g *GitLab) call(method, path string, body, out interface{}) (http.Header, error) {
+	u := strings.TrimRight(g.API, "/") + "/projects/" + url.PathEscape(g.Project) + path
+	return request(g.Client, method, u, map[string]string{"PRIVATE-TOKEN": g.Token}, body, out)
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
@@ -0,0 +1,168 @@
+package publish
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/registry"
+	"github.com/salchaD-27/infra-check/internal/report"
+)
+
+// Metrics renders a scan in the Prometheus text exposition format: gauges of the findings
+// by severity, by rule and by scanner, the files scanned, the scan duration and when the
+// scan finished. Findings without a Scanner are counted for scanner, when given. labels,
+// such as the repository, are put on every sample.
+func Metrics(findings []finding.Finding, summary report.Summary, scanner string, labels map[string]string, now time.Time) string {
+	bySeverity := make(map[[2]string]int)
+	for _, sev := range []finding.Severity{finding.Error, finding.Warning, finding.Info} {
+		bySeverity[[2]string{string(sev)}] = 0 // every severity has a series, so graphs drop to zero
+	}
+	byRule := make(map[[2]string]int)
+	byScanner := make(map[[2]string]int)
+	for _, f := range findings {
+		sev := string(f.Severity)
+		bySeverity[[2]string{sev}]++
+		byRule[[2]string{registry.Key(f), sev}]++
+		name := f.Scanner
+		if name == "" {
+			name = scanner
+		}
+		if name != "" {
+			byScanner[[2]string{name, sev}]++
+		}
+	}
+
+	base := renderLabels(labels, nil, nil)
+	var b strings.Builder
+	gauge := func(name, help string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	}
+	gauge("infra_check_findings", "Findings of the last scan by severity.")
+	writeSamples(&b, "infra_check_findings", labels, []string{"severity"}, bySeverity)
+	gauge("infra_check_rule_findings", "Findings of the last scan by rule (rule ID, or message of a check without one) and severity.")
+	writeSamples(&b, "infra_check_rule_findings", labels, []string{"rule", "severity"}, byRule)
+	gauge("infra_check_scanner_findings", "Findings of the last scan by scanner and severity.")
+	writeSamples(&b, "infra_check_scanner_findings", labels, []string{"scanner", "severity"}, byScanner)
+	gauge("infra_check_files_scanned", "Files read by the scanners in the last scan.")
+	fmt.Fprintf(&b, "infra_check_files_scanned%s %d\n", base, summary.FilesScanned)
+	gauge("infra_check_scan_duration_seconds", "Duration of the last scan.")
+	fmt.Fprintf(&b, "infra_check_scan_duration_seconds%s %g\n", base, float64(summary.DurationMs)/1000)
+	gauge("infra_check_last_scan_timestamp_seconds", "Unix time the last scan finished.")
+	fmt.Fprintf(&b, "infra_check_last_scan_timestamp_seconds%s %d\n", base, now.Unix())
+	return b.String()
+}
+
+// writeSamples writes one sample of name per key of counts, labelled with labels and the
+// key's first len(names) values under names, in label order.
+func writeSamples(b *strings.Builder, name string, labels map[string]string, names []string, counts map[[2]string]int) {
+	keys := make([][2]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		for n := range names {
+			if keys[i][n] != keys[j][n] {
+				return keys[i][n] < keys[j][n]
+			}
+		}
+		return false
+	})
+	for _, k := range keys {
+		fmt.Fprintf(b, "%s%s %d\n", name, renderLabels(labels, names, k[:len(names)]), counts[k])
+	}
+}
+
+// renderLabels renders labels, then names set to values, as {name="value",...}, or "" when
+// there are none.
+func renderLabels(labels map[string]string, names, values []string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var pairs []string
+	for _, k := range keys {
+		pairs = append(pairs, k+`="`+labelEscaper.Replace(labels[k])+`"`)
+	}
+	for i, n := range names {
+		pairs = append(pairs, n+`="`+labelEscaper.Replace(values[i])+`"`)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// Escapes label values the way the exposition format does: backslashes, double quotes and newlines
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Pushgateway pushes metrics to a Prometheus Pushgateway, replacing the metrics of the
+// group the Job and Labels name.
+type Pushgateway struct {
+	URL    string
+	Job    string
+	Labels map[string]string // grouping key besides the job, e.g. the repository
+	Client *http.Client
+}
+
+// Push replaces the group's metrics with metrics, as rendered by Metrics without the
+// grouping labels, which the Pushgateway adds.
+func (p *Pushgateway) Push(metrics string) (string, error) {
+	target := strings.TrimRight(p.URL, "/") + "/metrics" + groupingSegment("job", p.Job)
+	keys := make([]string, 0, len(p.Labels))
+	for k := range p.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		target += groupingSegment(k, p.Labels[k])
+	}
+
+	req, err := http.NewRequest(http.MethodPut, target, strings.NewReader(metrics))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	client := p.Client
+	if client == nil {
+		client = defaultClient
+	}
+	host := p.URL
+	if u, err := url.Parse(p.URL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		if inner := errors.Unwrap(err); inner != nil {
+			err = inner // *url.Error repeats the URL
+		}
+		return "", fmt.Errorf("pushing to %s: %v", host, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 300))
+		return "", fmt.Errorf("pushing to %s: %s: %s", host, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return fmt.Sprintf("Metrics: pushed to %s as job %q", host, p.Job), nil
+}
+
+// groupingSegment renders the grouping label name=v as "/name/v" for a Pushgateway URL, or
+// as "/name@base64/" and its base64url encoding when v holds a slash or is empty, which a
+// path segment cannot carry.
+func groupingSegment(name, v string) string {
+	if v == "" {
+		return "/" + name + "@base64/="
+	}
+	if strings.Contains(v, "/") {
+		return "/" + name + "@base64/" + base64.URLEncoding.EncodeToString([]byte(v))
+	}
+	return "/" + name + "/" + url.PathEscape(v)
+}
@@ -0,0 +1,141 @@
+// publishers posting findings to code hosts and chat services after a scan
+package publish
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// Marks comments infra-check posted, so a re-run can tell them apart from people's
+const marker = "<!-- infra-check -->"
+
+var defaultClient = &http.Client{Timeout: 30 * time.Second}
+
+// RepoRoot returns the root of the git work tree holding the working directory, or
+// the working directory outside one.
+func RepoRoot() string {
+	out, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "."
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// repoPath returns file relative to the repository root with forward slashes, the way
+// code hosts name files, or "" when file is outside root.
+func repoPath(root, file string) string {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return ""
+	}
+	rootAbs, err := filepath.Abs(root)
+	if err != nil {
+		return ""
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(rootAbs); err == nil {
+		rootAbs = resolved
+	}
+	rel, err := filepath.Rel(rootAbs, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
+
+// counts returns the number of findings per severity.
+func counts(findings []finding.Finding) map[finding.Severity]int {
+	c := make(map[finding.Severity]int)
+	for _, f := range findings {
+		c[f.Severity]++
+	}
+	return c
+}
+
+// addedLines parses a unified diff and returns the line numbers in the new file of the
+// lines it adds, the only ones a review comment can be attached to as new code.
+func addedLines(diff string) map[int]bool {
+	added := make(map[int]bool)
+	line := 0
+	for _, l := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(l, "@@"):
+			// @@ -a,b +c,d @@
+			fields := strings.Fields(l)
+			if len(fields) < 3 {
+				continue
+			}
+			start, _, _ := strings.Cut(strings.TrimPrefix(fields[2], "+"), ",")
+			line, _ = strconv.Atoi(start)
+		case line == 0, strings.HasPrefix(l, `\`):
+		case strings.HasPrefix(l, "+"):
+			added[line] = true
+			line++
+		case strings.HasPrefix(l, "-"):
+		default:
+			line++
+		}
+	}
+	return added
+}
+
+// request sends a JSON request and decodes a JSON response into out when it is not nil.
+// It returns the response headers, e.g. for pagination.
+func request(client *http.Client, method, url string, header map[string]string, body, out interface{}) (http.Header, error) {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, url, r)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	if client == nil {
+		client = defaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		msg := strings.TrimSpace(string(data))
+		if len(msg) > 300 {
+			msg = msg[:300] + "..."
+		}
+		return nil, fmt.Errorf("%s %s: %s: %s", method, req.URL.Path, resp.Status, msg)
+	}
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return nil, fmt.Errorf("%s %s: %v", method, req.URL.Path, err)
+		}
+	}
+	return resp.Header, nil
+}
@@ -0,0 +1,153 @@
+package publish
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// Files listed under "Top offenders" in a Slack summary
+const topOffenders = 5
+
+// Slack posts a scan summary to a channel through an incoming webhook.
+type Slack struct {
+	WebhookURL string
+	ReportURL  string // linked as the full report; CIRunURL() is a good default
+	Repo       string // shown in the header; optional
+	Commit     string // shown in the footer; optional
+	Root       string // repository root that file paths are made relative to
+	Client     *http.Client
+}
+
+// CIRunURL returns the URL of the CI run executing infra-check on GitHub Actions,
+// GitLab CI or Bitbucket Pipelines, or "" elsewhere.
+func CIRunURL() string {
+	if server, repo, run := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID"); server != "" && repo != "" && run != "" {
+		return fmt.Sprintf("%s/%s/actions/runs/%s", server, repo, run)
+	}
+	if job := os.Getenv("CI_JOB_URL"); job != "" {
+		return job
+	}
+	if origin, build := os.Getenv("BITBUCKET_GIT_HTTP_ORIGIN"), os.Getenv("BITBUCKET_BUILD_NUMBER"); origin != "" && build != "" {
+		return origin + "/addon/pipelines/home#!/results/" + build
+	}
+	return ""
+}
+
+// Notify posts the summary of findings; failed picks the red header emoji.
+func (s *Slack) Notify(findings []finding.Finding, failed bool) (string, error) {
+	if s.WebhookURL == "" {
+		return "", fmt.Errorf("no Slack webhook URL")
+	}
+	c := counts(findings)
+	emoji := ":white_check_mark:"
+	if failed {
+		emoji = ":rotating_light:"
+	} else if len(findings) > 0 {
+		emoji = ":warning:"
+	}
+	title := "infra-check"
+	if s.Repo != "" {
+		title += ": " + s.Repo
+	}
+	headline := fmt.Sprintf("%d error(s), %d warning(s), %d info", c[finding.Error], c[finding.Warning], c[finding.Info])
+
+	blocks := []interface{}{
+		map[string]interface{}{"type": "header", "text": plainText(fmt.Sprintf("%s %s", emoji, title))},
+		map[string]interface{}{"type": "section", "fields": []interface{}{
+			mrkdwn(fmt.Sprintf("*Errors*\n%d", c[finding.Error])),
+			mrkdwn(fmt.Sprintf("*Warnings*\n%d", c[finding.Warning])),
+			mrkdwn(fmt.Sprintf("*Info*\n%d", c[finding.Info])),
+			mrkdwn(fmt.Sprintf("*Total*\n%d", len(findings))),
+		}},
+	}
+	if top := s.offenders(findings); top != "" {
+		blocks = append(blocks, map[string]interface{}{"type": "section", "text": mrkdwn("*Top offenders*\n" + top)})
+	}
+	if s.ReportURL != "" {
+		blocks = append(blocks, map[string]interface{}{"type": "actions", "elements": []interface{}{
+			map[string]interface{}{"type": "button", "text": plainText("Full report"), "url": s.ReportURL},
+		}})
+	}
+	if s.Commit != "" {
+		blocks = append(blocks, map[string]interface{}{"type": "context", "elements": []interface{}{
+			mrkdwn(fmt.Sprintf("Commit `%s`", shortSHA(s.Commit))),
+		}})
+	}
+
+	// text is the fallback shown in notifications
+	message := map[string]interface{}{"text": title + ": " + headline, "blocks": blocks}
+	if _, err := request(s.Client, http.MethodPost, s.WebhookURL, nil, message, nil); err != nil {
+		// the webhook URL is a secret; keep it out of the error
+		msg := strings.ReplaceAll(err.Error(), s.WebhookURL, "<webhook>")
+		if u, perr := url.Parse(s.WebhookURL); perr == nil && u.Path != "" && u.Path != "/" {
+			msg = strings.ReplaceAll(msg, u.Path, "<webhook>")
+		}
+		return "", fmt.Errorf("posting to Slack: %s", msg)
+	}
+	return "Slack: posted scan summary (" + headline + ")", nil
+}
+
+// offenders lists the files with the most findings, most severe first on ties.
+func (s *Slack) offenders(findings []finding.Finding) string {
+	type file struct {
+		name   string
+		total  int
+		errors int
+	}
+	byFile := make(map[string]*file)
+	for _, f := range findings {
+		name := f.File
+		if p := repoPath(s.Root, f.File); p != "" {
+			name = p
+		}
+		if name == "" {
+			continue
+		}
+		name = filepath.ToSlash(name)
+		if byFile[name] == nil {
+			byFile[name] = &file{name: name}
+		}
+		byFile[name].total++
+		if f.Severity == finding.Error {
+			byFile[name].errors++
+		}
+	}
+	files := make([]*file, 0, len(byFile))
+	for _, f := range byFile {
+		files = append(files, f)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].total != files[j].total {
+			return files[i].total > files[j].total
+		}
+		if files[i].errors != files[j].errors {
+			return files[i].errors > files[j].errors
+		}
+		return files[i].name < files[j].name
+	})
+
+	var b strings.Builder
+	for i, f := range files {
+		if i == topOffenders {
+			fmt.Fprintf(&b, "…and %d more file(s)\n", len(files)-topOffenders)
+			break
+		}
+		fmt.Fprintf(&b, "• `%s`: %d finding(s), %d error(s)\n", f.name, f.total, f.errors)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func plainText(s string) map[string]interface{} {
+	return map[string]interface{}{"type": "plain_text", "text": s, "emoji": true}
+}
+
+func mrkdwn(s string) map[string]interface{} {
+	return map[string]interface{}{"type": "mrkdwn", "text": s}
+}
@@ -0,0 +1,140 @@
+package publish
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/report"
+	"github.com/salchaD-27/infra-check/pkg/schema"
+)
+
+// Headers set on webhook deliveries. The signature is the hex HMAC-SHA256 of the body
+// keyed with the secret, prefixed with "sha256=" as GitHub does; the delivery ID stays
+// the same across retries so receivers can drop duplicates.
+const (
+	SignatureHeader = "X-Infra-Check-Signature"
+	DeliveryHeader  = "X-Infra-Check-Delivery"
+	EventHeader     = "X-Infra-Check-Event"
+)
+
+// Webhook POSTs the JSON result of a scan to an arbitrary endpoint.
+type Webhook struct {
+	URL     string
+	Secret  string        // HMAC key signing the body; unsigned when empty
+	Retries int           // further attempts after a failed delivery
+	Backoff time.Duration // wait before the first retry, doubled for each next one; 1s when zero
+	Client  *http.Client
+}
+
+// WebhookPayload is the JSON result envelope with metadata about the scan.
+type WebhookPayload struct {
+	report.Result
+	Metadata WebhookMetadata `json:"metadata"`
+}
+
+// WebhookMetadata describes the scan a payload comes from.
+type WebhookMetadata struct {
+	Tool       string         `json:"tool"`
+	Version    string         `json:"version"`
+	Repository string         `json:"repository,omitempty"`
+	Commit     string         `json:"commit,omitempty"`
+	RunURL     string         `json:"runUrl,omitempty"`
+	ScannedAt  time.Time      `json:"scannedAt"`
+	Failed     bool           `json:"failed"`
+	Counts     map[string]int `json:"counts"`
+}
+
+// Send delivers result with meta, whose Counts, Failed and ScannedAt it fills in.
+// Network errors, 429 and 5xx responses are retried; other responses are final.
+func (w *Webhook) Send(result report.Result, failed bool, meta WebhookMetadata) (string, error) {
+	findings := result.Findings
+	if findings == nil {
+		findings = []finding.Finding{}
+	}
+	result.SchemaVersion, result.Findings = schema.Version, findings
+	meta.Tool = "infra-check"
+	meta.Failed = failed
+	meta.ScannedAt = time.Now().UTC()
+	meta.Counts = make(map[string]int)
+	for sev, n := range counts(findings) {
+		meta.Counts[string(sev)] = n
+	}
+	body, err := json.Marshal(WebhookPayload{result, meta})
+	if err != nil {
+		return "", err
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	delivery := hex.EncodeToString(id)
+	client := w.Client
+	if client == nil {
+		client = defaultClient
+	}
+	wait := w.Backoff
+	if wait <= 0 {
+		wait = time.Second
+	}
+
+	host := w.URL
+	if u, err := url.Parse(w.URL); err == nil && u.Host != "" {
+		host = u.Host // query strings often carry tokens
+	}
+	var lastErr error
+	for attempt := 0; attempt <= w.Retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(wait)
+			wait *= 2
+		}
+		req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(body))
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "infra-check/"+meta.Version)
+		req.Header.Set(DeliveryHeader, delivery)
+		req.Header.Set(EventHeader, "scan")
+		if w.Secret != "" {
+			mac := hmac.New(sha256.New, []byte(w.Secret))
+			mac.Write(body)
+			req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = errors.Unwrap(err) // *url.Error repeats the URL
+			if lastErr == nil {
+				lastErr = err
+			}
+			continue
+		}
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 300))
+		resp.Body.Close()
+		if resp.StatusCode/100 == 2 {
+			return fmt.Sprintf("Webhook: delivered %d finding(s) to %s (delivery %s)", len(findings), host, delivery), nil
+		}
+		lastErr = fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode/100 != 5 {
+			break
+		}
+		if after, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && after > 0 {
+			wait = time.Duration(after) * time.Second
+		}
+	}
+	return "", fmt.Errorf("delivering to %s: %v", host, lastErr)
+}
@@ -0,0 +1,266 @@
+// static analysis of Pulumi YAML programs and stack configuration
+package pulumi
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// Project is the subset of Pulumi.yaml the checks need.
+// Only YAML-runtime projects declare resources inline.
+type Project struct {
+	Name      string                 `yaml:"name"`
+	Runtime   interface{}            `yaml:"runtime"`
+	Config    map[string]interface{} `yaml:"config"`
+	Resources map[string]Resource    `yaml:"resources"`
+}
+
+type Resource struct {
+	Type       string                 `yaml:"type"`
+	Properties map[string]interface{} `yaml:"properties"`
+}
+
+// Stack is a Pulumi.<stack>.yaml configuration file.
+type Stack struct {
+	Config map[string]interface{} `yaml:"config"`
+}
+
+var deprecatedResources = map[string]string{
+	"aws:s3:BucketObject":                       "Use aws:s3:BucketObjectv2 instead.",
+	"aws:elasticsearch:Domain":                  "Use aws:opensearch:Domain instead.",
+	"aws:ec2:LaunchConfiguration":               "Use aws:ec2:LaunchTemplate instead.",
+	"aws:elasticloadbalancing:LoadBalancer":     "Classic load balancers are deprecated; use aws:lb:LoadBalancer instead.",
+	"aws:applicationloadbalancing:LoadBalancer": "Use aws:lb:LoadBalancer instead.",
+	"azure:core:ResourceGroup":                  "The azure provider is deprecated; use azure-native:resources:ResourceGroup instead.",
+	"kubernetes:extensions/v1beta1:Ingress":     "Use kubernetes:networking.k8s.io/v1:Ingress instead.",
+	"kubernetes:apps/v1beta1:Deployment":        "Use kubernetes:apps/v1:Deployment instead.",
+}
+
+// Providers whose resources accept a tags property.
+var taggableProviders = []string{"aws:", "azure-native:", "azure:"}
+
+// Keywords to detect hardcoded secrets in config keys and resource properties
+var secretKeywords = []string{"password", "secret", "token", "key", "pwd"}
+
+func containsSecretKeyword(s string) bool {
+	s = strings.ToLower(s)
+	for _, kw := range secretKeywords {
+		if strings.Contains(s, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// Scan finds Pulumi.yaml projects and Pulumi.<stack>.yaml stack files under path.
+// Stack files are analysed together with the project in the same directory.
+func Scan(path string) ([]finding.Finding, error) {
+	var findings []finding.Finding
+	projects := make(map[string]string) // dir -> project file
+	stacks := make(map[string][]string) // dir -> stack files
+
+	err := scanner.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		base := filepath.Base(p)
+		dir := filepath.Dir(p)
+		switch {
+		case base == "Pulumi.yaml" || base == "Pulumi.yml":
+			projects[dir] = p
+		case strings.HasPrefix(base, "Pulumi.") && (strings.HasSuffix(base, ".yaml") || strings.HasSuffix(base, ".yml")):
+			stacks[dir] = append(stacks[dir], p)
+		}
+		return nil
+	})
+	if err != nil {
+		return findings, err
+	}
+
+	dirs := make(map[string]bool)
+	for d := range projects {
+		dirs[d] = true
+	}
+	for d := range stacks {
+		dirs[d] = true
+	}
+	sorted := make([]string, 0, len(dirs))
+	for d := range dirs {
+		sorted = append(sorted, d)
+	}
+	sort.Strings(sorted)
+
+	for _, dir := range sorted {
+		findings = append(findings, scanDir(projects[dir], stacks[dir])...)
+	}
+	return findings, nil
+}
+
+func scanDir(projectFile string, stackFiles []string) []finding.Finding {
+	var findings []finding.Finding
+
+	// Stack config: plaintext secrets and default tags.
+	defaultTags := false
+	for _, sf := range stackFiles {
+		var stack Stack
+		if fs := readYAML(sf, &stack); fs != nil {
+			findings = append(findings, fs...)
+			continue
+		}
+		usesAWS := false
+		keys := sortedKeys(stack.Config)
+		for _, key := range keys {
+			if key == "aws:defaultTags" {
+				defaultTags = true
+			}
+			if strings.HasPrefix(key, "aws:") {
+				usesAWS = true
+			}
+			findings = append(findings, checkConfigValue(sf, key, stack.Config[key])...)
+		}
+		if usesAWS && stack.Config["aws:defaultTags"] == nil {
+			findings = append(findings, finding.Finding{
+				File:     sf,
+				Severity: finding.Warning,
+				Message:  "Stack config missing 'aws:defaultTags' (resources are not tagged by default)",
+			})
+		}
+	}
+
+	if projectFile == "" {
+		return findings
+	}
+	var project Project
+	if fs := readYAML(projectFile, &project); fs != nil {
+		return append(findings, fs...)
+	}
+
+	// Project config declarations: defaults for secrets must not be literals.
+	for _, key := range sortedKeys(project.Config) {
+		decl, ok := project.Config[key].(map[string]interface{})
+		if !ok {
+			findings = append(findings, checkConfigValue(projectFile, key, project.Config[key])...)
+			continue
+		}
+		if secret, _ := decl["secret"].(bool); secret {
+			continue
+		}
+		if def, ok := decl["default"].(string); ok && def != "" && containsSecretKeyword(key) {
+			findings = append(findings, finding.Finding{
+				File:     projectFile,
+				Severity: finding.Error,
+				Message:  fmt.Sprintf("Config '%s' has a hardcoded default secret (declare it with secret: true and set it with 'pulumi config set --secret')", key),
+			})
+		}
+	}
+
+	names := make([]string, 0, len(project.Resources))
+	for name := range project.Resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		res := project.Resources[name]
+		if msg, deprecated := deprecatedResources[res.Type]; deprecated {
+			findings = append(findings, finding.Finding{
+				File:     projectFile,
+				Severity: finding.Warning,
+				Message:  fmt.Sprintf("Resource '%s' uses deprecated type '%s': %s", name, res.Type, msg),
+			})
+		}
+		if isTaggable(res.Type) && !defaultTags {
+			if _, ok := res.Properties["tags"]; !ok {
+				findings = append(findings, finding.Finding{
+					File:     projectFile,
+					Severity: finding.Warning,
+					Message:  fmt.Sprintf("Resource '%s' (%s) missing 'tags' and no stack sets default tags", name, res.Type),
+				})
+			}
+		}
+		for _, prop := range sortedKeys(res.Properties) {
+			if s, ok := res.Properties[prop].(string); ok && containsSecretKeyword(prop) && isLiteral(s) {
+				findings = append(findings, finding.Finding{
+					File:     projectFile,
+					Severity: finding.Error,
+					Message:  fmt.Sprintf("Resource '%s' property '%s' may contain a hardcoded secret", name, prop),
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+// checkConfigValue flags plaintext values for secret-looking config keys.
+// Encrypted values are written by Pulumi as {secure: <ciphertext>}.
+func checkConfigValue(file, key string, value interface{}) []finding.Finding {
+	var findings []finding.Finding
+	switch v := value.(type) {
+	case string:
+		if containsSecretKeyword(configName(key)) && v != "" {
+			findings = append(findings, finding.Finding{
+				File:     file,
+				Severity: finding.Error,
+				Message:  fmt.Sprintf("Config '%s' stores a secret in plaintext (use 'pulumi config set --secret')", key),
+			})
+		}
+	case map[string]interface{}:
+		if _, secure := v["secure"]; secure {
+			return nil
+		}
+		for _, k := range sortedKeys(v) {
+			findings = append(findings, checkConfigValue(file, key+"."+k, v[k])...)
+		}
+	}
+	return findings
+}
+
+// configName strips the namespace from a config key (e.g. "myproj:dbPassword").
+func configName(key string) string {
+	if i := strings.LastIndex(key, ":"); i >= 0 {
+		return key[i+1:]
+	}
+	return key
+}
+
+// isLiteral reports whether a property value is a literal rather than an interpolation or config read.
+func isLiteral(s string) bool {
+	return s != "" && !strings.Contains(s, "${")
+}
+
+func isTaggable(resourceType string) bool {
+	for _, prefix := range taggableProviders {
+		if strings.HasPrefix(resourceType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func readYAML(p string, out interface{}) []finding.Finding {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return []finding.Finding{{File: p, Severity: finding.Error, Message: fmt.Sprintf("Failed to read file: %v", err)}}
+	}
+	if err := yaml.Unmarshal(data, out); err != nil {
+		return []finding.Finding{{File: p, Severity: finding.Error, Message: fmt.Sprintf("YAML parse error: %v", err)}}
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
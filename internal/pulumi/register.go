@@ -0,0 +1,22 @@
+package pulumi
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+func init() {
+	scanner.Register(scanner.Simple{
+		ID:       "pulumi",
+		Summary:  "Scan Pulumi YAML programs and stack config files in the specified directories or files",
+		Matcher:  isPulumiFile,
+		ScanFunc: Scan,
+	})
+}
+
+// isPulumiFile matches Pulumi.yaml projects and Pulumi.<stack>.yaml stack files.
+func isPulumiFile(p string) bool {
+	return strings.HasPrefix(filepath.Base(p), "Pulumi.") && scanner.Extensions(".yml", ".yaml")(p)
+}
@@ -0,0 +1,51 @@
+package pulumi
+
+import (
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/registry"
+)
+
+func init() {
+	scanners := []string{"pulumi"}
+	registry.Register(
+		registry.Rule{
+			ID: "PUL001", Name: "No default tags", Severity: finding.Warning, Category: "tagging", Scanners: scanners,
+			Description: "A stack config sets no aws:defaultTags, so resources without tags of their own are untagged.",
+			Messages:    []string{"Stack config missing 'aws:defaultTags' *"},
+			Remediation: `config:
+  aws:defaultTags:
+    tags:
+      Owner: platform`,
+		},
+		registry.Rule{
+			ID: "PUL002", Name: "Untagged resource", Severity: finding.Warning, Category: "tagging", Scanners: scanners,
+			Description: "A taggable resource has no tags and no stack provides default tags.",
+			Messages:    []string{"Resource '*' (*) missing 'tags' and no stack sets default tags"},
+			Remediation: `tags:
+  Owner: platform
+  Environment: prod`,
+		},
+		registry.Rule{
+			ID: "PUL003", Name: "Deprecated resource type", Severity: finding.Warning, Category: "deprecation", Scanners: scanners,
+			Description: "A resource uses a type token its provider has deprecated.",
+			Messages:    []string{"Resource '*' uses deprecated type '*:*': *"},
+			Remediation: `# use the type token the provider recommends
+type: aws:s3:BucketV2`,
+		},
+		registry.Rule{
+			ID: "PUL004", Name: "Hardcoded secret", Severity: finding.Error, Category: "secrets", Scanners: scanners,
+			Compliance:  registry.HardcodedSecrets,
+			Description: "A resource property or config default with a secret-like name is a literal instead of a Pulumi secret.",
+			Messages:    []string{"Resource '*' property '*' may contain a hardcoded secret", "Config '*' has a hardcoded default secret *"},
+			Remediation: `pulumi config set --secret dbPassword 's3cr3t'
+# then reference it: password: ${dbPassword}`,
+		},
+		registry.Rule{
+			ID: "PUL005", Name: "Plaintext config secret", Severity: finding.Error, Category: "secrets", Scanners: scanners,
+			Compliance:  registry.HardcodedSecrets,
+			Description: "A stack config value with a secret-like name is stored in plaintext rather than encrypted with --secret.",
+			Messages:    []string{"Config '*' stores a secret in plaintext *"},
+			Remediation: "pulumi config set --secret dbPassword 's3cr3t'",
+		},
+	)
+}
@@ -0,0 +1,34 @@
+package puppet
+
+import (
+	"fmt"
+
+	"github.com/salchaD-27/infra-check/internal/filemode"
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// checkFileModes flags file resources with a literal mode any user may write, such as
+// '0777' or 'o+w', naming the path they manage: the path parameter, or else the title.
+func checkFileModes(p string, m *Manifest) []finding.Finding {
+	var findings []finding.Finding
+	for _, r := range m.Resources {
+		if r.Type != "file" {
+			continue
+		}
+		mode, ok := r.Param("mode")
+		if !ok || !mode.Value.IsLiteral() || !filemode.WorldWritable(mode.Value.Text) {
+			continue
+		}
+		path := r.Title.String()
+		if a, ok := r.Param("path"); ok && a.Value.IsLiteral() {
+			path = a.Value.Text
+		}
+		findings = append(findings, finding.Finding{
+			File:     p,
+			Severity: finding.Warning,
+			Message:  fmt.Sprintf("%s makes '%s' world-writable (mode '%s'); any local user can modify it", r.Ref(), path, mode.Value.Text),
+			Line:     mode.Line,
+		})
+	}
+	return findings
+}
@@ -0,0 +1,474 @@
+package puppet
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/glob"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// Hiera data.
+//
+// Every hiera.yaml under the scan root is read for its hierarchy: the data directory and
+// the paths or globs of each level, and whether the level is read through hiera-eyaml.
+// A module or environment with a data/ directory next to manifests/ but no hiera.yaml gets
+// Hiera's default common.yaml hierarchy. The data files are checked for plaintext secrets,
+// and their keys back the lookup() and hiera() calls in manifests. Hierarchies using other
+// backends (a database, a custom function) cannot be read, so lookups are then not checked.
+
+// Key name fragments that mark a value as a secret
+var hieraSecretKeywords = []string{"password", "passwd", "secret", "token", "api_key", "apikey", "private_key"}
+
+// Hiera 5 backends whose data files are YAML or JSON on disk
+var fileBackends = map[string]bool{"yaml_data": true, "json_data": true, "eyaml_lookup_key": true}
+
+// Interpolations in hierarchy paths: %{facts.os.family}, %{::trusted.certname}
+var hieraInterpolationRegex = regexp.MustCompile(`%\{[^}]*\}`)
+
+// hieraLevel is one level of a hierarchy.
+type hieraLevel struct {
+	name     string
+	datadir  string   // absolute data directory
+	patterns []string // data file patterns relative to datadir, interpolations as '*'
+	eyaml    bool
+}
+
+// hieraData is what the hierarchies under a scan root provide.
+type hieraData struct {
+	configs  int
+	levels   []hieraLevel
+	keys     map[string]bool
+	opaque   bool // a hierarchy reads data the scanner cannot, so any key may exist
+	findings []finding.Finding
+}
+
+// loadHiera reads the hierarchies under root and checks their data files.
+func loadHiera(root string) *hieraData {
+	h := &hieraData{keys: make(map[string]bool)}
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return h
+	}
+	var configs []string
+	var implicit []string
+	scanner.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !info.IsDir() && info.Name() == "hiera.yaml" {
+			configs = append(configs, p)
+		}
+		if info.IsDir() && info.Name() == "data" {
+			parent := filepath.Dir(p)
+			if isDir(filepath.Join(parent, "manifests")) && !isFile(filepath.Join(parent, "hiera.yaml")) {
+				implicit = append(implicit, p)
+			}
+		}
+		return nil
+	})
+	for _, p := range configs {
+		h.configs++
+		h.readConfig(p)
+	}
+	for _, dir := range implicit {
+		h.configs++
+		h.levels = append(h.levels, hieraLevel{name: "Common", datadir: dir, patterns: []string{"common.yaml"}})
+	}
+	h.checkData()
+	return h
+}
+
+// isHieraData reports whether p is a data file the scanner reads: a YAML, JSON or eyaml
+// file under a data directory of the hiera.yaml in one of its parent directories, or under
+// the implicit data/ directory of a module or environment.
+func isHieraData(p string) bool {
+	switch filepath.Ext(p) {
+	case ".yaml", ".yml", ".json", ".eyaml":
+	default:
+		return false
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return false
+	}
+	for dir := filepath.Dir(abs); ; dir = filepath.Dir(dir) {
+		if config := filepath.Join(dir, "hiera.yaml"); isFile(config) {
+			for _, datadir := range hieraDatadirs(config) {
+				if inDir(abs, datadir) {
+					return true
+				}
+			}
+		} else if isDir(filepath.Join(dir, "manifests")) && inDir(abs, filepath.Join(dir, "data")) {
+			return true
+		}
+		if filepath.Dir(dir) == dir {
+			return false
+		}
+	}
+}
+
+// datadirCache holds the data directories of the hiera.yaml files read by hieraDatadirs,
+// by path and modification time, as the matcher asks for them for every data file.
+var datadirCache sync.Map
+
+// hieraDatadirs returns the absolute data directories of the levels of the hiera.yaml at config.
+func hieraDatadirs(config string) []string {
+	info, err := os.Stat(config)
+	if err != nil {
+		return nil
+	}
+	key := config + "\x00" + info.ModTime().String()
+	if dirs, ok := datadirCache.Load(key); ok {
+		return dirs.([]string)
+	}
+	h := &hieraData{keys: make(map[string]bool)}
+	h.readConfig(config)
+	var dirs []string
+	for _, level := range h.levels {
+		dirs = append(dirs, level.datadir)
+	}
+	datadirCache.Store(key, dirs)
+	return dirs
+}
+
+// inDir reports whether p is dir or a path below it.
+func inDir(p, dir string) bool {
+	rel, err := filepath.Rel(dir, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// readConfig adds the levels of the hiera.yaml at p.
+func (h *hieraData) readConfig(p string) {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		h.findings = append(h.findings, finding.Finding{File: p, Severity: finding.Error, Message: fmt.Sprintf("Failed to read file: %v", err)})
+		return
+	}
+	var config map[string]interface{}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		h.findings = append(h.findings, finding.Finding{File: p, Severity: finding.Error, Message: fmt.Sprintf("Hiera config parse error: %v", err)})
+		h.opaque = true
+		return
+	}
+	dir := filepath.Dir(p)
+	if version, _ := config["version"].(int); version != 5 {
+		h.findings = append(h.findings, finding.Finding{
+			File:     p,
+			Severity: finding.Warning,
+			Message:  "Hiera 3 configuration is deprecated; migrate hiera.yaml to version 5",
+		})
+		h.readConfig3(dir, config)
+		return
+	}
+
+	defaults, _ := config["defaults"].(map[string]interface{})
+	defaultDir := stringOr(defaults["datadir"], "data")
+	defaultBackend := backendOf(defaults)
+	if defaultBackend == "" {
+		defaultBackend = "yaml_data"
+	}
+	hierarchy, ok := config["hierarchy"].([]interface{})
+	if !ok {
+		h.levels = append(h.levels, hieraLevel{name: "Common", datadir: filepath.Join(dir, defaultDir), patterns: []string{"common.yaml"}})
+		return
+	}
+	for _, item := range hierarchy {
+		entry, _ := item.(map[string]interface{})
+		backend := backendOf(entry)
+		if backend == "" {
+			backend = defaultBackend
+		}
+		if !fileBackends[backend] {
+			h.opaque = true
+			continue
+		}
+		level := hieraLevel{
+			name:    stringOr(entry["name"], ""),
+			datadir: filepath.Join(dir, stringOr(entry["datadir"], defaultDir)),
+			eyaml:   backend == "eyaml_lookup_key",
+		}
+		for _, key := range []string{"path", "paths", "glob", "globs"} {
+			for _, pattern := range stringList(entry[key]) {
+				level.patterns = append(level.patterns, hieraInterpolationRegex.ReplaceAllString(pattern, "*"))
+			}
+		}
+		if mapped := stringList(entry["mapped_paths"]); len(mapped) == 3 {
+			level.patterns = append(level.patterns, hieraInterpolationRegex.ReplaceAllString(mapped[2], "*"))
+		}
+		if entry["uri"] != nil || entry["uris"] != nil {
+			h.opaque = true
+		}
+		h.levels = append(h.levels, level)
+	}
+}
+
+// readConfig3 adds the levels of a Hiera 3 configuration: :backends:, :hierarchy: and a
+// :datadir: per backend.
+func (h *hieraData) readConfig3(dir string, config map[string]interface{}) {
+	backends := stringList(config[":backends"])
+	if len(backends) == 0 {
+		backends = []string{"yaml"}
+	}
+	var hierarchy []string
+	for _, name := range stringList(config[":hierarchy"]) {
+		hierarchy = append(hierarchy, hieraInterpolationRegex.ReplaceAllString(name, "*"))
+	}
+	if len(hierarchy) == 0 {
+		hierarchy = []string{"common"}
+	}
+	for _, backend := range backends {
+		ext := map[string]string{"yaml": ".yaml", "json": ".json", "eyaml": ".eyaml"}[backend]
+		if ext == "" {
+			h.opaque = true
+			continue
+		}
+		settings, _ := config[":"+backend].(map[string]interface{})
+		datadir := stringOr(settings[":datadir"], "")
+		// global paths like /etc/puppetlabs/code/environments/%{environment}/hieradata
+		// point at the environment's hieradata directory
+		if datadir == "" || filepath.IsAbs(datadir) || strings.Contains(datadir, "%{") {
+			datadir = "hieradata"
+		}
+		level := hieraLevel{name: backend, datadir: filepath.Join(dir, datadir), eyaml: backend == "eyaml"}
+		for _, name := range hierarchy {
+			level.patterns = append(level.patterns, name+ext)
+		}
+		h.levels = append(h.levels, level)
+	}
+}
+
+// checkData reads the data files of every level: their keys back lookups, and secret-like
+// keys must be eyaml-encrypted.
+func (h *hieraData) checkData() {
+	seen := make(map[string]bool)
+	for _, level := range h.levels {
+		if seen[level.datadir] {
+			continue
+		}
+		seen[level.datadir] = true
+		scanner.Walk(level.datadir, func(p string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return nil
+			}
+			switch filepath.Ext(p) {
+			case ".yaml", ".yml", ".json", ".eyaml":
+				h.checkDataFile(p, level.datadir)
+			}
+			return nil
+		})
+	}
+}
+
+// checkDataFile records the keys of a data file and flags its plaintext secrets.
+func (h *hieraData) checkDataFile(p, datadir string) {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		h.findings = append(h.findings, finding.Finding{File: p, Severity: finding.Error, Message: fmt.Sprintf("Failed to read file: %v", err)})
+		return
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		h.findings = append(h.findings, finding.Finding{File: p, Severity: finding.Error, Message: fmt.Sprintf("Hiera data parse error: %v", err)})
+		return
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return
+	}
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key := strings.TrimPrefix(root.Content[i].Value, "::")
+		if key == "lookup_options" {
+			continue
+		}
+		h.keys[key] = true
+	}
+
+	rel, _ := filepath.Rel(datadir, p)
+	var eyamlLevel, otherEyaml string
+	for _, level := range h.levels {
+		if level.eyaml && level.datadir == datadir && glob.MatchAny(level.patterns, rel) {
+			eyamlLevel = level.name
+		}
+	}
+	for _, level := range h.levels {
+		if level.eyaml && level.datadir == datadir {
+			otherEyaml = level.name
+			break
+		}
+	}
+
+	var walk func(prefix string, n *yaml.Node)
+	walk = func(prefix string, n *yaml.Node) {
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			name := strings.TrimPrefix(k.Value, "::")
+			if prefix != "" {
+				name = prefix + "." + k.Value
+			}
+			if prefix == "" && name == "lookup_options" {
+				continue
+			}
+			if v.Kind == yaml.MappingNode {
+				walk(name, v)
+				continue
+			}
+			if v.Kind != yaml.ScalarNode || v.Tag != "!!str" || !isSecretKey(k.Value) || !isPlaintext(v.Value) {
+				continue
+			}
+			var msg string
+			switch {
+			case eyamlLevel != "":
+				msg = fmt.Sprintf("Hiera key '%s' holds a plaintext secret in the eyaml hierarchy level '%s'; encrypt it with 'eyaml encrypt'", name, eyamlLevel)
+			case otherEyaml != "":
+				msg = fmt.Sprintf("Hiera key '%s' holds a plaintext secret; move it to the eyaml hierarchy level '%s' and encrypt it", name, otherEyaml)
+			default:
+				msg = fmt.Sprintf("Hiera key '%s' holds a plaintext secret and the hierarchy has no eyaml level; encrypt it with hiera-eyaml", name)
+			}
+			h.findings = append(h.findings, finding.Finding{File: p, Severity: finding.Error, Message: msg, Line: k.Line})
+		}
+	}
+	walk("", root)
+}
+
+// checkLookups flags lookup() and hiera() calls of keys no data file defines, when the
+// call has no default to fall back on.
+func (h *hieraData) checkLookups(p string, m *Manifest) []finding.Finding {
+	if h.configs == 0 || h.opaque {
+		return nil
+	}
+	var findings []finding.Finding
+	for _, c := range m.Calls {
+		keys, hasDefault := lookupKeys(c)
+		if hasDefault || len(keys) == 0 {
+			continue
+		}
+		found := false
+		for _, key := range keys {
+			// a dotted key digs into the value of its first segment
+			root, _, _ := strings.Cut(strings.TrimPrefix(key, "::"), ".")
+			found = found || h.keys[root]
+		}
+		if found {
+			continue
+		}
+		findings = append(findings, finding.Finding{
+			File:     p,
+			Severity: finding.Warning,
+			Message:  fmt.Sprintf("Hiera lookup of '%s' has no default and no hierarchy data defines it; catalog compilation fails unless the key is provided", strings.Join(keys, "', '")),
+			Line:     c.Line,
+		})
+	}
+	return findings
+}
+
+// lookupKeys returns the literal keys a lookup or hiera call reads and whether it has a
+// default value. Calls of other functions and templated keys return no keys.
+func lookupKeys(c *Call) ([]string, bool) {
+	if len(c.Args) == 0 {
+		return nil, false
+	}
+	var hasDefault bool
+	switch c.Name {
+	case "lookup":
+		// lookup(name, type, merge, default) or lookup(name, {options})
+		hasDefault = len(c.Args) >= 4 || c.Lambda
+		if len(c.Args) == 2 && c.Args[1].Kind == Hash {
+			for _, k := range c.Args[1].Keys {
+				hasDefault = hasDefault || k.Text == "default_value" || k.Text == "default_values_hash"
+			}
+		}
+		if c.Args[0].Kind == Hash {
+			return nil, false // lookup({name => ..., default_value => ...})
+		}
+	case "hiera", "hiera_array", "hiera_hash", "hiera_include":
+		hasDefault = len(c.Args) >= 2
+	default:
+		return nil, false
+	}
+	names := []Value{c.Args[0]}
+	if c.Args[0].Kind == Array {
+		names = c.Args[0].Items
+	}
+	var keys []string
+	for _, n := range names {
+		if !n.IsLiteral() || n.Kind != String {
+			return nil, hasDefault
+		}
+		keys = append(keys, n.Text)
+	}
+	sort.Strings(keys)
+	return keys, hasDefault
+}
+
+func isSecretKey(key string) bool {
+	// profile::db::password is named by its last segment
+	if i := strings.LastIndex(key, "::"); i >= 0 {
+		key = key[i+2:]
+	}
+	key = strings.ToLower(key)
+	for _, kw := range hieraSecretKeywords {
+		if strings.Contains(key, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPlaintext reports whether a data value is a literal rather than eyaml ciphertext or an
+// interpolation such as %{lookup('db::password')}.
+func isPlaintext(v string) bool {
+	v = strings.TrimSpace(v)
+	return v != "" && !strings.HasPrefix(v, "ENC[") && !strings.Contains(v, "%{")
+}
+
+// backendOf returns the Hiera 5 backend function a level or the defaults name.
+func backendOf(entry map[string]interface{}) string {
+	for _, key := range []string{"data_hash", "lookup_key", "data_dig"} {
+		if s, ok := entry[key].(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+func stringOr(v interface{}, fallback string) string {
+	if s, ok := v.(string); ok && s != "" {
+		return s
+	}
+	return fallback
+}
+
+// stringList returns a string or list of strings as a list.
+func stringList(v interface{}) []string {
+	switch val := v.(type) {
+	case string:
+		return []string{val}
+	case []interface{}:
+		var out []string
+		for _, item := range val {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func isDir(p string) bool {
+	info, err := os.Stat(p)
+	return err == nil && info.IsDir()
+}
+
+func isFile(p string) bool {
+	info, err := os.Stat(p)
+	return err == nil && !info.IsDir()
+}
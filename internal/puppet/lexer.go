@@ -0,0 +1,237 @@
+package puppet
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Puppet DSL tokenizer.
+//
+// The lexer understands what it must to keep structure intact: comments, single- and
+// double-quoted strings (with interpolation detected), heredocs, regular expressions
+// where an operand is expected, variables, type references and multi-character operators.
+
+type tokenKind int
+
+const (
+	tokName   tokenKind = iota // bare word or qualified name: package, apache::vhost, present
+	tokRef                     // capitalized type reference: File, Apache::Vhost
+	tokVar                     // $name or $::name, without the $
+	tokString                  // quoted string or heredoc, unquoted
+	tokNumber
+	tokRegex
+	tokOp
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	interp bool // a double-quoted string or heredoc that interpolates variables
+	line   int
+	pos    int // byte offsets of the token in the source
+	end    int
+}
+
+// Operators longer than one character, longest first.
+var puppetOperators = []string{
+	"<<|", "|>>", "=>", "+>", "->", "~>", "<-", "<~", "==", "!=", "=~", "!~", ">=", "<=",
+	"<|", "|>", "<<", ">>", "&&", "||", "@@",
+}
+
+// lex tokenizes a manifest. On an unterminated string, comment or heredoc it returns the
+// tokens before it and the error.
+func lex(src string) ([]token, error) {
+	var toks []token
+	line := 1
+	resume := -1 // where lexing continues after the newline ending a heredoc tag line
+	resumeLine := 0
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			if resume >= 0 {
+				i, line, resume = resume, resumeLine, -1
+				continue
+			}
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return toks, &SyntaxError{Line: line, Msg: "unterminated comment"}
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+		case c == '\'' || c == '"':
+			tok, next, err := lexString(src, i, line)
+			if err != nil {
+				return toks, err
+			}
+			toks = append(toks, tok)
+			line += strings.Count(src[i:next], "\n")
+			i = next
+		case c == '@' && i+1 < len(src) && src[i+1] == '(':
+			tok, next, bodyEnd, err := lexHeredoc(src, i, line)
+			if err != nil {
+				return toks, err
+			}
+			toks = append(toks, tok)
+			bodyStart := i + strings.IndexByte(src[i:], '\n') + 1
+			resume, resumeLine = bodyEnd, line+1+strings.Count(src[bodyStart:bodyEnd], "\n")
+			i = next
+		case c == '$':
+			j := i + 1
+			if strings.HasPrefix(src[j:], "::") {
+				j += 2
+			}
+			for j < len(src) && (isWordChar(src[j]) || (src[j] == ':' && j+2 < len(src) && src[j+1] == ':' && isWordChar(src[j+2]))) {
+				if src[j] == ':' {
+					j++
+				}
+				j++
+			}
+			toks = append(toks, token{kind: tokVar, text: strings.TrimPrefix(src[i+1:j], "::"), line: line, pos: i, end: j})
+			i = j
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(src) && (isWordChar(src[j]) || src[j] == '.') {
+				j++
+			}
+			toks = append(toks, token{kind: tokNumber, text: src[i:j], line: line, pos: i, end: j})
+			i = j
+		case isWordChar(c) || (c == ':' && strings.HasPrefix(src[i:], "::") && i+2 < len(src) && isWordChar(src[i+2])):
+			j := i
+			for j < len(src) && (isWordChar(src[j]) || (src[j] == ':' && j+2 < len(src) && src[j+1] == ':' && isWordChar(src[j+2]))) {
+				if src[j] == ':' {
+					j++
+				}
+				j++
+			}
+			word := strings.TrimPrefix(src[i:j], "::")
+			kind := tokName
+			if word[0] >= 'A' && word[0] <= 'Z' {
+				kind = tokRef
+			}
+			toks = append(toks, token{kind: kind, text: word, line: line, pos: i, end: j})
+			i = j
+		case c == '/' && expectsOperand(toks):
+			j := i + 1
+			for j < len(src) && src[j] != '/' && src[j] != '\n' {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) || src[j] != '/' {
+				// not a regex after all
+				toks = append(toks, token{kind: tokOp, text: "/", line: line, pos: i, end: i + 1})
+				i++
+				continue
+			}
+			toks = append(toks, token{kind: tokRegex, text: src[i+1 : j], line: line, pos: i, end: j + 1})
+			i = j + 1
+		default:
+			op := src[i : i+1]
+			for _, long := range puppetOperators {
+				if strings.HasPrefix(src[i:], long) {
+					op = long
+					break
+				}
+			}
+			toks = append(toks, token{kind: tokOp, text: op, line: line, pos: i, end: i + len(op)})
+			i += len(op)
+		}
+	}
+	return toks, nil
+}
+
+// lexString reads the quoted string starting at i and returns it with the offset after it.
+func lexString(src string, i, line int) (token, int, error) {
+	quote := src[i]
+	var b strings.Builder
+	interp := false
+	j := i + 1
+	for ; j < len(src) && src[j] != quote; j++ {
+		if src[j] == '\\' && j+1 < len(src) {
+			next := src[j+1]
+			if next == quote || next == '\\' || (quote == '"' && next == '$') {
+				b.WriteByte(next)
+				j++
+				continue
+			}
+		}
+		if quote == '"' && src[j] == '$' && j+1 < len(src) && (src[j+1] == '{' || src[j+1] == ':' || isWordChar(src[j+1])) {
+			interp = true
+		}
+		b.WriteByte(src[j])
+	}
+	if j >= len(src) {
+		return token{}, 0, &SyntaxError{Line: line, Msg: "unterminated string"}
+	}
+	return token{kind: tokString, text: b.String(), interp: interp, line: line, pos: i, end: j + 1}, j + 1, nil
+}
+
+// lexHeredoc reads the heredoc tag @(END) at i. It returns the token holding the body,
+// the offset after the tag and the offset after the body's terminating line.
+func lexHeredoc(src string, i, line int) (token, int, int, error) {
+	tagEnd := strings.IndexByte(src[i:], ')')
+	eol := strings.IndexByte(src[i:], '\n')
+	if tagEnd < 0 || eol < 0 || tagEnd > eol {
+		return token{}, 0, 0, &SyntaxError{Line: line, Msg: "malformed heredoc tag"}
+	}
+	spec := src[i+2 : i+tagEnd]
+	tag, _, _ := strings.Cut(spec, ":")
+	tag, _, _ = strings.Cut(tag, "/")
+	tag = strings.TrimSpace(tag)
+	interp := strings.HasPrefix(tag, `"`)
+	tag = strings.Trim(tag, `"`)
+
+	bodyStart := i + eol + 1
+	for j := bodyStart; j <= len(src); {
+		k := strings.IndexByte(src[j:], '\n')
+		lineEnd := len(src)
+		if k >= 0 {
+			lineEnd = j + k
+		}
+		marker := strings.TrimLeft(strings.TrimSpace(src[j:lineEnd]), "|-")
+		if strings.TrimSpace(marker) == tag {
+			body := src[bodyStart:j]
+			tok := token{kind: tokString, text: body, interp: interp && strings.Contains(body, "$"), line: line, pos: i, end: i + tagEnd + 1}
+			next := lineEnd
+			if next < len(src) {
+				next++
+			}
+			return tok, i + tagEnd + 1, next, nil
+		}
+		if k < 0 {
+			break
+		}
+		j = lineEnd + 1
+	}
+	return token{}, 0, 0, &SyntaxError{Line: line, Msg: fmt.Sprintf("unterminated heredoc '%s'", tag)}
+}
+
+// expectsOperand reports whether a '/' after toks starts a regex rather than dividing.
+func expectsOperand(toks []token) bool {
+	if len(toks) == 0 {
+		return true
+	}
+	prev := toks[len(toks)-1]
+	switch prev.kind {
+	case tokOp:
+		return prev.text != ")" && prev.text != "]" && prev.text != "}"
+	case tokName:
+		return prev.text == "node" || prev.text == "and" || prev.text == "or" || prev.text == "in"
+	}
+	return false
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
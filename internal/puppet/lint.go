@@ -0,0 +1,115 @@
+package puppet
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// Native style checks.
+//
+// A subset of puppet-lint's checks, run on every manifest so the scanner needs no Ruby
+// toolchain: arrow_alignment, quoted_booleans, 140chars and documentation. puppet-lint
+// itself is run only when asked for.
+
+// Lines may be this long, in characters.
+const maxLineLength = 140
+
+// lintManifest runs the native style checks on a parsed manifest.
+func lintManifest(p, content string, m *Manifest) []finding.Finding {
+	var findings []finding.Finding
+	add := func(line int, msg string) {
+		findings = append(findings, finding.Finding{File: p, Severity: finding.Warning, Message: msg, Line: line})
+	}
+
+	lines := strings.Split(content, "\n")
+	column := func(offset int) int {
+		return offset - strings.LastIndexByte(content[:offset], '\n')
+	}
+
+	seen := make(map[int]bool) // resources with several titles share their parameters
+	for _, r := range m.Resources {
+		if len(r.Params) == 0 || seen[r.Params[0].arrowPos] {
+			continue
+		}
+		seen[r.Params[0].arrowPos] = true
+
+		// arrow_alignment: parameters on lines of their own align their arrows one space
+		// after the longest name
+		perLine := make(map[int]int)
+		for _, a := range r.Params {
+			perLine[a.Line]++
+		}
+		var group []Attribute
+		expected := 0
+		for _, a := range r.Params {
+			if perLine[a.Line] == 1 {
+				group = append(group, a)
+				expected = max(expected, column(a.nameEnd)+1)
+			}
+		}
+		if len(group) > 1 {
+			for _, a := range group {
+				if found := column(a.arrowPos); found != expected {
+					add(a.Line, fmt.Sprintf("Arrow of parameter '%s' in %s is not aligned (expected in column %d, found in column %d)", a.Name, r.Ref(), expected, found))
+				}
+			}
+		}
+
+		// quoted_booleans: bare true and false are Booleans, so a string with that text was quoted
+		for _, a := range r.Params {
+			if a.Value.Kind == String && (a.Value.Text == "true" || a.Value.Text == "false") {
+				add(a.Line, fmt.Sprintf("Quoted boolean '%s' in parameter '%s' of %s; use a bare %s", a.Value.Text, a.Name, r.Ref(), a.Value.Text))
+			}
+		}
+	}
+
+	// quoted_booleans in the parameter defaults of classes and defined types too
+	quotedDefaults := func(kind string, c *Class) {
+		for _, param := range c.Params {
+			if v := param.Default; v != nil && v.Kind == String && (v.Text == "true" || v.Text == "false") {
+				add(param.Line, fmt.Sprintf("Quoted boolean '%s' in parameter '$%s' of %s '%s'; use a bare %s", v.Text, param.Name, kind, c.Name, v.Text))
+			}
+		}
+	}
+	for _, c := range m.Classes {
+		quotedDefaults("class", c)
+	}
+	for _, d := range m.Defines {
+		quotedDefaults("defined type", d)
+	}
+
+	// 140chars: URLs and template paths are allowed to run long
+	for i, l := range lines {
+		l = strings.TrimRight(l, "\r")
+		if utf8.RuneCountInString(l) <= maxLineLength || strings.Contains(l, "://") || strings.Contains(l, "template(") {
+			continue
+		}
+		add(i+1, fmt.Sprintf("Line %d is longer than %d characters", i+1, maxLineLength))
+	}
+
+	// documentation: a comment before the definition, blank lines allowed in between
+	documented := func(line int) bool {
+		for i := line - 2; i >= 0; i-- {
+			trimmed := strings.TrimSpace(lines[i])
+			if trimmed == "" {
+				continue
+			}
+			return strings.HasPrefix(trimmed, "#") || strings.HasSuffix(trimmed, "*/")
+		}
+		return false
+	}
+	for _, c := range m.Classes {
+		if !documented(c.Line) {
+			add(c.Line, fmt.Sprintf("Class '%s' is not documented; add a comment above it", c.Name))
+		}
+	}
+	for _, d := range m.Defines {
+		if !documented(d.Line) {
+			add(d.Line, fmt.Sprintf("Defined type '%s' is not documented; add a comment above it", d.Name))
+		}
+	}
+	return findings
+}
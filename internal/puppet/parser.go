@@ -0,0 +1,663 @@
+package puppet
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Puppet DSL parser.
+//
+// Parse builds the declarations checks care about — classes, defined types, nodes and
+// resources with their parameters — and skips expressions it does not need to
+// understand. Conditionals, case options, lambdas and other blocks are descended into,
+// so resources declared anywhere are found, each with the class or defined type holding it.
+
+// Manifest is a parsed Puppet manifest.
+type Manifest struct {
+	Classes   []*Class
+	Defines   []*Class // defined types, which share the shape of classes
+	Nodes     []*Node
+	Resources []*Resource // every resource declaration, in source order
+	Calls     []*Call     // every prefix function call, e.g. lookup('key'), in source order
+	Vars      []string    // variables assigned at top or node scope
+}
+
+// Call is a function call written name(args).
+type Call struct {
+	Name      string
+	Args      []Value
+	Lambda    bool   // the call is followed by a lambda block: name(args) |$x| { ... }
+	Container string // enclosing class or defined type, "" at top level or in a node
+	Line      int
+}
+
+// Class is a class or defined type definition.
+type Class struct {
+	Name     string
+	Params   []Param
+	Inherits string
+	Vars     []string // variables the body assigns
+	Line     int
+
+	body [2]int // token range of the body
+}
+
+// Defines reports whether name is a parameter or a variable the body assigns.
+func (c *Class) Defines(name string) bool {
+	for _, param := range c.Params {
+		if param.Name == name {
+			return true
+		}
+	}
+	for _, v := range c.Vars {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Param is a class or defined type parameter.
+type Param struct {
+	Name    string
+	Type    string // the type expression, e.g. Optional[String], if any
+	Default *Value
+	Line    int
+}
+
+// Node is a node definition.
+type Node struct {
+	Names []string
+	Line  int
+}
+
+// Resource is one resource declaration. A declaration with several titles yields one
+// Resource per title.
+type Resource struct {
+	Type      string // lower-case type name, e.g. package, apache::vhost, class
+	Title     Value
+	Params    []Attribute
+	Virtual   bool   // @type { ... }
+	Exported  bool   // @@type { ... }
+	Container string // enclosing class or defined type, "" at top level or in a node
+	Line      int
+}
+
+// Attribute is a resource parameter: name => value.
+type Attribute struct {
+	Name  string
+	Value Value
+	Line  int
+
+	nameEnd, arrowPos int // byte offsets of the end of the name and of the arrow
+}
+
+// Param returns the resource's parameter named name.
+func (r *Resource) Param(name string) (Attribute, bool) {
+	for _, a := range r.Params {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Attribute{}, false
+}
+
+// Ref returns the resource reference, e.g. Package['nginx'].
+func (r *Resource) Ref() string {
+	parts := strings.Split(r.Type, "::")
+	for i, p := range parts {
+		if p != "" {
+			parts[i] = strings.ToUpper(p[:1]) + p[1:]
+		}
+	}
+	return fmt.Sprintf("%s['%s']", strings.Join(parts, "::"), r.Title.String())
+}
+
+// ValueKind classifies a parsed value.
+type ValueKind int
+
+const (
+	String ValueKind = iota
+	Number
+	Boolean
+	Undef
+	Variable
+	Array
+	Hash
+	Expression // anything else: function calls, references, operators, selectors
+)
+
+// Value is a parameter, title or default value.
+type Value struct {
+	Kind         ValueKind
+	Text         string // string contents, number, variable name or the source of an expression
+	Interpolated bool   // a double-quoted string or heredoc interpolating variables
+	Items        []Value
+	Keys         []Value // hash keys, parallel to Items
+	Line         int
+}
+
+// IsLiteral reports whether v is a constant written in the manifest: a string without
+// interpolation, a number or a boolean.
+func (v Value) IsLiteral() bool {
+	switch v.Kind {
+	case String:
+		return !v.Interpolated
+	case Number, Boolean:
+		return true
+	}
+	return false
+}
+
+// String renders v: strings and scalars as their text, arrays and hashes in Puppet syntax.
+func (v Value) String() string {
+	switch v.Kind {
+	case Variable:
+		return "$" + v.Text
+	case Array:
+		items := make([]string, len(v.Items))
+		for i, item := range v.Items {
+			items[i] = item.String()
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case Hash:
+		entries := make([]string, len(v.Items))
+		for i, item := range v.Items {
+			entries[i] = v.Keys[i].String() + " => " + item.String()
+		}
+		return "{" + strings.Join(entries, ", ") + "}"
+	}
+	return v.Text
+}
+
+// SyntaxError is a manifest the parser cannot tokenize or whose brackets do not balance.
+type SyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// Parse parses a manifest. It returns what it could parse along with the first syntax
+// error, a *SyntaxError: an unterminated string, comment or heredoc, or unbalanced brackets.
+func Parse(src []byte) (*Manifest, error) {
+	text := string(src)
+	toks, lexErr := lex(text)
+	p := &parser{src: text, toks: toks, m: &Manifest{}}
+	err := p.balanced()
+	p.statements("")
+	p.calls()
+	if lexErr != nil {
+		return p.m, lexErr
+	}
+	return p.m, err
+}
+
+type parser struct {
+	src   string
+	toks  []token
+	i     int
+	m     *Manifest
+	class *Class // the class or defined type being parsed
+}
+
+// Words that start statements other than resource declarations.
+var statementKeywords = map[string]bool{
+	"if": true, "elsif": true, "else": true, "unless": true, "case": true,
+	"function": true, "type": true, "plan": true, "application": true, "site": true,
+	"and": true, "or": true, "in": true, "default": true,
+}
+
+func (p *parser) peek(offset int) token {
+	if p.i+offset < len(p.toks) {
+		return p.toks[p.i+offset]
+	}
+	return token{kind: tokOp, text: ""}
+}
+
+func (p *parser) atOp(offset int, op string) bool {
+	t := p.peek(offset)
+	return t.kind == tokOp && t.text == op
+}
+
+// balanced reports the first unbalanced bracket.
+func (p *parser) balanced() error {
+	pairs := map[string]string{")": "(", "]": "[", "}": "{"}
+	var stack []token
+	for _, t := range p.toks {
+		if t.kind != tokOp {
+			continue
+		}
+		switch t.text {
+		case "(", "[", "{":
+			stack = append(stack, t)
+		case ")", "]", "}":
+			if len(stack) == 0 || stack[len(stack)-1].text != pairs[t.text] {
+				return &SyntaxError{Line: t.line, Msg: fmt.Sprintf("unexpected '%s'", t.text)}
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if len(stack) > 0 {
+		open := stack[len(stack)-1]
+		return &SyntaxError{Line: open.line, Msg: fmt.Sprintf("'%s' is never closed", open.text)}
+	}
+	return nil
+}
+
+// statements parses until the '}' closing the current block (consumed) or the end.
+func (p *parser) statements(container string) {
+	for p.i < len(p.toks) {
+		t := p.peek(0)
+		switch {
+		case t.kind == tokOp && t.text == "}":
+			p.i++
+			return
+		case t.kind == tokOp && t.text == "{":
+			p.i++
+			p.statements(container)
+		case t.kind == tokName && (t.text == "class" || t.text == "define") && p.peek(1).kind == tokName:
+			p.definition(t.text == "define")
+		case t.kind == tokName && t.text == "node":
+			p.node()
+		case t.kind == tokName && statementKeywords[t.text]:
+			// conditions and signatures up to the block, which is parsed as statements
+			p.i++
+			p.skipTo("{")
+		case t.kind == tokOp && (t.text == "@" || t.text == "@@") && p.peek(1).kind == tokName && p.atOp(2, "{"):
+			p.i++
+			p.resources(container, t.text == "@", t.text == "@@")
+		case t.kind == tokName && p.atOp(1, "{"):
+			p.resources(container, false, false)
+		case t.kind == tokRef && p.atOp(1, "{"):
+			// resource defaults: File { mode => '0644' }
+			p.i += 2
+			p.skipBlock()
+		case t.kind == tokVar && p.atOp(1, "="):
+			if p.class != nil {
+				p.class.Vars = append(p.class.Vars, t.text)
+			} else {
+				p.m.Vars = append(p.m.Vars, t.text)
+			}
+			p.i++
+		default:
+			p.i++
+		}
+	}
+}
+
+// Words followed by a parenthesized list that is not a call's arguments.
+var definitionKeywords = map[string]bool{
+	"class": true, "define": true, "function": true, "type": true, "plan": true,
+}
+
+// calls collects the function calls in the manifest.
+func (p *parser) calls() {
+	for i := 0; i+1 < len(p.toks); i++ {
+		t := p.toks[i]
+		if t.kind != tokName || statementKeywords[t.text] || p.toks[i+1].kind != tokOp || p.toks[i+1].text != "(" {
+			continue
+		}
+		if i > 0 && p.toks[i-1].kind == tokName && definitionKeywords[p.toks[i-1].text] {
+			continue
+		}
+		if i > 0 && p.toks[i-1].kind == tokOp && p.toks[i-1].text == "." {
+			continue // method call: $x.lookup(...)
+		}
+		end := closes(p.toks, i+1)
+		if end < 0 {
+			continue
+		}
+		c := &Call{Name: strings.TrimPrefix(t.text, "::"), Container: p.containerAt(i), Line: t.line}
+		for _, arg := range splitTopLevel(p.toks[i+2:end], ",") {
+			c.Args = append(c.Args, p.value(arg))
+		}
+		if end+1 < len(p.toks) && p.toks[end+1].kind == tokOp && p.toks[end+1].text == "|" {
+			c.Lambda = true
+		}
+		p.m.Calls = append(p.m.Calls, c)
+	}
+}
+
+// containerAt returns the innermost class or defined type whose body holds token i.
+func (p *parser) containerAt(i int) string {
+	name, size := "", len(p.toks)+1
+	for _, c := range append(p.m.Classes, p.m.Defines...) {
+		if c.body[0] <= i && i < c.body[1] && c.body[1]-c.body[0] < size {
+			name, size = c.Name, c.body[1]-c.body[0]
+		}
+	}
+	return name
+}
+
+// skipTo advances to the next op at bracket depth zero, without consuming it.
+func (p *parser) skipTo(op string) {
+	depth := 0
+	for ; p.i < len(p.toks); p.i++ {
+		t := p.toks[p.i]
+		if t.kind != tokOp {
+			continue
+		}
+		if depth == 0 && t.text == op {
+			return
+		}
+		switch t.text {
+		case "(", "[":
+			depth++
+		case ")", "]":
+			depth--
+		case "{":
+			if depth > 0 {
+				depth++
+			}
+		case "}":
+			if depth > 0 {
+				depth--
+			} else {
+				return // end of the enclosing block
+			}
+		}
+	}
+}
+
+// skipBlock advances past the '}' matching an already consumed '{'.
+func (p *parser) skipBlock() {
+	depth := 1
+	for ; p.i < len(p.toks); p.i++ {
+		t := p.toks[p.i]
+		if t.kind != tokOp {
+			continue
+		}
+		switch t.text {
+		case "{":
+			depth++
+		case "}":
+			if depth--; depth == 0 {
+				p.i++
+				return
+			}
+		}
+	}
+}
+
+// definition parses class NAME (params) inherits PARENT { ... } or define NAME (params) { ... }.
+func (p *parser) definition(isDefine bool) {
+	c := &Class{Name: strings.TrimPrefix(p.peek(1).text, "::"), Line: p.peek(0).line}
+	p.i += 2
+	if p.atOp(0, "(") {
+		p.i++
+		c.Params = p.params()
+	}
+	if t := p.peek(0); t.kind == tokName && t.text == "inherits" {
+		c.Inherits = p.peek(1).text
+		p.i += 2
+	}
+	if isDefine {
+		p.m.Defines = append(p.m.Defines, c)
+	} else {
+		p.m.Classes = append(p.m.Classes, c)
+	}
+	p.skipTo("{")
+	if p.atOp(0, "{") {
+		p.i++
+		outer := p.class
+		p.class, c.body[0] = c, p.i
+		p.statements(c.Name)
+		p.class, c.body[1] = outer, p.i
+	}
+}
+
+// params parses a parameter list after its '(' through the closing ')'.
+func (p *parser) params() []Param {
+	var params []Param
+	for p.i < len(p.toks) && !p.atOp(0, ")") {
+		start := p.i
+		p.skipToAny(",", ")")
+		toks := p.toks[start:p.i]
+		if p.atOp(0, ",") {
+			p.i++
+		}
+		varAt := -1
+		for j, t := range toks {
+			if t.kind == tokVar {
+				varAt = j
+				break
+			}
+		}
+		if varAt < 0 {
+			continue
+		}
+		param := Param{Name: toks[varAt].text, Line: toks[varAt].line}
+		if varAt > 0 {
+			param.Type = p.source(toks[:varAt])
+		}
+		if varAt+1 < len(toks) && toks[varAt+1].kind == tokOp && toks[varAt+1].text == "=" {
+			v := p.value(toks[varAt+2:])
+			param.Default = &v
+		}
+		params = append(params, param)
+	}
+	p.i++ // ')'
+	return params
+}
+
+// skipToAny advances to the first of ops at bracket depth zero.
+func (p *parser) skipToAny(ops ...string) {
+	depth := 0
+	for ; p.i < len(p.toks); p.i++ {
+		t := p.toks[p.i]
+		if t.kind != tokOp {
+			continue
+		}
+		if depth == 0 {
+			for _, op := range ops {
+				if t.text == op {
+					return
+				}
+			}
+		}
+		switch t.text {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			if depth == 0 {
+				return
+			}
+			depth--
+		}
+	}
+}
+
+// node parses node 'a', /b/ inherits c { ... }.
+func (p *parser) node() {
+	n := &Node{Line: p.peek(0).line}
+	p.i++
+	for p.i < len(p.toks) && !p.atOp(0, "{") {
+		if t := p.peek(0); t.kind == tokString || t.kind == tokName || t.kind == tokRegex {
+			if t.text != "inherits" {
+				n.Names = append(n.Names, t.text)
+			}
+		}
+		p.i++
+	}
+	p.m.Nodes = append(p.m.Nodes, n)
+	if p.atOp(0, "{") {
+		p.i++
+		p.statements("")
+	}
+}
+
+// resources parses type { title: attrs; title: attrs } starting at the type name.
+func (p *parser) resources(container string, virtual, exported bool) {
+	typeTok := p.peek(0)
+	p.i += 2
+	for p.i < len(p.toks) {
+		if p.atOp(0, "}") {
+			p.i++
+			return
+		}
+		start := p.i
+		p.skipToAny(":", "}")
+		if !p.atOp(0, ":") {
+			// not a titled body (e.g. a hash); nothing to declare
+			p.skipBlock()
+			return
+		}
+		title := p.value(p.toks[start:p.i])
+		p.i++
+		attrs := p.attributes()
+
+		titles := []Value{title}
+		if title.Kind == Array {
+			titles = title.Items
+		}
+		for _, t := range titles {
+			p.m.Resources = append(p.m.Resources, &Resource{
+				Type:      strings.ToLower(strings.TrimPrefix(typeTok.text, "::")),
+				Title:     t,
+				Params:    attrs,
+				Virtual:   virtual,
+				Exported:  exported,
+				Container: container,
+				Line:      typeTok.line,
+			})
+		}
+		if p.atOp(0, ";") {
+			p.i++
+		}
+	}
+}
+
+// attributes parses name => value pairs up to a ';' or '}' at depth zero, without consuming it.
+func (p *parser) attributes() []Attribute {
+	var attrs []Attribute
+	for p.i < len(p.toks) && !p.atOp(0, ";") && !p.atOp(0, "}") {
+		name := p.peek(0)
+		if (name.kind == tokName || name.kind == tokString || (name.kind == tokOp && name.text == "*")) &&
+			(p.atOp(1, "=>") || p.atOp(1, "+>")) {
+			p.i += 2
+			start := p.i
+			p.skipToAny(",", ";", "}")
+			attrs = append(attrs, Attribute{
+				Name:     name.text,
+				Value:    p.value(p.toks[start:p.i]),
+				Line:     name.line,
+				nameEnd:  name.end,
+				arrowPos: p.toks[start-1].pos,
+			})
+		} else {
+			p.skipToAny(",", ";", "}")
+		}
+		if p.atOp(0, ",") {
+			p.i++
+		}
+	}
+	return attrs
+}
+
+// value builds a Value from the tokens of one expression.
+func (p *parser) value(toks []token) Value {
+	if len(toks) == 0 {
+		return Value{Kind: Undef}
+	}
+	first, last := toks[0], toks[len(toks)-1]
+	v := Value{Line: first.line, Text: p.source(toks), Kind: Expression}
+	if len(toks) == 1 {
+		switch first.kind {
+		case tokString:
+			v.Kind, v.Text, v.Interpolated = String, first.text, first.interp
+		case tokNumber:
+			v.Kind = Number
+		case tokVar:
+			v.Kind, v.Text = Variable, first.text
+		case tokName:
+			switch first.text {
+			case "true", "false":
+				v.Kind = Boolean
+			case "undef":
+				v.Kind = Undef
+			default:
+				v.Kind = String // bare words are strings
+			}
+		}
+		return v
+	}
+	if first.kind == tokOp && last.kind == tokOp && closes(toks, 0) == len(toks)-1 {
+		switch first.text {
+		case "[":
+			v.Kind = Array
+			for _, item := range splitTopLevel(toks[1:len(toks)-1], ",") {
+				v.Items = append(v.Items, p.value(item))
+			}
+		case "{":
+			v.Kind = Hash
+			for _, entry := range splitTopLevel(toks[1:len(toks)-1], ",") {
+				for j, t := range entry {
+					if t.kind == tokOp && t.text == "=>" {
+						v.Keys = append(v.Keys, p.value(entry[:j]))
+						v.Items = append(v.Items, p.value(entry[j+1:]))
+						break
+					}
+				}
+			}
+		}
+	}
+	return v
+}
+
+// source returns the manifest text spanned by toks.
+func (p *parser) source(toks []token) string {
+	if len(toks) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(p.src[toks[0].pos:toks[len(toks)-1].end])
+}
+
+// closes returns the index of the bracket closing the one at open, or -1.
+func closes(toks []token, open int) int {
+	depth := 0
+	for i := open; i < len(toks); i++ {
+		if toks[i].kind != tokOp {
+			continue
+		}
+		switch toks[i].text {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitTopLevel splits toks on sep at bracket depth zero, dropping empty parts.
+func splitTopLevel(toks []token, sep string) [][]token {
+	var parts [][]token
+	depth, start := 0, 0
+	for i, t := range toks {
+		if t.kind != tokOp {
+			continue
+		}
+		switch t.text {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			depth--
+		case sep:
+			if depth == 0 {
+				if i > start {
+					parts = append(parts, toks[start:i])
+				}
+				start = i + 1
+			}
+		}
+	}
+	if start < len(toks) {
+		parts = append(parts, toks[start:])
+	}
+	return parts
+}
@@ -11,68 +11,93 @@ import (
 	"strings"
 
 	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/scanner"
 )
 
-// List of known deprecated Puppet resource types (example)
-var deprecatedResources = []string{
-	"execpipe",           // Deprecated: use 'exec' with better practices
-	"database",           // Deprecated in favor of dedicated DB modules or external management
-	"concat::fragment",   // Replaced by native concat resource in Puppet 4+
-	"filebucket",         // Deprecated in favor of external backup/version control
-	"nagios_service",     // Deprecated, replaced by newer monitoring modules
-	"package",            // Some providers (like gem) are deprecated, prefer specific package types
-	"resources",          // Deprecated meta-type, avoid using
-	"vcsrepo",            // Deprecated in some contexts, replaced by 'git' or other SCM modules
-	"apache::vhost",      // Deprecated in favor of official Apache modules or newer Puppet Forge modules
-	"mysql::db",          // Deprecated, use official MySQL module or external DB management
-	"ssh_authorized_key", // Some parameters deprecated; check current docs
+// Known deprecated Puppet resource types, matched against declared resource types
+var deprecatedResources = map[string]bool{
+	"execpipe":           true, // Deprecated: use 'exec' with better practices
+	"database":           true, // Deprecated in favor of dedicated DB modules or external management
+	"concat::fragment":   true, // Replaced by native concat resource in Puppet 4+
+	"filebucket":         true, // Deprecated in favor of external backup/version control
+	"nagios_service":     true, // Deprecated, replaced by newer monitoring modules
+	"resources":          true, // Deprecated meta-type, avoid using
+	"vcsrepo":            true, // Deprecated in some contexts, replaced by 'git' or other SCM modules
+	"apache::vhost":      true, // Deprecated in favor of official Apache modules or newer Puppet Forge modules
+	"mysql::db":          true, // Deprecated, use official MySQL module or external DB management
+	"ssh_authorized_key": true, // Some parameters deprecated; check current docs
 }
 
-// List of known unmanaged or disallowed parameters
-var disallowedParams = []string{
-	"force_destroy",       // Dangerous: might delete resources unexpectedly
-	"skip_final_snapshot", // Can lead to data loss if true
-	"public_ip",           // Assigning public IP may be disallowed in secure environments
-	"allow_remote_access", // Often disallowed due to security risks
-	"password",            // Hardcoded passwords should be disallowed
-	"secret_key",          // Sensitive keys should never be hardcoded
-	"access_key",          // AWS access keys hardcoded in resources
-	"enable_http_access",  // Disallowed if enabling insecure protocols
-	"insecure_ssl",        // Disallowed to prevent insecure SSL configurations
-	"admin_password",      // Hardcoded admin passwords are disallowed
+// Known unmanaged or disallowed parameters, matched against resource parameter names
+var disallowedParams = map[string]bool{
+	"force_destroy":       true, // Dangerous: might delete resources unexpectedly
+	"skip_final_snapshot": true, // Can lead to data loss if true
+	"public_ip":           true, // Assigning public IP may be disallowed in secure environments
+	"allow_remote_access": true, // Often disallowed due to security risks
+	"password":            true, // Hardcoded passwords should be disallowed
+	"secret_key":          true, // Sensitive keys should never be hardcoded
+	"access_key":          true, // AWS access keys hardcoded in resources
+	"enable_http_access":  true, // Disallowed if enabling insecure protocols
+	"insecure_ssl":        true, // Disallowed to prevent insecure SSL configurations
+	"admin_password":      true, // Hardcoded admin passwords are disallowed
 }
 
-// Regex for detecting class declarations
-var classDeclRegex = regexp.MustCompile(`(?m)^\s*class\s+[\w:]+`)
-
-// Regex for common hardcoded secrets (password-like)
-var hardcodedSecretRegex = regexp.MustCompile(`(?i)password\s*=>\s*["'].*["']`)
+// Parameter names that hold passwords
+var passwordParamRegex = regexp.MustCompile(`(?i)passw(or)?d`)
 
 // Check for trailing whitespace (space or tab)
 var trailingWhitespaceRegex = regexp.MustCompile(`\s+$`)
 
 // Scan scans Puppet manifests and returns findings.
 func Scan(path string) ([]finding.Finding, error) {
+	return ScanWithOptions(path, Options{})
+}
+
+// Options tune the Puppet scan.
+type Options struct {
+	// PuppetLint also runs the external puppet-lint binary. The native style checks
+	// run either way.
+	PuppetLint bool
+}
+
+// ScanWithOptions is Scan with tunable behaviour.
+func ScanWithOptions(path string, opts Options) ([]finding.Finding, error) {
 	var findings []finding.Finding
+	if opts.PuppetLint {
+		if _, err := exec.LookPath("puppet-lint"); err != nil {
+			return nil, fmt.Errorf("puppet-lint not found in PATH: %v", err)
+		}
+	}
+	hiera := loadHiera(path)
+	modules := findModules(path)
+	var templates []string
+	rendered := make(map[string][]templateUse)
+	topVars := make(map[string]bool)
 
-	err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+	err := scanner.Walk(path, func(p string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return err
 		}
+		if isTemplate(p) {
+			templates = append(templates, p)
+			return nil
+		}
 		if filepath.Ext(p) != ".pp" {
 			return nil
 		}
 
 		// 1. Run puppet-lint
-		puppetLintFindings, err := runPuppetLint(p)
-		if err != nil {
-			findings = append(findings, finding.Finding{
-				File:     p,
-				Severity: finding.Error,
-				Message:  fmt.Sprintf("puppet-lint error: %v", err),
-			})
+		if opts.PuppetLint {
+			puppetLintFindings, err := runPuppetLint(p)
+			if err != nil {
+				findings = append(findings, finding.Finding{
+					File:     p,
+					Severity: finding.Error,
+					Message:  fmt.Sprintf("puppet-lint error: %v", err),
+				})
+			}
+			findings = append(findings, puppetLintFindings...)
 		}
-		findings = append(findings, puppetLintFindings...)
 
 		// 2. Read file content for static checks
 		contentBytes, err := os.ReadFile(p)
@@ -86,19 +111,31 @@ func Scan(path string) ([]finding.Finding, error) {
 		}
 		content := string(contentBytes)
 
+		manifest, err := Parse(contentBytes)
+		if err != nil {
+			f := finding.Finding{File: p, Severity: finding.Error, Message: fmt.Sprintf("Puppet syntax error: %v", err)}
+			if se, ok := err.(*SyntaxError); ok {
+				f.Message, f.Line = fmt.Sprintf("Puppet syntax error: %s", se.Msg), se.Line
+			}
+			findings = append(findings, f)
+		}
+
+		findings = append(findings, lintManifest(p, content, manifest)...)
+
 		// 3. Deprecated resource checks
-		for _, dr := range deprecatedResources {
-			if strings.Contains(content, dr) {
+		for _, r := range manifest.Resources {
+			if deprecatedResources[r.Type] {
 				findings = append(findings, finding.Finding{
 					File:     p,
 					Severity: finding.Warning,
-					Message:  fmt.Sprintf("Deprecated resource type '%s' used", dr),
+					Message:  fmt.Sprintf("Deprecated resource type '%s' used", r.Type),
+					Line:     r.Line,
 				})
 			}
 		}
 
-		// 4. Missing class declaration
-		if !classDeclRegex.MatchString(content) {
+		// 4. Missing class declaration; defined types and node definitions are fine too
+		if len(manifest.Classes) == 0 && len(manifest.Defines) == 0 && len(manifest.Nodes) == 0 {
 			findings = append(findings, finding.Finding{
 				File:     p,
 				Severity: finding.Warning,
@@ -106,16 +143,43 @@ func Scan(path string) ([]finding.Finding, error) {
 			})
 		}
 
-		// 5. Hardcoded secrets detection
-		if loc := hardcodedSecretRegex.FindStringIndex(content); loc != nil {
-			findings = append(findings, finding.Finding{
-				File:     p,
-				Severity: finding.Error,
-				Message:  "Possible hardcoded password detected",
-			})
+		// 5. Hardcoded secrets detection: literal passwords in resource parameters and
+		// class or defined type parameter defaults
+		for _, r := range manifest.Resources {
+			for _, a := range r.Params {
+				if passwordParamRegex.MatchString(a.Name) && a.Value.IsLiteral() && a.Value.Text != "" {
+					findings = append(findings, finding.Finding{
+						File:     p,
+						Severity: finding.Error,
+						Message:  fmt.Sprintf("Possible hardcoded password detected in %s parameter '%s'", r.Ref(), a.Name),
+						Line:     a.Line,
+					})
+				}
+			}
+		}
+		for _, c := range append(manifest.Classes, manifest.Defines...) {
+			for _, param := range c.Params {
+				if passwordParamRegex.MatchString(param.Name) && param.Default != nil && param.Default.IsLiteral() && param.Default.Text != "" {
+					findings = append(findings, finding.Finding{
+						File:     p,
+						Severity: finding.Error,
+						Message:  fmt.Sprintf("Possible hardcoded password detected in the default of '%s' parameter '$%s'", c.Name, param.Name),
+						Line:     param.Line,
+					})
+				}
+			}
+		}
+
+		// 6. Hiera lookups without backing data, and the templates the manifest renders
+		findings = append(findings, hiera.checkLookups(p, manifest)...)
+		for t, uses := range templateUses(p, manifest, modules) {
+			rendered[t] = append(rendered[t], uses...)
+		}
+		for _, v := range manifest.Vars {
+			topVars[v] = true
 		}
 
-		// 6. Trailing whitespace
+		// 7. Trailing whitespace
 		lines := strings.Split(content, "\n")
 		for i, line := range lines {
 			if trailingWhitespaceRegex.MatchString(line) {
@@ -123,27 +187,52 @@ func Scan(path string) ([]finding.Finding, error) {
 					File:     p,
 					Severity: finding.Warning,
 					Message:  fmt.Sprintf("Trailing whitespace on line %d", i+1),
+					Line:     i + 1,
 				})
 			}
 		}
 
-		// 7. Disallowed parameters
-		for _, param := range disallowedParams {
-			if strings.Contains(content, param) {
-				findings = append(findings, finding.Finding{
-					File:     p,
-					Severity: finding.Warning,
-					Message:  fmt.Sprintf("Disallowed parameter '%s' used", param),
-				})
+		// 8. Disallowed parameters
+		for _, r := range manifest.Resources {
+			for _, a := range r.Params {
+				if disallowedParams[a.Name] {
+					findings = append(findings, finding.Finding{
+						File:     p,
+						Severity: finding.Warning,
+						Message:  fmt.Sprintf("Disallowed parameter '%s' used in %s", a.Name, r.Ref()),
+						Line:     a.Line,
+					})
+				}
 			}
 		}
 
+		// 9. World-writable file modes
+		findings = append(findings, checkFileModes(p, manifest)...)
+
 		return nil
 	})
 
+	// 10. ERB and EPP templates, with the scopes rendering them
+	for _, t := range templates {
+		findings = append(findings, checkTemplate(t, rendered[filepath.Clean(t)], topVars)...)
+	}
+
+	// 11. Hiera configuration and data files
+	findings = append(findings, hiera.findings...)
 	return findings, err
 }
 
+// isTemplate reports whether p is an ERB template in a templates directory or an EPP template.
+func isTemplate(p string) bool {
+	switch filepath.Ext(p) {
+	case ".epp":
+		return true
+	case ".erb":
+		return strings.Contains(filepath.ToSlash(p), "/templates/") || strings.HasPrefix(filepath.ToSlash(p), "templates/")
+	}
+	return false
+}
+
 // runPuppetLint runs puppet-lint and parses the output
 func runPuppetLint(filePath string) ([]finding.Finding, error) {
 	var findings []finding.Finding
@@ -0,0 +1,29 @@
+package puppet
+
+import (
+	"path/filepath"
+
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+func init() {
+	manifests := scanner.Extensions(".pp")
+	scanner.Register(puppetScanner{scanner.Simple{
+		ID:      "puppet",
+		Summary: "Scan Puppet manifests, templates and Hiera data in the specified directories or files",
+		Matcher: func(p string) bool {
+			return manifests(p) || isTemplate(p) || filepath.Base(p) == "hiera.yaml" || isHieraData(p)
+		},
+		ScanFunc: Scan,
+	}})
+}
+
+// puppetScanner scans Puppet trees, whose manifests look up the Hiera data beside them.
+type puppetScanner struct{ scanner.Simple }
+
+// Linked reports whether root holds a Hiera hierarchy. Its data files are only read through
+// the hiera.yaml naming them, and manifests are checked against their keys, so the
+// directory of a changed data file or manifest cannot be rescanned on its own.
+func (puppetScanner) Linked(root string, changed []string) bool {
+	return loadHiera(root).configs > 0
+}
@@ -0,0 +1,169 @@
+package puppet
+
+import (
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/registry"
+)
+
+func init() {
+	scanners := []string{"puppet"}
+	registry.Register(
+		registry.Rule{
+			ID: "PUP001", Name: "Deprecated resource type", Severity: finding.Warning, Category: "deprecation", Scanners: scanners,
+			Description: "A manifest declares a resource type removed from or deprecated in current Puppet releases.",
+			Messages:    []string{"Deprecated resource type '*' used"},
+			Remediation: `# replace the removed type with its module-provided successor
+cron { 'logrotate':
+  command => '/usr/sbin/logrotate /etc/logrotate.conf',
+  hour    => 2,
+}`,
+		},
+		registry.Rule{
+			ID: "PUP002", Name: "Manifest without class", Severity: finding.Warning, Category: "hygiene", Scanners: scanners,
+			Description: "A manifest declares no class or defined type, so its resources live at top scope.",
+			Messages:    []string{"No class declaration found in manifest"},
+			Remediation: `class profile::web {
+  package { 'nginx':
+    ensure => installed,
+  }
+}`,
+		},
+		registry.Rule{
+			ID: "PUP003", Name: "Hardcoded password", Severity: finding.Error, Category: "secrets", Scanners: scanners,
+			Compliance:  registry.HardcodedSecrets,
+			Description: "A resource parameter or class parameter default with a password-like name is a literal.",
+			Messages:    []string{"Possible hardcoded password detected in * parameter '*'", "Possible hardcoded password detected in the default of '*' parameter '$*'"},
+			Remediation: `class profile::db (
+  Sensitive[String] $password = lookup('profile::db::password'),
+) {
+  # ...
+}`,
+		},
+		registry.Rule{
+			ID: "PUP004", Name: "Trailing whitespace", Severity: finding.Warning, Category: "style", Scanners: scanners,
+			Description: "A line ends in whitespace.",
+			Messages:    []string{"Trailing whitespace on line *"},
+			Remediation: "# strip trailing whitespace, e.g. with: sed -i 's/[[:space:]]*$//' manifest.pp",
+		},
+		registry.Rule{
+			ID: "PUP005", Name: "Disallowed parameter", Severity: finding.Warning, Category: "policy", Scanners: scanners,
+			Description: "A resource uses a parameter on the configured deny list.",
+			Messages:    []string{"Disallowed parameter '*' used in *"},
+			Remediation: `# set the value the deny list allows, or remove the parameter
+file { '/etc/app.conf':
+  ensure => file,
+}`,
+		},
+		registry.Rule{
+			ID: "PUP006", Name: "Unaligned arrows", Severity: finding.Warning, Category: "style", Scanners: scanners,
+			Description: "The => arrows of a resource's parameters are not aligned in one column.",
+			Messages:    []string{"Arrow of parameter '*' in * is not aligned *"},
+			Remediation: `file { '/etc/motd':
+  ensure  => file,
+  content => 'Managed by Puppet',
+}`,
+		},
+		registry.Rule{
+			ID: "PUP007", Name: "Quoted boolean", Severity: finding.Warning, Category: "style", Scanners: scanners,
+			Description: "A resource parameter, or the default of a class or defined type parameter, is set to the string 'true' or 'false', which is truthy either way.",
+			Messages:    []string{"Quoted boolean '*' in parameter '*' of *; use a bare *"},
+			Remediation: `service { 'nginx':
+  enable => true,
+}`,
+		},
+		registry.Rule{
+			ID: "PUP008", Name: "Line too long", Severity: finding.Warning, Category: "style", Scanners: scanners,
+			Description: "A line is longer than 140 characters.",
+			Messages:    []string{"Line * is longer than * characters"},
+			Remediation: `exec { 'reload':
+  command     => '/usr/bin/systemctl reload nginx',
+  refreshonly => true,
+}`,
+		},
+		registry.Rule{
+			ID: "PUP009", Name: "Undocumented class or defined type", Severity: finding.Warning, Category: "style", Scanners: scanners,
+			Description: "A class or defined type has no comment above it describing what it manages.",
+			Messages:    []string{"Class '*' is not documented; *", "Defined type '*' is not documented; *"},
+			Remediation: `# @summary Installs and configures nginx.
+class profile::web {
+}`,
+		},
+		registry.Rule{
+			ID: "PUP010", Name: "Hiera 3 configuration", Severity: finding.Warning, Category: "deprecation", Scanners: scanners,
+			Description: "hiera.yaml uses the version 3 format, which Puppet has deprecated in favour of version 5.",
+			Messages:    []string{"Hiera 3 configuration is deprecated; *"},
+			Remediation: `---
+version: 5
+defaults:
+  datadir: data
+  data_hash: yaml_data
+hierarchy:
+  - name: "Per-node data"
+    path: "nodes/%{trusted.certname}.yaml"
+  - name: "Common data"
+    path: "common.yaml"`,
+		},
+		registry.Rule{
+			ID: "PUP011", Name: "Plaintext Hiera secret", Severity: finding.Error, Category: "secrets", Scanners: scanners,
+			Compliance:  registry.HardcodedSecrets,
+			Description: "A Hiera key with a secret-like name holds a plaintext value instead of an eyaml-encrypted one.",
+			Messages:    []string{"Hiera key '*' holds a plaintext secret*"},
+			Remediation: `profile::db::password: >
+  ENC[PKCS7,MIIBiQYJKoZIhvcNAQcDoIIBejCCAXYCAQAxggEhMIIBHQIBADAF...]`,
+		},
+		registry.Rule{
+			ID: "PUP012", Name: "Unresolvable Hiera lookup", Severity: finding.Warning, Category: "correctness", Scanners: scanners,
+			Description: "A lookup or automatic parameter binding has no default and no hierarchy level defines the key, so catalog compilation fails.",
+			Messages:    []string{"Hiera lookup of '*' has no default and no hierarchy data defines it; *"},
+			Remediation: `# data/common.yaml
+profile::web::port: 8080`,
+		},
+		registry.Rule{
+			ID: "PUP013", Name: "Hardcoded secret in template", Severity: finding.Error, Category: "secrets", Scanners: scanners,
+			Compliance:  registry.HardcodedSecrets,
+			Description: "An ERB or EPP template contains a literal secret instead of receiving it from Hiera.",
+			Messages:    []string{"Possible hardcoded secret '*' in template; *"},
+			Remediation: `<%# epp: receive the secret as a parameter %>
+<%- | Sensitive[String] $password | -%>
+password=<%= $password.unwrap %>`,
+		},
+		registry.Rule{
+			ID: "PUP014", Name: "Fact interpolated into a shell command", Severity: finding.Error, Category: "injection", Scanners: scanners,
+			Compliance:  registry.Injection,
+			Description: "A template puts a node-supplied fact into a shell script without escaping, or runs a command on the server with it.",
+			Messages: []string{
+				"Template interpolates the fact * into a shell script without escaping; *",
+				"Template runs a shell command on the Puppet server with the fact * in it; *",
+			},
+			Remediation: `exec { 'register':
+  command => "/usr/local/bin/register ${shell_escape($facts['networking']['hostname'])}",
+}`,
+		},
+		registry.Rule{
+			ID: "PUP015", Name: "Undefined template variable", Severity: finding.Warning, Category: "correctness", Scanners: scanners,
+			Description: "A template reads a variable that is neither a template parameter nor defined in the class rendering it.",
+			Messages: []string{
+				"Variable '*' used in template is not a declared template parameter *",
+				"Variable '*' used in template is not defined in '*', which renders it *",
+			},
+			Remediation: `<%- | String $server_name | -%>
+server_name <%= $server_name %>;`,
+		},
+		registry.Rule{
+			ID: "PUP016", Name: "Missing EPP parameter", Severity: finding.Warning, Category: "correctness", Scanners: scanners,
+			Description: "An epp() call does not pass a template parameter that has no default.",
+			Messages:    []string{"epp() call of '*' does not pass the template parameter '$*', which has no default"},
+			Remediation: "content => epp('profile/nginx.conf.epp', { 'server_name' => $facts['networking']['fqdn'] }),",
+		},
+		registry.Rule{
+			ID: "PUP017", Name: "World-writable file", Severity: finding.Warning, Category: "permissions", Scanners: scanners,
+			Compliance:  registry.FilePermissions,
+			Description: "A file resource sets a mode, such as '0777', '0666' or 'o+w', that lets every local user modify the file or directory.",
+			Messages:    []string{"* makes '*' world-writable (mode '*'); any local user can modify it"},
+			Remediation: `file { '/srv/app':
+  ensure => directory,
+  mode   => '0755',
+}`,
+		},
+	)
+}
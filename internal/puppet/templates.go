@@ -0,0 +1,416 @@
+package puppet
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// ERB and EPP templates.
+//
+// Templates are the .erb files in a module's templates/ directory and .epp files anywhere.
+// template('mod/file.erb') and epp('mod/file.epp') calls resolve to the module's templates
+// directory, so each template's variables are checked against the classes rendering it:
+// ERB reads the class's variables as @name, and an EPP template sees its declared
+// parameters or, when it declares none, the calling class's variables. Unrendered
+// templates only get the checks that need no scope.
+
+// Facts and node variables every template can read, in addition to legacy and core facts.
+var templateScopeVars = map[string]bool{
+	"facts": true, "trusted": true, "server_facts": true, "settings": true, "environment": true,
+	"title": true, "name": true, "module_name": true, "caller_module_name": true,
+	"clientcert": true, "clientversion": true, "servername": true, "serverip": true, "serverversion": true,
+}
+
+// Top-scope fact variables: legacy flat facts and the structured core facts.
+var topScopeFacts = map[string]bool{
+	"architecture": true, "domain": true, "fqdn": true, "hostname": true, "ipaddress": true,
+	"ipaddress6": true, "macaddress": true, "netmask": true, "network": true, "operatingsystem": true,
+	"operatingsystemrelease": true, "operatingsystemmajrelease": true, "osfamily": true,
+	"kernel": true, "kernelrelease": true, "kernelversion": true, "kernelmajversion": true,
+	"memorysize": true, "processorcount": true, "uptime": true, "timezone": true, "virtual": true,
+	"is_virtual": true, "lsbdistcodename": true, "lsbdistid": true, "lsbdistrelease": true,
+	"uuid": true, "serialnumber": true, "manufacturer": true, "productname": true,
+	"puppetversion": true, "facterversion": true, "rubyversion": true, "path": true,
+	"id": true, "gid": true, "interfaces": true, "os": true, "networking": true,
+	"processors": true, "memory": true, "disks": true, "identity": true, "system_uptime": true,
+	"dmi": true, "mountpoints": true, "partitions": true, "ruby": true, "load_averages": true,
+}
+
+// Lines assigning a literal to a secret-like setting: key = value, key: value.
+var templateSecretRegex = regexp.MustCompile(`(?i)^\s*(?:export\s+)?([A-Za-z0-9_.-]*(?:password|passwd|secret|token|api_key|apikey|private_key)[A-Za-z0-9_.-]*)\s*[:=]\s*(.*)$`)
+
+var (
+	// @name in ERB code, not @@class variables or user@host
+	erbVarRegex = regexp.MustCompile(`(?:^|[^\w@])@([a-z_]\w*)`)
+	// @name = value in ERB code
+	erbAssignRegex = regexp.MustCompile(`(?:^|[^\w@])@([a-z_]\w*)\s*=[^=~]`)
+	// scope['name'], scope["name"] and scope.lookupvar('name')
+	erbScopeRegex = regexp.MustCompile(`scope(?:\[|\.lookupvar\(|\.call_function\('lookupvar',\s*\[?)\s*['"]([\w:]+)['"]`)
+	// $name, ${name} and $::name in EPP code and strings
+	eppVarRegex = regexp.MustCompile(`\$\{?((?:::)?[a-z_]\w*(?:::[a-z_]\w*)*)`)
+	// $name = value in EPP code
+	eppAssignRegex = regexp.MustCompile(`\$([a-z_]\w*)\s*=[^=~>]`)
+	// lambda parameters: |$x, $y|
+	eppLambdaRegex = regexp.MustCompile(`\|([^|]*\$[^|]*)\|`)
+
+	// facts['name'] however it is reached, or a top-scope variable
+	factIndexRegex = regexp.MustCompile(`(?:@|\$\{?)facts\[\s*['"]([\w.]+)['"]|scope\[\s*['"]facts['"]\s*\]\[\s*['"]([\w.]+)['"]`)
+	topScopeRegex  = regexp.MustCompile(`(?:@|\$\{?(?:::)?|scope\[\s*['"]::|lookupvar\(\s*['"]::)([a-z_]\w*)`)
+
+	// Ruby that runs a command: `cmd`, %x{cmd}, system, exec, spawn, IO.popen, Open3
+	rubyShellRegex = regexp.MustCompile("`|%x[{(\\[]|\\b(?:system|exec|spawn)\\s*[( '\"]|IO\\.popen|Open3\\.")
+	// ERB and EPP shell escaping
+	shellEscapeRegex = regexp.MustCompile(`shellescape|Shellwords|shell_escape|shellquote`)
+)
+
+// templateTag is one <% %> tag of a template.
+type templateTag struct {
+	kind byte // '=' for expressions, '#' for comments, '|' for an EPP parameter tag, ' ' for code
+	body string
+	line int
+}
+
+// templateUse is a template() or epp() call rendering a template.
+type templateUse struct {
+	file  string // the manifest
+	call  *Call
+	class *Class // the rendering class or defined type, nil at top level
+}
+
+// splitTags returns the tags of a template and its text with every tag blanked out to
+// "\x00" plus the newlines it spans, so text lines keep their numbers.
+func splitTags(src string) ([]templateTag, string, error) {
+	var tags []templateTag
+	var text strings.Builder
+	line := 1
+	for {
+		start := strings.Index(src, "<%")
+		if start < 0 {
+			text.WriteString(src)
+			return tags, text.String(), nil
+		}
+		text.WriteString(src[:start])
+		line += strings.Count(src[:start], "\n")
+		if strings.HasPrefix(src[start:], "<%%") {
+			text.WriteString("<%")
+			src = src[start+3:]
+			continue
+		}
+		end := strings.Index(src[start:], "%>")
+		if end < 0 {
+			return tags, text.String(), &SyntaxError{Line: line, Msg: "unterminated template tag"}
+		}
+		raw := src[start+2 : start+end]
+		tag := templateTag{kind: ' ', line: line}
+		raw = strings.TrimPrefix(raw, "-")
+		switch {
+		case strings.HasPrefix(raw, "="):
+			tag.kind, raw = '=', raw[1:]
+		case strings.HasPrefix(raw, "#"):
+			tag.kind = '#'
+		case strings.HasPrefix(strings.TrimSpace(raw), "|"):
+			tag.kind = '|'
+		}
+		tag.body = strings.TrimSuffix(raw, "-")
+		tags = append(tags, tag)
+
+		n := strings.Count(src[start:start+end+2], "\n")
+		text.WriteString("\x00" + strings.Repeat("\n", n))
+		line += n
+		src = src[start+end+2:]
+	}
+}
+
+// findModules maps module names to their directories: every directory holding templates/.
+func findModules(root string) map[string]string {
+	modules := make(map[string]string)
+	scanner.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err == nil && info.IsDir() && info.Name() == "templates" {
+			dir := filepath.Dir(p)
+			if _, ok := modules[filepath.Base(dir)]; !ok {
+				modules[filepath.Base(dir)] = dir
+			}
+		}
+		return nil
+	})
+	return modules
+}
+
+// templateUses returns the templates a manifest renders with template() or epp(), keyed by path.
+func templateUses(p string, m *Manifest, modules map[string]string) map[string][]templateUse {
+	uses := make(map[string][]templateUse)
+	for _, c := range m.Calls {
+		if c.Name != "template" && c.Name != "epp" {
+			continue
+		}
+		args := c.Args
+		if c.Name == "epp" && len(args) > 1 {
+			args = args[:1] // the rest are the template parameters
+		}
+		for _, arg := range args {
+			if arg.Kind != String || arg.Interpolated {
+				continue
+			}
+			path := arg.Text
+			if !filepath.IsAbs(path) {
+				module, rest, ok := strings.Cut(path, "/")
+				dir, found := modules[module]
+				if !ok || !found {
+					continue
+				}
+				path = filepath.Join(dir, "templates", rest)
+			}
+			use := templateUse{file: p, call: c}
+			for _, class := range append(m.Classes, m.Defines...) {
+				if class.Name == c.Container {
+					use.class = class
+				}
+			}
+			uses[filepath.Clean(path)] = append(uses[filepath.Clean(path)], use)
+		}
+	}
+	return uses
+}
+
+// checkTemplate checks an ERB or EPP template: syntax, hardcoded secrets, facts reaching a
+// shell and the variables it reads from the classes rendering it. topVars are the
+// variables assigned at top or node scope in the scanned manifests.
+func checkTemplate(p string, uses []templateUse, topVars map[string]bool) []finding.Finding {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return []finding.Finding{{File: p, Severity: finding.Error, Message: fmt.Sprintf("Failed to read file: %v", err)}}
+	}
+	epp := filepath.Ext(p) == ".epp"
+
+	var findings []finding.Finding
+	reported := make(map[string]bool)
+	report := func(file string, line int, sev finding.Severity, msg string) {
+		if key := fmt.Sprintf("%s:%d:%s", file, line, msg); !reported[key] {
+			reported[key] = true
+			findings = append(findings, finding.Finding{File: file, Severity: sev, Message: msg, Line: line})
+		}
+	}
+
+	tags, text, err := splitTags(string(data))
+	if err != nil {
+		se := err.(*SyntaxError)
+		report(p, se.Line, finding.Error, fmt.Sprintf("Template syntax error: %s", se.Msg))
+	}
+
+	// Hardcoded secrets in the literal text
+	for i, l := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(l)
+		if strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";") || strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+		m := templateSecretRegex.FindStringSubmatch(l)
+		if m == nil {
+			continue
+		}
+		value := strings.Trim(strings.TrimSpace(m[2]), `"';,`)
+		if value == "" || strings.Contains(value, "\x00") {
+			continue
+		}
+		report(p, i+1, finding.Error, fmt.Sprintf("Possible hardcoded secret '%s' in template; pass it in from Hiera (eyaml) instead", m[1]))
+	}
+
+	// Facts are supplied by the node, so they must not reach a shell unescaped
+	base := strings.TrimSuffix(filepath.Base(p), filepath.Ext(p))
+	shellScript := strings.HasPrefix(text, "#!") && strings.Contains(strings.SplitN(text, "\n", 2)[0], "sh")
+	switch filepath.Ext(base) {
+	case ".sh", ".bash", ".ksh", ".zsh":
+		shellScript = true
+	}
+	escape := "shellescape"
+	if epp {
+		escape = "shell_escape()"
+	}
+
+	// Parameters and local variables of the template itself
+	var params []Param
+	hasParams := false
+	locals := make(map[string]bool)
+	for _, tag := range tags {
+		switch {
+		case tag.kind == '|':
+			params, hasParams = eppParams(tag.body), true
+		case tag.kind == '#':
+		case epp:
+			for _, m := range eppAssignRegex.FindAllStringSubmatch(tag.body, -1) {
+				locals[m[1]] = true
+			}
+			for _, m := range eppLambdaRegex.FindAllStringSubmatch(tag.body, -1) {
+				for _, v := range eppVarRegex.FindAllStringSubmatch(m[1], -1) {
+					locals[v[1]] = true
+				}
+			}
+		default:
+			for _, m := range erbAssignRegex.FindAllStringSubmatch(tag.body, -1) {
+				locals[m[1]] = true
+			}
+		}
+	}
+
+	labelOf := func(name string) string {
+		if epp {
+			return "$" + name
+		}
+		return "@" + name
+	}
+	for _, tag := range tags {
+		if tag.kind == '#' || tag.kind == '|' {
+			continue
+		}
+		if fact := factRef(tag.body, epp); fact != "" {
+			if tag.kind == '=' && shellScript && !shellEscapeRegex.MatchString(tag.body) {
+				report(p, tag.line, finding.Warning, fmt.Sprintf("Template interpolates the fact %s into a shell script without escaping; a node can inject commands through its facts, so wrap it in %s", fact, escape))
+			}
+			if !epp && rubyShellRegex.MatchString(tag.body) {
+				report(p, tag.line, finding.Error, fmt.Sprintf("Template runs a shell command on the Puppet server with the fact %s in it; a node can run commands on the server through its facts", fact))
+			}
+		}
+
+		for _, name := range templateVars(tag.body, epp) {
+			if locals[name] || templateScopeVars[name] || topScopeFacts[name] || topVars[name] {
+				continue
+			}
+			if hasParams {
+				if !eppDeclares(params, name) {
+					report(p, tag.line, finding.Warning, fmt.Sprintf("Variable '%s' used in template is not a declared template parameter (an EPP template with parameters cannot read the calling class's variables)", labelOf(name)))
+				}
+				continue
+			}
+			for _, use := range uses {
+				if use.class == nil || use.class.Inherits != "" || use.class.Defines(name) {
+					continue
+				}
+				if epp {
+					report(p, tag.line, finding.Warning, fmt.Sprintf("Variable '%s' used in template is not defined in '%s', which renders it (evaluation fails)", labelOf(name), use.class.Name))
+				} else {
+					report(p, tag.line, finding.Warning, fmt.Sprintf("Variable '%s' used in template is not defined in '%s', which renders it (the template reads nil)", labelOf(name), use.class.Name))
+				}
+			}
+		}
+	}
+
+	// epp() calls must pass the parameters without defaults
+	if hasParams {
+		for _, use := range uses {
+			if use.call.Name != "epp" {
+				continue
+			}
+			var passed *Value
+			if len(use.call.Args) > 1 {
+				passed = &use.call.Args[1]
+				if passed.Kind != Hash {
+					continue // parameters from a variable or function
+				}
+			}
+			for _, param := range params {
+				if param.Default != nil || strings.HasPrefix(param.Type, "Optional[") {
+					continue
+				}
+				given := false
+				if passed != nil {
+					for _, k := range passed.Keys {
+						given = given || k.Text == param.Name
+					}
+				}
+				if !given {
+					report(use.file, use.call.Line, finding.Warning, fmt.Sprintf("epp() call of '%s' does not pass the template parameter '$%s', which has no default", use.call.Args[0].Text, param.Name))
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].File != findings[j].File {
+			return findings[i].File == p
+		}
+		return findings[i].Line < findings[j].Line
+	})
+	return findings
+}
+
+// eppParams parses the parameter tag <%- | String $a, $b = 1 | -%>.
+func eppParams(body string) []Param {
+	body = strings.TrimSpace(body)
+	body = strings.TrimSuffix(strings.TrimPrefix(body, "|"), "|")
+	toks, _ := lex(body)
+	p := &parser{src: body, toks: toks, m: &Manifest{}}
+	var params []Param
+	for _, part := range splitTopLevel(toks, ",") {
+		for j, t := range part {
+			if t.kind != tokVar {
+				continue
+			}
+			param := Param{Name: t.text, Line: t.line, Type: p.source(part[:j])}
+			if j+1 < len(part) && part[j+1].kind == tokOp && part[j+1].text == "=" {
+				v := p.value(part[j+2:])
+				param.Default = &v
+			}
+			params = append(params, param)
+			break
+		}
+	}
+	return params
+}
+
+func eppDeclares(params []Param, name string) bool {
+	for _, param := range params {
+		if param.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// templateVars returns the unqualified variables template code reads from its scope.
+func templateVars(code string, epp bool) []string {
+	var vars []string
+	if epp {
+		for _, m := range eppVarRegex.FindAllStringSubmatch(code, -1) {
+			if !strings.Contains(m[1], "::") {
+				vars = append(vars, m[1])
+			}
+		}
+		return vars
+	}
+	for _, m := range erbVarRegex.FindAllStringSubmatch(code, -1) {
+		vars = append(vars, m[1])
+	}
+	for _, m := range erbScopeRegex.FindAllStringSubmatch(code, -1) {
+		if !strings.Contains(m[1], "::") {
+			vars = append(vars, m[1])
+		}
+	}
+	return vars
+}
+
+// factRef returns the first fact template code reads, as written, or "".
+func factRef(code string, epp bool) string {
+	if m := factIndexRegex.FindStringSubmatch(code); m != nil {
+		return fmt.Sprintf("facts['%s']", m[1]+m[2])
+	}
+	for _, m := range topScopeRegex.FindAllStringSubmatchIndex(code, -1) {
+		name := code[m[2]:m[3]]
+		// unqualified EPP variables may be template parameters; only $::name is top scope
+		if epp && !strings.Contains(code[m[0]:m[2]], "::") {
+			continue
+		}
+		if topScopeFacts[name] && epp {
+			return "$::" + name
+		}
+		if topScopeFacts[name] {
+			return "@" + name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,32 @@
+package registry
+
+import "github.com/salchaD-27/infra-check/internal/finding"
+
+// The rules shared by every scanner rather than declared by one of them
+func init() {
+	Register(Rule{
+		ID:          "IC001",
+		Name:        "Unreadable or invalid file",
+		Description: "A file a scanner selected could not be read, parsed or rendered, so none of its checks ran on it. Fix the syntax error or exclude the file.",
+		Severity:    finding.Error,
+		Category:    "parse",
+		Messages: []string{
+			"Failed to read file: *",
+			"failed to read file: *",
+			"Failed to parse *",
+			"Failed to render template: *",
+			"Failed to build kustomization: *",
+			"Failed to read the template of stack *",
+			"YAML parse error: *",
+			"HCL parse error: *",
+			"Template parse error: *",
+			"Hiera config parse error: *",
+			"Hiera data parse error: *",
+			"Puppet syntax error: *",
+			"Template syntax error: *",
+			"puppet-lint error: *",
+		},
+		Remediation: `# fix the error the finding reports, or exclude the file in .infra-check.yaml
+exclude: ["**/examples/**"]`,
+	})
+}
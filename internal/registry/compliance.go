@@ -0,0 +1,112 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Framework is a compliance framework rules map their checks to controls of.
+type Framework struct {
+	// Key names the framework in Rule.Compliance, custom rule files and --compliance.
+	Key string
+
+	// Prefix is prepended to control IDs in findings, so a reference reads "PCI-DSS 3.5.1";
+	// Title names the framework and the revision the control IDs are those of.
+	Prefix string
+	Title  string
+}
+
+// Frameworks are the frameworks rules may carry mappings for.
+var Frameworks = []Framework{
+	{Key: "soc2", Prefix: "SOC 2", Title: "SOC 2 Trust Services Criteria (2017)"},
+	{Key: "pci", Prefix: "PCI-DSS", Title: "PCI DSS v4.0"},
+	{Key: "hipaa", Prefix: "HIPAA", Title: "HIPAA Security Rule, 45 CFR 164 Subpart C"},
+	{Key: "nist", Prefix: "NIST 800-53", Title: "NIST SP 800-53 Rev. 5"},
+}
+
+// LookupFramework returns the framework with the given key, case-insensitively.
+func LookupFramework(key string) (Framework, error) {
+	var keys []string
+	for _, fw := range Frameworks {
+		if strings.EqualFold(fw.Key, key) {
+			return fw, nil
+		}
+		keys = append(keys, fw.Key)
+	}
+	return Framework{}, fmt.Errorf("unknown compliance framework '%s' (use %s)", key, strings.Join(keys, ", "))
+}
+
+// Compliance maps framework keys to the IDs of the framework's controls a rule checks.
+type Compliance map[string][]string
+
+// Validate reports framework keys not in Frameworks, and frameworks mapped to no control.
+func (c Compliance) Validate() error {
+	for key, controls := range c {
+		if _, err := LookupFramework(key); err != nil {
+			return err
+		}
+		if len(controls) == 0 {
+			return fmt.Errorf("compliance framework '%s' lists no controls", key)
+		}
+	}
+	return nil
+}
+
+// Controls returns the references of the controls c maps to, such as "PCI-DSS 3.5.1", in
+// the order of Frameworks.
+func (c Compliance) Controls() []string {
+	var controls []string
+	for _, fw := range Frameworks {
+		for _, id := range c[fw.Key] {
+			controls = append(controls, fw.Prefix+" "+id)
+		}
+	}
+	return controls
+}
+
+// The control mappings rules share, by what the rules check. Not every framework has a
+// control for everything: HIPAA, say, says nothing about how software is built.
+var (
+	// HardcodedSecrets is for credentials written into code and configuration.
+	HardcodedSecrets = Compliance{"soc2": {"CC6.1"}, "pci": {"8.6.2"}, "hipaa": {"164.312(a)(1)"}, "nist": {"IA-5(7)"}}
+
+	// ExposedSecrets is for credentials that are not hardcoded but end up in plain text:
+	// in logs, plan output or state.
+	ExposedSecrets = Compliance{"soc2": {"CC6.1"}, "pci": {"8.3.2"}, "hipaa": {"164.312(a)(1)"}, "nist": {"IA-5"}}
+
+	// EncryptionAtRest is for stored data left unencrypted.
+	EncryptionAtRest = Compliance{"soc2": {"CC6.1"}, "pci": {"3.5.1"}, "hipaa": {"164.312(a)(2)(iv)"}, "nist": {"SC-28"}}
+
+	// EncryptionInTransit is for connections without TLS, with old TLS or unverified certificates.
+	EncryptionInTransit = Compliance{"soc2": {"CC6.7"}, "pci": {"4.2.1"}, "hipaa": {"164.312(e)(1)"}, "nist": {"SC-8"}}
+
+	// WeakCryptography is for keys too short to resist attacks.
+	WeakCryptography = Compliance{"soc2": {"CC6.1"}, "pci": {"3.6.1"}, "hipaa": {"164.312(a)(2)(iv)"}, "nist": {"SC-13"}}
+
+	// NetworkExposure is for services reachable from the internet.
+	NetworkExposure = Compliance{"soc2": {"CC6.6"}, "pci": {"1.3.1"}, "hipaa": {"164.312(a)(1)"}, "nist": {"SC-7"}}
+
+	// PublicStorage is for buckets and containers anyone may read.
+	PublicStorage = Compliance{"soc2": {"CC6.1"}, "pci": {"1.4.4"}, "hipaa": {"164.312(a)(1)"}, "nist": {"AC-3"}}
+
+	// LeastPrivilege is for identities granted more than they need.
+	LeastPrivilege = Compliance{"soc2": {"CC6.3"}, "pci": {"7.2.1"}, "hipaa": {"164.308(a)(4)"}, "nist": {"AC-6"}}
+
+	// PrivilegedWorkloads is for processes running as root or outside their isolation.
+	PrivilegedWorkloads = Compliance{"soc2": {"CC6.3"}, "pci": {"2.2.6"}, "nist": {"AC-6", "CM-7"}}
+
+	// FilePermissions is for files any local user may modify.
+	FilePermissions = Compliance{"soc2": {"CC6.1"}, "hipaa": {"164.312(c)(1)"}, "nist": {"AC-3"}}
+
+	// AuditLogging is for activity that is not logged.
+	AuditLogging = Compliance{"soc2": {"CC7.2"}, "pci": {"10.2.1"}, "hipaa": {"164.312(b)"}, "nist": {"AU-12"}}
+
+	// DataRecovery is for deleted or overwritten data that cannot be restored.
+	DataRecovery = Compliance{"soc2": {"A1.2"}, "hipaa": {"164.308(a)(7)(ii)(A)"}, "nist": {"CP-9"}}
+
+	// SupplyChain is for dependencies that are unpinned or fetched without verification.
+	SupplyChain = Compliance{"soc2": {"CC8.1"}, "pci": {"6.3.2"}, "nist": {"SI-7"}}
+
+	// Injection is for values that may be run as code or commands.
+	Injection = Compliance{"pci": {"6.2.4"}, "nist": {"SI-10"}}
+)
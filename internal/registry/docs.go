@@ -0,0 +1,39 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Markdown renders rules as the page at DocsURL: an index, then a section per rule headed by
+// its ID, so the anchor GitHub gives the heading is the one Register links to.
+func Markdown(rules []Rule) string {
+	var b strings.Builder
+	b.WriteString("# Rules\n\n")
+	b.WriteString("The built-in rules of infra-check. This page is generated from the rule registry by\n")
+	b.WriteString("`infra-check rules docs > docs/rules.md`; edit the rules, not the page.\n\n")
+	b.WriteString("| ID | Name | Severity | Category |\n|----|------|----------|----------|\n")
+	for _, r := range rules {
+		fmt.Fprintf(&b, "| [%s](#%s) | %s | %s | %s |\n", r.ID, strings.ToLower(r.ID), cell(r.Name), r.Severity, r.Category)
+	}
+	for _, r := range rules {
+		fmt.Fprintf(&b, "\n## %s\n\n**%s**\n\n%s\n\n", r.ID, r.Name, r.Description)
+		scanners := strings.Join(r.Scanners, ", ")
+		if scanners == "" {
+			scanners = "any"
+		}
+		fmt.Fprintf(&b, "- Severity: %s\n- Category: %s\n- Scanners: %s\n", r.Severity, r.Category, scanners)
+		if controls := r.Compliance.Controls(); len(controls) > 0 {
+			fmt.Fprintf(&b, "- Controls: %s\n", strings.Join(controls, ", "))
+		}
+		if r.Remediation != "" {
+			fmt.Fprintf(&b, "\nRemediation:\n\n```\n%s\n```\n", strings.TrimRight(r.Remediation, "\n"))
+		}
+	}
+	return b.String()
+}
+
+// cell escapes s for a Markdown table cell.
+func cell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
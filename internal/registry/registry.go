@@ -0,0 +1,207 @@
+// central catalogue of the built-in checks and their metadata
+package registry
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// DocsURL is the page documenting the built-in rules; a rule without a URL of its own links
+// to its section there.
+const DocsURL = "https://github.com/salchaD-27/infra-check/blob/main/docs/rules.md"
+
+// Rule describes one built-in check. Packages declare their rules from an init function,
+// next to the checks themselves.
+type Rule struct {
+	// ID is the stable identifier used in reports, suppression comments and the config file.
+	ID string
+
+	// Name is a short title, Description explains what the check looks for and why it matters.
+	Name        string
+	Description string
+
+	// Severity is the severity the check reports before overrides and escalation.
+	Severity finding.Severity
+
+	// Category groups related rules, e.g. encryption, network or secrets.
+	Category string
+
+	// Compliance maps the frameworks of Frameworks to the controls of theirs the rule checks.
+	Compliance Compliance
+
+	// URL documents the rule. Defaults to its section of DocsURL.
+	URL string
+
+	// Remediation is an example of the fix: usually a corrected snippet of configuration.
+	Remediation string
+
+	// Scanners are the tools whose findings the rule applies to, none for any.
+	Scanners []string
+
+	// Messages are patterns matching the messages of the rule's findings, where '*' matches
+	// any run of characters. Rules whose findings carry their ID as a "[ID]" prefix, like
+	// the credential patterns, need none.
+	Messages []string
+
+	patterns []pattern
+}
+
+// pattern is a compiled message pattern and the number of literal characters in it, which
+// ranks it against other matching patterns.
+type pattern struct {
+	re      *regexp.Regexp
+	literal int
+}
+
+var (
+	mu    sync.RWMutex
+	rules []*Rule
+	byID  = make(map[string]*Rule)
+)
+
+var idRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.-]*$`)
+
+// Register adds rules to the registry. Invalid rules and IDs registered twice panic, as
+// they are programming errors.
+func Register(list ...Rule) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, r := range list {
+		switch {
+		case !idRegex.MatchString(r.ID):
+			panic(fmt.Sprintf("rule %q has an invalid ID", r.ID))
+		case byID[r.ID] != nil:
+			panic(fmt.Sprintf("rule %q registered twice", r.ID))
+		case r.Name == "" || r.Description == "" || r.Category == "":
+			panic(fmt.Sprintf("rule %q needs a name, description and category", r.ID))
+		}
+		switch r.Severity {
+		case finding.Info, finding.Warning, finding.Error:
+		default:
+			panic(fmt.Sprintf("rule %q has invalid severity %q", r.ID, r.Severity))
+		}
+		if err := r.Compliance.Validate(); err != nil {
+			panic(fmt.Sprintf("rule %q: %v", r.ID, err))
+		}
+		if r.URL == "" {
+			r.URL = DocsURL + "#" + strings.ToLower(r.ID)
+		}
+		for _, m := range r.Messages {
+			r.patterns = append(r.patterns, pattern{compile(m), len(m) - strings.Count(m, "*")})
+		}
+		rules = append(rules, &r)
+		byID[r.ID] = &r
+	}
+}
+
+// compile turns a message pattern into an anchored regular expression.
+func compile(pattern string) *regexp.Regexp {
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile(`^(?s)` + strings.Join(parts, ".*") + `$`)
+}
+
+// All returns the registered rules sorted by ID.
+func All() []Rule {
+	mu.RLock()
+	defer mu.RUnlock()
+	out := make([]Rule, 0, len(rules))
+	for _, r := range rules {
+		out = append(out, *r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Lookup returns the rule registered under id.
+func Lookup(id string) (Rule, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if r, ok := byID[id]; ok {
+		return *r, true
+	}
+	return Rule{}, false
+}
+
+// For returns the rule that produced f: the one named by its "[ID]" prefix, or the one
+// whose message pattern matches most specifically. Rules of the finding's scanner are
+// preferred when it is known, since some scanners word their findings alike.
+func For(f finding.Finding) (Rule, bool) {
+	if id := f.RuleID(); id != "" {
+		return Lookup(id)
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	var best *Rule
+	bestScore := -1
+	for _, r := range rules {
+		score := r.match(f.Message)
+		if score < 0 {
+			continue
+		}
+		if f.Scanner != "" && len(r.Scanners) > 0 && contains(r.Scanners, f.Scanner) {
+			score += 1 << 20
+		}
+		if score > bestScore {
+			best, bestScore = r, score
+		}
+	}
+	if best == nil {
+		return Rule{}, false
+	}
+	return *best, true
+}
+
+// ID returns the ID of the rule that produced f: its custom rule or credential pattern ID,
+// or the ID of the built-in rule matching it; "" when no rule is known.
+func ID(f finding.Finding) string {
+	if id := f.RuleID(); id != "" {
+		return id
+	}
+	if r, ok := For(f); ok {
+		return r.ID
+	}
+	return ""
+}
+
+// Key identifies the check that produced f, to group findings by: its ID, or its message
+// when no rule is known.
+func Key(f finding.Finding) string {
+	if id := ID(f); id != "" {
+		return id
+	}
+	return f.Message
+}
+
+// match returns the literal length of the most specific pattern of r matching message, or
+// -1 when none does.
+func (r *Rule) match(message string) int {
+	best := -1
+	for _, p := range r.patterns {
+		if p.literal > best && p.re.MatchString(message) {
+			best = p.literal
+		}
+	}
+	return best
+}
+
+// IsID reports whether s is shaped like a rule ID rather than a message pattern.
+func IsID(s string) bool {
+	return idRegex.MatchString(s)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package report
+
+import (
+	"encoding/csv"
+	"strconv"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/registry"
+)
+
+// ExportCSV returns the findings as CSV with a header row, for triage in spreadsheets.
+// The line is empty when unknown, the rule ID is empty for checks the registry does not know,
+// and the controls are those of --profile and --compliance the finding fails.
+func ExportCSV(findings []finding.Finding) (string, error) {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	if err := w.Write([]string{"file", "line", "rule_id", "severity", "message", "controls"}); err != nil {
+		return "", err
+	}
+	for _, f := range findings {
+		line := ""
+		if f.Line > 0 {
+			line = strconv.Itoa(f.Line)
+		}
+		record := []string{f.File, line, registry.ID(f), string(f.Severity), f.Message, strings.Join(f.Controls, ", ")}
+		for i, cell := range record {
+			record[i] = escapeFormula(cell)
+		}
+		if err := w.Write(record); err != nil {
+			return "", err
+		}
+	}
+	w.Flush()
+	return b.String(), w.Error()
+}
+
+// escapeFormula stops spreadsheets from evaluating cells that start like a formula,
+// since file names and messages come from the scanned repository.
+func escapeFormula(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
@@ -0,0 +1,101 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/registry"
+)
+
+// JUnit XML as read by Jenkins, GitLab and most other CI systems. Each scanner becomes a
+// test suite and each rule/file pair a test case, which fails when any of its findings is
+// WARN or ERROR. Rules are identified as in registry.Key.
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// ExportJUnit returns the findings as a JUnit XML report.
+func ExportJUnit(findings []finding.Finding) (string, error) {
+	type key struct{ suite, rule, file string }
+	grouped := make(map[key][]finding.Finding)
+	var order []key
+	for _, f := range findings {
+		suite := f.Scanner
+		if suite == "" {
+			suite = "infra-check"
+		}
+		k := key{suite, registry.Key(f), f.File}
+		if _, seen := grouped[k]; !seen {
+			order = append(order, k)
+		}
+		grouped[k] = append(grouped[k], f)
+	}
+	sort.SliceStable(order, func(i, j int) bool { return order[i].suite < order[j].suite })
+
+	root := junitSuites{Name: "infra-check"}
+	for _, k := range order {
+		if len(root.Suites) == 0 || root.Suites[len(root.Suites)-1].Name != k.suite {
+			root.Suites = append(root.Suites, junitSuite{Name: k.suite})
+		}
+		suite := &root.Suites[len(root.Suites)-1]
+
+		tc := junitCase{Name: k.rule, ClassName: k.file, File: k.file}
+		var details []string
+		worst := finding.Info
+		for _, f := range grouped[k] {
+			details = append(details, fmt.Sprintf("[%s] %s: %s", f.Severity, f.Location(), withControls(f)))
+			if f.Severity == finding.Error || (f.Severity == finding.Warning && worst == finding.Info) {
+				worst = f.Severity
+			}
+		}
+		if worst == finding.Info {
+			tc.SystemOut = strings.Join(details, "\n")
+		} else {
+			tc.Failure = &junitFailure{
+				Message: withControls(grouped[k][0]),
+				Type:    string(worst),
+				Text:    strings.Join(details, "\n"),
+			}
+			suite.Failures++
+			root.Failures++
+		}
+		suite.Tests++
+		root.Tests++
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	out, err := xml.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(out), nil
+}
@@ -0,0 +1,37 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// ExportNDJSON returns findings as newline-delimited JSON: one finding object per line, as
+// in the findings array of the JSON report, without the envelope.
+func ExportNDJSON(findings []finding.Finding) (string, error) {
+	var b strings.Builder
+	s := NewNDJSONStream(&b)
+	for _, f := range findings {
+		if err := s.Write(f); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+// NDJSONStream writes findings as ExportNDJSON does, each one as soon as it is written.
+type NDJSONStream struct {
+	enc *json.Encoder
+}
+
+// NewNDJSONStream returns an NDJSONStream writing to w.
+func NewNDJSONStream(w io.Writer) *NDJSONStream {
+	return &NDJSONStream{enc: json.NewEncoder(w)}
+}
+
+// Write writes f on a line of its own.
+func (s *NDJSONStream) Write(f finding.Finding) error {
+	return s.enc.Encode(f)
+}
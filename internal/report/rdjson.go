@@ -0,0 +1,89 @@
+package report
+
+import (
+	"encoding/json"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/registry"
+)
+
+// Reviewdog Diagnostic Format (rdjson), for posting findings as inline PR comments with
+// `reviewdog -f=rdjson`. Only the fields reviewdog uses for comments are emitted.
+
+type rdResult struct {
+	Source      rdSource       `json:"source"`
+	Diagnostics []rdDiagnostic `json:"diagnostics"`
+}
+
+type rdSource struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+type rdDiagnostic struct {
+	Message  string     `json:"message"`
+	Location rdLocation `json:"location"`
+	Severity string     `json:"severity"`
+	Source   *rdSource  `json:"source,omitempty"`
+	Code     *rdCode    `json:"code,omitempty"`
+}
+
+type rdLocation struct {
+	Path  string   `json:"path"`
+	Range *rdRange `json:"range,omitempty"`
+}
+
+type rdRange struct {
+	Start rdPosition `json:"start"`
+}
+
+type rdPosition struct {
+	Line int `json:"line"`
+}
+
+type rdCode struct {
+	Value string `json:"value"`
+	URL   string `json:"url,omitempty"`
+}
+
+// ExportRDJSON returns the findings in reviewdog's rdjson format.
+func ExportRDJSON(findings []finding.Finding) (string, error) {
+	out := rdResult{
+		Source:      rdSource{Name: "infra-check", URL: "https://github.com/salchaD-27/infra-check"},
+		Diagnostics: []rdDiagnostic{},
+	}
+	for _, f := range findings {
+		d := rdDiagnostic{
+			Message:  withControls(f),
+			Location: rdLocation{Path: f.File},
+			Severity: rdSeverity(f.Severity),
+		}
+		if f.Line > 0 {
+			d.Location.Range = &rdRange{Start: rdPosition{Line: f.Line}}
+		}
+		if f.Scanner != "" {
+			d.Source = &rdSource{Name: f.Scanner}
+		}
+		if rule, ok := registry.For(f); ok {
+			d.Code = &rdCode{Value: rule.ID, URL: rule.URL}
+		} else if id := f.RuleID(); id != "" {
+			d.Code = &rdCode{Value: id}
+		}
+		out.Diagnostics = append(out.Diagnostics, d)
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func rdSeverity(s finding.Severity) string {
+	switch s {
+	case finding.Error:
+		return "ERROR"
+	case finding.Warning:
+		return "WARNING"
+	}
+	return "INFO"
+}
@@ -1,17 +1,27 @@
 package report
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"strings"
 
 	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/registry"
 )
 
 // ExportMarkdown returns a Markdown formatted report string.
 func ExportMarkdown(findings []finding.Finding) (string, error) {
+	return ExportMarkdownWithSummary(findings, nil)
+}
+
+// ExportMarkdownWithSummary is ExportMarkdown with the summary, when given, as its header.
+func ExportMarkdownWithSummary(findings []finding.Finding, summary *Summary) (string, error) {
 	var b strings.Builder
 	b.WriteString("# InfraCheck Report\n\n")
+	if summary != nil {
+		b.WriteString(summary.markdown())
+	}
 
 	if len(findings) == 0 {
 		b.WriteString("✅ No issues found.\n")
@@ -19,19 +29,72 @@ func ExportMarkdown(findings []finding.Finding) (string, error) {
 	}
 
 	for _, f := range findings {
-		b.WriteString(fmt.Sprintf("- **[%s]** `%s`: %s\n", f.Severity, f.File, f.Message))
+		message := f.Message
+		if rule, ok := registry.For(f); ok {
+			message += fmt.Sprintf(" ([%s](%s))", rule.ID, rule.URL)
+		}
+		if len(f.Controls) > 0 {
+			message += " — " + strings.Join(f.Controls, ", ")
+		}
+		if f.Environment != "" {
+			b.WriteString(fmt.Sprintf("- **[%s]** _%s_ `%s`: %s\n", f.Severity, f.Environment, f.Location(), message))
+		} else {
+			b.WriteString(fmt.Sprintf("- **[%s]** `%s`: %s\n", f.Severity, f.Location(), message))
+		}
+		if f.Snippet != nil {
+			b.WriteString(markdownSnippet(f))
+		}
 	}
 
 	return b.String(), nil
 }
 
+// markdownSnippet renders the source excerpt of f as a code block nested in its list item,
+// fenced with more backticks than any run in the excerpt.
+func markdownSnippet(f finding.Finding) string {
+	fence := "```"
+	for _, l := range f.Snippet.Lines {
+		for strings.Contains(l, fence) {
+			fence += "`"
+		}
+	}
+	var b strings.Builder
+	b.WriteString("\n  " + fence + "\n")
+	width := len(fmt.Sprint(f.Snippet.StartLine + len(f.Snippet.Lines) - 1))
+	for i, l := range f.Snippet.Lines {
+		marker := " "
+		if f.Snippet.StartLine+i == f.Line {
+			marker = ">"
+		}
+		b.WriteString(fmt.Sprintf("  %s %*d | %s\n", marker, width, f.Snippet.StartLine+i, l))
+	}
+	b.WriteString("  " + fence + "\n\n")
+	return b.String()
+}
+
+// Result is the versioned envelope of the JSON report, described by pkg/schema.
+type Result struct {
+	SchemaVersion string            `json:"schemaVersion"`
+	Findings      []finding.Finding `json:"findings"`
+	Sections      []Section         `json:"sections,omitempty"`
+	Summary       *Summary          `json:"summary,omitempty"`
+}
+
+// ReadResult parses a JSON report: the Result envelope, or the bare array of findings that
+// reports held before it.
+func ReadResult(data []byte) (Result, error) {
+	var r Result
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		err := json.Unmarshal(trimmed, &r.Findings)
+		return r, err
+	}
+	err := json.Unmarshal(data, &r)
+	return r, err
+}
+
 // ExportJSON returns the JSON formatted report string.
 func ExportJSON(findings []finding.Finding) (string, error) {
-	data, err := json.MarshalIndent(findings, "", "  ")
-	if err != nil {
-		return "", err
-	}
-	return string(data), nil
+	return ExportJSONWithSections(findings, nil)
 }
 
 // ExportGitHubActions returns a GitHub Actions annotation formatted string.
@@ -47,11 +110,24 @@ func ExportGitHubActions(findings []finding.Finding) (string, error) {
 		default:
 			level = "notice"
 		}
-		b.WriteString(fmt.Sprintf("::%s file=%s::%s\n", level, f.File, escapeGHA(f.Message)))
+		if f.Line > 0 {
+			b.WriteString(fmt.Sprintf("::%s file=%s,line=%d::%s\n", level, f.File, f.Line, escapeGHA(withControls(f))))
+			continue
+		}
+		b.WriteString(fmt.Sprintf("::%s file=%s::%s\n", level, f.File, escapeGHA(withControls(f))))
 	}
 	return b.String(), nil
 }
 
+// withControls is the message of f followed by the controls it fails, as "[CIS AWS 2.1.4]",
+// for the formats that have no field of their own for them.
+func withControls(f finding.Finding) string {
+	if len(f.Controls) == 0 {
+		return f.Message
+	}
+	return f.Message + " [" + strings.Join(f.Controls, ", ") + "]"
+}
+
 // escapeGHA escapes special characters for GitHub Actions annotations
 // GitHub Actions supports annotations using special logs:
 // ::error file=app.js,line=1,col=5::Missing semicolon
@@ -0,0 +1,81 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/pkg/schema"
+)
+
+// Section is an additional tabular block of a report, such as a per-play summary,
+// rendered after the findings.
+type Section struct {
+	Title   string     `json:"title"`
+	Columns []string   `json:"columns"`
+	Rows    [][]string `json:"rows"`
+}
+
+// ExportMarkdownSections renders sections as Markdown tables.
+func ExportMarkdownSections(sections []Section) string {
+	var b strings.Builder
+	for _, s := range sections {
+		b.WriteString(fmt.Sprintf("\n## %s\n\n", s.Title))
+		if len(s.Rows) == 0 {
+			b.WriteString("_Nothing to report._\n")
+			continue
+		}
+		b.WriteString("| " + strings.Join(escapeCells(s.Columns), " | ") + " |\n")
+		b.WriteString("|" + strings.Repeat("---|", len(s.Columns)) + "\n")
+		for _, row := range s.Rows {
+			b.WriteString("| " + strings.Join(escapeCells(row), " | ") + " |\n")
+		}
+	}
+	return b.String()
+}
+
+// ExportTextSections renders sections as aligned plain-text tables.
+func ExportTextSections(sections []Section) string {
+	var b strings.Builder
+	for _, s := range sections {
+		b.WriteString(fmt.Sprintf("\n== %s ==\n", s.Title))
+		if len(s.Rows) == 0 {
+			b.WriteString("(nothing to report)\n")
+			continue
+		}
+		w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join(s.Columns, "\t"))
+		for _, row := range s.Rows {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		w.Flush()
+	}
+	return b.String()
+}
+
+// ExportJSONWithSections returns the JSON result envelope holding both the findings and the sections.
+func ExportJSONWithSections(findings []finding.Finding, sections []Section) (string, error) {
+	return ExportResult(Result{SchemaVersion: schema.Version, Findings: findings, Sections: sections})
+}
+
+// ExportResult returns r as indented JSON, with an empty findings array rather than null.
+func ExportResult(r Result) (string, error) {
+	if r.Findings == nil {
+		r.Findings = []finding.Finding{}
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func escapeCells(cells []string) []string {
+	out := make([]string, len(cells))
+	for i, c := range cells {
+		out[i] = strings.ReplaceAll(c, "|", `\|`)
+	}
+	return out
+}
@@ -0,0 +1,154 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/registry"
+)
+
+// Rules listed in the text and Markdown summaries, and the length their names are cut to;
+// JSON lists every rule in full.
+const (
+	summaryRules   = 10
+	summaryRuleLen = 80
+)
+
+// Summary holds the headline numbers of a report, so dashboards need not recount findings.
+type Summary struct {
+	Findings     int            `json:"findings"`
+	BySeverity   map[string]int `json:"bySeverity"`
+	ByRule       []RuleCount    `json:"byRule"`
+	FilesScanned int            `json:"filesScanned"`
+	DurationMs   int64          `json:"durationMs"`
+}
+
+// RuleCount is the number of findings of one rule: its ID, or the message of a check no
+// rule is known for.
+type RuleCount struct {
+	Rule  string `json:"rule"`
+	Count int    `json:"count"`
+}
+
+// Summarize counts findings by severity and by rule, the most frequent rules first.
+func Summarize(findings []finding.Finding, filesScanned int, duration time.Duration) Summary {
+	var t Tally
+	for _, f := range findings {
+		t.Add(f)
+	}
+	return t.Summary(filesScanned, duration)
+}
+
+// Tally counts findings as they are reported, for a summary of a streamed report that does
+// not keep them. The zero value is ready to use.
+type Tally struct {
+	findings   int
+	bySeverity map[string]int
+	byRule     []RuleCount
+	index      map[string]int
+}
+
+// Add counts f.
+func (t *Tally) Add(f finding.Finding) {
+	if t.index == nil {
+		t.bySeverity = make(map[string]int)
+		t.index = make(map[string]int)
+	}
+	t.findings++
+	t.bySeverity[string(f.Severity)]++
+	rule := registry.Key(f)
+	i, ok := t.index[rule]
+	if !ok {
+		i = len(t.byRule)
+		t.index[rule] = i
+		t.byRule = append(t.byRule, RuleCount{Rule: rule})
+	}
+	t.byRule[i].Count++
+}
+
+// Summary returns the summary of the findings counted so far.
+func (t *Tally) Summary(filesScanned int, duration time.Duration) Summary {
+	s := Summary{
+		Findings:     t.findings,
+		BySeverity:   map[string]int{string(finding.Error): 0, string(finding.Warning): 0, string(finding.Info): 0},
+		ByRule:       append([]RuleCount{}, t.byRule...),
+		FilesScanned: filesScanned,
+		DurationMs:   duration.Milliseconds(),
+	}
+	for sev, n := range t.bySeverity {
+		s.BySeverity[sev] = n
+	}
+	sort.SliceStable(s.ByRule, func(i, j int) bool {
+		if s.ByRule[i].Count != s.ByRule[j].Count {
+			return s.ByRule[i].Count > s.ByRule[j].Count
+		}
+		return s.ByRule[i].Rule < s.ByRule[j].Rule
+	})
+	return s
+}
+
+// headline is the one-line version of the summary.
+func (s Summary) headline() string {
+	return fmt.Sprintf("%d finding(s): %d error(s), %d warning(s), %d info in %d file(s) scanned, %s",
+		s.Findings, s.BySeverity[string(finding.Error)], s.BySeverity[string(finding.Warning)], s.BySeverity[string(finding.Info)],
+		s.FilesScanned, s.duration())
+}
+
+func (s Summary) duration() string {
+	if s.DurationMs == 0 {
+		return "<1ms"
+	}
+	return (time.Duration(s.DurationMs) * time.Millisecond).String()
+}
+
+// Sections renders the summary as report tables: the totals, then the most frequent rules.
+func (s Summary) Sections() []Section {
+	totals := Section{
+		Title:   "Summary",
+		Columns: []string{"Metric", "Value"},
+		Rows: [][]string{
+			{"Findings", fmt.Sprint(s.Findings)},
+			{string(finding.Error), fmt.Sprint(s.BySeverity[string(finding.Error)])},
+			{string(finding.Warning), fmt.Sprint(s.BySeverity[string(finding.Warning)])},
+			{string(finding.Info), fmt.Sprint(s.BySeverity[string(finding.Info)])},
+			{"Files scanned", fmt.Sprint(s.FilesScanned)},
+			{"Duration", s.duration()},
+		},
+	}
+	if len(s.ByRule) == 0 {
+		return []Section{totals}
+	}
+	return []Section{totals, s.rulesSection()}
+}
+
+func (s Summary) rulesSection() Section {
+	rules := Section{Title: "Findings by Rule", Columns: []string{"Rule", "Findings"}}
+	for i, r := range s.ByRule {
+		if i == summaryRules {
+			rules.Rows = append(rules.Rows, []string{fmt.Sprintf("(%d more rule(s))", len(s.ByRule)-summaryRules), ""})
+			break
+		}
+		name := r.Rule
+		if rule, ok := registry.Lookup(r.Rule); ok {
+			name += " " + rule.Name
+		}
+		if runes := []rune(name); len(runes) > summaryRuleLen {
+			name = string(runes[:summaryRuleLen-1]) + "…"
+		}
+		rules.Rows = append(rules.Rows, []string{name, fmt.Sprint(r.Count)})
+	}
+	return rules
+}
+
+// markdown renders the summary as the header of a Markdown report.
+func (s Summary) markdown() string {
+	var b strings.Builder
+	b.WriteString("**" + s.headline() + "**\n")
+	if len(s.ByRule) > 0 {
+		b.WriteString(ExportMarkdownSections([]Section{s.rulesSection()}))
+	}
+	return b.String() + "\n"
+}
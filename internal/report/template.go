@@ -0,0 +1,74 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// TemplateData is what a --template report template is executed with.
+type TemplateData struct {
+	Findings []finding.Finding // every finding, as in the JSON report
+	Sections []Section
+	Summary  Summary
+	Scan     ScanInfo
+}
+
+// ScanInfo describes the run that produced a report. Fields that cannot be determined,
+// such as the commit outside a git repository, are empty.
+type ScanInfo struct {
+	Tool          string
+	Version       string
+	SchemaVersion string
+	Path          string // the scanned path as given on the command line
+	Repository    string
+	Commit        string
+	RunURL        string
+	ScannedAt     time.Time
+}
+
+// templateFuncs are available to report templates in addition to the text/template builtins.
+var templateFuncs = template.FuncMap{
+	"upper":   strings.ToUpper,
+	"lower":   strings.ToLower,
+	"join":    strings.Join,
+	"replace": strings.ReplaceAll,
+	"trim":    strings.TrimSpace,
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	// bySeverity keeps the findings of one severity: {{range bySeverity "ERROR" .Findings}}
+	"bySeverity": func(sev string, findings []finding.Finding) []finding.Finding {
+		var out []finding.Finding
+		for _, f := range findings {
+			if strings.EqualFold(string(f.Severity), sev) {
+				out = append(out, f)
+			}
+		}
+		return out
+	},
+}
+
+// ExportTemplate renders data through the text/template in the file at path.
+func ExportTemplate(path string, data TemplateData) (string, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs).Parse(string(src))
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	// parse and execution errors name the template file and line themselves
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,163 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// ANSI escapes used by colored text reports
+const (
+	ansiReset = "\033[0m"
+	ansiBold  = "\033[1m"
+	ansiDim   = "\033[2m"
+)
+
+var ansiSeverity = map[finding.Severity]string{
+	finding.Error:   "\033[1;31m",
+	finding.Warning: "\033[33m",
+	finding.Info:    "\033[36m",
+}
+
+// ExportText renders findings for reading in a terminal: grouped under their file, files in
+// name order and each file's findings by line, with the line and severity columns aligned.
+// Paths under the working directory are shown relative to it. With color, severities, file
+// names and environments are highlighted with ANSI escapes.
+func ExportText(findings []finding.Finding, color bool) string {
+	paint := func(code, s string) string {
+		if !color {
+			return s
+		}
+		return code + s + ansiReset
+	}
+
+	var files []string
+	byFile := make(map[string][]finding.Finding)
+	for _, f := range findings {
+		if _, ok := byFile[f.File]; !ok {
+			files = append(files, f.File)
+		}
+		byFile[f.File] = append(byFile[f.File], f)
+	}
+	sort.Strings(files)
+
+	var b strings.Builder
+	for i, file := range files {
+		group := byFile[file]
+		sort.SliceStable(group, func(i, j int) bool { return group[i].Line < group[j].Line })
+		lineWidth := 0
+		for _, f := range group {
+			if f.Line > 0 {
+				lineWidth = max(lineWidth, len(fmt.Sprint(f.Line)))
+			}
+		}
+
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(paint(ansiBold, displayPath(file)) + "\n")
+		for _, f := range group {
+			writeFinding(&b, f, lineWidth, paint)
+		}
+	}
+	return b.String()
+}
+
+// writeFinding writes one finding of a file's group, its line number padded to lineWidth.
+func writeFinding(b *strings.Builder, f finding.Finding, lineWidth int, paint func(code, s string) string) {
+	line := ""
+	if f.Line > 0 {
+		line = fmt.Sprint(f.Line)
+	}
+	b.WriteString("  ")
+	if lineWidth > 0 {
+		b.WriteString(paint(ansiDim, fmt.Sprintf("%*s", lineWidth, line)) + "  ")
+	}
+	// pad before painting so escapes do not count towards the column width
+	b.WriteString(paint(ansiSeverity[f.Severity], fmt.Sprintf("%-5s", f.Severity)) + "  ")
+	if f.Environment != "" {
+		b.WriteString(paint(ansiDim, "("+f.Environment+")") + " ")
+	}
+	b.WriteString(f.Message)
+	if len(f.Controls) > 0 {
+		b.WriteString(" " + paint(ansiDim, "["+strings.Join(f.Controls, ", ")+"]"))
+	}
+	b.WriteString("\n")
+	if f.Snippet != nil {
+		writeSnippet(b, f, paint)
+	}
+}
+
+// TextStream writes findings in the text format as they arrive rather than grouped and sorted:
+// a file's name comes before its first finding and again whenever findings of other files came
+// in between. Line numbers are not aligned, as the findings still to come are unknown.
+type TextStream struct {
+	w     io.Writer
+	paint func(code, s string) string
+	file  string
+	wrote bool
+}
+
+// NewTextStream returns a TextStream writing to w, with ANSI colors when color is set.
+func NewTextStream(w io.Writer, color bool) *TextStream {
+	return &TextStream{w: w, paint: func(code, s string) string {
+		if !color {
+			return s
+		}
+		return code + s + ansiReset
+	}}
+}
+
+// Write writes f, under the name of its file unless it follows a finding of the same file.
+func (t *TextStream) Write(f finding.Finding) error {
+	var b strings.Builder
+	if !t.wrote || f.File != t.file {
+		if t.wrote {
+			b.WriteString("\n")
+		}
+		b.WriteString(t.paint(ansiBold, displayPath(f.File)) + "\n")
+		t.file, t.wrote = f.File, true
+	}
+	width := 0
+	if f.Line > 0 {
+		width = len(fmt.Sprint(f.Line))
+	}
+	writeFinding(&b, f, width, t.paint)
+	_, err := io.WriteString(t.w, b.String())
+	return err
+}
+
+// writeSnippet writes the source excerpt of f below it, numbered, with the line the finding
+// points at marked by '>'.
+func writeSnippet(b *strings.Builder, f finding.Finding, paint func(code, s string) string) {
+	width := len(fmt.Sprint(f.Snippet.StartLine + len(f.Snippet.Lines) - 1))
+	for i, l := range f.Snippet.Lines {
+		n := f.Snippet.StartLine + i
+		if n == f.Line {
+			b.WriteString(fmt.Sprintf("    > %*d | %s\n", width, n, l))
+			continue
+		}
+		b.WriteString(paint(ansiDim, fmt.Sprintf("      %*d | %s", width, n, l)) + "\n")
+	}
+}
+
+// displayPath shortens an absolute path under the working directory to a relative one.
+func displayPath(path string) string {
+	if !filepath.IsAbs(path) {
+		return path
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return rel
+}
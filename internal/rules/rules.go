@@ -0,0 +1,928 @@
+// custom rule definitions authored by platform teams as YAML files
+package rules
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"gopkg.in/yaml.v3"
+
+	"github.com/salchaD-27/infra-check/internal/cloudformation"
+	"github.com/salchaD-27/infra-check/internal/dockerfile"
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/glob"
+	"github.com/salchaD-27/infra-check/internal/helm"
+	"github.com/salchaD-27/infra-check/internal/kustomize"
+	"github.com/salchaD-27/infra-check/internal/nomad"
+	"github.com/salchaD-27/infra-check/internal/puppet"
+	"github.com/salchaD-27/infra-check/internal/registry"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+	"github.com/salchaD-27/infra-check/internal/serverless"
+)
+
+// DefaultDir is where rule files and their fixtures live unless --rules-dir is given.
+const DefaultDir = "rules"
+
+// TestsFile is the file inside the rules directory listing fixture test entries.
+const TestsFile = "tests.yaml"
+
+// Rule is a single user-authored check.
+//
+//	id: ORG001
+//	scanner: terraform
+//	severity: WARN
+//	message: S3 buckets must not be public
+//	match:
+//	  resource_type: aws_s3_bucket
+//	  attribute: acl
+//	  equals: public-read
+//	compliance:
+//	  pci: ["1.4.4"]
+//
+// Message is a text/template rendered with the matched resource's .ID, .Type,
+// .Name, .Attribute, .Value and .File. A message without template actions gets
+// the resource type and name appended. Compliance maps frameworks to the controls
+// the rule checks, as for the built-in rules.
+type Rule struct {
+	ID         string              `yaml:"id"`
+	Scanner    string              `yaml:"scanner"`
+	Severity   finding.Severity    `yaml:"severity"`
+	Message    string              `yaml:"message"`
+	Match      Match               `yaml:"match"`
+	Compliance registry.Compliance `yaml:"compliance,omitempty"`
+
+	// File is the rule file the rule was loaded from.
+	File string `yaml:"-"`
+
+	pattern *regexp.Regexp
+	message *template.Template
+}
+
+// Match describes which resources a rule fires on.
+// ResourceType and Attribute are glob patterns; nested attributes are addressed
+// with dots (versioning.enabled, spec.template.spec.hostNetwork) and list elements
+// share their parent's name. A rule fires once per matching attribute whose value
+// equals Equals and matches the Matches regular expression (when given), or once
+// per resource when Absent is set and no attribute matches.
+type Match struct {
+	ResourceType string `yaml:"resource_type"`
+	Attribute    string `yaml:"attribute"`
+	Equals       string `yaml:"equals,omitempty"`
+	Matches      string `yaml:"matches,omitempty"`
+	Absent       bool   `yaml:"absent,omitempty"`
+}
+
+// Test is an entry in tests.yaml: the rule must not fire on Pass and must fire on Fail.
+type Test struct {
+	Rule string `yaml:"rule"`
+	Pass string `yaml:"pass"`
+	Fail string `yaml:"fail"`
+}
+
+type testsFile struct {
+	Tests []Test `yaml:"tests"`
+}
+
+// Scanners lists the scanner names rules can target: every registered scanner but the
+// credential patterns, which read files as text rather than as resources.
+var Scanners = []string{
+	"terraform", "tfplan", "tfstate", "ansible", "puppet", "kubernetes", "helm", "kustomize",
+	"pulumi", "cloudformation", "cdk", "serverless", "nomad", "dockerfile",
+}
+
+var ruleIDRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// Validate checks that a rule has everything needed to be evaluated
+// and compiles its value pattern and message template.
+func (r *Rule) Validate() error {
+	if !ruleIDRegex.MatchString(r.ID) {
+		return fmt.Errorf("invalid rule id '%s'", r.ID)
+	}
+	if !isKnownScanner(r.Scanner) {
+		if _, ok := scanner.Lookup(r.Scanner); ok {
+			return fmt.Errorf("rule %s: the %s scanner has no resources for rules to match (want one of %s)", r.ID, r.Scanner, strings.Join(Scanners, ", "))
+		}
+		return fmt.Errorf("rule %s: unknown scanner '%s' (want one of %s)", r.ID, r.Scanner, strings.Join(Scanners, ", "))
+	}
+	switch r.Severity {
+	case finding.Info, finding.Warning, finding.Error:
+	default:
+		return fmt.Errorf("rule %s: invalid severity '%s'", r.ID, r.Severity)
+	}
+	if r.Match.ResourceType == "" || r.Match.Attribute == "" {
+		return fmt.Errorf("rule %s: match needs both resource_type and attribute", r.ID)
+	}
+	if r.Match.Absent && (r.Match.Equals != "" || r.Match.Matches != "") {
+		return fmt.Errorf("rule %s: absent cannot be combined with equals or matches", r.ID)
+	}
+	if err := r.Compliance.Validate(); err != nil {
+		return fmt.Errorf("rule %s: %v", r.ID, err)
+	}
+	if r.Match.Matches != "" {
+		re, err := regexp.Compile(r.Match.Matches)
+		if err != nil {
+			return fmt.Errorf("rule %s: invalid matches pattern: %v", r.ID, err)
+		}
+		r.pattern = re
+	}
+	text := r.Message
+	if !strings.Contains(text, "{{") {
+		text += " ({{.Type}} '{{.Name}}')"
+	}
+	tmpl, err := template.New(r.ID).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return fmt.Errorf("rule %s: invalid message template: %v", r.ID, err)
+	}
+	r.message = tmpl
+	return nil
+}
+
+func isKnownScanner(name string) bool {
+	for _, s := range Scanners {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Load reads every *.yaml/*.yml rule file directly inside dir (tests.yaml excluded).
+// A missing directory is not an error and yields no rules.
+func Load(dir string) ([]Rule, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var loaded []Rule
+	seen := make(map[string]string)
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if e.IsDir() || (ext != ".yaml" && ext != ".yml") || e.Name() == TestsFile {
+			continue
+		}
+		p := filepath.Join(dir, e.Name())
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		var r Rule
+		if err := yaml.Unmarshal(data, &r); err != nil {
+			return nil, fmt.Errorf("%s: %v", p, err)
+		}
+		if err := r.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %v", p, err)
+		}
+		if other, dup := seen[r.ID]; dup {
+			return nil, fmt.Errorf("%s: rule id %s already defined in %s", p, r.ID, other)
+		}
+		seen[r.ID] = p
+		r.File = p
+		loaded = append(loaded, r)
+	}
+	return loaded, nil
+}
+
+// LoadTests reads the tests.yaml entries in dir. Fixture paths are resolved relative to dir.
+func LoadTests(dir string) ([]Test, error) {
+	data, err := os.ReadFile(filepath.Join(dir, TestsFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var tf testsFile
+	if err := yaml.Unmarshal(data, &tf); err != nil {
+		return nil, fmt.Errorf("%s: %v", TestsFile, err)
+	}
+	for i, t := range tf.Tests {
+		tf.Tests[i].Pass = filepath.Join(dir, t.Pass)
+		tf.Tests[i].Fail = filepath.Join(dir, t.Fail)
+	}
+	return tf.Tests, nil
+}
+
+// resource is the scanner-neutral view of a configuration item that rules match against:
+// a Terraform resource block, an Ansible task module invocation, a Puppet resource, a
+// Kubernetes object, a CloudFormation resource, a Dockerfile instruction and so on.
+type resource struct {
+	Type  string
+	Name  string
+	Attrs []attribute
+	Line  int
+	File  string // the rendered template it comes from, when not the file read
+}
+
+// attribute is one (possibly nested) attribute of a resource. Non-literal values are recorded as "".
+type attribute struct {
+	Name  string
+	Value string
+}
+
+// flatten appends the leaves of a decoded YAML/JSON value as dotted attributes.
+func flatten(prefix string, v interface{}, out []attribute) []attribute {
+	join := func(k string) string {
+		if prefix == "" {
+			return k
+		}
+		return prefix + "." + k
+	}
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			out = flatten(join(k), child, out)
+		}
+	case []interface{}:
+		for _, child := range val {
+			out = flatten(prefix, child, out)
+		}
+	case nil:
+		out = append(out, attribute{prefix, ""})
+	default:
+		out = append(out, attribute{prefix, fmt.Sprint(val)})
+	}
+	return out
+}
+
+// messageData is what rule message templates are rendered with.
+type messageData struct {
+	ID, Type, Name, Attribute, Value, File string
+}
+
+// Evaluate runs the rule against every matching file under path.
+func Evaluate(r Rule, path string) ([]finding.Finding, error) {
+	var findings []finding.Finding
+
+	err := scanner.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		if !matchesScanner(r.Scanner, p) {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		resources, err := extract(r.Scanner, p, data)
+		if err != nil {
+			// Parse errors are reported by the built-in scanners; custom rules just skip the file.
+			return nil
+		}
+		for _, res := range resources {
+			if !glob.Match(r.Match.ResourceType, res.Type) {
+				continue
+			}
+			sort.SliceStable(res.Attrs, func(i, j int) bool { return res.Attrs[i].Name < res.Attrs[j].Name })
+			file := p
+			if res.File != "" {
+				file = res.File
+			}
+			for _, attr := range r.fires(res) {
+				msg, err := r.render(messageData{r.ID, res.Type, res.Name, attr.Name, attr.Value, file})
+				if err != nil {
+					return err
+				}
+				findings = append(findings, finding.Finding{
+					File:     file,
+					Severity: r.Severity,
+					Message:  msg,
+					Line:     res.Line,
+				})
+			}
+		}
+		return nil
+	})
+
+	return findings, err
+}
+
+func (r Rule) render(data messageData) (string, error) {
+	if r.message == nil {
+		if err := r.Validate(); err != nil {
+			return "", err
+		}
+	}
+	var b strings.Builder
+	if err := r.message.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("rule %s: rendering message: %v", r.ID, err)
+	}
+	return fmt.Sprintf("[%s] %s", r.ID, b.String()), nil
+}
+
+// EvaluateAll runs every rule targeting scanner against path.
+func EvaluateAll(all []Rule, scanner, path string) ([]finding.Finding, error) {
+	var findings []finding.Finding
+	for _, r := range all {
+		if r.Scanner != scanner {
+			continue
+		}
+		fs, err := Evaluate(r, path)
+		if err != nil {
+			return findings, err
+		}
+		findings = append(findings, fs...)
+	}
+	return findings, nil
+}
+
+// fires returns the attributes of res the rule fires on. For absent rules a single
+// placeholder attribute named after the pattern is returned when nothing matches.
+func (r Rule) fires(res resource) []attribute {
+	var matched, firing []attribute
+	for _, attr := range res.Attrs {
+		if glob.Match(r.Match.Attribute, attr.Name) {
+			matched = append(matched, attr)
+		}
+	}
+	if r.Match.Absent {
+		if len(matched) == 0 {
+			return []attribute{{Name: r.Match.Attribute}}
+		}
+		return nil
+	}
+
+	pattern := r.pattern
+	if pattern == nil && r.Match.Matches != "" {
+		pattern = regexp.MustCompile(r.Match.Matches)
+	}
+	for _, attr := range matched {
+		if r.Match.Equals != "" || pattern == nil {
+			if attr.Value != r.Match.Equals {
+				continue
+			}
+		}
+		if pattern != nil && !pattern.MatchString(attr.Value) {
+			continue
+		}
+		firing = append(firing, attr)
+	}
+	return firing
+}
+
+func matchesScanner(scanner, p string) bool {
+	ext := filepath.Ext(p)
+	switch scanner {
+	case "terraform":
+		return ext == ".tf"
+	case "tfplan":
+		return ext == ".json"
+	case "tfstate":
+		return ext == ".tfstate"
+	case "ansible":
+		return ext == ".yml" || ext == ".yaml"
+	case "puppet":
+		return ext == ".pp"
+	case "kubernetes":
+		return ext == ".yml" || ext == ".yaml"
+	case "helm":
+		return filepath.Base(p) == "Chart.yaml"
+	case "kustomize":
+		return kustomize.IsKustomization(p)
+	case "pulumi":
+		base := filepath.Base(p)
+		return base == "Pulumi.yaml" || base == "Pulumi.yml"
+	case "cloudformation":
+		return cloudformation.IsTemplateFile(p)
+	case "cdk":
+		return strings.HasSuffix(p, ".template.json")
+	case "serverless":
+		return serverless.IsConfig(p)
+	case "nomad":
+		return nomad.IsJobFile(p)
+	case "dockerfile":
+		return dockerfile.IsDockerfile(p)
+	}
+	return false
+}
+
+func extract(scanner, p string, data []byte) ([]resource, error) {
+	switch scanner {
+	case "terraform":
+		return extractTerraform(p, data)
+	case "tfplan":
+		return extractPlan(data)
+	case "tfstate":
+		return extractState(data)
+	case "ansible":
+		return extractAnsible(data)
+	case "puppet":
+		return extractPuppet(data)
+	case "kubernetes":
+		return extractKubernetes(data)
+	case "helm":
+		return extractChart(filepath.Dir(p))
+	case "kustomize":
+		return extractKustomization(filepath.Dir(p))
+	case "pulumi":
+		return extractPulumi(data)
+	case "cloudformation", "cdk":
+		return extractCloudFormation(data)
+	case "serverless":
+		return extractServerless(data)
+	case "nomad":
+		return extractNomad(p, data)
+	case "dockerfile":
+		return extractDockerfile(data), nil
+	}
+	return nil, fmt.Errorf("unknown scanner '%s'", scanner)
+}
+
+func extractTerraform(p string, data []byte) ([]resource, error) {
+	file, diags := hclsyntax.ParseConfig(data, p, hclStartPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, nil
+	}
+	var out []resource
+	for _, block := range body.Blocks {
+		if block.Type != "resource" || len(block.Labels) != 2 {
+			continue
+		}
+		out = append(out, resource{
+			Type:  block.Labels[0],
+			Name:  block.Labels[1],
+			Attrs: terraformAttributes("", block.Body, nil),
+			Line:  block.DefRange().Start.Line,
+		})
+	}
+	return out, nil
+}
+
+// terraformAttributes flattens a block body, naming nested block attributes block_type.attribute
+// and literal map or object entries attribute.key (tags.Environment).
+func terraformAttributes(prefix string, body *hclsyntax.Body, out []attribute) []attribute {
+	for name, attr := range body.Attributes {
+		val, diags := attr.Expr.Value(nil)
+		if !diags.HasErrors() && val.IsKnown() && !val.IsNull() && (val.Type().IsObjectType() || val.Type().IsMapType()) {
+			for it := val.ElementIterator(); it.Next(); {
+				k, v := it.Element()
+				out = append(out, attribute{prefix + name + "." + k.AsString(), literalValue(v)})
+			}
+			continue
+		}
+		out = append(out, attribute{prefix + name, literalString(attr.Expr)})
+	}
+	for _, nested := range body.Blocks {
+		out = terraformAttributes(prefix+nested.Type+".", nested.Body, out)
+	}
+	return out
+}
+
+var hclStartPos = hcl.Pos{Line: 1, Column: 1}
+
+// literalString renders a literal HCL value as a string, or "" when it depends on other values.
+func literalString(expr hcl.Expression) string {
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() {
+		return ""
+	}
+	return literalValue(val)
+}
+
+func literalValue(val cty.Value) string {
+	if val.IsNull() || !val.IsKnown() {
+		return ""
+	}
+	switch val.Type() {
+	case cty.String:
+		return val.AsString()
+	case cty.Bool:
+		return fmt.Sprint(val.True())
+	case cty.Number:
+		return val.AsBigFloat().String()
+	}
+	return ""
+}
+
+func extractAnsible(data []byte) ([]resource, error) {
+	var plays []struct {
+		Tasks []map[string]interface{} `yaml:"tasks"`
+	}
+	if err := yaml.Unmarshal(data, &plays); err != nil {
+		return nil, err
+	}
+	var out []resource
+	for _, play := range plays {
+		for _, task := range play.Tasks {
+			name, _ := task["name"].(string)
+			modules := make([]string, 0, len(task))
+			for module := range task {
+				modules = append(modules, module)
+			}
+			sort.Strings(modules)
+			for _, module := range modules {
+				argMap, ok := task[module].(map[string]interface{})
+				if !ok {
+					continue
+				}
+				out = append(out, resource{Type: module, Name: name, Attrs: flatten("", argMap, nil)})
+			}
+		}
+	}
+	return out, nil
+}
+
+// extractPuppet returns the resources a manifest declares, wherever they are nested,
+// naming literal hash entries attribute.key; array elements share the attribute's name.
+func extractPuppet(data []byte) ([]resource, error) {
+	manifest, err := puppet.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	var out []resource
+	for _, r := range manifest.Resources {
+		res := resource{Type: r.Type, Name: r.Title.String(), Line: r.Line}
+		for _, a := range r.Params {
+			res.Attrs = puppetAttributes(a.Name, a.Value, res.Attrs)
+		}
+		out = append(out, res)
+	}
+	return out, nil
+}
+
+func puppetAttributes(name string, v puppet.Value, out []attribute) []attribute {
+	switch {
+	case v.Kind == puppet.Hash:
+		for i, item := range v.Items {
+			out = puppetAttributes(name+"."+v.Keys[i].String(), item, out)
+		}
+		return out
+	case v.Kind == puppet.Array:
+		for _, item := range v.Items {
+			out = puppetAttributes(name, item, out)
+		}
+		return out
+	case v.IsLiteral():
+		return append(out, attribute{name, v.Text})
+	}
+	return append(out, attribute{name, ""})
+}
+
+// extractKubernetes treats every object in a multi-document manifest as a resource of its kind.
+func extractKubernetes(data []byte) ([]resource, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	var out []resource
+	for {
+		var doc map[string]interface{}
+		err := dec.Decode(&doc)
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		kind, _ := doc["kind"].(string)
+		if kind == "" {
+			continue
+		}
+		name := ""
+		if meta, ok := doc["metadata"].(map[string]interface{}); ok {
+			name, _ = meta["name"].(string)
+		}
+		delete(doc, "kind")
+		out = append(out, resource{Type: kind, Name: name, Attrs: flatten("", doc, nil)})
+	}
+}
+
+// extractPulumi returns the resources of a Pulumi YAML program with their properties.
+func extractPulumi(data []byte) ([]resource, error) {
+	var program struct {
+		Resources map[string]struct {
+			Type       string                 `yaml:"type"`
+			Properties map[string]interface{} `yaml:"properties"`
+		} `yaml:"resources"`
+	}
+	if err := yaml.Unmarshal(data, &program); err != nil {
+		return nil, err
+	}
+	var out []resource
+	for name, r := range program.Resources {
+		out = append(out, resource{Type: r.Type, Name: name, Attrs: flatten("", r.Properties, nil)})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// extractPlan returns the resources of a JSON plan, named by address, with their planned values.
+func extractPlan(data []byte) ([]resource, error) {
+	var plan struct {
+		FormatVersion string `json:"format_version"`
+		PlannedValues struct {
+			RootModule planModule `json:"root_module"`
+		} `json:"planned_values"`
+	}
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, err
+	}
+	if plan.FormatVersion == "" {
+		return nil, nil // some other JSON file
+	}
+	var out []resource
+	var walk func(m planModule)
+	walk = func(m planModule) {
+		for _, r := range m.Resources {
+			if r.Mode == "managed" {
+				out = append(out, resource{Type: r.Type, Name: r.Address, Attrs: flatten("", r.Values, nil)})
+			}
+		}
+		for _, child := range m.ChildModules {
+			walk(child)
+		}
+	}
+	walk(plan.PlannedValues.RootModule)
+	return out, nil
+}
+
+type planModule struct {
+	Resources []struct {
+		Address string                 `json:"address"`
+		Mode    string                 `json:"mode"`
+		Type    string                 `json:"type"`
+		Values  map[string]interface{} `json:"values"`
+	} `json:"resources"`
+	ChildModules []planModule `json:"child_modules"`
+}
+
+// extractState returns every instance of the managed resources in a state file, named by
+// address, with its recorded attributes.
+func extractState(data []byte) ([]resource, error) {
+	var state struct {
+		Resources []struct {
+			Module    string `json:"module"`
+			Mode      string `json:"mode"`
+			Type      string `json:"type"`
+			Name      string `json:"name"`
+			Instances []struct {
+				IndexKey   interface{}            `json:"index_key"`
+				Attributes map[string]interface{} `json:"attributes"`
+			} `json:"instances"`
+		} `json:"resources"`
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	var out []resource
+	for _, r := range state.Resources {
+		if r.Mode != "managed" {
+			continue
+		}
+		addr := r.Type + "." + r.Name
+		if r.Module != "" {
+			addr = r.Module + "." + addr
+		}
+		for _, inst := range r.Instances {
+			name := addr
+			switch key := inst.IndexKey.(type) {
+			case string:
+				name += fmt.Sprintf("[%q]", key)
+			case float64:
+				name += fmt.Sprintf("[%d]", int(key))
+			}
+			out = append(out, resource{Type: r.Type, Name: name, Attrs: flatten("", inst.Attributes, nil)})
+		}
+	}
+	return out, nil
+}
+
+// extractChart renders the chart in dir with its default values and returns the objects of
+// the rendered manifests, reported on their templates.
+func extractChart(dir string) ([]resource, error) {
+	rendered, err := helm.Render(dir)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(rendered))
+	for name := range rendered {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var out []resource
+	for _, name := range names {
+		objects, err := extractKubernetes([]byte(rendered[name]))
+		if err != nil {
+			continue // reported by the helm scanner
+		}
+		for i := range objects {
+			objects[i].File = name
+		}
+		out = append(out, objects...)
+	}
+	return out, nil
+}
+
+// extractKustomization builds the kustomization in dir and returns the objects it produces.
+func extractKustomization(dir string) ([]resource, error) {
+	built, err := kustomize.Build(dir)
+	if err != nil {
+		return nil, err
+	}
+	return extractKubernetes(built)
+}
+
+// extractCloudFormation returns the resources of a CloudFormation template, named by
+// logical ID, with their properties. Intrinsic functions such as !Ref are not literal values.
+func extractCloudFormation(data []byte) ([]resource, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 || !cloudformation.IsTemplate(data) {
+		return nil, nil
+	}
+	return cloudFormationResources(mappingValue(doc.Content[0], "Resources")), nil
+}
+
+func cloudFormationResources(resources *yaml.Node) []resource {
+	var out []resource
+	for i := 0; resources != nil && resources.Kind == yaml.MappingNode && i+1 < len(resources.Content); i += 2 {
+		id, body := resources.Content[i], resources.Content[i+1]
+		typ := mappingValue(body, "Type")
+		if typ == nil || typ.Kind != yaml.ScalarNode {
+			continue
+		}
+		var attrs []attribute
+		if props := mappingValue(body, "Properties"); props != nil {
+			attrs = flattenNode("", props, nil)
+		}
+		out = append(out, resource{Type: typ.Value, Name: id.Value, Attrs: attrs, Line: id.Line})
+	}
+	return out
+}
+
+// extractServerless returns the provider (type provider), each function (type function)
+// and the CloudFormation resources of a serverless.yml.
+func extractServerless(data []byte) ([]resource, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	root := doc.Content[0]
+	var out []resource
+	if provider := mappingValue(root, "provider"); provider != nil {
+		name := ""
+		if n := mappingValue(provider, "name"); n != nil {
+			name = n.Value
+		}
+		out = append(out, resource{Type: "provider", Name: name, Attrs: flattenNode("", provider, nil), Line: provider.Line})
+	}
+	functions := mappingValue(root, "functions")
+	for i := 0; functions != nil && functions.Kind == yaml.MappingNode && i+1 < len(functions.Content); i += 2 {
+		name, fn := functions.Content[i], functions.Content[i+1]
+		out = append(out, resource{Type: "function", Name: name.Value, Attrs: flattenNode("", fn, nil), Line: name.Line})
+	}
+	out = append(out, cloudFormationResources(mappingValue(mappingValue(root, "resources"), "Resources"))...)
+	return out, nil
+}
+
+// mappingValue returns the value of key in a mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// flattenNode is flatten for a YAML node, recording CloudFormation intrinsic functions, in
+// their short (!Ref) or long (Ref, Fn::GetAtt) form, as non-literal values.
+func flattenNode(prefix string, node *yaml.Node, out []attribute) []attribute {
+	join := func(k string) string {
+		if prefix == "" {
+			return k
+		}
+		return prefix + "." + k
+	}
+	if node.Tag != "" && !strings.HasPrefix(node.Tag, "!!") {
+		return append(out, attribute{prefix, ""})
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		if len(node.Content) == 2 && (node.Content[0].Value == "Ref" || strings.HasPrefix(node.Content[0].Value, "Fn::")) {
+			return append(out, attribute{prefix, ""})
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			out = flattenNode(join(node.Content[i].Value), node.Content[i+1], out)
+		}
+	case yaml.SequenceNode:
+		for _, child := range node.Content {
+			out = flattenNode(prefix, child, out)
+		}
+	case yaml.AliasNode:
+		out = flattenNode(prefix, node.Alias, out)
+	case yaml.ScalarNode:
+		if node.Tag == "!!null" {
+			return append(out, attribute{prefix, ""})
+		}
+		out = append(out, attribute{prefix, node.Value})
+	}
+	return out
+}
+
+// extractNomad returns the jobs, groups and tasks of a Nomad job file, typed job, group and
+// task and named by their labels, with their attributes flattened as for Terraform.
+func extractNomad(p string, data []byte) ([]resource, error) {
+	file, diags := hclsyntax.ParseConfig(data, p, hclStartPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, nil
+	}
+	var out []resource
+	var walk func(body *hclsyntax.Body, types ...string)
+	walk = func(body *hclsyntax.Body, types ...string) {
+		if len(types) == 0 {
+			return
+		}
+		for _, block := range body.Blocks {
+			if block.Type != types[0] {
+				continue
+			}
+			name := ""
+			if len(block.Labels) > 0 {
+				name = block.Labels[0]
+			}
+			out = append(out, resource{
+				Type:  block.Type,
+				Name:  name,
+				Attrs: terraformAttributes("", block.Body, nil),
+				Line:  block.DefRange().Start.Line,
+			})
+			walk(block.Body, types[1:]...)
+		}
+	}
+	walk(body, "job", "group", "task")
+	return out, nil
+}
+
+// extractDockerfile returns each instruction of a Dockerfile, typed by its keyword (FROM,
+// RUN, USER, ...) and named by the build stage it belongs to, with its arguments as args and,
+// for ENV, ARG and LABEL, each key it sets as an attribute of its own.
+func extractDockerfile(data []byte) []resource {
+	var out []resource
+	stage := ""
+	for _, in := range dockerfile.Parse(string(data)) {
+		if in.Cmd == "FROM" {
+			fields := strings.Fields(in.Args)
+			stage = ""
+			if len(fields) > 0 {
+				stage = fields[0]
+			}
+			if len(fields) >= 3 && strings.EqualFold(fields[len(fields)-2], "AS") {
+				stage = fields[len(fields)-1]
+			}
+		}
+		attrs := []attribute{{"args", in.Args}}
+		switch in.Cmd {
+		case "ENV", "ARG", "LABEL":
+			attrs = append(attrs, dockerfileKeys(in.Cmd, in.Args)...)
+		}
+		out = append(out, resource{Type: in.Cmd, Name: stage, Attrs: attrs, Line: in.Line})
+	}
+	return out
+}
+
+// dockerfileKeys returns the keys an ENV, ARG or LABEL instruction sets with their values:
+// key=value pairs, or the legacy ENV key value form.
+func dockerfileKeys(cmd, args string) []attribute {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		return nil
+	}
+	if cmd == "ENV" && !strings.Contains(fields[0], "=") {
+		return []attribute{{fields[0], strings.TrimSpace(strings.TrimPrefix(args, fields[0]))}}
+	}
+	var out []attribute
+	for _, field := range fields {
+		key, value, _ := strings.Cut(field, "=")
+		out = append(out, attribute{key, strings.Trim(value, `"'`)})
+	}
+	return out
+}
@@ -0,0 +1,56 @@
+package rules_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/salchaD-27/infra-check/internal/rules"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+	_ "github.com/salchaD-27/infra-check/internal/scanners"
+)
+
+// Rules can target every registered scanner but the overlays, which have no resources, and
+// the rule `rules new` scaffolds for each scanner passes its own fixtures.
+func TestEveryScannerTargetable(t *testing.T) {
+	targetable := make(map[string]bool)
+	for _, name := range rules.Scanners {
+		targetable[name] = true
+	}
+	for _, s := range scanner.All() {
+		if o, ok := s.(scanner.Overlay); ok && o.Overlay() {
+			continue
+		}
+		if !targetable[s.Name()] {
+			t.Errorf("custom rules cannot target the %s scanner", s.Name())
+		}
+	}
+
+	dir := t.TempDir()
+	for _, name := range rules.Scanners {
+		if _, err := rules.Scaffold(dir, name, "X_"+name); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+	loaded, err := rules.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range loaded {
+		pass, err := rules.Evaluate(r, filepath.Join(dir, "tests", r.ID, "pass"))
+		if err != nil {
+			t.Fatalf("%s: %v", r.ID, err)
+		}
+		fail, err := rules.Evaluate(r, filepath.Join(dir, "tests", r.ID, "fail"))
+		if err != nil {
+			t.Fatalf("%s: %v", r.ID, err)
+		}
+		if len(pass) > 0 || len(fail) == 0 {
+			t.Errorf("%s: %d findings on the passing fixture and %d on the failing one", r.ID, len(pass), len(fail))
+		}
+	}
+
+	invalid := rules.Rule{ID: "X_secrets", Scanner: "secrets", Severity: "WARN", Message: "m", Match: rules.Match{ResourceType: "*", Attribute: "*"}}
+	if err := invalid.Validate(); err == nil {
+		t.Error("a rule targeting the secrets scanner was accepted")
+	}
+}
@@ -0,0 +1,366 @@
+package rules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// scaffoldTemplate is the starter content generated for one scanner:
+// a rule plus a fixture it fires on and one it stays quiet on.
+type scaffoldTemplate struct {
+	Match       Match
+	Message     string
+	FixtureName string
+	Failing     string
+	Passing     string
+	Shared      map[string]string // files both fixtures need as they are, e.g. a Chart.yaml
+}
+
+// deploymentFixture is a Deployment running image, the manifest of the kubernetes, helm
+// and kustomize templates.
+func deploymentFixture(image string) string {
+	return `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+        - name: web
+          image: ` + image + "\n"
+}
+
+// bucketTemplateFixture is a CloudFormation template in JSON with a bucket whose
+// AccessControl is acl, the fixture of the cloudformation and cdk templates.
+func bucketTemplateFixture(acl string) string {
+	return `{
+  "AWSTemplateFormatVersion": "2010-09-09",
+  "Resources": {
+    "Bucket": {
+      "Type": "AWS::S3::Bucket",
+      "Properties": {
+        "AccessControl": "` + acl + `"
+      }
+    }
+  }
+}
+`
+}
+
+// unpinnedImage matches container images without a version, or on the latest tag.
+const unpinnedImage = `:latest$|^[^:@]+$`
+
+var scaffoldTemplates = map[string]scaffoldTemplate{
+	"terraform": {
+		Match:       Match{ResourceType: "aws_s3_bucket", Attribute: "acl", Equals: "public-read"},
+		Message:     "S3 buckets must not be publicly readable",
+		FixtureName: "main.tf",
+		Failing: `resource "aws_s3_bucket" "example" {
+  bucket = "example"
+  acl    = "public-read"
+}
+`,
+		Passing: `resource "aws_s3_bucket" "example" {
+  bucket = "example"
+  acl    = "private"
+}
+`,
+	},
+	"ansible": {
+		Match:       Match{ResourceType: "file", Attribute: "mode", Equals: "0777"},
+		Message:     "Files must not be world-writable",
+		FixtureName: "playbook.yml",
+		Failing: `- hosts: all
+  tasks:
+    - name: Create directory
+      file:
+        path: /srv/app
+        state: directory
+        mode: "0777"
+`,
+		Passing: `- hosts: all
+  tasks:
+    - name: Create directory
+      file:
+        path: /srv/app
+        state: directory
+        mode: "0755"
+`,
+	},
+	"puppet": {
+		Match:       Match{ResourceType: "file", Attribute: "mode", Equals: "0777"},
+		Message:     "Files must not be world-writable",
+		FixtureName: "init.pp",
+		Failing: `class example {
+  file { '/srv/app':
+    ensure => directory,
+    mode   => '0777',
+  }
+}
+`,
+		Passing: `class example {
+  file { '/srv/app':
+    ensure => directory,
+    mode   => '0755',
+  }
+}
+`,
+	},
+	"kubernetes": {
+		Match:       Match{ResourceType: "Deployment", Attribute: "spec.template.spec.containers.image", Matches: unpinnedImage},
+		Message:     "Container image {{.Value}} in {{.Type}} '{{.Name}}' must be pinned to a version",
+		FixtureName: "deployment.yaml",
+		Failing:     deploymentFixture("nginx:latest"),
+		Passing:     deploymentFixture("nginx:1.27.2"),
+	},
+	"helm": {
+		Match:       Match{ResourceType: "Deployment", Attribute: "spec.template.spec.containers.image", Matches: unpinnedImage},
+		Message:     "Container image {{.Value}} in {{.Type}} '{{.Name}}' must be pinned to a version",
+		FixtureName: filepath.Join("templates", "deployment.yaml"),
+		Failing:     deploymentFixture("nginx:latest"),
+		Passing:     deploymentFixture("nginx:1.27.2"),
+		Shared: map[string]string{"Chart.yaml": `apiVersion: v2
+name: example
+version: 0.1.0
+appVersion: "1.0.0"
+`},
+	},
+	"kustomize": {
+		Match:       Match{ResourceType: "Deployment", Attribute: "spec.template.spec.containers.image", Matches: unpinnedImage},
+		Message:     "Container image {{.Value}} in {{.Type}} '{{.Name}}' must be pinned to a version",
+		FixtureName: "deployment.yaml",
+		Failing:     deploymentFixture("nginx:latest"),
+		Passing:     deploymentFixture("nginx:1.27.2"),
+		Shared: map[string]string{"kustomization.yaml": `resources:
+  - deployment.yaml
+`},
+	},
+	"pulumi": {
+		Match:       Match{ResourceType: "aws:s3:*", Attribute: "acl", Matches: `^public-read`},
+		Message:     "S3 buckets must not be publicly readable",
+		FixtureName: "Pulumi.yaml",
+		Failing: `name: example
+runtime: yaml
+resources:
+  bucket:
+    type: aws:s3:Bucket
+    properties:
+      acl: public-read
+`,
+		Passing: `name: example
+runtime: yaml
+resources:
+  bucket:
+    type: aws:s3:Bucket
+    properties:
+      acl: private
+`,
+	},
+	"tfplan": {
+		Match:       Match{ResourceType: "aws_s3_bucket", Attribute: "acl", Equals: "public-read"},
+		Message:     "S3 buckets must not be publicly readable",
+		FixtureName: "plan.json",
+		Failing: `{"format_version": "1.2", "terraform_version": "1.9.0", "planned_values": {"root_module": {"resources": [
+  {"address": "aws_s3_bucket.example", "mode": "managed", "type": "aws_s3_bucket", "name": "example", "values": {"bucket": "example", "acl": "public-read"}}
+]}}}
+`,
+		Passing: `{"format_version": "1.2", "terraform_version": "1.9.0", "planned_values": {"root_module": {"resources": [
+  {"address": "aws_s3_bucket.example", "mode": "managed", "type": "aws_s3_bucket", "name": "example", "values": {"bucket": "example", "acl": "private"}}
+]}}}
+`,
+	},
+	"tfstate": {
+		Match:       Match{ResourceType: "aws_db_instance", Attribute: "publicly_accessible", Equals: "true"},
+		Message:     "Databases must not be publicly accessible",
+		FixtureName: "terraform.tfstate",
+		Failing: `{"version": 4, "terraform_version": "1.9.0", "lineage": "example", "resources": [
+  {"mode": "managed", "type": "aws_db_instance", "name": "main", "instances": [{"attributes": {"identifier": "main", "publicly_accessible": true}}]}
+]}
+`,
+		Passing: `{"version": 4, "terraform_version": "1.9.0", "lineage": "example", "resources": [
+  {"mode": "managed", "type": "aws_db_instance", "name": "main", "instances": [{"attributes": {"identifier": "main", "publicly_accessible": false}}]}
+]}
+`,
+	},
+	"cloudformation": {
+		Match:       Match{ResourceType: "AWS::S3::Bucket", Attribute: "AccessControl", Matches: `^PublicRead`},
+		Message:     "S3 buckets must not be publicly readable",
+		FixtureName: "template.json",
+		Failing:     bucketTemplateFixture("PublicRead"),
+		Passing:     bucketTemplateFixture("Private"),
+	},
+	"cdk": {
+		Match:       Match{ResourceType: "AWS::S3::Bucket", Attribute: "AccessControl", Matches: `^PublicRead`},
+		Message:     "S3 buckets must not be publicly readable",
+		FixtureName: "ExampleStack.template.json",
+		Failing:     bucketTemplateFixture("PublicRead"),
+		Passing:     bucketTemplateFixture("Private"),
+	},
+	"serverless": {
+		Match:       Match{ResourceType: "function", Attribute: "timeout", Absent: true},
+		Message:     "Functions must set a timeout",
+		FixtureName: "serverless.yml",
+		Failing: `service: example
+provider:
+  name: aws
+  runtime: nodejs22.x
+functions:
+  hello:
+    handler: handler.hello
+`,
+		Passing: `service: example
+provider:
+  name: aws
+  runtime: nodejs22.x
+functions:
+  hello:
+    handler: handler.hello
+    timeout: 10
+`,
+	},
+	"nomad": {
+		Match:       Match{ResourceType: "task", Attribute: "config.image", Matches: unpinnedImage},
+		Message:     "Container image {{.Value}} of task '{{.Name}}' must be pinned to a version",
+		FixtureName: "example.nomad.hcl",
+		Failing: `job "example" {
+  group "web" {
+    task "web" {
+      driver = "docker"
+      config {
+        image = "nginx:latest"
+      }
+    }
+  }
+}
+`,
+		Passing: `job "example" {
+  group "web" {
+    task "web" {
+      driver = "docker"
+      config {
+        image = "nginx:1.27.2"
+      }
+    }
+  }
+}
+`,
+	},
+	"dockerfile": {
+		Match:       Match{ResourceType: "FROM", Attribute: "args", Matches: `^[^:@\s]+(\s|$)|:latest(\s|$)`},
+		Message:     "Base image {{.Value}} must be pinned to a version",
+		FixtureName: "Dockerfile",
+		Failing: `FROM nginx:latest
+USER nginx
+`,
+		Passing: `FROM nginx:1.27.2
+USER nginx
+`,
+	},
+}
+
+// Scaffold creates a starter rule file for scanner with the given id in dir,
+// passing and failing fixture directories, and a matching entry in tests.yaml.
+// It returns the paths it created. Existing rules are never overwritten.
+func Scaffold(dir, scanner, id string) ([]string, error) {
+	tmpl, ok := scaffoldTemplates[scanner]
+	if !ok {
+		return nil, fmt.Errorf("unknown scanner '%s'", scanner)
+	}
+
+	r := Rule{
+		ID:       id,
+		Scanner:  scanner,
+		Severity: "WARN",
+		Message:  tmpl.Message,
+		Match:    tmpl.Match,
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+
+	existing, err := Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range existing {
+		if e.ID == id {
+			return nil, fmt.Errorf("rule %s already exists in %s", id, e.File)
+		}
+	}
+	rulePath := filepath.Join(dir, id+".yaml")
+	if _, err := os.Stat(rulePath); err == nil {
+		return nil, fmt.Errorf("%s already exists", rulePath)
+	}
+
+	test := Test{
+		Rule: id,
+		Pass: filepath.Join("tests", id, "pass"),
+		Fail: filepath.Join("tests", id, "fail"),
+	}
+
+	ruleData, err := yaml.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	type file struct {
+		path    string
+		content []byte
+	}
+	files := []file{
+		{rulePath, ruleData},
+		{filepath.Join(dir, test.Pass, tmpl.FixtureName), []byte(tmpl.Passing)},
+		{filepath.Join(dir, test.Fail, tmpl.FixtureName), []byte(tmpl.Failing)},
+	}
+	shared := make([]string, 0, len(tmpl.Shared))
+	for name := range tmpl.Shared {
+		shared = append(shared, name)
+	}
+	sort.Strings(shared)
+	for _, name := range shared {
+		for _, fixture := range []string{test.Pass, test.Fail} {
+			files = append(files, file{filepath.Join(dir, fixture, name), []byte(tmpl.Shared[name])})
+		}
+	}
+
+	var created []string
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+			return created, err
+		}
+		if err := os.WriteFile(f.path, f.content, 0o644); err != nil {
+			return created, err
+		}
+		created = append(created, f.path)
+	}
+
+	testsPath, err := appendTest(dir, test)
+	if err != nil {
+		return created, err
+	}
+	return append(created, testsPath), nil
+}
+
+// appendTest adds t to tests.yaml in dir, creating the file if needed.
+func appendTest(dir string, t Test) (string, error) {
+	p := filepath.Join(dir, TestsFile)
+	var tf testsFile
+	if data, err := os.ReadFile(p); err == nil {
+		if err := yaml.Unmarshal(data, &tf); err != nil {
+			return p, fmt.Errorf("%s: %v", p, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return p, err
+	}
+
+	tf.Tests = append(tf.Tests, t)
+	data, err := yaml.Marshal(tf)
+	if err != nil {
+		return p, err
+	}
+	return p, os.WriteFile(p, data, 0o644)
+}
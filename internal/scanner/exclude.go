@@ -0,0 +1,61 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/salchaD-27/infra-check/internal/glob"
+)
+
+var (
+	excludeMu sync.RWMutex
+	excludes  []string
+)
+
+// SetExcludes sets the path globs that Walk skips in every scanner, e.g. "**/examples/**"
+// or ".terraform/**". A pattern matches below any directory unless it starts with "/",
+// which anchors it to the working directory, or to the directory mounted (see Mount).
+func SetExcludes(patterns []string) {
+	excludeMu.Lock()
+	defer excludeMu.Unlock()
+	excludes = append([]string(nil), patterns...)
+}
+
+// Excluded reports whether path, a directory when dir is set, matches the patterns
+// given to SetExcludes.
+func Excluded(path string, dir bool) bool {
+	excludeMu.RLock()
+	defer excludeMu.RUnlock()
+	return MatchExcludes(excludes, path, dir)
+}
+
+// MatchExcludes reports whether path, a directory when dir is set, matches one of the
+// exclude patterns. A directory matches when the pattern covers it or everything below
+// it, so "vendor/**" excludes the vendor directory itself.
+func MatchExcludes(patterns []string, path string, dir bool) bool {
+	if len(patterns) == 0 {
+		return false
+	}
+	if _, rel, ok := mountOf(path); ok {
+		path = rel // patterns apply within an unpacked archive or clone as within a checkout
+	} else if filepath.IsAbs(path) {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, path); err == nil {
+				path = rel
+			}
+		}
+	}
+	for _, p := range patterns {
+		if strings.HasPrefix(p, "/") {
+			p = strings.TrimPrefix(p, "/")
+		} else if !strings.HasPrefix(p, "**/") {
+			p = "**/" + strings.TrimPrefix(p, "./")
+		}
+		if glob.Match(p, path) || (dir && glob.MatchDir(p, path)) {
+			return true
+		}
+	}
+	return false
+}
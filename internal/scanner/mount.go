@@ -0,0 +1,117 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// mount is a directory scanned in place of what it was unpacked or cloned from, whose
+// files are reported under name: below it, or relative to dir when name is "".
+type mount struct {
+	dir, name string
+}
+
+var (
+	mountMu sync.RWMutex
+	mounts  []mount
+)
+
+// Mount reports the files under dir, such as the members of an archive unpacked into a
+// temporary directory, as files under name, or relative to dir when name is "", until the
+// returned function is called. Reported paths are read back from dir (see Source) and
+// matched against the exclude patterns as paths within it.
+func Mount(dir, name string) (unmount func()) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+	m := mount{dir: filepath.Clean(abs), name: filepath.Clean(name)}
+	if name == "" {
+		m.name = ""
+	}
+	mountMu.Lock()
+	mounts = append(mounts, m)
+	mountMu.Unlock()
+	return func() {
+		mountMu.Lock()
+		defer mountMu.Unlock()
+		for i, other := range mounts {
+			if other == m {
+				mounts = append(mounts[:i], mounts[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Reported returns the path the file p is reported under: its path under the name of the
+// mounted directory it is in, or p itself outside mounted directories.
+func Reported(p string) string {
+	if m, rel, ok := mountOf(p); ok {
+		if m.name == "" {
+			return rel
+		}
+		return filepath.Join(m.name, rel)
+	}
+	return p
+}
+
+// ReportedText replaces the mounted directories named in s, such as a message naming the
+// file of a module call, with the names their files are reported under.
+func ReportedText(s string) string {
+	mountMu.RLock()
+	defer mountMu.RUnlock()
+	for _, m := range mounts {
+		if m.name == "" {
+			s = strings.ReplaceAll(strings.ReplaceAll(s, m.dir+string(filepath.Separator), ""), m.dir, ".")
+		} else {
+			s = strings.ReplaceAll(s, m.dir, m.name)
+		}
+	}
+	return s
+}
+
+// Source returns the file a path from Reported is read from, or p when it is not below
+// the name of a mounted directory.
+func Source(p string) string {
+	mountMu.RLock()
+	defer mountMu.RUnlock()
+	if filepath.IsAbs(p) {
+		return p
+	}
+	clean := filepath.Clean(p)
+	for _, m := range mounts {
+		switch {
+		case m.name == "":
+			return filepath.Join(m.dir, clean)
+		case clean == m.name:
+			return m.dir
+		case strings.HasPrefix(clean, m.name+string(filepath.Separator)):
+			return filepath.Join(m.dir, strings.TrimPrefix(clean, m.name+string(filepath.Separator)))
+		}
+	}
+	return p
+}
+
+// ReadFile reads the file a path from Reported names, see Source.
+func ReadFile(p string) ([]byte, error) {
+	return os.ReadFile(Source(p))
+}
+
+// mountOf returns the mounted directory p is in and the path of p within it.
+func mountOf(p string) (mount, string, bool) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return mount{}, "", false
+	}
+	mountMu.RLock()
+	defer mountMu.RUnlock()
+	for _, m := range mounts {
+		if rel, err := filepath.Rel(m.dir, abs); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return m, rel, true
+		}
+	}
+	return mount{}, "", false
+}
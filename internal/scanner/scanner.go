@@ -0,0 +1,185 @@
+// pluggable scanner interface and the registry commands and merged reports iterate over
+package scanner
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// FileMatcher reports whether a scanner reads the file at path.
+type FileMatcher func(path string) bool
+
+// Scanner is one IaC tool. Implementations register themselves from an init function
+// so commands, auto-detection and merged reports pick them up without further wiring.
+type Scanner interface {
+	// Name is the tool name used for `scan <name>`, rule targeting and reports.
+	Name() string
+	// FileMatcher selects the files the scanner reads.
+	FileMatcher() FileMatcher
+	// Scan analyses a file or directory.
+	Scan(path string) ([]finding.Finding, error)
+}
+
+// TargetDetector is implemented by scanners that pick their own scan targets within a
+// tree, e.g. only the YAML files that are playbooks. Without it a scanner is run once on
+// the root when any file under it matches.
+type TargetDetector interface {
+	// Targets returns the files or directories to scan, given the matching files under root.
+	Targets(root string, files []string) []string
+}
+
+// Linker is implemented by scanners whose findings in one directory depend on files in
+// others, as Terraform names the module calls leading to a module. A changed-files scan
+// rescans root as a whole when Linked reports that changed, the changed files under root
+// the scanner reads, may touch such links.
+type Linker interface {
+	Linked(root string, changed []string) bool
+}
+
+// Overlay is implemented by scanners reading the files of every other scanner rather than a
+// file type of their own, such as the credential patterns. Detecting the type of a single
+// file skips them; commands scanning one file run them alongside the scanner detected.
+type Overlay interface {
+	Overlay() bool
+}
+
+// Emit receives the findings of a scan as they are found.
+type Emit func(finding.Finding)
+
+// Streamer is implemented by scanners that emit each finding as soon as they find it, e.g.
+// after every file, rather than returning all of them once the whole tree is scanned.
+type Streamer interface {
+	ScanStream(path string, emit Emit) error
+}
+
+// Stream scans path with s, passing every finding to emit: as it is found when s is a
+// Streamer, or once Scan returns otherwise.
+func Stream(s Scanner, path string, emit Emit) error {
+	if st, ok := s.(Streamer); ok {
+		return st.ScanStream(path, emit)
+	}
+	findings, err := s.Scan(path)
+	for _, f := range findings {
+		emit(f)
+	}
+	return err
+}
+
+// Collect runs a streaming scan of path and returns its findings, for a Scan built on it.
+func Collect(path string, stream func(path string, emit Emit) error) ([]finding.Finding, error) {
+	var findings []finding.Finding
+	err := stream(path, func(f finding.Finding) { findings = append(findings, f) })
+	return findings, err
+}
+
+// Describer is implemented by scanners that provide a one-line help text for their scan command.
+type Describer interface {
+	Description() string
+}
+
+var (
+	mu       sync.RWMutex
+	registry []Scanner
+)
+
+// Register adds s to the registry. Registering two scanners with the same name panics.
+func Register(s Scanner) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, existing := range registry {
+		if existing.Name() == s.Name() {
+			panic(fmt.Sprintf("scanner %q registered twice", s.Name()))
+		}
+	}
+	registry = append(registry, s)
+}
+
+// All returns the registered scanners in registration order.
+func All() []Scanner {
+	mu.RLock()
+	defer mu.RUnlock()
+	return append([]Scanner(nil), registry...)
+}
+
+// Lookup returns the scanner registered under name.
+func Lookup(name string) (Scanner, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, s := range registry {
+		if s.Name() == name {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+// Names returns the registered scanner names in registration order.
+func Names() []string {
+	var names []string
+	for _, s := range All() {
+		names = append(names, s.Name())
+	}
+	return names
+}
+
+// Targets returns what s should scan given the files under root it matches.
+func Targets(s Scanner, root string, files []string) []string {
+	if len(files) == 0 {
+		return nil
+	}
+	if d, ok := s.(TargetDetector); ok {
+		return d.Targets(root, files)
+	}
+	return []string{root}
+}
+
+// Simple adapts a scan function and file matcher to Scanner. A scanner that streams sets
+// StreamFunc instead of ScanFunc.
+type Simple struct {
+	ID         string
+	Summary    string
+	Matcher    FileMatcher
+	ScanFunc   func(path string) ([]finding.Finding, error)
+	StreamFunc func(path string, emit Emit) error
+	// Overlays marks a scanner reading the files of the other scanners, see Overlay.
+	Overlays bool
+}
+
+func (s Simple) Name() string             { return s.ID }
+func (s Simple) Description() string      { return s.Summary }
+func (s Simple) FileMatcher() FileMatcher { return s.Matcher }
+func (s Simple) Overlay() bool            { return s.Overlays }
+
+func (s Simple) Scan(path string) ([]finding.Finding, error) {
+	if s.StreamFunc != nil {
+		return Collect(path, s.StreamFunc)
+	}
+	return s.ScanFunc(path)
+}
+
+func (s Simple) ScanStream(path string, emit Emit) error {
+	if s.StreamFunc != nil {
+		return s.StreamFunc(path, emit)
+	}
+	findings, err := s.ScanFunc(path)
+	for _, f := range findings {
+		emit(f)
+	}
+	return err
+}
+
+// Extensions returns a FileMatcher for files with any of the given extensions.
+func Extensions(exts ...string) FileMatcher {
+	return func(p string) bool {
+		for _, ext := range exts {
+			if strings.EqualFold(filepath.Ext(p), ext) {
+				return true
+			}
+		}
+		return false
+	}
+}
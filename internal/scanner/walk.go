@@ -0,0 +1,223 @@
+package scanner
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// WalkOptions control how Walk descends into directories.
+type WalkOptions struct {
+	// FollowSymlinks descends into symlinked directories and reports symlinked files with
+	// the information of their targets. Each directory and file is visited once, under the
+	// first path reaching it, which also breaks symlink cycles.
+	FollowSymlinks bool
+
+	// MaxDepth limits how many directory levels below the root are read: 1 reads only the
+	// entries of the root itself. Zero or less is unlimited.
+	MaxDepth int
+
+	// MaxFileSize skips files larger than this many bytes, such as state backups or
+	// archives; zero or less is unlimited.
+	MaxFileSize int64
+
+	// SkipBinary skips files whose first sniffLen bytes hold a NUL byte, which no
+	// configuration format contains.
+	SkipBinary bool
+
+	// Skipped, when set, is called with every file skipped for its size or content and why.
+	Skipped func(path, reason string)
+}
+
+// DefaultMaxFileSize is the MaxFileSize of a Walk without SetWalkOptions.
+const DefaultMaxFileSize = 64 << 20
+
+// sniffLen is how much of a file is read to tell binary content apart.
+const sniffLen = 8000
+
+var (
+	walkMu   sync.RWMutex
+	walkOpts = WalkOptions{MaxFileSize: DefaultMaxFileSize, SkipBinary: true}
+)
+
+// binary caches whether files are binary across the walks of one scan, which every scanner
+// makes over the same tree; entries are keyed by path, size and modification time.
+var binary sync.Map
+
+// SetWalkOptions sets the options of every Walk, as SetExcludes sets its excludes.
+func SetWalkOptions(opts WalkOptions) {
+	walkMu.Lock()
+	defer walkMu.Unlock()
+	walkOpts = opts
+}
+
+// Walk is filepath.Walk without the excluded paths: fn is not called for excluded files
+// and excluded directories are not descended into. It honours the options given to
+// SetWalkOptions; without any it does not follow symlinks, like filepath.Walk.
+func Walk(root string, fn filepath.WalkFunc) error {
+	walkMu.RLock()
+	opts := walkOpts
+	walkMu.RUnlock()
+
+	if !opts.FollowSymlinks && opts.MaxDepth <= 0 {
+		return filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+			if err == nil && Excluded(p, info.IsDir()) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if err == nil && !info.IsDir() && opts.skip(p, info) {
+				return nil
+			}
+			return fn(p, info, err)
+		})
+	}
+
+	w := &walker{opts: opts, fn: fn, visited: make(map[string]bool)}
+	info, err := os.Lstat(root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = w.walk(root, info, 0)
+	}
+	if err == filepath.SkipDir || err == filepath.SkipAll {
+		return nil
+	}
+	return err
+}
+
+// walker is one Walk with options, remembering what it visited when following symlinks.
+type walker struct {
+	opts    WalkOptions
+	fn      filepath.WalkFunc
+	visited map[string]bool
+}
+
+// walk visits path, depth levels below the root, and what is below it, with the SkipDir
+// and SkipAll semantics of filepath.Walk: it returns SkipDir only when fn does for a file.
+func (w *walker) walk(path string, info os.FileInfo, depth int) error {
+	if w.opts.FollowSymlinks {
+		if info.Mode()&os.ModeSymlink != 0 {
+			// a dangling link is reported as the link itself
+			if target, err := os.Stat(path); err == nil {
+				info = target
+			}
+		}
+		if real, err := filepath.EvalSymlinks(path); err == nil {
+			if w.visited[real] {
+				return nil
+			}
+			w.visited[real] = true
+		}
+	}
+	if Excluded(path, info.IsDir()) {
+		return nil
+	}
+	if !info.IsDir() {
+		if w.opts.skip(path, info) {
+			return nil
+		}
+		return w.fn(path, info, nil)
+	}
+
+	if err := w.fn(path, info, nil); err != nil {
+		if err == filepath.SkipDir {
+			return nil
+		}
+		return err
+	}
+	if w.opts.MaxDepth > 0 && depth >= w.opts.MaxDepth {
+		return nil
+	}
+	names, err := readDirNames(path)
+	if err != nil {
+		if err := w.fn(path, info, err); err != nil {
+			if err == filepath.SkipDir {
+				return nil
+			}
+			return err
+		}
+	}
+	for _, name := range names {
+		p := filepath.Join(path, name)
+		entry, err := os.Lstat(p)
+		if err != nil {
+			if err := w.fn(p, entry, err); err != nil && err != filepath.SkipDir {
+				return err
+			}
+			continue
+		}
+		if err := w.walk(p, entry, depth+1); err != nil {
+			// only a file passes SkipDir on, which skips the rest of its directory
+			if err == filepath.SkipDir {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// readDirNames returns the sorted names of the entries of dir.
+func readDirNames(dir string) ([]string, error) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	names, err := f.Readdirnames(-1)
+	f.Close()
+	sort.Strings(names)
+	return names, err
+}
+
+// skip reports whether the file at path is left out for its size or binary content,
+// telling Skipped about it.
+func (o WalkOptions) skip(path string, info os.FileInfo) bool {
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Stat(path)
+		if err != nil || target.IsDir() {
+			return false
+		}
+		info = target
+	}
+	if !info.Mode().IsRegular() {
+		return false
+	}
+	reason := ""
+	switch {
+	case o.MaxFileSize > 0 && info.Size() > o.MaxFileSize:
+		reason = fmt.Sprintf("%d bytes, over the %d byte limit", info.Size(), o.MaxFileSize)
+	case o.SkipBinary && isBinary(path, info):
+		reason = "binary content"
+	default:
+		return false
+	}
+	if o.Skipped != nil {
+		o.Skipped(path, reason)
+	}
+	return true
+}
+
+// isBinary reports whether the start of the file at path holds a NUL byte. Unreadable files
+// are not binary, so the scanner reading them reports why.
+func isBinary(path string, info os.FileInfo) bool {
+	key := fmt.Sprintf("%s\x00%d\x00%d", path, info.Size(), info.ModTime().UnixNano())
+	if v, ok := binary.Load(key); ok {
+		return v.(bool)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	buf := make([]byte, sniffLen)
+	n, _ := io.ReadFull(f, buf)
+	bin := bytes.IndexByte(buf[:n], 0) >= 0
+	binary.Store(key, bin)
+	return bin
+}
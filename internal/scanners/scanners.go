@@ -0,0 +1,20 @@
+// Package scanners links every built-in scanner into the binary. Each imported
+// package registers itself with the scanner registry from its init function;
+// adding a tool means adding its import here.
+package scanners
+
+import (
+	_ "github.com/salchaD-27/infra-check/internal/ansible"
+	_ "github.com/salchaD-27/infra-check/internal/cdk"
+	_ "github.com/salchaD-27/infra-check/internal/cloudformation"
+	_ "github.com/salchaD-27/infra-check/internal/dockerfile"
+	_ "github.com/salchaD-27/infra-check/internal/helm"
+	_ "github.com/salchaD-27/infra-check/internal/kubernetes"
+	_ "github.com/salchaD-27/infra-check/internal/kustomize"
+	_ "github.com/salchaD-27/infra-check/internal/nomad"
+	_ "github.com/salchaD-27/infra-check/internal/pulumi"
+	_ "github.com/salchaD-27/infra-check/internal/puppet"
+	_ "github.com/salchaD-27/infra-check/internal/secrets"
+	_ "github.com/salchaD-27/infra-check/internal/serverless"
+	_ "github.com/salchaD-27/infra-check/internal/terraform"
+)
@@ -0,0 +1,13 @@
+package secrets
+
+import "github.com/salchaD-27/infra-check/internal/scanner"
+
+func init() {
+	scanner.Register(scanner.Simple{
+		ID:         Name,
+		Summary:    "Detect well-known credential formats in all scanned file types",
+		Matcher:    scannedFile,
+		StreamFunc: ScanStream,
+		Overlays:   true,
+	})
+}
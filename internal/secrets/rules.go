@@ -0,0 +1,38 @@
+package secrets
+
+import (
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/registry"
+)
+
+// remediation applies to every committed credential, whatever issued it.
+const remediation = `# revoke or rotate the credential first, then purge it from the history
+git filter-repo --replace-text <(echo '<credential>==>REDACTED')`
+
+// Every credential pattern is a rule of its own, identified by the ID its findings carry.
+func init() {
+	for _, pat := range Patterns {
+		registry.Register(registry.Rule{
+			ID:          pat.ID,
+			Name:        "Committed " + pat.Description,
+			Description: "A file contains what looks like a " + pat.Description + ". Revoke it, remove it from the history and load it from a secrets manager instead.",
+			Severity:    pat.Severity,
+			Category:    "secrets",
+			Compliance:  registry.HardcodedSecrets,
+			Scanners:    []string{"secrets"},
+			Remediation: remediation,
+		})
+	}
+	registry.Register(registry.Rule{
+		ID:          gcpServiceAccountID,
+		Name:        "Committed GCP service account key",
+		Description: "A GCP service account key file with its private key is committed. Delete the key in IAM and use workload identity or a secrets manager instead.",
+		Severity:    finding.Error,
+		Category:    "secrets",
+		Compliance:  registry.HardcodedSecrets,
+		Scanners:    []string{"secrets"},
+		Remediation: `# delete the key first, then purge the file from the history
+gcloud iam service-accounts keys delete <key-id> --iam-account <account>
+git filter-repo --invert-paths --path <file>`,
+	})
+}
@@ -0,0 +1,118 @@
+// Package secrets detects well-known credential formats in every file type the other
+// scanners read.
+package secrets
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// Name is the scanner's registered name.
+const Name = "secrets"
+
+// Files larger than this are skipped; credentials live in configuration, not data dumps.
+const maxFileSize = 2 << 20
+
+// scannedFile reports whether any other registered scanner reads p.
+func scannedFile(p string) bool {
+	for _, s := range scanner.All() {
+		if s.Name() != Name && s.FileMatcher()(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Scan checks the files under path that any scanner reads against the credential patterns.
+// An explicitly given file is always checked.
+func Scan(path string) ([]finding.Finding, error) {
+	return scanner.Collect(path, ScanStream)
+}
+
+// ScanStream is Scan passing the matches of every file to emit as soon as the file is checked.
+func ScanStream(path string, emit scanner.Emit) error {
+	return scanner.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if p != path && (info.Name() == ".git" || info.Name() == ".terraform") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if p != path && !scannedFile(p) || info.Size() > maxFileSize {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			emit(finding.Finding{
+				File:     p,
+				Severity: finding.Error,
+				Message:  fmt.Sprintf("Failed to read file: %v", err),
+			})
+			return nil
+		}
+		for _, f := range Check(p, data) {
+			emit(f)
+		}
+		return nil
+	})
+}
+
+// Check matches the content of one file against the pattern library.
+func Check(p string, data []byte) []finding.Finding {
+	if bytes.IndexByte(data, 0) >= 0 {
+		return nil // binary
+	}
+	var findings []finding.Finding
+	add := func(id string, sev finding.Severity, line int, format string, args ...interface{}) {
+		findings = append(findings, finding.Finding{
+			File:     p,
+			Severity: sev,
+			Message:  fmt.Sprintf("[%s] ", id) + fmt.Sprintf(format, args...),
+			Line:     line,
+		})
+	}
+
+	serviceAccount := serviceAccountTypeRegex.Match(data) && serviceAccountKeyRegex.Match(data)
+	lines := bufio.NewScanner(bytes.NewReader(data))
+	lines.Buffer(make([]byte, 0, 64*1024), maxFileSize)
+	for n := 1; lines.Scan(); n++ {
+		text := lines.Text()
+		if serviceAccount && serviceAccountTypeRegex.MatchString(text) {
+			add(gcpServiceAccountID, finding.Error, n, "GCP service account key file with an embedded private key is committed; use workload identity or a secrets manager instead")
+		}
+		for _, pat := range Patterns {
+			if serviceAccount && pat.ID == "private-key" {
+				continue // reported once for the whole key file
+			}
+			for _, m := range pat.Regex.FindAllStringSubmatch(text, -1) {
+				if pat.Group == 0 {
+					add(pat.ID, pat.Severity, n, "Possible %s found", pat.Description)
+					continue
+				}
+				secret := m[pat.Group]
+				if exampleRegex.MatchString(secret) {
+					continue
+				}
+				add(pat.ID, pat.Severity, n, "Possible %s found: %s", pat.Description, redact(secret))
+			}
+		}
+	}
+	return findings
+}
+
+// redact keeps enough of a secret to recognise it in a report without leaking it.
+func redact(secret string) string {
+	if len(secret) <= 12 {
+		return "****"
+	}
+	return secret[:8] + "****"
+}
@@ -0,0 +1,337 @@
+// HTTP scan service, so teams can submit code to one shared infra-check instead of installing the CLI
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/salchaD-27/infra-check/internal/archive"
+	"github.com/salchaD-27/infra-check/internal/config"
+	"github.com/salchaD-27/infra-check/internal/engine"
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/gitrepo"
+	"github.com/salchaD-27/infra-check/internal/report"
+	"github.com/salchaD-27/infra-check/internal/rules"
+	"github.com/salchaD-27/infra-check/pkg/schema"
+)
+
+// Job states reported by GET /results/{id}
+const (
+	Queued  = "queued"
+	Running = "running"
+	Done    = "done"
+	Failed  = "failed"
+)
+
+// Options configures a Server. Zero values select the defaults noted on each field.
+type Options struct {
+	Rules     []rules.Rule
+	Config    *config.Config // applied to every scan's findings; none when nil
+	MaxUpload int64          // request body limit in bytes; 64 MiB when zero
+	Workers   int            // scans run at once; 2 when zero
+	Timeout   time.Duration  // per scan, including the clone; 5 minutes when zero
+	Retention time.Duration  // how long finished results are kept; 1 hour when zero
+}
+
+// Job is a submitted scan as returned by the API. Result holds the same envelope as
+// 'scan all --format json' once the scan is done.
+type Job struct {
+	ID       string         `json:"id"`
+	Status   string         `json:"status"`
+	Source   string         `json:"source"`
+	Error    string         `json:"error,omitempty"`
+	Created  time.Time      `json:"created"`
+	Finished *time.Time     `json:"finished,omitempty"`
+	Result   *report.Result `json:"result,omitempty"`
+
+	repo, ref string // what to clone, for repository scans
+	upload    string // where the archive was unpacked, for uploads
+}
+
+// Server runs submitted scans on a fixed number of workers and keeps their results
+// in memory for Options.Retention.
+type Server struct {
+	opts  Options
+	queue chan *Job
+
+	mu   sync.Mutex
+	jobs map[string]*Job
+}
+
+// New returns a server with its workers started; they stop when ctx is done.
+func New(ctx context.Context, opts Options) *Server {
+	if opts.Config == nil {
+		opts.Config = &config.Config{}
+	}
+	if opts.MaxUpload <= 0 {
+		opts.MaxUpload = 64 << 20
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = 2
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Minute
+	}
+	if opts.Retention <= 0 {
+		opts.Retention = time.Hour
+	}
+	s := &Server{opts: opts, queue: make(chan *Job, 100), jobs: make(map[string]*Job)}
+	for i := 0; i < opts.Workers; i++ {
+		go s.work(ctx)
+	}
+	return s
+}
+
+// Handler serves the API:
+//
+//	POST /scan           a tar or tar.gz archive as the body, or {"repo": url, "ref": branch-or-tag} as JSON
+//	GET  /results/{id}   the job, with its result once done
+//	GET  /healthz        liveness
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /scan", s.submit)
+	mux.HandleFunc("GET /results/{id}", s.result)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "schemaVersion": schema.Version})
+	})
+	return mux
+}
+
+func (s *Server) submit(w http.ResponseWriter, r *http.Request) {
+	body := http.MaxBytesReader(w, r.Body, s.opts.MaxUpload)
+	job := &Job{Status: Queued, Created: time.Now().UTC()}
+
+	if mediaType(r) == "application/json" {
+		var req struct {
+			Repo string `json:"repo"`
+			Ref  string `json:"ref"`
+		}
+		if err := json.NewDecoder(body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid JSON body: %v", err))
+			return
+		}
+		if req.Repo == "" {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("'repo' is required"))
+			return
+		}
+		if err := gitrepo.Check(req.Repo, req.Ref); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		job.Source = req.Repo
+		if req.Ref != "" {
+			job.Source += "@" + req.Ref
+		}
+		job.repo, job.ref = req.Repo, req.Ref
+	} else {
+		// read the archive now: the request body is gone once the handler returns
+		staging, err := os.MkdirTemp("", "infra-check-upload-")
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if err := archive.ExtractTar(body, staging, 8*s.opts.MaxUpload); err != nil {
+			os.RemoveAll(staging)
+			status := http.StatusBadRequest
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			writeError(w, status, err)
+			return
+		}
+		job.Source, job.upload = "upload", staging
+	}
+
+	id, err := newID()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	job.ID = id
+
+	s.mu.Lock()
+	s.expire()
+	s.jobs[id] = job
+	s.mu.Unlock()
+
+	select {
+	case s.queue <- job:
+	default:
+		s.mu.Lock()
+		delete(s.jobs, id)
+		s.mu.Unlock()
+		if job.upload != "" {
+			os.RemoveAll(job.upload)
+		}
+		writeError(w, http.StatusServiceUnavailable, fmt.Errorf("too many scans queued; retry later"))
+		return
+	}
+
+	w.Header().Set("Location", "/results/"+id)
+	writeJSON(w, http.StatusAccepted, s.snapshot(job))
+}
+
+func (s *Server) result(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	job, ok := s.jobs[r.PathValue("id")]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no scan with id '%s'", r.PathValue("id")))
+		return
+	}
+	writeJSON(w, http.StatusOK, s.snapshot(job))
+}
+
+func (s *Server) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-s.queue:
+			s.update(job, func(j *Job) { j.Status = Running })
+			result, err := s.scan(ctx, job)
+			s.update(job, func(j *Job) {
+				now := time.Now().UTC()
+				j.Finished = &now
+				if err != nil {
+					j.Status, j.Error = Failed, err.Error()
+					return
+				}
+				j.Status, j.Result = Done, result
+			})
+		}
+	}
+}
+
+// scan fetches the job's code into a temporary directory and scans it like 'scan all'.
+func (s *Server) scan(ctx context.Context, job *Job) (*report.Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.opts.Timeout)
+	defer cancel()
+
+	tmp, err := os.MkdirTemp("", "infra-check-scan-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	root := filepath.Join(tmp, "src")
+	if job.upload != "" {
+		err = os.Rename(job.upload, root)
+		if err != nil {
+			os.RemoveAll(job.upload)
+		}
+	} else {
+		err = gitrepo.Clone(ctx, job.repo, job.ref, root)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	started := time.Now()
+	results, err := engine.RunAll(root, s.opts.Rules)
+	if err != nil {
+		return nil, err
+	}
+	var tools []string
+	for _, r := range results {
+		tools = append(tools, r.Tool)
+	}
+	files, err := engine.FilesRead(root, nil, tools...)
+	if err != nil {
+		return nil, err
+	}
+	// report paths relative to the submitted tree, which is also what path classifiers match
+	for i := range results {
+		results[i].Findings = relativeTo(engine.Suppress(results[i].Findings), root)
+		results[i].Targets = relativePaths(results[i].Targets, root)
+	}
+	findings := engine.Fingerprint(engine.Process(engine.Dedupe(engine.Merge(results)), s.opts.Config), root)
+	if findings == nil {
+		findings = []finding.Finding{}
+	}
+	var sections []report.Section
+	if len(results) > 0 {
+		sections = []report.Section{engine.SummarySection(results, s.opts.Config)}
+	}
+	summary := report.Summarize(findings, files, time.Since(started))
+	return &report.Result{SchemaVersion: schema.Version, Findings: findings, Sections: sections, Summary: &summary}, nil
+}
+
+func (s *Server) update(job *Job, change func(*Job)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	change(job)
+}
+
+// snapshot copies job under the lock so it can be encoded while a worker updates it.
+func (s *Server) snapshot(job *Job) Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *job
+}
+
+// expire drops finished jobs older than the retention period. The caller holds s.mu.
+func (s *Server) expire() {
+	for id, j := range s.jobs {
+		if j.Finished != nil && time.Since(*j.Finished) > s.opts.Retention {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+func relativeTo(findings []finding.Finding, root string) []finding.Finding {
+	for i, f := range findings {
+		if rel, err := filepath.Rel(root, f.File); err == nil && !strings.HasPrefix(rel, "..") {
+			findings[i].File = filepath.ToSlash(rel)
+		}
+		msg := strings.ReplaceAll(f.Message, root+string(filepath.Separator), "")
+		findings[i].Message = strings.ReplaceAll(msg, root, ".")
+	}
+	return findings
+}
+
+func relativePaths(paths []string, root string) []string {
+	out := make([]string, len(paths))
+	for i, p := range paths {
+		out[i] = p
+		if rel, err := filepath.Rel(root, p); err == nil {
+			out[i] = filepath.ToSlash(rel)
+		}
+	}
+	return out
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func mediaType(r *http.Request) string {
+	t, _, _ := strings.Cut(r.Header.Get("Content-Type"), ";")
+	return strings.ToLower(strings.TrimSpace(t))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,12 @@
+package serverless
+
+import "github.com/salchaD-27/infra-check/internal/scanner"
+
+func init() {
+	scanner.Register(scanner.Simple{
+		ID:       "serverless",
+		Summary:  "Scan Serverless Framework service files (serverless.yml) in the specified directories or files",
+		Matcher:  IsConfig,
+		ScanFunc: Scan,
+	})
+}
@@ -0,0 +1,73 @@
+package serverless
+
+import (
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/registry"
+)
+
+func init() {
+	scanners := []string{"serverless"}
+	registry.Register(
+		registry.Rule{
+			ID: "SLS001", Name: "IAM administrator statement", Severity: finding.Error, Category: "iam", Scanners: scanners,
+			Compliance:  registry.LeastPrivilege,
+			Description: "An IAM statement of the functions' role allows every action on every resource.",
+			Messages:    []string{`IAM statement in * allows Action "*" on Resource "*" (full administrator access)`},
+			Remediation: `iam:
+  role:
+    statements:
+      - Effect: Allow
+        Action: dynamodb:GetItem
+        Resource: !GetAtt JobsTable.Arn`,
+		},
+		registry.Rule{
+			ID: "SLS002", Name: "IAM wildcard action", Severity: finding.Error, Category: "iam", Scanners: scanners,
+			Compliance:  registry.LeastPrivilege,
+			Description: "An IAM statement of the functions' role allows Action \"*\" or every action of a service.",
+			Messages: []string{
+				`IAM statement in * allows Action "*"; grant only the actions the functions need`,
+				`IAM statement in * allows *; grant only the actions the functions need`,
+				`IAM statement in * allows * on Resource "*"`,
+			},
+			Remediation: `Action:
+  - s3:GetObject
+  - s3:PutObject`,
+		},
+		registry.Rule{
+			ID: "SLS003", Name: "IAM statement on all resources", Severity: finding.Warning, Category: "iam", Scanners: scanners,
+			Compliance:  registry.LeastPrivilege,
+			Description: "An IAM statement of the functions' role applies its actions to Resource \"*\".",
+			Messages:    []string{`IAM statement in * allows * on Resource "*"; scope it to the resources the functions use`},
+			Remediation: `Resource:
+  - arn:aws:s3:::acme-uploads/*`,
+		},
+		registry.Rule{
+			ID: "SLS004", Name: "Plaintext secret in environment", Severity: finding.Error, Category: "secrets", Scanners: scanners,
+			Compliance:  registry.HardcodedSecrets,
+			Description: "A provider or function environment variable with a secret-like name holds a literal value.",
+			Messages:    []string{"* environment variable '*' holds a plaintext secret; *"},
+			Remediation: `environment:
+  DB_PASSWORD: ${ssm:/prod/db/password}`,
+		},
+		registry.Rule{
+			ID: "SLS005", Name: "Deprecated Lambda runtime", Severity: finding.Warning, Category: "deprecation", Scanners: scanners,
+			Description: "The functions use a Lambda runtime that AWS no longer patches.",
+			Messages:    []string{"* uses the deprecated Lambda runtime '*', *"},
+			Remediation: `provider:
+  name: aws
+  runtime: nodejs20.x`,
+		},
+		registry.Rule{
+			ID: "SLS006", Name: "Public HTTP endpoint", Severity: finding.Warning, Category: "access", Scanners: scanners,
+			Compliance:  registry.NetworkExposure,
+			Description: "An http or httpApi event has no authorizer and is not private, so anyone can invoke the function.",
+			Messages:    []string{"* * event '*' has no authorizer, so the endpoint is public"},
+			Remediation: `events:
+  - httpApi:
+      path: /orders
+      method: post
+      authorizer:
+        name: jwtAuthorizer`,
+		},
+	)
+}
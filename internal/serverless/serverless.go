@@ -0,0 +1,263 @@
+// static analysis of Serverless Framework service configuration
+package serverless
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// Keywords to detect secrets in environment variable names
+var secretKeywords = []string{"password", "passwd", "secret", "token", "api_key", "apikey", "access_key", "private_key", "credential"}
+
+// Lambda runtimes AWS no longer applies security patches to, with what to move to
+var deprecatedRuntimes = map[string]string{
+	"nodejs":        "nodejs22.x",
+	"nodejs4.3":     "nodejs22.x",
+	"nodejs6.10":    "nodejs22.x",
+	"nodejs8.10":    "nodejs22.x",
+	"nodejs10.x":    "nodejs22.x",
+	"nodejs12.x":    "nodejs22.x",
+	"nodejs14.x":    "nodejs22.x",
+	"nodejs16.x":    "nodejs22.x",
+	"nodejs18.x":    "nodejs22.x",
+	"python2.7":     "python3.12 or later",
+	"python3.6":     "python3.12 or later",
+	"python3.7":     "python3.12 or later",
+	"python3.8":     "python3.12 or later",
+	"ruby2.5":       "ruby3.3",
+	"ruby2.7":       "ruby3.3",
+	"java8":         "java21 (or java8.al2)",
+	"go1.x":         "provided.al2023 with a compiled bootstrap",
+	"provided":      "provided.al2023",
+	"dotnetcore1.0": "dotnet8",
+	"dotnetcore2.0": "dotnet8",
+	"dotnetcore2.1": "dotnet8",
+	"dotnetcore3.1": "dotnet8",
+	"dotnet5.0":     "dotnet8",
+	"dotnet6":       "dotnet8",
+}
+
+// IsConfig reports whether p is a serverless.yml service file.
+func IsConfig(p string) bool {
+	base := filepath.Base(p)
+	return base == "serverless.yml" || base == "serverless.yaml"
+}
+
+// Scan walks path for serverless.yml files and checks each service:
+// - IAM role statements allowing Action "*", service wildcards or Resource "*"
+// - secrets written in plain text into provider or function environment
+// - http and httpApi events without an authorizer, which anyone can invoke
+// - functions on deprecated Lambda runtimes
+func Scan(path string) ([]finding.Finding, error) {
+	var findings []finding.Finding
+
+	err := scanner.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		if !IsConfig(p) {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			findings = append(findings, finding.Finding{
+				File:     p,
+				Severity: finding.Error,
+				Message:  fmt.Sprintf("Failed to read file: %v", err),
+			})
+			return nil
+		}
+		findings = append(findings, Check(p, data)...)
+		return nil
+	})
+
+	return findings, err
+}
+
+// Check runs the checks against the content of one serverless.yml.
+func Check(p string, data []byte) []finding.Finding {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return []finding.Finding{{File: p, Severity: finding.Error, Message: fmt.Sprintf("YAML parse error: %v", err)}}
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	root := doc.Content[0]
+
+	var findings []finding.Finding
+	add := func(sev finding.Severity, line int, format string, args ...interface{}) {
+		findings = append(findings, finding.Finding{File: p, Severity: sev, Message: fmt.Sprintf(format, args...), Line: line})
+	}
+
+	provider := get(root, "provider")
+	if name := scalar(get(provider, "name")); name != "" && name != "aws" {
+		return nil // the checks are about AWS Lambda
+	}
+	checkStatements(add, "provider.iamRoleStatements", get(provider, "iamRoleStatements"))
+	checkStatements(add, "provider.iam.role.statements", get(get(get(provider, "iam"), "role"), "statements"))
+	checkEnvironment(add, "Provider", get(provider, "environment"))
+	checkRuntime(add, "Provider", get(provider, "runtime"))
+
+	functions := get(root, "functions")
+	for i := 0; functions != nil && functions.Kind == yaml.MappingNode && i+1 < len(functions.Content); i += 2 {
+		name, fn := functions.Content[i].Value, functions.Content[i+1]
+		label := fmt.Sprintf("Function '%s'", name)
+		checkStatements(add, "functions."+name+".iamRoleStatements", get(fn, "iamRoleStatements"))
+		checkEnvironment(add, label, get(fn, "environment"))
+		if runtime := get(fn, "runtime"); runtime != nil {
+			checkRuntime(add, label, runtime)
+		}
+		checkEvents(add, label, get(fn, "events"))
+	}
+	return findings
+}
+
+type addFunc func(sev finding.Severity, line int, format string, args ...interface{})
+
+// checkStatements flags IAM statements with wildcard actions or resources.
+func checkStatements(add addFunc, label string, statements *yaml.Node) {
+	if statements == nil || statements.Kind != yaml.SequenceNode {
+		return
+	}
+	for _, st := range statements.Content {
+		if effect := scalar(get(st, "Effect")); effect != "" && !strings.EqualFold(effect, "Allow") {
+			continue
+		}
+		actions, resources := stringList(get(st, "Action")), stringList(get(st, "Resource"))
+		allActions, allResources := contains(actions, "*"), contains(resources, "*")
+		switch {
+		case allActions && allResources:
+			add(finding.Error, st.Line, "IAM statement in %s allows Action \"*\" on Resource \"*\" (full administrator access)", label)
+		case allActions:
+			add(finding.Error, st.Line, "IAM statement in %s allows Action \"*\"; grant only the actions the functions need", label)
+		default:
+			var wild []string
+			for _, a := range actions {
+				if strings.HasSuffix(a, ":*") {
+					wild = append(wild, fmt.Sprintf("\"%s\"", a))
+				}
+			}
+			if len(wild) > 0 && allResources {
+				add(finding.Error, st.Line, "IAM statement in %s allows %s on Resource \"*\"", label, strings.Join(wild, ", "))
+			} else if len(wild) > 0 {
+				add(finding.Warning, st.Line, "IAM statement in %s allows %s; grant only the actions the functions need", label, strings.Join(wild, ", "))
+			} else if allResources && len(actions) > 0 {
+				add(finding.Warning, st.Line, "IAM statement in %s allows %s on Resource \"*\"; scope it to the resources the functions use", label, strings.Join(actions, ", "))
+			}
+		}
+	}
+}
+
+// checkEnvironment flags secret-like variables set to literal values, which are stored in
+// plain text in the repository and the Lambda configuration.
+func checkEnvironment(add addFunc, label string, env *yaml.Node) {
+	if env == nil || env.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(env.Content); i += 2 {
+		key, value := env.Content[i], env.Content[i+1]
+		if looksLikeSecret(key.Value) && isLiteral(value) {
+			add(finding.Error, key.Line, "%s environment variable '%s' holds a plaintext secret; resolve it at deploy time with ${ssm:...} or read it from Secrets Manager at runtime", label, key.Value)
+		}
+	}
+}
+
+func checkRuntime(add addFunc, label string, runtime *yaml.Node) {
+	if replacement, ok := deprecatedRuntimes[scalar(runtime)]; ok {
+		add(finding.Warning, runtime.Line, "%s uses the deprecated Lambda runtime '%s', which gets no security updates; move to %s", label, runtime.Value, replacement)
+	}
+}
+
+// checkEvents flags API Gateway events that have no authorizer and are not private.
+func checkEvents(add addFunc, label string, events *yaml.Node) {
+	if events == nil || events.Kind != yaml.SequenceNode {
+		return
+	}
+	for _, event := range events.Content {
+		for _, typ := range []string{"http", "httpApi"} {
+			e := get(event, typ)
+			if e == nil {
+				continue
+			}
+			endpoint := scalar(e)
+			if e.Kind == yaml.MappingNode {
+				if get(e, "authorizer") != nil || scalar(get(e, "private")) == "true" {
+					continue
+				}
+				endpoint = strings.TrimSpace(strings.ToUpper(scalar(get(e, "method"))) + " " + scalar(get(e, "path")))
+			}
+			add(finding.Warning, e.Line, "%s %s event '%s' has no authorizer, so the endpoint is public", label, typ, endpoint)
+		}
+	}
+}
+
+// get returns the value of key in a mapping node, or nil.
+func get(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func scalar(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return node.Value
+}
+
+// strings_ returns a scalar or a sequence of scalars as a list.
+func stringList(node *yaml.Node) []string {
+	if node == nil {
+		return nil
+	}
+	if node.Kind == yaml.ScalarNode {
+		return []string{node.Value}
+	}
+	var out []string
+	for _, n := range node.Content {
+		if n.Kind == yaml.ScalarNode {
+			out = append(out, n.Value)
+		}
+	}
+	return out
+}
+
+// isLiteral reports whether a value is written out rather than a ${...} variable or a
+// CloudFormation intrinsic function such as !Ref.
+func isLiteral(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Value != "" && !strings.Contains(node.Value, "${") &&
+		(node.Tag == "" || strings.HasPrefix(node.Tag, "!!"))
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func looksLikeSecret(name string) bool {
+	lower := strings.ToLower(name)
+	for _, kw := range secretKeywords {
+		if strings.Contains(lower, kw) {
+			return true
+		}
+	}
+	return false
+}
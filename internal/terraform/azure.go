@@ -0,0 +1,250 @@
+package terraform
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// Attributes holding the minimum TLS version of azurerm resources, with the accepted
+// values below 1.2.
+var azureTLSAttributes = map[string]string{
+	"azurerm_storage_account":      "min_tls_version",
+	"azurerm_mssql_server":         "minimum_tls_version",
+	"azurerm_redis_cache":          "minimum_tls_version",
+	"azurerm_postgresql_server":    "ssl_minimal_tls_version_enforced",
+	"azurerm_mysql_server":         "ssl_minimal_tls_version_enforced",
+	"azurerm_eventhub_namespace":   "minimum_tls_version",
+	"azurerm_servicebus_namespace": "minimum_tls_version",
+}
+
+// Web and function apps, whose site_config sets minimum_tls_version
+var azureSiteResources = map[string]bool{
+	"azurerm_linux_web_app":        true,
+	"azurerm_windows_web_app":      true,
+	"azurerm_linux_function_app":   true,
+	"azurerm_windows_function_app": true,
+	"azurerm_app_service":          true,
+	"azurerm_function_app":         true,
+}
+
+var weakTLSVersions = map[string]bool{
+	"TLS1_0": true, "TLS1_1": true, "1.0": true, "1.1": true, "TLSEnforcementDisabled": true, "Disabled": true,
+}
+
+// Source prefixes of NSG rules that match every address
+var azureWorldSources = map[string]bool{"*": true, "0.0.0.0/0": true, "0.0.0.0": true, "Internet": true, "Any": true}
+
+// checkAzure runs the azurerm checks that need only the resource itself: public blob
+// access, weak TLS, NSG rules open to the internet and key vault soft delete. SQL server
+// auditing may be configured by a separate resource and is judged by sqlAuditing.
+func checkAzure(p, resourceType, resourceName string, body hcl.Body) []finding.Finding {
+	syntaxBody, ok := body.(*hclsyntax.Body)
+	if !ok || !strings.HasPrefix(resourceType, "azurerm_") {
+		return nil
+	}
+	ref := fmt.Sprintf("%s.%s", resourceType, resourceName)
+	line := syntaxBody.SrcRange.Start.Line
+	var findings []finding.Finding
+	add := func(sev finding.Severity, line int, format string, args ...interface{}) {
+		findings = append(findings, finding.Finding{File: p, Severity: sev, Message: fmt.Sprintf(format, args...), Line: line})
+	}
+
+	if attr := azureTLSAttributes[resourceType]; attr != "" {
+		if v := stringAttr(syntaxBody, attr); weakTLSVersions[v] {
+			add(finding.Error, syntaxBody.Attributes[attr].SrcRange.Start.Line, "Resource '%s' sets %s = \"%s\"; require TLS 1.2 or later", ref, attr, v)
+		}
+	}
+	if azureSiteResources[resourceType] {
+		for _, block := range syntaxBody.Blocks {
+			if v := stringAttr(block.Body, "minimum_tls_version"); block.Type == "site_config" && weakTLSVersions[v] {
+				add(finding.Error, block.Body.Attributes["minimum_tls_version"].SrcRange.Start.Line, "Resource '%s' sets site_config minimum_tls_version = \"%s\"; require TLS 1.2 or later", ref, v)
+			}
+		}
+	}
+
+	switch resourceType {
+	case "azurerm_storage_account":
+		// allow_blob_public_access (provider v2) defaults to false, its successor to true
+		if attr, ok := syntaxBody.Attributes["allow_blob_public_access"]; ok && literalTrue(attr.Expr) {
+			add(finding.Error, attr.SrcRange.Start.Line, "Storage account '%s' sets allow_blob_public_access = true; containers can be made publicly readable", ref)
+		}
+		attr, ok := syntaxBody.Attributes["allow_nested_items_to_be_public"]
+		switch {
+		case ok && literalTrue(attr.Expr):
+			add(finding.Error, attr.SrcRange.Start.Line, "Storage account '%s' sets allow_nested_items_to_be_public = true; containers can be made publicly readable", ref)
+		case !ok && syntaxBody.Attributes["allow_blob_public_access"] == nil:
+			add(finding.Warning, line, "Storage account '%s' does not set allow_nested_items_to_be_public = false; it defaults to allowing public containers", ref)
+		}
+
+	case "azurerm_storage_container":
+		if v := stringAttr(syntaxBody, "container_access_type"); v == "blob" || v == "container" {
+			add(finding.Error, syntaxBody.Attributes["container_access_type"].SrcRange.Start.Line, "Storage container '%s' has container_access_type = \"%s\"; its blobs are publicly readable", ref, v)
+		}
+
+	case "azurerm_network_security_group":
+		for _, block := range syntaxBody.Blocks {
+			if block.Type == "security_rule" {
+				findings = append(findings, checkNSGRule(p, ref, block.Body, block.DefRange().Start.Line)...)
+			}
+		}
+	case "azurerm_network_security_rule":
+		findings = append(findings, checkNSGRule(p, ref, syntaxBody, line)...)
+
+	case "azurerm_key_vault":
+		if attr, ok := syntaxBody.Attributes["soft_delete_enabled"]; ok && literalFalse(attr.Expr) {
+			add(finding.Error, attr.SrcRange.Start.Line, "Key vault '%s' sets soft_delete_enabled = false; deleted keys, secrets and certificates cannot be recovered", ref)
+		}
+		if days, ok := numberAttr(syntaxBody, "soft_delete_retention_days"); ok && days < 7 {
+			add(finding.Error, syntaxBody.Attributes["soft_delete_retention_days"].SrcRange.Start.Line, "Key vault '%s' keeps soft-deleted objects for only %d days", ref, days)
+		}
+		if attr, ok := syntaxBody.Attributes["purge_protection_enabled"]; !ok || !literalTrue(attr.Expr) {
+			add(finding.Warning, line, "Key vault '%s' does not set purge_protection_enabled = true; soft-deleted objects can be purged before their retention ends", ref)
+		}
+	}
+	return findings
+}
+
+// checkNSGRule reports an inbound allow rule from any address to SSH, RDP or all ports.
+func checkNSGRule(p, ref string, body *hclsyntax.Body, line int) []finding.Finding {
+	if !strings.EqualFold(stringAttr(body, "direction"), "Inbound") || !strings.EqualFold(stringAttr(body, "access"), "Allow") {
+		return nil
+	}
+	var open string
+	for _, source := range append([]string{stringAttr(body, "source_address_prefix")}, stringList(body, "source_address_prefixes")...) {
+		if azureWorldSources[source] {
+			open = source
+			break
+		}
+	}
+	if open == "" {
+		return nil
+	}
+
+	// unlike AWS's "-1", protocol "*" still limits the rule to its destination ports
+	r := ingressRule{protocol: strings.ToLower(stringAttr(body, "protocol")), line: line}
+	var exposed []string
+	for _, ports := range append([]string{stringAttr(body, "destination_port_range")}, stringList(body, "destination_port_ranges")...) {
+		if ports == "" {
+			continue
+		}
+		r.portsKnown = true
+		if ports == "*" {
+			r.fromPort, r.toPort = 0, 65535
+		} else {
+			from, to, _ := strings.Cut(ports, "-")
+			if to == "" {
+				to = from
+			}
+			var err1, err2 error
+			r.fromPort, err1 = strconv.Atoi(strings.TrimSpace(from))
+			r.toPort, err2 = strconv.Atoi(strings.TrimSpace(to))
+			if err1 != nil || err2 != nil {
+				continue
+			}
+		}
+		if e := exposure(r); e != "" && !contains(exposed, e) {
+			exposed = append(exposed, e)
+		}
+	}
+	if len(exposed) == 0 {
+		return nil
+	}
+	return []finding.Finding{{
+		File:     p,
+		Severity: finding.Error,
+		Message:  fmt.Sprintf("Network security group rule in '%s' allows inbound traffic from %s to %s", ref, open, strings.Join(exposed, ", ")),
+		Line:     line,
+	}}
+}
+
+type sqlServer struct {
+	file, ref string
+	line      int
+}
+
+// sqlAuditing tracks, per directory, the Azure SQL servers declared and those with
+// auditing, configured inline (extended_auditing_policy) or through an
+// azurerm_mssql_server_extended_auditing_policy resource.
+type sqlAuditing struct {
+	servers map[string][]sqlServer
+	audited map[string]map[string]bool // dir -> "type.name" of audited servers
+}
+
+func newSQLAuditing() *sqlAuditing {
+	return &sqlAuditing{servers: make(map[string][]sqlServer), audited: make(map[string]map[string]bool)}
+}
+
+// add records a resource block relevant to SQL server auditing.
+func (a *sqlAuditing) add(p, resourceType, resourceName string, body hcl.Body) {
+	syntaxBody, ok := body.(*hclsyntax.Body)
+	if !ok {
+		return
+	}
+	dir := filepath.Dir(p)
+	ref := resourceType + "." + resourceName
+	switch resourceType {
+	case "azurerm_mssql_server", "azurerm_sql_server":
+		for _, block := range syntaxBody.Blocks {
+			if block.Type == "extended_auditing_policy" {
+				a.markAudited(dir, ref)
+			}
+		}
+		a.servers[dir] = append(a.servers[dir], sqlServer{p, ref, syntaxBody.SrcRange.Start.Line})
+	case "azurerm_mssql_server_extended_auditing_policy":
+		if attr, ok := syntaxBody.Attributes["enabled"]; ok && literalFalse(attr.Expr) {
+			return
+		}
+		attr, ok := syntaxBody.Attributes["server_id"]
+		if !ok {
+			return
+		}
+		for _, traversal := range attr.Expr.Variables() {
+			if len(traversal) < 2 {
+				continue
+			}
+			if step, ok := traversal[1].(hcl.TraverseAttr); ok {
+				a.markAudited(dir, traversal.RootName()+"."+step.Name)
+			}
+		}
+	}
+}
+
+func (a *sqlAuditing) markAudited(dir, ref string) {
+	if a.audited[dir] == nil {
+		a.audited[dir] = make(map[string]bool)
+	}
+	a.audited[dir][ref] = true
+}
+
+// findings reports the SQL servers without auditing.
+func (a *sqlAuditing) findings() []finding.Finding {
+	dirs := make([]string, 0, len(a.servers))
+	for dir := range a.servers {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	var findings []finding.Finding
+	for _, dir := range dirs {
+		for _, s := range a.servers[dir] {
+			if a.audited[dir][s.ref] {
+				continue
+			}
+			findings = append(findings, finding.Finding{
+				File:     s.file,
+				Severity: finding.Warning,
+				Message:  fmt.Sprintf("SQL server '%s' has no auditing; add an azurerm_mssql_server_extended_auditing_policy so access and changes are logged", s.ref),
+				Line:     s.line,
+			})
+		}
+	}
+	return findings
+}
@@ -0,0 +1,90 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// backendSetting is an attribute of a backend, set in the backend block itself or in
+// a partial configuration file (*.tfbackend) next to it.
+type backendSetting struct {
+	file string
+	attr *hclsyntax.Attribute
+}
+
+// checkBackend reports S3 backends storing state unencrypted or without state locking.
+// Hardcoded backend credentials are reported by AuditCredentials.
+func checkBackend(p string, body hcl.Body) []finding.Finding {
+	syntaxBody, ok := body.(*hclsyntax.Body)
+	if !ok {
+		return nil
+	}
+	var findings []finding.Finding
+	for _, block := range syntaxBody.Blocks {
+		if block.Type != "backend" || len(block.Labels) != 1 || block.Labels[0] != "s3" {
+			continue
+		}
+		settings := partialBackendConfig(filepath.Dir(p))
+		if len(block.Body.Attributes) == 0 && len(settings) == 0 {
+			continue // configured entirely through -backend-config flags we cannot see
+		}
+		for name, attr := range block.Body.Attributes {
+			settings[name] = backendSetting{p, attr}
+		}
+		line := block.DefRange().Start.Line
+
+		switch encrypt, ok := settings["encrypt"]; {
+		case !ok:
+			findings = append(findings, finding.Finding{
+				File:     p,
+				Severity: finding.Warning,
+				Message:  "backend \"s3\" does not set encrypt = true; state holds every secret Terraform manages and should be encrypted at rest",
+				Line:     line,
+			})
+		case literalFalse(encrypt.attr.Expr):
+			findings = append(findings, finding.Finding{
+				File:     encrypt.file,
+				Severity: finding.Error,
+				Message:  "backend \"s3\" disables state encryption (encrypt = false)",
+				Line:     encrypt.attr.SrcRange.Start.Line,
+			})
+		}
+
+		_, dynamodb := settings["dynamodb_table"]
+		lockfile, ok := settings["use_lockfile"]
+		if !dynamodb && !(ok && literalTrue(lockfile.attr.Expr)) {
+			findings = append(findings, finding.Finding{
+				File:     p,
+				Severity: finding.Warning,
+				Message:  "backend \"s3\" has no state locking (set dynamodb_table, or use_lockfile = true on Terraform 1.10+); concurrent applies can corrupt state",
+				Line:     line,
+			})
+		}
+	}
+	return findings
+}
+
+// partialBackendConfig returns the attributes set by the *.tfbackend files in dir.
+func partialBackendConfig(dir string) map[string]backendSetting {
+	settings := make(map[string]backendSetting)
+	files, _ := filepath.Glob(filepath.Join(dir, "*.tfbackend"))
+	for _, p := range files {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			continue
+		}
+		file, diags := hclsyntax.ParseConfig(data, p, hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			continue
+		}
+		for name, attr := range file.Body.(*hclsyntax.Body).Attributes {
+			settings[name] = backendSetting{p, attr}
+		}
+	}
+	return settings
+}
@@ -0,0 +1,307 @@
+package terraform
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/report"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// Credential source audit.
+//
+// For every provider block and backend configuration we classify where credentials
+// come from. Recommended sources (profiles, assumed roles, OIDC/workload identity,
+// instance or managed identities, the default environment chain) are listed in the
+// report; static secrets written into committed files are findings.
+
+// CredentialSource describes how one provider or backend authenticates.
+type CredentialSource struct {
+	Stack   string // directory holding the configuration
+	File    string
+	Block   string // e.g. provider "aws" (alias prod) or backend "s3"
+	Source  string
+	Details string
+	Risky   bool
+}
+
+// Attributes naming credentials directly, in addition to names matching credentialAttribute.
+var credentialAttributes = map[string]bool{
+	"access_key": true, "secret_key": true, "token": true, "credentials": true,
+	"access_token": true, "client_secret": true, "client_certificate_password": true,
+	"client_key": true, "password": true, "api_key": true, "api_token": true,
+	"app_key": true, "sas_token": true, "conn_str": true,
+}
+
+// Attributes or nested blocks selecting a recommended credential source.
+var recommendedSources = []struct {
+	name   string
+	source string
+}{
+	{"assume_role_with_web_identity", "OIDC / web identity"},
+	{"use_oidc", "OIDC / web identity"},
+	{"oidc_token_file_path", "OIDC / web identity"},
+	{"oidc_request_token", "OIDC / web identity"},
+	{"use_msi", "managed identity"},
+	{"impersonate_service_account", "service account impersonation"},
+	{"assume_role", "assumed role"},
+	{"profile", "shared credentials profile"},
+	{"shared_credentials_files", "shared credentials profile"},
+	{"shared_credentials_file", "shared credentials profile"},
+	{"config_path", "kubeconfig"},
+}
+
+// Matches placeholders meant to be filled from the environment: $AWS_SECRET or ${AWS_SECRET}
+var envPlaceholderRegex = regexp.MustCompile(`^\$\{?[A-Z][A-Z0-9_]*\}?$`)
+
+// Matches credentials embedded in connection strings (user:pass@host or password=...)
+var embeddedPasswordRegex = regexp.MustCompile(`(?i)(://[^/:@\s]+:[^@\s]+@|password=\S+)`)
+
+func credentialAttribute(name string) bool {
+	lower := strings.ToLower(name)
+	if credentialAttributes[lower] {
+		return true
+	}
+	for _, kw := range []string{"secret", "password", "token"} {
+		if strings.Contains(lower, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// AuditCredentials classifies the credential sources of every provider and backend
+// under path and returns findings for static credentials in committed files.
+func AuditCredentials(path string) ([]CredentialSource, []finding.Finding, error) {
+	stacks := make(map[string][]string)
+	err := scanner.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		if ext := filepath.Ext(p); ext == ".tf" || ext == ".tfbackend" {
+			stacks[filepath.Dir(p)] = append(stacks[filepath.Dir(p)], p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dirs := make([]string, 0, len(stacks))
+	for d := range stacks {
+		dirs = append(dirs, d)
+	}
+	sort.Strings(dirs)
+
+	var sources []CredentialSource
+	var findings []finding.Finding
+	for _, dir := range dirs {
+		s, f := auditStack(dir, stacks[dir])
+		sources = append(sources, s...)
+		findings = append(findings, f...)
+	}
+	return sources, findings, nil
+}
+
+type credentialBlock struct {
+	file  string
+	label string
+	body  *hclsyntax.Body
+}
+
+func auditStack(dir string, files []string) ([]CredentialSource, []finding.Finding) {
+	var blocks []credentialBlock
+	defaults := make(map[string]cty.Value) // variable name -> literal default
+
+	for _, p := range files {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			continue
+		}
+		file, diags := hclsyntax.ParseConfig(data, p, hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			continue // reported by Scan
+		}
+		body := file.Body.(*hclsyntax.Body)
+
+		// Partial backend configuration files are flat attribute lists.
+		if filepath.Ext(p) == ".tfbackend" {
+			blocks = append(blocks, credentialBlock{p, fmt.Sprintf("backend config %s", filepath.Base(p)), body})
+			continue
+		}
+
+		for _, block := range body.Blocks {
+			switch block.Type {
+			case "provider":
+				if len(block.Labels) != 1 {
+					continue
+				}
+				label := fmt.Sprintf("provider \"%s\"", block.Labels[0])
+				if alias, ok := block.Body.Attributes["alias"]; ok {
+					if v, diags := alias.Expr.Value(nil); !diags.HasErrors() && v.Type() == cty.String {
+						label += fmt.Sprintf(" (alias %s)", v.AsString())
+					}
+				}
+				blocks = append(blocks, credentialBlock{p, label, block.Body})
+			case "terraform":
+				for _, inner := range block.Body.Blocks {
+					if inner.Type == "backend" && len(inner.Labels) == 1 {
+						blocks = append(blocks, credentialBlock{p, fmt.Sprintf("backend \"%s\"", inner.Labels[0]), inner.Body})
+					}
+				}
+			case "variable":
+				if len(block.Labels) != 1 {
+					continue
+				}
+				if def, ok := block.Body.Attributes["default"]; ok {
+					if v, diags := def.Expr.Value(nil); !diags.HasErrors() {
+						defaults[block.Labels[0]] = v
+					}
+				}
+			}
+		}
+	}
+
+	var sources []CredentialSource
+	var findings []finding.Finding
+	for _, b := range blocks {
+		src := CredentialSource{Stack: dir, File: b.file, Block: b.label}
+		var static []string
+
+		for _, name := range sortedAttributeNames(b.body) {
+			if !credentialAttribute(name) {
+				continue
+			}
+			attr := b.body.Attributes[name]
+			kind, detail := classifyCredentialExpr(attr.Expr, defaults)
+			switch kind {
+			case "literal":
+				if name == "conn_str" && !embeddedPasswordRegex.MatchString(detail) {
+					continue
+				}
+				static = append(static, name)
+				findings = append(findings, finding.Finding{
+					File:     b.file,
+					Severity: finding.Error,
+					Message:  fmt.Sprintf("%s sets '%s' to a hardcoded credential; use a profile, assumed role, OIDC or an instance/managed identity instead", b.label, name),
+					Line:     attr.SrcRange.Start.Line,
+				})
+			case "variable-default":
+				static = append(static, name)
+				findings = append(findings, finding.Finding{
+					File:     b.file,
+					Severity: finding.Error,
+					Message:  fmt.Sprintf("%s sets '%s' from %s, which has a hardcoded default credential", b.label, name, detail),
+					Line:     attr.SrcRange.Start.Line,
+				})
+			case "variable":
+				static = append(static, name)
+				findings = append(findings, finding.Finding{
+					File:     b.file,
+					Severity: finding.Warning,
+					Message:  fmt.Sprintf("%s passes static credential '%s' through %s; prefer short-lived credentials from a profile, assumed role or OIDC", b.label, name, detail),
+					Line:     attr.SrcRange.Start.Line,
+				})
+			case "env-placeholder":
+				static = append(static, name)
+				findings = append(findings, finding.Finding{
+					File:     b.file,
+					Severity: finding.Warning,
+					Message:  fmt.Sprintf("%s sets '%s' to the environment placeholder '%s', which Terraform does not expand; committed templated credentials are easily leaked once rendered", b.label, name, detail),
+					Line:     attr.SrcRange.Start.Line,
+				})
+			}
+		}
+
+		switch {
+		case len(static) > 0:
+			src.Source = "static credentials"
+			src.Details = strings.Join(static, ", ")
+			src.Risky = true
+		default:
+			src.Source, src.Details = recommendedSource(b.body)
+		}
+		sources = append(sources, src)
+	}
+	return sources, findings
+}
+
+// classifyCredentialExpr reports how a credential value is supplied.
+func classifyCredentialExpr(expr hclsyntax.Expression, defaults map[string]cty.Value) (string, string) {
+	if traversal, diags := hcl.AbsTraversalForExpr(expr); !diags.HasErrors() && traversal.RootName() == "var" && len(traversal) > 1 {
+		if step, ok := traversal[1].(hcl.TraverseAttr); ok {
+			ref := "var." + step.Name
+			if def, ok := defaults[step.Name]; ok && !def.IsNull() && def.Type() == cty.String && def.AsString() != "" {
+				return "variable-default", ref
+			}
+			return "variable", ref
+		}
+	}
+
+	if tmpl, ok := expr.(*hclsyntax.TemplateWrapExpr); ok {
+		if t, ok := tmpl.Wrapped.(*hclsyntax.ScopeTraversalExpr); ok && isEnvName(t.Traversal.RootName()) {
+			return "env-placeholder", "${" + t.Traversal.RootName() + "}"
+		}
+	}
+
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() || val.IsNull() || !val.IsKnown() || val.Type() != cty.String {
+		return "expression", ""
+	}
+	s := val.AsString()
+	if s == "" {
+		return "expression", ""
+	}
+	if envPlaceholderRegex.MatchString(s) {
+		return "env-placeholder", s
+	}
+	return "literal", s
+}
+
+func isEnvName(name string) bool {
+	return name != "" && strings.ToUpper(name) == name && envPlaceholderRegex.MatchString("$"+name)
+}
+
+// recommendedSource names the credential mechanism a block without static credentials relies on.
+func recommendedSource(body *hclsyntax.Body) (string, string) {
+	for _, rs := range recommendedSources {
+		if attr, ok := body.Attributes[rs.name]; ok {
+			detail := rs.name
+			if v, diags := attr.Expr.Value(nil); !diags.HasErrors() && v.Type() == cty.String {
+				detail = fmt.Sprintf("%s = %s", rs.name, v.AsString())
+			}
+			return rs.source, detail
+		}
+		for _, block := range body.Blocks {
+			if block.Type == rs.name {
+				return rs.source, rs.name + " block"
+			}
+		}
+	}
+	return "default credential chain", "environment, instance role or workload identity"
+}
+
+// CredentialSection renders the per-stack credential sources as a report section.
+func CredentialSection(sources []CredentialSource) report.Section {
+	s := report.Section{
+		Title:   "Credential Sources",
+		Columns: []string{"Stack", "Block", "Source", "Details", "Risk"},
+	}
+	for _, src := range sources {
+		risk := "ok"
+		if src.Risky {
+			risk = "RISKY"
+		}
+		s.Rows = append(s.Rows, []string{src.Stack, src.Block, src.Source, src.Details, risk})
+	}
+	return s
+}
@@ -0,0 +1,102 @@
+package terraform
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// Resources encrypted at rest through a boolean attribute.
+var encryptionFlags = map[string]string{
+	"aws_ebs_volume":      "encrypted",
+	"aws_db_instance":     "storage_encrypted",
+	"aws_rds_cluster":     "storage_encrypted",
+	"aws_efs_file_system": "encrypted",
+}
+
+// checkEncryption reports resources that are not encrypted at rest. S3 buckets are
+// handled by s3Buckets, since their encryption may be configured by a separate resource.
+func checkEncryption(p, resourceType, resourceName string, body hcl.Body) []finding.Finding {
+	syntaxBody, ok := body.(*hclsyntax.Body)
+	if !ok {
+		return nil
+	}
+	ref := fmt.Sprintf("%s.%s", resourceType, resourceName)
+	line := syntaxBody.SrcRange.Start.Line
+
+	if flag, ok := encryptionFlags[resourceType]; ok {
+		attr, set := syntaxBody.Attributes[flag]
+		switch {
+		case !set:
+			return []finding.Finding{{
+				File:     p,
+				Severity: finding.Warning,
+				Message:  fmt.Sprintf("Resource '%s' does not set %s; its data is stored unencrypted at rest", ref, flag),
+				Line:     line,
+			}}
+		case literalFalse(attr.Expr):
+			return []finding.Finding{{
+				File:     p,
+				Severity: finding.Error,
+				Message:  fmt.Sprintf("Resource '%s' sets %s = false; its data is stored unencrypted at rest", ref, flag),
+				Line:     attr.SrcRange.Start.Line,
+			}}
+		}
+		return nil
+	}
+
+	switch resourceType {
+	case "aws_sqs_queue":
+		if _, kms := syntaxBody.Attributes["kms_master_key_id"]; kms {
+			return nil
+		}
+		attr, ok := syntaxBody.Attributes["sqs_managed_sse_enabled"]
+		switch {
+		case !ok:
+			return []finding.Finding{{
+				File:     p,
+				Severity: finding.Info,
+				Message:  fmt.Sprintf("Resource '%s' sets neither kms_master_key_id nor sqs_managed_sse_enabled; it relies on the SQS default encryption", ref),
+				Line:     line,
+			}}
+		case literalFalse(attr.Expr):
+			return []finding.Finding{{
+				File:     p,
+				Severity: finding.Warning,
+				Message:  fmt.Sprintf("Resource '%s' sets sqs_managed_sse_enabled = false without kms_master_key_id; messages are stored unencrypted", ref),
+				Line:     attr.SrcRange.Start.Line,
+			}}
+		}
+		return nil
+	case "aws_sns_topic":
+		if _, kms := syntaxBody.Attributes["kms_master_key_id"]; kms {
+			return nil
+		}
+		return []finding.Finding{{
+			File:     p,
+			Severity: finding.Warning,
+			Message:  fmt.Sprintf("Resource '%s' does not set kms_master_key_id; messages are stored unencrypted", ref),
+			Line:     line,
+		}}
+	}
+	return nil
+}
+
+// literalFalse reports whether expr is the constant false (or the string "false").
+func literalFalse(expr hclsyntax.Expression) bool {
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() || val.IsNull() || !val.IsKnown() {
+		return false
+	}
+	switch val.Type() {
+	case cty.Bool:
+		return val.False()
+	case cty.String:
+		return val.AsString() == "false"
+	}
+	return false
+}
@@ -0,0 +1,312 @@
+package terraform
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// Pure Terraform functions available to resolved expressions. Impure ones such as timestamp
+// and uuid, and those reading files, are left out, so expressions using them stay unresolved.
+var evalFunctions = map[string]function.Function{
+	"abs": stdlib.AbsoluteFunc, "ceil": stdlib.CeilFunc, "chomp": stdlib.ChompFunc,
+	"coalesce": stdlib.CoalesceFunc, "coalescelist": stdlib.CoalesceListFunc, "compact": stdlib.CompactFunc,
+	"concat": stdlib.ConcatFunc, "contains": stdlib.ContainsFunc, "distinct": stdlib.DistinctFunc,
+	"element": stdlib.ElementFunc, "flatten": stdlib.FlattenFunc, "floor": stdlib.FloorFunc,
+	"format": stdlib.FormatFunc, "formatlist": stdlib.FormatListFunc, "indent": stdlib.IndentFunc,
+	"join": stdlib.JoinFunc, "jsondecode": stdlib.JSONDecodeFunc, "jsonencode": stdlib.JSONEncodeFunc,
+	"keys": stdlib.KeysFunc, "length": stdlib.LengthFunc, "lookup": stdlib.LookupFunc,
+	"lower": stdlib.LowerFunc, "max": stdlib.MaxFunc, "merge": stdlib.MergeFunc, "min": stdlib.MinFunc,
+	"range": stdlib.RangeFunc, "regex": stdlib.RegexFunc, "regexall": stdlib.RegexAllFunc,
+	"replace": stdlib.ReplaceFunc, "reverse": stdlib.ReverseListFunc, "setunion": stdlib.SetUnionFunc,
+	"slice": stdlib.SliceFunc, "sort": stdlib.SortFunc, "split": stdlib.SplitFunc, "substr": stdlib.SubstrFunc,
+	"title": stdlib.TitleFunc, "trim": stdlib.TrimFunc, "trimprefix": stdlib.TrimPrefixFunc,
+	"trimspace": stdlib.TrimSpaceFunc, "trimsuffix": stdlib.TrimSuffixFunc, "upper": stdlib.UpperFunc,
+	"values": stdlib.ValuesFunc, "zipmap": stdlib.ZipmapFunc,
+	"tostring": stdlib.MakeToFunc(cty.String), "tonumber": stdlib.MakeToFunc(cty.Number),
+	"tobool": stdlib.MakeToFunc(cty.Bool), "tolist": stdlib.MakeToFunc(cty.List(cty.DynamicPseudoType)),
+	"toset": stdlib.MakeToFunc(cty.Set(cty.DynamicPseudoType)), "tomap": stdlib.MakeToFunc(cty.Map(cty.DynamicPseudoType)),
+}
+
+// decisiveAttributes are, by resource type, the attributes whose value a check judges. One
+// depending on a variable or local without a known value is reported, as its check cannot
+// tell whether the resource passes. Encryption flags and TLS versions are added by init.
+var decisiveAttributes = map[string][]string{
+	"aws_s3_bucket":                       {"acl"},
+	"aws_security_group":                  {"cidr_blocks", "ipv6_cidr_blocks", "from_port", "to_port", "protocol"},
+	"aws_security_group_rule":             {"type", "cidr_blocks", "ipv6_cidr_blocks", "from_port", "to_port", "protocol"},
+	"aws_vpc_security_group_ingress_rule": {"cidr_ipv4", "cidr_ipv6", "from_port", "to_port", "ip_protocol"},
+	"azurerm_storage_account":             {"allow_blob_public_access", "allow_nested_items_to_be_public"},
+	"azurerm_storage_container":           {"container_access_type"},
+	"azurerm_key_vault":                   {"soft_delete_enabled", "soft_delete_retention_days", "purge_protection_enabled"},
+	"google_storage_bucket_iam_member":    {"member"},
+	"google_storage_bucket_iam_binding":   {"members"},
+	"google_compute_firewall":             {"direction", "disabled", "source_ranges"},
+}
+
+func init() {
+	for resourceType, flag := range encryptionFlags {
+		decisiveAttributes[resourceType] = append(decisiveAttributes[resourceType], flag)
+	}
+	for resourceType, attr := range azureTLSAttributes {
+		decisiveAttributes[resourceType] = append(decisiveAttributes[resourceType], attr)
+	}
+}
+
+// evalContexts builds, per directory (a Terraform module), the context its expressions are
+// resolved in: var.* holding the variable defaults overridden by the variable definitions
+// files Terraform loads automatically, and local.* the locals computed from them. Values
+// that cannot be determined, such as a variable without a default or a local built from a
+// resource attribute, are unknown.
+//
+// The module's files are parsed with a parser of its own, as hclparse.Parser does not report
+// the errors of a file a second time.
+type evalContexts struct {
+	parser *hclparse.Parser
+	dirs   map[string]*hcl.EvalContext
+}
+
+func newEvalContexts() *evalContexts {
+	return &evalContexts{parser: hclparse.NewParser(), dirs: make(map[string]*hcl.EvalContext)}
+}
+
+// forDir returns the evaluation context of the module in dir, built on first use.
+func (e *evalContexts) forDir(dir string) *hcl.EvalContext {
+	if ctx, ok := e.dirs[dir]; ok {
+		return ctx
+	}
+	ctx := &hcl.EvalContext{Functions: evalFunctions}
+	e.dirs[dir] = ctx
+
+	entries, _ := os.ReadDir(dir)
+	var files, autoVars []string
+	for _, entry := range entries {
+		name := entry.Name()
+		switch {
+		case entry.IsDir():
+		case strings.HasSuffix(name, ".tf"):
+			files = append(files, filepath.Join(dir, name))
+		case strings.HasSuffix(name, ".auto.tfvars") || strings.HasSuffix(name, ".auto.tfvars.json"):
+			autoVars = append(autoVars, filepath.Join(dir, name))
+		}
+	}
+	// terraform.tfvars, then terraform.tfvars.json, then the *.auto.tfvars files by name, the
+	// later ones winning as with terraform plan
+	sort.Strings(autoVars)
+	tfvars := append([]string{filepath.Join(dir, "terraform.tfvars"), filepath.Join(dir, "terraform.tfvars.json")}, autoVars...)
+
+	vars := make(map[string]cty.Value)
+	locals := make(map[string]hcl.Expression)
+	for _, p := range files {
+		file, diags := e.parser.ParseHCLFile(p)
+		if diags.HasErrors() {
+			continue
+		}
+		body, ok := file.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+		for _, block := range body.Blocks {
+			switch {
+			case block.Type == "variable" && len(block.Labels) == 1:
+				vars[block.Labels[0]] = cty.DynamicVal
+				if def, ok := block.Body.Attributes["default"]; ok {
+					if v, diags := def.Expr.Value(nil); !diags.HasErrors() {
+						vars[block.Labels[0]] = v
+					}
+				}
+			case block.Type == "locals":
+				for name, attr := range block.Body.Attributes {
+					locals[name] = attr.Expr
+				}
+			}
+		}
+	}
+	for _, p := range tfvars {
+		if _, err := os.Stat(p); err != nil {
+			continue
+		}
+		var file *hcl.File
+		var diags hcl.Diagnostics
+		if strings.HasSuffix(p, ".json") {
+			file, diags = e.parser.ParseJSONFile(p)
+		} else {
+			file, diags = e.parser.ParseHCLFile(p)
+		}
+		if diags.HasErrors() {
+			continue
+		}
+		attrs, diags := file.Body.JustAttributes()
+		if diags.HasErrors() {
+			continue
+		}
+		for name, attr := range attrs {
+			if _, declared := vars[name]; !declared {
+				continue // terraform warns and ignores values of undeclared variables
+			}
+			if v, diags := attr.Expr.Value(nil); !diags.HasErrors() {
+				vars[name] = v
+			}
+		}
+	}
+	ctx.Variables = map[string]cty.Value{"var": cty.ObjectVal(vars), "local": cty.EmptyObjectVal}
+	ctx.Variables["local"] = evalLocals(ctx, locals)
+	return ctx
+}
+
+// evalLocals computes the locals in ctx, which holds var.*, as an object. Locals refer to each
+// other in any order, so each pass computes those whose references are computed; the ones
+// left, in a cycle or referring to undeclared locals, are unknown.
+func evalLocals(ctx *hcl.EvalContext, exprs map[string]hcl.Expression) cty.Value {
+	values := make(map[string]cty.Value)
+	for progress := true; progress && len(values) < len(exprs); {
+		progress = false
+		for _, name := range sortedKeys(exprs) {
+			if _, done := values[name]; done || !localsComputed(exprs[name], values) {
+				continue
+			}
+			v, diags := exprs[name].Value(ctx)
+			if diags.HasErrors() {
+				v = cty.DynamicVal // e.g. a resource attribute, known after apply
+			}
+			values[name] = v
+			ctx.Variables["local"] = cty.ObjectVal(values)
+			progress = true
+		}
+	}
+	for name := range exprs {
+		if _, done := values[name]; !done {
+			values[name] = cty.DynamicVal
+		}
+	}
+	return cty.ObjectVal(values)
+}
+
+// localsComputed reports whether every local expr refers to is in values.
+func localsComputed(expr hcl.Expression, values map[string]cty.Value) bool {
+	for _, t := range expr.Variables() {
+		if name, ok := referenceName(t, "local"); ok {
+			if _, done := values[name]; !done {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// referenceName returns the name in a traversal root.name, such as var.x.
+func referenceName(t hcl.Traversal, root string) (string, bool) {
+	if t.RootName() != root || len(t) < 2 {
+		return "", false
+	}
+	step, ok := t[1].(hcl.TraverseAttr)
+	return step.Name, ok
+}
+
+// resolvedExpr is an expression replaced by its value in the evaluation context of its
+// module, keeping the expression as written for checks that judge it rather than its value,
+// such as hardcoded secrets.
+type resolvedExpr struct {
+	*hclsyntax.LiteralValueExpr
+	written hclsyntax.Expression
+}
+
+// resolved reports whether expr is an expression resolve replaced by its value.
+func resolved(expr hcl.Expression) bool {
+	_, ok := expr.(*resolvedExpr)
+	return ok
+}
+
+// unresolvedAttribute is an attribute referring to values that are not known.
+type unresolvedAttribute struct {
+	name       string
+	line       int
+	references []string
+}
+
+// resolve replaces the attributes of body and its nested blocks that refer only to variables
+// and locals by their values in ctx, so the checks reading literal values judge them too.
+// Attributes whose value is not known, or only in part such as a list with an unknown
+// element, are returned; those not known at all are left as written.
+func resolve(body *hclsyntax.Body, ctx *hcl.EvalContext) []unresolvedAttribute {
+	var unresolved []unresolvedAttribute
+	for _, name := range sortedKeys(body.Attributes) {
+		attr := body.Attributes[name]
+		refs := attr.Expr.Variables()
+		if len(refs) == 0 || resolved(attr.Expr) {
+			continue
+		}
+		var unknown []string
+		inScope := true
+		for _, t := range refs {
+			if root := t.RootName(); root != "var" && root != "local" {
+				inScope = false // resources, count, each and self are only known to terraform
+				break
+			}
+			if v, diags := t.TraverseAbs(ctx); diags.HasErrors() || !v.IsWhollyKnown() {
+				unknown = append(unknown, fmt.Sprintf("%s.%s", t.RootName(), traversalName(t)))
+			}
+		}
+		if !inScope {
+			continue
+		}
+		v, diags := attr.Expr.Value(ctx)
+		if (diags.HasErrors() || !v.IsWhollyKnown()) && len(unknown) > 0 {
+			sort.Strings(unknown)
+			unresolved = append(unresolved, unresolvedAttribute{name: name, line: attr.SrcRange.Start.Line, references: dedupe(unknown)})
+		}
+		if diags.HasErrors() || !v.IsKnown() {
+			continue
+		}
+		attr.Expr = &resolvedExpr{
+			LiteralValueExpr: &hclsyntax.LiteralValueExpr{Val: v, SrcRange: attr.Expr.Range()},
+			written:          attr.Expr,
+		}
+	}
+	for _, block := range body.Blocks {
+		if block.Type != "dynamic" {
+			unresolved = append(unresolved, resolve(block.Body, ctx)...)
+		}
+	}
+	return unresolved
+}
+
+func traversalName(t hcl.Traversal) string {
+	if len(t) > 1 {
+		if step, ok := t[1].(hcl.TraverseAttr); ok {
+			return step.Name
+		}
+	}
+	return "?"
+}
+
+// unresolvedFindings reports the attributes of a resource that its checks judge but whose
+// value could not be determined.
+func unresolvedFindings(p, resourceType, resourceName string, unresolved []unresolvedAttribute) []finding.Finding {
+	decisive := decisiveAttributes[resourceType]
+	var findings []finding.Finding
+	for _, u := range unresolved {
+		if !contains(decisive, u.name) {
+			continue
+		}
+		findings = append(findings, finding.Finding{
+			File:     p,
+			Severity: finding.Info,
+			Message: fmt.Sprintf("Cannot determine %s of '%s.%s': it depends on %s, which has no known value; its checks are skipped",
+				u.name, resourceType, resourceName, strings.Join(u.references, ", ")),
+			Line: u.line,
+		})
+	}
+	return findings
+}
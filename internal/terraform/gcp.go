@@ -0,0 +1,168 @@
+package terraform
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// Members granting access to anyone, or to anyone with a Google account
+var publicMembers = map[string]bool{"allUsers": true, "allAuthenticatedUsers": true}
+
+// Scopes giving a service account access to every API its roles allow
+var fullScopes = map[string]bool{
+	"cloud-platform": true,
+	"https://www.googleapis.com/auth/cloud-platform": true,
+}
+
+// SQL ssl_mode values that refuse unencrypted connections
+var sslOnlyModes = map[string]bool{"ENCRYPTED_ONLY": true, "TRUSTED_CLIENT_CERTIFICATE_REQUIRED": true}
+
+// checkGoogle runs the google provider checks: public GCS buckets, instances using the
+// default service account with full API scopes, firewall rules open to the internet and
+// Cloud SQL instances accepting unencrypted connections.
+func checkGoogle(p, resourceType, resourceName string, body hcl.Body) []finding.Finding {
+	syntaxBody, ok := body.(*hclsyntax.Body)
+	if !ok || !strings.HasPrefix(resourceType, "google_") {
+		return nil
+	}
+	ref := fmt.Sprintf("%s.%s", resourceType, resourceName)
+	line := syntaxBody.SrcRange.Start.Line
+	var findings []finding.Finding
+	add := func(sev finding.Severity, line int, format string, args ...interface{}) {
+		findings = append(findings, finding.Finding{File: p, Severity: sev, Message: fmt.Sprintf(format, args...), Line: line})
+	}
+
+	switch resourceType {
+	case "google_storage_bucket_iam_member", "google_storage_bucket_iam_binding":
+		members := stringList(syntaxBody, "members")
+		if m := stringAttr(syntaxBody, "member"); m != "" {
+			members = append(members, m)
+		}
+		for _, m := range members {
+			if publicMembers[m] {
+				add(finding.Error, line, "Bucket IAM '%s' grants %s to %s; the bucket is public", ref, stringAttr(syntaxBody, "role"), m)
+			}
+		}
+	case "google_storage_bucket_access_control", "google_storage_default_object_access_control", "google_storage_object_access_control":
+		if e := stringAttr(syntaxBody, "entity"); publicMembers[e] {
+			add(finding.Error, line, "ACL '%s' grants %s to %s; the objects are public", ref, stringAttr(syntaxBody, "role"), e)
+		}
+	case "google_storage_bucket_acl", "google_storage_default_object_acl":
+		for _, entity := range stringList(syntaxBody, "role_entity") {
+			if _, who, _ := strings.Cut(entity, ":"); publicMembers[who] {
+				add(finding.Error, line, "ACL '%s' grants %s; the objects are public", ref, entity)
+			}
+		}
+
+	case "google_compute_instance", "google_compute_instance_template":
+		for _, block := range syntaxBody.Blocks {
+			if block.Type != "service_account" {
+				continue
+			}
+			email := stringAttr(block.Body, "email")
+			_, set := block.Body.Attributes["email"]
+			defaultAccount := !set || email == "default" || strings.HasSuffix(email, "-compute@developer.gserviceaccount.com")
+			for _, scope := range stringList(block.Body, "scopes") {
+				if defaultAccount && fullScopes[scope] {
+					add(finding.Error, block.DefRange().Start.Line, "Instance '%s' runs as the default compute service account with the cloud-platform scope, giving it the project-wide Editor role on every API; use a dedicated service account", ref)
+					break
+				}
+			}
+		}
+
+	case "google_compute_firewall":
+		findings = append(findings, checkFirewall(p, ref, syntaxBody)...)
+
+	case "google_sql_database_instance":
+		for _, settings := range syntaxBody.Blocks {
+			if settings.Type != "settings" {
+				continue
+			}
+			checked := false
+			for _, ipConfig := range settings.Body.Blocks {
+				if ipConfig.Type != "ip_configuration" {
+					continue
+				}
+				checked = true
+				requireSSL, setSSL := ipConfig.Body.Attributes["require_ssl"]
+				mode := stringAttr(ipConfig.Body, "ssl_mode")
+				switch {
+				case sslOnlyModes[mode] || setSSL && literalTrue(requireSSL.Expr):
+					// encrypted connections only
+				case mode != "":
+					add(finding.Error, ipConfig.Body.Attributes["ssl_mode"].SrcRange.Start.Line, "Cloud SQL instance '%s' sets ssl_mode = \"%s\", accepting unencrypted connections", ref, mode)
+				case setSSL && literalFalse(requireSSL.Expr):
+					add(finding.Error, requireSSL.SrcRange.Start.Line, "Cloud SQL instance '%s' sets require_ssl = false, accepting unencrypted connections", ref)
+				case !setSSL:
+					add(finding.Warning, ipConfig.DefRange().Start.Line, "Cloud SQL instance '%s' does not require SSL; set ssl_mode = \"ENCRYPTED_ONLY\" (or require_ssl = true on older providers)", ref)
+				}
+			}
+			if !checked {
+				add(finding.Warning, settings.DefRange().Start.Line, "Cloud SQL instance '%s' does not require SSL; set ssl_mode = \"ENCRYPTED_ONLY\" in settings.ip_configuration", ref)
+			}
+		}
+	}
+	return findings
+}
+
+// checkFirewall reports an ingress firewall rule allowing traffic from the whole internet to
+// SSH, RDP or all ports. A rule with neither source ranges nor source tags or service
+// accounts applies to 0.0.0.0/0.
+func checkFirewall(p, ref string, body *hclsyntax.Body) []finding.Finding {
+	if d := stringAttr(body, "direction"); d != "" && !strings.EqualFold(d, "INGRESS") {
+		return nil
+	}
+	if attr, ok := body.Attributes["disabled"]; ok && literalTrue(attr.Expr) {
+		return nil
+	}
+	open := worldOpen(stringList(body, "source_ranges"))
+	if _, ok := body.Attributes["source_ranges"]; !ok && body.Attributes["source_tags"] == nil && body.Attributes["source_service_accounts"] == nil {
+		open = "0.0.0.0/0 (the default without source ranges)"
+	}
+	if open == "" {
+		return nil
+	}
+
+	var exposed []string
+	for _, allow := range body.Blocks {
+		if allow.Type != "allow" {
+			continue
+		}
+		r := ingressRule{protocol: strings.ToLower(stringAttr(allow.Body, "protocol"))}
+		if r.protocol == "all" {
+			r.protocol = "-1"
+		}
+		ports := stringList(allow.Body, "ports")
+		if len(ports) == 0 && (r.protocol == "tcp" || r.protocol == "udp" || r.protocol == "-1") {
+			ports = []string{"0-65535"} // no ports allows every port
+		}
+		for _, portSpec := range ports {
+			from, to, _ := strings.Cut(portSpec, "-")
+			if to == "" {
+				to = from
+			}
+			var err1, err2 error
+			r.fromPort, err1 = strconv.Atoi(from)
+			r.toPort, err2 = strconv.Atoi(to)
+			r.portsKnown = err1 == nil && err2 == nil
+			if e := exposure(r); e != "" && !contains(exposed, e) {
+				exposed = append(exposed, e)
+			}
+		}
+	}
+	if len(exposed) == 0 {
+		return nil
+	}
+	return []finding.Finding{{
+		File:     p,
+		Severity: finding.Error,
+		Message:  fmt.Sprintf("Firewall rule '%s' allows ingress from %s to %s", ref, open, strings.Join(exposed, ", ")),
+		Line:     body.SrcRange.Start.Line,
+	}}
+}
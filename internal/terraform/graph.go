@@ -0,0 +1,503 @@
+package terraform
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/graph"
+)
+
+// graphNode is a block of a module that other blocks can refer to.
+type graphNode struct {
+	address string // aws_s3_bucket.logs, data.aws_ami.base, module.net, output.id, var.region, local.tags
+	file    string
+	line    int
+	endLine int
+}
+
+// graphRef is a reference from the block at from to the address to, in the same module. A
+// reference to a module output names the output too.
+type graphRef struct {
+	from, to  string
+	output    string
+	file      string
+	line      int
+	dependsOn bool // listed in depends_on rather than used in an expression
+}
+
+// referenceGraph holds, per directory (a Terraform module), the blocks declared and the
+// references between them, across the files of the module and the modules it calls.
+type referenceGraph struct {
+	nodes map[string]map[string]graphNode
+	refs  map[string][]graphRef
+	calls []moduleCall // local module calls followed, in the order scanned
+}
+
+func newReferenceGraph() *referenceGraph {
+	return &referenceGraph{nodes: make(map[string]map[string]graphNode), refs: make(map[string][]graphRef)}
+}
+
+// addFile records the blocks of a file and what their expressions refer to. Provider
+// references, lifecycle ignore_changes paths, and moved, import and removed blocks, which
+// name addresses that need not exist, are not references.
+func (g *referenceGraph) addFile(p string, body hcl.Body) {
+	syntaxBody, ok := body.(*hclsyntax.Body)
+	if !ok {
+		return
+	}
+	dir := filepath.Dir(p)
+	if g.nodes[dir] == nil {
+		g.nodes[dir] = make(map[string]graphNode)
+	}
+	declare := func(address string, r hcl.Range) {
+		if _, seen := g.nodes[dir][address]; !seen {
+			g.nodes[dir][address] = graphNode{address: address, file: p, line: r.Start.Line, endLine: r.End.Line}
+		}
+	}
+
+	for _, block := range syntaxBody.Blocks {
+		r := hcl.RangeBetween(block.DefRange(), block.Body.SrcRange)
+		var address string
+		switch {
+		case block.Type == "resource" && len(block.Labels) == 2:
+			address = block.Labels[0] + "." + block.Labels[1]
+		case block.Type == "data" && len(block.Labels) == 2:
+			address = "data." + block.Labels[0] + "." + block.Labels[1]
+		case block.Type == "module" && len(block.Labels) == 1:
+			address = "module." + block.Labels[0]
+		case block.Type == "output" && len(block.Labels) == 1:
+			address = "output." + block.Labels[0]
+		case block.Type == "variable" && len(block.Labels) == 1:
+			declare("var."+block.Labels[0], r)
+			continue
+		case block.Type == "locals":
+			for _, name := range sortedKeys(block.Body.Attributes) {
+				attr := block.Body.Attributes[name]
+				declare("local."+name, attr.SrcRange)
+				g.addRefs(dir, p, "local."+name, attr.Expr, boundNames(block.Body), false)
+			}
+			continue
+		default:
+			continue
+		}
+		declare(address, r)
+		g.addBody(dir, p, address, block.Body, boundNames(block.Body))
+	}
+}
+
+// addBody records the references of the attributes and nested blocks of the block at from.
+func (g *referenceGraph) addBody(dir, p, from string, body *hclsyntax.Body, bound map[string]bool) {
+	for _, name := range sortedKeys(body.Attributes) {
+		switch name {
+		case "provider", "providers", "source", "version":
+			continue
+		}
+		attr := body.Attributes[name]
+		g.addRefs(dir, p, from, attr.Expr, bound, name == "depends_on")
+	}
+	for _, block := range body.Blocks {
+		if block.Type == "lifecycle" {
+			for _, name := range sortedKeys(block.Body.Attributes) {
+				if name != "ignore_changes" {
+					g.addRefs(dir, p, from, block.Body.Attributes[name].Expr, bound, false)
+				}
+			}
+			continue
+		}
+		g.addBody(dir, p, from, block.Body, bound)
+	}
+}
+
+func (g *referenceGraph) addRefs(dir, p, from string, expr hclsyntax.Expression, bound map[string]bool, dependsOn bool) {
+	for _, t := range expr.Variables() {
+		to, output, ok := referenceAddress(t, bound)
+		if !ok {
+			continue
+		}
+		g.refs[dir] = append(g.refs[dir], graphRef{from: from, to: to, output: output, file: p, line: t.SourceRange().Start.Line, dependsOn: dependsOn})
+	}
+}
+
+// referenceAddress returns the address a traversal refers to, and the output for a module
+// output. Traversals of names bound by for expressions and dynamic blocks, and of count,
+// each, self, path and terraform, are not references.
+func referenceAddress(t hcl.Traversal, bound map[string]bool) (address, output string, ok bool) {
+	root := t.RootName()
+	if bound[root] || len(t) < 2 {
+		return "", "", false
+	}
+	attr := func(i int) (string, bool) {
+		if i >= len(t) {
+			return "", false
+		}
+		step, ok := t[i].(hcl.TraverseAttr)
+		return step.Name, ok
+	}
+	name, ok := attr(1)
+	if !ok {
+		return "", "", false
+	}
+	switch root {
+	case "var", "local":
+		return root + "." + name, "", true
+	case "module":
+		output, _ := attr(2)
+		if _, indexed := t[min(2, len(t)-1)].(hcl.TraverseIndex); indexed {
+			output, _ = attr(3) // module.x[0].output
+		}
+		return "module." + name, output, true
+	case "data":
+		resource, ok := attr(2)
+		if !ok {
+			return "", "", false
+		}
+		return "data." + name + "." + resource, "", true
+	}
+	// resource types carry their provider's prefix, as in aws_instance
+	if !strings.Contains(root, "_") {
+		return "", "", false
+	}
+	return root + "." + name, "", true
+}
+
+// boundNames returns the names for expressions and dynamic blocks bind anywhere in body.
+func boundNames(body *hclsyntax.Body) map[string]bool {
+	bound := make(map[string]bool)
+	hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+		switch n := node.(type) {
+		case *hclsyntax.ForExpr:
+			if n.KeyVar != "" {
+				bound[n.KeyVar] = true
+			}
+			bound[n.ValVar] = true
+		case *hclsyntax.Block:
+			if n.Type == "dynamic" && len(n.Labels) == 1 {
+				bound[n.Labels[0]] = true
+				if it, ok := n.Body.Attributes["iterator"]; ok {
+					if t, diags := hcl.AbsTraversalForExpr(it.Expr); !diags.HasErrors() {
+						bound[t.RootName()] = true
+					}
+				}
+			}
+		}
+		return nil
+	})
+	return bound
+}
+
+// findings reports references to resources, data sources and modules their module does not
+// declare, dependency cycles between blocks, and outputs of modules that none of their
+// callers among calls refers to. Undeclared variables and locals are reported by
+// variableUsage.
+func (g *referenceGraph) findings(calls []moduleCall) []finding.Finding {
+	var findings []finding.Finding
+	for _, dir := range sortedKeys(g.refs) {
+		reported := make(map[string]bool)
+		for _, r := range g.refs[dir] {
+			if strings.HasPrefix(r.to, "var.") || strings.HasPrefix(r.to, "local.") {
+				continue
+			}
+			if _, ok := g.nodes[dir][r.to]; ok || reported[r.from+" "+r.to] {
+				continue
+			}
+			reported[r.from+" "+r.to] = true
+			findings = append(findings, finding.Finding{
+				File:     r.file,
+				Severity: finding.Error,
+				Message:  fmt.Sprintf("'%s' references undeclared %s '%s'", r.from, kindOf(r.to), r.to),
+				Line:     r.line,
+			})
+		}
+		findings = append(findings, g.cycles(dir)...)
+	}
+	return append(findings, g.orphanOutputs(calls)...)
+}
+
+// kindOf names the kind of block an address refers to.
+func kindOf(address string) string {
+	switch {
+	case strings.HasPrefix(address, "data."):
+		return "data source"
+	case strings.HasPrefix(address, "module."):
+		return "module"
+	case strings.HasPrefix(address, "output."):
+		return "output"
+	}
+	return "resource"
+}
+
+// cycles reports each set of blocks of dir that depend on each other in a circle, which
+// terraform cannot order, with one path around it. Edges listed in depends_on are marked.
+func (g *referenceGraph) cycles(dir string) []finding.Finding {
+	edges := make(map[string][]graphRef)
+	for _, r := range g.refs[dir] {
+		if _, ok := g.nodes[dir][r.to]; ok && !strings.HasPrefix(r.to, "var.") {
+			edges[r.from] = append(edges[r.from], r)
+		}
+	}
+
+	var findings []finding.Finding
+	for _, component := range stronglyConnected(sortedKeys(g.nodes[dir]), edges) {
+		path := cyclePath(component, edges)
+		if len(path) == 0 {
+			continue
+		}
+		steps := []string{path[0].from}
+		for _, r := range path {
+			step := r.to
+			if r.dependsOn {
+				step = "(depends_on) " + step
+			}
+			steps = append(steps, step)
+		}
+		findings = append(findings, finding.Finding{
+			File:     path[0].file,
+			Severity: finding.Error,
+			Message:  fmt.Sprintf("Dependency cycle: %s; terraform cannot order these blocks", strings.Join(steps, " -> ")),
+			Line:     path[0].line,
+		})
+	}
+	return findings
+}
+
+// stronglyConnected returns the strongly connected components of the graph that hold a cycle,
+// each sorted, in the order of their first node (Tarjan's algorithm).
+func stronglyConnected(nodes []string, edges map[string][]graphRef) [][]string {
+	index := make(map[string]int)
+	low := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var components [][]string
+
+	var visit func(n string)
+	visit = func(n string) {
+		index[n] = len(index)
+		low[n] = index[n]
+		stack = append(stack, n)
+		onStack[n] = true
+		for _, e := range edges[n] {
+			if _, seen := index[e.to]; !seen {
+				visit(e.to)
+				low[n] = min(low[n], low[e.to])
+			} else if onStack[e.to] {
+				low[n] = min(low[n], index[e.to])
+			}
+		}
+		if low[n] != index[n] {
+			return
+		}
+		var component []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == n {
+				break
+			}
+		}
+		if len(component) > 1 || selfLoop(n, edges) {
+			sort.Strings(component)
+			components = append(components, component)
+		}
+	}
+	for _, n := range nodes {
+		if _, seen := index[n]; !seen {
+			visit(n)
+		}
+	}
+	sort.Slice(components, func(i, j int) bool { return components[i][0] < components[j][0] })
+	return components
+}
+
+func selfLoop(n string, edges map[string][]graphRef) bool {
+	for _, e := range edges[n] {
+		if e.to == n {
+			return true
+		}
+	}
+	return false
+}
+
+// cyclePath returns the edges of a shortest cycle through the first node of component.
+func cyclePath(component []string, edges map[string][]graphRef) []graphRef {
+	in := make(map[string]bool, len(component))
+	for _, n := range component {
+		in[n] = true
+	}
+	start := component[0]
+	via := make(map[string]graphRef)
+	queue := []string{start}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for _, e := range edges[n] {
+			if !in[e.to] {
+				continue
+			}
+			if e.to == start {
+				path := []graphRef{e}
+				for at := n; at != start; at = via[at].from {
+					path = append([]graphRef{via[at]}, path...)
+				}
+				return path
+			}
+			if _, seen := via[e.to]; !seen {
+				via[e.to] = e
+				queue = append(queue, e.to)
+			}
+		}
+	}
+	return nil
+}
+
+// orphanOutputs reports the outputs of called modules that no call refers to; the callers
+// are named by scanWithModules. A module used whole, as in for_each = module.net, uses every
+// output. Modules scanned on their own have no callers in the scan and are left alone, as
+// their outputs are their interface.
+func (g *referenceGraph) orphanOutputs(calls []moduleCall) []finding.Finding {
+	callers := make(map[string][]moduleCall) // module dir -> calls
+	for _, c := range calls {
+		dir := filepath.Clean(c.dir)
+		callers[dir] = append(callers[dir], c)
+	}
+
+	var findings []finding.Finding
+	for _, dir := range sortedKeys(callers) {
+		used := make(map[string]bool)
+		whole := false
+		for _, c := range callers[dir] {
+			for _, r := range g.refs[filepath.Dir(c.file)] {
+				if r.to != "module."+c.name {
+					continue
+				}
+				if r.output == "" {
+					whole = true
+				}
+				used[r.output] = true
+			}
+		}
+		if whole {
+			continue
+		}
+		for _, address := range sortedKeys(g.nodes[dir]) {
+			name, ok := strings.CutPrefix(address, "output.")
+			if !ok || used[name] {
+				continue
+			}
+			n := g.nodes[dir][address]
+			findings = append(findings, finding.Finding{
+				File:     n.file,
+				Severity: finding.Info,
+				Message:  fmt.Sprintf("Output '%s' is not referenced by any caller of its module", name),
+				Line:     n.line,
+			})
+		}
+	}
+	return findings
+}
+
+// BuildGraph scans path like Scan and returns, with the findings, the graph of its resources,
+// data sources and module calls, including those of the local modules it calls. References
+// through locals are followed to the blocks the locals refer to; variables and outputs,
+// which cross module boundaries, become the edge from a module call to its module.
+func BuildGraph(path string) (*graph.Graph, []finding.Finding, error) {
+	g := newReferenceGraph()
+	findings, err := scanWithModules(path, g)
+	if err != nil {
+		return nil, findings, err
+	}
+	return g.export(), findings, nil
+}
+
+// exported reports whether address is a block drawn in an exported graph.
+func exported(address string) bool {
+	return !strings.HasPrefix(address, "var.") && !strings.HasPrefix(address, "local.") && !strings.HasPrefix(address, "output.")
+}
+
+func (g *referenceGraph) export() *graph.Graph {
+	out := &graph.Graph{}
+	id := func(dir, address string) string { return dir + ":" + address }
+	for _, dir := range sortedKeys(g.nodes) {
+		m := graph.Module{Dir: dir}
+		for _, address := range sortedKeys(g.nodes[dir]) {
+			if n := g.nodes[dir][address]; exported(address) {
+				m.Nodes = append(m.Nodes, graph.Node{ID: id(dir, address), Label: address, File: n.file, Line: n.line, EndLine: n.endLine})
+			}
+		}
+		if len(m.Nodes) > 0 {
+			out.Modules = append(out.Modules, m)
+		}
+	}
+
+	for _, m := range out.Modules {
+		from := make(map[string][]graphRef)
+		for _, r := range g.refs[m.Dir] {
+			from[r.from] = append(from[r.from], r)
+		}
+		for _, n := range m.Nodes {
+			out.Edges = append(out.Edges, g.exportEdges(m.Dir, n.Label, from, id)...)
+		}
+	}
+
+	drawn := make(map[string]bool)
+	for _, m := range out.Modules {
+		drawn[m.Dir] = true
+	}
+	called := make(map[string]bool)
+	for _, c := range g.calls {
+		dir, to := filepath.Dir(c.file), filepath.Clean(c.dir)
+		key := id(dir, "module."+c.name) + " " + to
+		if called[key] || !drawn[to] {
+			continue
+		}
+		called[key] = true
+		out.Edges = append(out.Edges, graph.Edge{From: id(dir, "module."+c.name), To: to, Call: true})
+	}
+	return out
+}
+
+// exportEdges returns the edges from the block at address to the exported blocks it refers
+// to, directly or through locals. An edge only listed in depends_on is marked as such.
+func (g *referenceGraph) exportEdges(dir, address string, from map[string][]graphRef, id func(dir, address string) string) []graph.Edge {
+	dependsOn := make(map[string]bool) // target -> reached through depends_on only
+	var order []string
+	visited := map[string]bool{address: true}
+	var walk func(at string)
+	walk = func(at string) {
+		for _, r := range from[at] {
+			if _, ok := g.nodes[dir][r.to]; !ok {
+				continue
+			}
+			if strings.HasPrefix(r.to, "local.") {
+				if !visited[r.to] {
+					visited[r.to] = true
+					walk(r.to)
+				}
+				continue
+			}
+			if !exported(r.to) || r.to == address {
+				continue
+			}
+			only, seen := dependsOn[r.to]
+			if !seen {
+				order = append(order, r.to)
+			}
+			dependsOn[r.to] = r.dependsOn && (only || !seen)
+		}
+	}
+	walk(address)
+
+	sort.Strings(order)
+	edges := make([]graph.Edge, 0, len(order))
+	for _, to := range order {
+		edges = append(edges, graph.Edge{From: id(dir, address), To: id(dir, to), DependsOn: dependsOn[to]})
+	}
+	return edges
+}
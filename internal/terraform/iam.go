@@ -0,0 +1,300 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// Attributes holding IAM policy JSON, per resource type.
+var policyAttributesByType = map[string][]string{
+	"aws_iam_policy":       {"policy"},
+	"aws_iam_role":         {"assume_role_policy"},
+	"aws_iam_role_policy":  {"policy"},
+	"aws_iam_user_policy":  {"policy"},
+	"aws_iam_group_policy": {"policy"},
+	"aws_s3_bucket_policy": {"policy"},
+	"aws_sqs_queue_policy": {"policy"},
+	"aws_sns_topic_policy": {"policy"},
+	"aws_kms_key":          {"policy"},
+}
+
+// policyStatement is the part of an IAM statement the checks need, whether it came from
+// policy JSON or an aws_iam_policy_document data source.
+type policyStatement struct {
+	effect      string
+	actions     []string
+	resources   []string
+	principals  []string
+	conditional bool
+	line        int
+}
+
+// checkIAMPolicies analyses the policy documents a resource carries.
+func checkIAMPolicies(p, resourceType, resourceName string, body hcl.Body) []finding.Finding {
+	syntaxBody, ok := body.(*hclsyntax.Body)
+	if !ok {
+		return nil
+	}
+	ref := fmt.Sprintf("%s.%s", resourceType, resourceName)
+
+	var findings []finding.Finding
+	for _, name := range policyAttributesByType[resourceType] {
+		if attr, ok := syntaxBody.Attributes[name]; ok {
+			findings = append(findings, checkPolicyExpr(p, ref+" "+name, attr)...)
+		}
+	}
+	if resourceType == "aws_iam_role" {
+		for _, block := range syntaxBody.Blocks {
+			if attr, ok := block.Body.Attributes["policy"]; ok && block.Type == "inline_policy" {
+				findings = append(findings, checkPolicyExpr(p, ref+" inline_policy", attr)...)
+			}
+		}
+	}
+	return findings
+}
+
+// checkPolicyDocument analyses a data "aws_iam_policy_document" block.
+func checkPolicyDocument(p, name string, body hcl.Body) []finding.Finding {
+	syntaxBody, ok := body.(*hclsyntax.Body)
+	if !ok {
+		return nil
+	}
+	var statements []policyStatement
+	for _, block := range syntaxBody.Blocks {
+		if block.Type != "statement" {
+			continue
+		}
+		s := policyStatement{
+			effect:    stringAttr(block.Body, "effect"),
+			actions:   stringList(block.Body, "actions"),
+			resources: stringList(block.Body, "resources"),
+			line:      block.DefRange().Start.Line,
+		}
+		for _, inner := range block.Body.Blocks {
+			switch inner.Type {
+			case "principals":
+				if stringAttr(inner.Body, "type") == "*" {
+					s.principals = append(s.principals, "*")
+				}
+				s.principals = append(s.principals, stringList(inner.Body, "identifiers")...)
+			case "condition":
+				s.conditional = true
+			}
+		}
+		statements = append(statements, s)
+	}
+	return analyzeStatements(p, "data.aws_iam_policy_document."+name, statements)
+}
+
+// checkPolicyExpr analyses a policy given as a JSON string, a heredoc or jsonencode().
+func checkPolicyExpr(p, label string, attr *hclsyntax.Attribute) []finding.Finding {
+	doc, ok := policyValue(attr.Expr)
+	if !ok {
+		return nil
+	}
+	return analyzeStatements(p, label, documentStatements(doc, attr.SrcRange.Start.Line))
+}
+
+// documentStatements extracts the statements of a decoded policy document.
+func documentStatements(doc map[string]interface{}, line int) []policyStatement {
+	var statements []policyStatement
+	for _, raw := range asList(lookup(doc, "Statement")) {
+		st, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		s := policyStatement{
+			effect:    stringOf(lookup(st, "Effect")),
+			actions:   stringsOf(lookup(st, "Action")),
+			resources: stringsOf(lookup(st, "Resource")),
+			line:      line,
+		}
+		switch principal := lookup(st, "Principal").(type) {
+		case string:
+			s.principals = []string{principal}
+		case map[string]interface{}:
+			for _, v := range principal {
+				s.principals = append(s.principals, stringsOf(v)...)
+			}
+		}
+		_, s.conditional = st["Condition"]
+		statements = append(statements, s)
+	}
+	return statements
+}
+
+// analyzeStatements flags Allow statements granting every action, every action on every
+// resource, or access to any principal.
+func analyzeStatements(p, label string, statements []policyStatement) []finding.Finding {
+	var findings []finding.Finding
+	add := func(sev finding.Severity, line int, format string, args ...interface{}) {
+		findings = append(findings, finding.Finding{File: p, Severity: sev, Message: fmt.Sprintf(format, args...), Line: line})
+	}
+	for _, s := range statements {
+		if s.effect != "" && !strings.EqualFold(s.effect, "Allow") {
+			continue
+		}
+		allActions := contains(s.actions, "*")
+		allResources := contains(s.resources, "*")
+		switch {
+		case allActions && allResources:
+			add(finding.Error, s.line, "IAM policy %s allows Action \"*\" on Resource \"*\" (full administrator access)", label)
+		case allActions:
+			add(finding.Error, s.line, "IAM policy %s allows Action \"*\"; grant only the actions needed", label)
+		case allResources:
+			if wild := serviceWildcards(s.actions); len(wild) > 0 {
+				add(finding.Warning, s.line, "IAM policy %s allows %s on Resource \"*\"", label, strings.Join(wild, ", "))
+			}
+		}
+		if contains(s.principals, "*") {
+			if s.conditional {
+				add(finding.Warning, s.line, "IAM policy %s allows Principal \"*\"; make sure its conditions restrict who can use it", label)
+			} else {
+				add(finding.Error, s.line, "IAM policy %s allows Principal \"*\" without conditions (anyone can use it)", label)
+			}
+		}
+	}
+	return findings
+}
+
+func serviceWildcards(actions []string) []string {
+	var out []string
+	for _, a := range actions {
+		if strings.HasSuffix(a, ":*") {
+			out = append(out, fmt.Sprintf("\"%s\"", a))
+		}
+	}
+	return out
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// policyValue evaluates a policy expression to its decoded JSON document. References to
+// other resources or variables evaluate to unknown values and are left out of the document.
+func policyValue(expr hclsyntax.Expression) (map[string]interface{}, bool) {
+	if call, ok := expr.(*hclsyntax.FunctionCallExpr); ok && call.Name == "jsonencode" && len(call.Args) == 1 {
+		val, ok := evalPartial(call.Args[0])
+		if !ok {
+			return nil, false
+		}
+		doc, ok := goValue(val).(map[string]interface{})
+		return doc, ok
+	}
+
+	val, ok := evalPartial(expr)
+	if !ok || !val.IsKnown() || val.IsNull() || val.Type() != cty.String {
+		return nil, false
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(val.AsString()), &doc); err != nil {
+		return nil, false
+	}
+	return doc, true
+}
+
+// evalPartial evaluates expr with every referenced name bound to an unknown value.
+func evalPartial(expr hclsyntax.Expression) (cty.Value, bool) {
+	ctx := &hcl.EvalContext{Variables: make(map[string]cty.Value)}
+	for _, traversal := range expr.Variables() {
+		ctx.Variables[traversal.RootName()] = cty.DynamicVal
+	}
+	val, diags := expr.Value(ctx)
+	return val, !diags.HasErrors()
+}
+
+// goValue converts a cty value to the shapes encoding/json decodes into; unknown values become nil.
+func goValue(v cty.Value) interface{} {
+	if !v.IsKnown() || v.IsNull() {
+		return nil
+	}
+	t := v.Type()
+	switch {
+	case t == cty.String:
+		return v.AsString()
+	case t == cty.Bool:
+		return v.True()
+	case t == cty.Number:
+		f, _ := v.AsBigFloat().Float64()
+		return f
+	case t.IsObjectType() || t.IsMapType():
+		out := make(map[string]interface{})
+		for it := v.ElementIterator(); it.Next(); {
+			k, e := it.Element()
+			out[k.AsString()] = goValue(e)
+		}
+		return out
+	case v.CanIterateElements():
+		var out []interface{}
+		for it := v.ElementIterator(); it.Next(); {
+			_, e := it.Element()
+			out = append(out, goValue(e))
+		}
+		return out
+	}
+	return nil
+}
+
+// lookup returns m[key], matching the key case-insensitively like IAM does for element names.
+func lookup(m map[string]interface{}, key string) interface{} {
+	if v, ok := m[key]; ok {
+		return v
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return nil
+}
+
+func asList(v interface{}) []interface{} {
+	switch t := v.(type) {
+	case []interface{}:
+		return t
+	case nil:
+		return nil
+	}
+	return []interface{}{v}
+}
+
+func stringOf(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+func stringsOf(v interface{}) []string {
+	var out []string
+	for _, e := range asList(v) {
+		if s, ok := e.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// stringList returns the known string elements of a list attribute.
+func stringList(body *hclsyntax.Body, name string) []string {
+	attr, ok := body.Attributes[name]
+	if !ok {
+		return nil
+	}
+	val, ok := evalPartial(attr.Expr)
+	if !ok {
+		return nil
+	}
+	return stringsOf(goValue(val))
+}
@@ -0,0 +1,200 @@
+package terraform
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// Attributes whose drift is a security concern; ignoring changes to them hides
+// out-of-band policy or firewall edits forever.
+var securityRelevantAttributes = map[string]bool{
+	"policy":                               true,
+	"assume_role_policy":                   true,
+	"inline_policy":                        true,
+	"managed_policy_arns":                  true,
+	"ingress":                              true,
+	"egress":                               true,
+	"cidr_blocks":                          true,
+	"ipv6_cidr_blocks":                     true,
+	"security_groups":                      true,
+	"vpc_security_group_ids":               true,
+	"acl":                                  true,
+	"grant":                                true,
+	"iam_instance_profile":                 true,
+	"kms_key_id":                           true,
+	"encrypted":                            true,
+	"storage_encrypted":                    true,
+	"server_side_encryption_configuration": true,
+	"publicly_accessible":                  true,
+}
+
+// Functions that return a different value on every plan.
+var volatileFunctions = map[string]bool{
+	"timestamp":     true,
+	"plantimestamp": true,
+	"uuid":          true,
+	"bcrypt":        true,
+}
+
+// Computed attributes that change on every apply of the referenced resource, so
+// anything configured from them is updated (or replaced) on the next apply too.
+var volatileAttributes = map[string]bool{
+	"last_modified":  true,
+	"latest_version": true,
+	"revision":       true,
+}
+
+// checkLifecycle inspects a resource body for lifecycle blocks and values that stop
+// applies from converging: ignore_changes = all, ignored security attributes, and
+// attributes driven by volatile functions or volatile computed attributes.
+func checkLifecycle(p, resourceType, resourceName string, body hcl.Body) []finding.Finding {
+	var findings []finding.Finding
+	syntaxBody, ok := body.(*hclsyntax.Body)
+	if !ok {
+		return nil
+	}
+	ref := fmt.Sprintf("%s.%s", resourceType, resourceName)
+
+	ignored := make(map[string]bool)
+	ignoreAll := false
+	for _, block := range syntaxBody.Blocks {
+		if block.Type != "lifecycle" {
+			continue
+		}
+		attr, exists := block.Body.Attributes["ignore_changes"]
+		if !exists {
+			continue
+		}
+		all, names := ignoreChangesTargets(attr.Expr)
+		if all {
+			ignoreAll = true
+			findings = append(findings, finding.Finding{
+				File:     p,
+				Severity: finding.Warning,
+				Message:  fmt.Sprintf("Resource '%s' sets lifecycle ignore_changes = all; Terraform will never correct drift on any attribute", ref),
+				Line:     attr.SrcRange.Start.Line,
+			})
+			continue
+		}
+		for _, name := range names {
+			ignored[name] = true
+			if securityRelevantAttributes[name] {
+				findings = append(findings, finding.Finding{
+					File:     p,
+					Severity: finding.Warning,
+					Message:  fmt.Sprintf("Resource '%s' ignores changes to security-relevant attribute '%s'; out-of-band edits to it will go unnoticed", ref, name),
+					Line:     attr.SrcRange.Start.Line,
+				})
+			}
+		}
+	}
+	if ignoreAll {
+		return findings
+	}
+
+	for _, name := range sortedAttributeNames(syntaxBody) {
+		if ignored[name] {
+			continue
+		}
+		findings = append(findings, checkVolatileExpr(p, ref, name, syntaxBody.Attributes[name].Expr)...)
+	}
+	for _, block := range syntaxBody.Blocks {
+		if block.Type == "lifecycle" || ignored[block.Type] {
+			continue
+		}
+		for _, name := range sortedAttributeNames(block.Body) {
+			findings = append(findings, checkVolatileExpr(p, ref, block.Type+"."+name, block.Body.Attributes[name].Expr)...)
+		}
+	}
+
+	return findings
+}
+
+// ignoreChangesTargets returns whether ignore_changes covers everything, and otherwise
+// the root attribute names it lists. Both the keyword form (all, [tags]) and the legacy
+// string form ("*", ["tags"]) are understood.
+func ignoreChangesTargets(expr hcl.Expression) (bool, []string) {
+	if hcl.ExprAsKeyword(expr) == "all" {
+		return true, nil
+	}
+	exprs, diags := hcl.ExprList(expr)
+	if diags.HasErrors() {
+		return false, nil
+	}
+	var names []string
+	for _, e := range exprs {
+		if traversal, diags := hcl.AbsTraversalForExpr(e); !diags.HasErrors() {
+			names = append(names, traversal.RootName())
+			continue
+		}
+		val, diags := e.Value(nil)
+		if diags.HasErrors() || val.Type() != cty.String {
+			continue
+		}
+		if val.AsString() == "*" {
+			return true, nil
+		}
+		names = append(names, val.AsString())
+	}
+	return false, names
+}
+
+// checkVolatileExpr reports an attribute whose value changes on every plan.
+func checkVolatileExpr(p, ref, attrName string, expr hclsyntax.Expression) []finding.Finding {
+	var findings []finding.Finding
+	reported := make(map[string]bool)
+	hclsyntax.VisitAll(expr, func(node hclsyntax.Node) hcl.Diagnostics {
+		switch n := node.(type) {
+		case *hclsyntax.FunctionCallExpr:
+			if volatileFunctions[n.Name] && !reported[n.Name] {
+				reported[n.Name] = true
+				findings = append(findings, finding.Finding{
+					File:     p,
+					Severity: finding.Warning,
+					Message:  fmt.Sprintf("Resource '%s' attribute '%s' uses %s(), which changes on every plan and forces a perpetual diff (add it to lifecycle ignore_changes or use a stable value)", ref, attrName, n.Name),
+					Line:     n.Range().Start.Line,
+				})
+			}
+		case *hclsyntax.ScopeTraversalExpr:
+			t := n.Traversal
+			if len(t) < 3 {
+				return nil
+			}
+			attr, ok := t[2].(hcl.TraverseAttr)
+			if !ok || !volatileAttributes[attr.Name] {
+				return nil
+			}
+			target := t.RootName()
+			if step, ok := t[1].(hcl.TraverseAttr); ok {
+				target += "." + step.Name
+			}
+			key := target + "." + attr.Name
+			if !reported[key] {
+				reported[key] = true
+				findings = append(findings, finding.Finding{
+					File:     p,
+					Severity: finding.Info,
+					Message:  fmt.Sprintf("Resource '%s' attribute '%s' references '%s', which changes on every apply of %s and keeps this resource from converging", ref, attrName, key, target),
+					Line:     n.Range().Start.Line,
+				})
+			}
+		}
+		return nil
+	})
+	return findings
+}
+
+func sortedAttributeNames(body *hclsyntax.Body) []string {
+	names := make([]string, 0, len(body.Attributes))
+	for name := range body.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
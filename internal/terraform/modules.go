@@ -0,0 +1,133 @@
+package terraform
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// moduleCall is a module block with a local source.
+type moduleCall struct {
+	name   string
+	source string
+	file   string
+	line   int
+	dir    string // the module directory, resolved relative to the calling file
+}
+
+func (c moduleCall) site() string {
+	return fmt.Sprintf("module.%s at %s:%d", c.name, c.file, c.line)
+}
+
+// localModuleCall returns the call of a module block whose source is a local path.
+// Registry, git and other remote sources are not resolved.
+func localModuleCall(p string, block *hcl.Block) (moduleCall, bool) {
+	body, ok := block.Body.(*hclsyntax.Body)
+	if !ok || len(block.Labels) != 1 {
+		return moduleCall{}, false
+	}
+	attr, ok := body.Attributes["source"]
+	if !ok {
+		return moduleCall{}, false
+	}
+	val, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || val.IsNull() || val.Type() != cty.String {
+		return moduleCall{}, false
+	}
+	source := val.AsString()
+	if !strings.HasPrefix(source, "./") && !strings.HasPrefix(source, "../") {
+		return moduleCall{}, false
+	}
+	return moduleCall{
+		name:   block.Labels[0],
+		source: source,
+		file:   p,
+		line:   block.DefRange.Start.Line,
+		dir:    filepath.Join(filepath.Dir(p), source),
+	}, true
+}
+
+// scanWithModules scans path and then every local module it calls that lies outside the
+// scanned trees, following calls transitively. Findings in module files name the calling
+// sites, so a problem in a shared module is traceable to each stack using it. The blocks
+// and references of every module scanned are added to graph.
+func scanWithModules(path string, graph *referenceGraph) ([]finding.Finding, error) {
+	findings, queue, err := scanTree(path, graph)
+	if err != nil {
+		return findings, err
+	}
+
+	scanned := []string{absPath(path)}
+	sites := make(map[string][]string) // absolute module dir -> calling sites
+	for len(queue) > 0 {
+		call := queue[0]
+		queue = queue[1:]
+		graph.calls = append(graph.calls, call)
+		dir := absPath(call.dir)
+		sites[dir] = append(sites[dir], call.site())
+		if covered(dir, scanned) {
+			continue
+		}
+
+		if info, err := os.Stat(call.dir); err != nil || !info.IsDir() {
+			findings = append(findings, finding.Finding{
+				File:     call.file,
+				Severity: finding.Error,
+				Message:  fmt.Sprintf("Module '%s' source '%s' does not exist", call.name, call.source),
+				Line:     call.line,
+			})
+			continue
+		}
+		scanned = append(scanned, dir)
+		moduleFindings, more, err := scanTree(call.dir, graph)
+		if err != nil {
+			return findings, err
+		}
+		findings = append(findings, moduleFindings...)
+		queue = append(queue, more...)
+	}
+	findings = append(findings, graph.findings(graph.calls)...)
+
+	for i, f := range findings {
+		if callers := sites[absPath(filepath.Dir(f.File))]; len(callers) > 0 {
+			sort.Strings(callers)
+			findings[i].Message = fmt.Sprintf("%s (via %s)", f.Message, strings.Join(dedupe(callers), ", "))
+		}
+	}
+	return findings, nil
+}
+
+// covered reports whether dir lies within one of the scanned roots.
+func covered(dir string, roots []string) bool {
+	for _, root := range roots {
+		if dir == root || strings.HasPrefix(dir, root+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+func absPath(p string) string {
+	if abs, err := filepath.Abs(p); err == nil {
+		return abs
+	}
+	return filepath.Clean(p)
+}
+
+func dedupe(sorted []string) []string {
+	var out []string
+	for i, s := range sorted {
+		if i == 0 || s != sorted[i-1] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
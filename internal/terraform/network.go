@@ -0,0 +1,182 @@
+package terraform
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// Ports that must never be reachable from the whole internet.
+var sensitivePorts = map[int]string{
+	22:   "SSH",
+	3389: "RDP",
+}
+
+// CIDRs covering every address.
+var worldCIDRs = map[string]bool{"0.0.0.0/0": true, "::/0": true}
+
+// ingressRule is one ingress permission, however it is declared.
+type ingressRule struct {
+	protocol         string
+	fromPort, toPort int
+	portsKnown       bool
+	cidrs            []string
+	line             int
+}
+
+// checkSecurityGroup reports ingress open to the world on sensitive ports or on all ports,
+// for inline ingress blocks of aws_security_group and for standalone rule resources.
+func checkSecurityGroup(p, resourceType, resourceName string, body hcl.Body) []finding.Finding {
+	syntaxBody, ok := body.(*hclsyntax.Body)
+	if !ok {
+		return nil
+	}
+	ref := fmt.Sprintf("%s.%s", resourceType, resourceName)
+
+	var rules []ingressRule
+	switch resourceType {
+	case "aws_security_group":
+		for _, block := range syntaxBody.Blocks {
+			if block.Type == "ingress" {
+				rules = append(rules, readIngress(block.Body, block.DefRange().Start.Line, "cidr_blocks", "ipv6_cidr_blocks"))
+			}
+		}
+	case "aws_security_group_rule":
+		if stringAttr(syntaxBody, "type") != "ingress" {
+			return nil
+		}
+		rules = append(rules, readIngress(syntaxBody, syntaxBody.SrcRange.Start.Line, "cidr_blocks", "ipv6_cidr_blocks"))
+	case "aws_vpc_security_group_ingress_rule":
+		r := readIngress(syntaxBody, syntaxBody.SrcRange.Start.Line, "cidr_ipv4", "cidr_ipv6")
+		if r.protocol == "-1" && !r.portsKnown {
+			r.fromPort, r.toPort, r.portsKnown = 0, 65535, true // ports are omitted for all traffic
+		}
+		rules = append(rules, r)
+	default:
+		return nil
+	}
+
+	var findings []finding.Finding
+	for _, r := range rules {
+		open := worldOpen(r.cidrs)
+		if open == "" {
+			continue
+		}
+		if exposed := exposure(r); exposed != "" {
+			findings = append(findings, finding.Finding{
+				File:     p,
+				Severity: finding.Error,
+				Message:  fmt.Sprintf("Security group '%s' allows ingress from %s to %s", ref, open, exposed),
+				Line:     r.line,
+			})
+		}
+	}
+	return findings
+}
+
+// readIngress extracts the literal protocol, port range and CIDRs of an ingress rule.
+func readIngress(body *hclsyntax.Body, line int, cidrAttrs ...string) ingressRule {
+	r := ingressRule{protocol: strings.ToLower(stringAttr(body, "ip_protocol")), line: line}
+	if r.protocol == "" {
+		r.protocol = strings.ToLower(stringAttr(body, "protocol"))
+	}
+	if r.protocol == "all" {
+		r.protocol = "-1"
+	}
+	from, fromOK := numberAttr(body, "from_port")
+	to, toOK := numberAttr(body, "to_port")
+	r.fromPort, r.toPort, r.portsKnown = from, to, fromOK && toOK
+
+	for _, name := range cidrAttrs {
+		attr, ok := body.Attributes[name]
+		if !ok {
+			continue
+		}
+		val, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() || val.IsNull() || !val.IsKnown() {
+			continue
+		}
+		switch {
+		case val.Type() == cty.String:
+			r.cidrs = append(r.cidrs, val.AsString())
+		case val.CanIterateElements():
+			for it := val.ElementIterator(); it.Next(); {
+				_, v := it.Element()
+				if v.IsKnown() && !v.IsNull() && v.Type() == cty.String {
+					r.cidrs = append(r.cidrs, v.AsString())
+				}
+			}
+		}
+	}
+	return r
+}
+
+// exposure describes what an ingress rule opens that is sensitive, or "" for nothing.
+func exposure(r ingressRule) string {
+	if r.protocol == "-1" || (r.portsKnown && r.fromPort <= 0 && r.toPort >= 65535) {
+		return "all ports"
+	}
+	if !r.portsKnown {
+		return ""
+	}
+	var exposed []string
+	for _, port := range []int{22, 3389} {
+		if r.fromPort <= port && port <= r.toPort {
+			exposed = append(exposed, fmt.Sprintf("%s (%d)", sensitivePorts[port], port))
+		}
+	}
+	if len(exposed) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s via port range %s", strings.Join(exposed, " and "), portRange(r.fromPort, r.toPort))
+}
+
+func portRange(from, to int) string {
+	if from == to {
+		return fmt.Sprint(from)
+	}
+	return fmt.Sprintf("%d-%d", from, to)
+}
+
+// worldOpen returns the world-open CIDRs among cidrs, joined for display.
+func worldOpen(cidrs []string) string {
+	var open []string
+	for _, c := range cidrs {
+		if worldCIDRs[c] {
+			open = append(open, c)
+		}
+	}
+	return strings.Join(open, ", ")
+}
+
+// stringAttr returns the literal string value of an attribute, or "".
+func stringAttr(body *hclsyntax.Body, name string) string {
+	attr, ok := body.Attributes[name]
+	if !ok {
+		return ""
+	}
+	val, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || val.IsNull() || !val.IsKnown() || val.Type() != cty.String {
+		return ""
+	}
+	return val.AsString()
+}
+
+// numberAttr returns the literal integer value of an attribute.
+func numberAttr(body *hclsyntax.Body, name string) (int, bool) {
+	attr, ok := body.Attributes[name]
+	if !ok {
+		return 0, false
+	}
+	val, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || val.IsNull() || !val.IsKnown() || val.Type() != cty.Number {
+		return 0, false
+	}
+	n, _ := val.AsBigFloat().Int64()
+	return int(n), true
+}
@@ -0,0 +1,206 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// Plan scanning runs the resource checks against `terraform show -json` output, where
+// values from variables, locals and modules are already resolved. Nothing is known about
+// source positions, so findings point at the plan file and name the resource address.
+
+// plan is the subset of the JSON plan representation the checks need.
+type plan struct {
+	FormatVersion    string `json:"format_version"`
+	TerraformVersion string `json:"terraform_version"`
+	PlannedValues    struct {
+		RootModule planModule `json:"root_module"`
+	} `json:"planned_values"`
+}
+
+type planModule struct {
+	Address      string         `json:"address"`
+	Resources    []planResource `json:"resources"`
+	ChildModules []planModule   `json:"child_modules"`
+}
+
+type planResource struct {
+	Address string                 `json:"address"`
+	Mode    string                 `json:"mode"`
+	Type    string                 `json:"type"`
+	Name    string                 `json:"name"`
+	Values  map[string]interface{} `json:"values"`
+}
+
+// IsPlanFile reports whether data is a JSON plan from `terraform show -json`.
+func IsPlanFile(data []byte) bool {
+	var pl plan
+	return json.Unmarshal(data, &pl) == nil && pl.FormatVersion != "" && pl.TerraformVersion != ""
+}
+
+// ScanPlan checks the JSON plan at path, or every JSON plan under a directory.
+func ScanPlan(path string) ([]finding.Finding, error) {
+	var findings []finding.Finding
+	err := scanner.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		if filepath.Ext(p) != ".json" {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if !IsPlanFile(data) {
+			if p == path {
+				return fmt.Errorf("%s is not a Terraform JSON plan (create one with 'terraform show -json plan.out')", p)
+			}
+			return nil
+		}
+		var pl plan
+		if err := json.Unmarshal(data, &pl); err != nil {
+			return fmt.Errorf("%s: %v", p, err)
+		}
+		findings = append(findings, checkPlanModule(p, pl.PlannedValues.RootModule)...)
+		return nil
+	})
+	return findings, err
+}
+
+func checkPlanModule(p string, m planModule) []finding.Finding {
+	var findings []finding.Finding
+	for _, r := range m.Resources {
+		if r.Mode == "managed" {
+			findings = append(findings, checkPlanResource(p, r)...)
+		}
+	}
+	for _, child := range m.ChildModules {
+		findings = append(findings, checkPlanModule(p, child)...)
+	}
+	return findings
+}
+
+// checkPlanResource runs the resolved-value counterparts of the HCL resource checks.
+func checkPlanResource(p string, r planResource) []finding.Finding {
+	var findings []finding.Finding
+	add := func(sev finding.Severity, format string, args ...interface{}) {
+		findings = append(findings, finding.Finding{File: p, Severity: sev, Message: fmt.Sprintf(format, args...)})
+	}
+	v := r.Values
+
+	if msg, deprecated := deprecatedResources[r.Type]; deprecated {
+		add(finding.Warning, "Resource '%s' uses deprecated type '%s': %s", r.Address, r.Type, msg)
+	}
+
+	// Only taggable resources carry a tags key, null when no tags are set.
+	if raw, taggable := v["tags"]; taggable {
+		tags, _ := raw.(map[string]interface{})
+		if len(tags) == 0 {
+			if all, _ := v["tags_all"].(map[string]interface{}); len(all) == 0 {
+				add(finding.Warning, "Resource '%s' has no tags", r.Address)
+			}
+		}
+		for _, tag := range requiredTags {
+			if _, ok := tags[tag]; len(tags) > 0 && !ok {
+				if all, _ := v["tags_all"].(map[string]interface{}); all[tag] == nil {
+					add(finding.Warning, "Resource '%s' missing required tag '%s'", r.Address, tag)
+				}
+			}
+		}
+	}
+
+	if acl := stringOf(v["acl"]); r.Type == "aws_s3_bucket" && (acl == "public-read" || acl == "public-read-write") {
+		add(finding.Warning, "S3 bucket '%s' ACL is set to %s (publicly accessible)", r.Address, acl)
+	}
+
+	if flag, ok := encryptionFlags[r.Type]; ok && v[flag] != true {
+		add(finding.Error, "Resource '%s' is planned with %s = false; its data is stored unencrypted at rest", r.Address, flag)
+	}
+	switch r.Type {
+	case "aws_sqs_queue":
+		if v["kms_master_key_id"] == nil && v["sqs_managed_sse_enabled"] == false {
+			add(finding.Warning, "Resource '%s' is planned without SSE-SQS or a KMS key; messages are stored unencrypted", r.Address)
+		}
+	case "aws_sns_topic":
+		if stringOf(v["kms_master_key_id"]) == "" {
+			add(finding.Warning, "Resource '%s' is planned without kms_master_key_id; messages are stored unencrypted", r.Address)
+		}
+	case "tls_private_key":
+		switch strings.ToUpper(stringOf(v["algorithm"])) {
+		case "RSA":
+			if bits, ok := v["rsa_bits"].(float64); ok && int(bits) < minRSABits {
+				add(finding.Error, "Resource '%s' generates a %d-bit RSA key; use at least %d bits", r.Address, int(bits), minRSABits)
+			}
+		case "ECDSA":
+			if curve := strings.ToUpper(stringOf(v["ecdsa_curve"])); weakCurves[curve] {
+				add(finding.Error, "Resource '%s' generates an ECDSA key on the weak %s curve; use P256 or stronger", r.Address, curve)
+			}
+		}
+	}
+
+	for _, rule := range planIngress(r) {
+		if open := worldOpen(rule.cidrs); open != "" {
+			if exposed := exposure(rule); exposed != "" {
+				add(finding.Error, "Security group '%s' allows ingress from %s to %s", r.Address, open, exposed)
+			}
+		}
+	}
+
+	for _, name := range policyAttributesByType[r.Type] {
+		var doc map[string]interface{}
+		if err := json.Unmarshal([]byte(stringOf(v[name])), &doc); err == nil {
+			findings = append(findings, analyzeStatements(p, r.Address+" "+name, documentStatements(doc, 0))...)
+		}
+	}
+	return findings
+}
+
+// planIngress returns the ingress permissions of a security group or rule resource.
+func planIngress(r planResource) []ingressRule {
+	v := r.Values
+	fromValues := func(m map[string]interface{}, cidrKeys ...string) ingressRule {
+		rule := ingressRule{protocol: strings.ToLower(stringOf(m["protocol"]))}
+		if rule.protocol == "" {
+			rule.protocol = strings.ToLower(stringOf(m["ip_protocol"]))
+		}
+		if rule.protocol == "all" {
+			rule.protocol = "-1"
+		}
+		from, fromOK := m["from_port"].(float64)
+		to, toOK := m["to_port"].(float64)
+		rule.fromPort, rule.toPort, rule.portsKnown = int(from), int(to), fromOK && toOK
+		for _, key := range cidrKeys {
+			rule.cidrs = append(rule.cidrs, stringsOf(m[key])...)
+		}
+		return rule
+	}
+
+	switch r.Type {
+	case "aws_security_group":
+		var rules []ingressRule
+		for _, raw := range asList(v["ingress"]) {
+			if m, ok := raw.(map[string]interface{}); ok {
+				rules = append(rules, fromValues(m, "cidr_blocks", "ipv6_cidr_blocks"))
+			}
+		}
+		return rules
+	case "aws_security_group_rule":
+		if stringOf(v["type"]) == "ingress" {
+			return []ingressRule{fromValues(v, "cidr_blocks", "ipv6_cidr_blocks")}
+		}
+	case "aws_vpc_security_group_ingress_rule":
+		rule := fromValues(v, "cidr_ipv4", "cidr_ipv6")
+		if rule.protocol == "-1" && !rule.portsKnown {
+			rule.fromPort, rule.toPort, rule.portsKnown = 0, 65535, true
+		}
+		return []ingressRule{rule}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package terraform
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// Provider feature flags that switch off safety checks. They are useful against local
+// emulators such as LocalStack but should never reach configurations applied to real accounts.
+var riskyProviderFlags = []struct {
+	name     string
+	severity finding.Severity
+	reason   string
+}{
+	{"skip_credentials_validation", finding.Warning, "credentials are never validated with STS, so a wrong or leaked key set goes unnoticed until the first apply"},
+	{"skip_requesting_account_id", finding.Warning, "the account ID is never looked up, so allowed_account_ids/forbidden_account_ids cannot protect against applying to the wrong account"},
+	{"skip_metadata_api_check", finding.Info, "the instance metadata endpoint is not consulted, hiding the instance role actually in use"},
+	{"insecure", finding.Error, "TLS certificate verification is disabled, exposing credentials to man-in-the-middle attacks"},
+}
+
+// Providers that talk to a Kubernetes API server, directly or through a nested kubernetes block.
+var kubernetesProviders = map[string]bool{"kubernetes": true, "helm": true, "kubectl": true}
+
+// Minimum RSA modulus size accepted for tls_private_key.
+const minRSABits = 2048
+
+// Elliptic curves too small for new keys.
+var weakCurves = map[string]bool{"P224": true}
+
+// checkProvider reports provider feature flags that disable credential, account or TLS checks.
+func checkProvider(p string, block *hcl.Block) []finding.Finding {
+	body, ok := block.Body.(*hclsyntax.Body)
+	if !ok || len(block.Labels) != 1 {
+		return nil
+	}
+	name := block.Labels[0]
+	label := fmt.Sprintf("provider \"%s\"", name)
+	if alias, ok := body.Attributes["alias"]; ok {
+		if v, diags := alias.Expr.Value(nil); !diags.HasErrors() && v.Type() == cty.String {
+			label += fmt.Sprintf(" (alias %s)", v.AsString())
+		}
+	}
+
+	findings := providerFlags(p, label, body)
+	if kubernetesProviders[name] {
+		// The helm provider configures its cluster connection in a nested kubernetes block.
+		for _, inner := range body.Blocks {
+			if inner.Type == "kubernetes" {
+				findings = append(findings, providerFlags(p, label+" kubernetes block", inner.Body)...)
+			}
+		}
+	}
+	return findings
+}
+
+func providerFlags(p, label string, body *hclsyntax.Body) []finding.Finding {
+	var findings []finding.Finding
+	for _, flag := range riskyProviderFlags {
+		attr, ok := body.Attributes[flag.name]
+		if !ok || !literalTrue(attr.Expr) {
+			continue
+		}
+		findings = append(findings, finding.Finding{
+			File:     p,
+			Severity: flag.severity,
+			Message:  fmt.Sprintf("%s sets %s = true: %s", label, flag.name, flag.reason),
+			Line:     attr.SrcRange.Start.Line,
+		})
+	}
+	return findings
+}
+
+// literalTrue reports whether expr is the constant true (or the string "true").
+// Expressions depending on variables are not evaluated.
+func literalTrue(expr hclsyntax.Expression) bool {
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() || val.IsNull() || !val.IsKnown() {
+		return false
+	}
+	switch val.Type() {
+	case cty.Bool:
+		return val.True()
+	case cty.String:
+		return strings.EqualFold(val.AsString(), "true")
+	}
+	return false
+}
+
+// checkTLSKey reports tls_private_key resources generating keys that are too weak.
+func checkTLSKey(p, resourceName string, body hcl.Body) []finding.Finding {
+	syntaxBody, ok := body.(*hclsyntax.Body)
+	if !ok {
+		return nil
+	}
+	ref := "tls_private_key." + resourceName
+
+	algorithm := "RSA"
+	line := 0
+	if attr, ok := syntaxBody.Attributes["algorithm"]; ok {
+		val, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() || val.IsNull() || val.Type() != cty.String {
+			return nil
+		}
+		algorithm, line = strings.ToUpper(val.AsString()), attr.SrcRange.Start.Line
+	}
+
+	switch algorithm {
+	case "RSA":
+		attr, ok := syntaxBody.Attributes["rsa_bits"]
+		if !ok {
+			return nil // defaults to 2048
+		}
+		val, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() || val.IsNull() || val.Type() != cty.Number {
+			return nil
+		}
+		if bits, _ := val.AsBigFloat().Int64(); bits < minRSABits {
+			return []finding.Finding{{
+				File:     p,
+				Severity: finding.Error,
+				Message:  fmt.Sprintf("Resource '%s' generates a %d-bit RSA key; use at least %d bits", ref, bits, minRSABits),
+				Line:     attr.SrcRange.Start.Line,
+			}}
+		}
+	case "ECDSA":
+		curve, curveLine := "P224", line // the provider default
+		if attr, ok := syntaxBody.Attributes["ecdsa_curve"]; ok {
+			val, diags := attr.Expr.Value(nil)
+			if diags.HasErrors() || val.IsNull() || val.Type() != cty.String {
+				return nil
+			}
+			curve, curveLine = strings.ToUpper(val.AsString()), attr.SrcRange.Start.Line
+		}
+		if weakCurves[curve] {
+			return []finding.Finding{{
+				File:     p,
+				Severity: finding.Error,
+				Message:  fmt.Sprintf("Resource '%s' generates an ECDSA key on the weak %s curve; use P256 or stronger", ref, curve),
+				Line:     curveLine,
+			}}
+		}
+	}
+	return nil
+}
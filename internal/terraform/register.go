@@ -0,0 +1,93 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+func init() {
+	scanner.Register(configScanner{scanner.Simple{
+		ID:       "terraform",
+		Summary:  "Scan Terraform files in the specified directories or files",
+		Matcher:  IsTerraformFile,
+		ScanFunc: Scan,
+	}})
+	scanner.Register(planScanner{})
+	scanner.Register(stateScanner{})
+}
+
+// configScanner scans configuration trees, following local module calls.
+type configScanner struct{ scanner.Simple }
+
+// Linked reports whether root calls a local module anywhere, or a changed file is gone and
+// may have. Findings in a module name the sites calling it, and are reported by the scan of
+// the tree holding the callers, so rescanning the directories of the changed files alone
+// would report them under other messages and targets than a full scan.
+func (configScanner) Linked(root string, changed []string) bool {
+	for _, p := range changed {
+		if _, err := os.Stat(p); err != nil {
+			return true
+		}
+	}
+	parser := hclparse.NewParser()
+	linked := false
+	scanner.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil || linked {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(p) != ".tf" {
+			return nil
+		}
+		file, diags := parser.ParseHCLFile(p)
+		if diags.HasErrors() {
+			return nil
+		}
+		content, _, _ := file.Body.PartialContent(&hcl.BodySchema{
+			Blocks: []hcl.BlockHeaderSchema{{Type: "module", LabelNames: []string{"name"}}},
+		})
+		for _, block := range content.Blocks {
+			if _, ok := localModuleCall(p, block); ok {
+				linked = true
+			}
+		}
+		return nil
+	})
+	return linked
+}
+
+// planScanner scans JSON plans, told apart from other JSON files by content.
+type planScanner struct{}
+
+func (planScanner) Name() string { return "tfplan" }
+func (planScanner) Description() string {
+	return "Scan a Terraform JSON plan (terraform show -json) with fully resolved values"
+}
+func (planScanner) FileMatcher() scanner.FileMatcher            { return scanner.Extensions(".json") }
+func (planScanner) Scan(path string) ([]finding.Finding, error) { return ScanPlan(path) }
+
+func (planScanner) Targets(root string, files []string) []string {
+	var plans []string
+	for _, p := range files {
+		if data, err := os.ReadFile(p); err == nil && IsPlanFile(data) {
+			plans = append(plans, p)
+		}
+	}
+	return plans
+}
+
+// stateScanner audits each local state file against the configuration in its directory.
+type stateScanner struct{}
+
+func (stateScanner) Name() string { return "tfstate" }
+func (stateScanner) Description() string {
+	return "Audit Terraform state for stored secrets, exposed outputs and drift"
+}
+func (stateScanner) FileMatcher() scanner.FileMatcher             { return scanner.Extensions(".tfstate") }
+func (stateScanner) Scan(path string) ([]finding.Finding, error)  { return ScanState(path) }
+func (stateScanner) Targets(root string, files []string) []string { return files }
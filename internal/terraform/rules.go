@@ -0,0 +1,699 @@
+package terraform
+
+import (
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/registry"
+)
+
+var (
+	configScanners = []string{"terraform"}
+	planScanners   = []string{"terraform", "tfplan"}
+	stateScanners  = []string{"tfstate"}
+)
+
+func init() {
+	rules := []registry.Rule{
+		{
+			ID: "TF001", Name: "Deprecated resource type", Severity: finding.Warning, Category: "deprecation", Scanners: planScanners,
+			Description: "The resource type is deprecated by its provider and will be removed in a future major release; migrate to its replacement.",
+			Messages:    []string{"Resource type '*' is deprecated: *", "Resource '*' uses deprecated type '*': *"},
+			Remediation: `# replace the deprecated type with the one the provider recommends
+resource "aws_s3_bucket_acl" "logs" {
+  bucket = aws_s3_bucket.logs.id
+  acl    = "private"
+}`,
+		},
+		{
+			ID: "TF002", Name: "Public S3 bucket ACL", Severity: finding.Warning, Category: "access", Scanners: planScanners,
+			Compliance:  registry.PublicStorage,
+			Description: "An S3 bucket ACL of public-read or public-read-write, inline or in an aws_s3_bucket_acl resource, lets anyone list or download its objects.",
+			Messages:    []string{"S3 bucket ACL is set to public-read (publicly readable)", "S3 bucket '*' ACL is set to * (publicly accessible)"},
+			Remediation: `resource "aws_s3_bucket_acl" "assets" {
+  bucket = aws_s3_bucket.assets.id
+  acl    = "private"
+}`,
+		},
+		{
+			ID: "TF003", Name: "Missing required tag", Severity: finding.Warning, Category: "tagging", Scanners: planScanners,
+			Description: "A taggable resource lacks one of the tags every resource must carry for ownership and cost allocation.",
+			Messages:    []string{"Resource missing required tag '*'", "Resource '*' missing required tag '*'"},
+			Remediation: `resource "aws_instance" "web" {
+  # ...
+  tags = {
+    Owner       = "platform"
+    Environment = "prod"
+  }
+}`,
+		},
+		{
+			ID: "TF004", Name: "Resource without tags", Severity: finding.Warning, Category: "tagging", Scanners: planScanners,
+			Description: "A taggable resource sets no tags at all, so nobody can tell who owns it or what it costs.",
+			Messages:    []string{"Resource missing 'tags' attribute entirely", "Resource '*' has no tags"},
+			Remediation: `provider "aws" {
+  default_tags {
+    tags = { Owner = "platform" }
+  }
+}`,
+		},
+		{
+			ID: "TF005", Name: "Hardcoded secret in resource", Severity: finding.Error, Category: "secrets", Scanners: configScanners,
+			Compliance:  registry.HardcodedSecrets,
+			Description: "A resource or data source attribute with a secret-like name is set to a literal, committing the secret with the configuration.",
+			Messages:    []string{"Resource attribute '*' may contain hardcoded secret", "Data source attribute '*' may contain hardcoded secret"},
+			Remediation: `resource "aws_db_instance" "main" {
+  # ...
+  manage_master_user_password = true
+}`,
+		},
+		{
+			ID: "TF006", Name: "Hardcoded variable default secret", Severity: finding.Error, Category: "secrets", Scanners: configScanners,
+			Compliance:  registry.HardcodedSecrets,
+			Description: "A variable with a secret-like name has a literal default, which is used whenever no value is passed in.",
+			Messages:    []string{"Variable '*' has a hardcoded default secret"},
+			Remediation: `variable "db_password" {
+  type      = string
+  sensitive = true
+  # no default: pass it as TF_VAR_db_password
+}`,
+		},
+		{
+			ID: "TF007", Name: "Secret in variable definitions file", Severity: finding.Error, Category: "secrets", Scanners: configScanners,
+			Compliance:  registry.HardcodedSecrets,
+			Description: "A .tfvars file assigns a secret value, committing it next to the configuration.",
+			Messages:    []string{"Variable '*' * pass it through TF_VAR_ environment variables or a secrets manager instead"},
+			Remediation: `# keep the secret out of terraform.tfvars
+export TF_VAR_db_password="$(vault kv get -field=password secret/db)"`,
+		},
+		{
+			ID: "TF008", Name: "Variable without type", Severity: finding.Warning, Category: "hygiene", Scanners: configScanners,
+			Description: "A variable declares no type constraint, so callers can pass values of any shape and errors surface only at apply time.",
+			Messages:    []string{"Variable '*' has no type constraint, so any value is accepted"},
+			Remediation: `variable "instance_count" {
+  type        = number
+  description = "Number of web instances"
+}`,
+		},
+		{
+			ID: "TF009", Name: "Variable without description", Severity: finding.Warning, Category: "hygiene", Scanners: configScanners,
+			Description: "A variable has no description to tell the callers of the module what it is for.",
+			Messages:    []string{"Variable '*' has no description"},
+			Remediation: `variable "region" {
+  type        = string
+  description = "AWS region the stack is deployed to"
+}`,
+		},
+		{
+			ID: "TF010", Name: "Secret variable not sensitive", Severity: finding.Warning, Category: "secrets", Scanners: configScanners,
+			Compliance:  registry.ExposedSecrets,
+			Description: "A variable with a secret-like name is not declared sensitive = true, so plans and logs show its value.",
+			Messages:    []string{"Variable '*' looks like a secret but is not declared sensitive = true, *"},
+			Remediation: `variable "api_token" {
+  type      = string
+  sensitive = true
+}`,
+		},
+		{
+			ID: "TF011", Name: "Unused variable", Severity: finding.Warning, Category: "hygiene", Scanners: configScanners,
+			Description: "A variable is declared but nothing in its module references it.",
+			Messages:    []string{"Variable '*' is declared but never referenced in its module"},
+			Remediation: `# remove the declaration, or reference it
+resource "aws_instance" "web" {
+  instance_type = var.instance_type
+}`,
+		},
+		{
+			ID: "TF012", Name: "Undeclared variable", Severity: finding.Error, Category: "correctness", Scanners: configScanners,
+			Description: "An expression references a variable the module does not declare, which terraform validate rejects.",
+			Messages:    []string{"Reference to undeclared variable 'var.*'"},
+			Remediation: `variable "instance_type" {
+  type        = string
+  description = "EC2 instance type"
+}`,
+		},
+		{
+			ID: "TF013", Name: "Missing module source", Severity: finding.Error, Category: "correctness", Scanners: configScanners,
+			Description: "A module call points at a local source directory that does not exist.",
+			Messages:    []string{"Module '*' source '*' does not exist"},
+			Remediation: `module "network" {
+  source = "./modules/network" # a directory that exists
+}`,
+		},
+		{
+			ID: "TF014", Name: "No required_version", Severity: finding.Warning, Category: "versioning", Scanners: configScanners,
+			Description: "The root module does not constrain the Terraform CLI version, so any release can plan and apply it.",
+			Messages:    []string{"Configuration in * sets no required_version; *", "required_version '*' accepts any Terraform version"},
+			Remediation: `terraform {
+  required_version = ">= 1.5, < 2.0"
+}`,
+		},
+		{
+			ID: "TF015", Name: "Provider not in required_providers", Severity: finding.Warning, Category: "versioning", Scanners: configScanners,
+			Description: "A provider is used without a required_providers entry, so whichever version is newest gets installed.",
+			Messages:    []string{"Provider '*' is not listed in required_providers, *"},
+			Remediation: `terraform {
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 5.0"
+    }
+  }
+}`,
+		},
+		{
+			ID: "TF016", Name: "Unconstrained provider version", Severity: finding.Warning, Category: "versioning", Scanners: configScanners,
+			Description: "A required_providers entry has no version constraint, or one that accepts any version.",
+			Messages:    []string{"Provider '*' in required_providers has no version constraint", "Provider '*' version constraint '*' accepts any version"},
+			Remediation: `aws = {
+  source  = "hashicorp/aws"
+  version = "~> 5.0"
+}`,
+		},
+		{
+			ID: "TF017", Name: "Provider version without upper bound", Severity: finding.Info, Category: "versioning", Scanners: configScanners,
+			Description: "A provider version constraint has a lower bound only and will pick up the next major release with its breaking changes.",
+			Messages:    []string{"Provider '*' version constraint '*' has no upper bound *"},
+			Remediation: `aws = {
+  source  = "hashicorp/aws"
+  version = ">= 5.0, < 6.0"
+}`,
+		},
+		{
+			ID: "TF018", Name: "Provider skips account checks", Severity: finding.Warning, Category: "provider", Scanners: configScanners,
+			Description: "The provider sets skip_credentials_validation, skip_requesting_account_id or skip_metadata_api_check, disabling the checks that catch wrong credentials or accounts.",
+			Messages:    []string{"* sets skip_* = true: *"},
+			Remediation: `provider "aws" {
+  region = "eu-west-1"
+  # drop skip_credentials_validation and skip_requesting_account_id
+}`,
+		},
+		{
+			ID: "TF019", Name: "Provider TLS verification disabled", Severity: finding.Error, Category: "provider", Scanners: configScanners,
+			Compliance:  registry.EncryptionInTransit,
+			Description: "The provider sets insecure = true and accepts any TLS certificate from the API endpoint.",
+			Messages:    []string{"* sets insecure = true: *"},
+			Remediation: `provider "vault" {
+  address      = "https://vault.example.com"
+  ca_cert_file = "ca.pem" # instead of insecure = true
+}`,
+		},
+		{
+			ID: "TF020", Name: "Hardcoded provider credential", Severity: finding.Error, Category: "secrets", Scanners: configScanners,
+			Compliance:  registry.HardcodedSecrets,
+			Description: "A provider or backend block sets an access key, token or password to a literal, or to a variable with a literal default.",
+			Messages:    []string{"* sets '*' to a hardcoded credential; *", "* sets '*' from *, which has a hardcoded default credential"},
+			Remediation: `provider "aws" {
+  region = "eu-west-1"
+  # credentials come from AWS_PROFILE, SSO or an instance role
+}`,
+		},
+		{
+			ID: "TF021", Name: "Static provider credential", Severity: finding.Warning, Category: "secrets", Scanners: configScanners,
+			Compliance:  registry.ExposedSecrets,
+			Description: "A provider or backend block receives long-lived static credentials through variables instead of short-lived ones from a profile, role or OIDC.",
+			Messages:    []string{"* passes static credential '*' through *; *"},
+			Remediation: `provider "aws" {
+  assume_role {
+    role_arn = "arn:aws:iam::123456789012:role/terraform"
+  }
+}`,
+		},
+		{
+			ID: "TF022", Name: "Templated credential placeholder", Severity: finding.Warning, Category: "secrets", Scanners: configScanners,
+			Description: "A credential is set to an environment placeholder such as ${AWS_SECRET_ACCESS_KEY}, which Terraform does not expand and which tooling may render into the committed file.",
+			Messages:    []string{"* sets '*' to the environment placeholder '*'*"},
+			Remediation: `provider "aws" {
+  # let the provider read AWS_SECRET_ACCESS_KEY from the environment itself
+  region = "eu-west-1"
+}`,
+		},
+		{
+			ID: "TF023", Name: "Weak generated key", Severity: finding.Error, Category: "crypto", Scanners: planScanners,
+			Compliance:  registry.WeakCryptography,
+			Description: "A tls_private_key resource generates an RSA key under 2048 bits or an ECDSA key on the P224 curve.",
+			Messages:    []string{"Resource '*' generates a *-bit RSA key; use at least * bits", "Resource '*' generates an ECDSA key on the weak * curve; *"},
+			Remediation: `resource "tls_private_key" "deploy" {
+  algorithm = "RSA"
+  rsa_bits  = 4096
+}`,
+		},
+		{
+			ID: "TF024", Name: "Encryption at rest disabled", Severity: finding.Error, Category: "encryption", Scanners: planScanners,
+			Compliance:  registry.EncryptionAtRest,
+			Description: "A storage resource explicitly turns off encryption at rest.",
+			Messages:    []string{"Resource '*' sets * = false; its data is stored unencrypted at rest", "Resource '*' is planned with * = false; its data is stored unencrypted at rest"},
+			Remediation: `resource "aws_ebs_volume" "data" {
+  # ...
+  encrypted = true
+}`,
+		},
+		{
+			ID: "TF025", Name: "Encryption at rest not enabled", Severity: finding.Warning, Category: "encryption", Scanners: configScanners,
+			Compliance:  registry.EncryptionAtRest,
+			Description: "A storage resource whose encryption at rest is off by default does not turn it on.",
+			Messages:    []string{"Resource '*' does not set *; its data is stored unencrypted at rest"},
+			Remediation: `resource "aws_db_instance" "main" {
+  # ...
+  storage_encrypted = true
+  kms_key_id        = aws_kms_key.db.arn
+}`,
+		},
+		{
+			ID: "TF026", Name: "Unencrypted SQS queue", Severity: finding.Warning, Category: "encryption", Scanners: planScanners,
+			Compliance:  registry.EncryptionAtRest,
+			Description: "An SQS queue disables SSE-SQS without configuring a KMS key, so messages are stored unencrypted.",
+			Messages: []string{
+				"Resource '*' sets sqs_managed_sse_enabled = false without kms_master_key_id; messages are stored unencrypted",
+				"Resource '*' does not set kms_master_key_id; messages are stored unencrypted",
+				"Resource '*' is planned without SSE-SQS or a KMS key; messages are stored unencrypted",
+				"Resource '*' is planned without kms_master_key_id; messages are stored unencrypted",
+			},
+			Remediation: `resource "aws_sqs_queue" "jobs" {
+  kms_master_key_id = aws_kms_key.sqs.arn
+}`,
+		},
+		{
+			ID: "TF027", Name: "SQS queue on default encryption", Severity: finding.Info, Category: "encryption", Scanners: configScanners,
+			Description: "An SQS queue relies on the default SSE-SQS encryption rather than a KMS key you control.",
+			Messages:    []string{"Resource '*' sets neither kms_master_key_id nor sqs_managed_sse_enabled; *"},
+			Remediation: `resource "aws_sqs_queue" "jobs" {
+  kms_master_key_id                 = aws_kms_key.sqs.arn
+  kms_data_key_reuse_period_seconds = 300
+}`,
+		},
+		{
+			ID: "TF028", Name: "S3 bucket without SSE configuration", Severity: finding.Info, Category: "encryption", Scanners: configScanners,
+			Description: "An S3 bucket has no server-side encryption configuration and relies on the SSE-S3 default instead of a KMS key you control.",
+			Messages:    []string{"S3 bucket '*' has no server-side encryption configuration; *"},
+			Remediation: `resource "aws_s3_bucket_server_side_encryption_configuration" "data" {
+  bucket = aws_s3_bucket.data.id
+  rule {
+    apply_server_side_encryption_by_default {
+      sse_algorithm     = "aws:kms"
+      kms_master_key_id = aws_kms_key.s3.arn
+    }
+  }
+}`,
+		},
+		{
+			ID: "TF029", Name: "Security group open to the internet", Severity: finding.Error, Category: "network", Scanners: planScanners,
+			Compliance:  registry.NetworkExposure,
+			Description: "A security group allows ingress from 0.0.0.0/0 or ::/0 to SSH, RDP or all ports.",
+			Messages:    []string{"Security group '*' allows ingress from * to *"},
+			Remediation: `ingress {
+  from_port   = 22
+  to_port     = 22
+  protocol    = "tcp"
+  cidr_blocks = ["10.0.0.0/8"] # a known range, not 0.0.0.0/0
+}`,
+		},
+		{
+			ID: "TF030", Name: "IAM administrator policy", Severity: finding.Error, Category: "iam", Scanners: configScanners,
+			Compliance:  registry.LeastPrivilege,
+			Description: "An IAM policy statement allows every action on every resource.",
+			Messages:    []string{`IAM policy * allows Action "*" on Resource "*" (full administrator access)`},
+			Remediation: `data "aws_iam_policy_document" "app" {
+  statement {
+    actions   = ["s3:GetObject"]
+    resources = ["${aws_s3_bucket.data.arn}/*"]
+  }
+}`,
+		},
+		{
+			ID: "TF031", Name: "IAM wildcard action", Severity: finding.Error, Category: "iam", Scanners: configScanners,
+			Compliance:  registry.LeastPrivilege,
+			Description: `An IAM policy statement allows Action "*" on some resources instead of the actions it needs.`,
+			Messages:    []string{`IAM policy * allows Action "*"; grant only the actions needed`},
+			Remediation: `statement {
+  actions   = ["dynamodb:GetItem", "dynamodb:PutItem"]
+  resources = [aws_dynamodb_table.jobs.arn]
+}`,
+		},
+		{
+			ID: "TF032", Name: "IAM service wildcard on all resources", Severity: finding.Warning, Category: "iam", Scanners: configScanners,
+			Compliance:  registry.LeastPrivilege,
+			Description: `An IAM policy statement allows every action of a service, such as s3:*, on Resource "*".`,
+			Messages:    []string{`IAM policy * allows * on Resource "*"`},
+			Remediation: `statement {
+  actions   = ["s3:GetObject", "s3:ListBucket"]
+  resources = [aws_s3_bucket.data.arn, "${aws_s3_bucket.data.arn}/*"]
+}`,
+		},
+		{
+			ID: "TF033", Name: "IAM wildcard principal", Severity: finding.Error, Category: "iam", Scanners: configScanners,
+			Compliance:  registry.LeastPrivilege,
+			Description: `A resource policy allows Principal "*", letting anyone use it unless its conditions restrict who.`,
+			Messages: []string{
+				`IAM policy * allows Principal "*" without conditions (anyone can use it)`,
+				`IAM policy * allows Principal "*"; make sure its conditions restrict who can use it`,
+			},
+			Remediation: `statement {
+  principals {
+    type        = "AWS"
+    identifiers = ["arn:aws:iam::123456789012:root"]
+  }
+}`,
+		},
+		{
+			ID: "TF034", Name: "Unencrypted S3 state backend", Severity: finding.Warning, Category: "state", Scanners: configScanners,
+			Compliance:  registry.EncryptionAtRest,
+			Description: "The S3 backend does not encrypt the state file, which holds every secret Terraform manages.",
+			Messages:    []string{`backend "s3" does not set encrypt = true; *`, `backend "s3" disables state encryption (encrypt = false)`},
+			Remediation: `backend "s3" {
+  bucket  = "acme-terraform-state"
+  key     = "prod/terraform.tfstate"
+  encrypt = true
+}`,
+		},
+		{
+			ID: "TF035", Name: "S3 state backend without locking", Severity: finding.Warning, Category: "state", Scanners: configScanners,
+			Description: "The S3 backend has no DynamoDB table or lock file, so concurrent applies can corrupt the state.",
+			Messages:    []string{`backend "s3" has no state locking *`},
+			Remediation: `backend "s3" {
+  bucket       = "acme-terraform-state"
+  key          = "prod/terraform.tfstate"
+  use_lockfile = true # or dynamodb_table = "terraform-locks"
+}`,
+		},
+		{
+			ID: "TF036", Name: "Lifecycle ignores all changes", Severity: finding.Warning, Category: "drift", Scanners: configScanners,
+			Description: "A resource sets lifecycle ignore_changes = all, so Terraform never corrects drift on it.",
+			Messages:    []string{"Resource '*' sets lifecycle ignore_changes = all; *"},
+			Remediation: `lifecycle {
+  ignore_changes = [tags["LastScanned"]] # only what is managed elsewhere
+}`,
+		},
+		{
+			ID: "TF037", Name: "Lifecycle ignores a security attribute", Severity: finding.Warning, Category: "drift", Scanners: configScanners,
+			Description: "A resource ignores changes to an attribute such as a policy or ingress rules, hiding edits made outside Terraform.",
+			Messages:    []string{"Resource '*' ignores changes to security-relevant attribute '*'; *"},
+			Remediation: `lifecycle {
+  # keep policy and ingress under Terraform's control
+  ignore_changes = [tags]
+}`,
+		},
+		{
+			ID: "TF038", Name: "Perpetual diff", Severity: finding.Warning, Category: "drift", Scanners: configScanners,
+			Description: "An attribute uses a function such as timestamp() or uuid() whose value changes on every plan.",
+			Messages:    []string{"Resource '*' attribute '*' uses *(), which changes on every plan *"},
+			Remediation: `resource "time_static" "created" {}
+
+resource "aws_instance" "web" {
+  # ...
+  tags = { CreatedAt = time_static.created.rfc3339 } # instead of timestamp()
+}`,
+		},
+		{
+			ID: "TF039", Name: "Reference to a perpetually changing attribute", Severity: finding.Info, Category: "drift", Scanners: configScanners,
+			Description: "An attribute references another resource's attribute that changes on every apply, so this resource never converges either.",
+			Messages:    []string{"Resource '*' attribute '*' references '*', which changes on every apply of * *"},
+			Remediation: `# reference a stable attribute, such as an id, rather than one that changes on every apply
+instance_id = aws_instance.web.id`,
+		},
+		{
+			ID: "TF040", Name: "Plaintext secret in state", Severity: finding.Warning, Category: "state", Scanners: stateScanners,
+			Compliance:  registry.ExposedSecrets,
+			Description: "The state file stores a secret attribute in plaintext; anyone who can read the state can read the secret.",
+			Messages:    []string{"State stores secret '*' of '*' in plaintext; *"},
+			Remediation: `resource "aws_db_instance" "main" {
+  # let RDS keep the password in Secrets Manager instead of the state
+  manage_master_user_password = true
+}`,
+		},
+		{
+			ID: "TF041", Name: "Secret output not sensitive", Severity: finding.Error, Category: "secrets", Scanners: stateScanners,
+			Compliance:  registry.ExposedSecrets,
+			Description: "An output holds a secret but is not marked sensitive, so it is printed in logs and readable through terraform_remote_state.",
+			Messages:    []string{"Output '*' exposes * and is not marked sensitive; *", "Output '*' looks like a secret but is not marked sensitive; *"},
+			Remediation: `output "db_password" {
+  value     = random_password.db.result
+  sensitive = true
+}`,
+		},
+		{
+			ID: "TF042", Name: "Resource only in state", Severity: finding.Warning, Category: "drift", Scanners: stateScanners,
+			Description: "A resource is in state but no longer in the configuration, so the next apply destroys it.",
+			Messages:    []string{"Resource '*' is in state but its module is not in the configuration; *", "Resource '*' is in state but not in the configuration; *"},
+			Remediation: `# keep the resource without destroying it
+removed {
+  from = aws_instance.legacy
+  lifecycle {
+    destroy = false
+  }
+}`,
+		},
+		{
+			ID: "TF043", Name: "Resource not applied", Severity: finding.Info, Category: "drift", Scanners: stateScanners,
+			Description: "A resource is in the configuration but not in state: not applied yet, or managed by another state.",
+			Messages:    []string{"Resource '*' is in the configuration but not in state *"},
+			Remediation: `# adopt a resource created elsewhere instead of creating it again
+import {
+  to = aws_s3_bucket.logs
+  id = "acme-logs"
+}`,
+		},
+		{
+			ID: "TF044", Name: "Drifted attribute", Severity: finding.Warning, Category: "drift", Scanners: stateScanners,
+			Description: "An attribute's value in state differs from the literal in the configuration.",
+			Messages:    []string{"Resource '*' drifted: * is '*' in state but '*' in the configuration"},
+			Remediation: `# bring the configuration back in line with the intended value, then apply
+terraform plan -refresh-only`,
+		},
+		{
+			ID: "TF045", Name: "Azure resource allows old TLS", Severity: finding.Error, Category: "encryption", Scanners: configScanners,
+			Compliance:  registry.EncryptionInTransit,
+			Description: "An azurerm resource accepts TLS 1.0 or 1.1 connections.",
+			Messages:    []string{`Resource '*' sets * = "*"; require TLS 1.2 or later`},
+			Remediation: `resource "azurerm_storage_account" "main" {
+  # ...
+  min_tls_version = "TLS1_2"
+}`,
+		},
+		{
+			ID: "TF046", Name: "Azure storage allows public containers", Severity: finding.Error, Category: "access", Scanners: configScanners,
+			Compliance:  registry.PublicStorage,
+			Description: "A storage account allows its containers to be made publicly readable.",
+			Messages: []string{
+				"Storage account '*' sets allow_blob_public_access = true; *",
+				"Storage account '*' sets allow_nested_items_to_be_public = true; *",
+				"Storage account '*' does not set allow_nested_items_to_be_public = false; *",
+			},
+			Remediation: `resource "azurerm_storage_account" "main" {
+  # ...
+  allow_nested_items_to_be_public = false
+}`,
+		},
+		{
+			ID: "TF047", Name: "Public Azure storage container", Severity: finding.Error, Category: "access", Scanners: configScanners,
+			Compliance:  registry.PublicStorage,
+			Description: "A storage container has container_access_type blob or container, so anyone can read its blobs.",
+			Messages:    []string{"Storage container '*' has container_access_type = \"*\"; its blobs are publicly readable"},
+			Remediation: `resource "azurerm_storage_container" "data" {
+  name                  = "data"
+  container_access_type = "private"
+}`,
+		},
+		{
+			ID: "TF048", Name: "NSG open to the internet", Severity: finding.Error, Category: "network", Scanners: configScanners,
+			Compliance:  registry.NetworkExposure,
+			Description: "A network security group rule allows inbound traffic from any address to SSH, RDP or all ports.",
+			Messages:    []string{"Network security group rule in '*' allows inbound traffic from * to *"},
+			Remediation: `security_rule {
+  name                   = "ssh"
+  direction              = "Inbound"
+  destination_port_range = "22"
+  source_address_prefix  = "10.0.0.0/8" # instead of * or Internet
+}`,
+		},
+		{
+			ID: "TF049", Name: "Key vault soft delete weakened", Severity: finding.Error, Category: "resilience", Scanners: configScanners,
+			Compliance:  registry.DataRecovery,
+			Description: "A key vault disables soft delete or keeps deleted objects for less than 7 days.",
+			Messages:    []string{"Key vault '*' sets soft_delete_enabled = false; *", "Key vault '*' keeps soft-deleted objects for only * days"},
+			Remediation: `resource "azurerm_key_vault" "main" {
+  # ...
+  soft_delete_retention_days = 90
+}`,
+		},
+		{
+			ID: "TF050", Name: "Key vault without purge protection", Severity: finding.Warning, Category: "resilience", Scanners: configScanners,
+			Compliance:  registry.DataRecovery,
+			Description: "A key vault does not enable purge protection, so soft-deleted keys and secrets can be purged before their retention ends.",
+			Messages:    []string{"Key vault '*' does not set purge_protection_enabled = true; *"},
+			Remediation: `resource "azurerm_key_vault" "main" {
+  # ...
+  purge_protection_enabled = true
+}`,
+		},
+		{
+			ID: "TF051", Name: "SQL server without auditing", Severity: finding.Warning, Category: "logging", Scanners: configScanners,
+			Compliance:  registry.AuditLogging,
+			Description: "An Azure SQL server has no extended auditing policy, so access and changes are not logged.",
+			Messages:    []string{"SQL server '*' has no auditing; *"},
+			Remediation: `resource "azurerm_mssql_server_extended_auditing_policy" "main" {
+  server_id              = azurerm_mssql_server.main.id
+  log_monitoring_enabled = true
+}`,
+		},
+		{
+			ID: "TF052", Name: "Public GCS bucket", Severity: finding.Error, Category: "access", Scanners: configScanners,
+			Compliance:  registry.PublicStorage,
+			Description: "A bucket IAM member, binding or ACL grants access to allUsers or allAuthenticatedUsers.",
+			Messages:    []string{"Bucket IAM '*' grants * to *; the bucket is public", "ACL '*' grants *; the objects are public"},
+			Remediation: `resource "google_storage_bucket_iam_member" "reader" {
+  bucket = google_storage_bucket.data.name
+  role   = "roles/storage.objectViewer"
+  member = "group:analytics@example.com"
+}`,
+		},
+		{
+			ID: "TF053", Name: "Default compute service account with full scope", Severity: finding.Error, Category: "iam", Scanners: configScanners,
+			Compliance:  registry.LeastPrivilege,
+			Description: "An instance runs as the default compute service account with the cloud-platform scope, which carries the project Editor role to every API.",
+			Messages:    []string{"Instance '*' runs as the default compute service account with the cloud-platform scope, *"},
+			Remediation: `service_account {
+  email  = google_service_account.web.email
+  scopes = ["cloud-platform"]
+}`,
+		},
+		{
+			ID: "TF054", Name: "Firewall rule open to the internet", Severity: finding.Error, Category: "network", Scanners: configScanners,
+			Compliance:  registry.NetworkExposure,
+			Description: "A GCP ingress firewall rule allows 0.0.0.0/0, explicitly or by omitting source ranges, to SSH, RDP or all ports.",
+			Messages:    []string{"Firewall rule '*' allows ingress from * to *"},
+			Remediation: `resource "google_compute_firewall" "ssh" {
+  # ...
+  source_ranges = ["35.235.240.0/20"] # IAP, not 0.0.0.0/0
+}`,
+		},
+		{
+			ID: "TF055", Name: "Cloud SQL without SSL", Severity: finding.Error, Category: "encryption", Scanners: configScanners,
+			Compliance:  registry.EncryptionInTransit,
+			Description: "A Cloud SQL instance accepts unencrypted connections.",
+			Messages: []string{
+				"Cloud SQL instance '*' sets ssl_mode = \"*\", accepting unencrypted connections",
+				"Cloud SQL instance '*' sets require_ssl = false, accepting unencrypted connections",
+				"Cloud SQL instance '*' does not require SSL; *",
+			},
+			Remediation: `settings {
+  ip_configuration {
+    ssl_mode = "ENCRYPTED_ONLY"
+  }
+}`,
+		},
+		{
+			ID: "TF056", Name: "Checked value not known", Severity: finding.Info, Category: "coverage", Scanners: configScanners,
+			Description: "An attribute a check judges, such as an S3 ACL or an ingress CIDR, refers to a variable without a default or tfvars value, or to a local built from one or from a resource attribute, so the check cannot tell whether the resource passes.",
+			Messages:    []string{"Cannot determine * of '*': it depends on *, which has no known value; its checks are skipped"},
+			Remediation: `# give the variable a safe default, or set it in terraform.tfvars or *.auto.tfvars
+variable "bucket_acl" {
+  type    = string
+  default = "private"
+}`,
+		},
+		{
+			ID: "TF057", Name: "Deprecated data source type", Severity: finding.Warning, Category: "deprecation", Scanners: configScanners,
+			Description: "The data source type is deprecated or removed by its provider, or belongs to an archived provider; migrate to its replacement.",
+			Messages:    []string{"Data source type '*' is deprecated: *"},
+			Remediation: `data "aws_subnets" "private" {
+  filter {
+    name   = "vpc-id"
+    values = [var.vpc_id]
+  }
+}`,
+		},
+		{
+			ID: "TF058", Name: "Hardcoded secret in local value", Severity: finding.Error, Category: "secrets", Scanners: configScanners,
+			Compliance:  registry.HardcodedSecrets,
+			Description: "A local value, or a key of a map local, with a secret-like name is set to a literal, committing the secret with the configuration.",
+			Messages:    []string{"Local value '*' is a hardcoded secret; *"},
+			Remediation: `variable "db_password" {
+  type      = string
+  sensitive = true
+}
+
+locals {
+  db_password = var.db_password
+}`,
+		},
+		{
+			ID: "TF059", Name: "Unused local value", Severity: finding.Warning, Category: "hygiene", Scanners: configScanners,
+			Description: "A local value is declared but nothing in its module references it.",
+			Messages:    []string{"Local value '*' is declared but never referenced in its module"},
+			Remediation: `# remove the local, or reference it
+resource "aws_instance" "web" {
+  tags = local.common_tags
+}`,
+		},
+		{
+			ID: "TF060", Name: "Undeclared local value", Severity: finding.Error, Category: "correctness", Scanners: configScanners,
+			Description: "An expression references a local value no locals block of the module declares, which terraform validate rejects.",
+			Messages:    []string{"Reference to undeclared local value 'local.*'"},
+			Remediation: `locals {
+  common_tags = { Owner = "platform" }
+}`,
+		},
+		{
+			ID: "TF061", Name: "Reference to undeclared block", Severity: finding.Error, Category: "correctness", Scanners: configScanners,
+			Description: "An expression or depends_on refers to a resource, data source or module its module does not declare, which terraform validate rejects.",
+			Messages:    []string{"'*' references undeclared * '*'"},
+			Remediation: `# declare the block, or fix the address
+data "aws_ami" "base" {
+  most_recent = true
+  owners      = ["amazon"]
+}`,
+		},
+		{
+			ID: "TF062", Name: "Dependency cycle", Severity: finding.Error, Category: "correctness", Scanners: configScanners,
+			Description: "Blocks depend on each other in a circle, through references or depends_on, so terraform cannot order them and plan fails.",
+			Messages:    []string{"Dependency cycle: *; terraform cannot order these blocks"},
+			Remediation: `# drop the depends_on or reference closing the circle, or split the resource
+resource "aws_iam_role" "b" {
+  name = "b"
+}`,
+		},
+		{
+			ID: "TF063", Name: "Unused module output", Severity: finding.Info, Category: "hygiene", Scanners: configScanners,
+			Description: "An output of a local module is referenced by none of the module blocks calling it in the scanned configuration.",
+			Messages:    []string{"Output '*' is not referenced by any caller of its module"},
+			Remediation: `# remove the output, or use it
+resource "aws_instance" "web" {
+  subnet_id = module.net.subnet_id
+}`,
+		},
+		{
+			ID: "TF064", Name: "S3 bucket without a public access block", Severity: finding.Warning, Category: "access", Scanners: configScanners,
+			Compliance:  registry.PublicStorage,
+			Description: "An S3 bucket has no aws_s3_bucket_public_access_block, nor does its directory have an account-wide one, or its block leaves some of the four settings disabled, so an ACL or bucket policy can still make it public.",
+			Messages: []string{
+				"S3 bucket '*' has no public access block; *",
+				"Public access block '*' of S3 bucket '*' leaves * disabled",
+			},
+			Remediation: `resource "aws_s3_bucket_public_access_block" "logs" {
+  bucket                  = aws_s3_bucket.logs.id
+  block_public_acls       = true
+  block_public_policy     = true
+  ignore_public_acls      = true
+  restrict_public_buckets = true
+}`,
+		},
+		{
+			ID: "TF065", Name: "S3 bucket without versioning", Severity: finding.Warning, Category: "resilience", Scanners: configScanners,
+			Compliance:  registry.DataRecovery,
+			Description: "An S3 bucket enables versioning neither inline (provider v3) nor through an aws_s3_bucket_versioning resource (provider v4+), so overwritten and deleted objects are lost.",
+			Messages:    []string{"S3 bucket '*' does not enable versioning; *"},
+			Remediation: `resource "aws_s3_bucket_versioning" "logs" {
+  bucket = aws_s3_bucket.logs.id
+  versioning_configuration {
+    status = "Enabled"
+  }
+}`,
+		},
+	}
+	// findings in modules called from elsewhere name the callers: "... (via module.x at main.tf:3)"
+	for i := range rules {
+		for _, m := range rules[i].Messages {
+			rules[i].Messages = append(rules[i].Messages, m+" (via *)")
+		}
+	}
+	registry.Register(rules...)
+}
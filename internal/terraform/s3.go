@@ -0,0 +1,247 @@
+package terraform
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// The settings of aws_s3_bucket_public_access_block, which all default to false.
+var publicAccessSettings = []string{"block_public_acls", "block_public_policy", "ignore_public_acls", "restrict_public_buckets"}
+
+type s3Bucket struct {
+	file, name string
+	bucket     string // the bucket attribute, when a literal name
+	line       int
+}
+
+// s3Split is a resource configuring a bucket it names, resolved once every bucket of its
+// directory is known.
+type s3Split struct {
+	file, resourceType, resourceName string
+	body                             *hclsyntax.Body
+}
+
+// s3Buckets tracks, per directory, the S3 buckets declared and how they are configured:
+// inline in the aws_s3_bucket block (provider v3), or through the resources provider v4
+// split it into, which name the bucket they apply to.
+type s3Buckets struct {
+	buckets    map[string][]s3Bucket
+	encrypted  map[string]map[string]bool // dir -> bucket resource names
+	versioned  map[string]map[string]bool
+	blocked    map[string]map[string]bool // buckets with a public access block, complete or not
+	accountPAB map[string]bool            // dirs with an account-wide public access block
+	split      []s3Split
+}
+
+func newS3Buckets() *s3Buckets {
+	return &s3Buckets{
+		buckets:    make(map[string][]s3Bucket),
+		encrypted:  make(map[string]map[string]bool),
+		versioned:  make(map[string]map[string]bool),
+		blocked:    make(map[string]map[string]bool),
+		accountPAB: make(map[string]bool),
+	}
+}
+
+// add records a resource block relevant to S3 buckets.
+func (s *s3Buckets) add(p, resourceType, resourceName string, body hcl.Body) {
+	syntaxBody, ok := body.(*hclsyntax.Body)
+	if !ok {
+		return
+	}
+	dir := filepath.Dir(p)
+	switch resourceType {
+	case "aws_s3_bucket":
+		for _, block := range syntaxBody.Blocks {
+			switch block.Type {
+			case "server_side_encryption_configuration":
+				mark(s.encrypted, dir, resourceName)
+			case "versioning":
+				if enabled, ok := literalBool(block.Body, "enabled"); ok && enabled {
+					mark(s.versioned, dir, resourceName)
+				}
+			}
+		}
+		bucket, _ := literalString(syntaxBody, "bucket")
+		s.buckets[dir] = append(s.buckets[dir], s3Bucket{p, resourceName, bucket, syntaxBody.SrcRange.Start.Line})
+	case "aws_s3_account_public_access_block":
+		s.accountPAB[dir] = true
+	case "aws_s3_bucket_server_side_encryption_configuration", "aws_s3_bucket_versioning", "aws_s3_bucket_acl", "aws_s3_bucket_public_access_block":
+		s.split = append(s.split, s3Split{p, resourceType, resourceName, syntaxBody})
+	}
+}
+
+// addSplit records a split resource on the buckets it names. Public ACLs and incomplete
+// public access blocks are reported on the resource setting them.
+func (s *s3Buckets) addSplit(r s3Split) []finding.Finding {
+	dir := filepath.Dir(r.file)
+	var findings []finding.Finding
+	switch r.resourceType {
+	case "aws_s3_bucket_server_side_encryption_configuration":
+		for _, bucket := range s.bucketRefs(dir, r.body) {
+			mark(s.encrypted, dir, bucket)
+		}
+	case "aws_s3_bucket_versioning":
+		for _, block := range r.body.Blocks {
+			if block.Type != "versioning_configuration" {
+				continue
+			}
+			if status, ok := literalString(block.Body, "status"); ok && status == "Enabled" {
+				for _, bucket := range s.bucketRefs(dir, r.body) {
+					mark(s.versioned, dir, bucket)
+				}
+			}
+		}
+	case "aws_s3_bucket_acl":
+		acl, ok := literalString(r.body, "acl")
+		if !ok || (acl != "public-read" && acl != "public-read-write") {
+			return nil
+		}
+		for _, bucket := range s.bucketRefs(dir, r.body) {
+			findings = append(findings, finding.Finding{
+				File:     r.file,
+				Severity: finding.Warning,
+				Message:  fmt.Sprintf("S3 bucket 'aws_s3_bucket.%s' ACL is set to %s (publicly accessible)", bucket, acl),
+				Line:     r.body.Attributes["acl"].SrcRange.Start.Line,
+			})
+		}
+	case "aws_s3_bucket_public_access_block":
+		var disabled []string
+		for _, setting := range publicAccessSettings {
+			on, ok := literalBool(r.body, setting)
+			if (ok && !on) || r.body.Attributes[setting] == nil {
+				disabled = append(disabled, setting)
+			}
+		}
+		for _, bucket := range s.bucketRefs(dir, r.body) {
+			mark(s.blocked, dir, bucket)
+			if len(disabled) == 0 {
+				continue
+			}
+			findings = append(findings, finding.Finding{
+				File:     r.file,
+				Severity: finding.Warning,
+				Message:  fmt.Sprintf("Public access block '%s' of S3 bucket 'aws_s3_bucket.%s' leaves %s disabled", r.resourceName, bucket, strings.Join(disabled, ", ")),
+				Line:     r.body.SrcRange.Start.Line,
+			})
+		}
+	}
+	return findings
+}
+
+// bucketRefs returns the names of the aws_s3_bucket resources of dir the bucket attribute
+// of a split S3 resource refers to, as in bucket = aws_s3_bucket.logs.id, or names by the
+// same literal bucket name, as in bucket = "example-prod-logs".
+func (s *s3Buckets) bucketRefs(dir string, body *hclsyntax.Body) []string {
+	attr, ok := body.Attributes["bucket"]
+	if !ok {
+		return nil
+	}
+	var names []string
+	if literal, ok := literalString(body, "bucket"); ok {
+		for _, b := range s.buckets[dir] {
+			if b.bucket != "" && b.bucket == literal {
+				names = append(names, b.name)
+			}
+		}
+		return names
+	}
+	for _, traversal := range attr.Expr.Variables() {
+		if traversal.RootName() != "aws_s3_bucket" || len(traversal) < 2 {
+			continue
+		}
+		if step, ok := traversal[1].(hcl.TraverseAttr); ok {
+			names = append(names, step.Name)
+		}
+	}
+	return names
+}
+
+func mark(set map[string]map[string]bool, dir, bucket string) {
+	if set[dir] == nil {
+		set[dir] = make(map[string]bool)
+	}
+	set[dir][bucket] = true
+}
+
+// literalBool returns the value of a boolean attribute written as a constant, or as a
+// variable or local resolved to one.
+func literalBool(body *hclsyntax.Body, name string) (bool, bool) {
+	attr, ok := body.Attributes[name]
+	if !ok {
+		return false, false
+	}
+	val, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || val.IsNull() || !val.IsKnown() || val.Type() != cty.Bool {
+		return false, false
+	}
+	return val.True(), true
+}
+
+// literalString is literalBool for string attributes.
+func literalString(body *hclsyntax.Body, name string) (string, bool) {
+	attr, ok := body.Attributes[name]
+	if !ok {
+		return "", false
+	}
+	val, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || val.IsNull() || !val.IsKnown() || val.Type() != cty.String {
+		return "", false
+	}
+	return val.AsString(), true
+}
+
+// findings reports the buckets without an encryption configuration, a public access block
+// or versioning, after the findings about the split resources, resolved to the buckets they
+// name first. S3 applies SSE-S3 by default since 2023, so missing customer-controlled
+// encryption is informational. An account-wide public access block in the directory stands
+// in for the per-bucket ones.
+func (s *s3Buckets) findings() []finding.Finding {
+	dirs := make([]string, 0, len(s.buckets))
+	for dir := range s.buckets {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	var findings []finding.Finding
+	for _, r := range s.split {
+		findings = append(findings, s.addSplit(r)...)
+	}
+	for _, dir := range dirs {
+		for _, b := range s.buckets[dir] {
+			if !s.encrypted[dir][b.name] {
+				findings = append(findings, finding.Finding{
+					File:     b.file,
+					Severity: finding.Info,
+					Message:  fmt.Sprintf("S3 bucket 'aws_s3_bucket.%s' has no server-side encryption configuration; it relies on the SSE-S3 default instead of a KMS key you control", b.name),
+					Line:     b.line,
+				})
+			}
+			if !s.blocked[dir][b.name] && !s.accountPAB[dir] {
+				findings = append(findings, finding.Finding{
+					File:     b.file,
+					Severity: finding.Warning,
+					Message:  fmt.Sprintf("S3 bucket 'aws_s3_bucket.%s' has no public access block; add an aws_s3_bucket_public_access_block so an ACL or policy cannot make it public", b.name),
+					Line:     b.line,
+				})
+			}
+			if !s.versioned[dir][b.name] {
+				findings = append(findings, finding.Finding{
+					File:     b.file,
+					Severity: finding.Warning,
+					Message:  fmt.Sprintf("S3 bucket 'aws_s3_bucket.%s' does not enable versioning; overwritten and deleted objects cannot be recovered", b.name),
+					Line:     b.line,
+				})
+			}
+		}
+	}
+	return findings
+}
@@ -0,0 +1,330 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// State auditing reads Terraform state (format version 4) and reports secrets stored in it,
+// outputs exposing them, and drift between the state and the configuration next to it.
+
+type state struct {
+	Version   int                    `json:"version"`
+	Outputs   map[string]stateOutput `json:"outputs"`
+	Resources []stateResource        `json:"resources"`
+}
+
+type stateOutput struct {
+	Value     interface{} `json:"value"`
+	Sensitive bool        `json:"sensitive"`
+}
+
+type stateResource struct {
+	Module    string          `json:"module"`
+	Mode      string          `json:"mode"`
+	Type      string          `json:"type"`
+	Name      string          `json:"name"`
+	Instances []stateInstance `json:"instances"`
+}
+
+type stateInstance struct {
+	IndexKey   interface{}            `json:"index_key"`
+	Attributes map[string]interface{} `json:"attributes"`
+	// Sensitive holds sensitive_attributes, whose path encoding differs between Terraform versions.
+	Sensitive json.RawMessage `json:"sensitive_attributes"`
+}
+
+func (r stateResource) address() string {
+	addr := r.Type + "." + r.Name
+	if r.Module != "" {
+		addr = r.Module + "." + addr
+	}
+	return addr
+}
+
+// Attributes holding secrets whose names do not say so.
+var stateSecretAttributes = map[string][]string{
+	"random_password":    {"result"},
+	"random_string":      {"result"},
+	"tls_private_key":    {"private_key_pem", "private_key_openssh", "private_key_pem_pkcs8"},
+	"aws_iam_access_key": {"secret", "ses_smtp_password_v4"},
+}
+
+// IsStateFile reports whether data is a Terraform state file.
+func IsStateFile(data []byte) bool {
+	var probe struct {
+		Version          int    `json:"version"`
+		TerraformVersion string `json:"terraform_version"`
+		Lineage          string `json:"lineage"`
+	}
+	return json.Unmarshal(data, &probe) == nil && probe.Version > 0 && probe.Lineage != ""
+}
+
+// ScanState audits the *.tfstate files under path against the configuration in their directory.
+func ScanState(path string) ([]finding.Finding, error) {
+	var findings []finding.Finding
+	err := scanner.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		if filepath.Ext(p) != ".tfstate" {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		fs, err := AuditState(p, data, filepath.Dir(p))
+		if err != nil {
+			return err
+		}
+		findings = append(findings, fs...)
+		return nil
+	})
+	return findings, err
+}
+
+// AuditState audits state data attributed to file. configDir holds the root module the
+// state belongs to; drift is not checked when it contains no .tf files.
+func AuditState(file string, data []byte, configDir string) ([]finding.Finding, error) {
+	if !IsStateFile(data) {
+		return nil, fmt.Errorf("%s is not a Terraform state file", file)
+	}
+	var st state
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	if st.Version != 4 {
+		return nil, fmt.Errorf("%s: unsupported state format version %d", file, st.Version)
+	}
+
+	var findings []finding.Finding
+	add := func(sev finding.Severity, format string, args ...interface{}) {
+		findings = append(findings, finding.Finding{File: file, Severity: sev, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// Secrets stored in resource attributes
+	secrets := make(map[string]string) // secret value -> where it is stored
+	for _, r := range st.Resources {
+		if r.Mode != "managed" {
+			continue
+		}
+		for _, inst := range r.Instances {
+			addr := r.address() + indexSuffix(inst.IndexKey)
+			for _, name := range stateSecrets(r.Type, inst) {
+				value, _ := inst.Attributes[name].(string)
+				secrets[value] = addr + "." + name
+				add(finding.Warning, "State stores secret '%s' of '%s' in plaintext; keep state in an encrypted backend with restricted access", name, addr)
+			}
+		}
+	}
+
+	// Outputs exposing secrets
+	for _, name := range sortedOutputNames(st.Outputs) {
+		out := st.Outputs[name]
+		if out.Sensitive {
+			continue
+		}
+		value, _ := out.Value.(string)
+		switch {
+		case value != "" && secrets[value] != "":
+			add(finding.Error, "Output '%s' exposes %s and is not marked sensitive; it is printed in logs and readable through terraform_remote_state", name, secrets[value])
+		case value != "" && secretVariable(name):
+			add(finding.Error, "Output '%s' looks like a secret but is not marked sensitive; it is printed in logs and readable through terraform_remote_state", name)
+		}
+	}
+
+	// Drift against the checked-in configuration
+	config, err := loadRootResources(configDir)
+	if err != nil || config == nil {
+		return findings, err
+	}
+	inState := make(map[string]bool)
+	for _, r := range st.Resources {
+		if r.Mode != "managed" {
+			continue
+		}
+		inState[r.address()] = true
+		if r.Module != "" {
+			if !config.modules[strings.SplitN(strings.TrimPrefix(r.Module, "module."), ".", 2)[0]] {
+				add(finding.Warning, "Resource '%s' is in state but its module is not in the configuration; the next apply will destroy it", r.address())
+			}
+			continue
+		}
+		res, ok := config.resources[r.Type+"."+r.Name]
+		if !ok {
+			add(finding.Warning, "Resource '%s' is in state but not in the configuration; the next apply will destroy it", r.address())
+			continue
+		}
+		if len(r.Instances) == 1 && r.Instances[0].IndexKey == nil {
+			findings = append(findings, attributeDrift(file, r.address(), res, r.Instances[0].Attributes)...)
+		}
+	}
+	for _, addr := range config.order {
+		if !inState[addr] {
+			add(finding.Info, "Resource '%s' is in the configuration but not in state (not applied yet, or managed elsewhere)", addr)
+		}
+	}
+	return findings, nil
+}
+
+// stateSecrets returns the names of the non-empty secret attributes of an instance.
+func stateSecrets(resourceType string, inst stateInstance) []string {
+	names := make(map[string]bool)
+	for _, name := range stateSecretAttributes[resourceType] {
+		names[name] = true
+	}
+	for name := range inst.Attributes {
+		if secretVariable(name) {
+			names[name] = true
+		}
+	}
+	for _, path := range sensitivePaths(inst.Sensitive) {
+		names[path] = true
+	}
+
+	var out []string
+	for name := range names {
+		if s, ok := inst.Attributes[name].(string); ok && s != "" {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// sensitivePaths returns the top-level attribute names of sensitive_attributes paths.
+func sensitivePaths(raw json.RawMessage) []string {
+	var paths [][]struct {
+		Type  string      `json:"type"`
+		Value interface{} `json:"value"`
+	}
+	if json.Unmarshal(raw, &paths) != nil {
+		return nil
+	}
+	var names []string
+	for _, path := range paths {
+		if len(path) > 0 && path[0].Type == "get_attr" {
+			if name, ok := path[0].Value.(string); ok {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+func indexSuffix(key interface{}) string {
+	switch k := key.(type) {
+	case nil:
+		return ""
+	case string:
+		return fmt.Sprintf("[%q]", k)
+	case float64:
+		return fmt.Sprintf("[%d]", int(k))
+	}
+	return ""
+}
+
+func sortedOutputNames(outputs map[string]stateOutput) []string {
+	names := make([]string, 0, len(outputs))
+	for name := range outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// rootResources are the resources and module calls declared by a root module.
+type rootResources struct {
+	resources map[string]*hclsyntax.Body
+	order     []string
+	modules   map[string]bool
+}
+
+// loadRootResources parses the .tf files directly in dir; it returns nil when there are none.
+func loadRootResources(dir string) (*rootResources, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil || len(files) == 0 {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	parser := hclparse.NewParser()
+	root := &rootResources{resources: make(map[string]*hclsyntax.Body), modules: make(map[string]bool)}
+	for _, p := range files {
+		file, diags := parser.ParseHCLFile(p)
+		if diags.HasErrors() {
+			continue // reported by Scan
+		}
+		body, ok := file.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+		for _, block := range body.Blocks {
+			switch {
+			case block.Type == "resource" && len(block.Labels) == 2:
+				addr := block.Labels[0] + "." + block.Labels[1]
+				root.resources[addr] = block.Body
+				root.order = append(root.order, addr)
+			case block.Type == "module" && len(block.Labels) == 1:
+				root.modules[block.Labels[0]] = true
+			}
+		}
+	}
+	return root, nil
+}
+
+// attributeDrift compares the literal top-level attributes of a resource's configuration
+// with the values recorded in state.
+func attributeDrift(file, addr string, body *hclsyntax.Body, attrs map[string]interface{}) []finding.Finding {
+	if _, ok := body.Attributes["count"]; ok {
+		return nil
+	}
+	if _, ok := body.Attributes["for_each"]; ok {
+		return nil
+	}
+	var findings []finding.Finding
+	for _, name := range sortedAttributeNames(body) {
+		stored, ok := attrs[name]
+		if !ok {
+			continue
+		}
+		val, diags := body.Attributes[name].Expr.Value(nil)
+		if diags.HasErrors() || val.IsNull() || !val.IsKnown() || !val.Type().IsPrimitiveType() {
+			continue
+		}
+		configured := primitiveString(val)
+		if actual := fmt.Sprint(stored); actual != configured {
+			findings = append(findings, finding.Finding{
+				File:     file,
+				Severity: finding.Warning,
+				Message:  fmt.Sprintf("Resource '%s' drifted: %s is '%s' in state but '%s' in the configuration", addr, name, actual, configured),
+			})
+		}
+	}
+	return findings
+}
+
+func primitiveString(v cty.Value) string {
+	switch v.Type() {
+	case cty.String:
+		return v.AsString()
+	case cty.Bool:
+		return fmt.Sprint(v.True())
+	case cty.Number:
+		f, _ := v.AsBigFloat().Float64()
+		return fmt.Sprint(f)
+	}
+	return ""
+}
@@ -8,9 +8,11 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/scanner"
 )
 
 func looksLikeSecret(varName, value string) bool {
@@ -34,6 +36,14 @@ func isSensitiveKeyword(name string) bool {
 	return false
 }
 
+// Required tags on resources to check
+var requiredTags = []string{"Environment", "Owner", "Project"}
+
+// RequiredTags returns the tags every taggable resource must set.
+func RequiredTags() []string {
+	return append([]string(nil), requiredTags...)
+}
+
 var deprecatedResources = map[string]string{
 	"aws_db_instance":                   "This resource is deprecated, use aws_rds_instance instead.",
 	"aws_elb":                           "This resource is deprecated, use aws_lb instead.",
@@ -49,6 +59,20 @@ var deprecatedResources = map[string]string{
 	"aws_iam_group_policy_attachment":   "Deprecated, prefer aws_iam_group_policy.",
 }
 
+// Data sources deprecated by their provider, or whose provider is deprecated
+var deprecatedDataSources = map[string]string{
+	"aws_subnet_ids":                       "use the aws_subnets data source instead.",
+	"aws_kms_secret":                       "removed in AWS provider v3, use aws_kms_secrets instead.",
+	"aws_elasticsearch_domain":             "use aws_opensearch_domain instead.",
+	"aws_alb":                              "aws_alb is a legacy alias, use aws_lb instead.",
+	"aws_alb_listener":                     "aws_alb_listener is a legacy alias, use aws_lb_listener instead.",
+	"template_file":                        "the template provider is archived, use the templatefile() function instead.",
+	"template_cloudinit_config":            "the template provider is archived, use cloudinit_config from the cloudinit provider instead.",
+	"azurerm_app_service":                  "use azurerm_linux_web_app or azurerm_windows_web_app instead.",
+	"azurerm_app_service_plan":             "use azurerm_service_plan instead.",
+	"google_container_registry_repository": "Container Registry is shut down, use google_artifact_registry_repository instead.",
+}
+
 // FindingSeverity types
 type Severity string
 
@@ -85,22 +109,67 @@ type Finding struct {
 // Analyze resource blocks for specific attributes (e.g., an aws_s3_bucket resource with acl = "public-read").
 
 // Scan parses Terraform files under the path and runs checks such as:
-// - Publicly readable S3 buckets (acl = "public-read")
+// - Publicly readable S3 buckets (acl = "public-read", inline or in aws_s3_bucket_acl)
+// - S3 buckets without a complete public access block or versioning, see s3Buckets
 // - Hardcoded secrets in variables and resource attributes
 // - Missing required tags on resources
 // - Deprecated resource types warning
+// - Lifecycle ignore_changes and perpetual-diff patterns
+// - Static credentials in provider and backend configuration
+// - Provider flags disabling credential, account or TLS checks, and weak TLS keys
+// - Security group ingress from 0.0.0.0/0 to SSH, RDP or all ports
+// - Storage, databases, queues and topics without encryption at rest
+// - IAM policies allowing Action "*", Resource "*" or Principal "*"
+// - Hardcoded secrets in terraform.tfvars and *.auto.tfvars files
+// - S3 backends without state encryption or locking
+// - Azure public blob access, TLS below 1.2, NSG rules open to the internet, SQL servers without auditing and key vault soft delete
+// - Public GCS buckets, instances with the default service account and full scopes, firewall rules from 0.0.0.0/0 and Cloud SQL without SSL
+// - Variables and locals never referenced in their module, and references to undeclared ones
+// - References to undeclared resources, data sources and modules, dependency cycles, unused module outputs
+// - Deprecated data source types, hardcoded secrets in data source attributes and locals
+// - Variables without a type or description, and secret-like variables not marked sensitive
+// - Missing or overly loose required_version and required_providers constraints
+// - Checked attributes whose variables or locals have no known value
+//
+// Attributes referring to variables and locals are checked with the values they resolve to,
+// see evalContexts.
+// Local modules called from path are scanned too, see scanWithModules.
 func Scan(path string) ([]finding.Finding, error) {
+	return scanWithModules(path, newReferenceGraph())
+}
+
+// scanTree runs the checks on the .tf files under path and returns the local module calls
+// found. The blocks and references of the files are added to graph, which is checked once
+// the called modules are scanned too.
+func scanTree(path string, graph *referenceGraph) ([]finding.Finding, []moduleCall, error) {
 	parser := hclparse.NewParser()
 	var findings []finding.Finding
+	var calls []moduleCall
 	// Keywords for detecting secrets in variable/resource attribute names
 	secretKeywords := []string{"password", "secret", "token", "key", "pwd"}
-	// Required tags on resources to check
-	requiredTags := []string{"Environment", "Owner", "Project"}
+	// S3 encryption, versioning and public access blocks can be configured by separate
+	// resources, so buckets are judged per directory
+	s3 := newS3Buckets()
+	// Azure SQL auditing can be configured by a separate resource too
+	audits := newSQLAuditing()
+	// Variable definitions files are checked once the sensitive variables of their directory are known
+	var tfvars []string
+	sensitive := make(map[string]map[string]bool)
+	// Version constraints usually live in a separate versions.tf, so they are judged per directory too
+	versions := newVersionConstraints()
+	// Variables are declared and referenced across the files of a module
+	usage := newVariableUsage()
+	// Variables and locals are resolved per module so checks judge the values they hold
+	contexts := newEvalContexts()
 
-	err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+	err := scanner.Walk(path, func(p string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return err
 		}
+		if isTfvars(p) {
+			tfvars = append(tfvars, p)
+			return nil
+		}
 		if filepath.Ext(p) != ".tf" {
 			return nil
 		}
@@ -111,14 +180,21 @@ func Scan(path string) ([]finding.Finding, error) {
 				File:     p,
 				Severity: finding.Error,
 				Message:  fmt.Sprintf("Failed to parse HCL file: %s", diag.Error()),
+				Line:     diagLine(diag),
 			})
 			return nil
 		}
+		versions.addFile(p)
 
 		content, _, diag := file.Body.PartialContent(&hcl.BodySchema{
 			Blocks: []hcl.BlockHeaderSchema{
-				{Type: "resource"},
-				{Type: "variable"},
+				{Type: "resource", LabelNames: []string{"type", "name"}},
+				{Type: "variable", LabelNames: []string{"name"}},
+				{Type: "provider", LabelNames: []string{"name"}},
+				{Type: "data", LabelNames: []string{"type", "name"}},
+				{Type: "module", LabelNames: []string{"name"}},
+				{Type: "locals"},
+				{Type: "terraform"},
 			},
 		})
 		if diag.HasErrors() {
@@ -131,8 +207,8 @@ func Scan(path string) ([]finding.Finding, error) {
 		}
 
 		// Track declared and used variables for unused variable detection
-		var declaredVars = make(map[string]bool)
-		// var usedVars = make(map[string]bool)
+		usage.addFile(p, file.Body)
+		graph.addFile(p, file.Body)
 
 		for _, block := range content.Blocks {
 			switch block.Type {
@@ -142,7 +218,11 @@ func Scan(path string) ([]finding.Finding, error) {
 				}
 				resourceType := block.Labels[0]
 				resourceName := block.Labels[1]
-				_ = resourceName
+				if body, ok := block.Body.(*hclsyntax.Body); ok {
+					unresolved := resolve(body, contexts.forDir(filepath.Dir(p)))
+					findings = append(findings, unresolvedFindings(p, resourceType, resourceName, unresolved)...)
+				}
+				versions.useResource(p, resourceType, block.Body, block.DefRange.Start.Line)
 
 				// Check deprecated resource type
 				if msg, deprecated := deprecatedResources[resourceType]; deprecated {
@@ -150,9 +230,34 @@ func Scan(path string) ([]finding.Finding, error) {
 						File:     p,
 						Severity: finding.Warning,
 						Message:  fmt.Sprintf("Resource type '%s' is deprecated: %s", resourceType, msg),
+						Line:     block.DefRange.Start.Line,
 					})
 				}
 
+				// Lifecycle settings and values that force perpetual diffs
+				findings = append(findings, checkLifecycle(p, resourceType, resourceName, block.Body)...)
+
+				// Ingress open to the world on sensitive ports
+				findings = append(findings, checkSecurityGroup(p, resourceType, resourceName, block.Body)...)
+
+				// Encryption at rest, and S3 buckets with their split configuration resources
+				findings = append(findings, checkEncryption(p, resourceType, resourceName, block.Body)...)
+				s3.add(p, resourceType, resourceName, block.Body)
+
+				// Wildcard actions, resources and principals in IAM policy JSON
+				findings = append(findings, checkIAMPolicies(p, resourceType, resourceName, block.Body)...)
+
+				// Azure public blob access, weak TLS, open NSG rules and key vault soft delete
+				findings = append(findings, checkAzure(p, resourceType, resourceName, block.Body)...)
+				audits.add(p, resourceType, resourceName, block.Body)
+
+				// Public GCS buckets, default service accounts with full scopes, open firewalls and Cloud SQL without SSL
+				findings = append(findings, checkGoogle(p, resourceType, resourceName, block.Body)...)
+
+				if resourceType == "tls_private_key" {
+					findings = append(findings, checkTLSKey(p, resourceName, block.Body)...)
+				}
+
 				attrs, diags := block.Body.JustAttributes()
 				if diags.HasErrors() {
 					continue
@@ -170,6 +275,7 @@ func Scan(path string) ([]finding.Finding, error) {
 								File:     p,
 								Severity: finding.Warning,
 								Message:  "S3 bucket ACL is set to public-read (publicly readable)",
+								Line:     aclAttr.Range.Start.Line,
 							})
 						}
 					}
@@ -188,6 +294,7 @@ func Scan(path string) ([]finding.Finding, error) {
 								File:     p,
 								Severity: finding.Warning,
 								Message:  fmt.Sprintf("Resource missing required tag '%s'", tag),
+								Line:     tagsAttr.Range.Start.Line,
 							})
 						}
 					}
@@ -196,27 +303,86 @@ func Scan(path string) ([]finding.Finding, error) {
 						File:     p,
 						Severity: finding.Warning,
 						Message:  "Resource missing 'tags' attribute entirely",
+						Line:     block.DefRange.Start.Line,
 					})
 				}
 
 				// Check resource attributes for hardcoded secrets
-				for attrName, attr := range attrs {
-					lowerName := strings.ToLower(attrName)
-					for _, kw := range secretKeywords {
-						if strings.Contains(lowerName, kw) {
-							val, diag := attr.Expr.Value(nil)
-							if diag.HasErrors() || val.IsNull() {
-								continue
-							}
-							if val.Type() == cty.String && val.AsString() != "" {
-								findings = append(findings, finding.Finding{
-									File:     p,
-									Severity: finding.Error,
-									Message:  fmt.Sprintf("Resource attribute '%s' may contain hardcoded secret", attrName),
-								})
-							}
-							break
-						}
+				for _, attr := range hardcodedSecrets(attrs, secretKeywords) {
+					findings = append(findings, finding.Finding{
+						File:     p,
+						Severity: finding.Error,
+						Message:  fmt.Sprintf("Resource attribute '%s' may contain hardcoded secret", attr.Name),
+						Line:     attr.Range.Start.Line,
+					})
+				}
+
+			case "provider":
+				findings = append(findings, checkProvider(p, block)...)
+				if len(block.Labels) == 1 {
+					versions.use(p, block.Labels[0], block.DefRange.Start.Line)
+				}
+
+			case "terraform":
+				versions.addSettings(p, block.Body)
+				findings = append(findings, checkBackend(p, block.Body)...)
+
+			case "module":
+				versions.useModule(p)
+				if call, ok := localModuleCall(p, block); ok {
+					calls = append(calls, call)
+				}
+
+			case "data":
+				if body, ok := block.Body.(*hclsyntax.Body); ok {
+					resolve(body, contexts.forDir(filepath.Dir(p)))
+				}
+				if len(block.Labels) != 2 {
+					continue // invalid data block
+				}
+				dataType := block.Labels[0]
+				versions.useResource(p, dataType, block.Body, block.DefRange.Start.Line)
+				if msg, deprecated := deprecatedDataSources[dataType]; deprecated {
+					findings = append(findings, finding.Finding{
+						File:     p,
+						Severity: finding.Warning,
+						Message:  fmt.Sprintf("Data source type '%s' is deprecated: %s", dataType, msg),
+						Line:     block.DefRange.Start.Line,
+					})
+				}
+				if dataType == "aws_iam_policy_document" {
+					findings = append(findings, checkPolicyDocument(p, block.Labels[1], block.Body)...)
+				}
+				if attrs, diags := block.Body.JustAttributes(); !diags.HasErrors() {
+					for _, attr := range hardcodedSecrets(attrs, secretKeywords) {
+						findings = append(findings, finding.Finding{
+							File:     p,
+							Severity: finding.Error,
+							Message:  fmt.Sprintf("Data source attribute '%s' may contain hardcoded secret", attr.Name),
+							Line:     attr.Range.Start.Line,
+						})
+					}
+				}
+
+			case "locals":
+				attrs, diags := block.Body.JustAttributes()
+				if diags.HasErrors() {
+					continue
+				}
+				for _, name := range sortedKeys(attrs) {
+					attr := attrs[name]
+					usage.declareLocal(p, name, attr.Range.Start.Line)
+					val, diags := attr.Expr.Value(nil)
+					if diags.HasErrors() {
+						continue // computed from variables, resources or functions
+					}
+					for _, key := range secretValues(name, val, false) {
+						findings = append(findings, finding.Finding{
+							File:     p,
+							Severity: finding.Error,
+							Message:  fmt.Sprintf("Local value '%s' is a hardcoded secret; pass it in through a sensitive variable or read it from a secrets manager", key),
+							Line:     attr.Range.Start.Line,
+						})
 					}
 				}
 
@@ -225,7 +391,9 @@ func Scan(path string) ([]finding.Finding, error) {
 					continue // invalid variable block
 				}
 				varName := block.Labels[0]
-				declaredVars[varName] = true
+				usage.declare(p, varName, block.DefRange.Start.Line)
+				markSensitive(sensitive, p, varName, block.Body)
+				findings = append(findings, checkVariable(p, varName, block)...)
 
 				attrs, diags := block.Body.JustAttributes()
 				if diags.HasErrors() {
@@ -245,6 +413,7 @@ func Scan(path string) ([]finding.Finding, error) {
 									File:     p,
 									Severity: finding.Error,
 									Message:  fmt.Sprintf("Variable '%s' has a hardcoded default secret", varName),
+									Line:     defaultAttr.Range.Start.Line,
 								})
 								break
 							}
@@ -256,6 +425,54 @@ func Scan(path string) ([]finding.Finding, error) {
 
 		return nil
 	})
+	if err != nil {
+		return findings, calls, err
+	}
+	findings = append(findings, s3.findings()...)
+	findings = append(findings, audits.findings()...)
+	findings = append(findings, versions.findings()...)
+	findings = append(findings, usage.findings()...)
+	for _, p := range tfvars {
+		findings = append(findings, checkTfvars(parser, p, sensitive[filepath.Dir(p)])...)
+	}
+
+	// Static credentials in provider and backend configuration
+	_, credentialFindings, err := AuditCredentials(path)
+	findings = append(findings, credentialFindings...)
+
+	return findings, calls, err
+}
+
+// hardcodedSecrets returns the attributes with a secret-like name set to a non-empty literal
+// string, ordered by name. Attributes resolved from variables and locals are not literals
+// here: their secrets are reported where the value is written.
+func hardcodedSecrets(attrs hcl.Attributes, keywords []string) []*hcl.Attribute {
+	var found []*hcl.Attribute
+	for _, name := range sortedKeys(attrs) {
+		attr := attrs[name]
+		if resolved(attr.Expr) {
+			continue
+		}
+		lowerName := strings.ToLower(name)
+		for _, kw := range keywords {
+			if strings.Contains(lowerName, kw) {
+				val, diag := attr.Expr.Value(nil)
+				if !diag.HasErrors() && !val.IsNull() && val.Type() == cty.String && val.AsString() != "" {
+					found = append(found, attr)
+				}
+				break
+			}
+		}
+	}
+	return found
+}
 
-	return findings, err
+// diagLine returns the line of the first diagnostic that has a source range.
+func diagLine(diags hcl.Diagnostics) int {
+	for _, d := range diags {
+		if d.Subject != nil {
+			return d.Subject.Start.Line
+		}
+	}
+	return 0
 }
@@ -0,0 +1,126 @@
+package terraform
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// IsTerraformFile matches the files the Terraform scanner reads: configuration and
+// variable definitions files (terraform.tfvars, *.auto.tfvars and their JSON forms).
+func IsTerraformFile(p string) bool {
+	return filepath.Ext(p) == ".tf" || isTfvars(p)
+}
+
+func isTfvars(p string) bool {
+	return strings.HasSuffix(p, ".tfvars") || strings.HasSuffix(p, ".tfvars.json")
+}
+
+// secretVariable reports whether a variable name suggests it holds a credential.
+func secretVariable(name string) bool {
+	lower := strings.ToLower(name)
+	return credentialAttribute(name) || strings.Contains(lower, "pwd") || strings.HasSuffix(lower, "private_key") || strings.HasSuffix(lower, "api_key")
+}
+
+// markSensitive records, per directory, the variables declared with sensitive = true.
+func markSensitive(sensitive map[string]map[string]bool, p, name string, body hcl.Body) {
+	syntaxBody, ok := body.(*hclsyntax.Body)
+	if !ok {
+		return
+	}
+	attr, ok := syntaxBody.Attributes["sensitive"]
+	if !ok || !literalTrue(attr.Expr) {
+		return
+	}
+	dir := filepath.Dir(p)
+	if sensitive[dir] == nil {
+		sensitive[dir] = make(map[string]bool)
+	}
+	sensitive[dir][name] = true
+}
+
+// checkTfvars reports hardcoded secrets in a variable definitions file: values of variables
+// with credential-like names, nested map keys included, and of variables declared sensitive
+// in the same directory.
+func checkTfvars(parser *hclparse.Parser, p string, sensitive map[string]bool) []finding.Finding {
+	var file *hcl.File
+	var diags hcl.Diagnostics
+	if strings.HasSuffix(p, ".json") {
+		file, diags = parser.ParseJSONFile(p)
+	} else {
+		file, diags = parser.ParseHCLFile(p)
+	}
+	if diags.HasErrors() {
+		return []finding.Finding{{
+			File:     p,
+			Severity: finding.Error,
+			Message:  fmt.Sprintf("Failed to parse variable definitions file: %s", diags.Error()),
+			Line:     diagLine(diags),
+		}}
+	}
+	attrs, diags := file.Body.JustAttributes()
+	if diags.HasErrors() {
+		return nil
+	}
+
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var findings []finding.Finding
+	for _, name := range names {
+		attr := attrs[name]
+		val, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			continue
+		}
+		for _, key := range secretValues(name, val, sensitive[name]) {
+			reason := "has a hardcoded secret value"
+			if sensitive[name] && key == name {
+				reason = "is declared sensitive but its value is committed"
+			}
+			findings = append(findings, finding.Finding{
+				File:     p,
+				Severity: finding.Error,
+				Message:  fmt.Sprintf("Variable '%s' %s; pass it through TF_VAR_ environment variables or a secrets manager instead", key, reason),
+				Line:     attr.Range.Start.Line,
+			})
+		}
+	}
+	return findings
+}
+
+// secretValues returns the dotted names of the non-empty literal secrets in val.
+func secretValues(name string, val cty.Value, sensitive bool) []string {
+	if val.IsNull() || !val.IsKnown() {
+		return nil
+	}
+	t := val.Type()
+	switch {
+	case t == cty.String:
+		s := val.AsString()
+		if s == "" || envPlaceholderRegex.MatchString(s) || !(sensitive || secretVariable(name[strings.LastIndex(name, ".")+1:])) {
+			return nil
+		}
+		return []string{name}
+	case t.IsObjectType() || t.IsMapType():
+		var out []string
+		for it := val.ElementIterator(); it.Next(); {
+			k, v := it.Element()
+			out = append(out, secretValues(name+"."+k.AsString(), v, sensitive)...)
+		}
+		sort.Strings(out)
+		return out
+	}
+	return nil
+}
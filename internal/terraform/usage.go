@@ -0,0 +1,156 @@
+package terraform
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+type variableSite struct {
+	file string
+	line int
+}
+
+// variableUsage tracks, per directory (a Terraform module), the declared variables and
+// locals and the var.* and local.* references made anywhere in the module, so declarations
+// and uses in different files are matched up.
+type variableUsage struct {
+	declared   map[string]map[string]variableSite
+	referenced map[string]map[string]variableSite // first reference of each name
+
+	declaredLocals   map[string]map[string]variableSite
+	referencedLocals map[string]map[string]variableSite
+}
+
+func newVariableUsage() *variableUsage {
+	return &variableUsage{
+		declared:         make(map[string]map[string]variableSite),
+		referenced:       make(map[string]map[string]variableSite),
+		declaredLocals:   make(map[string]map[string]variableSite),
+		referencedLocals: make(map[string]map[string]variableSite),
+	}
+}
+
+// declare records a variable block.
+func (u *variableUsage) declare(p, name string, line int) {
+	record(u.declared, filepath.Dir(p), name, variableSite{p, line})
+}
+
+// declareLocal records a local value of a locals block.
+func (u *variableUsage) declareLocal(p, name string, line int) {
+	record(u.declaredLocals, filepath.Dir(p), name, variableSite{p, line})
+}
+
+// addFile records the var.* and local.* references in every expression of a file.
+// References inside variable blocks (validation conditions) do not count as uses; a local
+// referring to another one uses it.
+func (u *variableUsage) addFile(p string, body hcl.Body) {
+	syntaxBody, ok := body.(*hclsyntax.Body)
+	if !ok {
+		return
+	}
+	dir := filepath.Dir(p)
+	for _, block := range syntaxBody.Blocks {
+		if block.Type == "variable" {
+			continue
+		}
+		hclsyntax.VisitAll(block.Body, func(node hclsyntax.Node) hcl.Diagnostics {
+			expr, ok := node.(*hclsyntax.ScopeTraversalExpr)
+			if !ok {
+				return nil
+			}
+			if name, ok := referenceName(expr.Traversal, "var"); ok {
+				record(u.referenced, dir, name, variableSite{p, expr.SrcRange.Start.Line})
+			} else if name, ok := referenceName(expr.Traversal, "local"); ok {
+				record(u.referencedLocals, dir, name, variableSite{p, expr.SrcRange.Start.Line})
+			}
+			return nil
+		})
+	}
+}
+
+func record(sites map[string]map[string]variableSite, dir, name string, site variableSite) {
+	if sites[dir] == nil {
+		sites[dir] = make(map[string]variableSite)
+	}
+	if _, seen := sites[dir][name]; !seen {
+		sites[dir][name] = site
+	}
+}
+
+// findings reports variables and locals never referenced in their module and references
+// to ones the module does not declare, which terraform validate rejects.
+func (u *variableUsage) findings() []finding.Finding {
+	var findings []finding.Finding
+	for _, dir := range sortedKeys(u.declared) {
+		for _, name := range sortedKeys(u.declared[dir]) {
+			if _, used := u.referenced[dir][name]; used {
+				continue
+			}
+			site := u.declared[dir][name]
+			findings = append(findings, finding.Finding{
+				File:     site.file,
+				Severity: finding.Warning,
+				Message:  fmt.Sprintf("Variable '%s' is declared but never referenced in its module", name),
+				Line:     site.line,
+			})
+		}
+	}
+	for _, dir := range sortedKeys(u.referenced) {
+		for _, name := range sortedKeys(u.referenced[dir]) {
+			if _, ok := u.declared[dir][name]; ok {
+				continue
+			}
+			site := u.referenced[dir][name]
+			findings = append(findings, finding.Finding{
+				File:     site.file,
+				Severity: finding.Error,
+				Message:  fmt.Sprintf("Reference to undeclared variable 'var.%s'", name),
+				Line:     site.line,
+			})
+		}
+	}
+	for _, dir := range sortedKeys(u.declaredLocals) {
+		for _, name := range sortedKeys(u.declaredLocals[dir]) {
+			if _, used := u.referencedLocals[dir][name]; used {
+				continue
+			}
+			site := u.declaredLocals[dir][name]
+			findings = append(findings, finding.Finding{
+				File:     site.file,
+				Severity: finding.Warning,
+				Message:  fmt.Sprintf("Local value '%s' is declared but never referenced in its module", name),
+				Line:     site.line,
+			})
+		}
+	}
+	for _, dir := range sortedKeys(u.referencedLocals) {
+		for _, name := range sortedKeys(u.referencedLocals[dir]) {
+			if _, ok := u.declaredLocals[dir][name]; ok {
+				continue
+			}
+			site := u.referencedLocals[dir][name]
+			findings = append(findings, finding.Finding{
+				File:     site.file,
+				Severity: finding.Error,
+				Message:  fmt.Sprintf("Reference to undeclared local value 'local.%s'", name),
+				Line:     site.line,
+			})
+		}
+	}
+	return findings
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
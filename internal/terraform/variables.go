@@ -0,0 +1,76 @@
+package terraform
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/salchaD-27/infra-check/internal/config"
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// variableRules configures the variable hygiene checks; see Configure.
+var variableRules config.VariableRules
+
+// Configure applies the terraform section of the project configuration to later scans.
+func Configure(cfg *config.Config) {
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	variableRules = cfg.Terraform.Variables
+}
+
+// checkVariable reports variable blocks without a type or description, and secret-like
+// variables not declared sensitive, which lets plan and apply output print their values.
+func checkVariable(p, name string, block *hcl.Block) []finding.Finding {
+	body, ok := block.Body.(*hclsyntax.Body)
+	if !ok || variableRules.Ignored(name) {
+		return nil
+	}
+	var findings []finding.Finding
+	add := func(format string, args ...interface{}) {
+		findings = append(findings, finding.Finding{
+			File:     p,
+			Severity: finding.Warning,
+			Message:  fmt.Sprintf(format, args...),
+			Line:     block.DefRange.Start.Line,
+		})
+	}
+
+	if _, ok := body.Attributes["type"]; !ok && variableRules.TypeRequired() {
+		add("Variable '%s' has no type constraint, so any value is accepted", name)
+	}
+	if desc, ok := body.Attributes["description"]; variableRules.DescriptionRequired() && (!ok || isEmptyLiteral(desc.Expr)) {
+		add("Variable '%s' has no description", name)
+	}
+	if variableRules.SensitiveRequired() && secretLikeVariable(name) {
+		if attr, ok := body.Attributes["sensitive"]; !ok || !literalTrue(attr.Expr) {
+			add("Variable '%s' looks like a secret but is not declared sensitive = true, so its value is shown in plan output", name)
+		}
+	}
+	return findings
+}
+
+// secretLikeVariable reports whether a variable name suggests a secret, including the
+// configured extra name fragments.
+func secretLikeVariable(name string) bool {
+	if secretVariable(name) {
+		return true
+	}
+	lower := strings.ToLower(name)
+	for _, fragment := range variableRules.SensitiveNames {
+		if fragment != "" && strings.Contains(lower, strings.ToLower(fragment)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmptyLiteral reports whether expr is the literal empty string.
+func isEmptyLiteral(expr hclsyntax.Expression) bool {
+	val, diags := expr.Value(nil)
+	return !diags.HasErrors() && val.IsKnown() && !val.IsNull() && val.Type() == cty.String && strings.TrimSpace(val.AsString()) == ""
+}
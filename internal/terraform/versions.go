@@ -0,0 +1,240 @@
+package terraform
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+)
+
+// Providers built into Terraform itself, which need no version constraint.
+var builtinProviders = map[string]bool{"terraform": true}
+
+type providerUse struct {
+	file string
+	line int
+}
+
+type providerRequirement struct {
+	file, version string
+	line          int
+}
+
+// versionConstraints tracks, per directory, the terraform settings block and the
+// providers the configuration uses, so that constraints spread across files
+// (typically versions.tf) are judged for the module as a whole.
+type versionConstraints struct {
+	files    map[string][]string
+	active   map[string]bool                 // directories declaring resources, data sources, providers, modules or settings
+	required map[string]*providerRequirement // dir/name -> requirement
+	version  map[string]*providerRequirement // dir -> required_version
+	uses     map[string]map[string]providerUse
+}
+
+func newVersionConstraints() *versionConstraints {
+	return &versionConstraints{
+		files:    make(map[string][]string),
+		active:   make(map[string]bool),
+		required: make(map[string]*providerRequirement),
+		version:  make(map[string]*providerRequirement),
+		uses:     make(map[string]map[string]providerUse),
+	}
+}
+
+// addFile records a .tf file of the configuration.
+func (v *versionConstraints) addFile(p string) {
+	dir := filepath.Dir(p)
+	v.files[dir] = append(v.files[dir], p)
+}
+
+// use records the provider a resource, data source or provider block depends on.
+func (v *versionConstraints) use(p, provider string, line int) {
+	if builtinProviders[provider] {
+		return
+	}
+	dir := filepath.Dir(p)
+	v.active[dir] = true
+	if v.uses[dir] == nil {
+		v.uses[dir] = make(map[string]providerUse)
+	}
+	if _, seen := v.uses[dir][provider]; !seen {
+		v.uses[dir][provider] = providerUse{p, line}
+	}
+}
+
+// useModule records a module call, which makes its directory a configuration to constrain.
+func (v *versionConstraints) useModule(p string) {
+	v.active[filepath.Dir(p)] = true
+}
+
+// useResource records the provider implied by a resource or data source type, or
+// chosen explicitly through its provider meta-argument.
+func (v *versionConstraints) useResource(p, resourceType string, body hcl.Body, line int) {
+	provider, _, _ := strings.Cut(resourceType, "_")
+	if syntaxBody, ok := body.(*hclsyntax.Body); ok {
+		if attr, ok := syntaxBody.Attributes["provider"]; ok {
+			if traversal, diags := hcl.AbsTraversalForExpr(attr.Expr); !diags.HasErrors() {
+				provider = traversal.RootName()
+			}
+		}
+	}
+	v.use(p, provider, line)
+}
+
+// addSettings records the required_version and required_providers of a terraform block.
+func (v *versionConstraints) addSettings(p string, body hcl.Body) {
+	syntaxBody, ok := body.(*hclsyntax.Body)
+	if !ok {
+		return
+	}
+	dir := filepath.Dir(p)
+	v.active[dir] = true
+	if attr, ok := syntaxBody.Attributes["required_version"]; ok {
+		v.version[dir] = &providerRequirement{file: p, version: constraintString(attr.Expr), line: attr.SrcRange.Start.Line}
+	}
+	for _, block := range syntaxBody.Blocks {
+		if block.Type != "required_providers" {
+			continue
+		}
+		for name, attr := range block.Body.Attributes {
+			req := &providerRequirement{file: p, line: attr.SrcRange.Start.Line}
+			val, diags := attr.Expr.Value(nil)
+			switch {
+			case diags.HasErrors() || val.IsNull() || !val.IsKnown():
+			case val.Type() == cty.String:
+				// Legacy shorthand: aws = "~> 5.0"
+				req.version = val.AsString()
+			case val.Type().IsObjectType() && val.Type().HasAttribute("version"):
+				if version := val.GetAttr("version"); !version.IsNull() && version.IsKnown() && version.Type() == cty.String {
+					req.version = version.AsString()
+				}
+			}
+			v.required[dir+"/"+name] = req
+			if _, used := v.uses[dir][name]; !used {
+				v.use(p, name, req.line)
+			}
+		}
+	}
+}
+
+// constraintString returns a literal version constraint, or "" when it is not a literal.
+func constraintString(expr hclsyntax.Expression) string {
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() || val.IsNull() || !val.IsKnown() || val.Type() != cty.String {
+		return ""
+	}
+	return val.AsString()
+}
+
+// constraintBounds reports whether a version constraint accepts any version at all,
+// and whether it has an upper bound that stops the next major release.
+func constraintBounds(constraint string) (open, bounded bool) {
+	open = true
+	for _, part := range strings.Split(constraint, ",") {
+		part = strings.TrimSpace(part)
+		op := strings.TrimRight(part, "0123456789.v ")
+		version := strings.TrimSpace(strings.TrimPrefix(part, op))
+		switch strings.TrimSpace(op) {
+		case ">=", ">":
+			if strings.Trim(version, "0.v") != "" {
+				open = false
+			}
+		case "", "=", "~>", "<", "<=":
+			if part != "" {
+				open, bounded = false, true
+			}
+		case "!=":
+		default:
+			open = false
+		}
+	}
+	return open, bounded
+}
+
+// findings reports modules without required_version, providers without a version
+// constraint and constraints that do not pin anything.
+func (v *versionConstraints) findings() []finding.Finding {
+	dirs := make([]string, 0, len(v.files))
+	for dir := range v.files {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	var findings []finding.Finding
+	for _, dir := range dirs {
+		if !v.active[dir] {
+			continue // e.g. a directory holding only variables or outputs
+		}
+		files := append([]string(nil), v.files[dir]...)
+		sort.Strings(files)
+
+		switch req := v.version[dir]; {
+		case req == nil:
+			findings = append(findings, finding.Finding{
+				File:     files[0],
+				Severity: finding.Warning,
+				Message:  fmt.Sprintf("Configuration in %s sets no required_version; add a terraform block constraining the Terraform CLI version", dir),
+			})
+		case req.version != "":
+			if open, _ := constraintBounds(req.version); open {
+				findings = append(findings, finding.Finding{
+					File:     req.file,
+					Severity: finding.Warning,
+					Message:  fmt.Sprintf("required_version '%s' accepts any Terraform version", req.version),
+					Line:     req.line,
+				})
+			}
+		}
+
+		providers := make([]string, 0, len(v.uses[dir]))
+		for name := range v.uses[dir] {
+			providers = append(providers, name)
+		}
+		sort.Strings(providers)
+		for _, name := range providers {
+			use := v.uses[dir][name]
+			req := v.required[dir+"/"+name]
+			switch {
+			case req == nil:
+				findings = append(findings, finding.Finding{
+					File:     use.file,
+					Severity: finding.Warning,
+					Message:  fmt.Sprintf("Provider '%s' is not listed in required_providers, so any version is installed; add it with a version constraint", name),
+					Line:     use.line,
+				})
+				continue
+			case req.version == "":
+				findings = append(findings, finding.Finding{
+					File:     req.file,
+					Severity: finding.Warning,
+					Message:  fmt.Sprintf("Provider '%s' in required_providers has no version constraint", name),
+					Line:     req.line,
+				})
+				continue
+			}
+			switch open, bounded := constraintBounds(req.version); {
+			case open:
+				findings = append(findings, finding.Finding{
+					File:     req.file,
+					Severity: finding.Warning,
+					Message:  fmt.Sprintf("Provider '%s' version constraint '%s' accepts any version", name, req.version),
+					Line:     req.line,
+				})
+			case !bounded:
+				findings = append(findings, finding.Finding{
+					File:     req.file,
+					Severity: finding.Info,
+					Message:  fmt.Sprintf("Provider '%s' version constraint '%s' has no upper bound and will accept the next major release; consider '~>'", name, req.version),
+					Line:     req.line,
+				})
+			}
+		}
+	}
+	return findings
+}
@@ -0,0 +1,74 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/salchaD-27/infra-check/internal/engine"
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/registry"
+)
+
+// Reasons written into suppression comments are cut to this many characters.
+const maxReason = 80
+
+// commentPrefix returns how a line comment starts in file, or "" for files without comments.
+func commentPrefix(file string) string {
+	base := strings.ToLower(filepath.Base(file))
+	switch filepath.Ext(base) {
+	case ".tf", ".tfvars", ".hcl", ".pp", ".yml", ".yaml", ".sh", ".py", ".toml":
+		return "#"
+	case ".ts", ".js", ".go":
+		return "//"
+	}
+	if base == "dockerfile" || strings.HasPrefix(base, "dockerfile.") || strings.HasSuffix(base, ".dockerfile") {
+		return "#"
+	}
+	return ""
+}
+
+// writeSuppression inserts a suppression comment for f on the line above it, indented like
+// that line. The finding's rule ID, when it has one, limits the comment to that rule; its
+// message becomes the reason, so the comment says what it silences.
+func writeSuppression(f finding.Finding) error {
+	if f.Line == 0 {
+		return fmt.Errorf("the finding has no line to put a comment above")
+	}
+	prefix := commentPrefix(f.File)
+	if prefix == "" {
+		return fmt.Errorf("%s files have no comments", filepath.Ext(f.File))
+	}
+	info, err := os.Stat(f.File)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(f.File)
+	if err != nil {
+		return err
+	}
+	newline := "\n"
+	if strings.Contains(string(data), "\r\n") {
+		newline = "\r\n"
+	}
+	lines := strings.Split(string(data), newline)
+	if f.Line > len(lines) {
+		return fmt.Errorf("%s has no line %d", f.File, f.Line)
+	}
+
+	target := lines[f.Line-1]
+	indent := target[:len(target)-len(strings.TrimLeft(target, " \t"))]
+	comment := indent + prefix + " " + engine.SuppressMarker
+	if id := registry.ID(f); id != "" {
+		comment += " " + id
+	}
+	reason := strings.TrimSpace(strings.TrimPrefix(f.Message, "["+f.RuleID()+"]"))
+	if r := []rune(reason); len(r) > maxReason {
+		reason = string(r[:maxReason-1]) + "…"
+	}
+	comment += " -- " + reason
+
+	lines = append(lines[:f.Line-1], append([]string{comment}, lines[f.Line-1:]...)...)
+	return os.WriteFile(f.File, []byte(strings.Join(lines, newline)), info.Mode().Perm())
+}
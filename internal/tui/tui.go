@@ -0,0 +1,415 @@
+// terminal UI for browsing, filtering and suppressing findings
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/registry"
+)
+
+// ANSI styles
+const (
+	reset   = "\033[0m"
+	bold    = "\033[1m"
+	dim     = "\033[2m"
+	reverse = "\033[7m"
+)
+
+var severityStyles = map[finding.Severity]string{
+	finding.Error:   "\033[31m",
+	finding.Warning: "\033[33m",
+	finding.Info:    "\033[36m",
+}
+
+var severityRank = map[finding.Severity]int{finding.Error: 0, finding.Warning: 1, finding.Info: 2}
+
+// Run shows findings until the user quits.
+func Run(findings []finding.Finding) error {
+	m := &model{groupBy: "file"}
+	for _, f := range findings {
+		m.items = append(m.items, &item{Finding: f})
+	}
+	m.layout()
+	m.move(0)
+	_, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+	return err
+}
+
+type item struct {
+	finding.Finding
+	suppressed bool
+}
+
+// row is a line of the list: a group header or a finding.
+type row struct {
+	header string
+	item   *item
+}
+
+type model struct {
+	items   []*item
+	rows    []row
+	cursor  int // index into rows, always on a finding
+	offset  int // first row shown
+	width   int
+	height  int
+	groupBy string // "file" or "severity"
+
+	filter    string // rule ID or message text findings must contain
+	exactRule string // set by 'r': only findings of this rule
+	typing    bool   // editing filter
+	status    string
+}
+
+type editorDone struct{ err error }
+
+func (m *model) Init() tea.Cmd { return nil }
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.scroll()
+	case editorDone:
+		if msg.err != nil {
+			m.status = "Editor failed: " + msg.err.Error()
+		}
+	case tea.KeyMsg:
+		if m.typing {
+			return m, m.typeFilter(msg)
+		}
+		return m, m.key(msg)
+	}
+	return m, nil
+}
+
+func (m *model) key(msg tea.KeyMsg) tea.Cmd {
+	m.status = ""
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return tea.Quit
+	case "up", "k":
+		m.move(-1)
+	case "down", "j":
+		m.move(1)
+	case "pgup", "ctrl+u":
+		m.move(-max(m.listHeight()-1, 1))
+	case "pgdown", "ctrl+d":
+		m.move(max(m.listHeight()-1, 1))
+	case "home", "g":
+		m.move(-len(m.rows))
+	case "end", "G":
+		m.move(len(m.rows))
+	case "tab":
+		if m.groupBy == "file" {
+			m.groupBy = "severity"
+		} else {
+			m.groupBy = "file"
+		}
+		m.relayout()
+	case "/":
+		m.typing, m.exactRule = true, ""
+	case "r":
+		if it := m.selected(); it != nil {
+			if m.exactRule != "" {
+				m.exactRule = ""
+			} else {
+				m.exactRule, m.filter = registry.Key(it.Finding), ""
+			}
+			m.relayout()
+		}
+	case "esc":
+		m.filter, m.exactRule = "", ""
+		m.relayout()
+	case "enter", "o":
+		if it := m.selected(); it != nil {
+			return tea.ExecProcess(editorCommand(it.File, it.Line), func(err error) tea.Msg { return editorDone{err} })
+		}
+	case "s":
+		if it := m.selected(); it != nil {
+			m.suppress(it)
+		}
+	}
+	return nil
+}
+
+func (m *model) typeFilter(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.typing = false
+	case tea.KeyEsc:
+		m.typing, m.filter = false, ""
+	case tea.KeyBackspace:
+		if r := []rune(m.filter); len(r) > 0 {
+			m.filter = string(r[:len(r)-1])
+		}
+	case tea.KeyCtrlC:
+		return tea.Quit
+	case tea.KeyRunes, tea.KeySpace:
+		m.filter += string(msg.Runes)
+	}
+	m.relayout()
+	return nil
+}
+
+// suppress writes a suppression comment for it and hides it.
+func (m *model) suppress(it *item) {
+	if err := writeSuppression(it.Finding); err != nil {
+		m.status = "Cannot suppress: " + err.Error()
+		return
+	}
+	it.suppressed = true
+	id := registry.ID(it.Finding)
+	for _, other := range m.items {
+		if other == it || other.File != it.File {
+			continue
+		}
+		// the comment silences the same rule, or everything without an ID, on that line too
+		if other.Line == it.Line && (id == "" || registry.ID(other.Finding) == id) {
+			other.suppressed = true
+		}
+		// and it went in above the finding, so everything below it in the file moved down
+		if other.Line >= it.Line {
+			other.Line++
+		}
+	}
+	m.status = fmt.Sprintf("Suppressed with a comment above %s", it.Location())
+	m.relayout()
+}
+
+// visible reports whether it passes the filters.
+func (m *model) visible(it *item) bool {
+	if it.suppressed {
+		return false
+	}
+	if m.exactRule != "" {
+		return registry.Key(it.Finding) == m.exactRule
+	}
+	if m.filter == "" {
+		return true
+	}
+	filter := strings.ToLower(m.filter)
+	return strings.Contains(strings.ToLower(registry.ID(it.Finding)), filter) || strings.Contains(strings.ToLower(it.Message), filter)
+}
+
+// layout builds the rows, grouped by file or by severity.
+func (m *model) layout() {
+	var shown []*item
+	for _, it := range m.items {
+		if m.visible(it) {
+			shown = append(shown, it)
+		}
+	}
+	byFile := m.groupBy == "file"
+	sort.SliceStable(shown, func(i, j int) bool {
+		a, b := shown[i], shown[j]
+		if byFile && a.File != b.File {
+			return a.File < b.File
+		}
+		if severityRank[a.Severity] != severityRank[b.Severity] {
+			return severityRank[a.Severity] < severityRank[b.Severity]
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+
+	m.rows = m.rows[:0]
+	for i := 0; i < len(shown); {
+		key := func(it *item) string {
+			if byFile {
+				return it.File
+			}
+			return string(it.Severity)
+		}
+		j := i
+		counts := make(map[finding.Severity]int)
+		for j < len(shown) && key(shown[j]) == key(shown[i]) {
+			counts[shown[j].Severity]++
+			j++
+		}
+		header := fmt.Sprintf("%s (%d)", key(shown[i]), j-i)
+		if byFile {
+			header = fmt.Sprintf("%s (%d error(s), %d warning(s), %d info)", shown[i].File, counts[finding.Error], counts[finding.Warning], counts[finding.Info])
+		}
+		m.rows = append(m.rows, row{header: header})
+		for _, it := range shown[i:j] {
+			m.rows = append(m.rows, row{item: it})
+		}
+		i = j
+	}
+}
+
+// relayout rebuilds the rows keeping the cursor on the selected finding where possible.
+func (m *model) relayout() {
+	selected, previous := m.selected(), m.cursor
+	m.layout()
+	// a finding that disappeared leaves the cursor where it was
+	m.cursor = min(previous, max(len(m.rows)-1, 0))
+	for i, r := range m.rows {
+		if r.item != nil && r.item == selected {
+			m.cursor = i
+		}
+	}
+	m.move(0)
+}
+
+func (m *model) selected() *item {
+	if m.cursor < len(m.rows) {
+		return m.rows[m.cursor].item
+	}
+	return nil
+}
+
+// move moves the cursor by delta rows, skipping group headers.
+func (m *model) move(delta int) {
+	if len(m.rows) == 0 {
+		m.cursor = 0
+		return
+	}
+	c := min(max(m.cursor+delta, 0), len(m.rows)-1)
+	dir := 1
+	if delta < 0 {
+		dir = -1
+	}
+	if m.rows[c].item == nil {
+		// a header: go on the way we were moving, or back at the ends of the list
+		if n := m.nextItem(c, dir); n >= 0 {
+			c = n
+		} else if n := m.nextItem(c, -dir); n >= 0 {
+			c = n
+		}
+	}
+	m.cursor = c
+	m.scroll()
+}
+
+// nextItem returns the first finding row from row i on in direction dir, or -1.
+func (m *model) nextItem(i, dir int) int {
+	for ; i >= 0 && i < len(m.rows); i += dir {
+		if m.rows[i].item != nil {
+			return i
+		}
+	}
+	return -1
+}
+
+// listHeight is the number of rows between the title and help lines.
+func (m *model) listHeight() int { return max(m.height-2, 1) }
+
+// scroll keeps the cursor, and the header of its group at the top, in view.
+func (m *model) scroll() {
+	h := m.listHeight()
+	if m.cursor < m.offset+1 {
+		m.offset = max(m.cursor-1, 0)
+	}
+	if m.cursor >= m.offset+h {
+		m.offset = m.cursor - h + 1
+	}
+}
+
+func (m *model) View() string {
+	var b strings.Builder
+	counts := make(map[finding.Severity]int)
+	total := 0
+	for _, it := range m.items {
+		if !it.suppressed {
+			counts[it.Severity]++
+			total++
+		}
+	}
+	title := fmt.Sprintf("infra-check: %d finding(s), %d error(s), %d warning(s), %d info, grouped by %s", total, counts[finding.Error], counts[finding.Warning], counts[finding.Info], m.groupBy)
+	switch {
+	case m.exactRule != "":
+		title += ", rule: " + m.exactRule
+	case m.filter != "" || m.typing:
+		title += ", filter: " + m.filter
+	}
+	b.WriteString(bold + m.fit(title) + reset + "\n")
+
+	h := m.listHeight()
+	for i := m.offset; i < min(m.offset+h, len(m.rows)); i++ {
+		r := m.rows[i]
+		if r.item == nil {
+			b.WriteString(bold + m.fit(r.header) + reset + "\n")
+			continue
+		}
+		loc := fmt.Sprintf("%4d", r.item.Line)
+		if m.groupBy == "severity" {
+			loc = r.item.Location()
+		}
+		text := m.fit(fmt.Sprintf("  %-5s %s  %s", r.item.Severity, loc, r.item.Message))
+		if i == m.cursor {
+			b.WriteString(reverse + text + reset + "\n")
+			continue
+		}
+		// color the severity only; text is plain up to there
+		sev := string(r.item.Severity)
+		if k := strings.Index(text, sev); k >= 0 {
+			text = text[:k] + severityStyles[r.item.Severity] + sev + reset + text[k+len(sev):]
+		}
+		b.WriteString(text + "\n")
+	}
+	if len(m.rows) == 0 {
+		b.WriteString(dim + "No findings to show." + reset + "\n")
+		h--
+	}
+	for i := len(m.rows) - m.offset; i < h; i++ {
+		b.WriteString("\n")
+	}
+
+	help := "↑/↓ move  enter open  s suppress  r same rule  / filter  esc clear  tab group  q quit"
+	if m.typing {
+		help = "Filter by rule ID or message: " + m.filter + "_  (enter apply, esc cancel)"
+	}
+	if m.status != "" {
+		help = m.status
+	}
+	b.WriteString(dim + m.fit(help) + reset)
+	return b.String()
+}
+
+// fit cuts s to the terminal width.
+func (m *model) fit(s string) string {
+	if m.width <= 0 {
+		return s
+	}
+	r := []rune(s)
+	if len(r) > m.width {
+		return string(r[:max(m.width-1, 0)]) + "…"
+	}
+	return s
+}
+
+// editorCommand opens file at line in $VISUAL or $EDITOR, vi by default.
+func editorCommand(file string, line int) *exec.Cmd {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	args := strings.Fields(editor)
+	line = max(line, 1)
+	switch filepath.Base(args[0]) {
+	case "code", "code-insiders", "codium", "subl":
+		if filepath.Base(args[0]) == "subl" {
+			args = append(args, fmt.Sprintf("%s:%d", file, line))
+		} else {
+			args = append(args, "-g", fmt.Sprintf("%s:%d", file, line))
+		}
+	default: // vi, vim, nvim, nano, emacs, micro and most others
+		args = append(args, fmt.Sprintf("+%d", line), file)
+	}
+	return exec.Command(args[0], args[1:]...)
+}
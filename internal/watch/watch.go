@@ -0,0 +1,116 @@
+// file system watching that re-runs scans as files change
+package watch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// Debounce is how long changes must settle before onChange runs, so an editor saving
+// several files (or writing a file in steps) triggers one re-scan.
+const Debounce = 100 * time.Millisecond
+
+// Directories never watched: VCS metadata and downloaded dependencies.
+var skipDirs = map[string]bool{".git": true, ".hg": true, ".svn": true, ".terraform": true, "node_modules": true}
+
+// Run watches roots, each a directory tree or a single file, and calls onChange with the
+// files that changed and match, in sorted order, until ctx is done. Directories created
+// while watching are watched too.
+func Run(ctx context.Context, roots []string, match func(path string) bool, onChange func(changed []string)) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	var dirs []string
+	files := make(map[string]bool)
+	for _, root := range roots {
+		info, err := os.Stat(root)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if err := addTree(w, root); err != nil {
+				return err
+			}
+			dirs = append(dirs, filepath.Clean(root))
+			continue
+		}
+		// editors replace files on save, so watch the directory and filter to the file
+		files[filepath.Clean(root)] = true
+		if err := w.Add(filepath.Dir(root)); err != nil {
+			return err
+		}
+	}
+	inTree := func(p string) bool {
+		for _, dir := range dirs {
+			if rel, err := filepath.Rel(dir, p); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return true
+			}
+		}
+		return false
+	}
+	inner := match
+	match = func(p string) bool { return (files[filepath.Clean(p)] || inTree(p)) && inner(p) }
+
+	pending := make(map[string]bool)
+	timer := time.NewTimer(Debounce)
+	timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-w.Events:
+			if !ok {
+				return nil
+			}
+			if event.Has(fsnotify.Create) && inTree(event.Name) {
+				if fi, err := os.Stat(event.Name); err == nil && fi.IsDir() {
+					addTree(w, event.Name)
+					continue
+				}
+			}
+			if event.Has(fsnotify.Chmod) || !match(event.Name) {
+				continue
+			}
+			pending[event.Name] = true
+			timer.Reset(Debounce)
+		case err, ok := <-w.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintf(os.Stderr, "watch: %v\n", err)
+		case <-timer.C:
+			changed := make([]string, 0, len(pending))
+			for p := range pending {
+				changed = append(changed, p)
+			}
+			sort.Strings(changed)
+			pending = make(map[string]bool)
+			onChange(changed)
+		}
+	}
+}
+
+// addTree watches dir and the directories below it that are not excluded.
+func addTree(w *fsnotify.Watcher, dir string) error {
+	return scanner.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return nil
+		}
+		if p != dir && (skipDirs[info.Name()] || strings.HasPrefix(info.Name(), ".")) {
+			return filepath.SkipDir
+		}
+		return w.Add(p)
+	})
+}
@@ -0,0 +1,325 @@
+// Package infracheck is the programmatic API for embedding infra-check in other
+// Go programs, e.g. a platform service scanning every team repository nightly.
+package infracheck
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/salchaD-27/infra-check/internal/config"
+	"github.com/salchaD-27/infra-check/internal/engine"
+	"github.com/salchaD-27/infra-check/internal/finding"
+	"github.com/salchaD-27/infra-check/internal/rules"
+	"github.com/salchaD-27/infra-check/internal/scanner"
+)
+
+// Finding and Severity are re-exported so callers can work with results.
+type (
+	Finding  = finding.Finding
+	Severity = finding.Severity
+)
+
+const (
+	Info    = finding.Info
+	Warning = finding.Warning
+	Error   = finding.Error
+)
+
+// DefaultScanners run on targets that do not list their own.
+var DefaultScanners = []string{"terraform", "ansible", "puppet"}
+
+// Target is one independent root to scan.
+type Target struct {
+	Name     string   // label used in results; defaults to Root
+	Root     string   // directory or file to scan
+	Scanners []string // registered scanner names, e.g. terraform or helm; DefaultScanners when empty
+}
+
+// Result holds the outcome for a single target. A failing target never affects others.
+type Result struct {
+	Target    Target
+	Findings  []Finding
+	Err       error
+	Duration  time.Duration
+	CacheHits int // scanners whose findings were served from the shared cache
+}
+
+// Metrics aggregates a whole batch run.
+type Metrics struct {
+	Targets    int
+	Failed     int
+	Findings   int
+	BySeverity map[Severity]int
+	CacheHits  int
+	Duration   time.Duration
+}
+
+// Batch scans many targets in one process. Targets run concurrently while sharing
+// custom rules (loaded once) and a content-addressed findings cache, so identical
+// trees (forks, vendored modules, repeated runs) are only analysed once.
+// A Batch is safe for concurrent use and can be Run repeatedly.
+type Batch struct {
+	Targets     []Target
+	Concurrency int    // maximum targets scanned at once; runtime.NumCPU() when zero
+	RulesDir    string // custom rules directory; none when empty
+	ConfigFile  string // project config applied to every target's findings; none when empty
+
+	rulesOnce sync.Once
+	rules     []rules.Rule
+	rulesErr  error
+
+	configOnce sync.Once
+	config     *config.Config
+	configErr  error
+
+	mu    sync.Mutex
+	cache map[string][]finding.Finding
+}
+
+// Add appends a target to the batch.
+func (b *Batch) Add(name, root string, scannerNames ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.Targets = append(b.Targets, Target{Name: name, Root: root, Scanners: scannerNames})
+}
+
+// Run scans every target and returns results in target order plus aggregate metrics.
+// Cancelling ctx stops targets that have not started yet; they report ctx.Err().
+func (b *Batch) Run(ctx context.Context) ([]Result, Metrics) {
+	start := time.Now()
+	b.mu.Lock()
+	targets := append([]Target(nil), b.Targets...)
+	b.mu.Unlock()
+
+	workers := b.Concurrency
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	results := make([]Result, len(targets))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := ctx.Err(); err != nil {
+					results[i] = Result{Target: targets[i], Err: err}
+					continue
+				}
+				results[i] = b.scanTarget(targets[i])
+			}
+		}()
+	}
+	for i := range targets {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	metrics := Metrics{Targets: len(targets), BySeverity: make(map[Severity]int)}
+	for _, r := range results {
+		if r.Err != nil {
+			metrics.Failed++
+		}
+		metrics.CacheHits += r.CacheHits
+		metrics.Findings += len(r.Findings)
+		for _, f := range r.Findings {
+			metrics.BySeverity[f.Severity]++
+		}
+	}
+	metrics.Duration = time.Since(start)
+	return results, metrics
+}
+
+// scanTarget runs the target's scanners, isolating panics to this target.
+func (b *Batch) scanTarget(t Target) (res Result) {
+	start := time.Now()
+	if t.Name == "" {
+		t.Name = t.Root
+	}
+	res.Target = t
+	defer func() {
+		if r := recover(); r != nil {
+			res.Err = fmt.Errorf("scanning %s: panic: %v", t.Name, r)
+		}
+		res.Duration = time.Since(start)
+	}()
+
+	names := t.Scanners
+	if len(names) == 0 {
+		names = DefaultScanners
+	}
+	for _, name := range names {
+		s, ok := scanner.Lookup(name)
+		if !ok {
+			res.Err = fmt.Errorf("unknown scanner '%s'", name)
+			return res
+		}
+		findings, hit, err := b.cachedScan(name, s, t.Root)
+		if err != nil {
+			res.Err = fmt.Errorf("%s scan of %s: %v", name, t.Name, err)
+			return res
+		}
+		if hit {
+			res.CacheHits++
+		}
+		res.Findings = append(res.Findings, findings...)
+	}
+
+	cfg, err := b.loadConfig()
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	// Path classifiers and excludes are written relative to the repository, so apply them to
+	// root-relative paths.
+	res.Findings = rebase(engine.Fingerprint(engine.Process(engine.Dedupe(withoutExcluded(relativeTo(engine.Suppress(res.Findings), t.Root), cfg.Exclude)), cfg), t.Root), t.Root)
+	return res
+}
+
+// withoutExcluded drops the findings in files matching the exclude patterns. Cached scans
+// are shared between batches with different configs, so excludes apply to their findings.
+func withoutExcluded(findings []finding.Finding, patterns []string) []finding.Finding {
+	var out []finding.Finding
+	for _, f := range findings {
+		if !scanner.MatchExcludes(patterns, f.File, false) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// cachedScan returns the findings for root, reusing an earlier scan of identical content.
+// Cached findings are stored with root-relative paths and rebased onto each root.
+func (b *Batch) cachedScan(name string, s scanner.Scanner, root string) ([]finding.Finding, bool, error) {
+	loaded, err := b.loadRules()
+	if err != nil {
+		return nil, false, err
+	}
+
+	key, err := contentKey(name, root, s.FileMatcher())
+	if err != nil {
+		return nil, false, err
+	}
+
+	b.mu.Lock()
+	cached, hit := b.cache[key]
+	b.mu.Unlock()
+	if hit {
+		return rebase(cached, root), true, nil
+	}
+
+	findings, err := s.Scan(root)
+	if err != nil {
+		return nil, false, err
+	}
+	custom, err := rules.EvaluateAll(loaded, name, root)
+	if err != nil {
+		return nil, false, err
+	}
+	findings = append(findings, custom...)
+
+	b.mu.Lock()
+	if b.cache == nil {
+		b.cache = make(map[string][]finding.Finding)
+	}
+	b.cache[key] = relativeTo(findings, root)
+	b.mu.Unlock()
+
+	return findings, false, nil
+}
+
+// loadRules loads the custom rules once for the lifetime of the batch.
+func (b *Batch) loadRules() ([]rules.Rule, error) {
+	b.rulesOnce.Do(func() {
+		if b.RulesDir != "" {
+			b.rules, b.rulesErr = rules.Load(b.RulesDir)
+		}
+	})
+	return b.rules, b.rulesErr
+}
+
+// loadConfig loads the project config once for the lifetime of the batch.
+func (b *Batch) loadConfig() (*config.Config, error) {
+	b.configOnce.Do(func() {
+		b.config = &config.Config{}
+		if b.ConfigFile != "" {
+			b.config, b.configErr = config.Load(b.ConfigFile)
+		}
+	})
+	return b.config, b.configErr
+}
+
+// relativeTo rewrites finding paths relative to root; rebase is its inverse.
+func relativeTo(findings []finding.Finding, root string) []finding.Finding {
+	out := make([]finding.Finding, len(findings))
+	for i, f := range findings {
+		if rel, err := filepath.Rel(root, f.File); err == nil {
+			f.File = rel
+		}
+		out[i] = f
+	}
+	return out
+}
+
+func rebase(findings []finding.Finding, root string) []finding.Finding {
+	out := make([]finding.Finding, len(findings))
+	for i, f := range findings {
+		if !filepath.IsAbs(f.File) {
+			if info, err := os.Stat(root); err == nil && !info.IsDir() {
+				f.File = root
+			} else {
+				f.File = filepath.Join(root, f.File)
+			}
+		}
+		out[i] = f
+	}
+	return out
+}
+
+// contentKey hashes the relative paths and contents of every file the scanner reads under root.
+func contentKey(name, root string, reads scanner.FileMatcher) (string, error) {
+	var files []string
+	err := scanner.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		if reads(p) {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(files)
+
+	h := sha256.New()
+	io.WriteString(h, name+"\x00")
+	for _, p := range files {
+		rel, _ := filepath.Rel(root, p)
+		io.WriteString(h, rel+"\x00")
+		f, err := os.Open(p)
+		if err != nil {
+			return "", err
+		}
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,16 @@
+// Package schema publishes the versioned JSON schema of infra-check's JSON output.
+//
+// Stability: within a major version fields are only added, never renamed, retyped
+// or removed, and Version's minor number is bumped with each addition. A breaking
+// change ships as a new major version with its own schema file.
+package schema
+
+import _ "embed"
+
+// Version is the schemaVersion written into every JSON result.
+const Version = "1.4"
+
+// ResultV1 is the JSON Schema (draft 2020-12) describing major version 1 of the result envelope.
+//
+//go:embed result.v1.json
+var ResultV1 []byte
@@ -0,0 +1,222 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/salchaD-27/infra-check/internal/config"
+	"github.com/salchaD-27/infra-check/internal/engine"
+	"github.com/salchaD-27/infra-check/internal/profile"
+	"github.com/salchaD-27/infra-check/internal/report"
+	_ "github.com/salchaD-27/infra-check/internal/scanners"
+	"github.com/salchaD-27/infra-check/pkg/schema"
+)
+
+const fixture = "../../tests/sample-terraform-files"
+
+// The JSON result of a fixture scan, with every optional field filled in, must match
+// result.v1.json exactly: a field the output has and the schema does not was added or
+// renamed without the schema, and one the schema has and the output does not was renamed
+// or removed, which version 1 does not allow.
+func TestResultMatchesSchema(t *testing.T) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(schema.ResultV1, &doc); err != nil {
+		t.Fatalf("result.v1.json: %v", err)
+	}
+	v := &validator{root: doc, seen: make(map[string]bool)}
+	var value interface{}
+	if err := json.Unmarshal([]byte(fixtureResult(t)), &value); err != nil {
+		t.Fatal(err)
+	}
+	v.check(doc, value, "$", "")
+	for _, err := range v.errs {
+		t.Error(err)
+	}
+	declared := v.declared(doc, "", make(map[string]bool))
+	sort.Strings(declared)
+	for _, field := range declared {
+		if !v.seen[field] {
+			t.Errorf("the schema declares %s, which the result does not have", field)
+		}
+	}
+}
+
+// Fields are added in a minor version: the schema may not describe one as added in a later
+// version than Version.
+func TestSchemaAdditionsBumpVersion(t *testing.T) {
+	minor, err := strconv.Atoi(strings.TrimPrefix(schema.Version, "1."))
+	if err != nil {
+		t.Fatalf("Version %q is not 1.MINOR", schema.Version)
+	}
+	for _, m := range regexp.MustCompile(`[Aa]dded in 1\.([0-9]+)`).FindAllSubmatch(schema.ResultV1, -1) {
+		if n, _ := strconv.Atoi(string(m[1])); n > minor {
+			t.Errorf("result.v1.json describes a field added in 1.%d, but Version is %s", n, schema.Version)
+		}
+	}
+}
+
+// fixtureResult scans the fixture as 'scan all --format json --profile cis-aws' would.
+func fixtureResult(t *testing.T) string {
+	t.Helper()
+	results, err := engine.RunAll(fixture, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{Environments: map[string][]string{"prod": {"**/failure*.tf"}}}
+	findings := engine.Fingerprint(engine.Process(engine.Dedupe(engine.Merge(results)), cfg), fixture)
+	findings = engine.Snippets(findings, fixture, 1)
+	cis, err := profile.Lookup("cis-aws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, f := range findings {
+		findings[i], _ = cis.Apply(f)
+	}
+	summary := report.Summarize(findings, 1, time.Second)
+	out, err := report.ExportResult(report.Result{
+		SchemaVersion: schema.Version,
+		Findings:      findings,
+		Sections:      []report.Section{engine.SummarySection(results, cfg)},
+		Summary:       &summary,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// validator checks values against the parts of JSON Schema result.v1.json uses, recording
+// the declared fields it saw, by their path in the schema, such as "finding.Snippet.Lines".
+type validator struct {
+	root map[string]interface{}
+	seen map[string]bool
+	errs []error
+}
+
+func (v *validator) check(s map[string]interface{}, value interface{}, at, field string) {
+	if ref, ok := s["$ref"].(string); ok {
+		s, field = v.resolve(ref)
+	}
+	if typ, ok := s["type"].(string); ok && !hasType(value, typ) {
+		v.errorf("%s: %v is not of type %s", at, value, typ)
+		return
+	}
+	if enum, ok := s["enum"].([]interface{}); ok && !contains(enum, value) {
+		v.errorf("%s: %v is not one of %v", at, value, enum)
+	}
+	if pattern, ok := s["pattern"].(string); ok {
+		if str, _ := value.(string); !regexp.MustCompile(pattern).MatchString(str) {
+			v.errorf("%s: %q does not match %s", at, str, pattern)
+		}
+	}
+	if min, ok := s["minimum"].(float64); ok {
+		if n, _ := value.(float64); n < min {
+			v.errorf("%s: %v is less than %v", at, n, min)
+		}
+	}
+	if items, ok := s["items"].(map[string]interface{}); ok {
+		for i, item := range value.([]interface{}) {
+			v.check(items, item, fmt.Sprintf("%s[%d]", at, i), field)
+		}
+	}
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return
+	}
+	required, _ := s["required"].([]interface{})
+	for _, name := range required {
+		if _, ok := obj[name.(string)]; !ok {
+			v.errorf("%s: required field %s is missing", at, name)
+		}
+	}
+	props, _ := s["properties"].(map[string]interface{})
+	for name, val := range obj {
+		if p, ok := props[name].(map[string]interface{}); ok {
+			v.seen[join(field, name)] = true
+			v.check(p, val, at+"."+name, join(field, name))
+			continue
+		}
+		extra, ok := s["additionalProperties"].(map[string]interface{})
+		if !ok {
+			v.errorf("%s: field %s is not in the schema", at, name)
+			continue
+		}
+		v.check(extra, val, at+"."+name, field)
+	}
+}
+
+// declared returns the paths of the fields s declares, following references once.
+func (v *validator) declared(s map[string]interface{}, field string, followed map[string]bool) []string {
+	if ref, ok := s["$ref"].(string); ok {
+		if followed[ref] {
+			return nil
+		}
+		followed[ref] = true
+		s, field = v.resolve(ref)
+	}
+	var out []string
+	if items, ok := s["items"].(map[string]interface{}); ok {
+		out = append(out, v.declared(items, field, followed)...)
+	}
+	props, _ := s["properties"].(map[string]interface{})
+	for name, p := range props {
+		out = append(out, join(field, name))
+		out = append(out, v.declared(p.(map[string]interface{}), join(field, name), followed)...)
+	}
+	return out
+}
+
+// resolve returns the definition a "#/$defs/name" reference names, and name.
+func (v *validator) resolve(ref string) (map[string]interface{}, string) {
+	name := strings.TrimPrefix(ref, "#/$defs/")
+	def, ok := v.root["$defs"].(map[string]interface{})[name].(map[string]interface{})
+	if !ok {
+		v.errorf("unresolved reference %s", ref)
+		return map[string]interface{}{}, name
+	}
+	return def, name
+}
+
+func (v *validator) errorf(format string, args ...interface{}) {
+	v.errs = append(v.errs, fmt.Errorf(format, args...))
+}
+
+func hasType(value interface{}, typ string) bool {
+	switch typ {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == float64(int64(n))
+	}
+	return false
+}
+
+func contains(list []interface{}, value interface{}) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func join(field, name string) string {
+	if field == "" {
+		return name
+	}
+	return field + "." + name
+}
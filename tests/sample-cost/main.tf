@@ -0,0 +1,36 @@
+variable "db_class" {
+  type        = string
+  description = "RDS instance class"
+  default     = "db.m5.large"
+}
+
+resource "aws_instance" "web" {
+  count         = 2
+  instance_type = "m5.large"
+
+  root_block_device {
+    volume_size = 50
+    volume_type = "gp3"
+  }
+}
+
+resource "aws_db_instance" "main" {
+  instance_class    = var.db_class
+  allocated_storage = 100
+  storage_type      = "gp3"
+  multi_az          = true
+}
+
+resource "aws_ebs_volume" "data" {
+  availability_zone = "us-east-1a"
+  size              = 500
+  type              = "st1"
+}
+
+resource "aws_nat_gateway" "egress" {
+  subnet_id = "subnet-0abc"
+}
+
+resource "aws_instance" "batch" {
+  instance_type = "x9.superhuge"
+}
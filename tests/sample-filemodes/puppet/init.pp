@@ -0,0 +1,28 @@
+# @summary Shared directories and files of the application.
+class app::files {
+  file { '/srv/app/uploads':
+    ensure => directory,
+    mode   => '0777', # Failure: world-writable directory
+  }
+
+  file { 'app environment':
+    ensure => file,
+    path   => '/etc/app/app.env',
+    mode   => '0666', # Failure: world-writable file, reported with its path
+  }
+
+  file { '/etc/app/app.yml':
+    ensure => file,
+    mode   => 'o+w', # Failure: symbolic mode adding write for others
+  }
+
+  file { '/srv/app/scratch':
+    ensure => directory,
+    mode   => '1777', # sticky bit: users cannot remove each other's files
+  }
+
+  file { '/etc/app/secrets.yml':
+    ensure => file,
+    mode   => '0600',
+  }
+}
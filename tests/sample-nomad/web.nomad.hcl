@@ -0,0 +1,36 @@
+job "web" {
+  datacenters = ["dc1"]
+
+  group "frontend" {
+    count = 2
+
+    task "nginx" {
+      driver = "docker"
+
+      config {
+        image        = "nginx:1.27.2"
+        privileged   = true   # Failure: privileged container
+        network_mode = "host" # Failure: shares the host network namespace
+      }
+
+      env {
+        LOG_LEVEL   = "info"
+        DB_PASSWORD = "hunter2" # Failure: plaintext secret
+      }
+
+      resources {
+        cpu    = 500
+        memory = 256
+      }
+    }
+
+    task "cleanup" {
+      driver = "raw_exec" # Failure: no isolation
+
+      config {
+        command = "/usr/local/bin/cleanup"
+      }
+      # Failure: no resources stanza
+    }
+  }
+}
@@ -0,0 +1,24 @@
+# Class and defined type parameters default to quoted booleans.
+# A resource parameter is a quoted boolean too.
+
+# Manages the ntp service.
+class ntp (
+  String $server  = 'pool.ntp.org',
+  $enabled        = 'true',      # Failure: quoted boolean default
+) {
+  service { 'ntpd':
+    ensure => running,
+    enable => $enabled,
+  }
+}
+
+# A virtual host of the web server.
+define webserver::vhost (
+  $port       = 80,
+  $ssl        = 'false',         # Failure: quoted boolean default
+) {
+  file { "/etc/nginx/sites-enabled/${title}.conf":
+    ensure => file,
+    backup => 'false',           # Failure: quoted boolean
+  }
+}
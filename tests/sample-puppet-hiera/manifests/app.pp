@@ -0,0 +1,15 @@
+# Reads its settings from Hiera.
+class profile::app {
+  $port = lookup('profile::app::port')
+  $settings = lookup('profile::app::settings', Hash, 'deep')
+  $token = lookup('profile::app::settings.api_token')
+  $db_password = lookup('profile::app::db_password')
+  $log_level = lookup('profile::app::log_level')
+  $workers = lookup('profile::app::workers', Integer, 'first', 4)
+  $region = hiera('profile::app::region')
+
+  file { '/etc/app.conf':
+    ensure  => file,
+    content => "port=${port}\n",
+  }
+}
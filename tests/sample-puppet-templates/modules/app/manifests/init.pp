@@ -0,0 +1,28 @@
+# Renders ERB and EPP templates from the module.
+class app (
+  String $db_host = 'localhost',
+  Integer $port = 8080,
+) {
+  $log_dir = '/var/log/app'
+
+  file { '/etc/app/app.conf':
+    ensure  => file,
+    content => template('app/app.conf.erb'),
+  }
+
+  file { '/usr/local/bin/app-backup':
+    ensure  => file,
+    mode    => '0755',
+    content => template('app/backup.sh.erb'),
+  }
+
+  file { '/etc/motd':
+    ensure  => file,
+    content => epp('app/motd.epp', { 'owner' => 'ops' }),
+  }
+
+  file { '/etc/app/limits.conf':
+    ensure  => file,
+    content => epp('app/limits.conf.epp'),
+  }
+}
@@ -0,0 +1,75 @@
+resource "azurerm_key_vault" "app" {
+  name                       = "example-app"
+  location                   = "westeurope"
+  resource_group_name        = "example"
+  tenant_id                  = "00000000-0000-0000-0000-000000000000"
+  sku_name                   = "standard"
+  soft_delete_retention_days = 3 # Failure: too short, and purge protection is off
+
+  tags = {
+    Environment = "prod"
+    Owner       = "platform"
+    Project     = "app"
+  }
+}
+
+# Failure: no auditing, inline or through an extended auditing policy
+resource "azurerm_mssql_server" "orders" {
+  name                         = "example-orders"
+  resource_group_name          = "example"
+  location                     = "westeurope"
+  version                      = "12.0"
+  administrator_login          = "orders"
+  administrator_login_password = var.sql_password
+  minimum_tls_version          = "1.2"
+
+  tags = {
+    Environment = "prod"
+    Owner       = "platform"
+    Project     = "orders"
+  }
+}
+
+resource "azurerm_mssql_server" "audited" {
+  name                         = "example-audited"
+  resource_group_name          = "example"
+  location                     = "westeurope"
+  version                      = "12.0"
+  administrator_login          = "audited"
+  administrator_login_password = var.sql_password
+  minimum_tls_version          = "1.2"
+
+  tags = {
+    Environment = "prod"
+    Owner       = "platform"
+    Project     = "audited"
+  }
+}
+
+resource "azurerm_mssql_server_extended_auditing_policy" "audited" {
+  server_id         = azurerm_mssql_server.audited.id
+  retention_in_days = 90
+}
+
+resource "azurerm_linux_web_app" "portal" {
+  name                = "example-portal"
+  resource_group_name = "example"
+  location            = "westeurope"
+  service_plan_id     = "plan-id"
+
+  site_config {
+    minimum_tls_version = "1.1" # Failure: weak TLS
+  }
+
+  tags = {
+    Environment = "prod"
+    Owner       = "platform"
+    Project     = "portal"
+  }
+}
+
+variable "sql_password" {
+  description = "Administrator password of the SQL servers"
+  type        = string
+  sensitive   = true
+}
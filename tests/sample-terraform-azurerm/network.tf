@@ -0,0 +1,37 @@
+resource "azurerm_network_security_group" "jump" {
+  name                = "jump"
+  location            = "westeurope"
+  resource_group_name = "example"
+
+  security_rule {
+    name                       = "ssh"
+    priority                   = 100
+    direction                  = "Inbound"
+    access                     = "Allow"
+    protocol                   = "Tcp"
+    source_port_range          = "*"
+    destination_port_range     = "22"
+    source_address_prefix      = "Internet" # Failure: SSH open to the internet
+    destination_address_prefix = "*"
+  }
+
+  tags = {
+    Environment = "prod"
+    Owner       = "platform"
+    Project     = "network"
+  }
+}
+
+resource "azurerm_network_security_rule" "rdp" {
+  name                        = "rdp"
+  priority                    = 110
+  direction                   = "Inbound"
+  access                      = "Allow"
+  protocol                    = "*"
+  source_port_range           = "*"
+  destination_port_ranges     = ["3389"]
+  source_address_prefix       = "*" # Failure: RDP open to the internet
+  destination_address_prefix  = "*"
+  resource_group_name         = "example"
+  network_security_group_name = azurerm_network_security_group.jump.name
+}
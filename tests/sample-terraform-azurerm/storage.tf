@@ -0,0 +1,21 @@
+resource "azurerm_storage_account" "logs" {
+  name                     = "examplelogs"
+  resource_group_name      = "example"
+  location                 = "westeurope"
+  account_tier             = "Standard"
+  account_replication_type = "GRS"
+  min_tls_version          = "TLS1_0" # Failure: weak TLS
+  # Failure: allow_nested_items_to_be_public defaults to true
+
+  tags = {
+    Environment = "prod"
+    Owner       = "platform"
+    Project     = "logging"
+  }
+}
+
+resource "azurerm_storage_container" "exports" {
+  name                  = "exports"
+  storage_account_name  = azurerm_storage_account.logs.name
+  container_access_type = "container" # Failure: blobs publicly readable
+}
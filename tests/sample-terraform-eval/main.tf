@@ -0,0 +1,52 @@
+locals {
+  name_prefix = format("%s-%s", "example", var.environment)
+  buckets     = { for name in ["assets", "reports", "archive"] : name => "${local.name_prefix}-${name}" }
+  common_tags = merge({ Owner = "platform", Project = "example" }, { Environment = upper(var.environment) })
+  archive_acl = lookup({ dev = "public-read", prod = "private" }, var.environment, "private")
+}
+
+resource "aws_s3_bucket" "assets" {
+  bucket = local.buckets["assets"]
+  acl    = var.assets_acl # Failure: public-read from the variable default
+  tags   = local.common_tags
+}
+
+resource "aws_s3_bucket" "reports" {
+  bucket = local.buckets["reports"]
+  acl    = var.reports_acl # Failure: public-read from prod.auto.tfvars
+  tags   = local.common_tags
+}
+
+resource "aws_s3_bucket" "archive" {
+  bucket = local.buckets["archive"]
+  acl    = local.archive_acl # private: environment is prod in terraform.tfvars
+  tags   = local.common_tags
+}
+
+resource "aws_security_group" "admin" {
+  name        = "${local.name_prefix}-admin"
+  description = "Admin access"
+  tags        = local.common_tags
+
+  ingress {
+    description = "SSH"
+    from_port   = 22
+    to_port     = 22
+    protocol    = "tcp"
+    cidr_blocks = var.admin_cidrs # Failure: 0.0.0.0/0 from terraform.tfvars
+  }
+}
+
+resource "aws_security_group" "office" {
+  name        = "${local.name_prefix}-office"
+  description = "Office access"
+  tags        = local.common_tags
+
+  ingress {
+    description = "HTTPS"
+    from_port   = 443
+    to_port     = 443
+    protocol    = "tcp"
+    cidr_blocks = [var.office_cidr] # Failure: the variable has no value, so the check is skipped
+  }
+}
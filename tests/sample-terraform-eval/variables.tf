@@ -0,0 +1,28 @@
+variable "environment" {
+  description = "Deployment environment, set in terraform.tfvars"
+  type        = string
+  default     = "dev"
+}
+
+variable "assets_acl" {
+  description = "ACL of the assets bucket"
+  type        = string
+  default     = "public-read"
+}
+
+variable "reports_acl" {
+  description = "ACL of the reports bucket, overridden in prod.auto.tfvars"
+  type        = string
+  default     = "private"
+}
+
+variable "admin_cidrs" {
+  description = "Networks allowed to reach the admin port"
+  type        = list(string)
+  default     = ["10.0.0.0/8"]
+}
+
+variable "office_cidr" {
+  description = "Office network, given on the command line"
+  type        = string
+}
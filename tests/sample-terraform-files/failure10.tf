@@ -0,0 +1,4 @@
+output "public_subnet_ids" {
+  description = "Subnets of the load balancer"
+  value       = var.public_subnet_ids // Failure: no variable block declares public_subnet_ids
+}
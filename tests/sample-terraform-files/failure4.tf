@@ -0,0 +1,26 @@
+provider "aws" {
+  region                      = "us-east-1"
+  skip_credentials_validation = true
+  skip_requesting_account_id  = true
+}
+
+provider "kubernetes" {
+  host     = "https://cluster.example.com"
+  insecure = true
+}
+
+provider "helm" {
+  kubernetes {
+    host     = "https://cluster.example.com"
+    insecure = true
+  }
+}
+
+resource "tls_private_key" "legacy" {
+  algorithm = "RSA"
+  rsa_bits  = 1024
+}
+
+resource "tls_private_key" "ecdsa_default" {
+  algorithm = "ECDSA"
+}
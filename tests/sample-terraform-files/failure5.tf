@@ -0,0 +1,38 @@
+resource "aws_security_group" "bastion" {
+  name = "bastion"
+
+  ingress {
+    from_port   = 22
+    to_port     = 22
+    protocol    = "tcp"
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+
+  ingress {
+    from_port   = 443
+    to_port     = 443
+    protocol    = "tcp"
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+
+  tags = {
+    Environment = "dev"
+    Owner       = "platform"
+    Project     = "bastion"
+  }
+}
+
+resource "aws_security_group_rule" "rdp" {
+  type              = "ingress"
+  from_port         = 3000
+  to_port           = 4000
+  protocol          = "tcp"
+  ipv6_cidr_blocks  = ["::/0"]
+  security_group_id = aws_security_group.bastion.id
+}
+
+resource "aws_vpc_security_group_ingress_rule" "everything" {
+  security_group_id = aws_security_group.bastion.id
+  ip_protocol       = "-1"
+  cidr_ipv4         = "0.0.0.0/0"
+}
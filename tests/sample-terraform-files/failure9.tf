@@ -0,0 +1,4 @@
+variable "signing_salt" {
+  type      = string
+  sensitive = true
+}
@@ -0,0 +1,27 @@
+# --fix adds tags to resources whose type takes them, and leaves the rest alone.
+
+resource "aws_security_group" "admin" {
+  name        = "admin"
+  description = "Administrative access"
+  vpc_id      = "vpc-0123456789abcdef0"
+}
+
+# Security group rules, policies and attachments take no tags argument.
+resource "aws_security_group_rule" "https" {
+  type              = "ingress"
+  from_port         = 443
+  to_port           = 443
+  protocol          = "tcp"
+  cidr_blocks       = ["10.0.0.0/8"]
+  security_group_id = aws_security_group.admin.id
+}
+
+resource "aws_s3_bucket_policy" "logs" {
+  bucket = "example-logs"
+  policy = "{}"
+}
+
+resource "aws_iam_role_policy_attachment" "readonly" {
+  role       = "admin"
+  policy_arn = "arn:aws:iam::aws:policy/ReadOnlyAccess"
+}
@@ -0,0 +1,64 @@
+resource "google_compute_instance" "worker" {
+  name         = "worker"
+  machine_type = "e2-medium"
+  zone         = "europe-west1-b"
+
+  boot_disk {
+    initialize_params {
+      image = "debian-cloud/debian-12"
+    }
+  }
+
+  network_interface {
+    network = "default"
+  }
+
+  # Failure: default compute service account with the cloud-platform scope
+  service_account {
+    scopes = ["cloud-platform"]
+  }
+}
+
+resource "google_compute_instance_template" "batch" {
+  name         = "batch"
+  machine_type = "e2-standard-4"
+
+  disk {
+    source_image = "debian-cloud/debian-12"
+  }
+
+  network_interface {
+    network = "default"
+  }
+
+  service_account {
+    email  = google_service_account.batch.email
+    scopes = ["cloud-platform"]
+  }
+}
+
+resource "google_service_account" "batch" {
+  account_id = "batch"
+}
+
+# Failure: no source ranges, so SSH and RDP are open to 0.0.0.0/0
+resource "google_compute_firewall" "admin" {
+  name    = "admin"
+  network = "default"
+
+  allow {
+    protocol = "tcp"
+    ports    = ["22", "3389"]
+  }
+}
+
+resource "google_compute_firewall" "web" {
+  name          = "web"
+  network       = "default"
+  source_ranges = ["0.0.0.0/0"]
+
+  allow {
+    protocol = "tcp"
+    ports    = ["80", "443"]
+  }
+}
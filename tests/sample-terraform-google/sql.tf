@@ -0,0 +1,40 @@
+resource "google_sql_database_instance" "orders" {
+  name             = "orders"
+  database_version = "POSTGRES_15"
+  region           = "europe-west1"
+
+  settings {
+    tier = "db-custom-2-7680"
+
+    ip_configuration {
+      ipv4_enabled = true
+      ssl_mode     = "ALLOW_UNENCRYPTED_AND_ENCRYPTED" # Failure: accepts unencrypted connections
+    }
+  }
+}
+
+resource "google_sql_database_instance" "reports" {
+  name             = "reports"
+  database_version = "MYSQL_8_0"
+  region           = "europe-west1"
+
+  settings {
+    tier = "db-f1-micro"
+
+    ip_configuration {
+      ipv4_enabled = true
+      require_ssl  = true
+    }
+  }
+}
+
+# Failure: no ip_configuration, so SSL is not required
+resource "google_sql_database_instance" "legacy" {
+  name             = "legacy"
+  database_version = "POSTGRES_11"
+  region           = "europe-west1"
+
+  settings {
+    tier = "db-f1-micro"
+  }
+}
@@ -0,0 +1,22 @@
+resource "google_storage_bucket" "assets" {
+  name                        = "example-assets"
+  location                    = "EU"
+  uniform_bucket_level_access = true
+}
+
+resource "google_storage_bucket_iam_member" "public" {
+  bucket = google_storage_bucket.assets.name
+  role   = "roles/storage.objectViewer"
+  member = "allUsers" # Failure: the bucket is public
+}
+
+resource "google_storage_bucket_iam_binding" "team" {
+  bucket  = google_storage_bucket.assets.name
+  role    = "roles/storage.objectAdmin"
+  members = ["group:platform@example.com"]
+}
+
+resource "google_storage_default_object_acl" "legacy" {
+  bucket      = google_storage_bucket.assets.name
+  role_entity = ["OWNER:project-owners-123456", "READER:allAuthenticatedUsers"] # Failure: public objects
+}
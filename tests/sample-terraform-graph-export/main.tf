@@ -0,0 +1,53 @@
+# 'infra-check graph' draws this module and modules/bucket as clusters, an edge from
+# aws_instance.web to data.aws_subnets.private through local.instance_subnets, a dashed
+# depends_on edge to module.logs, and a node of each severity color.
+
+locals {
+  instance_subnets = data.aws_subnets.private.ids
+  common_tags = {
+    Environment = "prod"
+    Owner       = "platform"
+    Project     = "example"
+  }
+}
+
+data "aws_subnets" "private" {
+  filter {
+    name   = "tag:Tier"
+    values = ["private"]
+  }
+}
+
+data "aws_ami" "base" {
+  most_recent = true
+  owners      = ["amazon"]
+}
+
+module "logs" {
+  source = "./modules/bucket"
+  name   = "example-prod-logs"
+}
+
+resource "aws_security_group" "web" {
+  name        = "example-web"
+  description = "Web access"
+  tags        = local.common_tags
+
+  ingress {
+    description = "SSH"
+    from_port   = 22
+    to_port     = 22
+    protocol    = "tcp"
+    cidr_blocks = ["0.0.0.0/0"] # Failure: red node, SSH open to the internet
+  }
+}
+
+resource "aws_instance" "web" {
+  ami                    = data.aws_ami.base.id
+  instance_type          = "t3.small"
+  subnet_id              = local.instance_subnets[0]
+  vpc_security_group_ids = [aws_security_group.web.id]
+  tags                   = local.common_tags
+
+  depends_on = [module.logs]
+}
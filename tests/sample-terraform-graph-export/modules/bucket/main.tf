@@ -0,0 +1,28 @@
+variable "name" {
+  description = "Name of the bucket"
+  type        = string
+}
+
+resource "aws_s3_bucket" "this" { # Failure: blue node, no KMS encryption configuration
+  bucket = var.name
+  tags = {
+    Environment = "prod"
+    Owner       = "platform"
+    Project     = "example"
+  }
+}
+
+resource "aws_s3_bucket_public_access_block" "this" { # Failure: orange node, no tags
+  bucket                  = aws_s3_bucket.this.id
+  block_public_acls       = true
+  block_public_policy     = true
+  ignore_public_acls      = true
+  restrict_public_buckets = true
+}
+
+resource "aws_s3_bucket_versioning" "this" {
+  bucket = aws_s3_bucket.this.id
+  versioning_configuration {
+    status = "Enabled"
+  }
+}
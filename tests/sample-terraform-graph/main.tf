@@ -0,0 +1,55 @@
+module "network" {
+  source     = "./modules/network"
+  cidr_block = "10.20.0.0/16"
+}
+
+resource "aws_instance" "web" {
+  ami           = data.aws_ami.base.id # Failure: no data "aws_ami" "base" is declared
+  instance_type = "t3.small"
+  subnet_id     = module.network.private_subnet_id
+
+  tags = {
+    Environment = "prod"
+    Owner       = "platform"
+    Project     = "example"
+  }
+}
+
+resource "aws_eip" "web" {
+  instance = aws_instance.web.id
+  domain   = "vpc"
+
+  tags = {
+    Environment = "prod"
+    Owner       = "platform"
+    Project     = "example"
+  }
+}
+
+output "web_address" {
+  description = "Public address of the web instance"
+  value       = "${aws_eip.web.public_ip} in ${module.dns.zone_id}" # Failure: no module "dns" is declared
+}
+
+resource "aws_iam_role" "app" {
+  name               = "example-app"
+  assume_role_policy = aws_iam_policy.app.policy
+
+  tags = {
+    Environment = "prod"
+    Owner       = "platform"
+    Project     = "example"
+  }
+}
+
+resource "aws_iam_policy" "app" {
+  name       = "example-app"
+  policy     = jsonencode({ Version = "2012-10-17", Statement = [] })
+  depends_on = [aws_iam_role.app] # Failure: the role already depends on the policy
+
+  tags = {
+    Environment = "prod"
+    Owner       = "platform"
+    Project     = "example"
+  }
+}
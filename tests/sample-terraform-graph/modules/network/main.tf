@@ -0,0 +1,35 @@
+variable "cidr_block" {
+  description = "CIDR block of the VPC"
+  type        = string
+}
+
+resource "aws_vpc" "this" {
+  cidr_block = var.cidr_block
+
+  tags = {
+    Environment = "prod"
+    Owner       = "platform"
+    Project     = "example"
+  }
+}
+
+resource "aws_subnet" "private" {
+  vpc_id     = aws_vpc.this.id
+  cidr_block = cidrsubnet(var.cidr_block, 8, 1)
+
+  tags = {
+    Environment = "prod"
+    Owner       = "platform"
+    Project     = "example"
+  }
+}
+
+output "private_subnet_id" {
+  description = "ID of the private subnet"
+  value       = aws_subnet.private.id
+}
+
+output "vpc_arn" { # Failure: the root module does not use it
+  description = "ARN of the VPC"
+  value       = aws_vpc.this.arn
+}
@@ -0,0 +1,23 @@
+data "aws_subnet_ids" "private" { # Failure: deprecated, use aws_subnets
+  vpc_id = var.vpc_id
+}
+
+data "aws_subnets" "public" {
+  filter {
+    name   = "vpc-id"
+    values = [var.vpc_id]
+  }
+}
+
+data "template_file" "user_data" { # Failure: the template provider is archived
+  template = file("${path.module}/user-data.sh")
+
+  vars = {
+    api_token = local.api_token
+  }
+}
+
+data "aws_ami" "base" {
+  most_recent = true
+  owners      = ["amazon"]
+}
@@ -0,0 +1,16 @@
+locals {
+  api_token = "tok_live_4f9a2b7c1d8e3f60" # Failure: hardcoded secret in a local
+
+  database = { # Failure: hardcoded secret in the password key of a map local
+    host     = "db.internal.example.com"
+    password = "Sup3rS3cret!"
+  }
+
+  legacy_name = "example-web-v1" # Failure: never referenced
+
+  common_tags = {
+    Environment = "prod"
+    Owner       = "platform"
+    Project     = "example"
+  }
+}
@@ -0,0 +1,19 @@
+resource "aws_instance" "web" {
+  ami           = data.aws_ami.base.id
+  instance_type = "t3.small"
+  subnet_id     = tolist(data.aws_subnet_ids.private.ids)[0]
+  user_data     = data.template_file.user_data.rendered
+  tags          = merge(local.common_tags, local.extra_tags) # Failure: local.extra_tags is not declared
+}
+
+resource "aws_secretsmanager_secret" "database" {
+  name        = "example/database"
+  description = "Credentials of ${local.database.host}"
+  tags        = local.common_tags
+}
+
+resource "aws_lb" "public" {
+  name    = "example-public"
+  subnets = data.aws_subnets.public.ids
+  tags    = local.common_tags
+}
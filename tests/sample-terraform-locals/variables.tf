@@ -0,0 +1,11 @@
+variable "vpc_id" {
+  description = "VPC the instances run in"
+  type        = string
+  default     = "vpc-0a1b2c3d"
+}
+
+variable "instance_type" { # Failure: never referenced
+  description = "Instance type"
+  type        = string
+  default     = "t3.micro"
+}
@@ -0,0 +1,8 @@
+module "logs" {
+  source = "../../modules/bucket"
+  name   = "acme-prod-logs"
+}
+
+module "missing" {
+  source = "../../modules/queue"
+}
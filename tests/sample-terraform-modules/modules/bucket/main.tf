@@ -0,0 +1,14 @@
+variable "name" {
+  type = string
+}
+
+resource "aws_s3_bucket" "this" {
+  bucket = var.name
+  acl    = "public-read"
+
+  tags = {
+    Environment = "prod"
+    Owner       = "platform"
+    Project     = "logging"
+  }
+}
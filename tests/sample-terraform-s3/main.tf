@@ -0,0 +1,120 @@
+locals {
+  tags = {
+    Environment = "prod"
+    Owner       = "platform"
+    Project     = "example"
+  }
+}
+
+# Hardened through the provider v4+ split resources.
+resource "aws_s3_bucket" "logs" {
+  bucket = "example-prod-logs"
+  tags   = local.tags
+}
+
+resource "aws_s3_bucket_acl" "logs" {
+  bucket = aws_s3_bucket.logs.id
+  acl    = "private"
+}
+
+resource "aws_s3_bucket_public_access_block" "logs" {
+  bucket                  = aws_s3_bucket.logs.id
+  block_public_acls       = true
+  block_public_policy     = true
+  ignore_public_acls      = true
+  restrict_public_buckets = true
+}
+
+resource "aws_s3_bucket_versioning" "logs" {
+  bucket = aws_s3_bucket.logs.id
+  versioning_configuration {
+    status = "Enabled"
+  }
+}
+
+resource "aws_s3_bucket_server_side_encryption_configuration" "logs" {
+  bucket = aws_s3_bucket.logs.id
+  rule {
+    apply_server_side_encryption_by_default {
+      sse_algorithm     = "aws:kms"
+      kms_master_key_id = "alias/example-s3"
+    }
+  }
+}
+
+# Public through an ACL resource, with a partial public access block and suspended versioning.
+resource "aws_s3_bucket" "website" { # Failure: versioning is suspended
+  bucket = "example-prod-website"
+  tags   = local.tags
+}
+
+resource "aws_s3_bucket_acl" "website" {
+  bucket = aws_s3_bucket.website.id
+  acl    = "public-read" # Failure: publicly readable
+}
+
+resource "aws_s3_bucket_public_access_block" "website" { # Failure: leaves two settings disabled
+  bucket                  = aws_s3_bucket.website.id
+  block_public_acls       = false
+  block_public_policy     = true
+  ignore_public_acls      = false
+  restrict_public_buckets = true
+}
+
+resource "aws_s3_bucket_versioning" "website" {
+  bucket = aws_s3_bucket.website.id
+  versioning_configuration {
+    status = "Suspended"
+  }
+}
+
+resource "aws_s3_bucket_server_side_encryption_configuration" "website" {
+  bucket = aws_s3_bucket.website.id
+  rule {
+    apply_server_side_encryption_by_default {
+      sse_algorithm = "AES256"
+    }
+  }
+}
+
+# Configured by nothing: no public access block, versioning or encryption.
+resource "aws_s3_bucket" "scratch" { # Failure: no public access block, no versioning, no KMS encryption
+  bucket = "example-prod-scratch"
+  tags   = local.tags
+}
+
+# Hardened through split resources naming the bucket by its literal name rather than a reference.
+resource "aws_s3_bucket" "archive" {
+  bucket = "example-prod-archive"
+  tags   = local.tags
+}
+
+resource "aws_s3_bucket_public_access_block" "archive" {
+  bucket                  = "example-prod-archive"
+  block_public_acls       = true
+  block_public_policy     = true
+  ignore_public_acls      = true
+  restrict_public_buckets = true
+}
+
+resource "aws_s3_bucket_versioning" "archive" {
+  bucket = "example-prod-archive"
+  versioning_configuration {
+    status = "Enabled"
+  }
+}
+
+resource "aws_s3_bucket_server_side_encryption_configuration" "archive" {
+  bucket = "example-prod-archive"
+  rule {
+    apply_server_side_encryption_by_default {
+      sse_algorithm     = "aws:kms"
+      kms_master_key_id = "alias/example-s3"
+    }
+  }
+}
+
+resource "aws_s3_bucket_acl" "archive" {
+  bucket = "example-prod-archive"
+  acl    = "public-read" # Failure: publicly readable
+}
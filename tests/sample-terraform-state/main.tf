@@ -0,0 +1,16 @@
+resource "random_password" "db" {
+  length = 24
+}
+
+resource "aws_s3_bucket" "logs" {
+  bucket = "acme-logs"
+  acl    = "private"
+}
+
+resource "aws_sqs_queue" "jobs" {
+  name = "jobs"
+}
+
+output "db_password" {
+  value = random_password.db.result
+}